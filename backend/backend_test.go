@@ -231,3 +231,56 @@ func createServers(t *testing.T, td testData, _ *gomock.Controller) (teardown fu
 		}
 	}
 }
+
+func TestReconfigure(t *testing.T) {
+	run := func(t *testing.T, setup setupFunc) {
+		const n = 4
+		ctrl := gomock.NewController(t)
+		td := setup(t, ctrl, n)
+		teardown := createServers(t, td, ctrl)
+		defer teardown()
+
+		cfg := NewConfig(td.creds, gorums.WithDialTimeout(time.Second))
+		td.builders[0].Add(cfg)
+		td.builders.Build()
+		if err := cfg.Connect(td.replicas); err != nil {
+			t.Fatal(err)
+		}
+		defer cfg.Close()
+
+		validators := func(ids ...hotstuff.ID) []hotstuff.Validator {
+			var validators []hotstuff.Validator
+			for _, id := range ids {
+				validators = append(validators, hotstuff.Validator{ID: id, Address: td.replicas[id-1].Address})
+			}
+			return validators
+		}
+
+		// adding a replica keeps the manager and its connections.
+		mgr := cfg.manager()
+		if err := cfg.Reconfigure(validators(1, 2, 3, 4)); err != nil {
+			t.Fatal(err)
+		}
+		if cfg.manager() != mgr {
+			t.Error("the manager was replaced, although no replica was removed")
+		}
+
+		// removing a replica moves the connections to a new manager.
+		if err := cfg.Reconfigure(validators(1, 2, 3)); err != nil {
+			t.Fatal(err)
+		}
+		if cfg.manager() == mgr {
+			t.Error("the manager was not replaced when a replica was removed")
+		}
+		if ids := cfg.manager().NodeIDs(); len(ids) != 2 {
+			t.Errorf("new manager has nodes %v, want the nodes of replicas 2 and 3", ids)
+		}
+		if _, ok := cfg.Replica(4); ok || cfg.Len() != 3 {
+			t.Errorf("replica 4 is still in the configuration of %d replicas", cfg.Len())
+		}
+		if key := cfg.Replicas()[2].PublicKey(); key == nil {
+			t.Error("the public key of a remaining replica was lost")
+		}
+	}
+	runBoth(t, run)
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/relab/hotstuff/eventloop"
 	"github.com/relab/hotstuff/logging"
@@ -72,8 +73,9 @@ func (r *Replica) Metadata() map[string]string {
 type Config struct {
 	opts      []gorums.ManagerOption
 	connected bool
+	creds     *Credentials // the credentials that the peers are checked against on each request; nil without TLS.
 
-	mgr *hotstuffpb.Manager
+	mgr *hotstuffpb.Manager // guarded by subConfig.mut, since it is replaced by Reconfigure.
 	subConfig
 }
 
@@ -82,6 +84,9 @@ type subConfig struct {
 	logger    logging.Logger
 	opts      *modules.Options
 
+	// the configuration and the replicas are replaced by Reconfigure on the event loop,
+	// while the servers and the sync modules read them from other goroutines.
+	mut      sync.RWMutex
 	cfg      *hotstuffpb.Configuration
 	replicas map[hotstuff.ID]modules.Replica
 }
@@ -121,6 +126,9 @@ func NewConfig(creds credentials.TransportCredentials, opts ...gorums.ManagerOpt
 		},
 		opts: opts,
 	}
+	if r, ok := creds.(*reloadingCredentials); ok {
+		cfg.creds = r.creds
+	}
 	return cfg
 }
 
@@ -137,7 +145,7 @@ func (cfg *Config) replicaConnected(c replicaConnected) {
 		return
 	}
 
-	replica, ok := cfg.replicas[id]
+	replica, ok := cfg.Replica(id)
 	if !ok {
 		cfg.logger.Warnf("Replica with id %d was not found", id)
 		return
@@ -170,7 +178,7 @@ func readMetadata(md metadata.MD) map[string]string {
 
 // GetRawConfiguration returns the underlying gorums RawConfiguration.
 func (cfg *Config) GetRawConfiguration() gorums.RawConfiguration {
-	return cfg.cfg.RawConfiguration
+	return cfg.configuration().RawConfiguration
 }
 
 // ReplicaInfo holds information about a replica.
@@ -183,23 +191,22 @@ type ReplicaInfo struct {
 
 // Connect opens connections to the replicas in the configuration.
 func (cfg *Config) Connect(replicas []ReplicaInfo) (err error) {
-	opts := cfg.opts
-	cfg.opts = nil // options are not needed beyond this point, so we delete them.
-
 	md := mapToMetadata(cfg.subConfig.opts.ConnectionMetadata())
 
 	// embed own ID to allow other replicas to identify messages from this replica
 	md.Set("id", fmt.Sprintf("%d", cfg.subConfig.opts.ID()))
 
-	opts = append(opts, gorums.WithMetadata(md))
+	// the options are kept, since Reconfigure creates a new manager when replicas are removed.
+	cfg.opts = append(cfg.opts, gorums.WithMetadata(md))
 
-	cfg.mgr = hotstuffpb.NewManager(opts...)
+	mgr := hotstuffpb.NewManager(cfg.opts...)
 
 	// set up an ID mapping to give to gorums
 	idMapping := make(map[string]uint32, len(replicas))
+	replicaMap := make(map[hotstuff.ID]modules.Replica, len(replicas))
 	for _, replica := range replicas {
 		// also initialize Replica structures
-		cfg.replicas[replica.ID] = &Replica{
+		replicaMap[replica.ID] = &Replica{
 			eventLoop: cfg.eventLoop,
 			id:        replica.ID,
 			pubKey:    replica.PubKey,
@@ -212,20 +219,27 @@ func (cfg *Config) Connect(replicas []ReplicaInfo) (err error) {
 	}
 
 	// this will connect to the replicas
-	cfg.cfg, err = cfg.mgr.NewConfiguration(qspec{}, gorums.WithNodeMap(idMapping))
+	gorumsCfg, err := mgr.NewConfiguration(qspec{}, gorums.WithNodeMap(idMapping))
 	if err != nil {
+		mgr.Close()
 		return fmt.Errorf("failed to create configuration: %w", err)
 	}
 
 	// now we need to update the "node" field of each replica we connected to
-	for _, node := range cfg.cfg.Nodes() {
+	for _, node := range gorumsCfg.Nodes() {
 		// the node ID should correspond with the replica ID
 		// because we already configured an ID mapping for gorums to use.
 		id := hotstuff.ID(node.ID())
-		replica := cfg.replicas[id].(*Replica)
+		replica := replicaMap[id].(*Replica)
 		replica.node = node
 	}
 
+	cfg.mut.Lock()
+	cfg.mgr = mgr
+	cfg.cfg = gorumsCfg
+	cfg.replicas = replicaMap
+	cfg.mut.Unlock()
+
 	cfg.connected = true
 
 	// this event is sent so that any delayed `replicaConnected` events can be processed.
@@ -234,26 +248,125 @@ func (cfg *Config) Connect(replicas []ReplicaInfo) (err error) {
 	return nil
 }
 
+// Reconfigure replaces the replicas of the configuration with the given validators.
+// Connections to the replicas that remain in the configuration are kept, and the manager
+// connects to the new replicas, such that the replica can keep participating without downtime.
+// The address of a new validator is required, whereas it may be omitted for connected replicas.
+// Since a gorums manager cannot close the connection to a single node, the connections are moved
+// to a new manager when replicas are removed, and the connections of the old manager are closed.
+func (cfg *Config) Reconfigure(validators []hotstuff.Validator) error {
+	cfg.mut.RLock()
+	oldMgr, oldReplicas := cfg.mgr, cfg.replicas
+	cfg.mut.RUnlock()
+
+	replicas := make(map[hotstuff.ID]modules.Replica, len(validators))
+	idMapping := make(map[string]uint32, len(validators))
+	for _, validator := range validators {
+		replica := &Replica{
+			eventLoop: cfg.eventLoop,
+			id:        validator.ID,
+			pubKey:    validator.PublicKey,
+			md:        make(map[string]string),
+		}
+		if old, ok := oldReplicas[validator.ID].(*Replica); ok {
+			replica.md = old.md
+			if replica.pubKey == nil {
+				replica.pubKey = old.pubKey
+			}
+		}
+		replicas[validator.ID] = replica
+
+		if validator.ID == cfg.subConfig.opts.ID() {
+			continue
+		}
+		address := validator.Address
+		if node, ok := oldMgr.Node(uint32(validator.ID)); ok {
+			// gorums reuses the existing node, so its address must be used in the mapping.
+			address = node.Address()
+		}
+		if address == "" {
+			return fmt.Errorf("missing address of replica %d", validator.ID)
+		}
+		idMapping[address] = uint32(validator.ID)
+	}
+
+	mgr := oldMgr
+	removed := false
+	for id := range oldReplicas {
+		if _, ok := replicas[id]; !ok {
+			removed = true
+		}
+	}
+	if removed {
+		mgr = hotstuffpb.NewManager(cfg.opts...)
+	}
+
+	var newCfg *hotstuffpb.Configuration
+	if len(idMapping) > 0 {
+		var err error
+		newCfg, err = mgr.NewConfiguration(qspec{}, gorums.WithNodeMap(idMapping))
+		if err != nil {
+			if mgr != oldMgr {
+				mgr.Close()
+			}
+			return fmt.Errorf("failed to create configuration: %w", err)
+		}
+		for _, node := range newCfg.Nodes() {
+			replicas[hotstuff.ID(node.ID())].(*Replica).node = node
+		}
+	}
+
+	cfg.mut.Lock()
+	cfg.mgr = mgr
+	cfg.cfg = newCfg
+	cfg.replicas = replicas
+	cfg.mut.Unlock()
+
+	if mgr != oldMgr {
+		// messages that are in flight to the old manager's nodes are lost, but the consensus protocol recovers from that.
+		oldMgr.Close()
+	}
+	return nil
+}
+
+// manager returns the current gorums manager.
+func (cfg *Config) manager() *hotstuffpb.Manager {
+	cfg.mut.RLock()
+	defer cfg.mut.RUnlock()
+	return cfg.mgr
+}
+
+// configuration returns the current gorums configuration, which is nil if there are no other replicas.
+func (cfg *subConfig) configuration() *hotstuffpb.Configuration {
+	cfg.mut.RLock()
+	defer cfg.mut.RUnlock()
+	return cfg.cfg
+}
+
 // Replicas returns all of the replicas in the configuration.
+// The returned map is replaced rather than modified when the configuration changes, and must not be modified.
 func (cfg *subConfig) Replicas() map[hotstuff.ID]modules.Replica {
+	cfg.mut.RLock()
+	defer cfg.mut.RUnlock()
 	return cfg.replicas
 }
 
 // Replica returns a replica if it is present in the configuration.
 func (cfg *subConfig) Replica(id hotstuff.ID) (replica modules.Replica, ok bool) {
-	replica, ok = cfg.replicas[id]
+	replica, ok = cfg.Replicas()[id]
 	return
 }
 
 // SubConfig returns a subconfiguration containing the replicas specified in the ids slice.
 func (cfg *Config) SubConfig(ids []hotstuff.ID) (sub modules.Configuration, err error) {
+	all := cfg.Replicas()
 	replicas := make(map[hotstuff.ID]modules.Replica)
 	nids := make([]uint32, len(ids))
 	for i, id := range ids {
 		nids[i] = uint32(id)
-		replicas[id] = cfg.replicas[id]
+		replicas[id] = all[id]
 	}
-	newCfg, err := cfg.mgr.NewConfiguration(qspec{}, gorums.WithNodeIDs(nids))
+	newCfg, err := cfg.manager().NewConfiguration(qspec{}, gorums.WithNodeIDs(nids))
 	if err != nil {
 		return nil, err
 	}
@@ -272,7 +385,7 @@ func (cfg *subConfig) SubConfig(_ []hotstuff.ID) (_ modules.Configuration, err e
 
 // Len returns the number of replicas in the configuration.
 func (cfg *subConfig) Len() int {
-	return len(cfg.replicas)
+	return len(cfg.Replicas())
 }
 
 // QuorumSize returns the size of a quorum
@@ -282,12 +395,13 @@ func (cfg *subConfig) QuorumSize() int {
 
 // Propose sends the block to all replicas in the configuration
 func (cfg *subConfig) Propose(proposal hotstuff.ProposeMsg) {
-	if cfg.cfg == nil {
+	c := cfg.configuration()
+	if c == nil {
 		return
 	}
 	ctx, cancel := synchronizer.TimeoutContext(cfg.eventLoop.Context(), cfg.eventLoop)
 	defer cancel()
-	cfg.cfg.Propose(
+	c.Propose(
 		ctx,
 		hotstuffpb.ProposalToProto(proposal),
 	)
@@ -295,7 +409,8 @@ func (cfg *subConfig) Propose(proposal hotstuff.ProposeMsg) {
 
 // Timeout sends the timeout message to all replicas.
 func (cfg *subConfig) Timeout(msg hotstuff.TimeoutMsg) {
-	if cfg.cfg == nil {
+	c := cfg.configuration()
+	if c == nil {
 		return
 	}
 
@@ -303,15 +418,75 @@ func (cfg *subConfig) Timeout(msg hotstuff.TimeoutMsg) {
 	ctx, cancel := synchronizer.TimeoutContext(cfg.eventLoop.Context(), cfg.eventLoop)
 	defer cancel()
 
-	cfg.cfg.Timeout(
+	c.Timeout(
 		ctx,
 		hotstuffpb.TimeoutMsgToProto(msg),
 	)
 }
 
+// DecryptionShare sends the decryption shares to all replicas in the configuration.
+func (cfg *subConfig) DecryptionShare(msg hotstuff.DecryptionShareMsg) {
+	c := cfg.configuration()
+	if c == nil {
+		return
+	}
+	ctx, cancel := synchronizer.TimeoutContext(cfg.eventLoop.Context(), cfg.eventLoop)
+	defer cancel()
+	c.DecryptionShare(
+		ctx,
+		hotstuffpb.DecryptionSharesToProto(msg),
+	)
+}
+
+// Evidence sends the evidence of a misbehaving replica to all replicas in the configuration.
+func (cfg *subConfig) Evidence(evidence hotstuff.Evidence) {
+	c := cfg.configuration()
+	if c == nil {
+		return
+	}
+	ctx, cancel := synchronizer.TimeoutContext(cfg.eventLoop.Context(), cfg.eventLoop)
+	defer cancel()
+	c.Evidence(
+		ctx,
+		hotstuffpb.EvidenceToProto(evidence),
+	)
+}
+
+// MempoolBatch sends the mempool batch to all replicas in the configuration.
+func (cfg *subConfig) MempoolBatch(batch *hotstuff.MempoolBatch) {
+	c := cfg.configuration()
+	if c == nil {
+		return
+	}
+	// the batches are not tied to a view, so the message must not be canceled by a view change.
+	ctx := cfg.eventLoop.Context()
+	c.MempoolBatch(
+		ctx,
+		hotstuffpb.MempoolBatchToProto(batch),
+	)
+}
+
+// AvailabilityCert sends the availability certificate to all replicas in the configuration.
+func (cfg *subConfig) AvailabilityCert(cert hotstuff.AvailabilityCert) {
+	c := cfg.configuration()
+	if c == nil {
+		return
+	}
+	// the batches are not tied to a view, so the message must not be canceled by a view change.
+	ctx := cfg.eventLoop.Context()
+	c.AvailabilityCert(
+		ctx,
+		hotstuffpb.AvailabilityCertToProto(cert),
+	)
+}
+
 // Fetch requests a block from all the replicas in the configuration
 func (cfg *subConfig) Fetch(ctx context.Context, hash hotstuff.Hash) (*hotstuff.Block, bool) {
-	protoBlock, err := cfg.cfg.Fetch(ctx, &hotstuffpb.BlockHash{Hash: hash[:]})
+	c := cfg.configuration()
+	if c == nil {
+		return nil, false
+	}
+	protoBlock, err := c.Fetch(ctx, &hotstuffpb.BlockHash{Hash: hash[:]})
 	if err != nil {
 		qcErr, ok := err.(gorums.QuorumCallError)
 		// filter out context errors
@@ -323,12 +498,108 @@ func (cfg *subConfig) Fetch(ctx context.Context, hash hotstuff.Hash) (*hotstuff.
 	return hotstuffpb.BlockFromProto(protoBlock), true
 }
 
+// FetchRange requests the blocks with views in the range [from, to] on the branch that ends with the tip block
+// from the replica with the given ID. The blocks are streamed by the replica and returned in ascending order of view.
+func (cfg *Config) FetchRange(ctx context.Context, id hotstuff.ID, tip hotstuff.Hash, from, to hotstuff.View) ([]*hotstuff.Block, error) {
+	// the quorum function collects the streamed blocks, so each call needs its own configuration.
+	q := &rangeQSpec{}
+	node, err := cfg.manager().NewConfiguration(q, gorums.WithNodeIDs([]uint32{uint32(id)}))
+	if err != nil {
+		return nil, err
+	}
+	corr := node.FetchRange(ctx, &hotstuffpb.BlockRange{
+		FromView: uint64(from),
+		ToView:   uint64(to),
+		Tip:      tip[:],
+	})
+	<-corr.Done()
+	if _, _, err := corr.Get(); err != nil {
+		return nil, err
+	}
+	blocks := make([]*hotstuff.Block, len(q.blocks))
+	for i, b := range q.blocks {
+		blocks[i] = hotstuffpb.BlockFromProto(b)
+	}
+	return blocks, nil
+}
+
+// node returns the node of the replica with the given ID.
+func (cfg *Config) node(id hotstuff.ID) (*hotstuffpb.Node, error) {
+	replica, ok := cfg.Replicas()[id].(*Replica)
+	if !ok || replica.node == nil {
+		return nil, fmt.Errorf("replica %d not found", id)
+	}
+	return replica.node, nil
+}
+
+// FetchCheckpoint requests the checkpoint of the block with the given hash from the replica with the given ID.
+func (cfg *Config) FetchCheckpoint(ctx context.Context, id hotstuff.ID, hash hotstuff.Hash) (hotstuff.Checkpoint, error) {
+	node, err := cfg.node(id)
+	if err != nil {
+		return hotstuff.Checkpoint{}, err
+	}
+	req := &hotstuffpb.CheckpointRequest{}
+	if hash != (hotstuff.Hash{}) {
+		req.Hash = hash[:]
+	}
+	checkpoint, err := node.FetchCheckpoint(ctx, req)
+	if err != nil {
+		return hotstuff.Checkpoint{}, err
+	}
+	return hotstuffpb.CheckpointFromProto(checkpoint), nil
+}
+
+// FetchTrieNodes requests the encoded trie nodes with the given hashes from the replica with the given ID.
+func (cfg *Config) FetchTrieNodes(ctx context.Context, id hotstuff.ID, hashes []hotstuff.Hash) ([][]byte, error) {
+	node, err := cfg.node(id)
+	if err != nil {
+		return nil, err
+	}
+	req := &hotstuffpb.TrieNodesRequest{Hashes: make([][]byte, len(hashes))}
+	for i := range hashes {
+		req.Hashes[i] = hashes[i][:]
+	}
+	nodes, err := node.FetchTrieNodes(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return nodes.GetNodes(), nil
+}
+
+// AvailabilityVote sends the availability vote to the author of the batch.
+func (cfg *Config) AvailabilityVote(author hotstuff.ID, vote hotstuff.AvailabilityVoteMsg) {
+	node, err := cfg.node(author)
+	if err != nil {
+		return
+	}
+	node.AvailabilityVote(cfg.eventLoop.Context(), hotstuffpb.AvailabilityVoteToProto(vote))
+}
+
+// FetchMempoolBatch requests the mempool batch with the given digest from the replica with the given ID.
+func (cfg *Config) FetchMempoolBatch(ctx context.Context, id hotstuff.ID, digest hotstuff.Hash) (*hotstuff.MempoolBatch, error) {
+	node, err := cfg.node(id)
+	if err != nil {
+		return nil, err
+	}
+	batch, err := node.FetchMempoolBatch(ctx, &hotstuffpb.BlockHash{Hash: digest[:]})
+	if err != nil {
+		return nil, err
+	}
+	return hotstuffpb.MempoolBatchFromProto(batch), nil
+}
+
 // Close closes all connections made by this configuration.
 func (cfg *Config) Close() {
-	cfg.mgr.Close()
+	if mgr := cfg.manager(); mgr != nil {
+		mgr.Close()
+	}
 }
 
 var _ modules.Configuration = (*Config)(nil)
+var _ modules.RangeFetcher = (*Config)(nil)
+var _ modules.SnapshotFetcher = (*Config)(nil)
+var _ modules.MempoolSender = (*Config)(nil)
+var _ modules.Reconfigurable = (*Config)(nil)
 
 type qspec struct{}
 
@@ -346,5 +617,31 @@ func (q qspec) FetchQF(in *hotstuffpb.BlockHash, replies map[uint32]*hotstuffpb.
 	return nil, false
 }
 
+// FetchRangeQF is the quorum function for the FetchRange correctable stream method.
+// It returns true once the final part of the stream has been received.
+func (q qspec) FetchRangeQF(_ *hotstuffpb.BlockRange, replies map[uint32]*hotstuffpb.Blocks) (*hotstuffpb.Blocks, int, bool) {
+	for _, r := range replies {
+		return r, len(r.GetBlocks()), r.GetLast()
+	}
+	return nil, 0, false
+}
+
+// rangeQSpec collects the blocks streamed by a single replica in response to FetchRange.
+// The quorum function is called once for each part of the stream.
+type rangeQSpec struct {
+	qspec
+	blocks []*hotstuffpb.Block
+}
+
+// FetchRangeQF appends the latest part of the stream to the collected blocks.
+// It returns true once the final part of the stream has been received.
+func (q *rangeQSpec) FetchRangeQF(_ *hotstuffpb.BlockRange, replies map[uint32]*hotstuffpb.Blocks) (*hotstuffpb.Blocks, int, bool) {
+	for _, r := range replies {
+		q.blocks = append(q.blocks, r.GetBlocks()...)
+		return r, len(q.blocks), r.GetLast()
+	}
+	return nil, 0, false
+}
+
 // ConnectedEvent is sent when the configuration has connected to the other replicas.
 type ConnectedEvent struct{}
@@ -0,0 +1,439 @@
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/logging"
+	"google.golang.org/grpc/credentials"
+)
+
+// reloadDelay is the time to wait after a credential file has changed before the files are reloaded,
+// such that the certificate and the key can be replaced one after the other.
+const reloadDelay = 100 * time.Millisecond
+
+// CredentialFiles are the files that the TLS credentials of a replica are loaded from.
+type CredentialFiles struct {
+	Certificate string // The PEM encoded certificate chain of the replica.
+	Key         string // The PEM encoded private key of the certificate.
+	CA          string // The PEM encoded certificates of the trusted certificate authorities.
+	// Optional PEM or DER encoded certificate revocation lists.
+	// Each list must be signed by one of the trusted certificate authorities.
+	// When the next update of a list is due, the certificates of its issuer are rejected until the list is replaced.
+	CRL string
+	// Optional list of peers that are denied, with one replica ID or hex encoded SHA-256 certificate fingerprint per line.
+	// Empty lines and lines that start with # are ignored.
+	DenyList string
+}
+
+// Credentials are the TLS credentials of a replica, which can be reloaded while the replica is running.
+//
+// The certificate of a peer is verified against the current certificate authorities, revocation lists,
+// and deny list when a connection is established. The revocation lists and the deny list are checked again
+// for each request by GetPeerIDFromContext, such that a reload also applies to established connections.
+type Credentials struct {
+	files CredentialFiles // empty if the credentials were not loaded from files.
+	state atomic.Pointer[credentialState]
+}
+
+// credentialState is a snapshot of the credentials, which is replaced as a whole when the files are reloaded.
+type credentialState struct {
+	certificate *tls.Certificate
+	roots       *x509.CertPool
+	revoked     map[string]struct{}  // the issuer and serial number of revoked certificates; see revocationKey.
+	nextUpdate  map[string]time.Time // the time that the revocation list of each issuer expires, keyed by the raw issuer.
+	deniedIDs   map[hotstuff.ID]struct{}
+	deniedCerts map[[sha256.Size]byte]struct{}
+}
+
+// NewCredentials returns credentials that use the given certificate and trust the given certificate authorities.
+// The credentials cannot be reloaded.
+func NewCredentials(certificate *tls.Certificate, roots *x509.CertPool) *Credentials {
+	c := &Credentials{}
+	c.state.Store(&credentialState{certificate: certificate, roots: roots})
+	return c
+}
+
+// LoadCredentials loads credentials from the given files.
+func LoadCredentials(files CredentialFiles) (*Credentials, error) {
+	c := &Credentials{files: files}
+	if err := c.Reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Reload reads the credential files again. If any of the files cannot be read,
+// an error is returned and the previous credentials are still used.
+func (c *Credentials) Reload() error {
+	if c.files.Certificate == "" {
+		return errors.New("credentials were not loaded from files")
+	}
+	s, err := loadCredentialState(c.files)
+	if err != nil {
+		return err
+	}
+	c.state.Store(s)
+	return nil
+}
+
+// Watch reloads the credentials whenever one of the credential files changes, until the context is canceled.
+// The directories of the files are watched, such that files that are replaced by renaming are also detected.
+func (c *Credentials) Watch(ctx context.Context, logger logging.Logger) error {
+	if c.files.Certificate == "" {
+		return errors.New("credentials were not loaded from files")
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	files := make(map[string]struct{})
+	dirs := make(map[string]struct{})
+	for _, file := range []string{c.files.Certificate, c.files.Key, c.files.CA, c.files.CRL, c.files.DenyList} {
+		if file == "" {
+			continue
+		}
+		file = filepath.Clean(file)
+		files[file] = struct{}{}
+		dir := filepath.Dir(file)
+		if _, ok := dirs[dir]; ok {
+			continue
+		}
+		dirs[dir] = struct{}{}
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+			return fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+		reload := time.NewTimer(reloadDelay)
+		reload.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if _, ok := files[filepath.Clean(event.Name)]; ok && !event.Has(fsnotify.Chmod) {
+					reload.Reset(reloadDelay)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Warnf("Error while watching the TLS credentials: %v", err)
+			case <-reload.C:
+				if err := c.Reload(); err != nil {
+					logger.Errorf("Failed to reload the TLS credentials, using the previous credentials: %v", err)
+					continue
+				}
+				logger.Info("Reloaded the TLS credentials")
+			}
+		}
+	}()
+	return nil
+}
+
+// ServerCredentials returns transport credentials for a gRPC server, which use the current credentials for each connection.
+// The clientAuth parameter controls whether the clients must present a certificate.
+func (c *Credentials) ServerCredentials(clientAuth tls.ClientAuthType) credentials.TransportCredentials {
+	return &reloadingCredentials{
+		creds: c,
+		current: func() credentials.TransportCredentials {
+			return credentials.NewTLS(c.state.Load().serverConfig(clientAuth))
+		},
+	}
+}
+
+// ClientCredentials returns transport credentials for a gRPC client, which use the current credentials for each connection.
+func (c *Credentials) ClientCredentials() credentials.TransportCredentials {
+	return &reloadingCredentials{
+		creds: c,
+		current: func() credentials.TransportCredentials {
+			return credentials.NewTLS(c.state.Load().clientConfig())
+		},
+	}
+}
+
+func loadCredentialState(files CredentialFiles) (*credentialState, error) {
+	certificate, err := tls.LoadX509KeyPair(files.Certificate, files.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TLS certificate: %w", err)
+	}
+	b, err := os.ReadFile(files.CA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate authority: %w", err)
+	}
+	cas, err := parseCertificates(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", files.CA, err)
+	}
+	s := &credentialState{
+		certificate: &certificate,
+		roots:       x509.NewCertPool(),
+	}
+	for _, ca := range cas {
+		s.roots.AddCert(ca)
+	}
+	if files.CRL != "" {
+		s.revoked, s.nextUpdate, err = readRevocationLists(files.CRL, cas)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", files.CRL, err)
+		}
+	}
+	if files.DenyList != "" {
+		s.deniedIDs, s.deniedCerts, err = readDenyList(files.DenyList)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", files.DenyList, err)
+		}
+	}
+	return s, nil
+}
+
+func (s *credentialState) serverConfig(clientAuth tls.ClientAuthType) *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{*s.certificate},
+		ClientCAs:    s.roots,
+		ClientAuth:   clientAuth,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			return s.checkPeer(cs.VerifiedChains)
+		},
+	}
+}
+
+func (s *credentialState) clientConfig() *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{*s.certificate},
+		RootCAs:      s.roots,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			return s.checkPeer(cs.VerifiedChains)
+		},
+	}
+}
+
+// CheckPeer returns an error if the certificate of the peer of the TLS connection is denied or revoked
+// by the current credentials.
+func (c *Credentials) CheckPeer(state tls.ConnectionState) error {
+	return c.state.Load().checkPeer(state.VerifiedChains)
+}
+
+// checkPeer returns an error if the verified certificate of a peer is denied or revoked,
+// or if the revocation list of one of its issuers has expired.
+func (s *credentialState) checkPeer(chains [][]*x509.Certificate) error {
+	if len(chains) == 0 {
+		// the peer did not present a certificate, which is only allowed by the client server.
+		return nil
+	}
+	leaf := chains[0][0]
+	if _, ok := s.deniedCerts[sha256.Sum256(leaf.Raw)]; ok {
+		return fmt.Errorf("certificate %X is denied", leaf.SerialNumber)
+	}
+	if id, err := PeerIDFromCertificate(leaf); err == nil {
+		if _, ok := s.deniedIDs[id]; ok {
+			return fmt.Errorf("replica %d is denied", id)
+		}
+	}
+	for _, chain := range chains {
+		for _, cert := range chain {
+			if _, ok := s.revoked[revocationKey(cert.RawIssuer, cert.SerialNumber)]; ok {
+				return fmt.Errorf("certificate %X issued by %q is revoked", cert.SerialNumber, cert.Issuer)
+			}
+			if next, ok := s.nextUpdate[string(cert.RawIssuer)]; ok && time.Now().After(next) {
+				return fmt.Errorf("revocation list of %q expired at %v", cert.Issuer, next)
+			}
+		}
+	}
+	return nil
+}
+
+func revocationKey(rawIssuer []byte, serial *big.Int) string {
+	return string(rawIssuer) + serial.String()
+}
+
+func parseCertificates(b []byte) (certs []*x509.Certificate, err error) {
+	for {
+		var block *pem.Block
+		block, b = pem.Decode(b)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificates found")
+	}
+	return certs, nil
+}
+
+// readRevocationLists returns the revoked certificates of the revocation lists in the file,
+// and the time of the next update of each list, keyed by the raw issuer.
+// Lists whose next update is already due are rejected.
+func readRevocationLists(file string, cas []*x509.Certificate) (map[string]struct{}, map[string]time.Time, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	var ders [][]byte
+	for rest := b; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type == "X509 CRL" {
+			ders = append(ders, block.Bytes)
+		}
+	}
+	if len(ders) == 0 {
+		// not PEM encoded, so the file should contain a single DER encoded list.
+		ders = append(ders, b)
+	}
+	revoked := make(map[string]struct{})
+	nextUpdate := make(map[string]time.Time)
+	for _, der := range ders {
+		list, err := x509.ParseRevocationList(der)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !signedByAny(list, cas) {
+			return nil, nil, fmt.Errorf("revocation list of %q is not signed by a trusted certificate authority", list.Issuer)
+		}
+		if !list.NextUpdate.IsZero() {
+			if time.Now().After(list.NextUpdate) {
+				return nil, nil, fmt.Errorf("revocation list of %q expired at %v", list.Issuer, list.NextUpdate)
+			}
+			// with several lists from the same issuer, the list that expires first decides.
+			if next, ok := nextUpdate[string(list.RawIssuer)]; !ok || list.NextUpdate.Before(next) {
+				nextUpdate[string(list.RawIssuer)] = list.NextUpdate
+			}
+		}
+		for _, entry := range list.RevokedCertificateEntries {
+			revoked[revocationKey(list.RawIssuer, entry.SerialNumber)] = struct{}{}
+		}
+	}
+	return revoked, nextUpdate, nil
+}
+
+func signedByAny(list *x509.RevocationList, cas []*x509.Certificate) bool {
+	for _, ca := range cas {
+		if bytes.Equal(list.RawIssuer, ca.RawSubject) && list.CheckSignatureFrom(ca) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// readDenyList returns the replica IDs and certificate fingerprints in the deny list.
+func readDenyList(file string) (ids map[hotstuff.ID]struct{}, certs map[[sha256.Size]byte]struct{}, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	ids = make(map[hotstuff.ID]struct{})
+	certs = make(map[[sha256.Size]byte]struct{})
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		if id, err := strconv.ParseUint(entry, 10, 32); err == nil {
+			ids[hotstuff.ID(id)] = struct{}{}
+			continue
+		}
+		fingerprint, err := hex.DecodeString(strings.ReplaceAll(entry, ":", ""))
+		if err != nil || len(fingerprint) != sha256.Size {
+			return nil, nil, fmt.Errorf("line %d: expected a replica ID or a SHA-256 fingerprint: %q", line, entry)
+		}
+		certs[[sha256.Size]byte(fingerprint)] = struct{}{}
+	}
+	return ids, certs, scanner.Err()
+}
+
+// PeerIDFromCertificate returns the ID of the replica that the certificate was issued to.
+// The ID is taken from the replica URI in the subject alternative names of the certificate (see hotstuff.ID.URI).
+// Certificates without a replica URI are identified by their common name.
+func PeerIDFromCertificate(cert *x509.Certificate) (hotstuff.ID, error) {
+	var (
+		id    hotstuff.ID
+		found bool
+	)
+	for _, uri := range cert.URIs {
+		uriID, ok := hotstuff.IDFromURI(uri)
+		if !ok {
+			continue
+		}
+		if found && uriID != id {
+			return 0, fmt.Errorf("certificate identifies both replica %d and replica %d", id, uriID)
+		}
+		id, found = uriID, true
+	}
+	if found {
+		return id, nil
+	}
+	subject, err := strconv.ParseUint(cert.Subject.CommonName, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("certificate does not identify a replica: %q", cert.Subject.CommonName)
+	}
+	return hotstuff.ID(subject), nil
+}
+
+var _ credentials.TransportCredentials = (*reloadingCredentials)(nil)
+
+// reloadingCredentials performs each handshake with the credentials that are current at the time of the handshake.
+type reloadingCredentials struct {
+	creds   *Credentials
+	current func() credentials.TransportCredentials
+}
+
+func (r *reloadingCredentials) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return r.current().ClientHandshake(ctx, authority, conn)
+}
+
+func (r *reloadingCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return r.current().ServerHandshake(conn)
+}
+
+func (r *reloadingCredentials) Info() credentials.ProtocolInfo {
+	return r.current().Info()
+}
+
+func (r *reloadingCredentials) Clone() credentials.TransportCredentials {
+	return &reloadingCredentials{creds: r.creds, current: r.current}
+}
+
+// OverrideServerName is deprecated in gRPC, and is not supported.
+func (r *reloadingCredentials) OverrideServerName(string) error {
+	return errors.New("overriding the server name is not supported")
+}
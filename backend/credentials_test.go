@@ -0,0 +1,327 @@
+package backend
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/crypto/keygen"
+	"github.com/relab/hotstuff/logging"
+)
+
+type testCA struct {
+	key  *ecdsa.PrivateKey
+	cert *x509.Certificate
+}
+
+func newTestCA(t *testing.T) testCA {
+	t.Helper()
+	key, cert, err := keygen.GenerateCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testCA{key, cert}
+}
+
+// writeCredentialFiles writes a new certificate for the replica, signed by the CA, and the trusted CAs to dir.
+func writeCredentialFiles(t *testing.T, dir string, id hotstuff.ID, ca testCA, trusted ...testCA) CredentialFiles {
+	t.Helper()
+	keyChain, err := keygen.GenerateKeyChain(id, []string{"localhost"}, "ecdsa", ca.cert, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var caPEM []byte
+	for _, ca := range trusted {
+		caPEM = append(caPEM, keygen.CertToPEM(ca.cert)...)
+	}
+	files := CredentialFiles{
+		Certificate: filepath.Join(dir, "tls.crt"),
+		Key:         filepath.Join(dir, "tls.key"),
+		CA:          filepath.Join(dir, "ca.crt"),
+	}
+	writeFile(t, files.Certificate, keyChain.Certificate)
+	writeFile(t, files.Key, keyChain.CertificateKey)
+	writeFile(t, files.CA, caPEM)
+	return files
+}
+
+func writeFile(t *testing.T, file string, b []byte) {
+	t.Helper()
+	// replace the file by renaming, like most tools that rotate certificates do.
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func loadCertificate(t *testing.T, files CredentialFiles) *x509.Certificate {
+	t.Helper()
+	cert, err := keygen.ReadCertFile(files.Certificate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func loadCredentials(t *testing.T, files CredentialFiles) *Credentials {
+	t.Helper()
+	c, err := LoadCredentials(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// handshake connects a client with the client credentials to a server with the server credentials,
+// and returns an error if either side rejected the handshake.
+func handshake(t *testing.T, server, client *Credentials) error {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		_, _, err = server.ServerCredentials(tls.RequireAndVerifyClientCert).ServerHandshake(conn)
+		serverErr <- err
+	}()
+
+	conn, err := net.Dial("tcp", lis.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	tlsConn, _, clientErr := client.ClientCredentials().ClientHandshake(context.Background(), "localhost", conn)
+	if clientErr == nil {
+		// with TLS 1.3, the client finishes the handshake before the server has verified its certificate,
+		// so the connection is closed only when the server rejects the certificate.
+		_, _ = tlsConn.Read(make([]byte, 1))
+		_ = tlsConn.Close()
+	}
+	return errors.Join(clientErr, <-serverErr)
+}
+
+func TestCredentialsReload(t *testing.T) {
+	ca := newTestCA(t)
+	serverFiles := writeCredentialFiles(t, t.TempDir(), 1, ca, ca)
+	clientFiles := writeCredentialFiles(t, t.TempDir(), 2, ca, ca)
+	server := loadCredentials(t, serverFiles)
+	client := loadCredentials(t, clientFiles)
+
+	if err := handshake(t, server, client); err != nil {
+		t.Fatalf("Handshake failed: %v", err)
+	}
+
+	// rotate the certificate of the server to one that is signed by a new CA, which the client does not trust yet.
+	newCA := newTestCA(t)
+	writeCredentialFiles(t, filepath.Dir(serverFiles.Certificate), 1, newCA, ca, newCA)
+	if err := server.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(t, server, client); err == nil {
+		t.Fatal("Handshake succeeded with a certificate from an untrusted CA")
+	}
+	writeFile(t, clientFiles.CA, append(keygen.CertToPEM(ca.cert), keygen.CertToPEM(newCA.cert)...))
+	if err := client.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(t, server, client); err != nil {
+		t.Fatalf("Handshake failed after the client trusts the new CA: %v", err)
+	}
+
+	// a failed reload keeps the previous credentials.
+	writeFile(t, serverFiles.Key, []byte("not a key"))
+	if err := server.Reload(); err == nil {
+		t.Fatal("Reload succeeded with an invalid key")
+	}
+	if err := handshake(t, server, client); err != nil {
+		t.Fatalf("Handshake failed after a failed reload: %v", err)
+	}
+}
+
+func TestCredentialsRevocation(t *testing.T) {
+	ca := newTestCA(t)
+	serverFiles := writeCredentialFiles(t, t.TempDir(), 1, ca, ca)
+	clientFiles := writeCredentialFiles(t, t.TempDir(), 2, ca, ca)
+	clientCert := loadCertificate(t, clientFiles)
+	client := loadCredentials(t, clientFiles)
+
+	dir := filepath.Dir(serverFiles.Certificate)
+	serverFiles.CRL = filepath.Join(dir, "ca.crl")
+	serverFiles.DenyList = filepath.Join(dir, "deny.list")
+
+	writeCRLUntil := func(t *testing.T, signer testCA, nextUpdate time.Time, serials ...*big.Int) {
+		t.Helper()
+		tmpl := &x509.RevocationList{Number: big.NewInt(1), ThisUpdate: time.Now().Add(-time.Hour), NextUpdate: nextUpdate}
+		for _, sn := range serials {
+			tmpl.RevokedCertificateEntries = append(tmpl.RevokedCertificateEntries, x509.RevocationListEntry{SerialNumber: sn, RevocationTime: time.Now()})
+		}
+		der, err := x509.CreateRevocationList(rand.Reader, tmpl, signer.cert, signer.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, serverFiles.CRL, pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}))
+	}
+	writeCRL := func(t *testing.T, signer testCA, serials ...*big.Int) {
+		t.Helper()
+		writeCRLUntil(t, signer, time.Now().Add(time.Hour), serials...)
+	}
+	fingerprint := sha256.Sum256(clientCert.Raw)
+
+	tests := []struct {
+		name     string
+		crl      func(t *testing.T)
+		denyList string
+		wantErr  bool
+	}{
+		{name: "Allowed", crl: func(t *testing.T) { writeCRL(t, ca) }, denyList: "# no one\n", wantErr: false},
+		{name: "RevokedCertificate", crl: func(t *testing.T) { writeCRL(t, ca, clientCert.SerialNumber) }, wantErr: true},
+		{name: "DeniedID", crl: func(t *testing.T) { writeCRL(t, ca) }, denyList: "3\n2\n", wantErr: true},
+		{name: "DeniedFingerprint", crl: func(t *testing.T) { writeCRL(t, ca) }, denyList: hex.EncodeToString(fingerprint[:]), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.crl(t)
+			writeFile(t, serverFiles.DenyList, []byte(tt.denyList))
+			server := loadCredentials(t, serverFiles)
+			if err := handshake(t, server, client); (err != nil) != tt.wantErr {
+				t.Errorf("handshake() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("UntrustedCRL", func(t *testing.T) {
+		writeCRL(t, newTestCA(t), clientCert.SerialNumber)
+		if _, err := LoadCredentials(serverFiles); err == nil {
+			t.Error("Loaded a revocation list that was not signed by a trusted CA")
+		}
+	})
+
+	// the peers of established connections are checked against the current credentials on each request.
+	state := tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{clientCert, ca.cert}}}
+
+	t.Run("ExpiredCRL", func(t *testing.T) {
+		writeFile(t, serverFiles.DenyList, nil)
+		writeCRLUntil(t, ca, time.Now().Add(-time.Minute))
+		if _, err := LoadCredentials(serverFiles); err == nil {
+			t.Error("Loaded a revocation list whose next update is due")
+		}
+		writeCRL(t, ca)
+		server := loadCredentials(t, serverFiles)
+		if err := server.CheckPeer(state); err != nil {
+			t.Fatalf("CheckPeer() error = %v", err)
+		}
+		// the list expires while the replica is running.
+		server.state.Load().nextUpdate[string(ca.cert.RawSubject)] = time.Now().Add(-time.Second)
+		if err := server.CheckPeer(state); err == nil {
+			t.Error("Accepted a peer after the revocation list of its issuer expired")
+		}
+	})
+
+	t.Run("ReloadedDenyList", func(t *testing.T) {
+		writeCRL(t, ca)
+		writeFile(t, serverFiles.DenyList, nil)
+		server := loadCredentials(t, serverFiles)
+		if err := server.CheckPeer(state); err != nil {
+			t.Fatalf("CheckPeer() error = %v", err)
+		}
+		writeFile(t, serverFiles.DenyList, []byte("2\n"))
+		if err := server.Reload(); err != nil {
+			t.Fatal(err)
+		}
+		if err := server.CheckPeer(state); err == nil {
+			t.Error("Accepted a peer that was denied after the connection was established")
+		}
+	})
+}
+
+func TestCredentialsWatch(t *testing.T) {
+	ca := newTestCA(t)
+	serverFiles := writeCredentialFiles(t, t.TempDir(), 1, ca, ca)
+	clientFiles := writeCredentialFiles(t, t.TempDir(), 2, ca, ca)
+	serverFiles.DenyList = filepath.Join(filepath.Dir(serverFiles.Certificate), "deny.list")
+	writeFile(t, serverFiles.DenyList, nil)
+	server := loadCredentials(t, serverFiles)
+	client := loadCredentials(t, clientFiles)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := server.Watch(ctx, logging.New("test")); err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(t, server, client); err != nil {
+		t.Fatalf("Handshake failed: %v", err)
+	}
+
+	writeFile(t, serverFiles.DenyList, []byte("2\n"))
+	deadline := time.Now().Add(5 * time.Second)
+	for handshake(t, server, client) == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("Denied replica was not rejected after the deny list changed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPeerIDFromCertificate(t *testing.T) {
+	uri := func(s string) *url.URL {
+		u, err := url.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+	tests := []struct {
+		name       string
+		commonName string
+		uris       []*url.URL
+		want       hotstuff.ID
+		wantErr    bool
+	}{
+		{name: "URI", commonName: "2", uris: []*url.URL{hotstuff.ID(1).URI()}, want: 1},
+		{name: "OtherURIs", uris: []*url.URL{uri("spiffe://example.org/replica"), uri("hotstuff:replica:3")}, want: 3},
+		{name: "CommonName", commonName: "4", want: 4},
+		{name: "ConflictingURIs", uris: []*url.URL{hotstuff.ID(1).URI(), hotstuff.ID(2).URI()}, wantErr: true},
+		{name: "NoID", commonName: "replica", uris: []*url.URL{uri("hotstuff:replica:x")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert := &x509.Certificate{Subject: pkix.Name{CommonName: tt.commonName}, URIs: tt.uris}
+			got, err := PeerIDFromCertificate(cert)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PeerIDFromCertificate() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("PeerIDFromCertificate() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
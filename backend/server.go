@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"slices"
+	"sort"
 	"strconv"
+	"sync"
 
 	"github.com/relab/hotstuff/eventloop"
 	"github.com/relab/hotstuff/logging"
@@ -14,6 +17,7 @@ import (
 	"github.com/relab/hotstuff"
 	"github.com/relab/hotstuff/internal/latency"
 	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	"golang.org/x/time/rate"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
@@ -32,6 +36,11 @@ type Server struct {
 	lm            latency.Matrix
 	gorumsSrv     *gorums.Server
 	opts          *modules.Options
+	snapshots     modules.SnapshotProvider
+	mempool       modules.MempoolProvider
+
+	rangeMut   sync.Mutex
+	rangePeers map[hotstuff.ID]*rangePeer
 }
 
 // InitModule initializes the Server.
@@ -43,6 +52,8 @@ func (srv *Server) InitModule(mods *modules.Core) {
 		&srv.logger,
 		&srv.opts,
 	)
+	mods.TryGet(&srv.snapshots)
+	mods.TryGet(&srv.mempool)
 }
 
 // NewServer creates a new Server.
@@ -52,8 +63,9 @@ func NewServer(opts ...ServerOptions) *Server {
 		opt(options)
 	}
 	srv := &Server{
-		id: options.id,
-		lm: options.latencyMatrix,
+		id:         options.id,
+		lm:         options.latencyMatrix,
+		rangePeers: make(map[hotstuff.ID]*rangePeer),
 	}
 	options.gorumsSrvOpts = append(options.gorumsSrvOpts, gorums.WithConnectCallback(func(ctx context.Context) {
 		srv.eventLoop.AddEvent(replicaConnected{ctx})
@@ -100,6 +112,9 @@ func (srv *Server) StartOnListener(listener net.Listener) {
 }
 
 // GetPeerIDFromContext extracts the ID of the peer from the context.
+// If the configuration was created with Credentials, the certificate of the peer is checked against
+// the current revocation lists and deny list, such that a peer is rejected as soon as the credentials
+// are reloaded, even if its connection was established before.
 func GetPeerIDFromContext(ctx context.Context, cfg modules.Configuration) (hotstuff.ID, error) {
 	peerInfo, ok := peer.FromContext(ctx)
 	if !ok {
@@ -111,15 +126,22 @@ func GetPeerIDFromContext(ctx context.Context, cfg modules.Configuration) (hotst
 		if !ok {
 			return 0, fmt.Errorf("authInfo of wrong type: %T", peerInfo.AuthInfo)
 		}
-		if len(tlsInfo.State.PeerCertificates) > 0 {
-			cert := tlsInfo.State.PeerCertificates[0]
-			for replicaID := range cfg.Replicas() {
-				if subject, err := strconv.Atoi(cert.Subject.CommonName); err == nil && hotstuff.ID(subject) == replicaID {
-					return replicaID, nil
-				}
+		if len(tlsInfo.State.PeerCertificates) == 0 {
+			return 0, fmt.Errorf("could not find matching certificate")
+		}
+		if c, ok := cfg.(*Config); ok && c.creds != nil {
+			if err := c.creds.CheckPeer(tlsInfo.State); err != nil {
+				return 0, err
 			}
 		}
-		return 0, fmt.Errorf("could not find matching certificate")
+		id, err := PeerIDFromCertificate(tlsInfo.State.PeerCertificates[0])
+		if err != nil {
+			return 0, err
+		}
+		if _, ok := cfg.Replicas()[id]; !ok {
+			return 0, fmt.Errorf("replica %d is not in the configuration", id)
+		}
+		return id, nil
 	}
 
 	// If we're not using TLS, we'll fallback to checking the metadata
@@ -211,6 +233,191 @@ func (impl *serviceImpl) Fetch(_ gorums.ServerCtx, pb *hotstuffpb.BlockHash) (*h
 	return hotstuffpb.BlockToProto(block), nil
 }
 
+const (
+	// fetchRangeChunkSize is the maximum number of blocks sent in each part of a FetchRange stream.
+	fetchRangeChunkSize = 16
+	// maxFetchRangeViews is the maximum number of views that can be requested in a single FetchRange request.
+	maxFetchRangeViews = 1000
+	// fetchRangeRate is the number of FetchRange requests per second that each replica may send,
+	// and fetchRangeBurst is the number of requests that it may send at once.
+	fetchRangeRate  = 10
+	fetchRangeBurst = 20
+	// maxBranchViews is the maximum number of visited blocks that are remembered for each replica.
+	maxBranchViews = 10 * maxFetchRangeViews
+)
+
+// rangePeer is the FetchRange state of a replica.
+type rangePeer struct {
+	mut     sync.Mutex
+	limiter *rate.Limiter
+	// tip is the tip of the branch that the replica last requested blocks from.
+	tip hotstuff.Hash
+	// visited holds the blocks on the branch that have been visited, in descending order of view.
+	// Only the last maxBranchViews blocks are kept, and truncated is set if any were dropped.
+	visited   []visitedBlock
+	truncated bool
+	// next is the next block to visit when walking down the branch, or nil if the walk reached the genesis block.
+	next *hotstuff.Block
+}
+
+type visitedBlock struct {
+	view hotstuff.View
+	hash hotstuff.Hash
+}
+
+// rangePeer returns the FetchRange state of the replica.
+func (srv *Server) rangePeer(id hotstuff.ID) *rangePeer {
+	srv.rangeMut.Lock()
+	defer srv.rangeMut.Unlock()
+	p, ok := srv.rangePeers[id]
+	if !ok {
+		p = &rangePeer{limiter: rate.NewLimiter(fetchRangeRate, fetchRangeBurst)}
+		srv.rangePeers[id] = p
+	}
+	return p
+}
+
+// branch returns the hashes of the blocks with views in [from, to] on the branch that ends with the tip,
+// in ascending order of view. The visited part of the branch is remembered, such that the replica's
+// requests for the other ranges of the same branch continue the walk instead of starting from the tip.
+// If the requested range is above the blocks that are remembered, the walk starts over from the tip.
+func (srv *Server) branch(p *rangePeer, tip hotstuff.Hash, from, to hotstuff.View) ([]hotstuff.Hash, error) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	if p.visited == nil || p.tip != tip || (p.truncated && to > p.visited[0].view) {
+		block, ok := srv.blockChain.LocalGet(tip)
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "requested tip was not found")
+		}
+		p.tip, p.visited, p.truncated, p.next = tip, make([]visitedBlock, 0, 1), false, block
+	}
+	for p.next != nil && p.next.View() >= from {
+		p.visited = append(p.visited, visitedBlock{p.next.View(), p.next.Hash()})
+		if len(p.visited) > maxBranchViews {
+			p.visited = p.visited[len(p.visited)-maxBranchViews:]
+			p.truncated = true
+		}
+		if p.next.View() == 0 {
+			p.next = nil
+			break
+		}
+		parent, ok := srv.blockChain.LocalGet(p.next.Parent())
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "requested range was not found")
+		}
+		p.next = parent
+	}
+
+	// the first block with a view of at most to.
+	i := sort.Search(len(p.visited), func(i int) bool { return p.visited[i].view <= to })
+	var hashes []hotstuff.Hash
+	for ; i < len(p.visited) && p.visited[i].view >= from; i++ {
+		hashes = append(hashes, p.visited[i].hash)
+	}
+	slices.Reverse(hashes)
+	return hashes, nil
+}
+
+// FetchRange handles an incoming request for a range of blocks.
+// The blocks are found by following the parent links from the requested tip,
+// and are streamed in ascending order of view.
+// Each replica may request at most maxFetchRangeViews views at a time, and is limited to fetchRangeRate requests per second.
+func (impl *serviceImpl) FetchRange(ctx gorums.ServerCtx, req *hotstuffpb.BlockRange, send func(*hotstuffpb.Blocks) error) error {
+	id, err := GetPeerIDFromContext(ctx, impl.srv.configuration)
+	if err != nil {
+		return status.Errorf(codes.PermissionDenied, "could not get replica ID: %v", err)
+	}
+	// allow other requests to be processed while the blocks are streamed.
+	ctx.Release()
+
+	var tip hotstuff.Hash
+	copy(tip[:], req.GetTip())
+	from, to := hotstuff.View(req.GetFromView()), hotstuff.View(req.GetToView())
+	if to < from || to-from >= maxFetchRangeViews {
+		return status.Errorf(codes.InvalidArgument, "the range must contain between 1 and %d views", maxFetchRangeViews)
+	}
+
+	rp := impl.srv.rangePeer(id)
+	if !rp.limiter.Allow() {
+		return status.Errorf(codes.ResourceExhausted, "too many requests")
+	}
+
+	hashes, err := impl.srv.branch(rp, tip, from, to)
+	if err != nil {
+		return err
+	}
+	blocks := make([]*hotstuff.Block, 0, len(hashes))
+	for _, hash := range hashes {
+		block, ok := impl.srv.blockChain.LocalGet(hash)
+		if !ok {
+			return status.Errorf(codes.NotFound, "requested range was not found")
+		}
+		blocks = append(blocks, block)
+	}
+
+	impl.srv.logger.Debugf("OnFetchRange: %d blocks in views [%d, %d] for replica %d", len(blocks), from, to, id)
+
+	for len(blocks) > fetchRangeChunkSize {
+		if err := send(blocksToProto(blocks[:fetchRangeChunkSize], false)); err != nil {
+			return err
+		}
+		blocks = blocks[fetchRangeChunkSize:]
+	}
+	return send(blocksToProto(blocks, true))
+}
+
+func blocksToProto(blocks []*hotstuff.Block, last bool) *hotstuffpb.Blocks {
+	pb := &hotstuffpb.Blocks{Blocks: make([]*hotstuffpb.Block, len(blocks)), Last: last}
+	for i, block := range blocks {
+		pb.Blocks[i] = hotstuffpb.BlockToProto(block)
+	}
+	return pb
+}
+
+// maxTrieNodes is the maximum number of trie nodes that can be requested in a single FetchTrieNodes request.
+const maxTrieNodes = 1024
+
+// FetchCheckpoint handles an incoming request for a checkpoint.
+func (impl *serviceImpl) FetchCheckpoint(_ gorums.ServerCtx, req *hotstuffpb.CheckpointRequest) (*hotstuffpb.Checkpoint, error) {
+	if impl.srv.snapshots == nil {
+		return nil, status.Errorf(codes.Unimplemented, "snapshots are not available")
+	}
+	var hash hotstuff.Hash
+	copy(hash[:], req.GetHash())
+
+	checkpoint, ok := impl.srv.snapshots.Checkpoint(hash)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "requested checkpoint was not found")
+	}
+
+	impl.srv.logger.Debugf("OnFetchCheckpoint: %v", checkpoint)
+
+	return hotstuffpb.CheckpointToProto(checkpoint), nil
+}
+
+// FetchTrieNodes handles an incoming request for trie nodes.
+func (impl *serviceImpl) FetchTrieNodes(ctx gorums.ServerCtx, req *hotstuffpb.TrieNodesRequest) (*hotstuffpb.TrieNodes, error) {
+	if impl.srv.snapshots == nil {
+		return nil, status.Errorf(codes.Unimplemented, "snapshots are not available")
+	}
+	if len(req.GetHashes()) > maxTrieNodes {
+		return nil, status.Errorf(codes.InvalidArgument, "too many trie nodes requested")
+	}
+	// reading the nodes from the database does not need to block other requests.
+	ctx.Release()
+
+	hashes := make([]hotstuff.Hash, len(req.GetHashes()))
+	for i, h := range req.GetHashes() {
+		copy(hashes[i][:], h)
+	}
+	nodes := impl.srv.snapshots.TrieNodes(hashes)
+
+	impl.srv.logger.Debugf("OnFetchTrieNodes: %d of %d nodes", len(nodes), len(hashes))
+
+	return &hotstuffpb.TrieNodes{Nodes: nodes}, nil
+}
+
 // Timeout handles an incoming TimeoutMsg.
 func (impl *serviceImpl) Timeout(ctx gorums.ServerCtx, msg *hotstuffpb.TimeoutMsg) {
 	id, err := GetPeerIDFromContext(ctx, impl.srv.configuration)
@@ -223,6 +430,79 @@ func (impl *serviceImpl) Timeout(ctx gorums.ServerCtx, msg *hotstuffpb.TimeoutMs
 	impl.srv.eventLoop.AddEvent(timeoutMsg)
 }
 
+// DecryptionShare handles an incoming DecryptionShares message.
+func (impl *serviceImpl) DecryptionShare(ctx gorums.ServerCtx, msg *hotstuffpb.DecryptionShares) {
+	id, err := GetPeerIDFromContext(ctx, impl.srv.configuration)
+	if err != nil {
+		impl.srv.logger.Warnf("Could not get replica ID: %v", err)
+		return
+	}
+	shareMsg := hotstuffpb.DecryptionSharesFromProto(msg)
+	shareMsg.ID = id
+	impl.srv.addNetworkDelay(id)
+	impl.srv.eventLoop.AddEvent(shareMsg)
+}
+
+// Evidence handles incoming evidence of a misbehaving replica.
+func (impl *serviceImpl) Evidence(ctx gorums.ServerCtx, msg *hotstuffpb.Evidence) {
+	id, err := GetPeerIDFromContext(ctx, impl.srv.configuration)
+	if err != nil {
+		impl.srv.logger.Warnf("Could not get replica ID: %v", err)
+		return
+	}
+	impl.srv.eventLoop.AddEvent(hotstuff.EvidenceMsg{ID: id, Evidence: hotstuffpb.EvidenceFromProto(msg)})
+}
+
+// MempoolBatch handles an incoming mempool batch.
+func (impl *serviceImpl) MempoolBatch(ctx gorums.ServerCtx, msg *hotstuffpb.MempoolBatch) {
+	id, err := GetPeerIDFromContext(ctx, impl.srv.configuration)
+	if err != nil {
+		impl.srv.logger.Warnf("Could not get replica ID: %v", err)
+		return
+	}
+	impl.srv.addNetworkDelay(id)
+	impl.srv.eventLoop.AddEvent(hotstuff.MempoolBatchMsg{ID: id, Batch: hotstuffpb.MempoolBatchFromProto(msg)})
+}
+
+// AvailabilityVote handles an incoming vote for a mempool batch authored by this replica.
+func (impl *serviceImpl) AvailabilityVote(ctx gorums.ServerCtx, msg *hotstuffpb.AvailabilityVote) {
+	id, err := GetPeerIDFromContext(ctx, impl.srv.configuration)
+	if err != nil {
+		impl.srv.logger.Warnf("Could not get replica ID: %v", err)
+		return
+	}
+	impl.srv.addNetworkDelay(id)
+	vote := hotstuffpb.AvailabilityVoteFromProto(msg)
+	vote.ID = id
+	impl.srv.eventLoop.AddEvent(vote)
+}
+
+// AvailabilityCert handles an incoming availability certificate.
+func (impl *serviceImpl) AvailabilityCert(ctx gorums.ServerCtx, msg *hotstuffpb.AvailabilityCert) {
+	id, err := GetPeerIDFromContext(ctx, impl.srv.configuration)
+	if err != nil {
+		impl.srv.logger.Warnf("Could not get replica ID: %v", err)
+		return
+	}
+	impl.srv.addNetworkDelay(id)
+	impl.srv.eventLoop.AddEvent(hotstuff.AvailabilityCertMsg{ID: id, Cert: hotstuffpb.AvailabilityCertFromProto(msg)})
+}
+
+// FetchMempoolBatch handles an incoming request for a mempool batch.
+func (impl *serviceImpl) FetchMempoolBatch(_ gorums.ServerCtx, req *hotstuffpb.BlockHash) (*hotstuffpb.MempoolBatch, error) {
+	if impl.srv.mempool == nil {
+		return nil, status.Errorf(codes.Unimplemented, "the mempool is not enabled")
+	}
+	var digest hotstuff.Hash
+	copy(digest[:], req.GetHash())
+
+	batch, ok := impl.srv.mempool.MempoolBatch(digest)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "requested batch was not found")
+	}
+	return hotstuffpb.MempoolBatchToProto(batch), nil
+}
+
 type replicaConnected struct {
 	ctx context.Context
 }
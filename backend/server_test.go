@@ -0,0 +1,108 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/modules"
+)
+
+// countingChain is a block chain that counts the number of blocks that are looked up.
+type countingChain struct {
+	modules.BlockChain
+	blocks  map[hotstuff.Hash]*hotstuff.Block
+	lookups int
+}
+
+func (c *countingChain) LocalGet(hash hotstuff.Hash) (*hotstuff.Block, bool) {
+	c.lookups++
+	block, ok := c.blocks[hash]
+	return block, ok
+}
+
+func TestBranch(t *testing.T) {
+	genesis := hotstuff.GetGenesis()
+	chain := &countingChain{blocks: map[hotstuff.Hash]*hotstuff.Block{genesis.Hash(): genesis}}
+	tip := genesis
+	for view := hotstuff.View(1); view <= 100; view++ {
+		// every tenth view has no block.
+		if view%10 == 0 {
+			continue
+		}
+		tip = hotstuff.NewBlock(tip.Hash(), hotstuff.NewQuorumCert(nil, tip.View(), tip.Hash()), "", view, 1)
+		chain.blocks[tip.Hash()] = tip
+	}
+	srv := &Server{blockChain: chain, rangePeers: make(map[hotstuff.ID]*rangePeer)}
+	p := srv.rangePeer(1)
+
+	hashes, err := srv.branch(p, tip.Hash(), 71, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hashes) != 9 {
+		t.Fatalf("got %d blocks, want 9", len(hashes))
+	}
+	for i, hash := range hashes {
+		if want := hotstuff.View(71 + i); chain.blocks[hash].View() != want {
+			t.Errorf("block %d has view %d, want %d", i, chain.blocks[hash].View(), want)
+		}
+	}
+
+	// the next range continues the walk from where the previous one stopped.
+	chain.lookups = 0
+	hashes, err = srv.branch(p, tip.Hash(), 61, 70)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hashes) != 9 {
+		t.Fatalf("got %d blocks, want 9", len(hashes))
+	}
+	if chain.lookups > 10 {
+		t.Errorf("looked up %d blocks, want at most 10", chain.lookups)
+	}
+
+	// a range that has already been visited does not look up any blocks.
+	chain.lookups = 0
+	if _, err = srv.branch(p, tip.Hash(), 81, 99); err != nil {
+		t.Fatal(err)
+	}
+	if chain.lookups != 0 {
+		t.Errorf("looked up %d blocks, want 0", chain.lookups)
+	}
+}
+
+// TestBranchBounded checks that the number of blocks that are remembered for a replica is bounded,
+// and that ranges above the remembered blocks are found by walking from the tip again.
+func TestBranchBounded(t *testing.T) {
+	genesis := hotstuff.GetGenesis()
+	chain := &countingChain{blocks: map[hotstuff.Hash]*hotstuff.Block{genesis.Hash(): genesis}}
+	tip := genesis
+	for view := hotstuff.View(1); view <= maxBranchViews+2*maxFetchRangeViews; view++ {
+		tip = hotstuff.NewBlock(tip.Hash(), hotstuff.NewQuorumCert(nil, tip.View(), tip.Hash()), "", view, 1)
+		chain.blocks[tip.Hash()] = tip
+	}
+	srv := &Server{blockChain: chain, rangePeers: make(map[hotstuff.ID]*rangePeer)}
+	p := srv.rangePeer(1)
+
+	check := func(from, to hotstuff.View) {
+		t.Helper()
+		hashes, err := srv.branch(p, tip.Hash(), from, to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(hashes) != int(to-from+1) {
+			t.Fatalf("got %d blocks, want %d", len(hashes), to-from+1)
+		}
+		for i, hash := range hashes {
+			if want := from + hotstuff.View(i); chain.blocks[hash].View() != want {
+				t.Fatalf("block %d has view %d, want %d", i, chain.blocks[hash].View(), want)
+			}
+		}
+		if len(p.visited) > maxBranchViews {
+			t.Errorf("remembered %d blocks, want at most %d", len(p.visited), maxBranchViews)
+		}
+	}
+	check(1, maxFetchRangeViews)
+	check(tip.View()-maxFetchRangeViews+1, tip.View())
+	check(maxFetchRangeViews+1, 2*maxFetchRangeViews)
+}
@@ -4,6 +4,8 @@ import (
 	"crypto/sha256"
 	"encoding/binary"
 	"fmt"
+	"maps"
+	"slices"
 	"time"
 )
 
@@ -17,6 +19,7 @@ type Block struct {
 	cert     QuorumCert
 	view     View
 	ts       time.Time
+	meta     map[string][]byte
 }
 
 // NewBlock creates a new Block
@@ -40,6 +43,32 @@ func (b *Block) SetTimestamp(ts time.Time) {
 	b.hash = sha256.Sum256(b.ToBytes())
 }
 
+// SetMetadata sets the metadata with the given key, which modules use to extend the block with additional data.
+// The metadata is part of the block's hash, and must be set before the block is signed.
+func (b *Block) SetMetadata(key string, value []byte) {
+	if b.meta == nil {
+		b.meta = make(map[string][]byte)
+	}
+	b.meta[key] = value
+	// recalculate the hash since the metadata is part of the block
+	b.hash = sha256.Sum256(b.ToBytes())
+}
+
+// WithCommand returns a copy of the block with the given command, which executors use to pass on
+// a transformed command (e.g. reordered or decrypted). The copy keeps the hash of the original block,
+// such that it still matches the block's certificate. Since the hash does not match the contents,
+// the copy must not be stored or sent to other replicas.
+func (b *Block) WithCommand(cmd Command) *Block {
+	c := *b
+	c.cmd = cmd
+	return &c
+}
+
+// Metadata returns the metadata of the block. The returned map must not be modified.
+func (b *Block) Metadata() map[string][]byte {
+	return b.meta
+}
+
 func (b *Block) String() string {
 	return fmt.Sprintf(
 		"Block{ hash: %.6s parent: %.6s, proposer: %d, view: %d , cert: %v }",
@@ -100,5 +129,15 @@ func (b *Block) ToBytes() []byte {
 	var tsBuf [8]byte
 	binary.LittleEndian.PutUint64(tsBuf[:], uint64(b.ts.UnixNano()))
 	buf = append(buf, tsBuf[:]...)
+	// blocks without metadata keep the same byte form as before metadata was introduced.
+	for _, key := range slices.Sorted(maps.Keys(b.meta)) {
+		var lenBuf [4]byte
+		binary.LittleEndian.PutUint32(lenBuf[:], uint32(len(key)))
+		buf = append(buf, lenBuf[:]...)
+		buf = append(buf, key...)
+		binary.LittleEndian.PutUint32(lenBuf[:], uint32(len(b.meta[key])))
+		buf = append(buf, lenBuf[:]...)
+		buf = append(buf, b.meta[key]...)
+	}
 	return buf
 }
@@ -21,10 +21,12 @@ import (
 const (
 	blockPrefix  = "block:"  // block:<hash> -> serialized Block
 	heightPrefix = "height:" // height:<view> -> block hash
+	viewPrefix   = "view:"   // view:<big-endian view><hash> -> nothing; indexes all blocks, including forks, in order of view
 	metaPrefix   = "meta:"   // meta:<key> -> value
 
 	// Metadata keys
 	pruneHeightKey = "meta:prune_height"
+	prunedBelowKey = "meta:pruned_below"
 )
 
 // badgerBlockChain is a persistent implementation of the BlockChain interface using BadgerDB.
@@ -38,6 +40,7 @@ type badgerBlockChain struct {
 
 	mut          sync.Mutex
 	pruneHeight  hotstuff.View
+	prunedBelow  hotstuff.View                        // the blocks below this view have been deleted by PruneBelow.
 	pendingFetch map[hotstuff.Hash]context.CancelFunc // allows a pending fetch operation to be canceled
 }
 
@@ -64,6 +67,10 @@ func NewBadgerBlockChain(dbPath string) (modules.BlockChain, error) {
 		db.Close()
 		return nil, fmt.Errorf("failed to load prune height: %w", err)
 	}
+	if err := bc.loadPrunedBelow(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to load pruned view: %w", err)
+	}
 
 	// Note: Genesis block will be stored when InitModule is called
 
@@ -116,6 +123,11 @@ func (chain *badgerBlockChain) Store(block *hotstuff.Block) {
 			return fmt.Errorf("failed to store block by height: %w", err)
 		}
 
+		// Index the block by view, such that it can be pruned without visiting the other blocks
+		if err := txn.Set(makeViewKey(block.View(), hash), nil); err != nil {
+			return fmt.Errorf("failed to index block by view: %w", err)
+		}
+
 		return nil
 	})
 
@@ -284,6 +296,97 @@ func (chain *badgerBlockChain) PruneToHeight(height hotstuff.View) (forkedBlocks
 	return forkedBlocks
 }
 
+// PruneBelow deletes the blocks with a view lower than the given view from the database.
+// The genesis block is kept. If archive is not nil, each block is passed to archive before it is deleted.
+// Only the blocks between the previous and the given view are visited, since the blocks are indexed by view.
+func (chain *badgerBlockChain) PruneBelow(view hotstuff.View, archive func(key, value []byte) error) error {
+	chain.mut.Lock()
+	defer chain.mut.Unlock()
+
+	if view <= chain.prunedBelow {
+		return nil
+	}
+
+	type entry struct {
+		index, key, value []byte
+		view              hotstuff.View
+	}
+	var blocks []entry
+
+	err := chain.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.IteratorOptions{Prefix: []byte(viewPrefix)})
+		defer it.Close()
+		// the genesis block is the only block in view 0.
+		for it.Seek(makeViewKey(max(chain.prunedBelow, 1), hotstuff.Hash{})); it.Valid(); it.Next() {
+			index := it.Item().KeyCopy(nil)
+			blockView, hash := parseViewKey(index)
+			if blockView >= view {
+				break
+			}
+			key := makeBlockKey(hash)
+			item, err := txn.Get(key)
+			if err == badger.ErrKeyNotFound {
+				blocks = append(blocks, entry{index: index, view: blockView})
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			blocks = append(blocks, entry{index, key, value, blockView})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if archive != nil {
+		for _, block := range blocks {
+			if block.key == nil {
+				continue
+			}
+			if err := archive(block.key, block.value); err != nil {
+				return fmt.Errorf("failed to archive block: %w", err)
+			}
+		}
+	}
+
+	wb := chain.db.NewWriteBatch()
+	defer wb.Cancel()
+	for _, block := range blocks {
+		if err := wb.Delete(block.index); err != nil {
+			return err
+		}
+		if err := wb.Delete(makeHeightKey(block.view)); err != nil {
+			return err
+		}
+		if block.key == nil {
+			continue
+		}
+		if err := wb.Delete(block.key); err != nil {
+			return err
+		}
+	}
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(view))
+	if err := wb.Set([]byte(prunedBelowKey), buf[:]); err != nil {
+		return err
+	}
+	if err := wb.Flush(); err != nil {
+		return fmt.Errorf("failed to delete blocks: %w", err)
+	}
+	chain.prunedBelow = view
+
+	if chain.logger != nil {
+		chain.logger.Debugf("Pruned %d blocks below view %d", len(blocks), view)
+	}
+	return nil
+}
+
 // Helper methods
 
 // localGetUnsafe retrieves a block without locking (assumes lock is held)
@@ -341,6 +444,10 @@ func (chain *badgerBlockChain) storeUnsafe(block *hotstuff.Block) {
 			return fmt.Errorf("failed to store block by height: %w", err)
 		}
 
+		if err := txn.Set(makeViewKey(block.View(), hash), nil); err != nil {
+			return fmt.Errorf("failed to index block by view: %w", err)
+		}
+
 		return nil
 	})
 
@@ -402,6 +509,28 @@ func (chain *badgerBlockChain) loadPruneHeight() error {
 	})
 }
 
+// loadPrunedBelow loads the view that the blocks were last pruned below from the database
+func (chain *badgerBlockChain) loadPrunedBelow() error {
+	return chain.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(prunedBelowKey))
+		if err != nil {
+			if err == badger.ErrKeyNotFound {
+				chain.prunedBelow = 0
+				return nil
+			}
+			return err
+		}
+
+		return item.Value(func(val []byte) error {
+			if len(val) != 8 {
+				return fmt.Errorf("invalid pruned view length: %d", len(val))
+			}
+			chain.prunedBelow = hotstuff.View(binary.LittleEndian.Uint64(val))
+			return nil
+		})
+	})
+}
+
 // savePruneHeight saves the prune height to the database
 func (chain *badgerBlockChain) savePruneHeight() error {
 	return chain.db.Update(func(txn *badger.Txn) error {
@@ -440,4 +569,24 @@ func makeHeightKey(view hotstuff.View) []byte {
 	return key
 }
 
-var _ modules.BlockChain = (*badgerBlockChain)(nil)
+// makeViewKey returns the key of the block in the view index.
+// The view is big-endian, such that the keys are sorted by view.
+func makeViewKey(view hotstuff.View, hash hotstuff.Hash) []byte {
+	key := make([]byte, len(viewPrefix)+8+32)
+	copy(key, viewPrefix)
+	binary.BigEndian.PutUint64(key[len(viewPrefix):], uint64(view))
+	copy(key[len(viewPrefix)+8:], hash[:])
+	return key
+}
+
+// parseViewKey returns the view and the hash of the block in the key from the view index.
+func parseViewKey(key []byte) (view hotstuff.View, hash hotstuff.Hash) {
+	view = hotstuff.View(binary.BigEndian.Uint64(key[len(viewPrefix):]))
+	copy(hash[:], key[len(viewPrefix)+8:])
+	return view, hash
+}
+
+var (
+	_ modules.BlockChain = (*badgerBlockChain)(nil)
+	_ modules.Pruner     = (*badgerBlockChain)(nil)
+)
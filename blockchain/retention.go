@@ -0,0 +1,78 @@
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// RetentionMode defines what happens to the blocks below the latest checkpoint
+type RetentionMode string
+
+const (
+	// RetainAll keeps all committed blocks
+	RetainAll RetentionMode = "all"
+	// RetainArchive moves the blocks below the latest checkpoint to an archive database
+	RetainArchive RetentionMode = "archive"
+	// RetainDelete deletes the blocks below the latest checkpoint
+	RetainDelete RetentionMode = "delete"
+)
+
+// ParseRetentionMode returns the retention mode with the given name.
+// The empty string is parsed as RetainAll.
+func ParseRetentionMode(name string) (RetentionMode, error) {
+	switch mode := RetentionMode(name); mode {
+	case "":
+		return RetainAll, nil
+	case RetainAll, RetainArchive, RetainDelete:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unsupported retention mode: %s", name)
+	}
+}
+
+// Archive stores the data that has been pruned from the blockchain and the executor
+type Archive struct {
+	db *badger.DB
+}
+
+// NewArchive opens the archive database in the given directory
+func NewArchive(dbPath string) (*Archive, error) {
+	opts := badger.DefaultOptions(dbPath).
+		WithLogger(nil)
+
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive database: %w", err)
+	}
+	return &Archive{db: db}, nil
+}
+
+// Put stores an archived entry
+func (a *Archive) Put(key, value []byte) error {
+	return a.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(key, value)
+	})
+}
+
+// Get returns an archived entry
+func (a *Archive) Get(key []byte) (value []byte, ok bool, err error) {
+	err = a.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(key)
+		if err != nil {
+			if err == badger.ErrKeyNotFound {
+				return nil
+			}
+			return err
+		}
+		value, err = item.ValueCopy(nil)
+		ok = err == nil
+		return err
+	})
+	return value, ok, err
+}
+
+// Close closes the archive database
+func (a *Archive) Close() error {
+	return a.db.Close()
+}
@@ -0,0 +1,180 @@
+package blockchain
+
+import (
+	"maps"
+	"path/filepath"
+	"testing"
+
+	"github.com/relab/hotstuff"
+)
+
+// storeChain stores a chain of blocks from view 1 to n and returns the blocks indexed by view.
+func storeChain(bc *badgerBlockChain, n hotstuff.View) map[hotstuff.View]*hotstuff.Block {
+	blocks := make(map[hotstuff.View]*hotstuff.Block)
+	parent := hotstuff.GetGenesis()
+	for view := hotstuff.View(1); view <= n; view++ {
+		block := hotstuff.NewBlock(parent.Hash(), hotstuff.NewQuorumCert(nil, parent.View(), parent.Hash()), "cmd", view, 1)
+		bc.Store(block)
+		blocks[view] = block
+		parent = block
+	}
+	return blocks
+}
+
+func TestBadgerBlockChain_PruneBelow(t *testing.T) {
+	tmpDir := t.TempDir()
+	bc, err := NewBadgerBlockChain(filepath.Join(tmpDir, "blocks.db"))
+	if err != nil {
+		t.Fatalf("Failed to create BadgerDB blockchain: %v", err)
+	}
+	badgerBC := bc.(*badgerBlockChain)
+	// the block chain is reopened below.
+	defer func() { badgerBC.Close() }()
+	if err := badgerBC.ensureGenesis(); err != nil {
+		t.Fatalf("Failed to ensure genesis: %v", err)
+	}
+
+	blocks := storeChain(badgerBC, 20)
+	// a forked block that is only indexed by hash.
+	fork := hotstuff.NewBlock(blocks[4].Hash(), hotstuff.NewQuorumCert(nil, 4, blocks[4].Hash()), "fork", 5, 2)
+	badgerBC.Store(fork)
+	badgerBC.Store(blocks[5])
+
+	archive, err := NewArchive(filepath.Join(tmpDir, "archive.db"))
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	defer archive.Close()
+
+	if err := badgerBC.PruneBelow(10, archive.Put); err != nil {
+		t.Fatalf("Failed to prune: %v", err)
+	}
+
+	if _, ok := bc.LocalGet(hotstuff.GetGenesis().Hash()); !ok {
+		t.Error("Genesis block should be kept")
+	}
+	for view := hotstuff.View(1); view <= 20; view++ {
+		_, ok := bc.LocalGet(blocks[view].Hash())
+		if view < 10 && ok {
+			t.Errorf("Block at view %d should be pruned", view)
+		}
+		if view >= 10 && !ok {
+			t.Errorf("Block at view %d should be kept", view)
+		}
+		if _, ok := badgerBC.getByHeightUnsafe(view); ok != (view >= 10) {
+			t.Errorf("Height index at view %d: got %v, want %v", view, ok, view >= 10)
+		}
+		if view < 10 {
+			if _, ok, err := archive.Get(makeBlockKey(blocks[view].Hash())); !ok || err != nil {
+				t.Errorf("Block at view %d should be archived: %v", view, err)
+			}
+		}
+	}
+	if _, ok := bc.LocalGet(fork.Hash()); ok {
+		t.Error("Forked block should be pruned")
+	}
+
+	// the next prune continues from the previous one, also after a restart.
+	if err := badgerBC.Close(); err != nil {
+		t.Fatal(err)
+	}
+	bc, err = NewBadgerBlockChain(filepath.Join(tmpDir, "blocks.db"))
+	if err != nil {
+		t.Fatalf("Failed to reopen BadgerDB blockchain: %v", err)
+	}
+	badgerBC = bc.(*badgerBlockChain)
+	if badgerBC.prunedBelow != 10 {
+		t.Errorf("Pruned below view %d after restart, want 10", badgerBC.prunedBelow)
+	}
+	if err := badgerBC.PruneBelow(15, nil); err != nil {
+		t.Fatalf("Failed to prune: %v", err)
+	}
+	for view := hotstuff.View(10); view <= 20; view++ {
+		if _, ok := bc.LocalGet(blocks[view].Hash()); ok != (view >= 15) {
+			t.Errorf("Block at view %d: got %v, want %v", view, ok, view >= 15)
+		}
+	}
+}
+
+func TestStateStore_Checkpoints(t *testing.T) {
+	store, err := NewStateStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create state store: %v", err)
+	}
+	defer store.Close()
+
+	if _, ok, err := store.LatestCheckpoint(); ok || err != nil {
+		t.Fatalf("Expected no checkpoint, got ok=%v err=%v", ok, err)
+	}
+
+	var want []hotstuff.Checkpoint
+	for _, view := range []hotstuff.View{300, 100, 200} {
+		checkpoint := hotstuff.Checkpoint{
+			QC:        hotstuff.NewQuorumCert(nil, view, hotstuff.Hash{byte(view)}),
+			StateRoot: hotstuff.Hash{1, byte(view)},
+		}
+		if err := store.SetCheckpoint(checkpoint); err != nil {
+			t.Fatalf("Failed to set checkpoint: %v", err)
+		}
+		want = append(want, checkpoint)
+	}
+
+	latest, ok, err := store.LatestCheckpoint()
+	if !ok || err != nil {
+		t.Fatalf("Failed to get latest checkpoint: %v", err)
+	}
+	if latest.View() != 300 || latest.StateRoot != want[0].StateRoot {
+		t.Errorf("Latest checkpoint is %v, want %v", latest, want[0])
+	}
+
+	if err := store.DeleteCheckpointsBelow(200); err != nil {
+		t.Fatalf("Failed to delete checkpoints: %v", err)
+	}
+	checkpoints, err := store.GetCheckpoints()
+	if err != nil {
+		t.Fatalf("Failed to get checkpoints: %v", err)
+	}
+	if len(checkpoints) != 2 || checkpoints[0].View() != 200 || checkpoints[1].View() != 300 {
+		t.Errorf("Got checkpoints %v, want views 200 and 300", checkpoints)
+	}
+	if checkpoints[0].BlockHash() != want[2].BlockHash() {
+		t.Errorf("Checkpoint block hash %.8s, want %.8s", checkpoints[0].BlockHash(), want[2].BlockHash())
+	}
+}
+
+func TestStateStore_DeliveredBatches(t *testing.T) {
+	store, err := NewStateStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create state store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.AddDeliveredBatches(map[hotstuff.Hash]uint64{{1}: 1, {2}: 2, {3}: 3}, 0); err != nil {
+		t.Fatalf("Failed to add delivered batches: %v", err)
+	}
+	if err := store.AddDeliveredBatches(map[hotstuff.Hash]uint64{{1, 1}: 1, {4}: 4}, 2); err != nil {
+		t.Fatalf("Failed to add delivered batches: %v", err)
+	}
+	delivered, gcRound, err := store.GetDeliveredBatches()
+	if err != nil {
+		t.Fatalf("Failed to get delivered batches: %v", err)
+	}
+	if gcRound != 2 {
+		t.Errorf("Garbage collection round is %d, want 2", gcRound)
+	}
+	want := map[hotstuff.Hash]uint64{{2}: 2, {3}: 3, {4}: 4}
+	if !maps.Equal(delivered, want) {
+		t.Errorf("Got delivered batches %v, want %v", delivered, want)
+	}
+}
+
+func TestParseRetentionMode(t *testing.T) {
+	for name, want := range map[string]RetentionMode{"": RetainAll, "all": RetainAll, "archive": RetainArchive, "delete": RetainDelete} {
+		if got, err := ParseRetentionMode(name); err != nil || got != want {
+			t.Errorf("ParseRetentionMode(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseRetentionMode("forever"); err == nil {
+		t.Error("Expected error for unknown retention mode")
+	}
+}
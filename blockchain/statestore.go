@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"path/filepath"
+	"sync"
 
 	"github.com/dgraph-io/badger/v4"
 	"github.com/relab/hotstuff"
@@ -18,12 +19,20 @@ const (
 	stateCommittedHash = "state:committed_hash"
 	stateHighQC        = "state:high_qc"
 	stateHighTC        = "state:high_tc"
-	stateLockHash      = "state:lock_hash" // for consensus algorithms that maintain a locked block
+	stateLockHash      = "state:lock_hash"   // for consensus algorithms that maintain a locked block
+	stateClientPrefix  = "state:client:"     // followed by the client ID
+	stateCheckpoint    = "state:checkpoint:" // followed by the view of the checkpoint
+	stateRoot          = "state:state_root"  // the root of the application state
+	stateMempoolGC     = "state:mempool:gc_round"
+	stateDelivered     = "state:mempool:delivered:" // followed by the round and the digest of the batch
 )
 
 // StateStore manages persistent consensus and synchronizer state
 type StateStore struct {
 	db *badger.DB
+
+	mut    sync.Mutex
+	staged []byte // the state root that is saved with the next executed or committed block, if any
 }
 
 // NewStateStore creates a new persistent state store
@@ -147,9 +156,9 @@ func (s *StateStore) GetCommittedBlockHash() (hotstuff.Hash, error) {
 	return hash, err
 }
 
-// SetCommittedBlockHash saves the hash of the last committed block
+// SetCommittedBlockHash saves the hash of the last committed block, together with the staged state root
 func (s *StateStore) SetCommittedBlockHash(hash hotstuff.Hash) error {
-	return s.db.Update(func(txn *badger.Txn) error {
+	return s.update(func(txn *badger.Txn) error {
 		return txn.Set([]byte(stateCommittedHash), hash[:])
 	})
 }
@@ -266,8 +275,307 @@ func (s *StateStore) SetLockedBlockHash(hash hotstuff.Hash) error {
 	})
 }
 
+// Client Session Management
+
+// ClientSession records which commands from a client have been committed.
+type ClientSession struct {
+	// All commands with a sequence number up to and including Committed have been committed.
+	Committed uint64
+	// Sequence numbers above Committed that have been committed out of order, in increasing order.
+	OutOfOrder []uint64
+}
+
+// GetClientSessions returns the committed client sessions
+func (s *StateStore) GetClientSessions() (map[uint32]ClientSession, error) {
+	sessions := make(map[uint32]ClientSession)
+	err := s.db.View(func(txn *badger.Txn) error {
+		prefix := []byte(stateClientPrefix)
+		it := txn.NewIterator(badger.IteratorOptions{Prefix: prefix})
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			key := item.Key()[len(prefix):]
+			if len(key) != 4 {
+				return fmt.Errorf("invalid client ID length: %d", len(key))
+			}
+			err := item.Value(func(val []byte) error {
+				if len(val) < 8 || len(val)%8 != 0 {
+					return fmt.Errorf("invalid client session length: %d", len(val))
+				}
+				session := ClientSession{Committed: binary.LittleEndian.Uint64(val)}
+				for i := 8; i < len(val); i += 8 {
+					session.OutOfOrder = append(session.OutOfOrder, binary.LittleEndian.Uint64(val[i:]))
+				}
+				sessions[binary.BigEndian.Uint32(key)] = session
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return sessions, err
+}
+
+// SetExecuted atomically saves the hash of the last executed block together with the client sessions
+// that changed when its commands were committed and the staged state root, such that a restarted replica
+// neither executes the block again nor forgets which of its commands were committed
+func (s *StateStore) SetExecuted(hash hotstuff.Hash, sessions map[uint32]ClientSession) error {
+	return s.update(func(txn *badger.Txn) error {
+		if err := txn.Set([]byte(stateCommittedHash), hash[:]); err != nil {
+			return err
+		}
+		for clientID, session := range sessions {
+			key := binary.BigEndian.AppendUint32([]byte(stateClientPrefix), clientID)
+			val := binary.LittleEndian.AppendUint64(nil, session.Committed)
+			for _, seq := range session.OutOfOrder {
+				val = binary.LittleEndian.AppendUint64(val, seq)
+			}
+			if err := txn.Set(key, val); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Checkpoint Management
+
+// SetCheckpoint saves a checkpoint
+func (s *StateStore) SetCheckpoint(checkpoint hotstuff.Checkpoint) error {
+	return s.db.Update(func(txn *badger.Txn) error {
+		data, err := proto.Marshal(hotstuffpb.CheckpointToProto(checkpoint))
+		if err != nil {
+			return fmt.Errorf("failed to marshal checkpoint: %w", err)
+		}
+		return txn.Set(makeCheckpointKey(checkpoint.View()), data)
+	})
+}
+
+// GetCheckpoints returns the saved checkpoints in increasing order of view
+func (s *StateStore) GetCheckpoints() ([]hotstuff.Checkpoint, error) {
+	var checkpoints []hotstuff.Checkpoint
+	err := s.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.IteratorOptions{PrefetchValues: true, Prefix: []byte(stateCheckpoint)})
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			err := it.Item().Value(func(val []byte) error {
+				var pbCheckpoint hotstuffpb.Checkpoint
+				if err := proto.Unmarshal(val, &pbCheckpoint); err != nil {
+					return fmt.Errorf("failed to unmarshal checkpoint: %w", err)
+				}
+				checkpoints = append(checkpoints, hotstuffpb.CheckpointFromProto(&pbCheckpoint))
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return checkpoints, err
+}
+
+// LatestCheckpoint returns the checkpoint with the highest view, if any
+func (s *StateStore) LatestCheckpoint() (checkpoint hotstuff.Checkpoint, ok bool, err error) {
+	err = s.db.View(func(txn *badger.Txn) error {
+		prefix := []byte(stateCheckpoint)
+		it := txn.NewIterator(badger.IteratorOptions{Reverse: true, Prefix: prefix})
+		defer it.Close()
+
+		// seek to the largest possible key with the prefix.
+		it.Seek(append(prefix, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff))
+		if !it.Valid() {
+			return nil
+		}
+		return it.Item().Value(func(val []byte) error {
+			var pbCheckpoint hotstuffpb.Checkpoint
+			if err := proto.Unmarshal(val, &pbCheckpoint); err != nil {
+				return fmt.Errorf("failed to unmarshal checkpoint: %w", err)
+			}
+			checkpoint, ok = hotstuffpb.CheckpointFromProto(&pbCheckpoint), true
+			return nil
+		})
+	})
+	return checkpoint, ok, err
+}
+
+// DeleteCheckpointsBelow removes the checkpoints with a view lower than the given view
+func (s *StateStore) DeleteCheckpointsBelow(view hotstuff.View) error {
+	return s.db.Update(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.IteratorOptions{Prefix: []byte(stateCheckpoint)})
+		defer it.Close()
+
+		var keys [][]byte
+		for it.Rewind(); it.Valid(); it.Next() {
+			key := it.Item().KeyCopy(nil)
+			if hotstuff.View(binary.BigEndian.Uint64(key[len(stateCheckpoint):])) >= view {
+				break
+			}
+			keys = append(keys, key)
+		}
+		for _, key := range keys {
+			if err := txn.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Application State Management
+
+// SetStateRoot saves the root of the application state after executing the last committed block
+func (s *StateStore) SetStateRoot(root hotstuff.Hash) error {
+	return s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(stateRoot), root[:])
+	})
+}
+
+// StageStateRoot records the root of the application state after executing a block.
+// The root is saved in the same write as the hash of the block by SetExecuted or SetCommittedBlockHash,
+// such that a restarted replica does not continue from a state that belongs to a different block
+func (s *StateStore) StageStateRoot(root hotstuff.Hash) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.staged = root[:]
+}
+
+// update runs the read-write transaction, and saves the staged state root in the same transaction
+func (s *StateStore) update(fn func(txn *badger.Txn) error) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	err := s.db.Update(func(txn *badger.Txn) error {
+		if err := fn(txn); err != nil {
+			return err
+		}
+		if s.staged == nil {
+			return nil
+		}
+		return txn.Set([]byte(stateRoot), s.staged)
+	})
+	if err == nil {
+		s.staged = nil
+	}
+	return err
+}
+
+// GetStateRoot returns the root of the application state, if it has been saved
+func (s *StateStore) GetStateRoot() (root hotstuff.Hash, ok bool, err error) {
+	err = s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(stateRoot))
+		if err != nil {
+			if err == badger.ErrKeyNotFound {
+				return nil
+			}
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			if len(val) != len(root) {
+				return fmt.Errorf("invalid state root length: %d", len(val))
+			}
+			copy(root[:], val)
+			ok = true
+			return nil
+		})
+	})
+	return root, ok, err
+}
+
+// Mempool Management
+
+// AddDeliveredBatches atomically saves the digests of the delivered mempool batches, keyed by their round,
+// and the garbage collection round of the mempool. Delivered batches below the garbage collection round are removed.
+func (s *StateStore) AddDeliveredBatches(delivered map[hotstuff.Hash]uint64, gcRound uint64) error {
+	return s.db.Update(func(txn *badger.Txn) error {
+		for digest, round := range delivered {
+			if round < gcRound {
+				continue
+			}
+			if err := txn.Set(makeDeliveredKey(round, digest), nil); err != nil {
+				return err
+			}
+		}
+		if err := txn.Set([]byte(stateMempoolGC), binary.LittleEndian.AppendUint64(nil, gcRound)); err != nil {
+			return err
+		}
+
+		// the keys are ordered by round, so the iteration stops at the first key that is kept.
+		it := txn.NewIterator(badger.IteratorOptions{Prefix: []byte(stateDelivered)})
+		defer it.Close()
+		var keys [][]byte
+		for it.Rewind(); it.Valid(); it.Next() {
+			key := it.Item().KeyCopy(nil)
+			if binary.BigEndian.Uint64(key[len(stateDelivered):]) >= gcRound {
+				break
+			}
+			keys = append(keys, key)
+		}
+		for _, key := range keys {
+			if err := txn.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetDeliveredBatches returns the round of each delivered mempool batch, and the garbage collection round of the mempool.
+func (s *StateStore) GetDeliveredBatches() (delivered map[hotstuff.Hash]uint64, gcRound uint64, err error) {
+	delivered = make(map[hotstuff.Hash]uint64)
+	err = s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(stateMempoolGC))
+		if err != nil && err != badger.ErrKeyNotFound {
+			return err
+		}
+		if err == nil {
+			err = item.Value(func(val []byte) error {
+				if len(val) != 8 {
+					return fmt.Errorf("invalid round length: %d", len(val))
+				}
+				gcRound = binary.LittleEndian.Uint64(val)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		prefix := []byte(stateDelivered)
+		it := txn.NewIterator(badger.IteratorOptions{Prefix: prefix})
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			key := it.Item().Key()[len(prefix):]
+			var digest hotstuff.Hash
+			if len(key) != 8+len(digest) {
+				return fmt.Errorf("invalid delivered batch key length: %d", len(key))
+			}
+			copy(digest[:], key[8:])
+			delivered[digest] = binary.BigEndian.Uint64(key)
+		}
+		return nil
+	})
+	return delivered, gcRound, err
+}
+
 // Helper Methods
 
+// makeCheckpointKey returns the key of the checkpoint at the given view.
+// The view is big endian encoded, such that the checkpoints are iterated in increasing order of view.
+func makeCheckpointKey(view hotstuff.View) []byte {
+	return binary.BigEndian.AppendUint64([]byte(stateCheckpoint), uint64(view))
+}
+
+// makeDeliveredKey returns the key of a delivered mempool batch.
+// The round is big endian encoded, such that the batches are iterated in increasing order of round.
+func makeDeliveredKey(round uint64, digest hotstuff.Hash) []byte {
+	key := binary.BigEndian.AppendUint64([]byte(stateDelivered), round)
+	return append(key, digest[:]...)
+}
+
 // initializeDefaults sets up default values if the database is empty
 func (s *StateStore) initializeDefaults() error {
 	return s.db.Update(func(txn *badger.Txn) error {
@@ -0,0 +1,47 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/relab/hotstuff"
+)
+
+// TestStateStoreStagedRoot checks that a staged state root is only saved together with an executed block.
+func TestStateStoreStagedRoot(t *testing.T) {
+	store, err := NewStateStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	root := hotstuff.Hash{1}
+	store.StageStateRoot(root)
+	if _, ok, err := store.GetStateRoot(); err != nil || ok {
+		t.Fatalf("state root was saved before the block was executed (err: %v)", err)
+	}
+
+	block := hotstuff.Hash{2}
+	if err := store.SetExecuted(block, map[uint32]ClientSession{1: {Committed: 3}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok, err := store.GetStateRoot(); err != nil || !ok || got != root {
+		t.Errorf("got state root %.8s (err: %v), want %.8s", got, err, root)
+	}
+	if got, err := store.GetCommittedBlockHash(); err != nil || got != block {
+		t.Errorf("got executed block %.8s (err: %v), want %.8s", got, err, block)
+	}
+	if sessions, err := store.GetClientSessions(); err != nil || sessions[1].Committed != 3 {
+		t.Errorf("got sessions %v (err: %v), want client 1 at sequence number 3", sessions, err)
+	}
+
+	// the staged root is only saved once.
+	if err := store.SetStateRoot(hotstuff.Hash{3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetCommittedBlockHash(block); err != nil {
+		t.Fatal(err)
+	}
+	if got, _, _ := store.GetStateRoot(); got != (hotstuff.Hash{3}) {
+		t.Errorf("got state root %.8s, want %.8s", got, hotstuff.Hash{3})
+	}
+}
@@ -0,0 +1,343 @@
+// Package blocksync implements block synchronization for replicas that are lagging behind or have restarted.
+//
+// A replica that receives a proposal whose quorum certificate is far ahead of its committed block
+// stops voting and downloads the missing blocks from the other replicas.
+// The missing views are split into ranges that are downloaded in parallel from different replicas.
+// The quorum certificates of the downloaded blocks are verified as the ranges arrive,
+// and the blocks are only stored once the chain of certificates from the certified block
+// back to a locally known block is complete.
+//
+// If a StateSync module is available, a new replica that has not committed any blocks
+// first downloads the state of a recent checkpoint, and then syncs the blocks after the checkpoint.
+package blocksync
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+)
+
+const (
+	// DefaultLagThreshold is the number of views a replica must be behind before it starts syncing.
+	// Smaller gaps are filled by fetching the missing blocks one by one.
+	DefaultLagThreshold = 10
+	// DefaultRangeSize is the number of views requested from a single replica at a time.
+	DefaultRangeSize = 100
+
+	fetchTimeout = 10 * time.Second
+)
+
+// Progress describes the progress of a sync.
+type Progress struct {
+	// StartingView is the view of the committed block when the sync started.
+	StartingView hotstuff.View
+	// CurrentView is the highest view such that all blocks up to it have been downloaded.
+	CurrentView hotstuff.View
+	// HighestView is the view of the certified block that the replica is syncing to.
+	HighestView hotstuff.View
+}
+
+// syncDone is sent to the event loop when a sync has completed.
+type syncDone struct {
+	qc  hotstuff.QuorumCert
+	err error
+}
+
+// viewRange is a range of views [from, to].
+type viewRange struct {
+	from, to hotstuff.View
+}
+
+// BlockSync downloads missing blocks when the replica is lagging behind.
+type BlockSync struct {
+	blockChain    modules.BlockChain
+	configuration modules.Configuration
+	consensus     modules.Consensus
+	crypto        modules.Crypto
+	eventLoop     *eventloop.EventLoop
+	logger        logging.Logger
+	opts          *modules.Options
+	synchronizer  modules.Synchronizer
+	stateSync     modules.StateSync
+
+	lagThreshold hotstuff.View
+	rangeSize    hotstuff.View
+	// stateSynced is set once a state sync has been attempted.
+	// If it failed, the replica falls back to syncing all blocks.
+	stateSynced bool
+	// blocksMissing is set when a block sync has failed, for example because the other replicas
+	// have pruned the blocks below their latest checkpoint. The replica then syncs the state instead.
+	blocksMissing bool
+
+	mut      sync.Mutex
+	syncing  bool
+	progress Progress
+}
+
+// New returns a new BlockSync module.
+func New() *BlockSync {
+	return &BlockSync{
+		lagThreshold: DefaultLagThreshold,
+		rangeSize:    DefaultRangeSize,
+	}
+}
+
+// InitModule initializes the BlockSync module.
+func (s *BlockSync) InitModule(mods *modules.Core) {
+	mods.Get(
+		&s.blockChain,
+		&s.configuration,
+		&s.consensus,
+		&s.crypto,
+		&s.eventLoop,
+		&s.logger,
+		&s.opts,
+		&s.synchronizer,
+	)
+	mods.TryGet(&s.stateSync)
+
+	// the proposal must be inspected before the consensus module decides whether to vote for it.
+	s.eventLoop.RegisterHandler(hotstuff.ProposeMsg{}, func(event any) {
+		s.onPropose(event.(hotstuff.ProposeMsg))
+	}, eventloop.Prioritize())
+
+	s.eventLoop.RegisterHandler(syncDone{}, func(event any) {
+		s.onSyncDone(event.(syncDone))
+	})
+}
+
+// Syncing returns true while the replica is downloading blocks or state.
+func (s *BlockSync) Syncing() bool {
+	if s.stateSync != nil && s.stateSync.Syncing() {
+		return true
+	}
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.syncing
+}
+
+// Progress returns the progress of the current sync, and false if the replica is not syncing.
+func (s *BlockSync) Progress() (Progress, bool) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.progress, s.syncing
+}
+
+// onPropose starts a sync if the proposal certifies a block that is far ahead of the committed block.
+func (s *BlockSync) onPropose(proposal hotstuff.ProposeMsg) {
+	if s.Syncing() {
+		return
+	}
+	qc := proposal.Block.QuorumCert()
+	committed := s.consensus.CommittedBlock().View()
+	if qc.View() <= committed+s.lagThreshold {
+		return
+	}
+	if _, ok := s.blockChain.LocalGet(qc.BlockHash()); ok {
+		return
+	}
+	fetcher, ok := s.configuration.(modules.RangeFetcher)
+	if !ok {
+		return
+	}
+	// a proposal with an invalid QC must not cause the replica to stop voting.
+	if !s.crypto.VerifyQuorumCert(qc) {
+		return
+	}
+
+	peers := s.peers()
+	if len(peers) == 0 {
+		return
+	}
+
+	if (committed == 0 || s.blocksMissing) && s.stateSync != nil && !s.stateSynced {
+		s.stateSynced = true
+		s.blocksMissing = false
+		s.logger.Info("Syncing state from a checkpoint")
+		s.stateSync.Sync()
+		return
+	}
+
+	s.mut.Lock()
+	s.syncing = true
+	s.progress = Progress{StartingView: committed, CurrentView: committed, HighestView: qc.View()}
+	s.mut.Unlock()
+
+	s.logger.Infof("Syncing from view %d to view %d", committed, qc.View())
+
+	ctx := s.eventLoop.Context()
+	go func() {
+		err := s.sync(ctx, fetcher, peers, committed, qc)
+		s.eventLoop.AddEvent(syncDone{qc: qc, err: err})
+	}()
+}
+
+func (s *BlockSync) onSyncDone(done syncDone) {
+	s.mut.Lock()
+	s.syncing = false
+	s.mut.Unlock()
+
+	if done.err != nil {
+		s.logger.Warnf("Sync failed: %v", done.err)
+		if s.stateSync != nil {
+			s.blocksMissing = true
+			s.stateSynced = false
+		}
+		return
+	}
+	s.logger.Infof("Synced to view %d", done.qc.View())
+	s.synchronizer.AdvanceView(hotstuff.NewSyncInfo().WithQC(done.qc))
+}
+
+// peers returns the IDs of the other replicas in ascending order.
+func (s *BlockSync) peers() []hotstuff.ID {
+	var peers []hotstuff.ID
+	for id := range s.configuration.Replicas() {
+		if id != s.opts.ID() {
+			peers = append(peers, id)
+		}
+	}
+	slices.Sort(peers)
+	return peers
+}
+
+// sync downloads the blocks in the views after the committed view up to the view of the QC,
+// verifies the chain of quorum certificates, and stores the blocks.
+func (s *BlockSync) sync(ctx context.Context, fetcher modules.RangeFetcher, peers []hotstuff.ID, committed hotstuff.View, qc hotstuff.QuorumCert) error {
+	var ranges []viewRange
+	for from := committed + 1; from <= qc.View(); from += s.rangeSize {
+		ranges = append(ranges, viewRange{from, min(from+s.rangeSize-1, qc.View())})
+	}
+
+	var (
+		wg         sync.WaitGroup
+		results    = make([][]*hotstuff.Block, len(ranges))
+		errs       = make([]error, len(ranges))
+		downloaded = make([]bool, len(ranges))
+		next       = 0
+		// as many ranges in flight as there are peers.
+		sem = make(chan struct{}, len(peers))
+		// one request in flight per peer.
+		slots = make(map[hotstuff.ID]chan struct{}, len(peers))
+	)
+	for _, peer := range peers {
+		slots[peer] = make(chan struct{}, 1)
+	}
+	for i, r := range ranges {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i], errs[i] = s.fetchRange(ctx, fetcher, peers, slots, i, qc.BlockHash(), r)
+			if errs[i] != nil {
+				return
+			}
+
+			s.mut.Lock()
+			downloaded[i] = true
+			for next < len(ranges) && downloaded[next] {
+				s.progress.CurrentView = ranges[next].to
+				next++
+			}
+			s.mut.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	byHash := make(map[hotstuff.Hash]*hotstuff.Block)
+	for i, err := range errs {
+		if err != nil {
+			return err
+		}
+		for _, block := range results[i] {
+			byHash[block.Hash()] = block
+		}
+	}
+
+	// follow the chain of certificates back to a block that is known locally.
+	var chain []*hotstuff.Block
+	cert := qc
+	for {
+		if _, ok := s.blockChain.LocalGet(cert.BlockHash()); ok {
+			break
+		}
+		block, ok := byHash[cert.BlockHash()]
+		if !ok {
+			return fmt.Errorf("missing block %.8s", cert.BlockHash())
+		}
+		if block.View() != cert.View() {
+			return fmt.Errorf("block %.8s has view %d, but was certified in view %d", block.Hash(), block.View(), cert.View())
+		}
+		chain = append(chain, block)
+		cert = block.QuorumCert()
+	}
+
+	slices.Reverse(chain)
+	for _, block := range chain {
+		s.blockChain.Store(block)
+	}
+	return nil
+}
+
+// fetchRange downloads the blocks in the range from one of the peers.
+// The i'th range is first requested from the i'th peer, and then from the next peers if the request fails.
+// A request is only sent to a peer when no other request to the same peer is in flight.
+func (s *BlockSync) fetchRange(ctx context.Context, fetcher modules.RangeFetcher, peers []hotstuff.ID, slots map[hotstuff.ID]chan struct{}, i int, tip hotstuff.Hash, r viewRange) ([]*hotstuff.Block, error) {
+	var err error
+	for j := range peers {
+		peer := peers[(i+j)%len(peers)]
+		select {
+		case slots[peer] <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		var blocks []*hotstuff.Block
+		blocks, err = s.fetchFrom(ctx, fetcher, peer, tip, r)
+		<-slots[peer]
+		if err == nil {
+			return blocks, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		s.logger.Infof("Failed to fetch views [%d, %d] from replica %d: %v", r.from, r.to, peer, err)
+	}
+	return nil, fmt.Errorf("failed to fetch views [%d, %d]: %w", r.from, r.to, err)
+}
+
+// fetchFrom downloads the blocks in the range from the peer and verifies their quorum certificates.
+func (s *BlockSync) fetchFrom(ctx context.Context, fetcher modules.RangeFetcher, peer hotstuff.ID, tip hotstuff.Hash, r viewRange) ([]*hotstuff.Block, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	blocks, err := fetcher.FetchRange(ctx, peer, tip, r.from, r.to)
+	if err != nil {
+		return nil, err
+	}
+	prev := r.from - 1
+	for _, block := range blocks {
+		if block.View() <= prev || block.View() > r.to {
+			return nil, fmt.Errorf("block %.8s with view %d is out of order", block.Hash(), block.View())
+		}
+		qc := block.QuorumCert()
+		if qc.BlockHash() != block.Parent() || qc.View() >= block.View() {
+			return nil, fmt.Errorf("the QC of block %.8s does not certify its parent", block.Hash())
+		}
+		if !s.crypto.VerifyQuorumCert(qc) {
+			return nil, fmt.Errorf("the QC of block %.8s is invalid", block.Hash())
+		}
+		prev = block.View()
+	}
+	return blocks, nil
+}
+
+var _ modules.BlockSync = (*BlockSync)(nil)
@@ -0,0 +1,209 @@
+package blocksync
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/blockchain"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+)
+
+// testSignature is a quorum signature that is accepted by testCrypto.
+type testSignature struct{}
+
+func (testSignature) ToBytes() []byte              { return []byte("valid") }
+func (testSignature) Participants() hotstuff.IDSet { return hotstuff.NewIDSet() }
+
+// testCrypto accepts the genesis QC and QCs that have a testSignature.
+type testCrypto struct {
+	modules.Crypto
+}
+
+func (testCrypto) VerifyQuorumCert(qc hotstuff.QuorumCert) bool {
+	_, ok := qc.Signature().(testSignature)
+	return ok || qc.BlockHash() == hotstuff.GetGenesis().Hash()
+}
+
+type testConsensus struct {
+	modules.Consensus
+}
+
+func (testConsensus) CommittedBlock() *hotstuff.Block {
+	return hotstuff.GetGenesis()
+}
+
+type testSynchronizer struct {
+	modules.Synchronizer
+	highQC hotstuff.QuorumCert
+}
+
+func (s *testSynchronizer) AdvanceView(syncInfo hotstuff.SyncInfo) {
+	s.highQC, _ = syncInfo.QC()
+}
+
+// testConfig serves FetchRange requests from the blocks of each replica.
+type testConfig struct {
+	modules.Configuration
+	replicas map[hotstuff.ID]modules.Replica
+	blocks   map[hotstuff.ID]map[hotstuff.Hash]*hotstuff.Block
+	// replicas that respond with forged blocks.
+	forged hotstuff.IDSet
+
+	mut      sync.Mutex
+	requests map[hotstuff.ID]int
+}
+
+func (cfg *testConfig) Replicas() map[hotstuff.ID]modules.Replica {
+	return cfg.replicas
+}
+
+func (cfg *testConfig) FetchRange(_ context.Context, id hotstuff.ID, tip hotstuff.Hash, from, to hotstuff.View) ([]*hotstuff.Block, error) {
+	cfg.mut.Lock()
+	cfg.requests[id]++
+	cfg.mut.Unlock()
+
+	if cfg.forged.Contains(id) {
+		parent := hotstuff.GetGenesis()
+		var blocks []*hotstuff.Block
+		for view := from; view <= to; view++ {
+			block := hotstuff.NewBlock(parent.Hash(), hotstuff.NewQuorumCert(nil, parent.View(), parent.Hash()), "forged", view, id)
+			blocks = append(blocks, block)
+			parent = block
+		}
+		return blocks, nil
+	}
+
+	block, ok := cfg.blocks[id][tip]
+	if !ok {
+		return nil, errors.New("tip not found")
+	}
+	var blocks []*hotstuff.Block
+	for ok && block.View() >= from {
+		if block.View() <= to {
+			blocks = append([]*hotstuff.Block{block}, blocks...)
+		}
+		block, ok = cfg.blocks[id][block.Parent()]
+	}
+	return blocks, nil
+}
+
+// createChain creates a chain of n blocks on top of the genesis block,
+// and returns the blocks and the QC of the last block.
+func createChain(n int) (map[hotstuff.Hash]*hotstuff.Block, hotstuff.QuorumCert) {
+	blocks := make(map[hotstuff.Hash]*hotstuff.Block)
+	parent := hotstuff.GetGenesis()
+	qc := hotstuff.NewQuorumCert(nil, 0, parent.Hash())
+	for view := hotstuff.View(1); view <= hotstuff.View(n); view++ {
+		block := hotstuff.NewBlock(parent.Hash(), qc, "cmd", view, 1)
+		blocks[block.Hash()] = block
+		qc = hotstuff.NewQuorumCert(testSignature{}, view, block.Hash())
+		parent = block
+	}
+	return blocks, qc
+}
+
+func TestBlockSync(t *testing.T) {
+	blocks, qc := createChain(250)
+	cfg := &testConfig{
+		replicas: make(map[hotstuff.ID]modules.Replica),
+		blocks:   make(map[hotstuff.ID]map[hotstuff.Hash]*hotstuff.Block),
+		forged:   hotstuff.NewIDSet(),
+		requests: make(map[hotstuff.ID]int),
+	}
+	for id := hotstuff.ID(1); id <= 4; id++ {
+		cfg.replicas[id] = nil
+		cfg.blocks[id] = blocks
+	}
+	cfg.forged.Add(2)
+
+	eventLoop := eventloop.New(100)
+	blockChain := blockchain.New()
+	synchronizer := &testSynchronizer{}
+	bs := New()
+	bs.rangeSize = 25
+
+	builder := modules.NewBuilder(1, nil)
+	builder.Add(
+		eventLoop,
+		logging.New("test"),
+		blockChain,
+		testConsensus{},
+		testCrypto{},
+		cfg,
+		synchronizer,
+		bs,
+	)
+	builder.Build()
+
+	done := make(chan syncDone, 1)
+	eventLoop.RegisterHandler(syncDone{}, func(event any) {
+		done <- event.(syncDone)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	go eventLoop.Run(ctx)
+
+	// a proposal that extends the certified block at view 250.
+	proposal := hotstuff.ProposeMsg{ID: 3, Block: hotstuff.NewBlock(qc.BlockHash(), qc, "cmd", 251, 3)}
+	eventLoop.AddEvent(proposal)
+
+	select {
+	case d := <-done:
+		if d.err != nil {
+			t.Fatalf("sync failed: %v", d.err)
+		}
+	case <-ctx.Done():
+		t.Fatal("sync did not complete")
+	}
+
+	for hash, block := range blocks {
+		if _, ok := blockChain.LocalGet(hash); !ok {
+			t.Errorf("block %v was not stored", block)
+		}
+	}
+	if !synchronizer.highQC.Equals(qc) {
+		t.Errorf("synchronizer did not advance to the synced QC")
+	}
+	// the ranges that were first requested from the replica with forged blocks were fetched from the other replicas.
+	cfg.mut.Lock()
+	for _, id := range []hotstuff.ID{2, 3, 4} {
+		if cfg.requests[id] == 0 {
+			t.Errorf("no ranges were requested from replica %d", id)
+		}
+	}
+	cfg.mut.Unlock()
+}
+
+func TestBlockSyncIgnoresSmallLag(t *testing.T) {
+	_, qc := createChain(DefaultLagThreshold)
+	cfg := &testConfig{
+		replicas: map[hotstuff.ID]modules.Replica{1: nil, 2: nil},
+		forged:   hotstuff.NewIDSet(),
+		requests: make(map[hotstuff.ID]int),
+	}
+	builder := modules.NewBuilder(1, nil)
+	bs := New()
+	builder.Add(
+		eventloop.New(100),
+		logging.New("test"),
+		blockchain.New(),
+		testConsensus{},
+		testCrypto{},
+		cfg,
+		&testSynchronizer{},
+		bs,
+	)
+	builder.Build()
+
+	bs.onPropose(hotstuff.ProposeMsg{ID: 2, Block: hotstuff.NewBlock(qc.BlockHash(), qc, "cmd", qc.View()+1, 2)})
+	if bs.Syncing() {
+		t.Error("started syncing, but the replica is only a few views behind")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"crypto/x509"
 	"errors"
+	"fmt"
 	"io"
 	"math"
 	"sync"
@@ -15,6 +16,7 @@ import (
 	"github.com/relab/gorums"
 	"github.com/relab/hotstuff"
 	"github.com/relab/hotstuff/backend"
+	"github.com/relab/hotstuff/crypto/bls12"
 	"github.com/relab/hotstuff/eventloop"
 	"github.com/relab/hotstuff/internal/proto/clientpb"
 	"github.com/relab/hotstuff/logging"
@@ -56,6 +58,8 @@ type Config struct {
 	RateStep         float64       // rate limit step up
 	RateStepInterval time.Duration // step up interval
 	Timeout          time.Duration
+	// The committee public key used to encrypt commands. Commands are not encrypted if nil.
+	ThresholdPublicKey *bls12.ThresholdPublicKey
 }
 
 // Client is a hotstuff client.
@@ -77,6 +81,7 @@ type Client struct {
 	stepUp           float64
 	stepUpInterval   time.Duration
 	timeout          time.Duration
+	thresholdPub     *bls12.ThresholdPublicKey
 }
 
 // InitModule initializes the client.
@@ -100,6 +105,7 @@ func New(conf Config, builder modules.Builder) (client *Client) {
 		stepUp:           conf.RateStep,
 		stepUpInterval:   conf.RateStepInterval,
 		timeout:          conf.Timeout,
+		thresholdPub:     conf.ThresholdPublicKey,
 	}
 
 	builder.Add(client)
@@ -239,6 +245,11 @@ loop:
 			SequenceNumber: num,
 			Data:           data[:n],
 		}
+		if c.thresholdPub != nil {
+			if err := c.encrypt(cmd); err != nil {
+				return err
+			}
+		}
 
 		ctx, cancel := context.WithTimeout(ctx, c.timeout)
 		promise := c.gorumsConfig.ExecCommand(ctx, cmd)
@@ -260,6 +271,16 @@ loop:
 	return nil
 }
 
+// encrypt replaces the data of the command with a ciphertext that can only be decrypted by the replicas together.
+func (c *Client) encrypt(cmd *clientpb.Command) error {
+	ct, err := bls12.Encrypt(c.thresholdPub, cmd.GetData(), cmd.EncryptionLabel())
+	if err != nil {
+		return fmt.Errorf("failed to encrypt command: %w", err)
+	}
+	cmd.Data = ct.ToBytes()
+	return nil
+}
+
 // handleCommands will get pending commands from the pendingCmds channel and then
 // handle them as they become acknowledged by the replicas. We expect the commands to be
 // acknowledged in the order that they were sent.
@@ -0,0 +1,102 @@
+// Command hotstuff-signer is a reference implementation of a remote signer for hotstuff replicas.
+// It holds the private key of a replica, and signs messages for the replica over gRPC,
+// refusing to sign conflicting blocks and timeouts (see package crypto/remote).
+//
+// Without TLS, the signer should only listen on the loopback interface.
+// With TLS, the replica must present a certificate that is signed by the given certificate authority.
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/crypto/keygen"
+	"github.com/relab/hotstuff/crypto/keystore"
+	"github.com/relab/hotstuff/crypto/remote"
+	"github.com/relab/hotstuff/internal/proto/signerpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+func main() {
+	var (
+		id       = flag.Uint("id", 0, "ID of the replica that the signer signs for")
+		keyFile  = flag.String("key", "", "Private key file of the replica (ecdsa, eddsa or bls12)")
+		passFile = flag.String("passphrase-file", "", "File with the passphrase of an encrypted key (prompts for it if empty)")
+		state    = flag.String("state", "signer-state.json", "File that the high-watermark of signed messages is stored in")
+		listen   = flag.String("listen", "127.0.0.1:9090", "Address to listen on")
+		cert     = flag.String("cert", "", "TLS certificate of the signer (enables TLS)")
+		certKey  = flag.String("cert-key", "", "Private key of the TLS certificate")
+		caFile   = flag.String("ca", "", "Certificate authority that signs the certificates of the replicas")
+	)
+	flag.Parse()
+
+	if *id == 0 || *keyFile == "" {
+		log.Fatalln("Both -id and -key are required")
+	}
+	passphrase := keystore.Prompt("Passphrase for " + *keyFile + ": ")
+	if *passFile != "" {
+		passphrase = keystore.PassphraseFile(*passFile)
+	}
+	key, err := keygen.ReadPrivateKeyFile(*keyFile, passphrase)
+	if err != nil {
+		log.Fatalf("Failed to read private key: %v", err)
+	}
+	signer, err := remote.NewSigner(hotstuff.ID(*id), key, *state)
+	if err != nil {
+		log.Fatalf("Failed to create signer: %v", err)
+	}
+
+	var opts []grpc.ServerOption
+	if *cert != "" {
+		creds, err := loadCredentials(*cert, *certKey, *caFile)
+		if err != nil {
+			log.Fatalf("Failed to load TLS credentials: %v", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	srv := grpc.NewServer(opts...)
+	signerpb.RegisterSignerServer(srv, signer)
+
+	lis, err := net.Listen("tcp", *listen)
+	if err != nil {
+		log.Fatalf("Failed to listen: %v", err)
+	}
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		<-sig
+		srv.GracefulStop()
+	}()
+	log.Printf("Signing for replica %d on %s", *id, lis.Addr())
+	if err := srv.Serve(lis); err != nil {
+		log.Fatalf("Failed to serve: %v", err)
+	}
+}
+
+func loadCredentials(certFile, keyFile, caFile string) (credentials.TransportCredentials, error) {
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(ca) {
+		log.Fatalf("No certificates found in %s", caFile)
+	}
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}), nil
+}
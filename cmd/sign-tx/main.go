@@ -9,6 +9,7 @@ import (
 	"math/big"
 	"os"
 
+	"github.com/relab/hotstuff/crypto/keystore"
 	"github.com/relab/hotstuff/txpool"
 )
 
@@ -21,28 +22,62 @@ func main() {
 		data     = flag.String("data", "", "Transaction data (hex)")
 		nonce    = flag.Uint64("nonce", 0, "Transaction nonce")
 		chainID  = flag.Int64("chainId", 1337, "Chain ID")
-		genKey   = flag.Bool("genkey", false, "Generate a new key pair")
-		privKey  = flag.String("key", "", "Private key (hex, without 0x prefix)")
+		genKey   = flag.Bool("genkey", false, "Generate a new key pair and write it to the keystore file")
+		keyFile  = flag.String("keystore", "", "Encrypted key file (Web3 Secret Storage v3 JSON)")
+		passFile = flag.String("passphrase-file", "", "File with the passphrase of the keystore file (prompts for it if empty)")
+		keyEnv   = flag.String("key-env", "", "Environment variable with the private key (hex, without 0x prefix); prefer -keystore")
+		privKey  = flag.String("key", "", "Deprecated: use -keystore or -key-env. Private key (hex, without 0x prefix)")
 	)
 	flag.Parse()
 
 	// Generate key pair if requested
 	if *genKey {
-		generateKeyPair()
+		if *keyFile == "" {
+			*keyFile = "account.json"
+		}
+		passphrase := keystore.PromptNew("Passphrase for " + *keyFile + ": ")
+		if *passFile != "" {
+			passphrase = keystore.PassphraseFile(*passFile)
+		}
+		generateKeyPair(*keyFile, passphrase)
 		return
 	}
 
-	if *privKey == "" {
-		fmt.Println("Error: Private key is required. Use -key flag or -genkey to generate a new key.")
+	var (
+		privateKey *ecdsa.PrivateKey
+		err        error
+	)
+	switch {
+	case *keyFile != "":
+		passphrase := keystore.Prompt("Passphrase for " + *keyFile + ": ")
+		if *passFile != "" {
+			passphrase = keystore.PassphraseFile(*passFile)
+		}
+		privateKey, err = txpool.ReadKeyFile(*keyFile, passphrase)
+		if err != nil {
+			log.Fatalf("Failed to read key file: %v", err)
+		}
+	case *keyEnv != "":
+		keyHex, ok := os.LookupEnv(*keyEnv)
+		if !ok {
+			log.Fatalf("Environment variable %s is not set", *keyEnv)
+		}
+		privateKey, err = parsePrivateKey(keyHex)
+		if err != nil {
+			log.Fatalf("Invalid private key: %v", err)
+		}
+	case *privKey != "":
+		// the key is visible to other users in the process list and is saved in the shell history.
+		log.Println("Warning: -key is deprecated and exposes the private key; use -keystore or -key-env instead")
+		privateKey, err = parsePrivateKey(*privKey)
+		if err != nil {
+			log.Fatalf("Invalid private key: %v", err)
+		}
+	default:
+		fmt.Println("Error: A key is required. Use -keystore or -key-env, or -genkey to generate a new key.")
 		os.Exit(1)
 	}
 
-	// Parse private key
-	privateKey, err := parsePrivateKey(*privKey)
-	if err != nil {
-		log.Fatalf("Invalid private key: %v", err)
-	}
-
 	// Derive address from private key
 	address := txpool.AddressFromPublicKey(&privateKey.PublicKey)
 	fmt.Printf("From address: 0x%x\n", address[:])
@@ -128,21 +163,31 @@ func main() {
 	fmt.Println()
 }
 
-func generateKeyPair() {
+func generateKeyPair(keyFile string, passphrase keystore.Passphrase) {
+	if _, err := os.Stat(keyFile); err == nil {
+		log.Fatalf("Key file %s already exists", keyFile)
+	}
 	privateKey, publicKey, err := txpool.GenerateKeyPair()
 	if err != nil {
 		log.Fatalf("Failed to generate key pair: %v", err)
 	}
+	pass, err := passphrase()
+	if err != nil {
+		log.Fatalf("Failed to read passphrase: %v", err)
+	}
+	if err := txpool.WriteKeyFile(privateKey, keyFile, pass, keystore.StandardScryptN, keystore.StandardScryptP); err != nil {
+		log.Fatalf("Failed to write key file: %v", err)
+	}
 
 	address := txpool.AddressFromPublicKey(publicKey)
 
 	fmt.Printf("=== Generated Key Pair ===\n")
-	fmt.Printf("Private Key: %x\n", privateKey.D.Bytes())
+	fmt.Printf("Key File: %s\n", keyFile)
 	fmt.Printf("Public Key: %x%x\n", publicKey.X.Bytes(), publicKey.Y.Bytes())
 	fmt.Printf("Address: 0x%x\n", address[:])
 	fmt.Printf("\n=== Usage ===\n")
-	fmt.Printf("Save the private key securely!\n")
-	fmt.Printf("Use it with: ./sign-tx -key %x -to 0x... -value 1000000000000000000\n", privateKey.D.Bytes())
+	fmt.Printf("Keep the key file and its passphrase safe!\n")
+	fmt.Printf("Use it with: ./sign-tx -keystore %s -to 0x... -value 1000000000000000000\n", keyFile)
 }
 
 func parsePrivateKey(keyHex string) (*ecdsa.PrivateKey, error) {
@@ -151,12 +196,7 @@ func parsePrivateKey(keyHex string) (*ecdsa.PrivateKey, error) {
 		return nil, err
 	}
 
-	privateKey := new(ecdsa.PrivateKey)
-	privateKey.D = new(big.Int).SetBytes(keyBytes)
-	privateKey.PublicKey.Curve = txpool.GetCurve()
-	privateKey.PublicKey.X, privateKey.PublicKey.Y = privateKey.PublicKey.Curve.ScalarBaseMult(keyBytes)
-
-	return privateKey, nil
+	return txpool.PrivateKeyFromBytes(keyBytes), nil
 }
 
 // encodeTransaction encodes a transaction for eth_sendRawTransaction
@@ -52,9 +52,13 @@ type consensusBase struct {
 	opts           *modules.Options
 	synchronizer   modules.Synchronizer
 
-	kauri modules.Kauri
+	kauri     modules.Kauri
+	blockSync modules.BlockSync
+	extension modules.BlockExtension
 
 	lastVote hotstuff.View
+	// The local time at which each pending proposal was received.
+	received receipts
 
 	mut   sync.Mutex
 	bExec *hotstuff.Block
@@ -65,6 +69,7 @@ func New(impl Rules) modules.Consensus {
 	return &consensusBase{
 		impl:     impl,
 		lastVote: 0,
+		received: make(receipts),
 		bExec:    hotstuff.GetGenesis(),
 	}
 }
@@ -87,6 +92,8 @@ func (cs *consensusBase) InitModule(mods *modules.Core) {
 	)
 
 	mods.TryGet(&cs.kauri)
+	mods.TryGet(&cs.extension)
+	mods.TryGet(&cs.blockSync)
 
 	if mod, ok := cs.impl.(modules.Module); ok {
 		mod.InitModule(mods)
@@ -95,6 +102,25 @@ func (cs *consensusBase) InitModule(mods *modules.Core) {
 	cs.eventLoop.RegisterHandler(hotstuff.ProposeMsg{}, func(event any) {
 		cs.OnPropose(event.(hotstuff.ProposeMsg))
 	})
+	cs.eventLoop.RegisterHandler(hotstuff.StateSyncedEvent{}, func(event any) {
+		cs.onStateSynced(event.(hotstuff.StateSyncedEvent))
+	})
+}
+
+// onStateSynced continues from the block of a checkpoint whose state was downloaded from the other replicas.
+// The blocks before the checkpoint are treated as committed, but are not executed.
+func (cs *consensusBase) onStateSynced(event hotstuff.StateSyncedEvent) {
+	cs.blockChain.Store(event.Block)
+	cs.mut.Lock()
+	if event.Block.View() <= cs.bExec.View() {
+		cs.mut.Unlock()
+		return
+	}
+	cs.bExec = event.Block
+	cs.mut.Unlock()
+
+	cs.logger.Infof("Continuing from checkpoint at view %d", event.Block.View())
+	cs.synchronizer.AdvanceView(hotstuff.NewSyncInfo().WithQC(event.Checkpoint.QC))
 }
 
 func (cs *consensusBase) CommittedBlock() *hotstuff.Block {
@@ -157,6 +183,17 @@ func (cs *consensusBase) Propose(cert hotstuff.SyncInfo) {
 		}
 	}
 
+	if cs.extension != nil {
+		cs.extension.Extend(proposal.Block)
+	}
+
+	// sign the proposal, such that it can be used as evidence if we propose another block in the same view.
+	if sig, err := cs.crypto.CreatePartialCert(proposal.Block); err == nil {
+		proposal.Signature = sig.Signature()
+	} else {
+		cs.logger.Errorf("Propose: failed to sign block: %v", err)
+	}
+
 	cs.blockChain.Store(proposal.Block)
 	// kauri sends the proposal to only the children
 	if cs.kauri == nil {
@@ -166,27 +203,47 @@ func (cs *consensusBase) Propose(cert hotstuff.SyncInfo) {
 	cs.OnPropose(proposal)
 }
 
-func (cs *consensusBase) OnPropose(proposal hotstuff.ProposeMsg) { //nolint:gocyclo
-	// TODO: extract parts of this method into helper functions maybe?
-	cs.logger.Debugf("OnPropose: %v", proposal.Block)
-
+// verifyProposal verifies the certificates that the proposal carries.
+func verifyProposal(crypto modules.Crypto, logger logging.Logger, opts *modules.Options, proposal hotstuff.ProposeMsg) bool {
 	block := proposal.Block
 
-	if cs.opts.ShouldUseAggQC() && proposal.AggregateQC != nil {
-		highQC, ok := cs.crypto.VerifyAggregateQC(*proposal.AggregateQC)
+	if opts.ShouldUseAggQC() && proposal.AggregateQC != nil {
+		highQC, ok := crypto.VerifyAggregateQC(*proposal.AggregateQC)
 		if !ok {
-			cs.logger.Warn("OnPropose: failed to verify aggregate QC")
-			return
+			logger.Warn("OnPropose: failed to verify aggregate QC")
+			return false
 		}
 		// NOTE: for simplicity, we require that the highQC found in the AggregateQC equals the QC embedded in the block.
 		if !block.QuorumCert().Equals(highQC) {
-			cs.logger.Warn("OnPropose: block QC does not equal highQC")
-			return
+			logger.Warn("OnPropose: block QC does not equal highQC")
+			return false
 		}
 	}
 
-	if !cs.crypto.VerifyQuorumCert(block.QuorumCert()) {
-		cs.logger.Info("OnPropose: invalid QC")
+	if !crypto.VerifyQuorumCert(block.QuorumCert()) {
+		logger.Info("OnPropose: invalid QC")
+		return false
+	}
+
+	if proposal.TimeoutCert != nil && !crypto.VerifyTimeoutCert(*proposal.TimeoutCert) {
+		logger.Info("OnPropose: invalid TC")
+		return false
+	}
+	return true
+}
+
+func (cs *consensusBase) OnPropose(proposal hotstuff.ProposeMsg) { //nolint:gocyclo
+	// TODO: extract parts of this method into helper functions maybe?
+	cs.logger.Debugf("OnPropose: %v", proposal.Block)
+
+	block := proposal.Block
+
+	if cs.blockSync != nil && cs.blockSync.Syncing() {
+		cs.logger.Debug("OnPropose: syncing")
+		return
+	}
+
+	if !proposal.Verified && !verifyProposal(cs.crypto, cs.logger, cs.opts, proposal) {
 		return
 	}
 
@@ -196,6 +253,11 @@ func (cs *consensusBase) OnPropose(proposal hotstuff.ProposeMsg) { //nolint:gocy
 		return
 	}
 
+	if cs.extension != nil && !cs.extension.Verify(block) {
+		cs.logger.Info("OnPropose: invalid block metadata")
+		return
+	}
+
 	if !cs.impl.VoteRule(proposal) {
 		cs.logger.Info("OnPropose: Block not voted for")
 		return
@@ -214,11 +276,16 @@ func (cs *consensusBase) OnPropose(proposal hotstuff.ProposeMsg) { //nolint:gocy
 
 	// block is safe and was accepted
 	cs.blockChain.Store(block)
+	cs.received.add(block)
 
 	if b := cs.impl.CommitRule(block); b != nil {
-		cs.commit(b)
+		cs.commit(b, block)
 	}
-	cs.synchronizer.AdvanceView(hotstuff.NewSyncInfo().WithQC(block.QuorumCert()))
+	syncInfo := hotstuff.NewSyncInfo().WithQC(block.QuorumCert())
+	if proposal.TimeoutCert != nil {
+		syncInfo = syncInfo.WithTC(*proposal.TimeoutCert)
+	}
+	cs.synchronizer.AdvanceView(syncInfo)
 
 	if block.View() <= cs.lastVote {
 		cs.logger.Info("OnPropose: block view too old")
@@ -252,10 +319,11 @@ func (cs *consensusBase) OnPropose(proposal hotstuff.ProposeMsg) { //nolint:gocy
 	leader.Vote(pc)
 }
 
-func (cs *consensusBase) commit(block *hotstuff.Block) {
+func (cs *consensusBase) commit(block, tip *hotstuff.Block) {
 	cs.mut.Lock()
 	// can't recurse due to requiring the mutex, so we use a helper instead.
-	err := cs.commitInner(block)
+	cert := certificateOf(cs.blockChain, block, tip)
+	err := cs.commitInner(block, cert)
 	cs.mut.Unlock()
 
 	if err != nil {
@@ -263,6 +331,8 @@ func (cs *consensusBase) commit(block *hotstuff.Block) {
 		return
 	}
 
+	cs.received.prune(block.View())
+
 	// prune the blockchain and handle forked blocks
 	forkedBlocks := cs.blockChain.PruneToHeight(block.View())
 	for _, block := range forkedBlocks {
@@ -271,26 +341,85 @@ func (cs *consensusBase) commit(block *hotstuff.Block) {
 }
 
 // recursive helper for commit
-func (cs *consensusBase) commitInner(block *hotstuff.Block) error {
+func (cs *consensusBase) commitInner(block *hotstuff.Block, cert hotstuff.QuorumCert) error {
 	if cs.bExec.View() >= block.View() {
 		return nil
 	}
 	if parent, ok := cs.blockChain.Get(block.Parent()); ok {
-		err := cs.commitInner(parent)
+		// the parent is certified by the QC embedded in the block.
+		err := cs.commitInner(parent, block.QuorumCert())
 		if err != nil {
 			return err
 		}
 	} else {
 		return fmt.Errorf("failed to locate block: %s", block.Parent())
 	}
-	cs.eventLoop.AddEvent(hotstuff.ConsensusLatencyEvent{Latency: time.Since(block.Timestamp())})
+	cs.eventLoop.AddEvent(hotstuff.ConsensusLatencyEvent{
+		Latency:      time.Since(block.Timestamp()),
+		LocalLatency: cs.received.since(block),
+	})
 	cs.logger.Debug("EXEC: ", block)
-	cs.executor.Exec(block)
+	if ce, ok := cs.executor.(modules.CertifiedExecutor); ok && cert.BlockHash() == block.Hash() {
+		ce.ExecCertified(block, cert)
+	} else {
+		cs.executor.Exec(block)
+	}
 	cs.bExec = block
 	return nil
 }
 
+// certificateOf returns the quorum certificate that certified the given block.
+// The certificate is found in the child of the block on the branch that leads to tip.
+// If the child cannot be found, an empty quorum certificate is returned.
+func certificateOf(blockChain modules.BlockChain, block, tip *hotstuff.Block) hotstuff.QuorumCert {
+	child := tip
+	for child.View() > block.View() {
+		if child.Parent() == block.Hash() {
+			return child.QuorumCert()
+		}
+		parent, ok := blockChain.Get(child.Parent())
+		if !ok {
+			break
+		}
+		child = parent
+	}
+	return hotstuff.QuorumCert{}
+}
+
 // ChainLength returns the number of blocks that need to be chained together in order to commit.
 func (cs *consensusBase) ChainLength() int {
 	return cs.impl.ChainLength()
 }
+
+// receipts records when the replica received each pending proposal, using the local clock,
+// such that the commit latency can be measured without relying on the clock of the proposer.
+type receipts map[hotstuff.Hash]receipt
+
+type receipt struct {
+	view hotstuff.View
+	at   time.Time
+}
+
+// add records that the block was received now.
+func (r receipts) add(block *hotstuff.Block) {
+	if _, ok := r[block.Hash()]; !ok {
+		r[block.Hash()] = receipt{view: block.View(), at: time.Now()}
+	}
+}
+
+// since returns the time since the block was received, or 0 if the block was not received as a proposal.
+func (r receipts) since(block *hotstuff.Block) time.Duration {
+	if rc, ok := r[block.Hash()]; ok {
+		return time.Since(rc.at)
+	}
+	return 0
+}
+
+// prune removes the receipts of the blocks up to and including the given view.
+func (r receipts) prune(view hotstuff.View) {
+	for hash, rc := range r {
+		if rc.view <= view {
+			delete(r, hash)
+		}
+	}
+}
@@ -6,9 +6,11 @@ import (
 	"time"
 
 	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/consensus"
 	"github.com/relab/hotstuff/eventloop"
 	"github.com/relab/hotstuff/internal/mocks"
 	"github.com/relab/hotstuff/internal/testutil"
+	"github.com/relab/hotstuff/logging"
 	"github.com/relab/hotstuff/modules"
 	"github.com/relab/hotstuff/synchronizer"
 	"go.uber.org/mock/gomock"
@@ -61,3 +63,73 @@ func TestVote(t *testing.T) {
 		t.Error("No new view event happened")
 	}
 }
+
+// testSignature is a signature of replica 2.
+type testSignature struct{}
+
+func (testSignature) ToBytes() []byte { return nil }
+
+func (testSignature) Participants() hotstuff.IDSet {
+	ids := hotstuff.NewIDSet()
+	ids.Add(2)
+	return ids
+}
+
+// blockingCrypto blocks in Verify until it is released.
+type blockingCrypto struct {
+	modules.Crypto
+	release chan struct{}
+}
+
+func (c blockingCrypto) Verify(hotstuff.QuorumSignature, []byte) bool {
+	<-c.release
+	return true
+}
+
+// oneBlock is a block chain that only contains one block.
+type oneBlock struct {
+	modules.BlockChain
+	block *hotstuff.Block
+}
+
+func (b oneBlock) LocalGet(hash hotstuff.Hash) (*hotstuff.Block, bool) {
+	return b.block, hash == b.block.Hash()
+}
+
+// TestPipelineDeferredVote checks that the pipeline does not verify a deferred vote on the event loop,
+// which adds the vote again when a proposal arrives.
+func TestPipelineDeferredVote(t *testing.T) {
+	genesis := hotstuff.GetGenesis()
+	block := hotstuff.NewBlock(genesis.Hash(), hotstuff.NewQuorumCert(nil, 0, genesis.Hash()), "test", 1, 1)
+	cryptoImpl := blockingCrypto{release: make(chan struct{})}
+	eventLoop := eventloop.New(10)
+	builder := modules.NewBuilder(1, nil)
+	builder.Add(eventLoop, logging.New("test"), oneBlock{block: block}, cryptoImpl, consensus.NewPipeline())
+	builder.Build()
+
+	verified := make(chan hotstuff.VoteMsg, 1)
+	eventLoop.RegisterHandler(hotstuff.VoteMsg{}, func(event any) {
+		verified <- event.(hotstuff.VoteMsg)
+	}, eventloop.UnsafeRunInAddEvent())
+
+	added := make(chan struct{})
+	go func() {
+		eventLoop.AddEvent(hotstuff.VoteMsg{ID: 2, PartialCert: hotstuff.NewPartialCert(testSignature{}, block.Hash()), Deferred: true})
+		close(added)
+	}()
+	select {
+	case <-added:
+	case <-time.After(time.Second):
+		t.Fatal("the deferred vote was verified before AddEvent returned")
+	}
+
+	close(cryptoImpl.release)
+	select {
+	case vote := <-verified:
+		if !vote.Verified {
+			t.Error("the deferred vote was not marked as verified")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the deferred vote was not added after it was verified")
+	}
+}
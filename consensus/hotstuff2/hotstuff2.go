@@ -0,0 +1,119 @@
+// Package hotstuff2 implements the two-chain HotStuff-2 protocol.
+//
+// HotStuff-2 commits a block once it and its direct child are certified, like Jolteon, but keeps the linear view
+// change of HotStuff. Each replica locks on the highest certified block it has seen in a proposal, and only votes
+// for proposals whose QC is at least as high as its lock. If a leader enters its view through a QC for the previous
+// view, it proposes right away. If the previous view timed out, the leader does not know the highest lock, so it waits
+// until it has learned the highQCs of a quorum of replicas, or until a bounded time has passed, before proposing.
+// The waiting is done by the synchronizer when the ShouldWaitForHighQC option is set.
+//
+// See https://eprint.iacr.org/2023/397
+package hotstuff2
+
+import (
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+)
+
+func init() {
+	modules.RegisterModule("hotstuff2", New)
+}
+
+// HotStuff2 is an implementation of the HotStuff-2 protocol.
+type HotStuff2 struct {
+	blockChain   modules.BlockChain
+	logger       logging.Logger
+	opts         *modules.Options
+	synchronizer modules.Synchronizer
+
+	// protocol variables
+
+	bLock *hotstuff.Block // the highest certified block seen in a proposal
+}
+
+// New returns a new HotStuff2 instance.
+func New() consensus.Rules {
+	return &HotStuff2{
+		bLock: hotstuff.GetGenesis(),
+	}
+}
+
+// InitModule initializes the module.
+func (hs *HotStuff2) InitModule(mods *modules.Core) {
+	mods.Get(&hs.opts, &hs.blockChain, &hs.logger, &hs.synchronizer)
+
+	hs.opts.SetShouldWaitForHighQC()
+}
+
+func (hs *HotStuff2) qcRef(qc hotstuff.QuorumCert) (*hotstuff.Block, bool) {
+	if (hotstuff.Hash{}) == qc.BlockHash() {
+		return nil, false
+	}
+	return hs.blockChain.Get(qc.BlockHash())
+}
+
+// CommitRule decides whether an ancestor of the block can be committed.
+// The block certified by the QC of the block becomes locked, and its parent is committed
+// if the two blocks are from consecutive views.
+func (hs *HotStuff2) CommitRule(block *hotstuff.Block) *hotstuff.Block {
+	parent, ok := hs.qcRef(block.QuorumCert())
+	if !ok {
+		return nil
+	}
+	if parent.View() > hs.bLock.View() {
+		hs.logger.Debug("LOCK: ", parent)
+		hs.bLock = parent
+	}
+	grandparent, ok := hs.qcRef(parent.QuorumCert())
+	if !ok {
+		return nil
+	}
+	if parent.Parent() == grandparent.Hash() && parent.View() == grandparent.View()+1 {
+		hs.logger.Debug("COMMIT: ", grandparent)
+		return grandparent
+	}
+	return nil
+}
+
+// VoteRule decides whether to vote for the proposal or not.
+// The block must extend the block certified by its QC, and the QC must be at least as high as the locked block.
+func (hs *HotStuff2) VoteRule(proposal hotstuff.ProposeMsg) bool {
+	block := proposal.Block
+	qc := block.QuorumCert()
+	if block.Parent() != qc.BlockHash() {
+		return false
+	}
+	if qc.View() < hs.bLock.View() {
+		hs.logger.Debug("OnPropose: QC is lower than the locked block")
+		return false
+	}
+	return true
+}
+
+// ProposeRule creates a new proposal that extends the highest known QC.
+// If the previous view ended with a timeout, the TC of the previous view is included in the proposal,
+// such that replicas that have not yet received the TC can advance to the view of the proposal.
+func (hs *HotStuff2) ProposeRule(cert hotstuff.SyncInfo, cmd hotstuff.Command) (proposal hotstuff.ProposeMsg, ok bool) {
+	qc := hs.synchronizer.HighQC()
+	if certQC, ok := cert.QC(); ok && certQC.View() > qc.View() {
+		qc = certQC
+	}
+	view := hs.synchronizer.View()
+	proposal = hotstuff.ProposeMsg{
+		ID:    hs.opts.ID(),
+		Block: hotstuff.NewBlock(qc.BlockHash(), qc, cmd, view, hs.opts.ID()),
+	}
+	if tc, ok := cert.TC(); ok && qc.View()+1 != view && tc.View()+1 == view {
+		proposal.TimeoutCert = &tc
+	}
+	return proposal, true
+}
+
+// ChainLength returns the number of blocks that need to be chained together in order to commit.
+func (hs *HotStuff2) ChainLength() int {
+	return 2
+}
+
+var _ consensus.ProposeRuler = (*HotStuff2)(nil)
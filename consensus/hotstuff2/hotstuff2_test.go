@@ -0,0 +1,119 @@
+package hotstuff2
+
+import (
+	"testing"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+)
+
+type testBlockChain struct {
+	modules.BlockChain
+	blocks map[hotstuff.Hash]*hotstuff.Block
+}
+
+func (bc *testBlockChain) Get(hash hotstuff.Hash) (*hotstuff.Block, bool) {
+	block, ok := bc.blocks[hash]
+	return block, ok
+}
+
+// extend stores and returns a block in the view that extends the parent with a QC for the parent.
+func (bc *testBlockChain) extend(parent *hotstuff.Block, view hotstuff.View) *hotstuff.Block {
+	qc := hotstuff.NewQuorumCert(nil, parent.View(), parent.Hash())
+	block := hotstuff.NewBlock(parent.Hash(), qc, "cmd", view, 1)
+	bc.blocks[block.Hash()] = block
+	return block
+}
+
+type testSynchronizer struct {
+	modules.Synchronizer
+	view   hotstuff.View
+	highQC hotstuff.QuorumCert
+}
+
+func (s *testSynchronizer) View() hotstuff.View         { return s.view }
+func (s *testSynchronizer) HighQC() hotstuff.QuorumCert { return s.highQC }
+
+func newTestHotStuff2(view hotstuff.View) (*HotStuff2, *testBlockChain) {
+	blockChain := &testBlockChain{blocks: make(map[hotstuff.Hash]*hotstuff.Block)}
+	genesis := hotstuff.GetGenesis()
+	blockChain.blocks[genesis.Hash()] = genesis
+	builder := modules.NewBuilder(1, nil)
+	hs := New().(*HotStuff2)
+	hs.blockChain = blockChain
+	hs.logger = logging.New("test")
+	hs.opts = builder.Options()
+	hs.synchronizer = &testSynchronizer{view: view}
+	return hs, blockChain
+}
+
+func TestCommitRule(t *testing.T) {
+	hs, bc := newTestHotStuff2(1)
+	b1 := bc.extend(hotstuff.GetGenesis(), 1)
+	b2 := bc.extend(b1, 2)
+	b4 := bc.extend(b2, 4)
+	if got := hs.CommitRule(b4); got != b1 {
+		t.Errorf("CommitRule() = %v, want %v", got, b1)
+	}
+	if hs.bLock != b2 {
+		t.Errorf("locked block = %v, want %v", hs.bLock, b2)
+	}
+
+	// the child of b1 is certified, but it is not from the next view.
+	b3 := bc.extend(b1, 3)
+	b5 := bc.extend(b3, 5)
+	if got := hs.CommitRule(b5); got != nil {
+		t.Errorf("CommitRule() = %v, want nil", got)
+	}
+	if hs.bLock != b3 {
+		t.Errorf("locked block = %v, want %v", hs.bLock, b3)
+	}
+}
+
+func TestVoteRule(t *testing.T) {
+	hs, bc := newTestHotStuff2(5)
+	b1 := bc.extend(hotstuff.GetGenesis(), 1)
+	b2 := bc.extend(b1, 2)
+	b3 := bc.extend(b2, 3)
+	hs.CommitRule(b3) // locks b2
+
+	tests := []struct {
+		name  string
+		block *hotstuff.Block
+		want  bool
+	}{
+		{"QC for the locked block", bc.extend(b2, 5), true},
+		{"QC higher than the locked block", bc.extend(b3, 5), true},
+		{"QC lower than the locked block", bc.extend(b1, 5), false},
+		{"parent is not the QC block", hotstuff.NewBlock(b3.Hash(), b3.QuorumCert(), "cmd", 5, 1), false},
+	}
+	for _, test := range tests {
+		if got := hs.VoteRule(hotstuff.ProposeMsg{Block: test.block}); got != test.want {
+			t.Errorf("%s: VoteRule() = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestProposeRule(t *testing.T) {
+	hs, bc := newTestHotStuff2(4)
+	b2 := bc.extend(bc.extend(hotstuff.GetGenesis(), 1), 2)
+	hs.synchronizer.(*testSynchronizer).highQC = hotstuff.NewQuorumCert(nil, b2.View(), b2.Hash())
+	tc := hotstuff.NewTimeoutCert(nil, 3)
+
+	// the sync info carries a lower QC than the highQC learned while waiting.
+	cert := hotstuff.NewSyncInfo().WithQC(hotstuff.NewQuorumCert(nil, 0, hotstuff.GetGenesis().Hash())).WithTC(tc)
+	proposal, ok := hs.ProposeRule(cert, "cmd")
+	if !ok {
+		t.Fatal("no proposal")
+	}
+	if proposal.Block.View() != 4 || proposal.Block.Parent() != b2.Hash() {
+		t.Errorf("proposal does not extend the highQC in view 4: %v", proposal.Block)
+	}
+	if proposal.TimeoutCert == nil || proposal.TimeoutCert.View() != 3 {
+		t.Error("proposal does not include the TC of the previous view")
+	}
+	if !hs.VoteRule(proposal) {
+		t.Error("proposal is not voted for")
+	}
+}
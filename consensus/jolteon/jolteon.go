@@ -0,0 +1,112 @@
+// Package jolteon implements the two-chain Jolteon protocol (DiemBFT v4).
+//
+// Jolteon commits a block once it and its direct child are certified, which saves one round trip compared to the
+// three-chain rule of HotStuff. In return, the view change is quadratic: when a view times out, each replica signs
+// the view of its highQC, and the resulting timeout certificate proves the highest view that any replica in the
+// quorum may be locked on. The leader of the next view includes the TC in its proposal, and the replicas only vote
+// for the proposal if its QC is at least as high as the highest QC view in the TC.
+//
+// See https://arxiv.org/abs/2106.10362
+package jolteon
+
+import (
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+)
+
+func init() {
+	modules.RegisterModule("jolteon", New)
+}
+
+// Jolteon is an implementation of the Jolteon protocol.
+type Jolteon struct {
+	blockChain   modules.BlockChain
+	logger       logging.Logger
+	opts         *modules.Options
+	synchronizer modules.Synchronizer
+}
+
+// New returns a new Jolteon instance.
+func New() consensus.Rules {
+	return &Jolteon{}
+}
+
+// InitModule initializes the module.
+func (j *Jolteon) InitModule(mods *modules.Core) {
+	mods.Get(&j.opts, &j.blockChain, &j.logger, &j.synchronizer)
+
+	j.opts.SetShouldUseHighQCViews()
+}
+
+func (j *Jolteon) qcRef(qc hotstuff.QuorumCert) (*hotstuff.Block, bool) {
+	if (hotstuff.Hash{}) == qc.BlockHash() {
+		return nil, false
+	}
+	return j.blockChain.Get(qc.BlockHash())
+}
+
+// CommitRule decides whether an ancestor of the block can be committed.
+// A block is committed when it is certified by the QC of its direct child in the next view,
+// and that child is certified too.
+func (j *Jolteon) CommitRule(block *hotstuff.Block) *hotstuff.Block {
+	parent, ok := j.qcRef(block.QuorumCert())
+	if !ok {
+		return nil
+	}
+	j.logger.Debug("PRECOMMIT: ", parent)
+	grandparent, ok := j.qcRef(parent.QuorumCert())
+	if !ok {
+		return nil
+	}
+	if parent.Parent() == grandparent.Hash() && parent.View() == grandparent.View()+1 {
+		j.logger.Debug("COMMIT: ", grandparent)
+		return grandparent
+	}
+	return nil
+}
+
+// VoteRule decides whether to vote for the proposal or not.
+// The block must extend its QC, and either the QC is from the previous view,
+// or the proposal includes a TC for the previous view and the QC is at least as high as every highQC in the TC.
+func (j *Jolteon) VoteRule(proposal hotstuff.ProposeMsg) bool {
+	block := proposal.Block
+	qc := block.QuorumCert()
+	if block.View() < j.synchronizer.View() || block.Parent() != qc.BlockHash() {
+		return false
+	}
+	if block.View() == qc.View()+1 {
+		return true
+	}
+	tc := proposal.TimeoutCert
+	if tc == nil || tc.HighQCViews() == nil {
+		return false
+	}
+	return block.View() == tc.View()+1 && qc.View() >= tc.HighQCView()
+}
+
+// ProposeRule creates a new proposal that extends the highQC.
+// If the previous view ended with a timeout, the TC of the previous view is included in the proposal.
+func (j *Jolteon) ProposeRule(cert hotstuff.SyncInfo, cmd hotstuff.Command) (proposal hotstuff.ProposeMsg, ok bool) {
+	qc := j.synchronizer.HighQC()
+	if certQC, ok := cert.QC(); ok && certQC.View() > qc.View() {
+		qc = certQC
+	}
+	view := j.synchronizer.View()
+	proposal = hotstuff.ProposeMsg{
+		ID:    j.opts.ID(),
+		Block: hotstuff.NewBlock(qc.BlockHash(), qc, cmd, view, j.opts.ID()),
+	}
+	if tc, ok := cert.TC(); ok && qc.View()+1 != view && tc.View()+1 == view {
+		proposal.TimeoutCert = &tc
+	}
+	return proposal, true
+}
+
+// ChainLength returns the number of blocks that need to be chained together in order to commit.
+func (j *Jolteon) ChainLength() int {
+	return 2
+}
+
+var _ consensus.ProposeRuler = (*Jolteon)(nil)
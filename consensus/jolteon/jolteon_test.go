@@ -0,0 +1,121 @@
+package jolteon
+
+import (
+	"testing"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+)
+
+type testBlockChain struct {
+	modules.BlockChain
+	blocks map[hotstuff.Hash]*hotstuff.Block
+}
+
+func (bc *testBlockChain) Get(hash hotstuff.Hash) (*hotstuff.Block, bool) {
+	block, ok := bc.blocks[hash]
+	return block, ok
+}
+
+type testSynchronizer struct {
+	modules.Synchronizer
+	view   hotstuff.View
+	highQC hotstuff.QuorumCert
+}
+
+func (s *testSynchronizer) View() hotstuff.View         { return s.view }
+func (s *testSynchronizer) HighQC() hotstuff.QuorumCert { return s.highQC }
+
+func newTestJolteon(view hotstuff.View) (*Jolteon, *testBlockChain) {
+	blockChain := &testBlockChain{blocks: make(map[hotstuff.Hash]*hotstuff.Block)}
+	genesis := hotstuff.GetGenesis()
+	blockChain.blocks[genesis.Hash()] = genesis
+	builder := modules.NewBuilder(1, nil)
+	return &Jolteon{
+		blockChain:   blockChain,
+		logger:       logging.New("test"),
+		opts:         builder.Options(),
+		synchronizer: &testSynchronizer{view: view},
+	}, blockChain
+}
+
+// extend stores and returns a block in the view that extends the parent with a QC for the parent.
+func (bc *testBlockChain) extend(parent *hotstuff.Block, view hotstuff.View) *hotstuff.Block {
+	qc := hotstuff.NewQuorumCert(nil, parent.View(), parent.Hash())
+	block := hotstuff.NewBlock(parent.Hash(), qc, "cmd", view, 1)
+	bc.blocks[block.Hash()] = block
+	return block
+}
+
+func TestCommitRule(t *testing.T) {
+	j, bc := newTestJolteon(1)
+	b1 := bc.extend(hotstuff.GetGenesis(), 1)
+	b2 := bc.extend(b1, 2)
+	b4 := bc.extend(b2, 4)
+	if got := j.CommitRule(b4); got != b1 {
+		t.Errorf("CommitRule() = %v, want %v", got, b1)
+	}
+
+	// the child of b1 is certified, but it is not from the next view.
+	b3 := bc.extend(b1, 3)
+	b5 := bc.extend(b3, 5)
+	if got := j.CommitRule(b5); got != nil {
+		t.Errorf("CommitRule() = %v, want nil", got)
+	}
+}
+
+func TestVoteRule(t *testing.T) {
+	j, bc := newTestJolteon(4)
+	b1 := bc.extend(hotstuff.GetGenesis(), 1)
+	b2 := bc.extend(b1, 2)
+	b3 := bc.extend(b2, 3)
+	b4 := bc.extend(b2, 4)
+
+	tc := func(highQCViews map[hotstuff.ID]hotstuff.View) *hotstuff.TimeoutCert {
+		tc := hotstuff.NewTimeoutCertWithHighQCs(nil, 3, highQCViews)
+		return &tc
+	}
+	tests := []struct {
+		name     string
+		proposal hotstuff.ProposeMsg
+		want     bool
+	}{
+		{"QC from previous view", hotstuff.ProposeMsg{Block: bc.extend(b3, 4)}, true},
+		{"old view", hotstuff.ProposeMsg{Block: b3}, false},
+		{"no TC", hotstuff.ProposeMsg{Block: b4}, false},
+		{"TC without highQC views", hotstuff.ProposeMsg{Block: b4, TimeoutCert: func() *hotstuff.TimeoutCert {
+			tc := hotstuff.NewTimeoutCert(nil, 3)
+			return &tc
+		}()}, false},
+		{"QC at highest highQC", hotstuff.ProposeMsg{Block: b4, TimeoutCert: tc(map[hotstuff.ID]hotstuff.View{1: 2, 2: 1, 3: 2})}, true},
+		{"QC below highest highQC", hotstuff.ProposeMsg{Block: b4, TimeoutCert: tc(map[hotstuff.ID]hotstuff.View{1: 2, 2: 3, 3: 2})}, false},
+	}
+	for _, test := range tests {
+		if got := j.VoteRule(test.proposal); got != test.want {
+			t.Errorf("%s: VoteRule() = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestProposeRule(t *testing.T) {
+	j, bc := newTestJolteon(4)
+	b2 := bc.extend(bc.extend(hotstuff.GetGenesis(), 1), 2)
+	highQC := hotstuff.NewQuorumCert(nil, b2.View(), b2.Hash())
+	j.synchronizer.(*testSynchronizer).highQC = highQC
+	tc := hotstuff.NewTimeoutCertWithHighQCs(nil, 3, map[hotstuff.ID]hotstuff.View{1: 2})
+
+	proposal, ok := j.ProposeRule(hotstuff.NewSyncInfo().WithTC(tc), "cmd")
+	if !ok {
+		t.Fatal("no proposal")
+	}
+	if proposal.Block.View() != 4 || proposal.Block.Parent() != b2.Hash() {
+		t.Errorf("proposal does not extend the highQC in view 4: %v", proposal.Block)
+	}
+	if proposal.TimeoutCert == nil || proposal.TimeoutCert.View() != 3 {
+		t.Error("proposal does not include the TC of the previous view")
+	}
+	if !j.VoteRule(proposal) {
+		t.Error("proposal is not voted for")
+	}
+}
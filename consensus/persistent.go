@@ -30,10 +30,14 @@ type persistentConsensusBase struct {
 	opts           *modules.Options
 	synchronizer   modules.Synchronizer
 
-	kauri modules.Kauri
+	kauri     modules.Kauri
+	extension modules.BlockExtension
+	blockSync modules.BlockSync
 
 	// Persistent state store
 	stateStore *blockchain.StateStore
+	// The local time at which each pending proposal was received.
+	received receipts
 
 	// In-memory state (loaded from persistent store)
 	mut      sync.Mutex
@@ -57,6 +61,7 @@ func NewPersistentWithStateStore(impl Rules, stateStore *blockchain.StateStore)
 		impl:       impl,
 		stateStore: stateStore,
 		bExec:      hotstuff.GetGenesis(), // will be loaded from state store in InitModule
+		received:   make(receipts),
 	}, nil
 }
 
@@ -78,6 +83,8 @@ func (cs *persistentConsensusBase) InitModule(mods *modules.Core) {
 	)
 
 	mods.TryGet(&cs.kauri)
+	mods.TryGet(&cs.extension)
+	mods.TryGet(&cs.blockSync)
 
 	if mod, ok := cs.impl.(modules.Module); ok {
 		mod.InitModule(mods)
@@ -92,6 +99,28 @@ func (cs *persistentConsensusBase) InitModule(mods *modules.Core) {
 	cs.eventLoop.RegisterHandler(hotstuff.ProposeMsg{}, func(event any) {
 		cs.OnPropose(event.(hotstuff.ProposeMsg))
 	})
+	cs.eventLoop.RegisterHandler(hotstuff.StateSyncedEvent{}, func(event any) {
+		cs.onStateSynced(event.(hotstuff.StateSyncedEvent))
+	})
+}
+
+// onStateSynced continues from the block of a checkpoint whose state was downloaded from the other replicas.
+// The blocks before the checkpoint are treated as committed, but are not executed.
+func (cs *persistentConsensusBase) onStateSynced(event hotstuff.StateSyncedEvent) {
+	cs.blockChain.Store(event.Block)
+	cs.mut.Lock()
+	if event.Block.View() <= cs.bExec.View() {
+		cs.mut.Unlock()
+		return
+	}
+	cs.bExec = event.Block
+	cs.mut.Unlock()
+	if err := cs.stateStore.SetCommittedBlockHash(event.Block.Hash()); err != nil {
+		cs.logger.Errorf("Failed to persist committed block: %v", err)
+	}
+
+	cs.logger.Infof("Continuing from checkpoint at view %d", event.Block.View())
+	cs.synchronizer.AdvanceView(hotstuff.NewSyncInfo().WithQC(event.Checkpoint.QC))
 }
 
 // loadState loads the consensus state from persistent storage
@@ -211,6 +240,17 @@ func (cs *persistentConsensusBase) Propose(cert hotstuff.SyncInfo) {
 		}
 	}
 
+	if cs.extension != nil {
+		cs.extension.Extend(proposal.Block)
+	}
+
+	// sign the proposal, such that it can be used as evidence if we propose another block in the same view.
+	if sig, err := cs.crypto.CreatePartialCert(proposal.Block); err == nil {
+		proposal.Signature = sig.Signature()
+	} else {
+		cs.logger.Errorf("Propose: failed to sign block: %v", err)
+	}
+
 	cs.blockChain.Store(proposal.Block)
 	// kauri sends the proposal to only the children
 	if cs.kauri == nil {
@@ -227,21 +267,12 @@ func (cs *persistentConsensusBase) OnPropose(proposal hotstuff.ProposeMsg) { //n
 
 	block := proposal.Block
 
-	if cs.opts.ShouldUseAggQC() && proposal.AggregateQC != nil {
-		highQC, ok := cs.crypto.VerifyAggregateQC(*proposal.AggregateQC)
-		if !ok {
-			cs.logger.Warn("OnPropose: failed to verify aggregate QC")
-			return
-		}
-		// NOTE: for simplicity, we require that the highQC found in the AggregateQC equals the QC embedded in the block.
-		if !block.QuorumCert().Equals(highQC) {
-			cs.logger.Warn("OnPropose: block QC does not equal highQC")
-			return
-		}
+	if cs.blockSync != nil && cs.blockSync.Syncing() {
+		cs.logger.Debug("OnPropose: syncing")
+		return
 	}
 
-	if !cs.crypto.VerifyQuorumCert(block.QuorumCert()) {
-		cs.logger.Info("OnPropose: invalid QC")
+	if !proposal.Verified && !verifyProposal(cs.crypto, cs.logger, cs.opts, proposal) {
 		return
 	}
 
@@ -251,6 +282,11 @@ func (cs *persistentConsensusBase) OnPropose(proposal hotstuff.ProposeMsg) { //n
 		return
 	}
 
+	if cs.extension != nil && !cs.extension.Verify(block) {
+		cs.logger.Info("OnPropose: invalid block metadata")
+		return
+	}
+
 	if !cs.impl.VoteRule(proposal) {
 		cs.logger.Info("OnPropose: Block not voted for")
 		return
@@ -269,11 +305,16 @@ func (cs *persistentConsensusBase) OnPropose(proposal hotstuff.ProposeMsg) { //n
 
 	// block is safe and was accepted
 	cs.blockChain.Store(block)
+	cs.received.add(block)
 
 	if b := cs.impl.CommitRule(block); b != nil {
-		cs.commit(b)
+		cs.commit(b, block)
 	}
-	cs.synchronizer.AdvanceView(hotstuff.NewSyncInfo().WithQC(block.QuorumCert()))
+	syncInfo := hotstuff.NewSyncInfo().WithQC(block.QuorumCert())
+	if proposal.TimeoutCert != nil {
+		syncInfo = syncInfo.WithTC(*proposal.TimeoutCert)
+	}
+	cs.synchronizer.AdvanceView(syncInfo)
 
 	// Check last vote with persistence
 	cs.mut.Lock()
@@ -319,10 +360,11 @@ func (cs *persistentConsensusBase) OnPropose(proposal hotstuff.ProposeMsg) { //n
 }
 
 // commit handles block commitment with persistence
-func (cs *persistentConsensusBase) commit(block *hotstuff.Block) {
+func (cs *persistentConsensusBase) commit(block, tip *hotstuff.Block) {
 	cs.mut.Lock()
 	// can't recurse due to requiring the mutex, so we use a helper instead.
-	err := cs.commitInner(block)
+	cert := certificateOf(cs.blockChain, block, tip)
+	err := cs.commitInner(block, cert)
 	cs.mut.Unlock()
 
 	if err != nil {
@@ -335,6 +377,8 @@ func (cs *persistentConsensusBase) commit(block *hotstuff.Block) {
 		cs.logger.Errorf("Failed to persist committed block: %v", err)
 	}
 
+	cs.received.prune(block.View())
+
 	// prune the blockchain and handle forked blocks
 	forkedBlocks := cs.blockChain.PruneToHeight(block.View())
 	for _, block := range forkedBlocks {
@@ -343,21 +387,29 @@ func (cs *persistentConsensusBase) commit(block *hotstuff.Block) {
 }
 
 // commitInner is a recursive helper for commit (same as base implementation)
-func (cs *persistentConsensusBase) commitInner(block *hotstuff.Block) error {
+func (cs *persistentConsensusBase) commitInner(block *hotstuff.Block, cert hotstuff.QuorumCert) error {
 	if cs.bExec.View() >= block.View() {
 		return nil
 	}
 	if parent, ok := cs.blockChain.Get(block.Parent()); ok {
-		err := cs.commitInner(parent)
+		// the parent is certified by the QC embedded in the block.
+		err := cs.commitInner(parent, block.QuorumCert())
 		if err != nil {
 			return err
 		}
 	} else {
 		return fmt.Errorf("failed to locate block: %s", block.Parent())
 	}
-	cs.eventLoop.AddEvent(hotstuff.ConsensusLatencyEvent{Latency: time.Since(block.Timestamp())})
+	cs.eventLoop.AddEvent(hotstuff.ConsensusLatencyEvent{
+		Latency:      time.Since(block.Timestamp()),
+		LocalLatency: cs.received.since(block),
+	})
 	cs.logger.Debug("EXEC: ", block)
-	cs.executor.Exec(block)
+	if ce, ok := cs.executor.(modules.CertifiedExecutor); ok && cert.BlockHash() == block.Hash() {
+		ce.ExecCertified(block, cert)
+	} else {
+		cs.executor.Exec(block)
+	}
 	cs.bExec = block
 	return nil
 }
@@ -0,0 +1,119 @@
+package consensus
+
+import (
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/crypto"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+	"github.com/relab/hotstuff/synchronizer"
+)
+
+func init() {
+	modules.RegisterModule("verification-pipeline", NewPipeline)
+}
+
+// Pipeline verifies proposals, votes and timeouts before they are added to the event loop,
+// such that a burst of messages does not block the event loop, and invalid messages never reach it.
+// The messages are verified by the goroutine that adds them, or on the verification pool if there is one,
+// and are marked as verified such that the handlers on the event loop do not verify them again.
+// Votes for blocks that are not known yet are passed on unverified, and are verified by the voting machine.
+type Pipeline struct {
+	blockChain modules.BlockChain
+	crypto     modules.Crypto
+	logger     logging.Logger
+	opts       *modules.Options
+	pool       modules.VerificationPool // nil if messages are verified by the goroutine that adds them.
+}
+
+// NewPipeline returns a new verification pipeline.
+func NewPipeline() *Pipeline {
+	return &Pipeline{}
+}
+
+// InitModule gives the module a reference to the Core object.
+func (p *Pipeline) InitModule(mods *modules.Core) {
+	var eventLoop *eventloop.EventLoop
+	mods.Get(
+		&p.blockChain,
+		&p.crypto,
+		&eventLoop,
+		&p.logger,
+		&p.opts,
+	)
+	mods.TryGet(&p.pool)
+
+	eventLoop.UnsafeRegisterPreprocessor(hotstuff.ProposeMsg{}, func(event any, next func(any)) {
+		p.verifyProposal(event.(hotstuff.ProposeMsg), next)
+	})
+	eventLoop.UnsafeRegisterPreprocessor(hotstuff.VoteMsg{}, func(event any, next func(any)) {
+		p.verifyVote(event.(hotstuff.VoteMsg), next)
+	})
+	eventLoop.UnsafeRegisterPreprocessor(hotstuff.TimeoutMsg{}, func(event any, next func(any)) {
+		p.verifyTimeout(event.(hotstuff.TimeoutMsg), next)
+	})
+}
+
+func (p *Pipeline) verifyProposal(proposal hotstuff.ProposeMsg, next func(any)) {
+	if proposal.Verified {
+		next(proposal)
+		return
+	}
+	// the certificates of a proposal are verified by the goroutine that receives it,
+	// since they should not be delayed by pending votes.
+	if !verifyProposal(p.crypto, p.logger, p.opts, proposal) {
+		return
+	}
+	proposal.Verified = true
+	next(proposal)
+}
+
+func (p *Pipeline) verifyVote(vote hotstuff.VoteMsg, next func(any)) {
+	if vote.Verified {
+		next(vote)
+		return
+	}
+	block, ok := p.blockChain.LocalGet(vote.PartialCert.BlockHash())
+	if !ok {
+		// the voting machine defers the vote until the block arrives, and then it passes through here again.
+		next(vote)
+		return
+	}
+	signatures := []hotstuff.QuorumSignature{vote.PartialCert.Signature()}
+	messages := [][]byte{block.ToBytes()}
+	verify := func() {
+		crypto.VerifyAll(p.pool, p.crypto, signatures, messages, func(valid bool) {
+			if !valid {
+				p.logger.Info("Pipeline: vote could not be verified")
+				return
+			}
+			vote.Verified = true
+			next(vote)
+		})
+	}
+	if vote.Deferred && p.pool == nil {
+		// a deferred vote is added again by the event loop, which must not wait for the verification.
+		go verify()
+		return
+	}
+	verify()
+}
+
+func (p *Pipeline) verifyTimeout(timeout hotstuff.TimeoutMsg, next func(any)) {
+	if timeout.Verified {
+		next(timeout)
+		return
+	}
+	signatures, messages, ok := synchronizer.TimeoutSignatures(p.opts, timeout)
+	if !ok {
+		return
+	}
+	crypto.VerifyAll(p.pool, p.crypto, signatures, messages, func(valid bool) {
+		if !valid {
+			p.logger.Info("Pipeline: timeout could not be verified")
+			return
+		}
+		timeout.Verified = true
+		next(timeout)
+	})
+}
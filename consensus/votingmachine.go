@@ -18,6 +18,8 @@ type VotingMachine struct {
 	logger        logging.Logger
 	synchronizer  modules.Synchronizer
 	opts          *modules.Options
+	power         modules.VotingPower
+	pool          modules.VerificationPool // nil if votes are verified on their own goroutines.
 
 	mut           sync.Mutex
 	verifiedVotes map[hotstuff.Hash][]hotstuff.PartialCert // verified votes that could become a QC
@@ -41,6 +43,8 @@ func (vm *VotingMachine) InitModule(mods *modules.Core) {
 		&vm.synchronizer,
 		&vm.opts,
 	)
+	mods.TryGet(&vm.power)
+	mods.TryGet(&vm.pool)
 
 	vm.eventLoop.RegisterHandler(hotstuff.VoteMsg{}, func(event any) { vm.OnVote(event.(hotstuff.VoteMsg)) })
 }
@@ -80,9 +84,21 @@ func (vm *VotingMachine) OnVote(vote hotstuff.VoteMsg) {
 		return
 	}
 
-	if vm.opts.ShouldVerifyVotesSync() {
+	switch {
+	case vote.Verified:
+		vm.collectVote(cert, block)
+	case vm.opts.ShouldVerifyVotesSync():
 		vm.verifyCert(cert, block)
-	} else {
+	case vm.pool != nil:
+		// the pool verifies votes that arrive while its workers are busy together.
+		vm.pool.Submit(cert.Signature(), block.ToBytes(), func(valid bool) {
+			if !valid {
+				vm.logger.Info("OnVote: Vote could not be verified!")
+				return
+			}
+			vm.collectVote(cert, block)
+		})
+	default:
 		go vm.verifyCert(cert, block)
 	}
 }
@@ -92,7 +108,11 @@ func (vm *VotingMachine) verifyCert(cert hotstuff.PartialCert, block *hotstuff.B
 		vm.logger.Info("OnVote: Vote could not be verified!")
 		return
 	}
+	vm.collectVote(cert, block)
+}
 
+// collectVote adds a verified vote, and creates a QC if there is a quorum of votes for the block.
+func (vm *VotingMachine) collectVote(cert hotstuff.PartialCert, block *hotstuff.Block) {
 	vm.mut.Lock()
 	defer vm.mut.Unlock()
 
@@ -114,7 +134,11 @@ func (vm *VotingMachine) verifyCert(cert hotstuff.PartialCert, block *hotstuff.B
 	votes = append(votes, cert)
 	vm.verifiedVotes[cert.BlockHash()] = votes
 
-	if len(votes) < vm.configuration.QuorumSize() {
+	signers := hotstuff.NewIDSet()
+	for _, vote := range votes {
+		signers.Add(vote.Signer())
+	}
+	if !modules.HasQuorum(vm.configuration, vm.power, block.View(), signers) {
 		return
 	}
 
@@ -106,3 +106,8 @@ func (bf Bitfield) Len() int {
 func (bf Bitfield) String() string {
 	return hotstuff.IDSetToString(&bf)
 }
+
+// Power returns the sum of the voting power of the IDs in the set.
+func (bf Bitfield) Power(power func(hotstuff.ID) uint64) uint64 {
+	return hotstuff.SetPower(&bf, power)
+}
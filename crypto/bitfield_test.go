@@ -92,3 +92,14 @@ func TestBitfieldForEach(t *testing.T) {
 		}
 	}
 }
+
+func TestBitfieldPower(t *testing.T) {
+	power := map[hotstuff.ID]uint64{1: 10, 2: 1, 9: 5}
+	var bm Bitfield
+	bm.Add(1)
+	bm.Add(9)
+	bm.Add(12)
+	if got := bm.Power(func(id hotstuff.ID) uint64 { return power[id] }); got != 15 {
+		t.Errorf("Unexpected power: got: %v, want: %v", got, 15)
+	}
+}
@@ -165,6 +165,10 @@ func (bls *bls12Base) InitModule(mods *modules.Core) {
 		&bls.opts,
 	)
 
+	// a replica whose key is held by a remote signer gets the proof of possession from the signer.
+	if bls.opts.PrivateKey() == nil {
+		return
+	}
 	pop := bls.popProve()
 	b := bls12.NewG2().ToCompressed(pop)
 	bls.opts.SetConnectionMetadata(popMetadataKey, string(b))
@@ -363,6 +367,20 @@ func (bls *bls12Base) Combine(signatures ...hotstuff.QuorumSignature) (combined
 
 // Verify verifies the given quorum signature against the message.
 func (bls *bls12Base) Verify(signature hotstuff.QuorumSignature, message []byte) bool {
+	return bls.verify(signature, message, bls.publicKey)
+}
+
+// VerifyWithKeys verifies the given quorum signature against the message using the given public keys.
+// The keys are not checked for a proof of possession, so they must come from a trusted source,
+// such as a committed reconfiguration.
+func (bls *bls12Base) VerifyWithKeys(signature hotstuff.QuorumSignature, message []byte, keys map[hotstuff.ID]hotstuff.PublicKey) bool {
+	return bls.verify(signature, message, func(id hotstuff.ID) (*PublicKey, bool) {
+		pk, ok := keys[id].(*PublicKey)
+		return pk, ok
+	})
+}
+
+func (bls *bls12Base) verify(signature hotstuff.QuorumSignature, message []byte, publicKey func(hotstuff.ID) (*PublicKey, bool)) bool {
 	s, ok := signature.(*AggregateSignature)
 	if !ok {
 		bls.logger.Panicf("cannot verify signature of incompatible type %T (expected %T)", signature, s)
@@ -372,7 +390,7 @@ func (bls *bls12Base) Verify(signature hotstuff.QuorumSignature, message []byte)
 
 	if n == 1 {
 		id := firstParticipant(s.Participants())
-		pk, ok := bls.publicKey(id)
+		pk, ok := publicKey(id)
 		if !ok {
 			bls.logger.Warnf("Missing public key for ID %d", id)
 			return false
@@ -383,7 +401,7 @@ func (bls *bls12Base) Verify(signature hotstuff.QuorumSignature, message []byte)
 	// else if l > 1:
 	pks := make([]*PublicKey, 0, n)
 	s.Participants().RangeWhile(func(id hotstuff.ID) bool {
-		pk, ok := bls.publicKey(id)
+		pk, ok := publicKey(id)
 		if ok {
 			pks = append(pks, pk)
 			return true
@@ -427,3 +445,69 @@ func (bls *bls12Base) BatchVerify(signature hotstuff.QuorumSignature, batch map[
 
 	return bls.aggregateVerify(pks, msgs, &s.sig)
 }
+
+// VerifyBatch verifies signatures on different messages with a single multi-pairing.
+// Each signature is multiplied by a random scalar, such that an invalid signature
+// cannot be cancelled out by another invalid signature in the batch.
+func (bls *bls12Base) VerifyBatch(signatures []hotstuff.QuorumSignature, messages [][]byte) bool {
+	if len(signatures) != len(messages) || len(signatures) == 0 {
+		return false
+	}
+	engine := bls12.NewEngine()
+	var aggregate bls12.PointG2
+	for i, signature := range signatures {
+		s, ok := signature.(*AggregateSignature)
+		if !ok {
+			bls.logger.Panicf("cannot verify signature of incompatible type %T (expected %T)", signature, s)
+		}
+		if s.Participants().Len() == 0 || !bls.subgroupCheck(&s.sig) {
+			return false
+		}
+		var pk bls12.PointG1
+		valid := true
+		s.Participants().RangeWhile(func(id hotstuff.ID) bool {
+			key, ok := bls.publicKey(id)
+			if !ok {
+				bls.logger.Warnf("Missing public key for ID %d", id)
+				valid = false
+				return false
+			}
+			engine.G1.Add(&pk, &pk, key.p)
+			return true
+		})
+		if !valid {
+			return false
+		}
+		q, err := engine.G2.HashToCurve(messages[i], domain)
+		if err != nil {
+			return false
+		}
+		r, err := batchScalar()
+		if err != nil {
+			bls.logger.Errorf("VerifyBatch: %v", err)
+			return false
+		}
+		var sig bls12.PointG2
+		engine.G2.MulScalarBig(&sig, &s.sig, r)
+		engine.G2.Add(&aggregate, &aggregate, &sig)
+		engine.G1.MulScalarBig(&pk, &pk, r)
+		engine.AddPair(&pk, q)
+	}
+	engine.AddPairInv(&bls12.G1One, &aggregate)
+	return engine.Result().IsOne()
+}
+
+// batchScalar returns a random non-zero 128-bit scalar for batch verification.
+func batchScalar() (*big.Int, error) {
+	var b [16]byte
+	for {
+		if _, err := rand.Read(b[:]); err != nil {
+			return nil, fmt.Errorf("bls12: failed to generate random scalar: %w", err)
+		}
+		if k := new(big.Int).SetBytes(b[:]); k.Sign() != 0 {
+			return k, nil
+		}
+	}
+}
+
+var _ modules.BatchVerifier = (*bls12Base)(nil)
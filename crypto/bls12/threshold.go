@@ -0,0 +1,449 @@
+package bls12
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	bls12 "github.com/kilic/bls12-381"
+	"github.com/relab/hotstuff"
+)
+
+// This file implements threshold encryption using curve BLS12-381.
+//
+// A committee shares a private key s using Shamir secret sharing, such that replica i holds the share s_i.
+// The committee public key is s·G1, and the verification key of replica i is s_i·G2.
+// A message is encrypted by choosing a random r, computing U = r·G1, and deriving a symmetric key from r·(s·G1).
+// The ciphertext includes a proof of knowledge of r that is bound to the encrypted data and a label,
+// such that the ciphertext cannot be modified or reused without being detected.
+// Replica i releases the decryption share s_i·U, which can be verified using the pairing
+// e(s_i·U, G2) = e(U, s_i·G2). Any threshold number of valid shares can be combined to recover s·U = r·(s·G1).
+
+const (
+	// ThresholdKeyShareFileType is the PEM type for a replica's share of a committee private key.
+	ThresholdKeyShareFileType = "BLS12-381 THRESHOLD KEY SHARE"
+
+	// ThresholdPublicKeyFileType is the PEM type for a committee public key.
+	ThresholdPublicKeyFileType = "BLS12-381 THRESHOLD PUBLIC KEY"
+)
+
+var (
+	domainEncryptionProof = []byte("HOTSTUFF_BLS12381G1_THRESHOLD_ENCRYPTION_PROOF_")
+	domainEncryptionKey   = []byte("HOTSTUFF_BLS12381G1_THRESHOLD_ENCRYPTION_KEY_")
+)
+
+// ErrNotEnoughShares is returned when there are not enough valid decryption shares to decrypt a ciphertext.
+var ErrNotEnoughShares = errors.New("bls12: not enough valid decryption shares")
+
+const (
+	g1CompressedSize = 48
+	g2CompressedSize = 96
+	scalarSize       = 32
+)
+
+// CurveOrder returns the order r of the groups G1 and G2.
+// Secret shares and polynomial coefficients are integers modulo r.
+func CurveOrder() *big.Int {
+	return new(big.Int).Set(curveOrder)
+}
+
+// ThresholdPublicKey is the public key of a committee whose private key is shared among the replicas.
+type ThresholdPublicKey struct {
+	threshold        int
+	key              bls12.PointG1
+	verificationKeys map[hotstuff.ID]bls12.PointG2
+	// groupKey is s·G2, which is interpolated from the verification keys.
+	groupKey bls12.PointG2
+}
+
+// NewThresholdPublicKey returns a committee public key.
+// Any threshold number of decryption shares are needed to decrypt a ciphertext.
+// The key is s·G1, and the verification key of each replica i is s_i·G2.
+func NewThresholdPublicKey(threshold int, key *bls12.PointG1, verificationKeys map[hotstuff.ID]*bls12.PointG2) *ThresholdPublicKey {
+	pub := &ThresholdPublicKey{
+		threshold:        threshold,
+		key:              *key,
+		verificationKeys: make(map[hotstuff.ID]bls12.PointG2, len(verificationKeys)),
+	}
+	for id, vk := range verificationKeys {
+		pub.verificationKeys[id] = *vk
+	}
+	pub.interpolateGroupKey()
+	return pub
+}
+
+// interpolateGroupKey computes the group key s·G2 from the verification keys s_i·G2 of the first threshold replicas.
+func (pub *ThresholdPublicKey) interpolateGroupKey() {
+	ids := pub.Participants()
+	if len(ids) > pub.threshold {
+		ids = ids[:pub.threshold]
+	}
+	g2 := bls12.NewG2()
+	var term bls12.PointG2
+	pub.groupKey = *g2.Zero()
+	for _, id := range ids {
+		vk := pub.verificationKeys[id]
+		g2.MulScalarBig(&term, &vk, LagrangeCoefficient(id, ids))
+		g2.Add(&pub.groupKey, &pub.groupKey, &term)
+	}
+}
+
+// GroupKey returns the group key s·G2 in compressed form.
+// It is the only key that is needed to verify threshold signatures, see VerifyThresholdSignature.
+func (pub *ThresholdPublicKey) GroupKey() []byte {
+	return bls12.NewG2().ToCompressed(&pub.groupKey)
+}
+
+// Threshold returns the number of decryption shares that are needed to decrypt a ciphertext.
+func (pub *ThresholdPublicKey) Threshold() int {
+	return pub.threshold
+}
+
+// Participants returns the IDs of the replicas that hold a share of the committee private key.
+func (pub *ThresholdPublicKey) Participants() []hotstuff.ID {
+	ids := make([]hotstuff.ID, 0, len(pub.verificationKeys))
+	for id := range pub.verificationKeys {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// ToBytes marshals the committee public key to a byte slice.
+func (pub *ThresholdPublicKey) ToBytes() []byte {
+	g1, g2 := bls12.NewG1(), bls12.NewG2()
+	b := binary.BigEndian.AppendUint32(nil, uint32(pub.threshold))
+	b = append(b, g1.ToCompressed(&pub.key)...)
+	for _, id := range pub.Participants() {
+		vk := pub.verificationKeys[id]
+		b = binary.BigEndian.AppendUint32(b, uint32(id))
+		b = append(b, g2.ToCompressed(&vk)...)
+	}
+	return b
+}
+
+// FromBytes unmarshals the committee public key from a byte slice.
+func (pub *ThresholdPublicKey) FromBytes(b []byte) error {
+	if len(b) < 4+g1CompressedSize || (len(b)-4-g1CompressedSize)%(4+g2CompressedSize) != 0 {
+		return fmt.Errorf("bls12: invalid threshold public key length: %d", len(b))
+	}
+	g1, g2 := bls12.NewG1(), bls12.NewG2()
+	pub.threshold = int(binary.BigEndian.Uint32(b))
+	b = b[4:]
+	key, err := g1.FromCompressed(b[:g1CompressedSize])
+	if err != nil {
+		return fmt.Errorf("bls12: failed to decompress threshold public key: %w", err)
+	}
+	pub.key = *key
+	b = b[g1CompressedSize:]
+	pub.verificationKeys = make(map[hotstuff.ID]bls12.PointG2)
+	for len(b) > 0 {
+		id := hotstuff.ID(binary.BigEndian.Uint32(b))
+		if id == 0 {
+			return fmt.Errorf("bls12: invalid replica ID 0 in threshold public key")
+		}
+		if _, ok := pub.verificationKeys[id]; ok {
+			return fmt.Errorf("bls12: duplicate verification key of replica %d", id)
+		}
+		vk, err := g2.FromCompressed(b[4 : 4+g2CompressedSize])
+		if err != nil {
+			return fmt.Errorf("bls12: failed to decompress verification key of replica %d: %w", id, err)
+		}
+		pub.verificationKeys[id] = *vk
+		b = b[4+g2CompressedSize:]
+	}
+	if pub.threshold < 1 || pub.threshold > len(pub.verificationKeys) {
+		return fmt.Errorf("bls12: invalid threshold %d for %d replicas", pub.threshold, len(pub.verificationKeys))
+	}
+	pub.interpolateGroupKey()
+	// the group key s·G2 must match the committee key s·G1: e(s·G1, G2) = e(G1, s·G2)
+	engine := bls12.NewEngine()
+	engine.AddPair(&pub.key, &bls12.G2One)
+	engine.AddPairInv(&bls12.G1One, &pub.groupKey)
+	if !engine.Result().IsOne() {
+		return fmt.Errorf("bls12: verification keys do not match the threshold public key")
+	}
+	return nil
+}
+
+// ThresholdKeyShare is a replica's share of a committee private key.
+type ThresholdKeyShare struct {
+	id    hotstuff.ID
+	share *big.Int
+}
+
+// NewThresholdKeyShare returns the key share of the replica with the given ID.
+func NewThresholdKeyShare(id hotstuff.ID, share *big.Int) *ThresholdKeyShare {
+	return &ThresholdKeyShare{id: id, share: new(big.Int).Mod(share, curveOrder)}
+}
+
+// ID returns the ID of the replica that holds the key share.
+func (key *ThresholdKeyShare) ID() hotstuff.ID {
+	return key.id
+}
+
+// ToBytes marshals the key share to a byte slice.
+func (key *ThresholdKeyShare) ToBytes() []byte {
+	b := binary.BigEndian.AppendUint32(nil, uint32(key.id))
+	return append(b, key.share.FillBytes(make([]byte, scalarSize))...)
+}
+
+// FromBytes unmarshals the key share from a byte slice.
+func (key *ThresholdKeyShare) FromBytes(b []byte) error {
+	if len(b) != 4+scalarSize {
+		return fmt.Errorf("bls12: invalid threshold key share length: %d", len(b))
+	}
+	key.id = hotstuff.ID(binary.BigEndian.Uint32(b))
+	key.share = new(big.Int).SetBytes(b[4:])
+	return nil
+}
+
+// DecryptionShare returns the replica's decryption share for the ciphertext.
+// The caller must verify the ciphertext before releasing the share.
+func (key *ThresholdKeyShare) DecryptionShare(ct *Ciphertext) *DecryptionShare {
+	share := &DecryptionShare{id: key.id}
+	bls12.NewG1().MulScalarBig(&share.p, &ct.u, key.share)
+	return share
+}
+
+// DecryptionShare is a replica's share of the decryption of a ciphertext.
+type DecryptionShare struct {
+	id hotstuff.ID
+	p  bls12.PointG1
+}
+
+// RestoreDecryptionShare restores the decryption share that was created by the replica with the given ID.
+func RestoreDecryptionShare(id hotstuff.ID, b []byte) (*DecryptionShare, error) {
+	p, err := bls12.NewG1().FromCompressed(b)
+	if err != nil {
+		return nil, fmt.Errorf("bls12: failed to decompress decryption share: %w", err)
+	}
+	return &DecryptionShare{id: id, p: *p}, nil
+}
+
+// ID returns the ID of the replica that created the decryption share.
+func (share *DecryptionShare) ID() hotstuff.ID {
+	return share.id
+}
+
+// ToBytes marshals the decryption share to a byte slice.
+func (share *DecryptionShare) ToBytes() []byte {
+	return bls12.NewG1().ToCompressed(&share.p)
+}
+
+// Ciphertext is a message that was encrypted to a committee public key.
+type Ciphertext struct {
+	u         bls12.PointG1
+	challenge *big.Int
+	response  *big.Int
+	data      []byte
+}
+
+// Encrypt encrypts the message to the committee public key.
+// The label is bound to the ciphertext, and must be provided again to verify and decrypt it.
+func Encrypt(pub *ThresholdPublicKey, msg, label []byte) (*Ciphertext, error) {
+	r, err := randomScalar()
+	if err != nil {
+		return nil, err
+	}
+	g1 := bls12.NewG1()
+	ct := &Ciphertext{}
+	g1.MulScalarBig(&ct.u, &bls12.G1One, r)
+
+	var shared bls12.PointG1
+	g1.MulScalarBig(&shared, &pub.key, r)
+	ct.data, err = seal(&shared, &ct.u, msg, label)
+	if err != nil {
+		return nil, err
+	}
+
+	// Schnorr proof of knowledge of r, bound to the encrypted data and the label.
+	w, err := randomScalar()
+	if err != nil {
+		return nil, err
+	}
+	var commitment bls12.PointG1
+	g1.MulScalarBig(&commitment, &bls12.G1One, w)
+	ct.challenge = proofChallenge(&ct.u, &commitment, ct.data, label)
+	ct.response = new(big.Int).Mul(ct.challenge, r)
+	ct.response.Add(ct.response, w)
+	ct.response.Mod(ct.response, curveOrder)
+	return ct, nil
+}
+
+// Verify returns true if the ciphertext is well-formed and was created with the given label.
+// Replicas must only release decryption shares for verified ciphertexts.
+func (ct *Ciphertext) Verify(label []byte) bool {
+	g1 := bls12.NewG1()
+	if g1.IsZero(&ct.u) || !g1.InCorrectSubgroup(&ct.u) {
+		return false
+	}
+	if ct.challenge.Cmp(curveOrder) >= 0 || ct.response.Cmp(curveOrder) >= 0 {
+		return false
+	}
+	// commitment = response·G1 - challenge·U
+	var commitment, cu bls12.PointG1
+	g1.MulScalarBig(&commitment, &bls12.G1One, ct.response)
+	g1.MulScalarBig(&cu, &ct.u, ct.challenge)
+	g1.Sub(&commitment, &commitment, &cu)
+	return proofChallenge(&ct.u, &commitment, ct.data, label).Cmp(ct.challenge) == 0
+}
+
+// ToBytes marshals the ciphertext to a byte slice.
+func (ct *Ciphertext) ToBytes() []byte {
+	b := bls12.NewG1().ToCompressed(&ct.u)
+	b = append(b, ct.challenge.FillBytes(make([]byte, scalarSize))...)
+	b = append(b, ct.response.FillBytes(make([]byte, scalarSize))...)
+	return append(b, ct.data...)
+}
+
+// FromBytes unmarshals the ciphertext from a byte slice.
+func (ct *Ciphertext) FromBytes(b []byte) error {
+	if len(b) < g1CompressedSize+2*scalarSize {
+		return fmt.Errorf("bls12: invalid ciphertext length: %d", len(b))
+	}
+	u, err := bls12.NewG1().FromCompressed(b[:g1CompressedSize])
+	if err != nil {
+		return fmt.Errorf("bls12: failed to decompress ciphertext: %w", err)
+	}
+	ct.u = *u
+	b = b[g1CompressedSize:]
+	ct.challenge = new(big.Int).SetBytes(b[:scalarSize])
+	ct.response = new(big.Int).SetBytes(b[scalarSize : 2*scalarSize])
+	ct.data = append([]byte(nil), b[2*scalarSize:]...)
+	return nil
+}
+
+// VerifyShare returns true if the decryption share was created for the ciphertext
+// by the replica that is identified by the share.
+func (pub *ThresholdPublicKey) VerifyShare(ct *Ciphertext, share *DecryptionShare) bool {
+	vk, ok := pub.verificationKeys[share.id]
+	if !ok {
+		return false
+	}
+	if !bls12.NewG1().InCorrectSubgroup(&share.p) {
+		return false
+	}
+	// e(s_i·U, G2) = e(U, s_i·G2)
+	engine := bls12.NewEngine()
+	engine.AddPair(&share.p, &bls12.G2One)
+	engine.AddPairInv(&ct.u, &vk)
+	return engine.Result().IsOne()
+}
+
+// Decrypt combines the decryption shares and decrypts the ciphertext.
+// Shares that fail verification are ignored.
+func (pub *ThresholdPublicKey) Decrypt(ct *Ciphertext, shares []*DecryptionShare, label []byte) ([]byte, error) {
+	valid := make(map[hotstuff.ID]*DecryptionShare)
+	for _, share := range shares {
+		if len(valid) == pub.threshold {
+			break
+		}
+		if _, ok := valid[share.id]; ok || !pub.VerifyShare(ct, share) {
+			continue
+		}
+		valid[share.id] = share
+	}
+	if len(valid) < pub.threshold {
+		return nil, ErrNotEnoughShares
+	}
+
+	ids := make([]hotstuff.ID, 0, len(valid))
+	for id := range valid {
+		ids = append(ids, id)
+	}
+
+	// interpolate s·U from the shares s_i·U.
+	g1 := bls12.NewG1()
+	var shared, term bls12.PointG1
+	for _, id := range ids {
+		g1.MulScalarBig(&term, &valid[id].p, LagrangeCoefficient(id, ids))
+		g1.Add(&shared, &shared, &term)
+	}
+	return open(&shared, &ct.u, ct.data, label)
+}
+
+// LagrangeCoefficient returns the Lagrange coefficient of the given ID for interpolating the value at zero
+// from the shares of the given IDs.
+func LagrangeCoefficient(id hotstuff.ID, ids []hotstuff.ID) *big.Int {
+	num, den := big.NewInt(1), big.NewInt(1)
+	xi := big.NewInt(int64(id))
+	for _, other := range ids {
+		if other == id {
+			continue
+		}
+		xj := big.NewInt(int64(other))
+		num.Mul(num, xj)
+		num.Mod(num, curveOrder)
+		den.Mul(den, new(big.Int).Sub(xj, xi))
+		den.Mod(den, curveOrder)
+	}
+	den.ModInverse(den, curveOrder)
+	return num.Mul(num, den).Mod(num, curveOrder)
+}
+
+func randomScalar() (*big.Int, error) {
+	for {
+		k, err := rand.Int(rand.Reader, curveOrder)
+		if err != nil {
+			return nil, fmt.Errorf("bls12: failed to generate random scalar: %w", err)
+		}
+		if k.Sign() != 0 {
+			return k, nil
+		}
+	}
+}
+
+func proofChallenge(u, commitment *bls12.PointG1, data, label []byte) *big.Int {
+	g1 := bls12.NewG1()
+	h := sha256.New()
+	_, _ = h.Write(domainEncryptionProof)
+	_, _ = h.Write(g1.ToCompressed(u))
+	_, _ = h.Write(g1.ToCompressed(commitment))
+	_, _ = h.Write(label)
+	_, _ = h.Write(data)
+	return new(big.Int).Mod(new(big.Int).SetBytes(h.Sum(nil)), curveOrder)
+}
+
+// newAEAD derives a symmetric cipher from the shared point.
+// Since a new shared point is used for each ciphertext, a fixed nonce can be used.
+func newAEAD(shared *bls12.PointG1) (cipher.AEAD, error) {
+	h := sha256.New()
+	_, _ = h.Write(domainEncryptionKey)
+	_, _ = h.Write(bls12.NewG1().ToCompressed(shared))
+	block, err := aes.NewCipher(h.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func seal(shared, u *bls12.PointG1, msg, label []byte) ([]byte, error) {
+	aead, err := newAEAD(shared)
+	if err != nil {
+		return nil, fmt.Errorf("bls12: failed to create cipher: %w", err)
+	}
+	nonce := make([]byte, aead.NonceSize())
+	ad := append(bls12.NewG1().ToCompressed(u), label...)
+	return aead.Seal(nil, nonce, msg, ad), nil
+}
+
+func open(shared, u *bls12.PointG1, data, label []byte) ([]byte, error) {
+	aead, err := newAEAD(shared)
+	if err != nil {
+		return nil, fmt.Errorf("bls12: failed to create cipher: %w", err)
+	}
+	nonce := make([]byte, aead.NonceSize())
+	ad := append(bls12.NewG1().ToCompressed(u), label...)
+	msg, err := aead.Open(nil, nonce, data, ad)
+	if err != nil {
+		return nil, fmt.Errorf("bls12: failed to decrypt: %w", err)
+	}
+	return msg, nil
+}
@@ -0,0 +1,275 @@
+package bls12
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	bls12 "github.com/kilic/bls12-381"
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/crypto"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+)
+
+// This file implements threshold signatures using curve BLS12-381.
+//
+// The replicas share a private key s in the same way as for threshold encryption (see threshold.go),
+// such that replica i holds the share s_i, and its verification key is s_i·G2.
+// Signatures are points in G1. Replica i signs a message m by computing the signature share s_i·H(m),
+// which is verified using the pairing e(s_i·H(m), G2) = e(H(m), s_i·G2).
+// Any threshold number of signature shares are combined by Lagrange interpolation into the group signature s·H(m),
+// which is verified with the group key s·G2 alone: e(s·H(m), G2) = e(H(m), s·G2).
+// Hence, a certificate is a single 48 byte signature that can be verified without knowing the replicas.
+
+func init() {
+	modules.RegisterModule("bls12-threshold", NewThreshold)
+}
+
+var domainThreshold = []byte("BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_NUL_HOTSTUFF_THRESHOLD_")
+
+var (
+	// ErrNotEnoughSignatureShares is returned when there are too few signature shares to create a threshold signature.
+	ErrNotEnoughSignatureShares = errors.New("bls12: not enough signature shares")
+
+	// ErrDistinctMessages is returned when combining signatures for a protocol where the signers sign different messages,
+	// such as the timeout certificates of Jolteon and the aggregate QCs of Fast-HotStuff.
+	// Signature shares of different messages cannot be combined into a threshold signature.
+	ErrDistinctMessages = errors.New("bls12: threshold signatures cannot combine signatures of different messages")
+)
+
+// SignatureShare is a replica's share of a threshold signature.
+type SignatureShare struct {
+	signer hotstuff.ID
+	p      bls12.PointG1
+}
+
+// RestoreSignatureShare restores the signature share that was created by the replica with the given ID.
+func RestoreSignatureShare(signer hotstuff.ID, b []byte) (*SignatureShare, error) {
+	p, err := bls12.NewG1().FromCompressed(b)
+	if err != nil {
+		return nil, fmt.Errorf("bls12: failed to restore signature share: %w", err)
+	}
+	return &SignatureShare{signer: signer, p: *p}, nil
+}
+
+// Signer returns the ID of the replica that created the signature share.
+func (share *SignatureShare) Signer() hotstuff.ID {
+	return share.signer
+}
+
+// ToBytes returns a byte representation of the signature share.
+func (share *SignatureShare) ToBytes() []byte {
+	return bls12.NewG1().ToCompressed(&share.p)
+}
+
+// Participants returns the ID of the replica that created the signature share.
+func (share *SignatureShare) Participants() hotstuff.IDSet {
+	var bf crypto.Bitfield
+	bf.Add(share.signer)
+	return &bf
+}
+
+// GroupSignature is a threshold signature that is combined from a threshold number of signature shares.
+type GroupSignature struct {
+	threshold int
+	p         bls12.PointG1
+}
+
+// RestoreGroupSignature restores a threshold signature that was combined from the given number of signature shares.
+func RestoreGroupSignature(threshold int, b []byte) (*GroupSignature, error) {
+	p, err := bls12.NewG1().FromCompressed(b)
+	if err != nil {
+		return nil, fmt.Errorf("bls12: failed to restore group signature: %w", err)
+	}
+	return &GroupSignature{threshold: threshold, p: *p}, nil
+}
+
+// ToBytes returns a byte representation of the threshold signature.
+func (sig *GroupSignature) ToBytes() []byte {
+	return bls12.NewG1().ToCompressed(&sig.p)
+}
+
+// Participants returns an empty set, since a threshold signature does not reveal its signers.
+func (sig *GroupSignature) Participants() hotstuff.IDSet {
+	return &crypto.Bitfield{}
+}
+
+// Threshold returns the number of signature shares that are needed to create the signature.
+func (sig *GroupSignature) Threshold() int {
+	return sig.threshold
+}
+
+var _ hotstuff.ThresholdSignature = (*GroupSignature)(nil)
+
+// VerifyThresholdSignature verifies a threshold signature of the message, given the compressed group key
+// and the compressed signature. It allows light clients to check quorum certificates
+// without knowing the replicas, see ThresholdPublicKey.GroupKey.
+func VerifyThresholdSignature(groupKey, message, signature []byte) bool {
+	key, err := bls12.NewG2().FromCompressed(groupKey)
+	if err != nil {
+		return false
+	}
+	sig, err := bls12.NewG1().FromCompressed(signature)
+	if err != nil {
+		return false
+	}
+	return thresholdVerify(key, message, sig)
+}
+
+// thresholdVerify checks that e(sig, G2) = e(H(m), key).
+func thresholdVerify(key *bls12.PointG2, message []byte, sig *bls12.PointG1) bool {
+	g1 := bls12.NewG1()
+	if g1.IsZero(sig) || !g1.InCorrectSubgroup(sig) {
+		return false
+	}
+	point, err := g1.HashToCurve(message, domainThreshold)
+	if err != nil {
+		return false
+	}
+	engine := bls12.NewEngine()
+	engine.AddPair(sig, &bls12.G2One)
+	engine.AddPairInv(point, key)
+	return engine.Result().IsOne()
+}
+
+// SigningKeys are the keys of the replica that are used to create and verify threshold signatures.
+// They are created by distributed key generation, and must be added to the modules together with NewThreshold.
+type SigningKeys struct {
+	// Share is the replica's share of the group's private key.
+	Share *ThresholdKeyShare
+	// Public is the group's public key. Its threshold must be the quorum size,
+	// such that a threshold signature proves that a quorum of replicas signed.
+	Public *ThresholdPublicKey
+}
+
+type thresholdBase struct {
+	logger logging.Logger
+	opts   *modules.Options
+
+	key *ThresholdKeyShare
+	pub *ThresholdPublicKey
+}
+
+// NewThreshold returns a CryptoBase implementation that creates threshold signatures with the replica's key share,
+// which it gets from the SigningKeys module. The private and public keys of the replicas are not used.
+// Since a threshold signature does not reveal its signers, it is not compatible with voting power, with Kauri,
+// which merges contributions by their signers, and with protocols where the signers of a certificate sign
+// different messages. The replica fails when it is built with any of these.
+func NewThreshold() modules.CryptoBase {
+	return &thresholdBase{}
+}
+
+// InitModule gives the module a reference to the Core object.
+func (t *thresholdBase) InitModule(mods *modules.Core) {
+	var keys *SigningKeys
+	mods.Get(
+		&keys,
+		&t.logger,
+		&t.opts,
+	)
+	t.key, t.pub = keys.Share, keys.Public
+	if t.key.ID() != t.opts.ID() {
+		t.logger.Panicf("threshold key share belongs to replica %d", t.key.ID())
+	}
+	var (
+		power modules.VotingPower
+		kauri modules.Kauri
+	)
+	if mods.TryGet(&power) {
+		t.logger.Panic("threshold signatures cannot be used with voting power, since they do not reveal their signers")
+	}
+	if mods.TryGet(&kauri) {
+		t.logger.Panic("threshold signatures cannot be used with Kauri, since they do not reveal their signers")
+	}
+	if t.opts.ShouldUseAggQC() || t.opts.ShouldUseHighQCViews() {
+		t.logger.Panicf("The consensus protocol is not compatible with threshold signatures: %v", ErrDistinctMessages)
+	}
+	t.opts.SetThresholdSignatures()
+}
+
+// Sign creates a signature share of the given message.
+func (t *thresholdBase) Sign(message []byte) (signature hotstuff.QuorumSignature, err error) {
+	g1 := bls12.NewG1()
+	point, err := g1.HashToCurve(message, domainThreshold)
+	if err != nil {
+		return nil, fmt.Errorf("bls12: hash to curve failed: %w", err)
+	}
+	share := &SignatureShare{signer: t.key.ID()}
+	g1.MulScalarBig(&share.p, point, t.key.share)
+	return share, nil
+}
+
+// Combine combines a threshold number of signature shares of the same message into a threshold signature.
+// If there are more shares than needed, the shares of the replicas with the lowest IDs are used.
+func (t *thresholdBase) Combine(signatures ...hotstuff.QuorumSignature) (combined hotstuff.QuorumSignature, err error) {
+	if t.opts.ShouldUseAggQC() || t.opts.ShouldUseHighQCViews() {
+		return nil, ErrDistinctMessages
+	}
+	if len(signatures) < 2 {
+		return nil, crypto.ErrCombineMultiple
+	}
+	shares := make(map[hotstuff.ID]*SignatureShare, len(signatures))
+	for _, sig := range signatures {
+		share, ok := sig.(*SignatureShare)
+		if !ok {
+			t.logger.Panicf("cannot combine incompatible signature type %T (expected %T)", sig, share)
+		}
+		if _, ok := shares[share.signer]; ok {
+			return nil, crypto.ErrCombineOverlap
+		}
+		shares[share.signer] = share
+	}
+	if len(shares) < t.pub.threshold {
+		return nil, ErrNotEnoughSignatureShares
+	}
+
+	ids := make([]hotstuff.ID, 0, len(shares))
+	for id := range shares {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	ids = ids[:t.pub.threshold]
+
+	g1 := bls12.NewG1()
+	sig := &GroupSignature{threshold: t.pub.threshold}
+	var term bls12.PointG1
+	for _, id := range ids {
+		g1.MulScalarBig(&term, &shares[id].p, LagrangeCoefficient(id, ids))
+		g1.Add(&sig.p, &sig.p, &term)
+	}
+	return sig, nil
+}
+
+// Verify verifies a signature share against the verification key of its signer,
+// or a threshold signature against the group key.
+func (t *thresholdBase) Verify(signature hotstuff.QuorumSignature, message []byte) bool {
+	switch sig := signature.(type) {
+	case *SignatureShare:
+		vk, ok := t.pub.verificationKeys[sig.signer]
+		if !ok {
+			t.logger.Warnf("Missing verification key for ID %d", sig.signer)
+			return false
+		}
+		return thresholdVerify(&vk, message, &sig.p)
+	case *GroupSignature:
+		if sig.threshold != t.pub.threshold {
+			return false
+		}
+		return thresholdVerify(&t.pub.groupKey, message, &sig.p)
+	default:
+		t.logger.Panicf("cannot verify signature of incompatible type %T", signature)
+		return false
+	}
+}
+
+// BatchVerify verifies a signature share against a batch that only contains the message of its signer.
+// Threshold signatures of different messages do not exist, see ErrDistinctMessages.
+func (t *thresholdBase) BatchVerify(signature hotstuff.QuorumSignature, batch map[hotstuff.ID][]byte) bool {
+	share, ok := signature.(*SignatureShare)
+	if !ok || len(batch) != 1 {
+		return false
+	}
+	message, ok := batch[share.signer]
+	return ok && t.Verify(share, message)
+}
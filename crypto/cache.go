@@ -9,10 +9,12 @@ import (
 	"sync"
 
 	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/logging"
 	"github.com/relab/hotstuff/modules"
 )
 
 type cache struct {
+	logger      logging.Logger
 	impl        modules.CryptoBase
 	mut         sync.Mutex
 	capacity    int
@@ -33,6 +35,7 @@ func NewCache(impl modules.CryptoBase, capacity int) modules.Crypto {
 // InitModule gives the module a reference to the Core object.
 // It also allows the module to set module options using the OptionsBuilder.
 func (cache *cache) InitModule(mods *modules.Core) {
+	mods.Get(&cache.logger)
 	if mod, ok := cache.impl.(modules.Module); ok {
 		mod.InitModule(mods)
 	}
@@ -72,7 +75,12 @@ func (cache *cache) evict() {
 
 // Sign signs a message and adds it to the cache for use during verification.
 func (cache *cache) Sign(message []byte) (sig hotstuff.QuorumSignature, err error) {
-	sig, err = cache.impl.Sign(message)
+	return cache.SignWithContext(modules.SigningContext{Kind: modules.SignOther}, message)
+}
+
+// SignWithContext signs a message that is described by the context and adds it to the cache for use during verification.
+func (cache *cache) SignWithContext(ctx modules.SigningContext, message []byte) (sig hotstuff.QuorumSignature, err error) {
+	sig, err = modules.SignWithContext(cache.impl, ctx, message)
 	if err != nil {
 		return nil, err
 	}
@@ -103,6 +111,18 @@ func (cache *cache) Verify(signature hotstuff.QuorumSignature, message []byte) b
 	return false
 }
 
+// VerifyWithKeys verifies the given quorum signature against the message using the given public keys.
+// The result is not cached, since the cache entries are only valid for the keys of the current configuration.
+func (cache *cache) VerifyWithKeys(signature hotstuff.QuorumSignature, message []byte, keys map[hotstuff.ID]hotstuff.PublicKey) bool {
+	kv, ok := cache.impl.(modules.KeyedVerifier)
+	if !ok {
+		// verifying with the keys of the current configuration could accept signatures of removed validators.
+		cache.logger.Errorf("%T cannot verify signatures with the keys of other configurations", cache.impl)
+		return false
+	}
+	return kv.VerifyWithKeys(signature, message, keys)
+}
+
 // BatchVerify verifies the given quorum signature against the batch of messages.
 func (cache *cache) BatchVerify(signature hotstuff.QuorumSignature, batch map[hotstuff.ID][]byte) bool {
 	// sort the list of ids from the batch map
@@ -131,6 +151,46 @@ func (cache *cache) BatchVerify(signature hotstuff.QuorumSignature, batch map[ho
 	return false
 }
 
+// VerifyBatch verifies the signatures that are not in the cache together.
+// It must only be called if the CryptoBase implements modules.BatchVerifier.
+func (cache *cache) VerifyBatch(signatures []hotstuff.QuorumSignature, messages [][]byte) bool {
+	var (
+		keys     []string
+		missSigs []hotstuff.QuorumSignature
+		missMsgs [][]byte
+	)
+	for i, signature := range signatures {
+		var key strings.Builder
+		hash := sha256.Sum256(messages[i])
+		_, _ = key.Write(hash[:])
+		_, _ = key.Write(signature.ToBytes())
+
+		if cache.check(key.String()) {
+			continue
+		}
+		keys = append(keys, key.String())
+		missSigs = append(missSigs, signature)
+		missMsgs = append(missMsgs, messages[i])
+	}
+
+	switch len(keys) {
+	case 0:
+		return true
+	case 1:
+		if !cache.impl.Verify(missSigs[0], missMsgs[0]) {
+			return false
+		}
+	default:
+		if !cache.impl.(modules.BatchVerifier).VerifyBatch(missSigs, missMsgs) {
+			return false
+		}
+	}
+	for _, key := range keys {
+		cache.insert(key)
+	}
+	return true
+}
+
 // Combine combines multiple signatures together into a single signature.
 func (cache *cache) Combine(signatures ...hotstuff.QuorumSignature) (hotstuff.QuorumSignature, error) {
 	// we don't cache the result of this operation, because it is not guaranteed to be valid.
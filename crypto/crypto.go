@@ -11,6 +11,9 @@ type crypto struct {
 	blockChain    modules.BlockChain
 	configuration modules.Configuration
 	logger        logging.Logger
+	opts          *modules.Options
+	epochs        modules.EpochManager
+	power         modules.VotingPower
 
 	modules.CryptoBase
 }
@@ -28,16 +31,24 @@ func (c *crypto) InitModule(mods *modules.Core) {
 		&c.blockChain,
 		&c.configuration,
 		&c.logger,
+		&c.opts,
 	)
+	mods.TryGet(&c.epochs)
+	mods.TryGet(&c.power)
 
 	if mod, ok := c.CryptoBase.(modules.Module); ok {
 		mod.InitModule(mods)
 	}
 }
 
+// SignWithContext signs the message, and tells the CryptoBase what the message is if it implements modules.ContextSigner.
+func (c crypto) SignWithContext(ctx modules.SigningContext, message []byte) (signature hotstuff.QuorumSignature, err error) {
+	return modules.SignWithContext(c.CryptoBase, ctx, message)
+}
+
 // CreatePartialCert signs a single block and returns the partial certificate.
 func (c crypto) CreatePartialCert(block *hotstuff.Block) (cert hotstuff.PartialCert, err error) {
-	sig, err := c.Sign(block.ToBytes())
+	sig, err := c.SignWithContext(modules.SigningContext{Kind: modules.SignBlock, View: block.View()}, block.ToBytes())
 	if err != nil {
 		return hotstuff.PartialCert{}, err
 	}
@@ -68,6 +79,19 @@ func (c crypto) CreateTimeoutCert(view hotstuff.View, timeouts []hotstuff.Timeou
 		return hotstuff.NewTimeoutCert(nil, 0), nil
 	}
 	sigs := make([]hotstuff.QuorumSignature, 0, len(timeouts))
+	if c.opts.ShouldUseHighQCViews() {
+		highQCViews := make(map[hotstuff.ID]hotstuff.View, len(timeouts))
+		for _, timeout := range timeouts {
+			qc, _ := timeout.SyncInfo.QC()
+			highQCViews[timeout.ID] = qc.View()
+			sigs = append(sigs, timeout.MsgSignature)
+		}
+		sig, err := c.Combine(sigs...)
+		if err != nil {
+			return hotstuff.TimeoutCert{}, err
+		}
+		return hotstuff.NewTimeoutCertWithHighQCs(sig, view, highQCViews), nil
+	}
 	for _, timeout := range timeouts {
 		sigs = append(sigs, timeout.ViewSignature)
 	}
@@ -119,15 +143,11 @@ func (c crypto) VerifyQuorumCert(qc hotstuff.QuorumCert) bool {
 		c.logger.DPanicf("quorum certificate has nil signature (view=%d)", qc.View())
 	}
 
-	participants := qcSignature.Participants()
-	if participants.Len() < c.configuration.QuorumSize() {
-		return false
-	}
 	block, ok := c.blockChain.Get(qc.BlockHash())
 	if !ok {
 		return false
 	}
-	return c.Verify(qc.Signature(), block.ToBytes())
+	return c.verifyQuorum(qc.View(), qcSignature, block.ToBytes())
 }
 
 // VerifyTimeoutCert verifies a timeout certificate.
@@ -136,10 +156,76 @@ func (c crypto) VerifyTimeoutCert(tc hotstuff.TimeoutCert) bool {
 	if tc.View() == 0 {
 		return true
 	}
-	if tc.Signature().Participants().Len() < c.configuration.QuorumSize() {
+	if highQCViews := tc.HighQCViews(); highQCViews != nil {
+		return c.verifyHighQCViews(tc, highQCViews)
+	}
+	return c.verifyQuorum(tc.View(), tc.Signature(), tc.View().ToBytes())
+}
+
+// verifyHighQCViews verifies a timeout certificate whose signers have signed the views of their high QCs.
+func (c crypto) verifyHighQCViews(tc hotstuff.TimeoutCert, highQCViews map[hotstuff.ID]hotstuff.View) bool {
+	participants := tc.Signature().Participants()
+	if participants.Len() != len(highQCViews) {
+		return false
+	}
+	if !modules.HasQuorum(c.configuration, c.power, tc.View(), participants) {
+		return false
+	}
+	messages := make(map[hotstuff.ID][]byte, len(highQCViews))
+	for id, view := range highQCViews {
+		if !participants.Contains(id) || view >= tc.View() {
+			return false
+		}
+		messages[id] = hotstuff.TimeoutBytes(id, tc.View(), view)
+	}
+	return c.BatchVerify(tc.Signature(), messages)
+}
+
+// verifyQuorum verifies that the quorum signature from the given view is signed by a quorum of replicas.
+// If an EpochManager is available, the signature is verified against the validators of the epoch of the view.
+// Otherwise, it is verified against the replicas in the current configuration.
+// If a VotingPower module is available, the signers must hold more than two thirds of the total voting power.
+// A threshold signature is verified with the group key alone, since it can only be created by a quorum.
+func (c crypto) verifyQuorum(view hotstuff.View, signature hotstuff.QuorumSignature, message []byte) bool {
+	if _, ok := signature.(hotstuff.ThresholdSignature); ok {
+		return c.Verify(signature, message)
+	}
+	participants := signature.Participants()
+	if c.epochs == nil {
+		if !modules.HasQuorum(c.configuration, c.power, view, participants) {
+			return false
+		}
+		return c.Verify(signature, message)
+	}
+
+	keys := c.epochs.Validators(view)
+	if c.power != nil {
+		if !modules.HasQuorum(c.configuration, c.power, view, participants) {
+			return false
+		}
+	} else if participants.Len() < hotstuff.QuorumSize(len(keys)) {
+		return false
+	}
+	valid := true
+	participants.RangeWhile(func(id hotstuff.ID) bool {
+		_, valid = keys[id]
+		return valid
+	})
+	if !valid {
+		return false
+	}
+	return c.VerifyWithKeys(signature, message, keys)
+}
+
+// VerifyWithKeys verifies the quorum signature against the message using the given public keys.
+// It returns false if the CryptoBase can only verify signatures with the keys of the current configuration.
+func (c crypto) VerifyWithKeys(signature hotstuff.QuorumSignature, message []byte, keys map[hotstuff.ID]hotstuff.PublicKey) bool {
+	kv, ok := c.CryptoBase.(modules.KeyedVerifier)
+	if !ok {
+		c.logger.Errorf("%T cannot verify signatures with the keys of other configurations", c.CryptoBase)
 		return false
 	}
-	return c.Verify(tc.Signature(), tc.View().ToBytes())
+	return kv.VerifyWithKeys(signature, message, keys)
 }
 
 // VerifyAggregateQC verifies the AggregateQC and returns the highQC, if valid.
@@ -156,7 +242,7 @@ func (c crypto) VerifyAggregateQC(aggQC hotstuff.AggregateQC) (highQC hotstuff.Q
 			SyncInfo: hotstuff.NewSyncInfo().WithQC(qc),
 		}.ToBytes()
 	}
-	if aggQC.Sig().Participants().Len() < c.configuration.QuorumSize() {
+	if !modules.HasQuorum(c.configuration, c.power, aggQC.View(), aggQC.Sig().Participants()) {
 		return hotstuff.QuorumCert{}, false
 	}
 	// both the batched aggQC signatures and the highQC must be verified
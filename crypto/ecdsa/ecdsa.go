@@ -70,6 +70,7 @@ type ecdsaBase struct {
 	configuration modules.Configuration
 	logger        logging.Logger
 	opts          *modules.Options
+	pool          modules.VerificationPool // nil if signatures are verified on the calling goroutine.
 }
 
 // New returns a new instance of the ECDSA CryptoBase implementation.
@@ -85,6 +86,7 @@ func (ec *ecdsaBase) InitModule(mods *modules.Core) {
 		&ec.logger,
 		&ec.opts,
 	)
+	mods.TryGet(&ec.pool)
 }
 
 func (ec *ecdsaBase) privateKey() *ecdsa.PrivateKey {
@@ -129,6 +131,18 @@ func (ec *ecdsaBase) Combine(signatures ...hotstuff.QuorumSignature) (hotstuff.Q
 
 // Verify verifies the given quorum signature against the message.
 func (ec *ecdsaBase) Verify(signature hotstuff.QuorumSignature, message []byte) bool {
+	return ec.verify(signature, message, ec.replicaKey)
+}
+
+// VerifyWithKeys verifies the given quorum signature against the message using the given public keys.
+func (ec *ecdsaBase) VerifyWithKeys(signature hotstuff.QuorumSignature, message []byte, keys map[hotstuff.ID]hotstuff.PublicKey) bool {
+	return ec.verify(signature, message, func(id hotstuff.ID) (hotstuff.PublicKey, bool) {
+		key, ok := keys[id]
+		return key, ok
+	})
+}
+
+func (ec *ecdsaBase) verify(signature hotstuff.QuorumSignature, message []byte, keyOf func(hotstuff.ID) (hotstuff.PublicKey, bool)) bool {
 	s, ok := signature.(crypto.Multi[*Signature])
 	if !ok {
 		ec.logger.Panicf("cannot verify signature of incompatible type %T (expected %T)", signature, s)
@@ -138,20 +152,21 @@ func (ec *ecdsaBase) Verify(signature hotstuff.QuorumSignature, message []byte)
 		return false
 	}
 
-	results := make(chan bool, n)
-	hash := sha256.Sum256(message)
+	sigs := make([]*Signature, 0, n)
 	for _, sig := range s {
-		go func(sig *Signature, hash hotstuff.Hash) {
-			results <- ec.verifySingle(sig, hash)
-		}(sig, hash)
+		sigs = append(sigs, sig)
 	}
-	valid := true
-	for range s {
-		if !<-results {
-			valid = false
+	hash := sha256.Sum256(message)
+	results := make([]bool, n)
+	crypto.ForEach(ec.pool, n, func(i int) {
+		results[i] = ec.verifySingle(sigs[i], hash, keyOf)
+	})
+	for _, valid := range results {
+		if !valid {
+			return false
 		}
 	}
-	return valid
+	return true
 }
 
 // BatchVerify verifies the given quorum signature against the batch of messages.
@@ -165,7 +180,8 @@ func (ec *ecdsaBase) BatchVerify(signature hotstuff.QuorumSignature, batch map[h
 		return false
 	}
 
-	results := make(chan bool, n)
+	sigs := make([]*Signature, 0, n)
+	hashes := make([]hotstuff.Hash, 0, n)
 	set := make(map[hotstuff.Hash]struct{})
 	for id, sig := range s {
 		message, ok := batch[id]
@@ -174,27 +190,42 @@ func (ec *ecdsaBase) BatchVerify(signature hotstuff.QuorumSignature, batch map[h
 		}
 		hash := sha256.Sum256(message)
 		set[hash] = struct{}{}
-		go func(sig *Signature, hash hotstuff.Hash) {
-			results <- ec.verifySingle(sig, hash)
-		}(sig, hash)
+		sigs = append(sigs, sig)
+		hashes = append(hashes, hash)
 	}
-	valid := true
-	for range s {
-		if !<-results {
-			valid = false
+	results := make([]bool, n)
+	crypto.ForEach(ec.pool, n, func(i int) {
+		results[i] = ec.verifySingle(sigs[i], hashes[i], ec.replicaKey)
+	})
+	for _, valid := range results {
+		if !valid {
+			return false
 		}
 	}
 
 	// valid if all partial signatures are valid and there are no duplicate messages
-	return valid && len(set) == len(batch)
+	return len(set) == len(batch)
 }
 
-func (ec *ecdsaBase) verifySingle(sig *Signature, hash hotstuff.Hash) bool {
-	replica, ok := ec.configuration.Replica(sig.Signer())
+// replicaKey returns the public key of the replica in the current configuration.
+func (ec *ecdsaBase) replicaKey(id hotstuff.ID) (hotstuff.PublicKey, bool) {
+	replica, ok := ec.configuration.Replica(id)
+	if !ok {
+		return nil, false
+	}
+	return replica.PublicKey(), true
+}
+
+func (ec *ecdsaBase) verifySingle(sig *Signature, hash hotstuff.Hash, keyOf func(hotstuff.ID) (hotstuff.PublicKey, bool)) bool {
+	key, ok := keyOf(sig.Signer())
 	if !ok {
 		ec.logger.Warnf("ecdsaBase: got signature from replica whose ID (%d) was not in the config.", sig.Signer())
 		return false
 	}
-	pk := replica.PublicKey().(*ecdsa.PublicKey)
+	pk, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		ec.logger.Warnf("ecdsaBase: unsupported public key type %T for replica %d", key, sig.Signer())
+		return false
+	}
 	return ecdsa.Verify(pk, hash[:], sig.R(), sig.S())
 }
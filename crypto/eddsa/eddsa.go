@@ -2,9 +2,13 @@
 package eddsa
 
 import (
+	"bytes"
 	"crypto/ed25519"
+	"crypto/rand"
 	"crypto/sha256"
+	"crypto/sha512"
 
+	"filippo.io/edwards25519"
 	"github.com/relab/hotstuff"
 	"github.com/relab/hotstuff/crypto"
 	"github.com/relab/hotstuff/logging"
@@ -26,6 +30,7 @@ var (
 	_ hotstuff.QuorumSignature = (*crypto.Multi[*Signature])(nil)
 	_ hotstuff.IDSet           = (*crypto.Multi[*Signature])(nil)
 	_ crypto.Signature         = (*Signature)(nil)
+	_ modules.BatchVerifier    = (*eddsaBase)(nil)
 )
 
 // Signature is an EDDSA signature.
@@ -56,6 +61,7 @@ type eddsaBase struct {
 	configuration modules.Configuration
 	logger        logging.Logger
 	opts          *modules.Options
+	pool          modules.VerificationPool // nil if signatures are verified on the calling goroutine.
 }
 
 // New returns a new instance of the EDDSA CryptoBase implementation.
@@ -71,6 +77,7 @@ func (ed *eddsaBase) InitModule(mods *modules.Core) {
 		&ed.logger,
 		&ed.opts,
 	)
+	mods.TryGet(&ed.pool)
 }
 
 func (ed *eddsaBase) privateKey() ed25519.PrivateKey {
@@ -108,6 +115,18 @@ func (ed *eddsaBase) Combine(signatures ...hotstuff.QuorumSignature) (hotstuff.Q
 
 // Verify verifies the given quorum signature against the message.
 func (ed *eddsaBase) Verify(signature hotstuff.QuorumSignature, message []byte) bool {
+	return ed.verify(signature, message, ed.replicaKey)
+}
+
+// VerifyWithKeys verifies the given quorum signature against the message using the given public keys.
+func (ed *eddsaBase) VerifyWithKeys(signature hotstuff.QuorumSignature, message []byte, keys map[hotstuff.ID]hotstuff.PublicKey) bool {
+	return ed.verify(signature, message, func(id hotstuff.ID) (hotstuff.PublicKey, bool) {
+		key, ok := keys[id]
+		return key, ok
+	})
+}
+
+func (ed *eddsaBase) verify(signature hotstuff.QuorumSignature, message []byte, keyOf func(hotstuff.ID) (hotstuff.PublicKey, bool)) bool {
 	s, ok := signature.(crypto.Multi[*Signature])
 	if !ok {
 		ed.logger.Panicf("cannot verify signature of incompatible type %T (expected %T)", signature, s)
@@ -117,19 +136,20 @@ func (ed *eddsaBase) Verify(signature hotstuff.QuorumSignature, message []byte)
 		return false
 	}
 
-	results := make(chan bool, n)
+	sigs := make([]*Signature, 0, n)
 	for _, sig := range s {
-		go func(sig *Signature, msg []byte) {
-			results <- ed.verifySingle(sig, msg)
-		}(sig, message)
-	}
-	valid := true
-	for range s {
-		if !<-results {
-			valid = false
+		sigs = append(sigs, sig)
+	}
+	results := make([]bool, n)
+	crypto.ForEach(ed.pool, n, func(i int) {
+		results[i] = ed.verifySingle(sigs[i], message, keyOf)
+	})
+	for _, valid := range results {
+		if !valid {
+			return false
 		}
 	}
-	return valid
+	return true
 }
 
 // BatchVerify verifies the given quorum signature against the batch of messages.
@@ -143,7 +163,8 @@ func (ed *eddsaBase) BatchVerify(signature hotstuff.QuorumSignature, batch map[h
 		return false
 	}
 
-	results := make(chan bool, n)
+	sigs := make([]*Signature, 0, n)
+	messages := make([][]byte, 0, n)
 	set := make(map[hotstuff.Hash]struct{})
 	for id, sig := range s {
 		message, ok := batch[id]
@@ -152,27 +173,150 @@ func (ed *eddsaBase) BatchVerify(signature hotstuff.QuorumSignature, batch map[h
 		}
 		hash := sha256.Sum256(message)
 		set[hash] = struct{}{}
-		go func(sig *Signature, msg []byte) {
-			results <- ed.verifySingle(sig, msg)
-		}(sig, message)
-	}
-	valid := true
-	for range s {
-		if !<-results {
-			valid = false
+		sigs = append(sigs, sig)
+		messages = append(messages, message)
+	}
+	results := make([]bool, n)
+	crypto.ForEach(ed.pool, n, func(i int) {
+		results[i] = ed.verifySingle(sigs[i], messages[i], ed.replicaKey)
+	})
+	for _, valid := range results {
+		if !valid {
+			return false
 		}
 	}
 
 	// valid if all partial signatures are valid and there are no duplicate messages
-	return valid && len(set) == len(batch)
+	return len(set) == len(batch)
+}
+
+// VerifyBatch verifies signatures on different messages together.
+// It checks a random linear combination of the verification equations of the signatures,
+// which takes a single multi-scalar multiplication. As in ZIP-215, the combined equation is multiplied
+// by the cofactor. Since signatures whose R or public key have a small-order component are rejected,
+// both here and in single verification, a signature passes the batch check if and only if it passes ed25519.Verify.
+func (ed *eddsaBase) VerifyBatch(signatures []hotstuff.QuorumSignature, messages [][]byte) bool {
+	if len(signatures) != len(messages) || len(signatures) == 0 {
+		return false
+	}
+	var (
+		scalars = []*edwards25519.Scalar{edwards25519.NewScalar()}
+		points  = []*edwards25519.Point{edwards25519.NewGeneratorPoint()}
+		sum     = scalars[0] // the sum of z*S, which is negated before the multiplication
+	)
+	for i, signature := range signatures {
+		s, ok := signature.(crypto.Multi[*Signature])
+		if !ok {
+			ed.logger.Panicf("cannot verify signature of incompatible type %T (expected %T)", signature, s)
+		}
+		if len(s) == 0 {
+			return false
+		}
+		for _, sig := range s {
+			key, ok := ed.replicaKey(sig.Signer())
+			if !ok {
+				ed.logger.Warnf("eddsaBase: got signature from replica whose ID (%d) was not in the config.", sig.Signer())
+				return false
+			}
+			pk, ok := key.(ed25519.PublicKey)
+			if !ok || len(pk) != ed25519.PublicKeySize || len(sig.sign) != ed25519.SignatureSize {
+				return false
+			}
+			a, r, ok := decodePoints(pk, sig.sign)
+			if !ok {
+				return false
+			}
+			sc, err := edwards25519.NewScalar().SetCanonicalBytes(sig.sign[32:])
+			if err != nil {
+				return false
+			}
+			h := sha512.New()
+			_, _ = h.Write(sig.sign[:32])
+			_, _ = h.Write(pk)
+			_, _ = h.Write(messages[i])
+			k, err := edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+			if err != nil {
+				return false
+			}
+			z, err := batchScalar()
+			if err != nil {
+				ed.logger.Errorf("VerifyBatch: %v", err)
+				return false
+			}
+			sum.MultiplyAdd(z, sc, sum)
+			scalars = append(scalars, z, edwards25519.NewScalar().Multiply(z, k))
+			points = append(points, r, a)
+		}
+	}
+	sum.Negate(sum)
+	// [8](-sum(z*S)*B + sum(z*R) + sum(z*k*A)) must be the identity
+	check := new(edwards25519.Point).VarTimeMultiScalarMult(scalars, points)
+	check.MultByCofactor(check)
+	return check.Equal(edwards25519.NewIdentityPoint()) == 1
+}
+
+// decodePoints decodes the public key A and the R component of the signature, and returns ok = false
+// if either of them is not a canonically encoded point in the prime-order subgroup.
+// Rejecting small-order components makes cofactored batch verification and cofactorless single verification agree.
+func decodePoints(pk ed25519.PublicKey, sign []byte) (a, r *edwards25519.Point, ok bool) {
+	a, err := new(edwards25519.Point).SetBytes(pk)
+	if err != nil || !bytes.Equal(a.Bytes(), pk) || !torsionFree(a) {
+		return nil, nil, false
+	}
+	r, err = new(edwards25519.Point).SetBytes(sign[:32])
+	if err != nil || !bytes.Equal(r.Bytes(), sign[:32]) || !torsionFree(r) {
+		return nil, nil, false
+	}
+	return a, r, true
+}
+
+// minusOne is the scalar L-1, where L is the order of the prime-order subgroup.
+var minusOne = func() *edwards25519.Scalar {
+	one, _ := edwards25519.NewScalar().SetCanonicalBytes(append([]byte{1}, make([]byte, 31)...))
+	return edwards25519.NewScalar().Negate(one)
+}()
+
+// torsionFree returns true if the point is in the prime-order subgroup, that is, if [L]P is the identity.
+func torsionFree(p *edwards25519.Point) bool {
+	lp := new(edwards25519.Point).VarTimeDoubleScalarBaseMult(minusOne, p, edwards25519.NewScalar())
+	lp.Add(lp, p)
+	return lp.Equal(edwards25519.NewIdentityPoint()) == 1
+}
+
+// batchScalar returns a random 128-bit scalar for batch verification.
+func batchScalar() (*edwards25519.Scalar, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:16]); err != nil {
+		return nil, err
+	}
+	return edwards25519.NewScalar().SetCanonicalBytes(b[:])
 }
 
-func (ed *eddsaBase) verifySingle(sig *Signature, message []byte) bool {
-	replica, ok := ed.configuration.Replica(sig.Signer())
+// replicaKey returns the public key of the replica in the current configuration.
+func (ed *eddsaBase) replicaKey(id hotstuff.ID) (hotstuff.PublicKey, bool) {
+	replica, ok := ed.configuration.Replica(id)
+	if !ok {
+		return nil, false
+	}
+	return replica.PublicKey(), true
+}
+
+func (ed *eddsaBase) verifySingle(sig *Signature, message []byte, keyOf func(hotstuff.ID) (hotstuff.PublicKey, bool)) bool {
+	key, ok := keyOf(sig.Signer())
 	if !ok {
 		ed.logger.Warnf("eddsaBase: got signature from replica whose ID (%d) was not in the config.", sig.Signer())
 		return false
 	}
-	pk := replica.PublicKey().(ed25519.PublicKey)
+	pk, ok := key.(ed25519.PublicKey)
+	if !ok {
+		ed.logger.Warnf("eddsaBase: unsupported public key type %T for replica %d", key, sig.Signer())
+		return false
+	}
+	if len(pk) != ed25519.PublicKeySize || len(sig.sign) != ed25519.SignatureSize {
+		return false
+	}
+	if _, _, ok := decodePoints(pk, sig.sign); !ok {
+		return false
+	}
 	return ed25519.Verify(pk, message, sig.sign)
 }
@@ -0,0 +1,314 @@
+package keygen
+
+import (
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+
+	bls12lib "github.com/kilic/bls12-381"
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/crypto/bls12"
+)
+
+// DKG implements one participant of a distributed key generation protocol for a BLS12-381 committee key.
+//
+// The protocol is the Joint-Feldman protocol: each participant acts as a dealer of a random secret,
+// by choosing a random polynomial f of degree threshold-1, sending the share f(j) privately to each participant j,
+// and broadcasting a commitment to the coefficients of f. Each participant verifies the shares it receives
+// against the dealer's commitment. The committee private key is the sum of the dealers' secrets f(0),
+// and a participant's key share is the sum of the shares it received. No participant learns the committee private key.
+//
+// All participants must agree on the set of dealers that are used to compute the keys.
+// This implementation requires valid deals from all participants.
+type DKG struct {
+	id           hotstuff.ID
+	threshold    int
+	participants []hotstuff.ID
+	coefficients []*big.Int
+
+	commitments map[hotstuff.ID]*DKGCommitment
+	shares      map[hotstuff.ID]*big.Int
+}
+
+// DKGCommitment is the commitment to a dealer's polynomial, which is broadcast to all participants.
+type DKGCommitment struct {
+	// Dealer is the ID of the participant that created the commitment.
+	Dealer hotstuff.ID
+	// PublicKey is the dealer's secret multiplied by the generator of G1.
+	PublicKey *bls12lib.PointG1
+	// Coefficients are the coefficients of the dealer's polynomial multiplied by the generator of G2.
+	Coefficients []*bls12lib.PointG2
+}
+
+// NewDKG returns a new participant of the distributed key generation protocol.
+// Any threshold number of participants can later combine their key shares, while fewer learn nothing.
+func NewDKG(id hotstuff.ID, participants []hotstuff.ID, threshold int) (*DKG, error) {
+	if threshold < 1 || threshold > len(participants) {
+		return nil, fmt.Errorf("invalid threshold %d for %d participants", threshold, len(participants))
+	}
+	found := false
+	for _, p := range participants {
+		if p == 0 {
+			return nil, fmt.Errorf("participant ID must not be zero")
+		}
+		found = found || p == id
+	}
+	if !found {
+		return nil, fmt.Errorf("participant %d is not in the list of participants", id)
+	}
+
+	coefficients := make([]*big.Int, threshold)
+	for i := range coefficients {
+		c, err := rand.Int(rand.Reader, bls12.CurveOrder())
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate polynomial: %w", err)
+		}
+		coefficients[i] = c
+	}
+
+	return &DKG{
+		id:           id,
+		threshold:    threshold,
+		participants: append([]hotstuff.ID(nil), participants...),
+		coefficients: coefficients,
+		commitments:  make(map[hotstuff.ID]*DKGCommitment),
+		shares:       make(map[hotstuff.ID]*big.Int),
+	}, nil
+}
+
+// Commitment returns the commitment to the participant's polynomial, which must be sent to all participants.
+func (d *DKG) Commitment() *DKGCommitment {
+	g1, g2 := bls12lib.NewG1(), bls12lib.NewG2()
+	c := &DKGCommitment{
+		Dealer:       d.id,
+		PublicKey:    g1.MulScalarBig(g1.New(), &bls12lib.G1One, d.coefficients[0]),
+		Coefficients: make([]*bls12lib.PointG2, len(d.coefficients)),
+	}
+	for i, a := range d.coefficients {
+		c.Coefficients[i] = g2.MulScalarBig(g2.New(), &bls12lib.G2One, a)
+	}
+	return c
+}
+
+// Share returns the share of the participant's secret for the given participant.
+// The share must be sent privately to that participant.
+func (d *DKG) Share(to hotstuff.ID) *big.Int {
+	return evalPolynomial(d.coefficients, to)
+}
+
+// Receive verifies a dealer's commitment and the share that the dealer sent to this participant.
+func (d *DKG) Receive(commitment *DKGCommitment, share *big.Int) error {
+	if !d.isParticipant(commitment.Dealer) {
+		return fmt.Errorf("dealer %d is not a participant", commitment.Dealer)
+	}
+	if _, ok := d.commitments[commitment.Dealer]; ok {
+		return fmt.Errorf("already received deal from %d", commitment.Dealer)
+	}
+	if len(commitment.Coefficients) != d.threshold {
+		return fmt.Errorf("dealer %d committed to %d coefficients, expected %d", commitment.Dealer, len(commitment.Coefficients), d.threshold)
+	}
+	g1, g2 := bls12lib.NewG1(), bls12lib.NewG2()
+	if !g1.InCorrectSubgroup(commitment.PublicKey) {
+		return fmt.Errorf("invalid public key from dealer %d", commitment.Dealer)
+	}
+	for _, c := range commitment.Coefficients {
+		if !g2.InCorrectSubgroup(c) {
+			return fmt.Errorf("invalid commitment from dealer %d", commitment.Dealer)
+		}
+	}
+
+	// the G1 public key must match the constant term of the G2 commitment: e(P, G2) = e(G1, C_0)
+	engine := bls12lib.NewEngine()
+	engine.AddPair(commitment.PublicKey, &bls12lib.G2One)
+	engine.AddPairInv(&bls12lib.G1One, commitment.Coefficients[0])
+	if !engine.Result().IsOne() {
+		return fmt.Errorf("public key of dealer %d does not match its commitment", commitment.Dealer)
+	}
+
+	// share·G2 must equal the commitment evaluated at our ID.
+	expected := g2.MulScalarBig(g2.New(), &bls12lib.G2One, share)
+	if !g2.Equal(expected, evalCommitment(commitment.Coefficients, d.id)) {
+		return fmt.Errorf("share from dealer %d does not match its commitment", commitment.Dealer)
+	}
+
+	d.commitments[commitment.Dealer] = commitment
+	d.shares[commitment.Dealer] = new(big.Int).Set(share)
+	return nil
+}
+
+// Finish computes the participant's key share and the committee public key.
+// It returns an error if a valid deal has not been received from every participant.
+func (d *DKG) Finish() (*bls12.ThresholdKeyShare, *bls12.ThresholdPublicKey, error) {
+	for _, p := range d.participants {
+		if _, ok := d.commitments[p]; !ok {
+			return nil, nil, fmt.Errorf("missing deal from participant %d", p)
+		}
+	}
+
+	g1, g2 := bls12lib.NewG1(), bls12lib.NewG2()
+	share := new(big.Int)
+	publicKey := g1.Zero()
+	verificationKeys := make(map[hotstuff.ID]*bls12lib.PointG2, len(d.participants))
+	for _, p := range d.participants {
+		verificationKeys[p] = g2.Zero()
+	}
+	for _, dealer := range d.participants {
+		c := d.commitments[dealer]
+		share.Add(share, d.shares[dealer])
+		g1.Add(publicKey, publicKey, c.PublicKey)
+		for _, p := range d.participants {
+			g2.Add(verificationKeys[p], verificationKeys[p], evalCommitment(c.Coefficients, p))
+		}
+	}
+
+	return bls12.NewThresholdKeyShare(d.id, share), bls12.NewThresholdPublicKey(d.threshold, publicKey, verificationKeys), nil
+}
+
+func (d *DKG) isParticipant(id hotstuff.ID) bool {
+	for _, p := range d.participants {
+		if p == id {
+			return true
+		}
+	}
+	return false
+}
+
+// evalPolynomial evaluates the polynomial with the given coefficients at x.
+func evalPolynomial(coefficients []*big.Int, x hotstuff.ID) *big.Int {
+	order := bls12.CurveOrder()
+	xi := big.NewInt(int64(x))
+	result := new(big.Int)
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		result.Mul(result, xi)
+		result.Add(result, coefficients[i])
+		result.Mod(result, order)
+	}
+	return result
+}
+
+// evalCommitment evaluates the committed polynomial at x in the exponent.
+func evalCommitment(coefficients []*bls12lib.PointG2, x hotstuff.ID) *bls12lib.PointG2 {
+	g2 := bls12lib.NewG2()
+	xi := big.NewInt(int64(x))
+	result := g2.Zero()
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		g2.MulScalarBig(result, result, xi)
+		g2.Add(result, result, coefficients[i])
+	}
+	return result
+}
+
+// GenerateThresholdKeys runs the distributed key generation protocol locally for the given participants.
+// It is intended for setting up test networks, where a single party may know all key shares.
+func GenerateThresholdKeys(participants []hotstuff.ID, threshold int) (map[hotstuff.ID]*bls12.ThresholdKeyShare, *bls12.ThresholdPublicKey, error) {
+	ids := append([]hotstuff.ID(nil), participants...)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	dkgs := make(map[hotstuff.ID]*DKG, len(ids))
+	for _, id := range ids {
+		d, err := NewDKG(id, ids, threshold)
+		if err != nil {
+			return nil, nil, err
+		}
+		dkgs[id] = d
+	}
+	for _, dealer := range ids {
+		commitment := dkgs[dealer].Commitment()
+		for _, id := range ids {
+			if err := dkgs[id].Receive(commitment, dkgs[dealer].Share(id)); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	shares := make(map[hotstuff.ID]*bls12.ThresholdKeyShare, len(ids))
+	var publicKey *bls12.ThresholdPublicKey
+	for _, id := range ids {
+		share, pub, err := dkgs[id].Finish()
+		if err != nil {
+			return nil, nil, err
+		}
+		shares[id] = share
+		publicKey = pub
+	}
+	return shares, publicKey, nil
+}
+
+// ThresholdKeyShareToPEM encodes the threshold key share in PEM format.
+func ThresholdKeyShareToPEM(key *bls12.ThresholdKeyShare) []byte {
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  bls12.ThresholdKeyShareFileType,
+		Bytes: key.ToBytes(),
+	})
+}
+
+// ThresholdPublicKeyToPEM encodes the committee public key in PEM format.
+func ThresholdPublicKeyToPEM(key *bls12.ThresholdPublicKey) []byte {
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  bls12.ThresholdPublicKeyFileType,
+		Bytes: key.ToBytes(),
+	})
+}
+
+// ParseThresholdKeyShare parses a PEM encoded threshold key share.
+func ParseThresholdKeyShare(buf []byte) (*bls12.ThresholdKeyShare, error) {
+	b, _ := pem.Decode(buf)
+	if b == nil {
+		return nil, fmt.Errorf("failed to decode PEM block")
+	}
+	if b.Type != bls12.ThresholdKeyShareFileType {
+		return nil, fmt.Errorf("file type did not match: %v", b.Type)
+	}
+	key := &bls12.ThresholdKeyShare{}
+	if err := key.FromBytes(b.Bytes); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// ParseThresholdPublicKey parses a PEM encoded committee public key.
+func ParseThresholdPublicKey(buf []byte) (*bls12.ThresholdPublicKey, error) {
+	b, _ := pem.Decode(buf)
+	if b == nil {
+		return nil, fmt.Errorf("failed to decode PEM block")
+	}
+	if b.Type != bls12.ThresholdPublicKeyFileType {
+		return nil, fmt.Errorf("file type did not match: %v", b.Type)
+	}
+	key := &bls12.ThresholdPublicKey{}
+	if err := key.FromBytes(b.Bytes); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// WriteThresholdKeyShareFile writes a threshold key share to the specified file.
+func WriteThresholdKeyShareFile(key *bls12.ThresholdKeyShare, filePath string) error {
+	return os.WriteFile(filePath, ThresholdKeyShareToPEM(key), 0o600)
+}
+
+// WriteThresholdPublicKeyFile writes a committee public key to the specified file.
+func WriteThresholdPublicKeyFile(key *bls12.ThresholdPublicKey, filePath string) error {
+	return os.WriteFile(filePath, ThresholdPublicKeyToPEM(key), 0o644)
+}
+
+// ReadThresholdKeyShareFile reads a threshold key share from the specified file.
+func ReadThresholdKeyShareFile(filePath string) (*bls12.ThresholdKeyShare, error) {
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return ParseThresholdKeyShare(b)
+}
+
+// ReadThresholdPublicKeyFile reads a committee public key from the specified file.
+func ReadThresholdPublicKeyFile(filePath string) (*bls12.ThresholdPublicKey, error) {
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return ParseThresholdPublicKey(b)
+}
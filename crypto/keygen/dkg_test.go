@@ -0,0 +1,253 @@
+package keygen_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/crypto/bls12"
+	"github.com/relab/hotstuff/crypto/keygen"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+)
+
+func TestThresholdDecryption(t *testing.T) {
+	ids := []hotstuff.ID{1, 2, 3, 4}
+	const threshold = 2
+	shares, pub, err := keygen.GenerateThresholdKeys(ids, threshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// round-trip the keys through PEM encoding.
+	pub, err = keygen.ParseThresholdPublicKey(keygen.ThresholdPublicKeyToPEM(pub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, share := range shares {
+		shares[id], err = keygen.ParseThresholdKeyShare(keygen.ThresholdKeyShareToPEM(share))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	msg := []byte("transfer 100 to alice")
+	label := []byte("client 1, command 1")
+	ct, err := bls12.Encrypt(pub, msg, label)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := &bls12.Ciphertext{}
+	if err := restored.FromBytes(ct.ToBytes()); err != nil {
+		t.Fatal(err)
+	}
+	if !restored.Verify(label) {
+		t.Fatal("failed to verify ciphertext")
+	}
+	if restored.Verify([]byte("client 2, command 1")) {
+		t.Error("verified ciphertext with the wrong label")
+	}
+
+	decShares := make([]*bls12.DecryptionShare, 0, len(ids))
+	for _, id := range ids {
+		s := shares[id].DecryptionShare(restored)
+		if !pub.VerifyShare(restored, s) {
+			t.Errorf("failed to verify decryption share of replica %d", id)
+		}
+		decShares = append(decShares, s)
+	}
+
+	if _, err := pub.Decrypt(restored, decShares[:threshold-1], label); !errors.Is(err, bls12.ErrNotEnoughShares) {
+		t.Errorf("expected ErrNotEnoughShares, got %v", err)
+	}
+
+	// any threshold number of shares can decrypt the ciphertext.
+	for i := 0; i+threshold <= len(decShares); i++ {
+		got, err := pub.Decrypt(restored, decShares[i:i+threshold], label)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, msg) {
+			t.Errorf("got %q, want %q", got, msg)
+		}
+	}
+
+	// a share from one replica cannot be passed off as a share from another replica.
+	forged, err := bls12.RestoreDecryptionShare(2, decShares[0].ToBytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub.VerifyShare(restored, forged) {
+		t.Error("verified decryption share with the wrong ID")
+	}
+}
+
+func TestDKGRejectsInvalidShare(t *testing.T) {
+	ids := []hotstuff.ID{1, 2, 3}
+	dealer, err := keygen.NewDKG(1, ids, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiver, err := keygen.NewDKG(2, ids, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the dealer sends the share intended for replica 3 to replica 2.
+	if err := receiver.Receive(dealer.Commitment(), dealer.Share(3)); err == nil {
+		t.Error("accepted share that does not match the commitment")
+	}
+	if err := receiver.Receive(dealer.Commitment(), dealer.Share(2)); err != nil {
+		t.Errorf("rejected valid share: %v", err)
+	}
+}
+
+func TestThresholdSignature(t *testing.T) {
+	ids := []hotstuff.ID{1, 2, 3, 4}
+	threshold := hotstuff.QuorumSize(len(ids))
+	shares, pub, err := keygen.GenerateThresholdKeys(ids, threshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signers := make([]modules.CryptoBase, 0, len(ids))
+	for _, id := range ids {
+		signer := bls12.NewThreshold()
+		builder := modules.NewBuilder(id, nil)
+		builder.Add(logging.New(""), &bls12.SigningKeys{Share: shares[id], Public: pub}, signer)
+		builder.Build()
+		signers = append(signers, signer)
+	}
+
+	msg := []byte("block 1")
+	sigShares := make([]hotstuff.QuorumSignature, 0, len(ids))
+	for i, signer := range signers {
+		s, err := signer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !signers[0].Verify(s, msg) {
+			t.Errorf("failed to verify signature share of replica %d", ids[i])
+		}
+		sigShares = append(sigShares, s)
+	}
+
+	// a share from one replica cannot be passed off as a share from another replica.
+	forged, err := bls12.RestoreSignatureShare(2, sigShares[0].ToBytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signers[0].Verify(forged, msg) {
+		t.Error("verified signature share with the wrong ID")
+	}
+
+	if _, err := signers[0].Combine(sigShares[:threshold-1]...); !errors.Is(err, bls12.ErrNotEnoughSignatureShares) {
+		t.Errorf("expected ErrNotEnoughSignatureShares, got %v", err)
+	}
+
+	// any threshold number of shares creates the same signature.
+	var want []byte
+	for i := 0; i+threshold <= len(sigShares); i++ {
+		combined, err := signers[0].Combine(sigShares[i : i+threshold]...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, ok := combined.(hotstuff.ThresholdSignature)
+		if !ok {
+			t.Fatalf("got signature of type %T, want a threshold signature", combined)
+		}
+		if sig.Participants().Len() != 0 || sig.Threshold() != threshold {
+			t.Errorf("got %d participants and threshold %d, want none and %d", sig.Participants().Len(), sig.Threshold(), threshold)
+		}
+		if want == nil {
+			want = sig.ToBytes()
+		} else if !bytes.Equal(sig.ToBytes(), want) {
+			t.Error("signatures of different quorums do not match")
+		}
+		for j, verifier := range signers {
+			if !verifier.Verify(sig, msg) {
+				t.Errorf("replica %d failed to verify threshold signature", ids[j])
+			}
+		}
+	}
+
+	// the signature can be verified with the group key alone, also after restoring the public key.
+	restored, err := keygen.ParseThresholdPublicKey(keygen.ThresholdPublicKeyToPEM(pub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bls12.VerifyThresholdSignature(restored.GroupKey(), msg, want) {
+		t.Error("failed to verify threshold signature with the group key")
+	}
+	if bls12.VerifyThresholdSignature(restored.GroupKey(), []byte("block 2"), want) {
+		t.Error("verified threshold signature of the wrong message")
+	}
+	if bls12.VerifyThresholdSignature(restored.GroupKey(), msg, sigShares[0].ToBytes()) {
+		t.Error("verified signature share with the group key")
+	}
+}
+
+func TestThresholdPublicKeyDuplicateID(t *testing.T) {
+	ids := []hotstuff.ID{1, 2, 3, 4}
+	_, pub, err := keygen.GenerateThresholdKeys(ids, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := pub.ToBytes()
+	// the threshold and the compressed G1 key are followed by the ID and verification key of each replica.
+	entry := (len(b) - 4 - 48) / len(ids)
+	var restored bls12.ThresholdPublicKey
+	if err := restored.FromBytes(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := restored.FromBytes(append(b[:len(b):len(b)], b[len(b)-entry:]...)); err == nil {
+		t.Error("accepted a threshold public key with a duplicate replica ID")
+	}
+}
+
+func TestThresholdSignatureIncompatible(t *testing.T) {
+	ids := []hotstuff.ID{1, 2, 3, 4}
+	shares, pub, err := keygen.GenerateThresholdKeys(ids, hotstuff.QuorumSize(len(ids)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	build := func(configure func(*modules.Builder), mods ...any) (panicked bool) {
+		defer func() { panicked = recover() != nil }()
+		builder := modules.NewBuilder(1, nil)
+		builder.Add(logging.New(""), &bls12.SigningKeys{Share: shares[1], Public: pub}, bls12.NewThreshold())
+		builder.Add(mods...)
+		configure(&builder)
+		builder.Build()
+		return false
+	}
+	none := func(*modules.Builder) {}
+
+	if build(none) {
+		t.Fatal("failed to build a replica with threshold signatures")
+	}
+	if !build(none, votingPower{}) {
+		t.Error("built a replica with threshold signatures and voting power")
+	}
+	if !build(func(b *modules.Builder) { b.Options().SetShouldUseHighQCViews() }) {
+		t.Error("built a replica with threshold signatures and a protocol that signs the views of the high QCs")
+	}
+	// the option may be set by a module that is initialized after the threshold signatures.
+	if !build(none, setAggQC{}) {
+		t.Error("built a replica with threshold signatures and aggregate QCs")
+	}
+}
+
+// votingPower gives every replica one vote.
+type votingPower struct{}
+
+func (votingPower) Power(hotstuff.View, hotstuff.ID) uint64 { return 1 }
+func (votingPower) TotalPower(hotstuff.View) uint64         { return 4 }
+
+// setAggQC sets the ShouldUseAggQC option when it is initialized, like Fast-HotStuff.
+type setAggQC struct{}
+
+func (setAggQC) InitModule(mods *modules.Core) {
+	var opts *modules.Options
+	mods.Get(&opts)
+	opts.SetShouldUseAggQC()
+}
@@ -0,0 +1,135 @@
+package keygen
+
+import (
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/crypto/keystore"
+)
+
+// ErrEncryptedKey is returned when an encrypted private key is parsed without a passphrase.
+var ErrEncryptedKey = errors.New("private key is encrypted")
+
+// The headers of an encrypted PEM block. The key is encrypted as in keystore.Encrypt,
+// and the parameters of the encryption are stored in the headers instead of in JSON.
+const (
+	procTypeHeader  = "Proc-Type"
+	dekInfoHeader   = "DEK-Info"
+	kdfHeader       = "KDF"
+	macHeader       = "MAC"
+	procTypeValue   = "4,ENCRYPTED"
+	cipherName      = "AES-128-CTR"
+	kdfParamsFormat = "scrypt,n=%d,r=%d,p=%d,dklen=%d,salt=%s"
+)
+
+func isEncrypted(b *pem.Block) bool {
+	return b.Headers[procTypeHeader] == procTypeValue
+}
+
+// EncryptPrivateKeyToPEM encodes the private key in PEM format, encrypted with the passphrase.
+// The scrypt parameters determine the cost of decrypting the key, see keystore.StandardScryptN.
+func EncryptPrivateKeyToPEM(key hotstuff.PrivateKey, passphrase []byte, scryptN, scryptP int) ([]byte, error) {
+	plain, err := PrivateKeyToPEM(key)
+	if err != nil {
+		return nil, err
+	}
+	b, _ := pem.Decode(plain)
+	c, err := keystore.Encrypt(b.Bytes, passphrase, scryptN, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	cipherText, err := hex.DecodeString(c.CipherText)
+	if err != nil {
+		return nil, err
+	}
+	p := c.KDFParams
+	return pem.EncodeToMemory(&pem.Block{
+		Type: b.Type,
+		Headers: map[string]string{
+			procTypeHeader: procTypeValue,
+			dekInfoHeader:  cipherName + "," + c.CipherParams.IV,
+			kdfHeader:      fmt.Sprintf(kdfParamsFormat, p.N, p.R, p.P, p.DKLen, p.Salt),
+			macHeader:      c.MAC,
+		},
+		Bytes: cipherText,
+	}), nil
+}
+
+// WriteEncryptedPrivateKeyFile writes a private key that is encrypted with the passphrase to the specified file.
+func WriteEncryptedPrivateKeyFile(key hotstuff.PrivateKey, filePath string, passphrase []byte, scryptN, scryptP int) error {
+	b, err := EncryptPrivateKeyToPEM(key, passphrase, scryptN, scryptP)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, b, 0o600)
+}
+
+// ParseEncryptedPrivateKey parses a PEM encoded private key, which is decrypted with the passphrase if it is encrypted.
+// It returns keystore.ErrDecrypt if the passphrase is wrong.
+func ParseEncryptedPrivateKey(buf, passphrase []byte) (hotstuff.PrivateKey, error) {
+	b, _ := pem.Decode(buf)
+	if b == nil {
+		return nil, fmt.Errorf("failed to decode PEM block")
+	}
+	if !isEncrypted(b) {
+		return parsePrivateKeyBlock(b.Type, b.Bytes)
+	}
+	c, err := encryptionHeaders(b)
+	if err != nil {
+		return nil, err
+	}
+	c.CipherText = hex.EncodeToString(b.Bytes)
+	der, err := keystore.Decrypt(c, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return parsePrivateKeyBlock(b.Type, der)
+}
+
+// encryptionHeaders parses the parameters of the encryption from the headers of the PEM block.
+func encryptionHeaders(b *pem.Block) (*keystore.CryptoJSON, error) {
+	cipher, iv, ok := strings.Cut(b.Headers[dekInfoHeader], ",")
+	if !ok || cipher != cipherName {
+		return nil, fmt.Errorf("unsupported encryption %q", b.Headers[dekInfoHeader])
+	}
+	kdf, params, _ := strings.Cut(b.Headers[kdfHeader], ",")
+	if kdf != "scrypt" {
+		return nil, fmt.Errorf("unsupported key derivation function %q", kdf)
+	}
+	c := &keystore.CryptoJSON{
+		Cipher:       strings.ToLower(cipher),
+		CipherParams: keystore.CipherParams{IV: iv},
+		KDF:          kdf,
+		MAC:          b.Headers[macHeader],
+	}
+	for _, param := range strings.Split(params, ",") {
+		name, value, _ := strings.Cut(param, "=")
+		if name == "salt" {
+			c.KDFParams.Salt = value
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scrypt parameter %q", param)
+		}
+		switch name {
+		case "n":
+			c.KDFParams.N = n
+		case "r":
+			c.KDFParams.R = n
+		case "p":
+			c.KDFParams.P = n
+		case "dklen":
+			c.KDFParams.DKLen = n
+		default:
+			return nil, fmt.Errorf("unknown scrypt parameter %q", name)
+		}
+	}
+	return c, nil
+}
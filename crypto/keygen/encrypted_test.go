@@ -0,0 +1,72 @@
+package keygen_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/crypto/bls12"
+	"github.com/relab/hotstuff/crypto/keygen"
+	"github.com/relab/hotstuff/crypto/keystore"
+)
+
+func TestEncryptedPrivateKeyFile(t *testing.T) {
+	ecdsaKey, err := keygen.GenerateECDSAPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, eddsaKey, err := keygen.GenerateED25519Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blsKey, err := bls12.GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	passFile := filepath.Join(dir, "passphrase")
+	if err := os.WriteFile(passFile, []byte("secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, key := range map[string]hotstuff.PrivateKey{"ecdsa": ecdsaKey, "eddsa": eddsaKey, "bls12": blsKey} {
+		t.Run(name, func(t *testing.T) {
+			keyFile := filepath.Join(dir, name+".key")
+			if err := keygen.WriteEncryptedPrivateKeyFile(key, keyFile, []byte("secret"), keystore.LightScryptN, keystore.LightScryptP); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := keygen.ReadPrivateKeyFile(keyFile, nil); !errors.Is(err, keygen.ErrEncryptedKey) {
+				t.Errorf("expected ErrEncryptedKey without a passphrase, got %v", err)
+			}
+			if _, err := keygen.ReadPrivateKeyFile(keyFile, func() ([]byte, error) { return []byte("wrong"), nil }); !errors.Is(err, keystore.ErrDecrypt) {
+				t.Errorf("expected ErrDecrypt with the wrong passphrase, got %v", err)
+			}
+			got, err := keygen.ReadPrivateKeyFile(keyFile, keystore.PassphraseFile(passFile))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, _ := keygen.PrivateKeyToPEM(key)
+			if gotPEM, _ := keygen.PrivateKeyToPEM(got); string(gotPEM) != string(want) {
+				t.Error("decrypted key does not match the encrypted key")
+			}
+		})
+	}
+}
+
+func TestReadUnencryptedPrivateKeyFile(t *testing.T) {
+	key, err := keygen.GenerateECDSAPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "priv.key")
+	if err := keygen.WritePrivateKeyFile(key, keyFile); err != nil {
+		t.Fatal(err)
+	}
+	// the passphrase is only asked for if the key is encrypted.
+	passphrase := func() ([]byte, error) { return nil, errors.New("asked for passphrase") }
+	if _, err := keygen.ReadPrivateKeyFile(keyFile, passphrase); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -10,9 +10,11 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"math/big"
 	"net"
+	"net/url"
 	"os"
 	"time"
 
@@ -20,6 +22,7 @@ import (
 	"github.com/relab/hotstuff/crypto/bls12"
 	ecdsacrypto "github.com/relab/hotstuff/crypto/ecdsa"
 	"github.com/relab/hotstuff/crypto/eddsa"
+	"github.com/relab/hotstuff/crypto/keystore"
 )
 
 // GenerateECDSAPrivateKey returns a new ECDSA private key.
@@ -41,6 +44,7 @@ func GenerateED25519Key() (pub ed25519.PublicKey, pk ed25519.PrivateKey, err err
 }
 
 // GenerateRootCert generates a self-signed TLS certificate to act as a CA.
+// The CA can also sign certificate revocation lists.
 func GenerateRootCert(privateKey *ecdsa.PrivateKey) (cert *x509.Certificate, err error) {
 	sn, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
 	if err != nil {
@@ -51,7 +55,7 @@ func GenerateRootCert(privateKey *ecdsa.PrivateKey) (cert *x509.Certificate, err
 		SerialNumber:          sn,
 		NotBefore:             time.Now(),
 		NotAfter:              time.Now().AddDate(10, 0, 0),
-		KeyUsage:              x509.KeyUsageCertSign,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
 		IsCA:                  true,
 		BasicConstraintsValid: true,
 	}
@@ -65,6 +69,7 @@ func GenerateRootCert(privateKey *ecdsa.PrivateKey) (cert *x509.Certificate, err
 }
 
 // GenerateTLSCert generates a TLS certificate for the server that is valid for the given hosts.
+// The ID of the replica is included both as the common name and as a URI in the subject alternative names.
 func GenerateTLSCert(id hotstuff.ID, hosts []string, parent *x509.Certificate, signeeKey *ecdsa.PublicKey, signerKey *ecdsa.PrivateKey) (cert *x509.Certificate, err error) {
 	sn, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
 	if err != nil {
@@ -80,6 +85,7 @@ func GenerateTLSCert(id hotstuff.ID, hosts []string, parent *x509.Certificate, s
 		NotAfter:              time.Now().AddDate(10, 0, 0),
 		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
 		BasicConstraintsValid: true,
+		URIs:                  []*url.URL{id.URI()},
 	}
 
 	for _, h := range hosts {
@@ -227,13 +233,24 @@ func WriteCertFile(cert *x509.Certificate, file string) (err error) {
 }
 
 // ParsePrivateKey parses a PEM encoded private key.
+// It returns ErrEncryptedKey if the key is encrypted; use ParseEncryptedPrivateKey for encrypted keys.
 func ParsePrivateKey(buf []byte) (key hotstuff.PrivateKey, err error) {
 	b, _ := pem.Decode(buf)
-	switch b.Type {
+	if b == nil {
+		return nil, fmt.Errorf("failed to decode PEM block")
+	}
+	if isEncrypted(b) {
+		return nil, ErrEncryptedKey
+	}
+	return parsePrivateKeyBlock(b.Type, b.Bytes)
+}
+
+func parsePrivateKeyBlock(keyType string, der []byte) (key hotstuff.PrivateKey, err error) {
+	switch keyType {
 	case ecdsacrypto.PrivateKeyFileType:
-		key, err = x509.ParseECPrivateKey(b.Bytes)
+		key, err = x509.ParseECPrivateKey(der)
 	case eddsa.PrivateKeyFileType:
-		genericKey, err := x509.ParsePKCS8PrivateKey(b.Bytes)
+		genericKey, err := x509.ParsePKCS8PrivateKey(der)
 		if err != nil {
 			return nil, err
 		}
@@ -244,10 +261,10 @@ func ParsePrivateKey(buf []byte) (key hotstuff.PrivateKey, err error) {
 		}
 	case bls12.PrivateKeyFileType:
 		k := &bls12.PrivateKey{}
-		k.FromBytes(b.Bytes)
+		k.FromBytes(der)
 		key = k
 	default:
-		return nil, fmt.Errorf("private key file type did not match any known types %v", b.Type)
+		return nil, fmt.Errorf("private key file type did not match any known types %v", keyType)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse key: %w", err)
@@ -256,12 +273,21 @@ func ParsePrivateKey(buf []byte) (key hotstuff.PrivateKey, err error) {
 }
 
 // ReadPrivateKeyFile reads a private key from the specified file.
-func ReadPrivateKeyFile(keyFile string) (key hotstuff.PrivateKey, err error) {
+// If the key is encrypted, it is decrypted with the passphrase, which may be nil if the key is not encrypted.
+func ReadPrivateKeyFile(keyFile string, passphrase keystore.Passphrase) (key hotstuff.PrivateKey, err error) {
 	b, err := os.ReadFile(keyFile)
 	if err != nil {
 		return nil, err
 	}
-	return ParsePrivateKey(b)
+	key, err = ParsePrivateKey(b)
+	if !errors.Is(err, ErrEncryptedKey) || passphrase == nil {
+		return key, err
+	}
+	pass, err := passphrase()
+	if err != nil {
+		return nil, err
+	}
+	return ParseEncryptedPrivateKey(b, pass)
 }
 
 // ParsePublicKey parses a PEM encoded public key
@@ -353,7 +379,8 @@ func GenerateKeyChain(id hotstuff.ID, validFor []string, crypto string, ca *x509
 	var privateKey hotstuff.PrivateKey
 	var publicKey hotstuff.PublicKey
 	switch crypto {
-	case "ecdsa":
+	case "ecdsa", "bls12-threshold":
+		// the keys for threshold signatures are created by distributed key generation, see GenerateThresholdKeys.
 		privateKey = ecdsaKey
 		publicKey = privateKey.Public()
 	case "bls12":
@@ -0,0 +1,225 @@
+// Package keystore encrypts private keys with a passphrase,
+// using the scrypt key derivation function and AES-128-CTR as in the Web3 Secret Storage Definition (version 3).
+// The encrypted keys of Ethereum accounts are compatible with the keystore files of Ethereum clients.
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/sha3"
+	"golang.org/x/term"
+)
+
+// The scrypt parameters. The standard parameters use 256 MB of memory and take about a second to compute.
+// The light parameters use 4 MB of memory, and are intended for tests and testnets.
+const (
+	StandardScryptN = 1 << 18
+	StandardScryptP = 1
+	LightScryptN    = 1 << 12
+	LightScryptP    = 6
+
+	scryptR     = 8
+	scryptDKLen = 32
+
+	// The scrypt parameters of an encrypted key are read from the key file,
+	// so they are bounded to keep a crafted file from exhausting the memory or the CPU.
+	// The bounds allow twice the work of the standard parameters.
+	maxScryptMemory = 128 * StandardScryptN * scryptR                 // bytes used by 128*N*r
+	maxScryptWork   = 2 * StandardScryptN * scryptR * StandardScryptP // N*r*p
+)
+
+// ErrDecrypt is returned when the passphrase does not decrypt the key.
+var ErrDecrypt = errors.New("keystore: could not decrypt key with given passphrase")
+
+// CryptoJSON is an encrypted secret in the format of the "crypto" object of a Web3 Secret Storage file.
+type CryptoJSON struct {
+	Cipher       string       `json:"cipher"`
+	CipherText   string       `json:"ciphertext"`
+	CipherParams CipherParams `json:"cipherparams"`
+	KDF          string       `json:"kdf"`
+	KDFParams    ScryptParams `json:"kdfparams"`
+	MAC          string       `json:"mac"`
+}
+
+// CipherParams are the parameters of the cipher.
+type CipherParams struct {
+	IV string `json:"iv"`
+}
+
+// ScryptParams are the parameters of the scrypt key derivation function.
+type ScryptParams struct {
+	DKLen int    `json:"dklen"`
+	N     int    `json:"n"`
+	P     int    `json:"p"`
+	R     int    `json:"r"`
+	Salt  string `json:"salt"`
+}
+
+// check returns an error if the parameters are invalid or exceed the bounds on memory and work.
+func (p ScryptParams) check() error {
+	if p.DKLen != scryptDKLen {
+		return fmt.Errorf("keystore: unsupported derived key length %d", p.DKLen)
+	}
+	if p.N <= 1 || p.N&(p.N-1) != 0 || p.R < 1 || p.P < 1 {
+		return fmt.Errorf("keystore: invalid scrypt parameters n=%d r=%d p=%d", p.N, p.R, p.P)
+	}
+	if p.N > maxScryptMemory/128/p.R || p.N > maxScryptWork/p.R/p.P {
+		return fmt.Errorf("keystore: scrypt parameters n=%d r=%d p=%d exceed the limits", p.N, p.R, p.P)
+	}
+	return nil
+}
+
+// Encrypt encrypts the secret with a key that is derived from the passphrase with the given scrypt parameters.
+func Encrypt(secret, passphrase []byte, scryptN, scryptP int) (*CryptoJSON, error) {
+	salt := make([]byte, 32)
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	derivedKey, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, scryptDKLen)
+	if err != nil {
+		return nil, err
+	}
+	cipherText, err := aesCTR(derivedKey[:16], iv, secret)
+	if err != nil {
+		return nil, err
+	}
+	return &CryptoJSON{
+		Cipher:       "aes-128-ctr",
+		CipherText:   hex.EncodeToString(cipherText),
+		CipherParams: CipherParams{IV: hex.EncodeToString(iv)},
+		KDF:          "scrypt",
+		KDFParams: ScryptParams{
+			DKLen: scryptDKLen,
+			N:     scryptN,
+			P:     scryptP,
+			R:     scryptR,
+			Salt:  hex.EncodeToString(salt),
+		},
+		MAC: hex.EncodeToString(mac(derivedKey, cipherText)),
+	}, nil
+}
+
+// Decrypt decrypts the secret with the passphrase.
+// It returns ErrDecrypt if the passphrase is wrong.
+func Decrypt(c *CryptoJSON, passphrase []byte) ([]byte, error) {
+	if c.Cipher != "aes-128-ctr" {
+		return nil, fmt.Errorf("keystore: unsupported cipher %q", c.Cipher)
+	}
+	if c.KDF != "scrypt" {
+		return nil, fmt.Errorf("keystore: unsupported key derivation function %q", c.KDF)
+	}
+	cipherText, err := hex.DecodeString(c.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: invalid ciphertext: %w", err)
+	}
+	iv, err := hex.DecodeString(c.CipherParams.IV)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: invalid iv: %w", err)
+	}
+	salt, err := hex.DecodeString(c.KDFParams.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: invalid salt: %w", err)
+	}
+	wantMAC, err := hex.DecodeString(c.MAC)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: invalid mac: %w", err)
+	}
+	p := c.KDFParams
+	if err := p.check(); err != nil {
+		return nil, err
+	}
+	derivedKey, err := scrypt.Key(passphrase, salt, p.N, p.R, p.P, p.DKLen)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: %w", err)
+	}
+	if subtle.ConstantTimeCompare(mac(derivedKey, cipherText), wantMAC) != 1 {
+		return nil, ErrDecrypt
+	}
+	return aesCTR(derivedKey[:16], iv, cipherText)
+}
+
+// mac returns the message authentication code of the ciphertext, which is the Keccak-256 hash
+// of the second half of the derived key and the ciphertext.
+func mac(derivedKey, cipherText []byte) []byte {
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(derivedKey[16:32])
+	hasher.Write(cipherText)
+	return hasher.Sum(nil)
+}
+
+func aesCTR(key, iv, in []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("keystore: invalid iv length %d", len(iv))
+	}
+	out := make([]byte, len(in))
+	cipher.NewCTR(block, iv).XORKeyStream(out, in)
+	return out, nil
+}
+
+// Passphrase returns the passphrase of an encrypted key.
+type Passphrase func() ([]byte, error)
+
+// PassphraseFile returns a Passphrase that reads the passphrase from the first line of a file.
+func PassphraseFile(path string) Passphrase {
+	return func() ([]byte, error) {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("keystore: failed to read passphrase: %w", err)
+		}
+		line, _, _ := bytes.Cut(b, []byte("\n"))
+		return bytes.TrimSuffix(line, []byte("\r")), nil
+	}
+}
+
+// Prompt returns a Passphrase that asks for the passphrase on the terminal.
+// The passphrase is not echoed. It fails if the standard input is not a terminal.
+func Prompt(prompt string) Passphrase {
+	return func() ([]byte, error) {
+		return readPassword(prompt)
+	}
+}
+
+// PromptNew returns a Passphrase that asks for a new passphrase on the terminal, and asks again to confirm it.
+func PromptNew(prompt string) Passphrase {
+	return func() ([]byte, error) {
+		passphrase, err := readPassword(prompt)
+		if err != nil {
+			return nil, err
+		}
+		confirm, err := readPassword("Repeat passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(passphrase, confirm) {
+			return nil, errors.New("keystore: passphrases do not match")
+		}
+		return passphrase, nil
+	}
+}
+
+func readPassword(prompt string) ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, errors.New("keystore: cannot prompt for passphrase: standard input is not a terminal")
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	defer fmt.Fprintln(os.Stderr)
+	return term.ReadPassword(fd)
+}
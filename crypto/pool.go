@@ -0,0 +1,264 @@
+package crypto
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+)
+
+func init() {
+	modules.RegisterModule("verification-pool", func() modules.VerificationPool {
+		return NewVerificationPool(0, DefaultMaxBatch)
+	})
+}
+
+const (
+	// DefaultMaxBatch is the default maximum number of signatures that are verified together.
+	DefaultMaxBatch = 64
+
+	// workerIdleTimeout is the time that a worker waits for a new task before it exits.
+	workerIdleTimeout = time.Second
+)
+
+type verifyRequest struct {
+	signature hotstuff.QuorumSignature
+	message   []byte
+	done      func(valid bool)
+}
+
+// VerificationPool verifies signatures on a bounded number of workers.
+//
+// Workers are started when there is work to do, and exit when they have been idle for a while,
+// such that an idle pool does not hold any goroutines.
+// Signatures that are submitted while the workers are busy are collected,
+// and verified together by the next free worker.
+// If the CryptoBase implements modules.BatchVerifier, such a batch is verified with a single batch verification,
+// and the signatures are verified one by one only if the batch verification fails.
+type VerificationPool struct {
+	crypto modules.Crypto
+	logger logging.Logger
+
+	workers  int
+	maxBatch int
+	tasks    chan func()
+
+	mut       sync.Mutex
+	running   int // the number of worker goroutines
+	scheduled int // the number of tasks that verify pending requests
+	pending   []verifyRequest
+}
+
+// NewVerificationPool returns a verification pool with the given number of workers,
+// which verifies at most maxBatch signatures together.
+// If workers is 0, the pool uses one worker per CPU.
+func NewVerificationPool(workers, maxBatch int) *VerificationPool {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if maxBatch <= 0 {
+		maxBatch = 1
+	}
+	return &VerificationPool{
+		workers:  workers,
+		maxBatch: maxBatch,
+		tasks:    make(chan func()),
+	}
+}
+
+// InitModule gives the module a reference to the Core object.
+func (p *VerificationPool) InitModule(mods *modules.Core) {
+	mods.Get(
+		&p.crypto,
+		&p.logger,
+	)
+}
+
+// trySubmit runs the task on an idle worker, or on a new worker if there are fewer than the maximum.
+// It returns false if all workers are busy.
+func (p *VerificationPool) trySubmit(task func()) bool {
+	select {
+	case p.tasks <- task:
+		return true
+	default:
+	}
+	p.mut.Lock()
+	if p.running >= p.workers {
+		p.mut.Unlock()
+		return false
+	}
+	p.running++
+	p.mut.Unlock()
+	go p.worker(task)
+	return true
+}
+
+func (p *VerificationPool) worker(task func()) {
+	timer := time.NewTimer(workerIdleTimeout)
+	defer timer.Stop()
+	for {
+		task()
+		timer.Reset(workerIdleTimeout)
+		select {
+		case task = <-p.tasks:
+		case <-timer.C:
+			p.mut.Lock()
+			p.running--
+			p.mut.Unlock()
+			return
+		}
+	}
+}
+
+// ForEach calls f for each index in [0, n), on the calling goroutine and on the idle workers,
+// and returns when all calls have returned. Since the calling goroutine also calls f,
+// ForEach does not wait for busy workers, and can be called from a worker.
+func (p *VerificationPool) ForEach(n int, f func(i int)) {
+	var (
+		next atomic.Int64
+		wg   sync.WaitGroup
+	)
+	work := func() {
+		for i := int(next.Add(1) - 1); i < n; i = int(next.Add(1) - 1) {
+			f(i)
+		}
+	}
+	for h := 1; h < n; h++ {
+		wg.Add(1)
+		if !p.trySubmit(func() { defer wg.Done(); work() }) {
+			wg.Done()
+			break
+		}
+	}
+	work()
+	wg.Wait()
+}
+
+// Submit queues the signature for verification, and calls done with the result on one of the workers.
+// If all workers are busy and none of them will verify the queued signatures, the signatures are verified on a new goroutine,
+// such that Submit never blocks the caller.
+func (p *VerificationPool) Submit(signature hotstuff.QuorumSignature, message []byte, done func(valid bool)) {
+	p.mut.Lock()
+	p.pending = append(p.pending, verifyRequest{signature, message, done})
+	first := p.scheduled == 0
+	// start another task if the pending requests are more than the scheduled tasks can take in one batch each.
+	schedule := first || (p.scheduled < p.workers && len(p.pending) > p.scheduled*p.maxBatch)
+	if schedule {
+		p.scheduled++
+	}
+	p.mut.Unlock()
+
+	if !schedule || p.trySubmit(p.verifyPending) {
+		return
+	}
+	if first {
+		go p.verifyPending()
+		return
+	}
+	p.mut.Lock()
+	p.scheduled--
+	p.mut.Unlock()
+}
+
+// verifyPending verifies batches of pending requests until there are none left.
+func (p *VerificationPool) verifyPending() {
+	for {
+		p.mut.Lock()
+		if len(p.pending) == 0 {
+			p.scheduled--
+			p.mut.Unlock()
+			return
+		}
+		// share the pending requests among the scheduled tasks.
+		n := min(p.maxBatch, (len(p.pending)+p.scheduled-1)/p.scheduled)
+		batch := p.pending[:n:n]
+		p.pending = p.pending[n:]
+		if len(p.pending) == 0 {
+			p.pending = nil
+		}
+		p.mut.Unlock()
+
+		p.verifyBatch(batch)
+	}
+}
+
+func (p *VerificationPool) verifyBatch(batch []verifyRequest) {
+	if bv, ok := batchVerifier(p.crypto); ok && len(batch) > 1 {
+		signatures := make([]hotstuff.QuorumSignature, len(batch))
+		messages := make([][]byte, len(batch))
+		for i, req := range batch {
+			signatures[i] = req.signature
+			messages[i] = req.message
+		}
+		if bv.VerifyBatch(signatures, messages) {
+			for _, req := range batch {
+				req.done(true)
+			}
+			return
+		}
+		p.logger.Debugf("Batch verification of %d signatures failed, verifying them one by one", len(batch))
+	}
+	for _, req := range batch {
+		req.done(p.crypto.Verify(req.signature, req.message))
+	}
+}
+
+// batchVerifier returns the BatchVerifier of the CryptoBase, looking through the Crypto and cache wrappers.
+func batchVerifier(impl modules.CryptoBase) (modules.BatchVerifier, bool) {
+	switch c := impl.(type) {
+	case *crypto:
+		return batchVerifier(c.CryptoBase)
+	case *cache:
+		if _, ok := c.impl.(modules.BatchVerifier); !ok {
+			return nil, false
+		}
+		return c, true
+	}
+	bv, ok := impl.(modules.BatchVerifier)
+	return bv, ok
+}
+
+// ForEach calls f for each index in [0, n) on the verification pool, or on the calling goroutine if pool is nil.
+func ForEach(pool modules.VerificationPool, n int, f func(i int)) {
+	if pool == nil {
+		for i := 0; i < n; i++ {
+			f(i)
+		}
+		return
+	}
+	pool.ForEach(n, f)
+}
+
+// VerifyAll verifies the signatures against the messages at the same index on the verification pool,
+// or on the calling goroutine with the verifier if pool is nil.
+// It calls done once, with true if all of the signatures are valid.
+func VerifyAll(pool modules.VerificationPool, verifier modules.CryptoBase, signatures []hotstuff.QuorumSignature, messages [][]byte, done func(valid bool)) {
+	if pool == nil {
+		for i, signature := range signatures {
+			if !verifier.Verify(signature, messages[i]) {
+				done(false)
+				return
+			}
+		}
+		done(true)
+		return
+	}
+	var remaining, invalid atomic.Int32
+	remaining.Store(int32(len(signatures)))
+	for i, signature := range signatures {
+		pool.Submit(signature, messages[i], func(valid bool) {
+			if !valid {
+				invalid.Add(1)
+			}
+			if remaining.Add(-1) == 0 {
+				done(invalid.Load() == 0)
+			}
+		})
+	}
+}
+
+var _ modules.VerificationPool = (*VerificationPool)(nil)
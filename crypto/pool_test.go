@@ -0,0 +1,184 @@
+package crypto_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"filippo.io/edwards25519"
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/crypto"
+	"github.com/relab/hotstuff/crypto/bls12"
+	"github.com/relab/hotstuff/crypto/ecdsa"
+	"github.com/relab/hotstuff/crypto/eddsa"
+	"github.com/relab/hotstuff/internal/testutil"
+	"github.com/relab/hotstuff/modules"
+	"go.uber.org/mock/gomock"
+)
+
+// signMessages returns a signature of a distinct message by each signer.
+func signMessages(t *testing.T, signers []modules.Crypto) ([]hotstuff.QuorumSignature, [][]byte) {
+	t.Helper()
+	signatures := make([]hotstuff.QuorumSignature, len(signers))
+	messages := make([][]byte, len(signers))
+	for i, signer := range signers {
+		messages[i] = fmt.Appendf(nil, "message %d", i)
+		sig, err := signer.Sign(messages[i])
+		if err != nil {
+			t.Fatalf("Failed to sign message: %v", err)
+		}
+		signatures[i] = sig
+	}
+	return signatures, messages
+}
+
+func TestVerifyBatch(t *testing.T) {
+	run := func(t *testing.T, newBase func() modules.CryptoBase, keyFunc keyFunc) {
+		const n = 4
+		ctrl := gomock.NewController(t)
+		bl := testutil.CreateBuilders(t, ctrl, n, testutil.GenerateKeys(t, n, keyFunc)...)
+		bases := make([]modules.CryptoBase, n)
+		for i, builder := range bl {
+			bases[i] = newBase()
+			builder.Add(crypto.New(bases[i]))
+		}
+		hl := bl.Build()
+
+		signatures, messages := signMessages(t, hl.Signers())
+		verifier := bases[0].(modules.BatchVerifier)
+
+		if !verifier.VerifyBatch(signatures, messages) {
+			t.Error("Batch of valid signatures was not verified.")
+		}
+		messages[2] = []byte("forged message")
+		if verifier.VerifyBatch(signatures, messages) {
+			t.Error("Batch with an invalid signature was verified.")
+		}
+		// the signatures are valid, but for the messages of other signers.
+		signatures[0], signatures[1] = signatures[1], signatures[0]
+		messages[2] = []byte("message 2")
+		if verifier.VerifyBatch(signatures, messages) {
+			t.Error("Batch with swapped signatures was verified.")
+		}
+	}
+	t.Run("Eddsa", func(t *testing.T) { run(t, eddsa.New, testutil.GenerateEDDSAKey) })
+	t.Run("BLS12-381", func(t *testing.T) { run(t, bls12.New, testutil.GenerateBLS12Key) })
+}
+
+// TestEddsaSmallOrder checks that a signature whose R has a small-order component is rejected by both
+// single and batch verification. The signature satisfies the cofactored verification equation, but not
+// the cofactorless one used by ed25519.Verify.
+func TestEddsaSmallOrder(t *testing.T) {
+	const n = 4
+	ctrl := gomock.NewController(t)
+	keys := testutil.GenerateKeys(t, n, testutil.GenerateEDDSAKey)
+	bl := testutil.CreateBuilders(t, ctrl, n, keys...)
+	bases := make([]modules.CryptoBase, n)
+	for i, builder := range bl {
+		bases[i] = eddsa.New()
+		builder.Add(crypto.New(bases[i]))
+	}
+	bl.Build()
+
+	// the private scalar of replica 2.
+	key := keys[1].(ed25519.PrivateKey)
+	digest := sha512.Sum512(key.Seed())
+	a, err := edwards25519.NewScalar().SetBytesWithClamping(digest[:32])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the point of order 2, (0, -1).
+	torsion, err := new(edwards25519.Point).SetBytes(append([]byte{0xec}, append(bytes.Repeat([]byte{0xff}, 30), 0x7f)...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nonce [64]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		t.Fatal(err)
+	}
+	r, _ := edwards25519.NewScalar().SetUniformBytes(nonce[:])
+	// R = rB + T, and S = r + H(R || A || M)a, such that [8](SB - R - kA) is the identity, but SB - kA != R.
+	bigR := new(edwards25519.Point).ScalarBaseMult(r)
+	bigR.Add(bigR, torsion)
+	message := []byte("message")
+	h := sha512.New()
+	_, _ = h.Write(bigR.Bytes())
+	_, _ = h.Write(key.Public().(ed25519.PublicKey))
+	_, _ = h.Write(message)
+	k, _ := edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+	sc := edwards25519.NewScalar().MultiplyAdd(k, a, r)
+	forged := crypto.Multi[*eddsa.Signature]{2: eddsa.RestoreSignature(append(bigR.Bytes(), sc.Bytes()...), 2)}
+
+	if bases[0].Verify(forged, message) {
+		t.Error("Signature with a small-order R was verified.")
+	}
+	if bases[0].(modules.BatchVerifier).VerifyBatch([]hotstuff.QuorumSignature{forged}, [][]byte{message}) {
+		t.Error("Signature with a small-order R was batch verified.")
+	}
+}
+
+func TestVerificationPool(t *testing.T) {
+	run := func(t *testing.T, newFunc func() modules.Crypto, keyFunc keyFunc) {
+		const n = 4
+		ctrl := gomock.NewController(t)
+		bl := testutil.CreateBuilders(t, ctrl, n, testutil.GenerateKeys(t, n, keyFunc)...)
+		pool := crypto.NewVerificationPool(2, 2)
+		for i, builder := range bl {
+			builder.Add(newFunc())
+			if i == 0 {
+				builder.Add(pool)
+			}
+		}
+		hl := bl.Build()
+
+		var (
+			wg      sync.WaitGroup
+			results = make([]atomic.Bool, n)
+		)
+		// submit several rounds of signatures such that some of them are verified in batches.
+		for range 10 {
+			signatures, messages := signMessages(t, hl.Signers())
+			messages[1] = []byte("forged message")
+			for i := range n {
+				wg.Add(1)
+				pool.Submit(signatures[i], messages[i], func(valid bool) {
+					results[i].Store(valid)
+					wg.Done()
+				})
+			}
+		}
+		wg.Wait()
+
+		for i := range results {
+			if want := i != 1; results[i].Load() != want {
+				t.Errorf("signature %d: got %t, want %t", i, results[i].Load(), want)
+			}
+		}
+	}
+	t.Run("Ecdsa", func(t *testing.T) { run(t, NewBase(ecdsa.New), testutil.GenerateECDSAKey) })
+	t.Run("Eddsa", func(t *testing.T) { run(t, NewBase(eddsa.New), testutil.GenerateEDDSAKey) })
+	t.Run("Cache+Eddsa", func(t *testing.T) { run(t, NewCache(eddsa.New), testutil.GenerateEDDSAKey) })
+	t.Run("BLS12-381", func(t *testing.T) { run(t, NewBase(bls12.New), testutil.GenerateBLS12Key) })
+	t.Run("Cache+BLS12-381", func(t *testing.T) { run(t, NewCache(bls12.New), testutil.GenerateBLS12Key) })
+}
+
+func TestVerificationPoolForEach(t *testing.T) {
+	pool := crypto.NewVerificationPool(4, 1)
+	const n = 100
+	var calls [n]atomic.Int32
+	pool.ForEach(n, func(i int) {
+		// nested calls must not wait for busy workers.
+		pool.ForEach(2, func(int) {})
+		calls[i].Add(1)
+	})
+	for i := range calls {
+		if c := calls[i].Load(); c != 1 {
+			t.Errorf("index %d: got %d calls, want 1", i, c)
+		}
+	}
+}
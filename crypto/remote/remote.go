@@ -0,0 +1,133 @@
+// Package remote provides a CryptoBase implementation that signs messages with a remote signer,
+// such that the replica's private key is not kept in the replica's process.
+//
+// The signer is a gRPC service that is implemented by Signer.
+// It keeps a high-watermark of the views of the blocks and timeouts that it has signed,
+// and refuses to sign a different block in the same view, or a block or timeout in an older view.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/crypto/keygen"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	"github.com/relab/hotstuff/internal/proto/signerpb"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// signTimeout is the time to wait for the signer to sign a message.
+const signTimeout = 5 * time.Second
+
+var signingKinds = map[modules.SigningKind]signerpb.SigningKind{
+	modules.SignOther:   signerpb.SigningKind_Other,
+	modules.SignBlock:   signerpb.SigningKind_Block,
+	modules.SignTimeout: signerpb.SigningKind_Timeout,
+}
+
+type remoteBase struct {
+	client signerpb.SignerClient
+	info   *signerpb.SignerInfo
+	impl   modules.CryptoBase
+
+	logger logging.Logger
+	opts   *modules.Options
+}
+
+// New returns a CryptoBase that signs messages with the signer that the connection leads to.
+// The signer must sign for the replica with the given ID and public key.
+// Signatures are verified and combined by impl, which must be the CryptoBase of the signer's key type.
+// The replica's modules must be created without a private key.
+func New(conn grpc.ClientConnInterface, id hotstuff.ID, publicKey hotstuff.PublicKey, impl modules.CryptoBase) (modules.CryptoBase, error) {
+	client := signerpb.NewSignerClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), signTimeout)
+	defer cancel()
+	info, err := client.Info(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, fmt.Errorf("remote: failed to contact signer: %w", err)
+	}
+	if hotstuff.ID(info.GetID()) != id {
+		return nil, fmt.Errorf("remote: signer signs for replica %d, not replica %d", info.GetID(), id)
+	}
+	want, err := keygen.PublicKeyToPEM(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(info.GetPublicKey(), want) {
+		return nil, fmt.Errorf("remote: signer does not hold the private key of replica %d", id)
+	}
+	return &remoteBase{client: client, info: info, impl: impl}, nil
+}
+
+// InitModule gives the module a reference to the Core object.
+// It also sends the connection metadata of the signer's key, such as a proof of possession, to the other replicas.
+func (r *remoteBase) InitModule(mods *modules.Core) {
+	mods.Get(
+		&r.logger,
+		&r.opts,
+	)
+	if mod, ok := r.impl.(modules.Module); ok {
+		mod.InitModule(mods)
+	}
+	for key, value := range r.info.GetMetadata() {
+		r.opts.SetConnectionMetadata(key, string(value))
+	}
+}
+
+// Sign creates a cryptographic signature of the given message.
+func (r *remoteBase) Sign(message []byte) (signature hotstuff.QuorumSignature, err error) {
+	return r.SignWithContext(modules.SigningContext{Kind: modules.SignOther}, message)
+}
+
+// SignWithContext asks the signer to sign the message, which is described by the context.
+// The signer returns an error if the message conflicts with a message that it has signed before.
+func (r *remoteBase) SignWithContext(ctx modules.SigningContext, message []byte) (signature hotstuff.QuorumSignature, err error) {
+	rctx, cancel := context.WithTimeout(context.Background(), signTimeout)
+	defer cancel()
+	sig, err := r.client.Sign(rctx, &signerpb.SignRequest{
+		Message: message,
+		Kind:    signingKinds[ctx.Kind],
+		View:    uint64(ctx.View),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("remote: sign failed: %w", err)
+	}
+	signature = hotstuffpb.QuorumSignatureFromProto(sig)
+	if signature == nil {
+		return nil, fmt.Errorf("remote: signer returned an invalid signature")
+	}
+	return signature, nil
+}
+
+// Combine combines multiple signatures into a single signature.
+func (r *remoteBase) Combine(signatures ...hotstuff.QuorumSignature) (hotstuff.QuorumSignature, error) {
+	return r.impl.Combine(signatures...)
+}
+
+// Verify verifies the given quorum signature against the message.
+func (r *remoteBase) Verify(signature hotstuff.QuorumSignature, message []byte) bool {
+	return r.impl.Verify(signature, message)
+}
+
+// VerifyWithKeys verifies the given quorum signature against the message using the given public keys.
+// It returns false if impl can only verify signatures with the keys of the current configuration.
+func (r *remoteBase) VerifyWithKeys(signature hotstuff.QuorumSignature, message []byte, keys map[hotstuff.ID]hotstuff.PublicKey) bool {
+	kv, ok := r.impl.(modules.KeyedVerifier)
+	return ok && kv.VerifyWithKeys(signature, message, keys)
+}
+
+// BatchVerify verifies the given quorum signature against the batch of messages.
+func (r *remoteBase) BatchVerify(signature hotstuff.QuorumSignature, batch map[hotstuff.ID][]byte) bool {
+	return r.impl.BatchVerify(signature, batch)
+}
+
+var (
+	_ modules.ContextSigner = (*remoteBase)(nil)
+	_ modules.KeyedVerifier = (*remoteBase)(nil)
+)
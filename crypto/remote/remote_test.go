@@ -0,0 +1,248 @@
+package remote_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/crypto/bls12"
+	"github.com/relab/hotstuff/crypto/ecdsa"
+	"github.com/relab/hotstuff/crypto/eddsa"
+	"github.com/relab/hotstuff/crypto/keygen"
+	"github.com/relab/hotstuff/crypto/remote"
+	"github.com/relab/hotstuff/internal/mocks"
+	"github.com/relab/hotstuff/internal/proto/signerpb"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type keyType struct {
+	name     string
+	generate func(t *testing.T) hotstuff.PrivateKey
+	new      func() modules.CryptoBase
+}
+
+var keyTypes = []keyType{
+	{"ecdsa", func(t *testing.T) hotstuff.PrivateKey {
+		key, err := keygen.GenerateECDSAPrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}, ecdsa.New},
+	{"eddsa", func(t *testing.T) hotstuff.PrivateKey {
+		_, key, err := keygen.GenerateED25519Key()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}, eddsa.New},
+	{"bls12", func(t *testing.T) hotstuff.PrivateKey {
+		key, err := bls12.GeneratePrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}, bls12.New},
+}
+
+// serve starts the signer on an in-memory listener, and returns a connection to it.
+func serve(t *testing.T, signer *remote.Signer) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	signerpb.RegisterSignerServer(srv, signer)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///signer",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// replicas returns a configuration with a replica for each of the public keys.
+func replicas(t *testing.T, keys map[hotstuff.ID]hotstuff.PublicKey, metadata map[hotstuff.ID]map[string]string) modules.Configuration {
+	ctrl := gomock.NewController(t)
+	cfg := mocks.NewMockConfiguration(ctrl)
+	for id, key := range keys {
+		replica := mocks.NewMockReplica(ctrl)
+		replica.EXPECT().ID().Return(id).AnyTimes()
+		replica.EXPECT().PublicKey().Return(key).AnyTimes()
+		replica.EXPECT().Metadata().Return(metadata[id]).AnyTimes()
+		cfg.EXPECT().Replica(id).Return(replica, true).AnyTimes()
+	}
+	return cfg
+}
+
+func TestRemoteSign(t *testing.T) {
+	for _, kt := range keyTypes {
+		t.Run(kt.name, func(t *testing.T) {
+			key1, key2 := kt.generate(t), kt.generate(t)
+			signer, err := remote.NewSigner(1, key1, filepath.Join(t.TempDir(), "state.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			base, err := remote.New(serve(t, signer), 1, key1.Public(), kt.new())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// replica 1 signs remotely, so it is built without a private key.
+			keys := map[hotstuff.ID]hotstuff.PublicKey{1: key1.Public(), 2: key2.Public()}
+			builder1 := modules.NewBuilder(1, nil)
+			builder1.Add(logging.New("hs1"), base)
+			builder2 := modules.NewBuilder(2, key2)
+			verifier := kt.new()
+			builder2.Add(logging.New("hs2"), verifier)
+			metadata := map[hotstuff.ID]map[string]string{
+				1: builder1.Options().ConnectionMetadata(),
+				2: builder2.Options().ConnectionMetadata(),
+			}
+			builder1.Add(replicas(t, keys, metadata))
+			builder2.Add(replicas(t, keys, metadata))
+			builder1.Build()
+			builder2.Build()
+			metadata[1], metadata[2] = builder1.Options().ConnectionMetadata(), builder2.Options().ConnectionMetadata()
+
+			msg := newBlock(1, "hello")
+			sig, err := modules.SignWithContext(base, modules.SigningContext{Kind: modules.SignBlock, View: 1}, msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !verifier.Verify(sig, msg) {
+				t.Error("replica 2 failed to verify the signature of replica 1")
+			}
+			if !base.Verify(sig, msg) {
+				t.Error("replica 1 failed to verify its own signature")
+			}
+			if base.Verify(sig, []byte("goodbye")) {
+				t.Error("verified the signature against the wrong message")
+			}
+		})
+	}
+}
+
+// newBlock returns the message that is signed for a block with the command in the view.
+func newBlock(view hotstuff.View, cmd string) []byte {
+	genesis := hotstuff.GetGenesis()
+	return hotstuff.NewBlock(genesis.Hash(), hotstuff.NewQuorumCert(nil, 0, genesis.Hash()), hotstuff.Command(cmd), view, 1).ToBytes()
+}
+
+func sign(t *testing.T, base modules.CryptoBase, kind modules.SigningKind, view hotstuff.View, msg []byte) error {
+	t.Helper()
+	_, err := modules.SignWithContext(base, modules.SigningContext{Kind: kind, View: view}, msg)
+	if err != nil && status.Code(errors.Unwrap(err)) != codes.FailedPrecondition {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return err
+}
+
+func TestRemoteDoubleSign(t *testing.T) {
+	key, err := keygen.GenerateECDSAPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	connect := func() modules.CryptoBase {
+		signer, err := remote.NewSigner(1, key, statePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		base, err := remote.New(serve(t, signer), 1, key.Public(), ecdsa.New())
+		if err != nil {
+			t.Fatal(err)
+		}
+		builder := modules.NewBuilder(1, nil)
+		builder.Add(logging.New("hs1"), replicas(t, nil, nil), base)
+		builder.Build()
+		return base
+	}
+	base := connect()
+
+	blockA, blockB, blockC := newBlock(5, "a"), newBlock(5, "b"), newBlock(4, "c")
+	if err := sign(t, base, modules.SignBlock, 5, blockA); err != nil {
+		t.Fatal(err)
+	}
+	if err := sign(t, base, modules.SignBlock, 5, blockA); err != nil {
+		t.Errorf("refused to sign the same block again: %v", err)
+	}
+	if err := sign(t, base, modules.SignBlock, 5, blockB); err == nil {
+		t.Error("signed a different block in the same view")
+	}
+	if err := sign(t, base, modules.SignBlock, 4, blockC); err == nil {
+		t.Error("signed a block in an older view")
+	}
+	if err := sign(t, base, modules.SignBlock, 6, blockB); err == nil {
+		t.Error("signed a different block in the same view, which the replica described as a newer view")
+	}
+	if err := sign(t, base, modules.SignTimeout, 5, hotstuff.View(5).ToBytes()); err != nil {
+		t.Fatal(err)
+	}
+	if err := sign(t, base, modules.SignTimeout, 5, hotstuff.TimeoutBytes(1, 5, 4)); err != nil {
+		t.Errorf("refused to sign the high QC view of a timeout: %v", err)
+	}
+	if err := sign(t, base, modules.SignTimeout, 3, hotstuff.View(3).ToBytes()); err == nil {
+		t.Error("signed a timeout in an older view")
+	}
+	if err := sign(t, base, modules.SignTimeout, 6, hotstuff.TimeoutBytes(2, 6, 4)); err == nil {
+		t.Error("signed a timeout of another replica")
+	}
+	if err := sign(t, base, modules.SignOther, 1, hotstuff.AvailabilityBytes(hotstuff.Hash{1}, 2, 3)); err != nil {
+		t.Errorf("refused to sign a message that is not a block or timeout: %v", err)
+	}
+	if err := sign(t, base, modules.SignOther, 5, blockB); err == nil {
+		t.Error("signed a block that was described as another kind of message")
+	}
+	if err := sign(t, base, modules.SignOther, 3, hotstuff.View(3).ToBytes()); err == nil {
+		t.Error("signed a timeout that was described as another kind of message")
+	}
+
+	// a restarted signer remembers what it has signed.
+	base = connect()
+	if err := sign(t, base, modules.SignBlock, 5, blockB); err == nil {
+		t.Error("signed a different block in the same view after restart")
+	}
+	if err := sign(t, base, modules.SignTimeout, 4, hotstuff.View(4).ToBytes()); err == nil {
+		t.Error("signed a timeout in an older view after restart")
+	}
+	if err := sign(t, base, modules.SignBlock, 6, newBlock(6, "d")); err != nil {
+		t.Errorf("refused to sign a block in a newer view after restart: %v", err)
+	}
+}
+
+func TestRemoteWrongIdentity(t *testing.T) {
+	key, err := keygen.GenerateECDSAPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := keygen.GenerateECDSAPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := remote.NewSigner(1, key, filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn := serve(t, signer)
+	if _, err := remote.New(conn, 2, key.Public(), ecdsa.New()); err == nil {
+		t.Error("accepted a signer for another replica")
+	}
+	if _, err := remote.New(conn, 1, other.Public(), ecdsa.New()); err == nil {
+		t.Error("accepted a signer with another key")
+	}
+}
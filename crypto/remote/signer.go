@@ -0,0 +1,251 @@
+package remote
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/crypto/bls12"
+	hsecdsa "github.com/relab/hotstuff/crypto/ecdsa"
+	"github.com/relab/hotstuff/crypto/eddsa"
+	"github.com/relab/hotstuff/crypto/keygen"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	"github.com/relab/hotstuff/internal/proto/signerpb"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// ErrConflict is returned when the signer refuses to sign a message that conflicts with a message it has signed.
+var ErrConflict = errors.New("remote: refusing to sign conflicting message")
+
+// watermark is the latest view that the signer has signed a message of some kind in.
+// For blocks, it also contains the hash of the signed block.
+type watermark struct {
+	View hotstuff.View `json:"view"`
+	Hash hotstuff.Hash `json:"hash"`
+}
+
+// signerState is the state of the signer, which is persisted before each signature is returned.
+type signerState struct {
+	Block   watermark `json:"block"`
+	Timeout watermark `json:"timeout"`
+}
+
+// Signer is a gRPC service that signs messages with the private key of a replica.
+//
+// The signer keeps the high-watermark of the views of the blocks and timeouts that it has signed in a state file.
+// It refuses to sign a block in an older view, or a different block in the same view,
+// and refuses to sign a timeout in an older view. This protects against double signing,
+// for example when two instances of a replica run with the same key, or when a replica loses its state.
+// The signer reads the view from the messages themselves, and refuses to sign messages of other kinds
+// that have the size of a block or timeout message, so a replica cannot misdescribe what it asks to sign.
+type Signer struct {
+	signerpb.UnimplementedSignerServer
+
+	impl      modules.CryptoBase
+	info      *signerpb.SignerInfo
+	statePath string
+
+	mut   sync.Mutex
+	state signerState
+}
+
+// signerConfiguration is the configuration of the signer's CryptoBase.
+// The signer only creates signatures, which does not require a configuration.
+type signerConfiguration struct {
+	modules.Configuration
+}
+
+// NewSigner returns a signer for the replica with the given ID and private key.
+// The key must be an ecdsa, eddsa or bls12 key. The state is read from and written to the state file.
+func NewSigner(id hotstuff.ID, key hotstuff.PrivateKey, statePath string) (*Signer, error) {
+	var impl modules.CryptoBase
+	switch key.(type) {
+	case *ecdsa.PrivateKey:
+		impl = hsecdsa.New()
+	case ed25519.PrivateKey:
+		impl = eddsa.New()
+	case *bls12.PrivateKey:
+		impl = bls12.New()
+	default:
+		return nil, fmt.Errorf("remote: unsupported key type %T", key)
+	}
+	publicKey, err := keygen.PublicKeyToPEM(key.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	builder := modules.NewBuilder(id, key)
+	builder.Add(
+		logging.New("signer"+strconv.Itoa(int(id))),
+		signerConfiguration{},
+		impl,
+	)
+	builder.Build()
+
+	// the metadata may contain binary values, such as the proof of possession of a bls12 key.
+	metadata := make(map[string][]byte)
+	for key, value := range builder.Options().ConnectionMetadata() {
+		metadata[key] = []byte(value)
+	}
+	s := &Signer{
+		impl: impl,
+		info: &signerpb.SignerInfo{
+			ID:        uint32(id),
+			PublicKey: publicKey,
+			Metadata:  metadata,
+		},
+		statePath: statePath,
+	}
+	b, err := os.ReadFile(statePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("remote: failed to read signer state: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(b, &s.state); err != nil {
+			return nil, fmt.Errorf("remote: invalid signer state: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// Info returns the identity of the replica that the signer signs for.
+func (s *Signer) Info(_ context.Context, _ *emptypb.Empty) (*signerpb.SignerInfo, error) {
+	return s.info, nil
+}
+
+// Sign signs the message, unless it conflicts with a message that the signer has signed before.
+// The new watermark is persisted before the signature is returned.
+func (s *Signer) Sign(_ context.Context, req *signerpb.SignRequest) (*hotstuffpb.QuorumSignature, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	next, err := s.check(req)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if next != s.state {
+		if err := s.persist(next); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to persist signer state: %v", err)
+		}
+		s.state = next
+	}
+	sig, err := s.impl.Sign(req.GetMessage())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return hotstuffpb.QuorumSignatureToProto(sig), nil
+}
+
+// Sizes of the messages that replicas sign for blocks and timeouts, as encoded by hotstuff.Block.ToBytes,
+// hotstuff.View.ToBytes, hotstuff.TimeoutBytes and hotstuff.TimeoutMsg.ToBytes.
+const (
+	viewSize = 8
+	// the ID and view of a timeout message without a QC.
+	timeoutSize = 12
+	// the ID, view and high QC view of a timeout.
+	highQCTimeoutSize = 20
+	// the ID and view of a timeout message, and a QC without a signature.
+	minTimeoutQCSize = timeoutSize + 8 + len(hotstuff.Hash{})
+	// the parent, proposer, view, an empty command, a QC without a signature, and the timestamp of a block.
+	minBlockSize = len(hotstuff.Hash{}) + 4 + 8 + 8 + len(hotstuff.Hash{}) + 8
+)
+
+// blockView returns the view of the block that the message encodes.
+func blockView(message []byte) (hotstuff.View, bool) {
+	if len(message) < minBlockSize {
+		return 0, false
+	}
+	offset := len(hotstuff.Hash{}) + 4
+	return hotstuff.View(binary.LittleEndian.Uint64(message[offset:])), true
+}
+
+// timeoutView returns the view of the timeout that the message encodes, and the ID of the replica that timed out,
+// which is zero if the message is just the view.
+func timeoutView(message []byte) (hotstuff.View, hotstuff.ID, bool) {
+	switch {
+	case len(message) == viewSize:
+		return hotstuff.View(binary.LittleEndian.Uint64(message)), 0, true
+	case len(message) == timeoutSize || len(message) == highQCTimeoutSize || len(message) >= minTimeoutQCSize:
+		return hotstuff.View(binary.LittleEndian.Uint64(message[4:])), hotstuff.ID(binary.LittleEndian.Uint32(message)), true
+	}
+	return 0, 0, false
+}
+
+// check returns the state after signing the requested message, or ErrConflict if the message must not be signed.
+// The view is read from the message, not from the request, such that the replica cannot misdescribe the message.
+// Messages of other kinds are refused if they have the size of a block or timeout message.
+func (s *Signer) check(req *signerpb.SignRequest) (signerState, error) {
+	next := s.state
+	message := req.GetMessage()
+	switch req.GetKind() {
+	case signerpb.SigningKind_Block:
+		view, ok := blockView(message)
+		if !ok {
+			return next, fmt.Errorf("%w: message is not a block", ErrConflict)
+		}
+		hash := sha256.Sum256(message)
+		if view < s.state.Block.View || (view == s.state.Block.View && hash != s.state.Block.Hash) {
+			return next, fmt.Errorf("%w: block in view %d, after signing a block in view %d", ErrConflict, view, s.state.Block.View)
+		}
+		next.Block = watermark{View: view, Hash: hash}
+	case signerpb.SigningKind_Timeout:
+		view, id, ok := timeoutView(message)
+		if !ok || (id != 0 && id != hotstuff.ID(s.info.GetID())) {
+			return next, fmt.Errorf("%w: message is not a timeout of replica %d", ErrConflict, s.info.GetID())
+		}
+		if view < s.state.Timeout.View {
+			return next, fmt.Errorf("%w: timeout in view %d, after signing a timeout in view %d", ErrConflict, view, s.state.Timeout.View)
+		}
+		next.Timeout = watermark{View: view}
+	case signerpb.SigningKind_Other:
+		if _, ok := blockView(message); ok {
+			return next, fmt.Errorf("%w: message may be a block", ErrConflict)
+		}
+		if _, _, ok := timeoutView(message); ok {
+			return next, fmt.Errorf("%w: message may be a timeout", ErrConflict)
+		}
+	default:
+		return next, fmt.Errorf("%w: unknown kind %v", ErrConflict, req.GetKind())
+	}
+	return next, nil
+}
+
+// persist writes the state to a temporary file, and then replaces the state file,
+// such that the state file is never partially written.
+func (s *Signer) persist(state signerState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(s.statePath), filepath.Base(s.statePath)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), s.statePath)
+}
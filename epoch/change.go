@@ -0,0 +1,106 @@
+package epoch
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/crypto/keygen"
+)
+
+// transactionPrefix identifies the data of a client command as a reconfiguration transaction.
+var transactionPrefix = []byte("hotstuff-reconfigure:")
+
+// ValidatorSpec describes a validator that is added by a reconfiguration.
+type ValidatorSpec struct {
+	ID        hotstuff.ID `json:"id"`
+	Address   string      `json:"address"`
+	PublicKey []byte      `json:"publicKey"`       // PEM encoded public key.
+	Power     uint64      `json:"power,omitempty"` // voting power; zero means one vote.
+}
+
+// Change is a change of the validator set.
+// Adding a validator that is already in the validator set replaces its address and public key.
+type Change struct {
+	// Nonce is the number of changes that have been committed before this change.
+	// It prevents a signed change from being replayed.
+	Nonce  uint64          `json:"nonce"`
+	Add    []ValidatorSpec `json:"add,omitempty"`
+	Remove []hotstuff.ID   `json:"remove,omitempty"`
+}
+
+type transaction struct {
+	Change    json.RawMessage `json:"change"`
+	Signature []byte          `json:"signature"`
+}
+
+// NewTransaction returns the data of a client command that applies the change when it is committed.
+// The change is signed by the reconfiguration authority.
+func NewTransaction(change Change, authority ed25519.PrivateKey) ([]byte, error) {
+	data, err := json.Marshal(change)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := json.Marshal(transaction{
+		Change:    data,
+		Signature: ed25519.Sign(authority, data),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(bytes.Clone(transactionPrefix), tx...), nil
+}
+
+// IsTransaction returns true if the data of a client command is a reconfiguration transaction.
+func IsTransaction(data []byte) bool {
+	return bytes.HasPrefix(data, transactionPrefix)
+}
+
+// ParseTransaction parses a reconfiguration transaction and verifies that it is signed by the authority.
+func ParseTransaction(data []byte, authority ed25519.PublicKey) (Change, error) {
+	if !IsTransaction(data) {
+		return Change{}, errors.New("not a reconfiguration transaction")
+	}
+	var tx transaction
+	if err := json.Unmarshal(data[len(transactionPrefix):], &tx); err != nil {
+		return Change{}, fmt.Errorf("invalid transaction: %w", err)
+	}
+	if len(authority) != ed25519.PublicKeySize || !ed25519.Verify(authority, tx.Change, tx.Signature) {
+		return Change{}, errors.New("invalid signature")
+	}
+	var change Change
+	if err := json.Unmarshal(tx.Change, &change); err != nil {
+		return Change{}, fmt.Errorf("invalid change: %w", err)
+	}
+	return change, nil
+}
+
+// apply returns the validator set after applying the change to the given validator set.
+func (c Change) apply(validators map[hotstuff.ID]hotstuff.Validator) (map[hotstuff.ID]hotstuff.Validator, error) {
+	next := make(map[hotstuff.ID]hotstuff.Validator, len(validators)+len(c.Add))
+	for id, validator := range validators {
+		next[id] = validator
+	}
+	for _, id := range c.Remove {
+		delete(next, id)
+	}
+	for _, spec := range c.Add {
+		publicKey, err := keygen.ParsePublicKey(spec.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("validator %d: %w", spec.ID, err)
+		}
+		next[spec.ID] = hotstuff.Validator{
+			ID:        spec.ID,
+			Address:   spec.Address,
+			PublicKey: publicKey,
+			Power:     spec.Power,
+		}
+	}
+	if len(next) == 0 {
+		return nil, errors.New("the validator set cannot be empty")
+	}
+	return next, nil
+}
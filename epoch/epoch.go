@@ -0,0 +1,322 @@
+// Package epoch implements epoch-based reconfiguration of the validator set.
+//
+// The views are divided into epochs of a fixed number of views. The validator set is changed by committing
+// a reconfiguration transaction: a client command whose data is a Change signed by the reconfiguration authority.
+// A change that is committed in a block from epoch e takes effect at the start of epoch e+2.
+//
+// Since blocks are committed in ascending order of view, the validator set of epoch e+2 is final
+// once the replica has committed a block from epoch e+1 or later. Until then, a block from epoch e
+// may still be committed, so the certificates of epoch e+2 are not verified, and the configuration
+// is not changed, rather than applying a late change retroactively to views that were already verified.
+// The replica is then delayed until an earlier block is committed, so the epochs must be long enough
+// that a block is committed in every epoch.
+//
+// The Manager tracks the validator set of each epoch, such that quorum certificates are verified
+// against the public keys of the validators of the epoch of the certificate's view.
+// When the replica enters the first view of a new epoch, the Manager reconfigures the configuration,
+// which keeps the connections to the remaining validators and connects to the new ones.
+package epoch
+
+import (
+	"crypto/ed25519"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+	"github.com/relab/hotstuff/synchronizer"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultLength is the default number of views in an epoch.
+const DefaultLength = 100
+
+// validatorSet is the validator set that takes effect at the start of an epoch.
+type validatorSet struct {
+	epoch      hotstuff.Epoch
+	validators map[hotstuff.ID]hotstuff.Validator
+}
+
+// Manager tracks the validator set of each epoch.
+type Manager struct {
+	configuration modules.Configuration
+	eventLoop     *eventloop.EventLoop
+	logger        logging.Logger
+
+	length    hotstuff.View
+	authority ed25519.PublicKey
+
+	mut   sync.Mutex
+	sets  []validatorSet // in ascending order of epoch. The first set is the initial configuration.
+	nonce uint64         // the nonce of the next change.
+	// the epoch of the validator set that the configuration was last updated to.
+	active hotstuff.Epoch
+	// the view of the latest committed block, which determines the epochs whose validator sets are final.
+	committed hotstuff.View
+	// the view that the replica is in.
+	view hotstuff.View
+}
+
+// New returns a new Manager with the given number of views per epoch.
+// Only reconfiguration transactions that are signed by the authority are applied.
+func New(length hotstuff.View, authority ed25519.PublicKey) *Manager {
+	if length == 0 {
+		length = DefaultLength
+	}
+	return &Manager{
+		length:    length,
+		authority: authority,
+	}
+}
+
+// InitModule gives the module access to the other modules.
+func (m *Manager) InitModule(mods *modules.Core) {
+	mods.Get(
+		&m.configuration,
+		&m.eventLoop,
+		&m.logger,
+	)
+
+	m.eventLoop.RegisterHandler(synchronizer.ViewChangeEvent{}, func(event any) {
+		m.onViewChange(event.(synchronizer.ViewChangeEvent).View)
+	}, eventloop.Prioritize())
+}
+
+// Epoch returns the epoch that the view belongs to.
+func (m *Manager) Epoch(view hotstuff.View) hotstuff.Epoch {
+	return hotstuff.Epoch(view / m.length)
+}
+
+// StartView returns the first view of the epoch.
+func (m *Manager) StartView(epoch hotstuff.Epoch) hotstuff.View {
+	return hotstuff.View(epoch) * m.length
+}
+
+// Validators returns the public keys of the validators of the epoch that the view belongs to.
+// It returns no keys if the validator set of the epoch is not final yet.
+func (m *Manager) Validators(view hotstuff.View) map[hotstuff.ID]hotstuff.PublicKey {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	if !m.final(m.Epoch(view)) {
+		m.logger.Debugf("The validators of epoch %d are not final yet", m.Epoch(view))
+		return map[hotstuff.ID]hotstuff.PublicKey{}
+	}
+	set := m.setAt(m.Epoch(view))
+	keys := make(map[hotstuff.ID]hotstuff.PublicKey, len(set.validators))
+	for id, validator := range set.validators {
+		keys[id] = validator.PublicKey
+	}
+	return keys
+}
+
+// Power returns the voting power of the replica in the epoch that the view belongs to.
+// Replicas that are not validators in the epoch, or whose epoch's validator set is not final, have no voting power.
+func (m *Manager) Power(view hotstuff.View, id hotstuff.ID) uint64 {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if !m.final(m.Epoch(view)) {
+		return 0
+	}
+	validator, ok := m.setAt(m.Epoch(view)).validators[id]
+	if !ok {
+		return 0
+	}
+	return validator.VotingPower()
+}
+
+// TotalPower returns the total voting power of the validators in the epoch that the view belongs to.
+func (m *Manager) TotalPower(view hotstuff.View) (total uint64) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	for _, validator := range m.setAt(m.Epoch(view)).validators {
+		total = hotstuff.AddPower(total, validator.VotingPower())
+	}
+	return total
+}
+
+// ValidatorIDs returns the IDs of the validators of the epoch that the view belongs to, in ascending order.
+// If the validator set of the epoch is not final, the latest known set is returned,
+// which only affects the choice of leader and not which certificates are accepted.
+func (m *Manager) ValidatorIDs(view hotstuff.View) []hotstuff.ID {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	return slices.Sorted(maps.Keys(m.setAt(m.Epoch(view)).validators))
+}
+
+// final returns true if no more changes can be scheduled for the epoch.
+// A change for the epoch must be committed in a block from two epochs earlier, so the validator set is final
+// once a block from the previous epoch or later has been committed. The caller must hold the lock.
+func (m *Manager) final(epoch hotstuff.Epoch) bool {
+	return epoch < 2 || m.Epoch(m.committed)+1 >= epoch
+}
+
+// setAt returns the validator set that is in effect in the epoch.
+// The caller must hold the lock.
+func (m *Manager) setAt(epoch hotstuff.Epoch) validatorSet {
+	m.initialize()
+	if len(m.sets) == 0 {
+		return validatorSet{}
+	}
+	i := len(m.sets) - 1
+	for i > 0 && m.sets[i].epoch > epoch {
+		i--
+	}
+	return m.sets[i]
+}
+
+// initialize adds the replicas of the initial configuration as the validator set of epoch 0.
+// This is done on first use, since the configuration is not connected when the module is initialized.
+// The caller must hold the lock.
+func (m *Manager) initialize() {
+	if len(m.sets) > 0 {
+		return
+	}
+	validators := make(map[hotstuff.ID]hotstuff.Validator)
+	for id, replica := range m.configuration.Replicas() {
+		validators[id] = hotstuff.Validator{ID: id, PublicKey: replica.PublicKey()}
+	}
+	if len(validators) == 0 {
+		// not connected yet; try again on next use.
+		return
+	}
+	m.sets = append(m.sets, validatorSet{epoch: 0, validators: validators})
+}
+
+// WrapExecutor returns an executor that applies the reconfiguration transactions in the committed blocks,
+// and then passes the blocks on to the given executor.
+func (m *Manager) WrapExecutor(executor modules.ExecutorExt) modules.ExecutorExt {
+	return &epochExecutor{manager: m, executor: executor}
+}
+
+// exec applies the reconfiguration transactions in the block.
+// Afterwards, the configuration is updated if the block made the validator set of the current epoch final.
+func (m *Manager) exec(block *hotstuff.Block) {
+	defer m.update()
+	m.mut.Lock()
+	m.committed = max(m.committed, block.View())
+	m.mut.Unlock()
+
+	batch := new(clientpb.Batch)
+	if err := (proto.UnmarshalOptions{AllowPartial: true}).Unmarshal([]byte(block.Command()), batch); err != nil {
+		return
+	}
+	for _, cmd := range batch.GetCommands() {
+		if !IsTransaction(cmd.GetData()) {
+			continue
+		}
+		change, err := ParseTransaction(cmd.GetData(), m.authority)
+		if err != nil {
+			m.logger.Warnf("Rejected reconfiguration from client %d: %v", cmd.GetClientID(), err)
+			continue
+		}
+		m.schedule(block.View(), change)
+	}
+}
+
+// schedule applies the change, which was committed in the given view, to the validator set of epoch e+2.
+func (m *Manager) schedule(view hotstuff.View, change Change) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	if change.Nonce != m.nonce {
+		m.logger.Warnf("Rejected reconfiguration with nonce %d, expected %d", change.Nonce, m.nonce)
+		return
+	}
+	m.initialize()
+	if len(m.sets) == 0 {
+		m.logger.Warn("Rejected reconfiguration: the initial configuration is unknown")
+		return
+	}
+	target := m.Epoch(view) + 2
+	latest := m.sets[len(m.sets)-1]
+	validators, err := change.apply(latest.validators)
+	if err != nil {
+		m.logger.Warnf("Rejected reconfiguration: %v", err)
+		return
+	}
+	m.nonce++
+
+	if latest.epoch == target {
+		// another change was already scheduled for the same epoch.
+		m.sets[len(m.sets)-1].validators = validators
+	} else {
+		m.sets = append(m.sets, validatorSet{epoch: target, validators: validators})
+	}
+	m.logger.Infof("Scheduled reconfiguration for epoch %d (view %d): %d validators", target, m.StartView(target), len(validators))
+}
+
+// onViewChange updates the configuration when the replica enters an epoch with a new validator set.
+func (m *Manager) onViewChange(view hotstuff.View) {
+	m.mut.Lock()
+	m.view = max(m.view, view)
+	m.mut.Unlock()
+	m.update()
+}
+
+// update changes the configuration to the validator set of the current epoch, once it is final.
+func (m *Manager) update() {
+	m.mut.Lock()
+	epoch := m.Epoch(m.view)
+	set := m.setAt(epoch)
+	if len(m.sets) == 0 || set.epoch <= m.active || !m.final(epoch) {
+		m.mut.Unlock()
+		return
+	}
+	m.active = set.epoch
+	validators := make([]hotstuff.Validator, 0, len(set.validators))
+	for _, id := range slices.Sorted(maps.Keys(set.validators)) {
+		validators = append(validators, set.validators[id])
+	}
+	m.mut.Unlock()
+
+	if reconfigurable, ok := m.configuration.(modules.Reconfigurable); ok {
+		if err := reconfigurable.Reconfigure(validators); err != nil {
+			m.logger.Errorf("Failed to reconfigure for epoch %d: %v", epoch, err)
+			return
+		}
+	}
+
+	ids := make([]hotstuff.ID, len(validators))
+	for i, validator := range validators {
+		ids[i] = validator.ID
+	}
+	m.logger.Infof("Entered epoch %d with validators %v", epoch, ids)
+	m.eventLoop.AddEvent(hotstuff.EpochChangeEvent{Epoch: epoch, StartView: m.StartView(epoch), Validators: ids})
+}
+
+// epochExecutor applies the reconfiguration transactions before passing the blocks on to another executor.
+type epochExecutor struct {
+	manager  *Manager
+	executor modules.ExecutorExt
+}
+
+func (e *epochExecutor) InitModule(mods *modules.Core) {
+	if m, ok := e.executor.(modules.Module); ok {
+		m.InitModule(mods)
+	}
+}
+
+func (e *epochExecutor) Exec(block *hotstuff.Block) {
+	e.manager.exec(block)
+	e.executor.Exec(block)
+}
+
+func (e *epochExecutor) ExecCertified(block *hotstuff.Block, cert hotstuff.QuorumCert) {
+	e.manager.exec(block)
+	if ce, ok := e.executor.(modules.CertifiedExecutor); ok {
+		ce.ExecCertified(block, cert)
+	} else {
+		e.executor.Exec(block)
+	}
+}
+
+var (
+	_ modules.EpochManager      = (*Manager)(nil)
+	_ modules.VotingPower       = (*Manager)(nil)
+	_ modules.CertifiedExecutor = (*epochExecutor)(nil)
+)
@@ -0,0 +1,250 @@
+package epoch
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/crypto/keygen"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+	"github.com/relab/hotstuff/synchronizer"
+	"google.golang.org/protobuf/proto"
+)
+
+type testReplica struct {
+	modules.Replica
+	id     hotstuff.ID
+	pubKey hotstuff.PublicKey
+}
+
+func (r testReplica) ID() hotstuff.ID               { return r.id }
+func (r testReplica) PublicKey() hotstuff.PublicKey { return r.pubKey }
+
+// testConfig is a configuration that records the reconfigurations.
+type testConfig struct {
+	modules.Configuration
+	replicas       map[hotstuff.ID]modules.Replica
+	reconfigured   [][]hotstuff.Validator
+	reconfigureErr error
+}
+
+func (cfg *testConfig) Replicas() map[hotstuff.ID]modules.Replica {
+	return cfg.replicas
+}
+
+func (cfg *testConfig) Reconfigure(validators []hotstuff.Validator) error {
+	cfg.reconfigured = append(cfg.reconfigured, validators)
+	return cfg.reconfigureErr
+}
+
+type testExecutor struct {
+	blocks int
+}
+
+func (e *testExecutor) Exec(hotstuff.Command) { e.blocks++ }
+
+func generateKey(t *testing.T) (hotstuff.PublicKey, []byte) {
+	t.Helper()
+	pub, _, err := keygen.GenerateED25519Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pem, err := keygen.PublicKeyToPEM(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pub, pem
+}
+
+// blockWith returns a block in the given view whose batch contains the given command data.
+func blockWith(t *testing.T, view hotstuff.View, data ...[]byte) *hotstuff.Block {
+	t.Helper()
+	batch := &clientpb.Batch{}
+	for i, d := range data {
+		batch.Commands = append(batch.Commands, &clientpb.Command{ClientID: 1, SequenceNumber: uint64(i + 1), Data: d})
+	}
+	b, err := proto.Marshal(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hotstuff.NewBlock(hotstuff.GetGenesis().Hash(), hotstuff.NewQuorumCert(nil, 0, hotstuff.GetGenesis().Hash()), hotstuff.Command(b), view, 1)
+}
+
+func setup(t *testing.T) (*Manager, *testConfig, *eventloop.EventLoop, ed25519.PrivateKey) {
+	t.Helper()
+	authorityPub, authority, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &testConfig{replicas: make(map[hotstuff.ID]modules.Replica)}
+	for id := hotstuff.ID(1); id <= 4; id++ {
+		pub, _ := generateKey(t)
+		cfg.replicas[id] = testReplica{id: id, pubKey: pub}
+	}
+	manager := New(10, authorityPub)
+	eventLoop := eventloop.New(100)
+	builder := modules.NewBuilder(1, nil)
+	builder.Add(eventLoop, logging.New("test"), cfg, manager)
+	builder.Build()
+	return manager, cfg, eventLoop, authority
+}
+
+func ids(keys map[hotstuff.ID]hotstuff.PublicKey) []hotstuff.ID {
+	var ids []hotstuff.ID
+	for id := range keys {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestReconfiguration(t *testing.T) {
+	manager, cfg, eventLoop, authority := setup(t)
+	executor := &testExecutor{}
+	wrapped := manager.WrapExecutor(modules.ExtendedExecutor(executor))
+
+	newKey, newPEM := generateKey(t)
+	tx, err := NewTransaction(Change{
+		Nonce:  0,
+		Add:    []ValidatorSpec{{ID: 5, Address: "127.0.0.1:5005", PublicKey: newPEM}},
+		Remove: []hotstuff.ID{2},
+	}, authority)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// committed in epoch 1, so the change takes effect at the start of epoch 3.
+	wrapped.Exec(blockWith(t, 15, []byte("other command"), tx))
+	if executor.blocks != 1 {
+		t.Errorf("the block was not passed on to the inner executor")
+	}
+
+	// until a block from epoch 2 is committed, another change for epoch 3 may be committed.
+	if got := manager.Validators(30); len(got) != 0 {
+		t.Errorf("Validators(30) = %v before the validator set of epoch 3 is final", ids(got))
+	}
+	wrapped.Exec(blockWith(t, 20))
+
+	for _, test := range []struct {
+		view hotstuff.View
+		want []hotstuff.ID
+	}{
+		{view: 1, want: []hotstuff.ID{1, 2, 3, 4}},
+		{view: 29, want: []hotstuff.ID{1, 2, 3, 4}},
+		{view: 30, want: []hotstuff.ID{1, 3, 4, 5}},
+		{view: 39, want: []hotstuff.ID{1, 3, 4, 5}},
+		{view: 40, want: nil},
+	} {
+		if got := ids(manager.Validators(test.view)); fmt.Sprint(got) != fmt.Sprint(test.want) {
+			t.Errorf("Validators(%d) = %v, want %v", test.view, got, test.want)
+		}
+	}
+	if key := manager.Validators(30)[5]; !key.(ed25519.PublicKey).Equal(newKey) {
+		t.Error("the public key of the new validator was not applied")
+	}
+
+	// a replayed transaction is rejected.
+	wrapped.Exec(blockWith(t, 25, tx))
+	wrapped.Exec(blockWith(t, 30))
+	if got := ids(manager.Validators(40)); fmt.Sprint(got) != "[1 3 4 5]" {
+		t.Errorf("replayed change was applied: %v", got)
+	}
+
+	changes := make(chan hotstuff.EpochChangeEvent, 1)
+	eventLoop.RegisterHandler(hotstuff.EpochChangeEvent{}, func(event any) {
+		changes <- event.(hotstuff.EpochChangeEvent)
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go eventLoop.Run(ctx)
+
+	eventLoop.AddEvent(synchronizer.ViewChangeEvent{View: 29})
+	eventLoop.AddEvent(synchronizer.ViewChangeEvent{View: 31})
+	var event hotstuff.EpochChangeEvent
+	select {
+	case event = <-changes:
+	case <-ctx.Done():
+		t.Fatal("no epoch change event")
+	}
+	if event.Epoch != 3 || event.StartView != 30 || fmt.Sprint(event.Validators) != "[1 3 4 5]" {
+		t.Errorf("got epoch change %+v", event)
+	}
+	if len(cfg.reconfigured) != 1 {
+		t.Fatalf("reconfigured %d times, want 1", len(cfg.reconfigured))
+	}
+	if added := cfg.reconfigured[0][3]; added.ID != 5 || added.Address != "127.0.0.1:5005" {
+		t.Errorf("new validator %+v, want ID 5 with address", added)
+	}
+}
+
+func TestRejectUnauthorizedReconfiguration(t *testing.T) {
+	manager, _, _, _ := setup(t)
+	wrapped := manager.WrapExecutor(modules.ExtendedExecutor(&testExecutor{}))
+
+	_, other, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := NewTransaction(Change{Remove: []hotstuff.ID{1}}, other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrapped.Exec(blockWith(t, 5, tx))
+	wrapped.Exec(blockWith(t, 10))
+	if got := ids(manager.Validators(20)); fmt.Sprint(got) != "[1 2 3 4]" {
+		t.Errorf("change signed by another key was applied: %v", got)
+	}
+}
+
+func TestVotingPower(t *testing.T) {
+	manager, _, _, authority := setup(t)
+	wrapped := manager.WrapExecutor(modules.ExtendedExecutor(&testExecutor{}))
+
+	_, pem := generateKey(t)
+	tx, err := NewTransaction(Change{Add: []ValidatorSpec{{ID: 5, PublicKey: pem, Power: 6}}}, authority)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrapped.Exec(blockWith(t, 5, tx))
+	wrapped.Exec(blockWith(t, 10))
+
+	if got := manager.TotalPower(5); got != 4 {
+		t.Errorf("TotalPower(5) = %d, want 4", got)
+	}
+	if got := manager.TotalPower(20); got != 10 {
+		t.Errorf("TotalPower(20) = %d, want 10", got)
+	}
+	if got := manager.Power(20, 5); got != 6 {
+		t.Errorf("Power(20, 5) = %d, want 6", got)
+	}
+	if got := manager.Power(5, 5); got != 0 {
+		t.Errorf("Power(5, 5) = %d, want 0", got)
+	}
+}
+
+func TestReconfigurationWaitsUntilFinal(t *testing.T) {
+	manager, cfg, _, authority := setup(t)
+	wrapped := manager.WrapExecutor(modules.ExtendedExecutor(&testExecutor{}))
+
+	tx, err := NewTransaction(Change{Remove: []hotstuff.ID{2}}, authority)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrapped.Exec(blockWith(t, 15, tx))
+
+	// the replica enters epoch 3 before a block from epoch 2 is committed.
+	manager.onViewChange(31)
+	if len(cfg.reconfigured) != 0 {
+		t.Fatalf("reconfigured before the validator set of epoch 3 was final: %v", cfg.reconfigured)
+	}
+	wrapped.Exec(blockWith(t, 22))
+	if len(cfg.reconfigured) != 1 || len(cfg.reconfigured[0]) != 3 {
+		t.Fatalf("got reconfigurations %v, want one with 3 validators", cfg.reconfigured)
+	}
+}
@@ -46,6 +46,11 @@ func Prioritize() HandlerOption {
 // EventHandler processes an event.
 type EventHandler func(event any)
 
+// Preprocessor takes over an event in AddEvent, before any handlers see it.
+// It must call next with the event, or a replacement, once the event is ready to be handled,
+// or drop the event by not calling next. It may call next from another goroutine.
+type Preprocessor func(event any, next func(event any))
+
 type handler struct {
 	callback EventHandler
 	opts     handlerOpts
@@ -65,6 +70,8 @@ type EventLoop struct {
 
 	handlers map[reflect.Type][]handler
 
+	preprocessors map[reflect.Type]Preprocessor
+
 	tickers  map[int]*ticker
 	tickerID int
 }
@@ -76,6 +83,7 @@ func New(bufferSize uint) *EventLoop {
 		eventQ:        newQueue(bufferSize),
 		waitingEvents: make(map[reflect.Type][]any),
 		handlers:      make(map[reflect.Type][]handler),
+		preprocessors: make(map[reflect.Type]Preprocessor),
 		tickers:       make(map[int]*ticker),
 	}
 	return el
@@ -132,15 +140,37 @@ func (el *EventLoop) UnregisterHandler(eventType any, id int) {
 	el.handlers[t][id].callback = nil
 }
 
+// UnsafeRegisterPreprocessor registers a preprocessor for the given event type, replacing any previous preprocessor.
+// Like handlers that use the UnsafeRunInAddEvent option, the preprocessor runs as a part of AddEvent,
+// which could be running outside the event loop. Only thread-safe modules can be used safely from a preprocessor.
+// Preprocessors can be used to verify events before they reach the event queue.
+func (el *EventLoop) UnsafeRegisterPreprocessor(eventType any, preprocessor Preprocessor) {
+	el.mut.Lock()
+	defer el.mut.Unlock()
+	el.preprocessors[reflect.TypeOf(eventType)] = preprocessor
+}
+
 // AddEvent adds an event to the event queue.
 func (el *EventLoop) AddEvent(event any) {
-	if event != nil {
-		// run handlers with runInAddEvent option
-		el.processEvent(event, true)
-		droppedEvent := el.eventQ.push(event)
-		if droppedEvent != nil {
-			el.logger.Warnf("event queue is full, dropped event: %v", droppedEvent)
-		}
+	if event == nil {
+		return
+	}
+	el.mut.Lock()
+	preprocess, ok := el.preprocessors[reflect.TypeOf(event)]
+	el.mut.Unlock()
+	if ok {
+		preprocess(event, el.addEvent)
+		return
+	}
+	el.addEvent(event)
+}
+
+func (el *EventLoop) addEvent(event any) {
+	// run handlers with runInAddEvent option
+	el.processEvent(event, true)
+	droppedEvent := el.eventQ.push(event)
+	if droppedEvent != nil {
+		el.logger.Warnf("event queue is full, dropped event: %v", droppedEvent)
 	}
 }
 
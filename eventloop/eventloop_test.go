@@ -3,6 +3,7 @@ package eventloop_test
 import (
 	"context"
 	"os"
+	"slices"
 	"testing"
 	"time"
 
@@ -160,6 +161,45 @@ func TestDelayedEvent(t *testing.T) {
 	}
 }
 
+func TestPreprocessor(t *testing.T) {
+	el := eventloop.New(10)
+	var (
+		handled  []testEvent
+		inAdd    []testEvent
+		deferred func()
+	)
+	el.RegisterHandler(testEvent(0), func(event any) {
+		handled = append(handled, event.(testEvent))
+	})
+	el.RegisterHandler(testEvent(0), func(event any) {
+		inAdd = append(inAdd, event.(testEvent))
+	}, eventloop.UnsafeRunInAddEvent())
+	el.UnsafeRegisterPreprocessor(testEvent(0), func(event any, next func(any)) {
+		switch e := event.(testEvent); {
+		case e < 0: // drop
+		case e == 2: // pass on later
+			deferred = func() { next(e * 10) }
+		default:
+			next(e)
+		}
+	})
+
+	for _, e := range []testEvent{1, -1, 2, 3} {
+		el.AddEvent(e)
+	}
+	deferred()
+	for el.Tick(context.Background()) {
+	}
+
+	want := []testEvent{1, 3, 20}
+	if !slices.Equal(handled, want) {
+		t.Errorf("handled events: got: %v, want: %v", handled, want)
+	}
+	if !slices.Equal(inAdd, want) {
+		t.Errorf("events handled in AddEvent: got: %v, want: %v", inAdd, want)
+	}
+}
+
 func BenchmarkEventLoopWithPrioritize(b *testing.B) {
 	el := eventloop.New(100)
 
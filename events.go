@@ -10,6 +10,12 @@ type ProposeMsg struct {
 	ID          ID           // The ID of the replica who sent the message.
 	Block       *Block       // The block that is proposed.
 	AggregateQC *AggregateQC // Optional AggregateQC
+	TimeoutCert *TimeoutCert // Optional TC for the previous view, used by Jolteon.
+	// The proposer's signature of the block. Optional, but proposals without it
+	// cannot be used as evidence of equivocation.
+	Signature QuorumSignature
+	// Verified is set if the certificates of the proposal were verified before it was added to the event loop.
+	Verified bool
 }
 
 func (p ProposeMsg) String() string {
@@ -21,6 +27,7 @@ type VoteMsg struct {
 	ID          ID          // the ID of the replica who sent the message.
 	PartialCert PartialCert // The partial certificate.
 	Deferred    bool
+	Verified    bool // Set if the partial certificate was verified before the vote was added to the event loop.
 }
 
 func (v VoteMsg) String() string {
@@ -34,6 +41,7 @@ type TimeoutMsg struct {
 	ViewSignature QuorumSignature // A signature of the view
 	MsgSignature  QuorumSignature // A signature of the view, QC.BlockHash, and the replica ID
 	SyncInfo      SyncInfo        // The highest QC/TC known to the sender.
+	Verified      bool            // Set if the signatures were verified before the timeout was added to the event loop.
 }
 
 // ToBytes returns a byte form of the timeout message.
@@ -63,3 +71,79 @@ type NewViewMsg struct {
 type CommitEvent struct {
 	Commands int
 }
+
+// BatchEvent is raised whenever the replica has assembled a batch of commands to propose.
+type BatchEvent struct {
+	Commands  int // The number of commands in the batch.
+	Bytes     int // The size of the batch in bytes.
+	BatchSize int // The effective batch size when the batch was assembled.
+}
+
+// StateSyncedEvent is raised when the replica has downloaded the application state of a checkpoint.
+// The block is the block that the checkpoint was taken after.
+type StateSyncedEvent struct {
+	Checkpoint Checkpoint
+	Block      *Block
+}
+
+// EpochChangeEvent is raised when the replica enters the first view of a new epoch,
+// after the configuration has been updated with the validators of the epoch.
+type EpochChangeEvent struct {
+	Epoch      Epoch
+	StartView  View
+	Validators []ID // The validators of the epoch in ascending order.
+}
+
+// EvidenceMsg is gossiped by a replica that has detected that another replica signed conflicting blocks.
+type EvidenceMsg struct {
+	ID       ID // The ID of the replica who sent the message.
+	Evidence Evidence
+}
+
+func (e EvidenceMsg) String() string {
+	return fmt.Sprintf("ID %d, %s", e.ID, e.Evidence)
+}
+
+// MempoolBatchMsg is broadcast by the author of a mempool batch, and is sent in response to a request for a batch.
+type MempoolBatchMsg struct {
+	ID    ID // The ID of the replica who sent the message.
+	Batch *MempoolBatch
+}
+
+func (m MempoolBatchMsg) String() string {
+	return fmt.Sprintf("ID %d, %s", m.ID, m.Batch)
+}
+
+// AvailabilityVoteMsg is sent to the author of a mempool batch by a replica that has stored the batch.
+type AvailabilityVoteMsg struct {
+	ID        ID              // The ID of the replica who sent the message.
+	Digest    Hash            // The digest of the stored batch.
+	Signature QuorumSignature // The signature of AvailabilityBytes for the batch.
+}
+
+func (v AvailabilityVoteMsg) String() string {
+	return fmt.Sprintf("ID %d, Batch %.8s", v.ID, v.Digest)
+}
+
+// AvailabilityCertMsg is broadcast by the author of a mempool batch once the batch has been certified.
+type AvailabilityCertMsg struct {
+	ID   ID // The ID of the replica who sent the message.
+	Cert AvailabilityCert
+}
+
+func (c AvailabilityCertMsg) String() string {
+	return fmt.Sprintf("ID %d, %s", c.ID, c.Cert)
+}
+
+// DecryptionShareMsg is broadcast by a replica after it has committed a block containing encrypted commands.
+// It contains the replica's threshold decryption shares for the commands in the block.
+// A message without shares is a request for the shares of the other replicas.
+type DecryptionShareMsg struct {
+	ID        ID       // The ID of the replica who sent the message.
+	BlockHash Hash     // The hash of the committed block.
+	Shares    [][]byte // One decryption share per command in the block. Empty if the command is invalid.
+}
+
+func (d DecryptionShareMsg) String() string {
+	return fmt.Sprintf("ID %d, Block %.8s, Shares: %d", d.ID, d.BlockHash, len(d.Shares))
+}
@@ -0,0 +1,385 @@
+// Package evidence detects replicas that sign two different blocks in the same view.
+//
+// The Pool watches the signed proposals and the votes that the replica receives.
+// When it sees that a replica has signed two different blocks in the same view, either as the proposer
+// or as a voter, it builds an Evidence object containing both blocks and signatures, and gossips it
+// to the other replicas. The evidence that has not yet been committed is included in the batches proposed
+// by the replica, such that the offender can be punished when the evidence is committed.
+//
+// Only equivocation is detected. A leader that proposes a single block that does not extend the highest
+// certified block, like the fork strategy in the consensus/byzantine package, has not signed conflicting blocks,
+// and its proposal cannot be proven to be faulty to a replica that does not know a higher certificate.
+// Such leaders are not punished; the voting rules of the consensus protocol keep the fork from being committed.
+package evidence
+
+import (
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+	"github.com/relab/hotstuff/synchronizer"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultWindow is the default number of views for which the signed blocks are remembered.
+const DefaultWindow = 100
+
+// key identifies the block signed by a replica in a view.
+type key struct {
+	view hotstuff.View
+	id   hotstuff.ID
+}
+
+// signed is a block together with a signature of it.
+type signed struct {
+	block     *hotstuff.Block
+	signature hotstuff.QuorumSignature
+}
+
+// Pool detects conflicting signed blocks and collects the resulting evidence.
+type Pool struct {
+	blockChain    modules.BlockChain
+	configuration modules.Configuration
+	crypto        modules.Crypto
+	epochs        modules.EpochManager // nil if the validator set never changes.
+	eventLoop     *eventloop.EventLoop
+	logger        logging.Logger
+	sender        modules.EvidenceSender // nil if the configuration cannot gossip evidence.
+
+	window hotstuff.View
+
+	mut       sync.Mutex
+	proposals map[key]signed
+	votes     map[key]signed
+	pending   map[key]hotstuff.Evidence
+	committed map[key]struct{}
+}
+
+// New returns a new Pool that remembers the signed blocks of the given number of views.
+func New(window hotstuff.View) *Pool {
+	if window == 0 {
+		window = DefaultWindow
+	}
+	return &Pool{
+		window:    window,
+		proposals: make(map[key]signed),
+		votes:     make(map[key]signed),
+		pending:   make(map[key]hotstuff.Evidence),
+		committed: make(map[key]struct{}),
+	}
+}
+
+// InitModule gives the module access to the other modules.
+func (p *Pool) InitModule(mods *modules.Core) {
+	mods.Get(
+		&p.blockChain,
+		&p.configuration,
+		&p.crypto,
+		&p.eventLoop,
+		&p.logger,
+	)
+	mods.TryGet(&p.epochs)
+	p.sender, _ = p.configuration.(modules.EvidenceSender)
+
+	p.eventLoop.RegisterHandler(hotstuff.ProposeMsg{}, func(event any) {
+		p.OnProposal(event.(hotstuff.ProposeMsg))
+	})
+	p.eventLoop.RegisterHandler(hotstuff.VoteMsg{}, func(event any) {
+		p.OnVote(event.(hotstuff.VoteMsg))
+	})
+	p.eventLoop.RegisterHandler(hotstuff.EvidenceMsg{}, func(event any) {
+		p.OnEvidence(event.(hotstuff.EvidenceMsg))
+	})
+	p.eventLoop.RegisterHandler(synchronizer.ViewChangeEvent{}, func(event any) {
+		p.prune(event.(synchronizer.ViewChangeEvent).View)
+	})
+}
+
+// OnProposal records the block of a signed proposal.
+func (p *Pool) OnProposal(proposal hotstuff.ProposeMsg) {
+	if proposal.Block == nil || proposal.Signature == nil {
+		return
+	}
+	if signer, ok := soleSigner(proposal.Signature); !ok || signer != proposal.Block.Proposer() {
+		return
+	}
+	p.observe(p.proposals, proposal.Block, proposal.Signature)
+}
+
+// OnVote records the block that a vote was cast for.
+// Votes for blocks that are not known locally are ignored.
+func (p *Pool) OnVote(vote hotstuff.VoteMsg) {
+	block, ok := p.blockChain.LocalGet(vote.PartialCert.BlockHash())
+	if !ok {
+		return
+	}
+	p.observe(p.votes, block, vote.PartialCert.Signature())
+}
+
+// OnEvidence handles evidence gossiped by another replica.
+func (p *Pool) OnEvidence(msg hotstuff.EvidenceMsg) {
+	if err := p.Verify(msg.Evidence); err != nil {
+		p.logger.Infof("Invalid evidence from replica %d: %v", msg.ID, err)
+		return
+	}
+	p.add(msg.Evidence)
+}
+
+// observe records that the block was signed, and creates evidence if the signer has signed another block in the same view.
+func (p *Pool) observe(seen map[key]signed, block *hotstuff.Block, signature hotstuff.QuorumSignature) {
+	signer, ok := soleSigner(signature)
+	if !ok {
+		return
+	}
+	k := key{view: block.View(), id: signer}
+
+	p.mut.Lock()
+	prev, ok := seen[k]
+	if ok && (prev.block.Hash() == block.Hash() || p.known(k)) {
+		p.mut.Unlock()
+		return
+	}
+	p.mut.Unlock()
+
+	// only blocks that were actually signed by the signer are stored,
+	// such that a forged signature cannot hide the signer's real block.
+	if !p.verify(block.View(), signature, block.ToBytes()) {
+		return
+	}
+	if !ok {
+		p.mut.Lock()
+		if _, ok := seen[k]; !ok {
+			seen[k] = signed{block: block, signature: signature}
+		}
+		p.mut.Unlock()
+		return
+	}
+
+	evidence := hotstuff.Evidence{
+		Offender:        signer,
+		First:           prev.block,
+		FirstSignature:  prev.signature,
+		Second:          block,
+		SecondSignature: signature,
+	}
+	if err := p.Verify(evidence); err != nil {
+		p.logger.Infof("Conflicting blocks signed by replica %d are not valid evidence: %v", signer, err)
+		return
+	}
+	p.add(evidence)
+}
+
+// add adds verified evidence to the pool and gossips it, unless evidence against the offender in the same view is already known.
+func (p *Pool) add(evidence hotstuff.Evidence) {
+	k := key{view: evidence.View(), id: evidence.Offender}
+	p.mut.Lock()
+	if p.known(k) {
+		p.mut.Unlock()
+		return
+	}
+	p.pending[k] = evidence
+	p.mut.Unlock()
+
+	p.logger.Warnf("Replica %d signed conflicting blocks in view %d", evidence.Offender, evidence.View())
+	if p.sender != nil {
+		p.sender.Evidence(evidence)
+	}
+}
+
+// known returns true if there is evidence against the replica in the view.
+// The caller must hold the lock.
+func (p *Pool) known(k key) bool {
+	if _, ok := p.pending[k]; ok {
+		return true
+	}
+	_, ok := p.committed[k]
+	return ok
+}
+
+// Verify returns an error if the evidence does not prove that the offender signed two different blocks in the same view.
+func (p *Pool) Verify(evidence hotstuff.Evidence) error {
+	if evidence.First == nil || evidence.Second == nil {
+		return errors.New("missing block")
+	}
+	if evidence.FirstSignature == nil || evidence.SecondSignature == nil {
+		return errors.New("missing signature")
+	}
+	if evidence.First.View() != evidence.Second.View() {
+		return errors.New("the blocks are from different views")
+	}
+	if evidence.First.Hash() == evidence.Second.Hash() {
+		return errors.New("the blocks are the same")
+	}
+	for _, signature := range []hotstuff.QuorumSignature{evidence.FirstSignature, evidence.SecondSignature} {
+		if signer, ok := soleSigner(signature); !ok || signer != evidence.Offender {
+			return errors.New("the blocks are not signed by the offender only")
+		}
+	}
+	if !p.verify(evidence.View(), evidence.FirstSignature, evidence.First.ToBytes()) ||
+		!p.verify(evidence.View(), evidence.SecondSignature, evidence.Second.ToBytes()) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// verify verifies a signature that was created in the view. If an EpochManager is available,
+// the signature is verified with the keys of the validators of the view's epoch, such that evidence
+// is judged by the keys that the offender had when it signed the blocks, not the keys of the current configuration.
+func (p *Pool) verify(view hotstuff.View, signature hotstuff.QuorumSignature, message []byte) bool {
+	if p.epochs == nil {
+		return p.crypto.Verify(signature, message)
+	}
+	kv, ok := p.crypto.(modules.KeyedVerifier)
+	if !ok {
+		p.logger.Errorf("Cannot verify signatures from view %d with the keys of its epoch", view)
+		return false
+	}
+	return kv.VerifyWithKeys(signature, message, p.epochs.Validators(view))
+}
+
+// Pending returns the evidence that has been verified, but not yet committed, in order of view and offender.
+func (p *Pool) Pending() []hotstuff.Evidence {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	keys := make([]key, 0, len(p.pending))
+	for k := range p.pending {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b key) int {
+		if a.view != b.view {
+			return int(a.view) - int(b.view)
+		}
+		return int(a.id) - int(b.id)
+	})
+	evidence := make([]hotstuff.Evidence, len(keys))
+	for i, k := range keys {
+		evidence[i] = p.pending[k]
+	}
+	return evidence
+}
+
+// commit marks the evidence as committed, such that it is no longer proposed.
+func (p *Pool) commit(evidence hotstuff.Evidence) {
+	k := key{view: evidence.View(), id: evidence.Offender}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	delete(p.pending, k)
+	p.committed[k] = struct{}{}
+}
+
+// prune forgets the signed blocks from views that are older than the window.
+// Committed evidence is forgotten too, since it can no longer be proposed again.
+func (p *Pool) prune(view hotstuff.View) {
+	if view <= p.window {
+		return
+	}
+	oldest := view - p.window
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	for _, m := range []map[key]signed{p.proposals, p.votes} {
+		for k := range m {
+			if k.view < oldest {
+				delete(m, k)
+			}
+		}
+	}
+	for k := range p.committed {
+		if k.view < oldest {
+			delete(p.committed, k)
+		}
+	}
+}
+
+// soleSigner returns the signer of a signature that has exactly one participant.
+func soleSigner(signature hotstuff.QuorumSignature) (signer hotstuff.ID, ok bool) {
+	participants := signature.Participants()
+	if participants.Len() != 1 {
+		return 0, false
+	}
+	participants.ForEach(func(id hotstuff.ID) { signer = id })
+	return signer, true
+}
+
+// FromBatch returns the evidence included in the batch.
+// Entries that cannot be decoded are skipped.
+func FromBatch(batch *clientpb.Batch) []hotstuff.Evidence {
+	evidence := make([]hotstuff.Evidence, 0, len(batch.GetEvidence()))
+	for _, b := range batch.GetEvidence() {
+		m := new(hotstuffpb.Evidence)
+		if err := proto.Unmarshal(b, m); err != nil {
+			continue
+		}
+		evidence = append(evidence, hotstuffpb.EvidenceFromProto(m))
+	}
+	return evidence
+}
+
+// ToBatch adds the evidence to the batch.
+func ToBatch(batch *clientpb.Batch, evidence []hotstuff.Evidence) error {
+	for _, e := range evidence {
+		b, err := proto.MarshalOptions{Deterministic: true}.Marshal(hotstuffpb.EvidenceToProto(e))
+		if err != nil {
+			return err
+		}
+		batch.Evidence = append(batch.Evidence, b)
+	}
+	return nil
+}
+
+// WrapExecutor returns an executor that marks the evidence in the committed blocks as committed,
+// and then passes the blocks on to the given executor.
+func (p *Pool) WrapExecutor(executor modules.ExecutorExt) modules.ExecutorExt {
+	return &evidenceExecutor{pool: p, executor: executor}
+}
+
+// exec marks the valid evidence in the block as committed.
+func (p *Pool) exec(block *hotstuff.Block) {
+	batch := new(clientpb.Batch)
+	if err := (proto.UnmarshalOptions{AllowPartial: true}).Unmarshal([]byte(block.Command()), batch); err != nil {
+		return
+	}
+	for _, evidence := range FromBatch(batch) {
+		if err := p.Verify(evidence); err != nil {
+			p.logger.Infof("Committed block %.8s contains invalid evidence: %v", block.Hash(), err)
+			continue
+		}
+		p.commit(evidence)
+	}
+}
+
+// evidenceExecutor marks committed evidence before passing the blocks on to another executor.
+type evidenceExecutor struct {
+	pool     *Pool
+	executor modules.ExecutorExt
+}
+
+func (e *evidenceExecutor) InitModule(mods *modules.Core) {
+	if m, ok := e.executor.(modules.Module); ok {
+		m.InitModule(mods)
+	}
+}
+
+func (e *evidenceExecutor) Exec(block *hotstuff.Block) {
+	e.pool.exec(block)
+	e.executor.Exec(block)
+}
+
+func (e *evidenceExecutor) ExecCertified(block *hotstuff.Block, cert hotstuff.QuorumCert) {
+	e.pool.exec(block)
+	if ce, ok := e.executor.(modules.CertifiedExecutor); ok {
+		ce.ExecCertified(block, cert)
+	} else {
+		e.executor.Exec(block)
+	}
+}
+
+var (
+	_ modules.EvidencePool      = (*Pool)(nil)
+	_ modules.CertifiedExecutor = (*evidenceExecutor)(nil)
+)
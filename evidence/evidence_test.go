@@ -0,0 +1,199 @@
+package evidence
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+)
+
+// testSignature is a signature of msg by the signers.
+type testSignature struct {
+	signers []hotstuff.ID
+	msg     []byte
+}
+
+func (s testSignature) ToBytes() []byte { return s.msg }
+
+func (s testSignature) Participants() hotstuff.IDSet {
+	participants := hotstuff.NewIDSet()
+	for _, id := range s.signers {
+		participants.Add(id)
+	}
+	return participants
+}
+
+func sign(block *hotstuff.Block, signers ...hotstuff.ID) testSignature {
+	return testSignature{signers: signers, msg: block.ToBytes()}
+}
+
+// testCrypto accepts the testSignatures of the message.
+type testCrypto struct {
+	modules.Crypto
+}
+
+func (testCrypto) Verify(signature hotstuff.QuorumSignature, message []byte) bool {
+	s, ok := signature.(testSignature)
+	return ok && bytes.Equal(s.msg, message)
+}
+
+// testConfig records the gossiped evidence.
+type testConfig struct {
+	modules.Configuration
+	gossiped []hotstuff.Evidence
+}
+
+func (cfg *testConfig) Evidence(evidence hotstuff.Evidence) {
+	cfg.gossiped = append(cfg.gossiped, evidence)
+}
+
+type testBlockChain struct {
+	modules.BlockChain
+	blocks map[hotstuff.Hash]*hotstuff.Block
+}
+
+func (bc *testBlockChain) LocalGet(hash hotstuff.Hash) (*hotstuff.Block, bool) {
+	block, ok := bc.blocks[hash]
+	return block, ok
+}
+
+func newTestPool(t *testing.T, blocks ...*hotstuff.Block) (*Pool, *testConfig) {
+	t.Helper()
+	blockChain := &testBlockChain{blocks: make(map[hotstuff.Hash]*hotstuff.Block)}
+	for _, block := range blocks {
+		blockChain.blocks[block.Hash()] = block
+	}
+	cfg := &testConfig{}
+	pool := New(10)
+	builder := modules.NewBuilder(1, nil)
+	builder.Add(
+		eventloop.New(100),
+		logging.New("test"),
+		blockChain,
+		testCrypto{},
+		cfg,
+		pool,
+	)
+	builder.Build()
+	return pool, cfg
+}
+
+func testBlock(cmd hotstuff.Command, view hotstuff.View, proposer hotstuff.ID) *hotstuff.Block {
+	genesis := hotstuff.GetGenesis()
+	return hotstuff.NewBlock(genesis.Hash(), hotstuff.NewQuorumCert(nil, 0, genesis.Hash()), cmd, view, proposer)
+}
+
+func TestConflictingProposals(t *testing.T) {
+	pool, cfg := newTestPool(t)
+	first := testBlock("a", 5, 2)
+	second := testBlock("b", 5, 2)
+	third := testBlock("c", 5, 2)
+
+	pool.OnProposal(hotstuff.ProposeMsg{ID: 2, Block: first, Signature: sign(first, 2)})
+	pool.OnProposal(hotstuff.ProposeMsg{ID: 2, Block: first, Signature: sign(first, 2)})
+	if len(pool.Pending()) != 0 {
+		t.Fatal("a repeated proposal was reported as evidence")
+	}
+	pool.OnProposal(hotstuff.ProposeMsg{ID: 2, Block: second, Signature: sign(second, 2)})
+	pool.OnProposal(hotstuff.ProposeMsg{ID: 2, Block: third, Signature: sign(third, 2)})
+
+	pending := pool.Pending()
+	if len(pending) != 1 {
+		t.Fatalf("got %d pending evidence, want 1", len(pending))
+	}
+	if got := pending[0]; got.Offender != 2 || got.View() != 5 || got.First.Hash() != first.Hash() || got.Second.Hash() != second.Hash() {
+		t.Errorf("unexpected evidence: %v", got)
+	}
+	if len(cfg.gossiped) != 1 {
+		t.Errorf("evidence was gossiped %d times, want 1", len(cfg.gossiped))
+	}
+
+	// evidence received from another replica is ignored once it is known.
+	pool.OnEvidence(hotstuff.EvidenceMsg{ID: 3, Evidence: pending[0]})
+	if len(cfg.gossiped) != 1 {
+		t.Errorf("known evidence was gossiped again")
+	}
+
+	pool.commit(pending[0])
+	if len(pool.Pending()) != 0 {
+		t.Error("committed evidence is still pending")
+	}
+	pool.OnEvidence(hotstuff.EvidenceMsg{ID: 3, Evidence: pending[0]})
+	if len(pool.Pending()) != 0 {
+		t.Error("committed evidence was added again")
+	}
+}
+
+func TestConflictingVotes(t *testing.T) {
+	first := testBlock("a", 7, 1)
+	second := testBlock("b", 7, 4)
+	pool, _ := newTestPool(t, first, second)
+
+	pool.OnVote(hotstuff.VoteMsg{ID: 3, PartialCert: hotstuff.NewPartialCert(sign(first, 3), first.Hash())})
+	pool.OnVote(hotstuff.VoteMsg{ID: 2, PartialCert: hotstuff.NewPartialCert(sign(second, 2), second.Hash())})
+	if len(pool.Pending()) != 0 {
+		t.Fatal("votes from different replicas were reported as evidence")
+	}
+	pool.OnVote(hotstuff.VoteMsg{ID: 3, PartialCert: hotstuff.NewPartialCert(sign(second, 3), second.Hash())})
+	pending := pool.Pending()
+	if len(pending) != 1 || pending[0].Offender != 3 {
+		t.Fatalf("got evidence %v, want evidence against replica 3", pending)
+	}
+
+	// the signed blocks are forgotten after the window.
+	pool.prune(20)
+	pool.OnVote(hotstuff.VoteMsg{ID: 2, PartialCert: hotstuff.NewPartialCert(sign(first, 2), first.Hash())})
+	if len(pool.Pending()) != 1 {
+		t.Error("a vote from a pruned view was reported as evidence")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	pool, _ := newTestPool(t)
+	first := testBlock("a", 5, 2)
+	second := testBlock("b", 5, 2)
+	other := testBlock("b", 6, 2)
+
+	valid := hotstuff.Evidence{Offender: 2, First: first, FirstSignature: sign(first, 2), Second: second, SecondSignature: sign(second, 2)}
+	if err := pool.Verify(valid); err != nil {
+		t.Fatalf("valid evidence rejected: %v", err)
+	}
+
+	for _, test := range []struct {
+		name     string
+		evidence hotstuff.Evidence
+	}{
+		{"missing block", hotstuff.Evidence{Offender: 2, First: first, FirstSignature: sign(first, 2)}},
+		{"same block", hotstuff.Evidence{Offender: 2, First: first, FirstSignature: sign(first, 2), Second: first, SecondSignature: sign(first, 2)}},
+		{"different views", hotstuff.Evidence{Offender: 2, First: first, FirstSignature: sign(first, 2), Second: other, SecondSignature: sign(other, 2)}},
+		{"other offender", hotstuff.Evidence{Offender: 3, First: first, FirstSignature: sign(first, 2), Second: second, SecondSignature: sign(second, 2)}},
+		{"several signers", hotstuff.Evidence{Offender: 2, First: first, FirstSignature: sign(first, 2, 3), Second: second, SecondSignature: sign(second, 2)}},
+		{"invalid signature", hotstuff.Evidence{Offender: 2, First: first, FirstSignature: sign(first, 2), Second: second, SecondSignature: sign(first, 2)}},
+	} {
+		if err := pool.Verify(test.evidence); err == nil {
+			t.Errorf("%s: invalid evidence accepted", test.name)
+		}
+	}
+}
+
+func TestForgedProposal(t *testing.T) {
+	pool, _ := newTestPool(t)
+	forged := testBlock("a", 5, 2)
+	first := testBlock("b", 5, 2)
+	second := testBlock("c", 5, 2)
+
+	// a forged signature must not take the place of the replica's real block.
+	pool.OnProposal(hotstuff.ProposeMsg{ID: 2, Block: forged, Signature: sign(first, 2)})
+	pool.OnProposal(hotstuff.ProposeMsg{ID: 2, Block: first, Signature: sign(first, 2)})
+	if len(pool.Pending()) != 0 {
+		t.Fatal("a proposal with a forged signature was reported as evidence")
+	}
+	pool.OnProposal(hotstuff.ProposeMsg{ID: 2, Block: second, Signature: sign(second, 2)})
+	pending := pool.Pending()
+	if len(pending) != 1 || pending[0].First.Hash() != first.Hash() || pending[0].Second.Hash() != second.Hash() {
+		t.Fatalf("got evidence %v, want evidence of the correctly signed blocks", pending)
+	}
+}
@@ -0,0 +1,172 @@
+package evm
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+	"github.com/relab/hotstuff/txpool"
+	"google.golang.org/protobuf/proto"
+)
+
+// BatchExecutor executes committed HotStuff blocks whose command is a batch of client commands,
+// where the data of each command is a JSON encoded transaction.
+// It can be used to execute the batches produced by the encrypted mempool after they have been decrypted.
+type BatchExecutor struct {
+	mut      sync.Mutex
+	executor *Executor
+	stateDB  StateDB
+	gasLimit uint64
+	blocks   []*EVMBlock
+	logger   logging.Logger
+	evidence modules.EvidencePool // nil if evidence is not verified.
+}
+
+// NewBatchExecutor creates a new batch executor that applies the transactions to the state database.
+func NewBatchExecutor(config ExecutionConfig, stateDB StateDB) *BatchExecutor {
+	return &BatchExecutor{
+		executor: NewExecutor(config),
+		stateDB:  stateDB,
+		gasLimit: config.GasLimit,
+		logger:   logging.New("evm-batch-executor"),
+	}
+}
+
+// InitModule gives the module access to the other modules.
+// If an evidence pool is available, the evidence in the committed blocks is used to slash the offenders.
+func (be *BatchExecutor) InitModule(mods *modules.Core) {
+	mods.TryGet(&be.evidence)
+}
+
+// Exec executes the transactions in the block.
+// Commands whose data is not a valid transaction are skipped.
+func (be *BatchExecutor) Exec(block *hotstuff.Block) {
+	batch := new(clientpb.Batch)
+	err := proto.UnmarshalOptions{AllowPartial: true}.Unmarshal([]byte(block.Command()), batch)
+	if err != nil {
+		be.logger.Errorf("Failed to unmarshal command: %v", err)
+		return
+	}
+
+	transactions := make([]*txpool.Transaction, 0, len(batch.GetCommands()))
+	for _, cmd := range batch.GetCommands() {
+		if len(cmd.GetData()) == 0 {
+			continue
+		}
+		tx := new(txpool.Transaction)
+		if err := json.Unmarshal(cmd.GetData(), tx); err != nil {
+			be.logger.Infof("Command %d from client %d is not a transaction: %v", cmd.GetSequenceNumber(), cmd.GetClientID(), err)
+			continue
+		}
+		transactions = append(transactions, tx)
+	}
+
+	be.mut.Lock()
+	defer be.mut.Unlock()
+
+	be.slash(batch)
+
+	stateRoot := be.stateDB.GetStateRoot()
+	evmBlock := NewEVMBlock(block.Parent(), block.QuorumCert(), transactions, block.View(), block.Proposer(), stateRoot, be.gasLimit)
+
+	receipts, err := be.executor.ExecuteBlock(evmBlock, be.stateDB)
+	if err != nil {
+		be.logger.Errorf("Failed to execute block: %v", err)
+		return
+	}
+	evmBlock.UpdateReceipts(receipts)
+
+	if _, err := be.stateDB.Commit(); err != nil {
+		be.logger.Errorf("Failed to commit state: %v", err)
+		return
+	}
+	be.blocks = append(be.blocks, evmBlock)
+}
+
+// slash slashes the offenders of the valid evidence in the batch.
+// The caller must hold the lock.
+func (be *BatchExecutor) slash(batch *clientpb.Batch) {
+	staking := be.executor.Staking()
+	if staking == nil || be.evidence == nil {
+		return
+	}
+	for _, b := range batch.GetEvidence() {
+		m := new(hotstuffpb.Evidence)
+		if err := proto.Unmarshal(b, m); err != nil {
+			continue
+		}
+		evidence := hotstuffpb.EvidenceFromProto(m)
+		if err := be.evidence.Verify(evidence); err != nil {
+			be.logger.Infof("Skipping invalid evidence: %v", err)
+			continue
+		}
+		if staking.Slash(be.stateDB, evidence.Offender, evidence.View()) {
+			be.logger.Infof("Slashed validator %d for signing conflicting blocks in view %d", evidence.Offender, evidence.View())
+		}
+	}
+}
+
+// Blocks returns the EVM blocks that have been executed so far.
+func (be *BatchExecutor) Blocks() []*EVMBlock {
+	be.mut.Lock()
+	defer be.mut.Unlock()
+	return append([]*EVMBlock(nil), be.blocks...)
+}
+
+// PruneBelow removes the EVM blocks and their receipts with a view lower than the given view.
+// If archive is not nil, each block is passed to archive as JSON, keyed by "evmblock:" followed by its hash.
+func (be *BatchExecutor) PruneBelow(view hotstuff.View, archive func(key, value []byte) error) error {
+	be.mut.Lock()
+	defer be.mut.Unlock()
+
+	i := 0
+	for ; i < len(be.blocks) && be.blocks[i].View() < view; i++ {
+		if archive == nil {
+			continue
+		}
+		block := be.blocks[i]
+		data, err := json.Marshal(block)
+		if err != nil {
+			return fmt.Errorf("failed to marshal block %.8s: %w", block.Hash(), err)
+		}
+		hash := block.Hash()
+		if err := archive(append([]byte("evmblock:"), hash[:]...), data); err != nil {
+			return fmt.Errorf("failed to archive block %.8s: %w", block.Hash(), err)
+		}
+	}
+	// the blocks are in order of view, so only the pruned blocks are visited.
+	clear(be.blocks[:i])
+	be.blocks = be.blocks[i:]
+	return nil
+}
+
+// StateRoot returns the root of the state after executing the blocks so far.
+func (be *BatchExecutor) StateRoot() hotstuff.Hash {
+	be.mut.Lock()
+	defer be.mut.Unlock()
+	return be.stateDB.GetStateRoot()
+}
+
+// Restore replaces the state with the committed state that has the given root.
+// The nodes of the state trie must already be stored in the database of the state.
+// This is only supported if the state is a TrieStateDB.
+func (be *BatchExecutor) Restore(stateRoot hotstuff.Hash) error {
+	be.mut.Lock()
+	defer be.mut.Unlock()
+
+	trieState, ok := be.stateDB.(*TrieStateDB)
+	if !ok || trieState.db == nil {
+		return fmt.Errorf("restoring state requires a persistent trie state database")
+	}
+	stateDB, err := NewTrieStateDBWithRoot(trieState.db, stateRoot)
+	if err != nil {
+		return err
+	}
+	be.stateDB = stateDB
+	return nil
+}
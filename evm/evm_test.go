@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/trie"
 	"github.com/relab/hotstuff/txpool"
 )
 
@@ -105,6 +106,41 @@ func TestStateDBOperations(t *testing.T) {
 	t.Logf("StateDB operations test passed")
 }
 
+func TestTrieStateDBRestore(t *testing.T) {
+	db, err := trie.NewBadgerTrieDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open trie database: %v", err)
+	}
+	defer db.Close()
+
+	stateDB := NewTrieStateDB(db)
+	addr := createTestAddress("0x1000000000000000000000000000000000000001")
+	balance := big.NewInt(1000000000000000000)
+	key := hotstuff.Hash{1, 2, 3}
+	value := hotstuff.Hash{4, 5, 6}
+	stateDB.SetBalance(addr, balance)
+	stateDB.SetState(addr, key, value)
+
+	root, err := stateDB.Commit()
+	if err != nil {
+		t.Fatalf("Failed to commit state: %v", err)
+	}
+
+	restored, err := NewTrieStateDBWithRoot(db, root)
+	if err != nil {
+		t.Fatalf("Failed to restore state: %v", err)
+	}
+	if got := restored.GetBalance(addr); got.Cmp(balance) != 0 {
+		t.Errorf("Expected balance %s, got %s", balance, got)
+	}
+	if got := restored.GetState(addr, key); got != value {
+		t.Errorf("Expected storage value %x, got %x", value, got)
+	}
+	if restored.GetStateRoot() != root {
+		t.Errorf("Expected state root %x, got %x", root, restored.GetStateRoot())
+	}
+}
+
 func TestTransactionExecution(t *testing.T) {
 	// Create executor
 	config := ExecutionConfig{
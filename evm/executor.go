@@ -13,20 +13,28 @@ type ExecutionConfig struct {
 	GasLimit uint64   // Block gas limit
 	BaseFee  *big.Int // EIP-1559 base fee
 	ChainID  *big.Int // Chain ID for replay protection
+
+	// Staking enables the staking system contract at StakingAddress, if not nil.
+	Staking *StakingConfig
 }
 
 // Executor handles transaction execution and state transitions
 type Executor struct {
-	config ExecutionConfig
-	logger logging.Logger
+	config  ExecutionConfig
+	staking *Staking
+	logger  logging.Logger
 }
 
 // NewExecutor creates a new transaction executor
 func NewExecutor(config ExecutionConfig) *Executor {
-	return &Executor{
+	e := &Executor{
 		config: config,
 		logger: logging.New("evm-executor"),
 	}
+	if config.Staking != nil {
+		e.staking = NewStaking(*config.Staking)
+	}
+	return e
 }
 
 // ExecuteBlock executes all transactions in a block and returns receipts
@@ -53,6 +61,10 @@ func (e *Executor) ExecuteBlock(block *EVMBlock, stateDB StateDB) ([]*Transactio
 		}
 	}
 
+	if e.staking != nil {
+		e.staking.endBlock(block, stateDB)
+	}
+
 	e.logger.Infof("Block execution completed, gas used: %d/%d", cumulativeGasUsed, e.config.GasLimit)
 	return receipts, nil
 }
@@ -151,6 +163,9 @@ func (e *Executor) applyTransaction(tx *txpool.Transaction, stateDB StateDB,
 	if tx.To == nil {
 		// Contract creation
 		contractAddress, gasUsed, logs, err = e.createContract(tx, stateDB, from)
+	} else if e.staking != nil && *tx.To == StakingAddress {
+		// Staking system contract
+		gasUsed, logs, err = e.staking.call(tx, stateDB, block, from)
 	} else {
 		// Contract call or value transfer
 		gasUsed, logs, err = e.callContract(tx, stateDB, from)
@@ -191,6 +206,11 @@ func (e *Executor) applyTransaction(tx *txpool.Transaction, stateDB StateDB,
 	return receipt, nil
 }
 
+// Staking returns the staking system contract, or nil if staking is not enabled.
+func (e *Executor) Staking() *Staking {
+	return e.staking
+}
+
 // createContract handles contract creation transactions (now delegated to simple_vm.go)
 func (e *Executor) createContract(tx *txpool.Transaction, stateDB StateDB, from txpool.Address) (*txpool.Address, uint64, []*Log, error) {
 	return e.CreateContractWithEVM(tx, stateDB, from)
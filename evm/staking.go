@@ -0,0 +1,687 @@
+package evm
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/txpool"
+)
+
+// StakingAddress is the address of the staking system contract.
+// Transactions sent to this address are executed natively by the Executor.
+// The contract holds the bonded stake, the unbonding stake and the unclaimed rewards.
+var StakingAddress = txpool.Address{18: 0x10, 19: 0x00}
+
+// Function selectors of the staking system contract.
+// The selector is the first byte of the transaction data.
+const (
+	stakingBond     byte = 0x01 // bond(id uint32): bond the value as self-stake of validator id.
+	stakingUnbond   byte = 0x02 // unbond(id uint32, amount uint256): start unbonding stake from validator id.
+	stakingDelegate byte = 0x03 // delegate(id uint32): delegate the value to validator id.
+	stakingClaim    byte = 0x04 // claim(): withdraw rewards and stake that has finished unbonding.
+)
+
+const (
+	// stakingCallGas is the gas used by a call to the staking system contract.
+	stakingCallGas = 50000
+	// stakingEntryGas is the additional gas used for each stored entry that a call iterates over.
+	stakingEntryGas = 5000
+)
+
+// Default limits of the staking system contract.
+const (
+	defaultMaxValidators = 100
+	defaultMaxDelegators = 100
+	defaultMaxUnbondings = 16
+)
+
+// rewardScale is the precision of the accumulated reward per unit of stake.
+var rewardScale = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+
+// StakingConfig holds the configuration of the staking system contract.
+// Zero limits are replaced by their defaults.
+type StakingConfig struct {
+	BlockReward     *big.Int `json:"block_reward"`     // Reward minted for each executed block, paid out at the end of each epoch
+	EpochLength     uint64   `json:"epoch_length"`     // Number of blocks in a reward epoch
+	UnbondingPeriod uint64   `json:"unbonding_period"` // Number of epochs before unbonded stake can be claimed
+	SlashPercent    uint64   `json:"slash_percent"`    // Percentage of the stake of a validator that is burned when it is slashed
+	MaxValidators   uint64   `json:"max_validators"`   // Maximum number of validators that can be registered
+	MaxDelegators   uint64   `json:"max_delegators"`   // Maximum number of accounts with bonded stake, and with unbonding stake, in a validator
+	MaxUnbondings   uint64   `json:"max_unbondings"`   // Maximum number of unclaimed unbonding entries of an account
+}
+
+// BondData returns the transaction data that bonds the transaction value as self-stake of the validator.
+// The first account to bond to a validator ID becomes its operator; only the operator can bond more self-stake.
+func BondData(id hotstuff.ID) []byte {
+	return binary.BigEndian.AppendUint32([]byte{stakingBond}, uint32(id))
+}
+
+// UnbondData returns the transaction data that unbonds the amount of the sender's stake in the validator.
+// The amount can be claimed after the unbonding period.
+func UnbondData(id hotstuff.ID, amount *big.Int) []byte {
+	data := binary.BigEndian.AppendUint32([]byte{stakingUnbond}, uint32(id))
+	return append(data, amount.FillBytes(make([]byte, 32))...)
+}
+
+// DelegateData returns the transaction data that delegates the transaction value to the validator.
+func DelegateData(id hotstuff.ID) []byte {
+	return binary.BigEndian.AppendUint32([]byte{stakingDelegate}, uint32(id))
+}
+
+// ClaimData returns the transaction data that withdraws the sender's rewards and unbonded stake.
+func ClaimData() []byte {
+	return []byte{stakingClaim}
+}
+
+// Staking implements the staking system contract.
+// All of its state is kept in the storage of StakingAddress, such that it is part of the state root
+// and is reverted together with the rest of the state.
+type Staking struct {
+	config StakingConfig
+}
+
+// NewStaking returns the staking system contract with the given configuration.
+func NewStaking(config StakingConfig) *Staking {
+	if config.BlockReward == nil {
+		config.BlockReward = new(big.Int)
+	}
+	if config.EpochLength == 0 {
+		config.EpochLength = 1
+	}
+	if config.MaxValidators == 0 {
+		config.MaxValidators = defaultMaxValidators
+	}
+	if config.MaxDelegators == 0 {
+		config.MaxDelegators = defaultMaxDelegators
+	}
+	if config.MaxUnbondings == 0 {
+		config.MaxUnbondings = defaultMaxUnbondings
+	}
+	return &Staking{config: config}
+}
+
+// Validators returns the IDs of the validators that have bonded stake, in order of registration.
+func (s *Staking) Validators(stateDB StateDB) []hotstuff.ID {
+	n := s.getUint(stateDB, stakingKey("validators"))
+	ids := make([]hotstuff.ID, 0, n)
+	for i := range n {
+		ids = append(ids, hotstuff.ID(s.getUint(stateDB, stakingKey("validator", u64(i)))))
+	}
+	return ids
+}
+
+// Operator returns the address of the account that registered the validator.
+func (s *Staking) Operator(stateDB StateDB, id hotstuff.ID) (txpool.Address, bool) {
+	addr := s.getAddress(stateDB, stakingKey("operator", u32(id)))
+	return addr, addr != (txpool.Address{})
+}
+
+// Stake returns the total stake bonded to the validator, including delegations.
+func (s *Staking) Stake(stateDB StateDB, id hotstuff.ID) *big.Int {
+	return s.getBig(stateDB, stakingKey("stake", u32(id)))
+}
+
+// Delegation returns the stake that the account has bonded to the validator.
+func (s *Staking) Delegation(stateDB StateDB, id hotstuff.ID, addr txpool.Address) *big.Int {
+	return s.getBig(stateDB, stakingKey("delegation", u32(id), addr[:]))
+}
+
+// Rewards returns the unclaimed rewards of the account, including the rewards that have not been settled yet.
+func (s *Staking) Rewards(stateDB StateDB, addr txpool.Address) *big.Int {
+	rewards := s.getBig(stateDB, stakingKey("reward", addr[:]))
+	for _, id := range s.Validators(stateDB) {
+		rewards.Add(rewards, s.pending(stateDB, id, addr))
+	}
+	return rewards
+}
+
+// Unbonding returns the stake of the account that is unbonding, including stake that can already be claimed.
+func (s *Staking) Unbonding(stateDB StateDB, addr txpool.Address) *big.Int {
+	total := new(big.Int)
+	n := s.getUint(stateDB, stakingKey("unbondings", addr[:]))
+	for i := range n {
+		total.Add(total, s.unbonding(stateDB, addr, i).amount)
+	}
+	return total
+}
+
+// epoch returns the reward epoch of the block.
+func (s *Staking) epoch(block *EVMBlock) uint64 {
+	return block.Header.Number.Uint64() / s.config.EpochLength
+}
+
+// gas returns the gas used by the call. A claim iterates over the validators and the unbonding entries of the sender,
+// and pays for each of them; the other calls use a bounded number of entries.
+func (s *Staking) gas(tx *txpool.Transaction, stateDB StateDB, from txpool.Address) uint64 {
+	if len(tx.Data) == 0 || tx.Data[0] != stakingClaim {
+		return stakingCallGas
+	}
+	entries := s.getUint(stateDB, stakingKey("validators")) + s.getUint(stateDB, stakingKey("unbondings", from[:]))
+	return stakingCallGas + entries*stakingEntryGas
+}
+
+// call executes a transaction sent to the staking system contract.
+// The state changes made by a failed call are reverted, but the gas is still used.
+func (s *Staking) call(tx *txpool.Transaction, stateDB StateDB, block *EVMBlock, from txpool.Address) (uint64, []*Log, error) {
+	gas := s.gas(tx, stateDB, from)
+	if tx.GasLimit < gas {
+		return tx.GasLimit, nil, fmt.Errorf("out of gas: staking call requires %d gas", gas)
+	}
+	snapshot := stateDB.Snapshot()
+	if err := s.dispatch(tx, stateDB, block, from); err != nil {
+		stateDB.RevertToSnapshot(snapshot)
+		return gas, nil, err
+	}
+	log := &Log{
+		Address:     StakingAddress,
+		Topics:      []hotstuff.Hash{sha256.Sum256(tx.Data[:1])},
+		Data:        tx.Data,
+		BlockNumber: block.Header.Number,
+		TxHash:      tx.Hash(),
+		BlockHash:   block.Hash(),
+	}
+	return gas, []*Log{log}, nil
+}
+
+func (s *Staking) dispatch(tx *txpool.Transaction, stateDB StateDB, block *EVMBlock, from txpool.Address) error {
+	if len(tx.Data) == 0 {
+		return errors.New("missing staking function selector")
+	}
+	value := tx.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	switch selector, args := tx.Data[0], tx.Data[1:]; selector {
+	case stakingBond, stakingDelegate:
+		if len(args) != 4 {
+			return errors.New("invalid arguments")
+		}
+		if value.Sign() <= 0 {
+			return errors.New("no stake in transaction value")
+		}
+		id := hotstuff.ID(binary.BigEndian.Uint32(args))
+		if err := s.checkOperator(stateDB, id, from, selector == stakingBond); err != nil {
+			return err
+		}
+		if err := s.addDelegation(stateDB, id, from, value); err != nil {
+			return err
+		}
+		stateDB.SubBalance(from, value)
+		stateDB.AddBalance(StakingAddress, value)
+		return nil
+
+	case stakingUnbond:
+		if len(args) != 4+32 {
+			return errors.New("invalid arguments")
+		}
+		if value.Sign() != 0 {
+			return errors.New("unbond does not accept value")
+		}
+		id := hotstuff.ID(binary.BigEndian.Uint32(args))
+		amount := new(big.Int).SetBytes(args[4:])
+		return s.unbond(stateDB, id, from, amount, block)
+
+	case stakingClaim:
+		if len(args) != 0 {
+			return errors.New("invalid arguments")
+		}
+		if value.Sign() != 0 {
+			return errors.New("claim does not accept value")
+		}
+		s.claim(stateDB, from, s.epoch(block))
+		return nil
+
+	default:
+		return fmt.Errorf("unknown staking function selector: %#x", selector)
+	}
+}
+
+// checkOperator registers the sender as the operator of a new validator when bonding,
+// and checks that the validator exists and, when bonding, that the sender is its operator.
+func (s *Staking) checkOperator(stateDB StateDB, id hotstuff.ID, from txpool.Address, bond bool) error {
+	operator, ok := s.Operator(stateDB, id)
+	switch {
+	case !ok && bond:
+		n := s.getUint(stateDB, stakingKey("validators"))
+		if n >= s.config.MaxValidators {
+			return fmt.Errorf("the maximum number of validators (%d) is registered", s.config.MaxValidators)
+		}
+		s.setAddress(stateDB, stakingKey("operator", u32(id)), from)
+		s.setUint(stateDB, stakingKey("validator", u64(n)), uint64(id))
+		s.setUint(stateDB, stakingKey("validators"), n+1)
+		return nil
+	case !ok:
+		return fmt.Errorf("validator %d does not exist", id)
+	case bond && operator != from:
+		return fmt.Errorf("validator %d is operated by another account", id)
+	}
+	return nil
+}
+
+func (s *Staking) addDelegation(stateDB StateDB, id hotstuff.ID, from txpool.Address, amount *big.Int) error {
+	if !s.isDelegator(stateDB, id, from) {
+		if !s.addAccount(stateDB, "delegator", id, from) {
+			return fmt.Errorf("validator %d has the maximum number of delegators (%d)", id, s.config.MaxDelegators)
+		}
+	}
+	delegation := s.Delegation(stateDB, id, from)
+	s.setDelegation(stateDB, id, from, delegation.Add(delegation, amount))
+	stake := s.Stake(stateDB, id)
+	s.setBig(stateDB, stakingKey("stake", u32(id)), stake.Add(stake, amount))
+	return nil
+}
+
+// Each validator has a list of the delegators with bonded stake in it,
+// and a list of the unbonders, the accounts with unbonding entries from it.
+// Both lists are bounded by the MaxDelegators limit.
+
+// isDelegator returns true if the account is in the list of delegators of the validator.
+func (s *Staking) isDelegator(stateDB StateDB, id hotstuff.ID, addr txpool.Address) bool {
+	return s.hasAccount(stateDB, "delegator", id, addr)
+}
+
+// removeDelegator removes the account from the list of delegators of the validator.
+func (s *Staking) removeDelegator(stateDB StateDB, id hotstuff.ID, addr txpool.Address) {
+	s.removeAccount(stateDB, "delegator", id, addr)
+}
+
+func (s *Staking) delegators(stateDB StateDB, id hotstuff.ID) []txpool.Address {
+	return s.accounts(stateDB, "delegator", id)
+}
+
+func (s *Staking) unbonders(stateDB StateDB, id hotstuff.ID) []txpool.Address {
+	return s.accounts(stateDB, "unbonder", id)
+}
+
+// hasAccount returns true if the account is in the named list of the validator.
+// The index of each account is stored, offset by one, such that the list is not searched.
+func (s *Staking) hasAccount(stateDB StateDB, list string, id hotstuff.ID, addr txpool.Address) bool {
+	return s.getUint(stateDB, stakingKey(list+"Index", u32(id), addr[:])) != 0
+}
+
+// addAccount appends the account to the named list of the validator.
+// It returns false if the list already has the maximum number of accounts.
+func (s *Staking) addAccount(stateDB StateDB, list string, id hotstuff.ID, addr txpool.Address) bool {
+	n := s.getUint(stateDB, stakingKey(list+"s", u32(id)))
+	if n >= s.config.MaxDelegators {
+		return false
+	}
+	s.setAddress(stateDB, stakingKey(list, u32(id), u64(n)), addr)
+	s.setUint(stateDB, stakingKey(list+"Index", u32(id), addr[:]), n+1)
+	s.setUint(stateDB, stakingKey(list+"s", u32(id)), n+1)
+	return true
+}
+
+// removeAccount removes the account from the named list of the validator,
+// by moving the last account into its place.
+func (s *Staking) removeAccount(stateDB StateDB, list string, id hotstuff.ID, addr txpool.Address) {
+	indexKey := stakingKey(list+"Index", u32(id), addr[:])
+	index := s.getUint(stateDB, indexKey)
+	if index == 0 {
+		return
+	}
+	n := s.getUint(stateDB, stakingKey(list+"s", u32(id)))
+	last := s.getAddress(stateDB, stakingKey(list, u32(id), u64(n-1)))
+	s.setAddress(stateDB, stakingKey(list, u32(id), u64(index-1)), last)
+	s.setUint(stateDB, stakingKey(list+"Index", u32(id), last[:]), index)
+	s.setAddress(stateDB, stakingKey(list, u32(id), u64(n-1)), txpool.Address{})
+	s.setUint(stateDB, indexKey, 0)
+	s.setUint(stateDB, stakingKey(list+"s", u32(id)), n-1)
+}
+
+func (s *Staking) accounts(stateDB StateDB, list string, id hotstuff.ID) []txpool.Address {
+	n := s.getUint(stateDB, stakingKey(list+"s", u32(id)))
+	accounts := make([]txpool.Address, 0, n)
+	for i := range n {
+		accounts = append(accounts, s.getAddress(stateDB, stakingKey(list, u32(id), u64(i))))
+	}
+	return accounts
+}
+
+// The rewards of the delegators are settled lazily: each validator accumulates the reward per unit of stake,
+// and each delegation records the accumulated reward at the time it last changed.
+// The difference is credited to the delegator when its delegation changes or when it claims.
+
+// The earned rewards are rounded down and the recorded rewards are rounded up,
+// such that the delegators are never paid more than what was distributed to the validator.
+
+// pending returns the rewards that the delegation has earned since they were last settled.
+func (s *Staking) pending(stateDB StateDB, id hotstuff.ID, addr txpool.Address) *big.Int {
+	earned := s.earned(stateDB, id, s.Delegation(stateDB, id, addr), false)
+	earned.Sub(earned, s.getBig(stateDB, stakingKey("debt", u32(id), addr[:])))
+	if earned.Sign() < 0 {
+		return new(big.Int)
+	}
+	return earned
+}
+
+// earned returns the rewards that the amount of stake has earned since the validator was registered,
+// rounded down or up.
+func (s *Staking) earned(stateDB StateDB, id hotstuff.ID, amount *big.Int, roundUp bool) *big.Int {
+	earned := new(big.Int).Mul(amount, s.getBig(stateDB, stakingKey("acc", u32(id))))
+	if roundUp {
+		earned.Add(earned, new(big.Int).Sub(rewardScale, big.NewInt(1)))
+	}
+	return earned.Quo(earned, rewardScale)
+}
+
+// settle credits the pending rewards of the delegation to the delegator.
+func (s *Staking) settle(stateDB StateDB, id hotstuff.ID, addr txpool.Address) {
+	pending := s.pending(stateDB, id, addr)
+	if pending.Sign() > 0 {
+		rewards := s.getBig(stateDB, stakingKey("reward", addr[:]))
+		s.setBig(stateDB, stakingKey("reward", addr[:]), rewards.Add(rewards, pending))
+	}
+	s.setBig(stateDB, stakingKey("debt", u32(id), addr[:]), s.earned(stateDB, id, s.Delegation(stateDB, id, addr), true))
+}
+
+// setDelegation settles the rewards of the delegation and changes its amount.
+// It does not change the total stake of the validator.
+func (s *Staking) setDelegation(stateDB StateDB, id hotstuff.ID, addr txpool.Address, amount *big.Int) {
+	s.settle(stateDB, id, addr)
+	s.setBig(stateDB, stakingKey("delegation", u32(id), addr[:]), amount)
+	s.setBig(stateDB, stakingKey("debt", u32(id), addr[:]), s.earned(stateDB, id, amount, true))
+}
+
+// unbondingEntry is stake that is unbonding from a validator.
+type unbondingEntry struct {
+	amount  *big.Int
+	release uint64        // the epoch in which the entry can be claimed.
+	id      hotstuff.ID   // the validator that the stake was unbonded from.
+	view    hotstuff.View // the view of the block in which the stake was unbonded.
+}
+
+// unbonding returns the i-th unbonding entry of the account.
+func (s *Staking) unbonding(stateDB StateDB, addr txpool.Address, i uint64) unbondingEntry {
+	return unbondingEntry{
+		amount:  s.getBig(stateDB, stakingKey("unbonding", addr[:], u64(i))),
+		release: s.getUint(stateDB, stakingKey("release", addr[:], u64(i))),
+		id:      hotstuff.ID(s.getUint(stateDB, stakingKey("unbondingValidator", addr[:], u64(i)))),
+		view:    hotstuff.View(s.getUint(stateDB, stakingKey("unbondingView", addr[:], u64(i)))),
+	}
+}
+
+func (s *Staking) setUnbonding(stateDB StateDB, addr txpool.Address, i uint64, entry unbondingEntry) {
+	s.setBig(stateDB, stakingKey("unbonding", addr[:], u64(i)), entry.amount)
+	s.setUint(stateDB, stakingKey("release", addr[:], u64(i)), entry.release)
+	s.setUint(stateDB, stakingKey("unbondingValidator", addr[:], u64(i)), uint64(entry.id))
+	s.setUint(stateDB, stakingKey("unbondingView", addr[:], u64(i)), uint64(entry.view))
+}
+
+// unbond removes the amount from the sender's stake in the validator,
+// and adds an unbonding entry that can be claimed after the unbonding period.
+// The sender is added to the unbonders of the validator, such that the entry can be slashed.
+func (s *Staking) unbond(stateDB StateDB, id hotstuff.ID, from txpool.Address, amount *big.Int, block *EVMBlock) error {
+	if amount.Sign() <= 0 {
+		return errors.New("unbond amount must be positive")
+	}
+	delegation := s.Delegation(stateDB, id, from)
+	if delegation.Cmp(amount) < 0 {
+		return fmt.Errorf("insufficient stake: have %s, want to unbond %s", delegation, amount)
+	}
+	n := s.getUint(stateDB, stakingKey("unbondings", from[:]))
+	if n >= s.config.MaxUnbondings {
+		return fmt.Errorf("the maximum number of unbonding entries (%d) must be claimed first", s.config.MaxUnbondings)
+	}
+	if !s.hasAccount(stateDB, "unbonder", id, from) && !s.addAccount(stateDB, "unbonder", id, from) {
+		return fmt.Errorf("validator %d has the maximum number of unbonding accounts (%d)", id, s.config.MaxDelegators)
+	}
+	s.setDelegation(stateDB, id, from, delegation.Sub(delegation, amount))
+	if delegation.Sign() == 0 {
+		s.removeDelegator(stateDB, id, from)
+	}
+	stake := s.Stake(stateDB, id)
+	s.setBig(stateDB, stakingKey("stake", u32(id)), stake.Sub(stake, amount))
+
+	s.setUnbonding(stateDB, from, n, unbondingEntry{
+		amount:  amount,
+		release: s.epoch(block) + s.config.UnbondingPeriod,
+		id:      id,
+		view:    block.View(),
+	})
+	s.setUint(stateDB, stakingKey("unbondings", from[:]), n+1)
+	return nil
+}
+
+// claim transfers the rewards of the sender, and the unbonding entries that are released in the epoch, to the sender.
+// The sender is removed from the unbonders of the validators that it no longer has unbonding entries from.
+func (s *Staking) claim(stateDB StateDB, from txpool.Address, epoch uint64) {
+	validators := s.Validators(stateDB)
+	for _, id := range validators {
+		s.settle(stateDB, id, from)
+	}
+	payout := s.getBig(stateDB, stakingKey("reward", from[:]))
+	s.setBig(stateDB, stakingKey("reward", from[:]), new(big.Int))
+
+	// move the entries that are still unbonding to the front of the list.
+	n := s.getUint(stateDB, stakingKey("unbondings", from[:]))
+	var kept uint64
+	unbonding := make(map[hotstuff.ID]bool)
+	for i := range n {
+		entry := s.unbonding(stateDB, from, i)
+		if entry.release <= epoch {
+			payout.Add(payout, entry.amount)
+			continue
+		}
+		s.setUnbonding(stateDB, from, kept, entry)
+		unbonding[entry.id] = true
+		kept++
+	}
+	for i := kept; i < n; i++ {
+		s.setUnbonding(stateDB, from, i, unbondingEntry{amount: new(big.Int)})
+	}
+	s.setUint(stateDB, stakingKey("unbondings", from[:]), kept)
+
+	for _, id := range validators {
+		if !unbonding[id] {
+			s.removeAccount(stateDB, "unbonder", id, from)
+		}
+	}
+
+	if payout.Sign() > 0 {
+		stateDB.SubBalance(StakingAddress, payout)
+		stateDB.AddBalance(from, payout)
+	}
+}
+
+// Jailed returns true if the validator has been slashed.
+// A jailed validator forfeits the rewards of its delegators from then on, but nothing else:
+// the staking contract does not determine the validator set, so the validator keeps its voting power.
+// There is no way to leave jail; the delegators can only unbond their stake.
+func (s *Staking) Jailed(stateDB StateDB, id hotstuff.ID) bool {
+	return s.getUint(stateDB, stakingKey("jailed", u32(id))) != 0
+}
+
+// Slash punishes the validator for signing conflicting blocks in the view.
+// The configured percentage of the stake of each delegator is burned, as well as of each unbonding entry
+// that was unbonded from the validator in or after the view, such that the stake cannot escape by unbonding.
+// The validator is jailed, which only forfeits its rewards; see Jailed.
+// The number of delegators and unbonders is bounded by the MaxDelegators limit,
+// and the number of entries of each unbonder by the MaxUnbondings limit.
+// It returns false if the validator does not exist or has already been slashed for the view.
+func (s *Staking) Slash(stateDB StateDB, id hotstuff.ID, view hotstuff.View) bool {
+	if _, ok := s.Operator(stateDB, id); !ok {
+		return false
+	}
+	slashedKey := stakingKey("slashed", u32(id), u64(uint64(view)))
+	if s.getUint(stateDB, slashedKey) != 0 {
+		return false
+	}
+	s.setUint(stateDB, slashedKey, 1)
+	s.setUint(stateDB, stakingKey("jailed", u32(id)), 1)
+
+	burned := new(big.Int)
+	percent := new(big.Int).SetUint64(min(s.config.SlashPercent, 100))
+	for _, delegator := range s.delegators(stateDB, id) {
+		delegation := s.Delegation(stateDB, id, delegator)
+		burn := new(big.Int).Mul(delegation, percent)
+		burn.Quo(burn, big.NewInt(100))
+		s.setDelegation(stateDB, id, delegator, delegation.Sub(delegation, burn))
+		burned.Add(burned, burn)
+	}
+	stake := s.Stake(stateDB, id)
+	s.setBig(stateDB, stakingKey("stake", u32(id)), stake.Sub(stake, burned))
+
+	// the unbonding stake is no longer part of the stake of the validator.
+	for _, unbonder := range s.unbonders(stateDB, id) {
+		n := s.getUint(stateDB, stakingKey("unbondings", unbonder[:]))
+		for i := range n {
+			entry := s.unbonding(stateDB, unbonder, i)
+			if entry.id != id || entry.view < view {
+				continue
+			}
+			burn := new(big.Int).Mul(entry.amount, percent)
+			burn.Quo(burn, big.NewInt(100))
+			entry.amount.Sub(entry.amount, burn)
+			s.setUnbonding(stateDB, unbonder, i, entry)
+			burned.Add(burned, burn)
+		}
+	}
+	if burned.Sign() > 0 {
+		stateDB.SubBalance(StakingAddress, burned)
+	}
+	return true
+}
+
+// endBlock is called after the transactions of a block have been executed.
+// When the block is the first of a new epoch, the rewards of the previous epoch are distributed.
+// Then, the block reward is added to the reward pool of the epoch,
+// and the validators that signed the quorum certificate of the block are credited for their participation.
+func (s *Staking) endBlock(block *EVMBlock, stateDB StateDB) {
+	epoch := s.epoch(block)
+	if epoch > s.getUint(stateDB, stakingKey("epoch")) {
+		s.distribute(stateDB)
+		s.setUint(stateDB, stakingKey("epoch"), epoch)
+	}
+
+	if s.config.BlockReward.Sign() > 0 {
+		pool := s.getBig(stateDB, stakingKey("pool"))
+		s.setBig(stateDB, stakingKey("pool"), pool.Add(pool, s.config.BlockReward))
+		stateDB.AddBalance(StakingAddress, s.config.BlockReward)
+	}
+
+	signature := block.QuorumCert().Signature()
+	if signature == nil {
+		return
+	}
+	if _, ok := signature.(hotstuff.ThresholdSignature); ok {
+		// a threshold signature does not reveal its signers, so all validators are credited,
+		// and the rewards are distributed in proportion to stake alone.
+		for _, id := range s.Validators(stateDB) {
+			s.credit(stateDB, id)
+		}
+		return
+	}
+	signature.Participants().ForEach(func(id hotstuff.ID) {
+		if _, ok := s.Operator(stateDB, id); ok {
+			s.credit(stateDB, id)
+		}
+	})
+}
+
+// credit records that the validator signed a quorum certificate in the current epoch.
+func (s *Staking) credit(stateDB StateDB, id hotstuff.ID) {
+	key := stakingKey("signed", u32(id))
+	s.setUint(stateDB, key, s.getUint(stateDB, key)+1)
+}
+
+// distribute pays out the reward pool to the validators in proportion to their stake
+// multiplied by the number of quorum certificates that they signed in the epoch.
+// The reward of each validator is added to its accumulated reward per unit of stake,
+// from which its delegators are paid in proportion to their stake when their rewards are settled.
+// Hence, the cost is bounded by the number of validators, and not by the number of delegators.
+// What cannot be divided evenly is kept in the pool for the next epoch.
+func (s *Staking) distribute(stateDB StateDB) {
+	validators := s.Validators(stateDB)
+	weights := make([]*big.Int, len(validators))
+	total := new(big.Int)
+	for i, id := range validators {
+		signed := s.getUint(stateDB, stakingKey("signed", u32(id)))
+		if s.Jailed(stateDB, id) {
+			signed = 0
+		}
+		weights[i] = new(big.Int).Mul(s.Stake(stateDB, id), new(big.Int).SetUint64(signed))
+		total.Add(total, weights[i])
+		s.setUint(stateDB, stakingKey("signed", u32(id)), 0)
+	}
+	pool := s.getBig(stateDB, stakingKey("pool"))
+	if total.Sign() == 0 || pool.Sign() == 0 {
+		return
+	}
+
+	paid := new(big.Int)
+	for i, id := range validators {
+		if weights[i].Sign() == 0 {
+			continue
+		}
+		share := new(big.Int).Mul(pool, weights[i])
+		share.Quo(share, total)
+		stake := s.Stake(stateDB, id)
+		perStake := new(big.Int).Mul(share, rewardScale)
+		perStake.Quo(perStake, stake)
+		acc := s.getBig(stateDB, stakingKey("acc", u32(id)))
+		s.setBig(stateDB, stakingKey("acc", u32(id)), acc.Add(acc, perStake))
+		// the delegators can claim at most the reward per unit of stake times their stake, rounded up,
+		// which is at most the share of the validator.
+		reward := new(big.Int).Mul(perStake, stake)
+		reward.Add(reward, new(big.Int).Sub(rewardScale, big.NewInt(1)))
+		paid.Add(paid, reward.Quo(reward, rewardScale))
+	}
+	s.setBig(stateDB, stakingKey("pool"), pool.Sub(pool, paid))
+}
+
+// stakingKey returns the storage key of a value in the staking system contract.
+func stakingKey(name string, parts ...[]byte) hotstuff.Hash {
+	h := sha256.New()
+	h.Write([]byte(name))
+	for _, part := range parts {
+		h.Write(part)
+	}
+	var key hotstuff.Hash
+	h.Sum(key[:0])
+	return key
+}
+
+func u32(id hotstuff.ID) []byte {
+	return binary.BigEndian.AppendUint32(nil, uint32(id))
+}
+
+func u64(i uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, i)
+}
+
+func (s *Staking) getBig(stateDB StateDB, key hotstuff.Hash) *big.Int {
+	value := stateDB.GetState(StakingAddress, key)
+	return new(big.Int).SetBytes(value[:])
+}
+
+func (s *Staking) setBig(stateDB StateDB, key hotstuff.Hash, value *big.Int) {
+	var h hotstuff.Hash
+	value.FillBytes(h[:])
+	stateDB.SetState(StakingAddress, key, h)
+}
+
+func (s *Staking) getUint(stateDB StateDB, key hotstuff.Hash) uint64 {
+	return s.getBig(stateDB, key).Uint64()
+}
+
+func (s *Staking) setUint(stateDB StateDB, key hotstuff.Hash, value uint64) {
+	s.setBig(stateDB, key, new(big.Int).SetUint64(value))
+}
+
+func (s *Staking) getAddress(stateDB StateDB, key hotstuff.Hash) txpool.Address {
+	value := stateDB.GetState(StakingAddress, key)
+	var addr txpool.Address
+	copy(addr[:], value[12:])
+	return addr
+}
+
+func (s *Staking) setAddress(stateDB StateDB, key hotstuff.Hash, addr txpool.Address) {
+	var value hotstuff.Hash
+	copy(value[12:], addr[:])
+	stateDB.SetState(StakingAddress, key, value)
+}
@@ -0,0 +1,326 @@
+package evm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/txpool"
+)
+
+// testQuorumSignature is a quorum signature that is only used for its participants.
+type testQuorumSignature struct {
+	participants hotstuff.IDSet
+}
+
+func (s testQuorumSignature) ToBytes() []byte              { return nil }
+func (s testQuorumSignature) Participants() hotstuff.IDSet { return s.participants }
+
+// blockSignedBy returns a block with the given number whose quorum certificate is signed by the given replicas.
+func blockSignedBy(number uint64, signers ...hotstuff.ID) *EVMBlock {
+	participants := hotstuff.NewIDSet()
+	for _, id := range signers {
+		participants.Add(id)
+	}
+	cert := hotstuff.NewQuorumCert(testQuorumSignature{participants}, hotstuff.View(number-1), hotstuff.Hash{})
+	return NewEVMBlock(hotstuff.Hash{}, cert, nil, hotstuff.View(number), 1, hotstuff.Hash{}, 8000000)
+}
+
+func stakingTx(value int64, data []byte) *txpool.Transaction {
+	return &txpool.Transaction{
+		GasPrice: big.NewInt(1000000000),
+		GasLimit: 100000,
+		To:       &StakingAddress,
+		Value:    big.NewInt(value),
+		Data:     data,
+		ChainID:  big.NewInt(1337),
+	}
+}
+
+func newStakingExecutor() *Executor {
+	return NewExecutor(ExecutionConfig{
+		GasLimit: 8000000,
+		BaseFee:  big.NewInt(1000000000),
+		ChainID:  big.NewInt(1337),
+		Staking: &StakingConfig{
+			BlockReward:     big.NewInt(1000),
+			EpochLength:     10,
+			UnbondingPeriod: 2,
+		},
+	})
+}
+
+func TestStakingBondTransaction(t *testing.T) {
+	executor := newStakingExecutor()
+	stateDB := NewInMemoryStateDB()
+
+	tx := stakingTx(100, BondData(1))
+	from, _ := executor.getSender(tx)
+	stateDB.CreateAccount(from)
+	stateDB.SetBalance(from, big.NewInt(1e18))
+
+	receipt, err := executor.ExecuteTransaction(tx, stateDB, blockSignedBy(1), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Status != 1 || len(receipt.Logs) != 1 {
+		t.Fatalf("bond failed: status %d, %d logs", receipt.Status, len(receipt.Logs))
+	}
+	staking := executor.Staking()
+	if operator, ok := staking.Operator(stateDB, 1); !ok || operator != from {
+		t.Errorf("Operator(1) = %v, %v, want %v", operator, ok, from)
+	}
+	if got := staking.Stake(stateDB, 1); got.Int64() != 100 {
+		t.Errorf("Stake(1) = %v, want 100", got)
+	}
+	if got := stateDB.GetBalance(StakingAddress); got.Int64() != 100 {
+		t.Errorf("staking contract balance = %v, want 100", got)
+	}
+}
+
+func TestStakingRewards(t *testing.T) {
+	executor := newStakingExecutor()
+	staking := executor.Staking()
+	stateDB := NewInMemoryStateDB()
+
+	operator1 := txpool.Address{1}
+	operator2 := txpool.Address{2}
+	delegator := txpool.Address{3}
+	for _, addr := range []txpool.Address{operator1, operator2, delegator} {
+		stateDB.CreateAccount(addr)
+		stateDB.SetBalance(addr, big.NewInt(1000))
+	}
+
+	call := func(from txpool.Address, tx *txpool.Transaction, block *EVMBlock) error {
+		t.Helper()
+		_, _, err := staking.call(tx, stateDB, block, from)
+		return err
+	}
+	mustCall := func(from txpool.Address, tx *txpool.Transaction, block *EVMBlock) {
+		t.Helper()
+		if err := call(from, tx, block); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	block := blockSignedBy(1)
+	mustCall(operator1, stakingTx(100, BondData(1)), block)
+	mustCall(operator2, stakingTx(300, BondData(2)), block)
+	mustCall(delegator, stakingTx(100, DelegateData(1)), block)
+
+	if err := call(delegator, stakingTx(10, BondData(1)), block); err == nil {
+		t.Error("bonded self-stake to a validator operated by another account")
+	}
+	if err := call(delegator, stakingTx(10, DelegateData(3)), block); err == nil {
+		t.Error("delegated to a validator that does not exist")
+	}
+	if err := call(delegator, stakingTx(0, UnbondData(1, big.NewInt(101))), block); err == nil {
+		t.Error("unbonded more than the delegated stake")
+	}
+	if got := stateDB.GetBalance(delegator); got.Int64() != 900 {
+		t.Errorf("failed calls were not reverted: delegator balance = %v, want 900", got)
+	}
+	if got := staking.Validators(stateDB); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("Validators() = %v, want [1 2]", got)
+	}
+
+	// epoch 0: validator 1 signs all 9 certificates, and validator 2 signs 3 of them.
+	for number := uint64(1); number < 10; number++ {
+		signers := []hotstuff.ID{1}
+		if number%3 == 0 {
+			signers = append(signers, 2)
+		}
+		staking.endBlock(blockSignedBy(number, signers...), stateDB)
+	}
+	// the rewards of epoch 0 are distributed by the first block of epoch 1.
+	staking.endBlock(blockSignedBy(10), stateDB)
+
+	// the pool of 9000 is divided by stake times participation: 200*9 for validator 1 and 300*3 for validator 2.
+	for _, test := range []struct {
+		addr txpool.Address
+		want int64
+	}{{operator1, 3000}, {delegator, 3000}, {operator2, 3000}} {
+		if got := staking.Rewards(stateDB, test.addr); got.Int64() != test.want {
+			t.Errorf("Rewards(%x) = %v, want %v", test.addr[:1], got, test.want)
+		}
+	}
+
+	// unbonding in epoch 1 is released in epoch 3.
+	mustCall(delegator, stakingTx(0, UnbondData(1, big.NewInt(50))), blockSignedBy(11))
+	if got := staking.Stake(stateDB, 1); got.Int64() != 150 {
+		t.Errorf("Stake(1) = %v, want 150", got)
+	}
+	mustCall(delegator, stakingTx(0, ClaimData()), blockSignedBy(12))
+	if got := stateDB.GetBalance(delegator); got.Int64() != 900+3000 {
+		t.Errorf("delegator balance after claiming rewards = %v, want %v", got, 900+3000)
+	}
+	if got := staking.Unbonding(stateDB, delegator); got.Int64() != 50 {
+		t.Errorf("Unbonding() = %v, want 50", got)
+	}
+	mustCall(delegator, stakingTx(0, ClaimData()), blockSignedBy(30))
+	if got := stateDB.GetBalance(delegator); got.Int64() != 900+3000+50 {
+		t.Errorf("delegator balance after claiming unbonded stake = %v, want %v", got, 900+3000+50)
+	}
+	if got := staking.Unbonding(stateDB, delegator); got.Sign() != 0 {
+		t.Errorf("Unbonding() = %v, want 0", got)
+	}
+}
+
+func TestStakingSlash(t *testing.T) {
+	executor := NewExecutor(ExecutionConfig{
+		GasLimit: 8000000,
+		BaseFee:  big.NewInt(1000000000),
+		ChainID:  big.NewInt(1337),
+		Staking: &StakingConfig{
+			BlockReward:  big.NewInt(1000),
+			EpochLength:  10,
+			SlashPercent: 10,
+		},
+	})
+	staking := executor.Staking()
+	stateDB := NewInMemoryStateDB()
+
+	operator := txpool.Address{1}
+	delegator := txpool.Address{2}
+	for _, addr := range []txpool.Address{operator, delegator} {
+		stateDB.CreateAccount(addr)
+		stateDB.SetBalance(addr, big.NewInt(1000))
+	}
+	// the operator unbonds before the offending view, and the delegator unbonds after it.
+	for _, c := range []struct {
+		from  txpool.Address
+		tx    *txpool.Transaction
+		block *EVMBlock
+	}{
+		{operator, stakingTx(100, BondData(1)), blockSignedBy(1)},
+		{delegator, stakingTx(300, DelegateData(1)), blockSignedBy(1)},
+		{operator, stakingTx(0, UnbondData(1, big.NewInt(20))), blockSignedBy(3)},
+		{delegator, stakingTx(0, UnbondData(1, big.NewInt(100))), blockSignedBy(6)},
+	} {
+		if _, _, err := staking.call(c.tx, stateDB, c.block, c.from); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if staking.Slash(stateDB, 2, 5) {
+		t.Error("slashed a validator that does not exist")
+	}
+	if !staking.Slash(stateDB, 1, 5) {
+		t.Fatal("failed to slash validator 1")
+	}
+	if staking.Slash(stateDB, 1, 5) {
+		t.Error("slashed validator 1 twice for the same view")
+	}
+	if !staking.Jailed(stateDB, 1) {
+		t.Error("slashed validator is not jailed")
+	}
+	if got := staking.Stake(stateDB, 1); got.Int64() != 252 {
+		t.Errorf("Stake(1) = %v, want 252", got)
+	}
+	if got := staking.Delegation(stateDB, 1, delegator); got.Int64() != 180 {
+		t.Errorf("Delegation(1, delegator) = %v, want 180", got)
+	}
+	if got := staking.Unbonding(stateDB, delegator); got.Int64() != 90 {
+		t.Errorf("Unbonding(delegator) = %v, want 90", got)
+	}
+	if got := staking.Unbonding(stateDB, operator); got.Int64() != 20 {
+		t.Errorf("Unbonding(operator) = %v, want 20", got)
+	}
+	if got := stateDB.GetBalance(StakingAddress); got.Int64() != 362 {
+		t.Errorf("staking contract balance = %v, want 362", got)
+	}
+
+	// a jailed validator earns no rewards.
+	for number := uint64(1); number <= 10; number++ {
+		staking.endBlock(blockSignedBy(number, 1), stateDB)
+	}
+	if got := staking.Rewards(stateDB, operator); got.Sign() != 0 {
+		t.Errorf("Rewards(operator) = %v, want 0", got)
+	}
+
+	// an account that has claimed all of its unbonding entries is no longer slashed for them.
+	if _, _, err := staking.call(stakingTx(0, ClaimData()), stateDB, blockSignedBy(11), delegator); err != nil {
+		t.Fatal(err)
+	}
+	if got := staking.unbonders(stateDB, 1); len(got) != 1 || got[0] != operator {
+		t.Errorf("unbonders(1) = %v, want [%v]", got, operator)
+	}
+	if got := stateDB.GetBalance(delegator); got.Int64() != 700+90 {
+		t.Errorf("delegator balance after claiming unbonded stake = %v, want %v", got, 700+90)
+	}
+}
+
+func TestStakingLimits(t *testing.T) {
+	executor := NewExecutor(ExecutionConfig{
+		GasLimit: 8000000,
+		BaseFee:  big.NewInt(1000000000),
+		ChainID:  big.NewInt(1337),
+		Staking: &StakingConfig{
+			EpochLength:   10,
+			MaxValidators: 1,
+			MaxDelegators: 2,
+			MaxUnbondings: 1,
+		},
+	})
+	staking := executor.Staking()
+	stateDB := NewInMemoryStateDB()
+
+	operator := txpool.Address{1}
+	delegator := txpool.Address{2}
+	other := txpool.Address{3}
+	for _, addr := range []txpool.Address{operator, delegator, other} {
+		stateDB.CreateAccount(addr)
+		stateDB.SetBalance(addr, big.NewInt(1000))
+	}
+	call := func(from txpool.Address, tx *txpool.Transaction) (uint64, error) {
+		t.Helper()
+		gas, _, err := staking.call(tx, stateDB, blockSignedBy(1), from)
+		return gas, err
+	}
+
+	if _, err := call(operator, stakingTx(100, BondData(1))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := call(other, stakingTx(100, BondData(2))); err == nil {
+		t.Error("registered more validators than the limit")
+	}
+	if _, err := call(delegator, stakingTx(100, DelegateData(1))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := call(other, stakingTx(100, DelegateData(1))); err == nil {
+		t.Error("delegated to a validator with the maximum number of delegators")
+	}
+
+	// a delegator that unbonds all of its stake makes room for another delegator.
+	if _, err := call(delegator, stakingTx(0, UnbondData(1, big.NewInt(100)))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := call(other, stakingTx(100, DelegateData(1))); err != nil {
+		t.Errorf("failed to delegate after a delegator left: %v", err)
+	}
+	if got := staking.delegators(stateDB, 1); len(got) != 2 || got[0] != operator || got[1] != other {
+		t.Errorf("delegators(1) = %v, want [%v %v]", got, operator, other)
+	}
+	if staking.isDelegator(stateDB, 1, delegator) {
+		t.Error("an account without stake is still a delegator")
+	}
+
+	if _, err := call(other, stakingTx(0, UnbondData(1, big.NewInt(10)))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := call(other, stakingTx(0, UnbondData(1, big.NewInt(10)))); err == nil {
+		t.Error("added more unbonding entries than the limit")
+	}
+	if _, err := call(operator, stakingTx(0, UnbondData(1, big.NewInt(10)))); err == nil {
+		t.Error("unbonded from a validator with the maximum number of unbonding accounts")
+	}
+
+	// a claim pays for the validators and the unbonding entries that it iterates over.
+	gas, err := call(other, stakingTx(0, ClaimData()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := uint64(stakingCallGas + 2*stakingEntryGas); gas != want {
+		t.Errorf("claim used %d gas, want %d", gas, want)
+	}
+}
@@ -1,7 +1,6 @@
 package evm
 
 import (
-	"fmt"
 	"math/big"
 
 	"github.com/relab/hotstuff"
@@ -371,17 +370,31 @@ func (s *InMemoryStateDB) Copy() StateDB {
 
 // Genesis account setup for testing
 func (s *InMemoryStateDB) SetupGenesisAccounts() {
-	// Create some test accounts with initial balances
-	testAccounts := map[string]*big.Int{
+	testGenesisAlloc().Apply(s)
+}
+
+// GenesisAlloc is the initial balance of each account in the genesis state.
+type GenesisAlloc map[txpool.Address]*big.Int
+
+// Apply creates the accounts with their initial balances in the state.
+// The state is not committed.
+func (alloc GenesisAlloc) Apply(s StateDB) {
+	for addr, balance := range alloc {
+		s.CreateAccount(addr)
+		s.SetBalance(addr, new(big.Int).Set(balance))
+	}
+}
+
+// testGenesisAlloc returns some test accounts with initial balances.
+func testGenesisAlloc() GenesisAlloc {
+	alloc := make(GenesisAlloc)
+	for addrStr, balance := range map[string]*big.Int{
 		"0x1000000000000000000000000000000000000001": big.NewInt(1000000000000000000), // 1 ETH
 		"0x1000000000000000000000000000000000000002": big.NewInt(2000000000000000000), // 2 ETH
 		"0x1000000000000000000000000000000000000003": big.NewInt(5000000000000000000), // 5 ETH
+	} {
+		addr, _ := txpool.ParseAddress(addrStr)
+		alloc[addr] = balance
 	}
-
-	for addrStr, balance := range testAccounts {
-		var addr txpool.Address
-		fmt.Sscanf(addrStr, "0x%40x", &addr)
-		s.CreateAccount(addr)
-		s.SetBalance(addr, balance)
-	}
+	return alloc
 }
@@ -444,18 +444,21 @@ func (s *TrieStateDB) Commit() (hotstuff.Hash, error) {
 
 // commitTrie commits a trie to the database
 func (s *TrieStateDB) commitTrie(t *trie.MerklePatriciaTrie) error {
-	// This is a simplified commit - in a full implementation,
-	// we would traverse the trie and store all dirty nodes
-
-	root := t.Root()
-	if root == (hotstuff.Hash{}) {
+	if s.db == nil || t.Root() == (hotstuff.Hash{}) {
 		return nil
 	}
+	return t.Commit(s.db)
+}
 
-	// For now, we'll use a simplified approach where the trie
-	// manages its own persistence through the database interface
-
-	return nil
+// AccountStorageRoot returns the storage root referenced by an encoded account in the state trie.
+// It can be used as the leaf callback when syncing the state trie, such that the storage tries are synced as well.
+// Values that are not accounts, such as contract code, do not reference any storage trie.
+func AccountStorageRoot(value []byte) []hotstuff.Hash {
+	var account AccountRLP
+	if err := json.Unmarshal(value, &account); err != nil || account.StorageRoot == (hotstuff.Hash{}) {
+		return nil
+	}
+	return []hotstuff.Hash{account.StorageRoot}
 }
 
 // Copy creates a deep copy of the state database
@@ -543,19 +546,8 @@ func (s TrieStateStats) String() string {
 
 // SetupGenesisAccounts creates initial accounts for testing
 func (s *TrieStateDB) SetupGenesisAccounts() {
-	// Create some test accounts with initial balances
-	testAccounts := map[string]*big.Int{
-		"0x1000000000000000000000000000000000000001": big.NewInt(1000000000000000000), // 1 ETH
-		"0x1000000000000000000000000000000000000002": big.NewInt(2000000000000000000), // 2 ETH
-		"0x1000000000000000000000000000000000000003": big.NewInt(5000000000000000000), // 5 ETH
-	}
-
-	for addrStr, balance := range testAccounts {
-		var addr txpool.Address
-		fmt.Sscanf(addrStr, "0x%40x", &addr)
-		s.CreateAccount(addr)
-		s.SetBalance(addr, balance)
-	}
+	alloc := testGenesisAlloc()
+	alloc.Apply(s)
 
-	s.logger.Infof("Created %d genesis accounts", len(testAccounts))
+	s.logger.Infof("Created %d genesis accounts", len(alloc))
 }
@@ -1,5 +1,7 @@
 package hotstuff
 
+import "math/bits"
+
 // NumFaulty calculates 'f', which is the number of replicas that can be faulty for a configuration of size 'n'.
 func NumFaulty(n int) int {
 	return (n - 1) / 3
@@ -9,3 +11,31 @@ func NumFaulty(n int) int {
 func QuorumSize(n int) int {
 	return n - NumFaulty(n)
 }
+
+// HasQuorumPower returns true if 'power' is more than two thirds of 'total'.
+// This is the quorum condition for stake-weighted quorums, and is equivalent to
+// QuorumSize when every replica has the same voting power.
+// The products are computed with 128 bits, such that they cannot overflow.
+func HasQuorumPower(power, total uint64) bool {
+	hi1, lo1 := bits.Mul64(power, 3)
+	hi2, lo2 := bits.Mul64(total, 2)
+	return hi1 > hi2 || (hi1 == hi2 && lo1 > lo2)
+}
+
+// AddPower returns the sum of two voting powers.
+// The sum saturates at the largest uint64 instead of overflowing.
+func AddPower(a, b uint64) uint64 {
+	sum, carry := bits.Add64(a, b, 0)
+	if carry != 0 {
+		return ^uint64(0)
+	}
+	return sum
+}
+
+// SetPower returns the sum of the voting power of the IDs in the set.
+func SetPower(set IDSet, power func(ID) uint64) (sum uint64) {
+	set.ForEach(func(id ID) {
+		sum = AddPower(sum, power(id))
+	})
+	return sum
+}
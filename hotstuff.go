@@ -1,7 +1,12 @@
 // Package hotstuff implements the basic types that are used by hotstuff.
 package hotstuff
 
-import "encoding/binary"
+import (
+	"encoding/binary"
+	"net/url"
+	"strconv"
+	"strings"
+)
 
 // ID uniquely identifies a replica
 type ID uint32
@@ -12,3 +17,29 @@ func (id ID) ToBytes() []byte {
 	binary.LittleEndian.PutUint32(idBytes[:], uint32(id))
 	return idBytes[:]
 }
+
+// idURIScheme is the scheme of the URIs that identify replicas in the subject alternative names of TLS certificates.
+const idURIScheme = "hotstuff"
+
+// URI returns the URI that identifies the replica in the subject alternative names of its TLS certificate,
+// such as hotstuff:replica:1.
+func (id ID) URI() *url.URL {
+	return &url.URL{Scheme: idURIScheme, Opaque: "replica:" + strconv.FormatUint(uint64(id), 10)}
+}
+
+// IDFromURI returns the ID of the replica that is identified by the URI.
+// It returns false if the URI does not identify a replica.
+func IDFromURI(uri *url.URL) (ID, bool) {
+	if uri.Scheme != idURIScheme {
+		return 0, false
+	}
+	s, ok := strings.CutPrefix(uri.Opaque, "replica:")
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(s, 10, 32)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return ID(id), true
+}
@@ -31,6 +31,15 @@ func init() {
 	runCmd.Flags().Int("replicas", 4, "number of replicas to run")
 	runCmd.Flags().Int("clients", 1, "number of clients to run")
 	runCmd.Flags().Int("batch-size", 1, "number of commands to batch together in each block")
+	runCmd.Flags().Int("max-batch-bytes", 0, "maximum size in bytes of the commands in each block (0 means no limit)")
+	runCmd.Flags().Duration("max-batch-delay", 100*time.Millisecond, "maximum time to wait for a full batch before proposing a smaller batch (0 means no limit)")
+	runCmd.Flags().Bool("adaptive-batching", false, "adjust the batch size based on the observed commit latency")
+	runCmd.Flags().Int("client-window", 1024, "number of sequence numbers a client may have in flight beyond its committed commands")
+	runCmd.Flags().IntSlice("voting-power", nil, "voting power of each replica, in order of ID (replicas without an entry, or with zero, have one vote)")
+	runCmd.Flags().Bool("evidence", false, "detect replicas that sign conflicting blocks and include the evidence in proposed blocks")
+	runCmd.Flags().Bool("fair-ordering", false, "derive the order of commands in a block from its quorum certificate instead of letting the leader choose (requires --crypto bls12-threshold)")
+	runCmd.Flags().Bool("encrypted-mempool", false, "encrypt commands to a committee key and decrypt them only after they are committed")
+	runCmd.Flags().Bool("mempool", false, "disseminate commands in certified batches and let consensus order only the certificates")
 	runCmd.Flags().Int("payload-size", 0, "size in bytes of the command payload")
 	runCmd.Flags().Int("max-concurrent", 4, "maximum number of concurrent commands per client")
 	runCmd.Flags().Duration("client-timeout", 500*time.Millisecond, "Client timeout.")
@@ -76,6 +85,8 @@ func init() {
 	// Persistent storage flags
 	runCmd.Flags().Bool("persistent", false, "enable persistent storage using BadgerDB")
 	runCmd.Flags().String("data-dir", "./hotstuff_data", "directory for persistent storage data")
+	runCmd.Flags().Uint64("checkpoint-interval", 0, "number of views between persisted checkpoints (0 disables checkpoints)")
+	runCmd.Flags().String("retention", "all", "what to do with blocks below the latest checkpoint (all, archive, or delete); blocks are only pruned if the replica runs a state machine")
 	
 	// RPC flags
 	runCmd.Flags().Bool("rpc", false, "enable JSON-RPC server for Ethereum compatibility")
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/relab/hotstuff/internal/node"
+	"github.com/spf13/cobra"
+)
+
+var nodeHome string
+
+// nodeCmd represents the node command
+var nodeCmd = &cobra.Command{
+	Use:   "node",
+	Short: "Run a replica from a configuration directory.",
+	Long: `Starts a replica that loads its ID, keys, and peers from a configuration directory,
+and the validators and initial balances from the genesis file in the same directory.
+The replica runs until it receives an interrupt or terminate signal,
+and it continues from its stored state when it is started again.`,
+	Run: func(_ *cobra.Command, _ []string) {
+		runNode()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(nodeCmd)
+
+	nodeCmd.Flags().StringVar(&nodeHome, "home", ".", "the configuration directory of the node")
+}
+
+func runNode() {
+	n, err := node.New(nodeHome)
+	checkf("failed to create node: %v", err)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err = n.Run(ctx)
+	checkf("node failed: %v", err)
+}
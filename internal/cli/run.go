@@ -78,7 +78,12 @@ func runController() {
 	}
 
 	if cfg.Worker || len(hosts) == 0 {
-		worker, wait := localWorker(cfg.Output, cfg.Metrics, cfg.MeasurementInterval, cfg.Persistent, cfg.DataDir, cfg.RPC, cfg.RPCAddr, cfg.RPCCORS)
+		worker, wait := localWorker(cfg.Output, cfg.Metrics, cfg.MeasurementInterval, orchestration.PersistentWorkerConfig{
+			DataDir:            cfg.DataDir,
+			UsePersistent:      cfg.Persistent,
+			CheckpointInterval: cfg.CheckpointInterval,
+			Retention:          cfg.Retention,
+		}, cfg.RPC, cfg.RPCAddr, cfg.RPCCORS)
 		defer wait()
 		remoteWorkers["localhost"] = worker
 	}
@@ -117,7 +122,7 @@ func checkf(format string, args ...any) {
 	}
 }
 
-func localWorker(globalOutput string, enableMetrics []string, interval time.Duration, persistent bool, dataDir string, rpcEnabled bool, rpcAddr string, rpcCors bool) (worker orchestration.RemoteWorker, wait func()) {
+func localWorker(globalOutput string, enableMetrics []string, interval time.Duration, persistence orchestration.PersistentWorkerConfig, rpcEnabled bool, rpcAddr string, rpcCors bool) (worker orchestration.RemoteWorker, wait func()) {
 	// set up an output dir
 	output := ""
 	if globalOutput != "" {
@@ -186,6 +191,8 @@ func localWorker(globalOutput string, enableMetrics []string, interval time.Dura
 			// Create RPC service that interfaces with the blockchain
 			rpcService := rpc.NewSimpleRPCServiceWithBlockchain(stateDB, executor, txPool, l1Blockchain)
 			handler := rpc.NewHandler(rpcService)
+			// eth_syncing reports the progress of the block sync of the replicas that the worker runs.
+			handler.SetSyncProgress(&baseWorker)
 			rpcServer = rpc.NewServer(handler, rpcAddr)
 
 			// Start RPC server
@@ -198,16 +205,12 @@ func localWorker(globalOutput string, enableMetrics []string, interval time.Dura
 		}
 
 		var err error
-		if persistent {
+		if persistence.UsePersistent {
 			// Create data directory for persistent storage
-			if err := os.MkdirAll(dataDir, 0755); err != nil {
-				log.Fatalf("Failed to create data directory %s: %v", dataDir, err)
+			if err := os.MkdirAll(persistence.DataDir, 0755); err != nil {
+				log.Fatalf("Failed to create data directory %s: %v", persistence.DataDir, err)
 			}
-			config := orchestration.PersistentWorkerConfig{
-				DataDir:       dataDir,
-				UsePersistent: true,
-			}
-			persistentWorker := orchestration.NewPersistentWorker(config, baseWorker)
+			persistentWorker := orchestration.NewPersistentWorker(persistence, baseWorker)
 			err = persistentWorker.Run()
 		} else {
 			err = baseWorker.Run()
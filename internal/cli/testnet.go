@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/relab/hotstuff/crypto/keystore"
+	"github.com/relab/hotstuff/internal/node"
+	"github.com/spf13/cobra"
+)
+
+var (
+	testnetOutput     string
+	testnetValidators int
+	testnetHosts      []string
+	testnetBasePort   int
+	testnetTLS        bool
+	testnetDocker     bool
+	testnetAccounts   []string
+	testnetPassFile   string
+	testnetGenesis    node.Genesis
+)
+
+// testnetCmd represents the testnet command
+var testnetCmd = &cobra.Command{
+	Use:   "testnet",
+	Short: "Manage configurations for testnets of nodes.",
+}
+
+// testnetInitCmd represents the testnet init command
+var testnetInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Generate the configuration directories of a testnet.",
+	Long: `Generates a certificate authority, the keys and TLS certificates of the validators,
+a shared genesis file, and a configuration directory for each validator.
+Each validator can then be started with 'hotstuff node --home <output>/node<id>'.
+
+By default, the validators run on the local host with distinct ports.
+With --docker, the validators run in Docker Compose, using the services in <output>/docker-compose.yml
+together with scripts/docker-compose.yml.
+
+With --passphrase-file, the private keys of the validators are encrypted with the passphrase in the file.
+The validators then ask for the passphrase at startup, unless 'passphrase_file' is set in their config.json.`,
+	Run: func(_ *cobra.Command, _ []string) {
+		runTestnetInit()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(testnetCmd)
+	testnetCmd.AddCommand(testnetInitCmd)
+
+	testnetInitCmd.Flags().StringVarP(&testnetOutput, "output", "o", "testnet", "the directory to write the configurations to")
+	testnetInitCmd.Flags().IntVar(&testnetValidators, "validators", 4, "the number of validators")
+	testnetInitCmd.Flags().StringSliceVar(&testnetHosts, "hosts", nil, "the hosts to run the validators on (default 127.0.0.1)")
+	testnetInitCmd.Flags().IntVar(&testnetBasePort, "base-port", 30000, "the replica port of the first validator")
+	testnetInitCmd.Flags().BoolVar(&testnetTLS, "tls", true, "use TLS for the connections between replicas")
+	testnetInitCmd.Flags().BoolVar(&testnetDocker, "docker", false, "generate a Docker Compose file that runs the validators")
+	testnetInitCmd.Flags().StringSliceVar(&testnetAccounts, "accounts", nil, "the accounts to fund in the genesis file, as address[=balance in wei]")
+	testnetInitCmd.Flags().StringVar(&testnetPassFile, "passphrase-file", "", "encrypt the private keys with the passphrase in this file")
+
+	testnetInitCmd.Flags().StringVar(&testnetGenesis.Crypto, "crypto", "ecdsa", "name of the crypto implementation to use (ecdsa, eddsa, bls12, bls12-threshold)")
+	testnetInitCmd.Flags().StringVar(&testnetGenesis.Consensus, "consensus", "chainedhotstuff", "name of the consensus implementation to use")
+	testnetInitCmd.Flags().StringVar(&testnetGenesis.LeaderRotation, "leader-rotation", "round-robin", "name of the leader rotation algorithm to use")
+	testnetInitCmd.Flags().Uint64Var(&testnetGenesis.ChainID, "chain-id", 1337, "the chain ID of the EVM")
+}
+
+func runTestnetInit() {
+	if testnetDocker {
+		if len(testnetHosts) > 0 {
+			log.Fatalln("--hosts cannot be used together with --docker")
+		}
+		testnetHosts = node.DockerHosts(testnetValidators)
+	}
+	accounts, err := node.ParseAccounts(testnetAccounts)
+	checkf("failed to parse accounts: %v", err)
+	testnetGenesis.Accounts = accounts
+	var passphrase []byte
+	if testnetPassFile != "" {
+		passphrase, err = keystore.PassphraseFile(testnetPassFile)()
+		checkf("failed to read passphrase: %v", err)
+	}
+
+	dirs, err := node.InitTestnet(testnetOutput, node.TestnetOptions{
+		Validators: testnetValidators,
+		Hosts:      testnetHosts,
+		BasePort:   testnetBasePort,
+		TLS:        testnetTLS,
+		Passphrase: passphrase,
+		Genesis:    testnetGenesis,
+	})
+	checkf("failed to create testnet: %v", err)
+
+	if testnetDocker {
+		err = node.WriteComposeFile(testnetOutput, testnetValidators)
+		checkf("failed to write docker compose file: %v", err)
+	}
+	for _, dir := range dirs {
+		fmt.Println(dir)
+	}
+}
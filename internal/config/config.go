@@ -74,6 +74,10 @@ type ExperimentConfig struct {
 	Persistent bool
 	// DataDir is the directory for persistent storage data.
 	DataDir string
+	// CheckpointInterval is the number of views between persisted checkpoints. Zero disables checkpoints.
+	CheckpointInterval uint64
+	// Retention is what happens to the blocks below the latest checkpoint: all, archive or delete.
+	Retention string
 
 	// # RPC configuration below:
 
@@ -108,6 +112,27 @@ type ExperimentConfig struct {
 	RateStep float64
 	// The number of client commands that should be batched together.
 	BatchSize uint32
+	// MaxBatchBytes is the maximum size of a batch in bytes.
+	MaxBatchBytes uint32
+	// MaxBatchDelay is the maximum time to wait for a full batch before proposing a smaller batch.
+	MaxBatchDelay time.Duration
+	// AdaptiveBatching adjusts the effective batch size based on the observed commit latency.
+	AdaptiveBatching bool
+	// ClientWindow is the number of sequence numbers that a client may have in flight beyond its committed commands.
+	ClientWindow uint64
+	// VotingPower is the voting power of each replica, in order of ID.
+	// Replicas without an entry have one vote.
+	VotingPower []uint64
+	// Evidence makes the replicas detect conflicting signed blocks and propose the resulting evidence.
+	Evidence bool
+	// FairOrdering derives the order of commands in a batch from the quorum certificate of the block.
+	FairOrdering bool
+	// EncryptedMempool makes clients encrypt their commands to a committee key that is shared among the replicas.
+	// The commands are decrypted after they have been committed.
+	EncryptedMempool bool
+	// Mempool makes the replicas disseminate commands in batches that are certified by a quorum of replicas.
+	// Consensus then orders only the certificates of the batches.
+	Mempool bool
 
 	// # Other values:
 
@@ -247,6 +272,14 @@ func (c *ExperimentConfig) CreateReplicaOpts() *orchestrationpb.ReplicaOpts {
 	return &orchestrationpb.ReplicaOpts{
 		UseTLS:            c.UseTLS,
 		BatchSize:         c.BatchSize,
+		MaxBatchBytes:     c.MaxBatchBytes,
+		MaxBatchDelay:     durationpb.New(c.MaxBatchDelay),
+		AdaptiveBatching:  c.AdaptiveBatching,
+		ClientWindow:      c.ClientWindow,
+		FairOrdering:      c.FairOrdering,
+		VotingPower:       c.VotingPower,
+		Evidence:          c.Evidence,
+		Mempool:           c.Mempool,
 		TimeoutMultiplier: float32(c.TimeoutMultiplier),
 		Consensus:         c.Consensus,
 		Crypto:            c.Crypto,
@@ -28,6 +28,15 @@ func NewViper() (*ExperimentConfig, error) {
 		treePos[i] = uint32(pos)
 	}
 
+	intVotingPower := viper.GetIntSlice("voting-power")
+	votingPower := make([]uint64, len(intVotingPower))
+	for i, power := range intVotingPower {
+		if power < 0 {
+			return nil, fmt.Errorf("invalid voting power for replica %d: %d", i+1, power)
+		}
+		votingPower[i] = uint64(power)
+	}
+
 	cfg := &ExperimentConfig{
 		Duration:            viper.GetDuration("duration"),
 		Replicas:            viper.GetInt("replicas"),
@@ -50,6 +59,15 @@ func NewViper() (*ExperimentConfig, error) {
 		Metrics:             viper.GetStringSlice("metrics"),
 		MeasurementInterval: viper.GetDuration("measurement-interval"),
 		BatchSize:           viper.GetUint32("batch-size"),
+		MaxBatchBytes:       viper.GetUint32("max-batch-bytes"),
+		MaxBatchDelay:       viper.GetDuration("max-batch-delay"),
+		AdaptiveBatching:    viper.GetBool("adaptive-batching"),
+		ClientWindow:        viper.GetUint64("client-window"),
+		FairOrdering:        viper.GetBool("fair-ordering"),
+		VotingPower:         votingPower,
+		Evidence:            viper.GetBool("evidence"),
+		EncryptedMempool:    viper.GetBool("encrypted-mempool"),
+		Mempool:             viper.GetBool("mempool"),
 		TimeoutMultiplier:   viper.GetFloat64("timeout-multiplier"),
 		Consensus:           viper.GetString("consensus"),
 		Crypto:              viper.GetString("crypto"),
@@ -57,6 +75,8 @@ func NewViper() (*ExperimentConfig, error) {
 		ConnectTimeout:      viper.GetDuration("connect-timeout"),
 		Persistent:          viper.GetBool("persistent"),
 		DataDir:             viper.GetString("data-dir"),
+		CheckpointInterval:  viper.GetUint64("checkpoint-interval"),
+		Retention:           viper.GetString("retention"),
 		ViewTimeout:         viper.GetDuration("view-timeout"),
 		DurationSamples:     viper.GetUint32("duration-samples"),
 		MaxTimeout:          viper.GetDuration("max-timeout"),
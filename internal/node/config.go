@@ -0,0 +1,370 @@
+// Package node runs a long-lived replica whose identity, keys, and peers are loaded from a configuration directory.
+//
+// The configuration directory contains the following files:
+//
+//	config.json   the node configuration (see Config)
+//	genesis.json  the genesis file that is shared by all validators (see Genesis)
+//	priv.key      the private key of the replica, which may be encrypted (not needed if a remote signer is used)
+//	tls.crt       the TLS certificate of the replica (only if TLS is enabled)
+//	tls.key       the private key of the TLS certificate (only if TLS is enabled)
+//	ca.crt        the certificate authority that signed the TLS certificates (only if TLS is enabled)
+//	signing.key   the share of the group key for threshold signatures (only with the bls12-threshold crypto)
+//
+// The TLS files, and the revocation list and deny list if they are configured,
+// are reloaded when they change, such that certificates can be rotated without restarting the node.
+//
+// The blocks, the consensus state, and the EVM state are stored in the data directory,
+// such that the node continues where it left off after a restart.
+package node
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/crypto/bls12"
+	"github.com/relab/hotstuff/crypto/keygen"
+	"github.com/relab/hotstuff/epoch"
+	"github.com/relab/hotstuff/evm"
+	"github.com/relab/hotstuff/txpool"
+)
+
+// The names of the files in the configuration directory.
+const (
+	ConfigFile         = "config.json"
+	GenesisFile        = "genesis.json"
+	PrivateKeyFile     = "priv.key"
+	CertificateFile    = "tls.crt"
+	CertificateKeyFile = "tls.key"
+	CAFile             = "ca.crt"
+	SigningKeyFile     = "signing.key"
+)
+
+// Duration is a time.Duration that is encoded as a string, such as "500ms", in JSON.
+type Duration time.Duration
+
+// MarshalJSON encodes the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON decodes a duration from a string.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Peer is the address of another replica.
+type Peer struct {
+	ID      hotstuff.ID `json:"id"`
+	Address string      `json:"address"`
+}
+
+// Config is the configuration of a node.
+type Config struct {
+	// The ID of the replica. It must be the ID of one of the validators in the genesis file.
+	ID hotstuff.ID `json:"id"`
+	// The address that the replica listens on for connections from the other replicas.
+	ListenAddress string `json:"listen_address"`
+	// The address that the replica listens on for connections from clients.
+	ClientAddress string `json:"client_address"`
+	// The directory that the blocks and the state are stored in.
+	// A relative path is relative to the configuration directory.
+	DataDir string `json:"data_dir"`
+	// Controls whether TLS is used for the connections between replicas and from clients.
+	TLS bool `json:"tls"`
+	// The addresses of the replicas, including this one.
+	Peers []Peer `json:"peers"`
+	// The address of a remote signer that holds the private key of the replica (see cmd/hotstuff-signer).
+	// If it is empty, the replica signs with the private key in the configuration directory.
+	// If TLS is enabled, the replica authenticates to the signer with its TLS certificate.
+	Signer string `json:"signer,omitempty"`
+	// The file that contains the passphrase of the private key, if the private key is encrypted.
+	// If it is empty, the passphrase is read from the terminal.
+	// A relative path is relative to the configuration directory.
+	PassphraseFile string `json:"passphrase_file,omitempty"`
+	// The file that contains the certificate revocation lists of the certificate authority, if any.
+	// A relative path is relative to the configuration directory.
+	CRLFile string `json:"crl_file,omitempty"`
+	// The file that lists the replica IDs and certificate fingerprints that are denied, if any.
+	// A relative path is relative to the configuration directory.
+	DenyListFile string `json:"deny_list_file,omitempty"`
+
+	BatchSize          uint32   `json:"batch_size"`
+	MaxBatchBytes      uint32   `json:"max_batch_bytes,omitempty"`
+	MaxBatchDelay      Duration `json:"max_batch_delay"`
+	ClientWindow       uint64   `json:"client_window,omitempty"`
+	ViewTimeout        Duration `json:"view_timeout"`
+	MaxTimeout         Duration `json:"max_timeout,omitempty"`
+	TimeoutSamples     uint64   `json:"timeout_samples"`
+	TimeoutMultiplier  float64  `json:"timeout_multiplier"`
+	ConnectTimeout     Duration `json:"connect_timeout"`
+	CheckpointInterval uint64   `json:"checkpoint_interval,omitempty"`
+	Retention          string   `json:"retention,omitempty"`
+	Mempool            bool     `json:"mempool,omitempty"`
+}
+
+// DefaultConfig returns the configuration of the replica with the given ID, with default values for the other fields.
+func DefaultConfig(id hotstuff.ID) Config {
+	return Config{
+		ID:                id,
+		ListenAddress:     ":30000",
+		ClientAddress:     ":40000",
+		DataDir:           "data",
+		BatchSize:         100,
+		MaxBatchDelay:     Duration(100 * time.Millisecond),
+		ViewTimeout:       Duration(500 * time.Millisecond),
+		TimeoutSamples:    1000,
+		TimeoutMultiplier: 1.2,
+		ConnectTimeout:    Duration(5 * time.Second),
+		Retention:         "all",
+	}
+}
+
+// LoadConfig reads the node configuration from the configuration directory.
+// Fields that are missing from the file have their default values.
+func LoadConfig(dir string) (*Config, error) {
+	b, err := os.ReadFile(filepath.Join(dir, ConfigFile))
+	if err != nil {
+		return nil, err
+	}
+	cfg := DefaultConfig(0)
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ConfigFile, err)
+	}
+	if cfg.ID == 0 {
+		return nil, fmt.Errorf("%s: missing replica id", ConfigFile)
+	}
+	if !filepath.IsAbs(cfg.DataDir) {
+		cfg.DataDir = filepath.Join(dir, cfg.DataDir)
+	}
+	if cfg.PassphraseFile != "" && !filepath.IsAbs(cfg.PassphraseFile) {
+		cfg.PassphraseFile = filepath.Join(dir, cfg.PassphraseFile)
+	}
+	if cfg.CRLFile != "" && !filepath.IsAbs(cfg.CRLFile) {
+		cfg.CRLFile = filepath.Join(dir, cfg.CRLFile)
+	}
+	if cfg.DenyListFile != "" && !filepath.IsAbs(cfg.DenyListFile) {
+		cfg.DenyListFile = filepath.Join(dir, cfg.DenyListFile)
+	}
+	return &cfg, nil
+}
+
+// Validator is a replica that participates in consensus from genesis.
+type Validator struct {
+	ID hotstuff.ID `json:"id"`
+	// The public key of the replica in PEM format.
+	PublicKey string `json:"public_key"`
+	// The voting power of the replica. Zero means one vote.
+	Power uint64 `json:"power,omitempty"`
+}
+
+// Genesis describes the initial state of the chain. All validators must use the same genesis file.
+type Genesis struct {
+	ChainID        uint64 `json:"chain_id"`
+	Consensus      string `json:"consensus"`
+	Crypto         string `json:"crypto"`
+	LeaderRotation string `json:"leader_rotation"`
+	// The seed that is shared by the replicas, for leader rotations that choose leaders at random.
+	Seed     int64    `json:"seed"`
+	GasLimit uint64   `json:"gas_limit"`
+	BaseFee  *big.Int `json:"base_fee"`
+	// The validators and their public keys.
+	Validators []Validator `json:"validators"`
+	// The group public key for threshold signatures in PEM format. It is required by the bls12-threshold crypto,
+	// and it is the only key that is needed to verify the quorum certificates.
+	GroupPublicKey string `json:"group_public_key,omitempty"`
+	// The initial balance of each account, keyed by the hex address of the account.
+	Accounts map[string]*big.Int `json:"accounts"`
+	// The configuration of the staking system contract. Staking is disabled if it is missing.
+	Staking *evm.StakingConfig `json:"staking,omitempty"`
+	// The configuration of reconfiguration transactions. The validator set is fixed if it is missing.
+	Reconfiguration *Reconfiguration `json:"reconfiguration,omitempty"`
+}
+
+// Reconfiguration allows the validator set to be changed by committed reconfiguration transactions (see package epoch).
+type Reconfiguration struct {
+	// The number of views in an epoch. Zero means the default length.
+	EpochLength uint64 `json:"epoch_length,omitempty"`
+	// The ed25519 public key in PEM format that signs the reconfiguration transactions.
+	Authority string `json:"authority"`
+}
+
+// LoadGenesis reads and validates the genesis file.
+// Fields that are missing from the file have their default values.
+func LoadGenesis(path string) (*Genesis, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	genesis := &Genesis{}
+	if err := json.Unmarshal(b, genesis); err != nil {
+		return nil, fmt.Errorf("failed to parse genesis file: %w", err)
+	}
+	if genesis.Consensus == "" {
+		genesis.Consensus = "chainedhotstuff"
+	}
+	if genesis.Crypto == "" {
+		genesis.Crypto = "ecdsa"
+	}
+	if genesis.LeaderRotation == "" {
+		genesis.LeaderRotation = "round-robin"
+	}
+	if genesis.GasLimit == 0 {
+		genesis.GasLimit = 8000000
+	}
+	if genesis.BaseFee == nil {
+		genesis.BaseFee = big.NewInt(1000000000)
+	}
+	if len(genesis.Validators) == 0 {
+		return nil, fmt.Errorf("genesis file has no validators")
+	}
+	seen := make(map[hotstuff.ID]bool)
+	for _, v := range genesis.Validators {
+		if v.ID == 0 || seen[v.ID] {
+			return nil, fmt.Errorf("genesis file has an invalid or duplicate validator id: %d", v.ID)
+		}
+		seen[v.ID] = true
+		if _, err := keygen.ParsePublicKey([]byte(v.PublicKey)); err != nil {
+			return nil, fmt.Errorf("invalid public key of validator %d: %w", v.ID, err)
+		}
+	}
+	if _, err := genesis.Alloc(); err != nil {
+		return nil, err
+	}
+	if s := genesis.Staking; s != nil {
+		if s.BlockReward != nil && s.BlockReward.Sign() < 0 {
+			return nil, fmt.Errorf("genesis file: negative block reward")
+		}
+		if s.SlashPercent > 100 {
+			return nil, fmt.Errorf("genesis file: slash percent %d is greater than 100", s.SlashPercent)
+		}
+	}
+	if genesis.Reconfiguration != nil {
+		if _, err := genesis.EpochManager(); err != nil {
+			return nil, err
+		}
+		if genesis.VotingPower() != nil {
+			return nil, fmt.Errorf("genesis file: voting power cannot be used with reconfiguration")
+		}
+		if genesis.Crypto == "bls12-threshold" {
+			return nil, fmt.Errorf("genesis file: threshold signatures cannot be used with reconfiguration")
+		}
+	}
+	if genesis.Crypto == "bls12-threshold" {
+		if _, err := genesis.ThresholdPublicKey(); err != nil {
+			return nil, err
+		}
+		if genesis.VotingPower() != nil {
+			return nil, fmt.Errorf("genesis file: threshold signatures cannot be used with voting power")
+		}
+	}
+	return genesis, nil
+}
+
+// ThresholdPublicKey returns the group public key for threshold signatures.
+// The key must be shared by the validators, and its threshold must be the quorum size.
+func (g *Genesis) ThresholdPublicKey() (*bls12.ThresholdPublicKey, error) {
+	pub, err := keygen.ParseThresholdPublicKey([]byte(g.GroupPublicKey))
+	if err != nil {
+		return nil, fmt.Errorf("genesis file: invalid group public key: %w", err)
+	}
+	participants := pub.Participants()
+	if len(participants) != len(g.Validators) {
+		return nil, fmt.Errorf("genesis file: group public key has %d participants, expected %d", len(participants), len(g.Validators))
+	}
+	for _, id := range participants {
+		if _, ok := g.Validator(id); !ok {
+			return nil, fmt.Errorf("genesis file: replica %d in the group public key is not a validator", id)
+		}
+	}
+	if want := hotstuff.QuorumSize(len(g.Validators)); pub.Threshold() != want {
+		return nil, fmt.Errorf("genesis file: group public key has threshold %d, expected the quorum size %d", pub.Threshold(), want)
+	}
+	return pub, nil
+}
+
+// EpochManager returns the module that applies the reconfiguration transactions,
+// or nil if the validator set cannot be changed.
+func (g *Genesis) EpochManager() (*epoch.Manager, error) {
+	if g.Reconfiguration == nil {
+		return nil, nil
+	}
+	key, err := keygen.ParsePublicKey([]byte(g.Reconfiguration.Authority))
+	if err != nil {
+		return nil, fmt.Errorf("genesis file: invalid reconfiguration authority: %w", err)
+	}
+	authority, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("genesis file: the reconfiguration authority must be an ed25519 key, not %T", key)
+	}
+	return epoch.New(hotstuff.View(g.Reconfiguration.EpochLength), authority), nil
+}
+
+// Validator returns the validator with the given ID.
+func (g *Genesis) Validator(id hotstuff.ID) (Validator, bool) {
+	for _, v := range g.Validators {
+		if v.ID == id {
+			return v, true
+		}
+	}
+	return Validator{}, false
+}
+
+// VotingPower returns the voting power of the validators, or nil if every validator has one vote.
+func (g *Genesis) VotingPower() map[hotstuff.ID]uint64 {
+	var power map[hotstuff.ID]uint64
+	for _, v := range g.Validators {
+		if v.Power == 0 {
+			continue
+		}
+		if power == nil {
+			power = make(map[hotstuff.ID]uint64)
+		}
+		power[v.ID] = v.Power
+	}
+	return power
+}
+
+// Alloc returns the initial balances of the accounts.
+func (g *Genesis) Alloc() (evm.GenesisAlloc, error) {
+	alloc := make(evm.GenesisAlloc, len(g.Accounts))
+	for s, balance := range g.Accounts {
+		addr, err := txpool.ParseAddress(s)
+		if err != nil {
+			return nil, fmt.Errorf("genesis file: %w", err)
+		}
+		if balance == nil || balance.Sign() < 0 {
+			return nil, fmt.Errorf("genesis file: invalid balance of account %s", s)
+		}
+		alloc[addr] = balance
+	}
+	return alloc, nil
+}
+
+// ExecutionConfig returns the configuration of the EVM.
+func (g *Genesis) ExecutionConfig() evm.ExecutionConfig {
+	config := evm.ExecutionConfig{
+		GasLimit: g.GasLimit,
+		BaseFee:  g.BaseFee,
+		ChainID:  new(big.Int).SetUint64(g.ChainID),
+	}
+	if g.Staking != nil {
+		staking := *g.Staking
+		config.Staking = &staking
+	}
+	return config
+}
@@ -0,0 +1,446 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/relab/gorums"
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/backend"
+	"github.com/relab/hotstuff/blockchain"
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/crypto"
+	"github.com/relab/hotstuff/crypto/keygen"
+	"github.com/relab/hotstuff/crypto/keystore"
+	"github.com/relab/hotstuff/crypto/remote"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/evm"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+	"github.com/relab/hotstuff/replica"
+	"github.com/relab/hotstuff/synchronizer"
+	"github.com/relab/hotstuff/trie"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	// imported modules
+	_ "github.com/relab/hotstuff/consensus/chainedhotstuff"
+	_ "github.com/relab/hotstuff/consensus/fasthotstuff"
+	_ "github.com/relab/hotstuff/consensus/hotstuff2"
+	_ "github.com/relab/hotstuff/consensus/jolteon"
+	_ "github.com/relab/hotstuff/consensus/simplehotstuff"
+	"github.com/relab/hotstuff/crypto/bls12"
+	_ "github.com/relab/hotstuff/crypto/ecdsa"
+	_ "github.com/relab/hotstuff/crypto/eddsa"
+	_ "github.com/relab/hotstuff/leaderrotation"
+)
+
+// peerRetryInterval is the time between attempts to reach the other replicas at startup.
+const peerRetryInterval = time.Second
+
+// Node is a replica that runs until it is stopped, and that keeps its state on disk.
+type Node struct {
+	config  *Config
+	genesis *Genesis
+	logger  logging.Logger
+
+	replica    *replica.Replica
+	replicas   []backend.ReplicaInfo
+	blockChain modules.BlockChain
+	stateStore *blockchain.StateStore
+	stateDB    *trie.BadgerTrieDB
+	state      *stateMachine
+	signerConn *grpc.ClientConn
+	// The TLS credentials, which are reloaded when the files change. Nil if TLS is disabled.
+	credentials *backend.Credentials
+}
+
+// New creates the node that is configured by the files in the configuration directory.
+// The stores in the data directory are created if they do not exist.
+func New(dir string) (_ *Node, err error) {
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		return nil, err
+	}
+	genesis, err := LoadGenesis(filepath.Join(dir, GenesisFile))
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := genesis.Validator(cfg.ID); !ok {
+		return nil, fmt.Errorf("replica %d is not a validator in the genesis file", cfg.ID)
+	}
+	var privKey hotstuff.PrivateKey
+	if cfg.Signer == "" {
+		passphrase := keystore.Prompt("Passphrase for " + filepath.Join(dir, PrivateKeyFile) + ": ")
+		if cfg.PassphraseFile != "" {
+			passphrase = keystore.PassphraseFile(cfg.PassphraseFile)
+		}
+		privKey, err = keygen.ReadPrivateKeyFile(filepath.Join(dir, PrivateKeyFile), passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private key: %w", err)
+		}
+	}
+
+	n := &Node{
+		config:  cfg,
+		genesis: genesis,
+		logger:  logging.New("hs" + strconv.Itoa(int(cfg.ID))),
+	}
+	var archive *blockchain.Archive
+	defer func() {
+		if err != nil {
+			n.closeStores()
+			if archive != nil {
+				_ = archive.Close()
+			}
+		}
+	}()
+
+	n.replicas, err = n.peers()
+	if err != nil {
+		return nil, err
+	}
+	if err := n.openStores(); err != nil {
+		return nil, err
+	}
+
+	c := replica.Config{
+		ID:                 cfg.ID,
+		PrivateKey:         privKey,
+		TLS:                cfg.TLS,
+		BatchSize:          cfg.BatchSize,
+		MaxBatchBytes:      cfg.MaxBatchBytes,
+		MaxBatchDelay:      time.Duration(cfg.MaxBatchDelay),
+		ClientWindow:       cfg.ClientWindow,
+		VotingPower:        genesis.VotingPower(),
+		Mempool:            cfg.Mempool,
+		StateStore:         n.stateStore,
+		StateMachine:       n.state,
+		StateDB:            n.stateDB,
+		StateLeaf:          evm.AccountStorageRoot,
+		CheckpointInterval: hotstuff.View(cfg.CheckpointInterval),
+		ManagerOptions: []gorums.ManagerOption{
+			gorums.WithDialTimeout(time.Duration(cfg.ConnectTimeout)),
+		},
+	}
+	c.Epochs, err = genesis.EpochManager()
+	if err != nil {
+		return nil, err
+	}
+	c.Retention, err = blockchain.ParseRetentionMode(cfg.Retention)
+	if err != nil {
+		return nil, err
+	}
+	if c.Retention == blockchain.RetainArchive {
+		archive, err = blockchain.NewArchive(filepath.Join(cfg.DataDir, "archive.db"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create archive: %w", err)
+		}
+		c.Archive = archive
+	}
+	if genesis.Crypto == "bls12-threshold" {
+		share, err := keygen.ReadThresholdKeyShareFile(filepath.Join(dir, SigningKeyFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing key: %w", err)
+		}
+		pub, err := genesis.ThresholdPublicKey()
+		if err != nil {
+			return nil, err
+		}
+		c.SigningKeys = &bls12.SigningKeys{Share: share, Public: pub}
+	}
+	if cfg.TLS {
+		n.credentials, err = backend.LoadCredentials(backend.CredentialFiles{
+			Certificate: filepath.Join(dir, CertificateFile),
+			Key:         filepath.Join(dir, CertificateKeyFile),
+			CA:          filepath.Join(dir, CAFile),
+			CRL:         cfg.CRLFile,
+			DenyList:    cfg.DenyListFile,
+		})
+		if err != nil {
+			return nil, err
+		}
+		c.Credentials = n.credentials
+	}
+
+	if cfg.Signer != "" {
+		creds := insecure.NewCredentials()
+		if cfg.TLS {
+			creds = n.credentials.ClientCredentials()
+		}
+		n.signerConn, err = grpc.NewClient(cfg.Signer, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to signer: %w", err)
+		}
+	}
+
+	builder, err := n.modules(privKey)
+	if err != nil {
+		return nil, err
+	}
+	n.replica = replica.New(c, builder)
+	return n, nil
+}
+
+// peers returns the addresses and public keys of the validators.
+func (n *Node) peers() ([]backend.ReplicaInfo, error) {
+	addresses := make(map[hotstuff.ID]string, len(n.config.Peers))
+	for _, peer := range n.config.Peers {
+		addresses[peer.ID] = peer.Address
+	}
+	replicas := make([]backend.ReplicaInfo, 0, len(n.genesis.Validators))
+	for _, v := range n.genesis.Validators {
+		addr, ok := addresses[v.ID]
+		if !ok && v.ID != n.config.ID {
+			return nil, fmt.Errorf("no address for validator %d in %s", v.ID, ConfigFile)
+		}
+		pubKey, err := keygen.ParsePublicKey([]byte(v.PublicKey))
+		if err != nil {
+			return nil, err
+		}
+		replicas = append(replicas, backend.ReplicaInfo{ID: v.ID, Address: addr, PubKey: pubKey})
+	}
+	return replicas, nil
+}
+
+// openStores opens the blockchain, the consensus state, and the EVM state in the data directory.
+// If the EVM state does not exist, it is created from the genesis file.
+func (n *Node) openStores() (err error) {
+	dataDir := n.config.DataDir
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	n.blockChain, err = blockchain.NewBlockChain(blockchain.Config{
+		StorageType: blockchain.BadgerStorage,
+		DataDir:     dataDir,
+		DBName:      "blocks.db",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create blockchain: %w", err)
+	}
+	n.stateStore, err = blockchain.NewStateStore(dataDir)
+	if err != nil {
+		return fmt.Errorf("failed to create state store: %w", err)
+	}
+	n.stateDB, err = trie.NewBadgerTrieDB(filepath.Join(dataDir, "evm.db"))
+	if err != nil {
+		return fmt.Errorf("failed to create state database: %w", err)
+	}
+
+	root, ok, err := n.stateStore.GetStateRoot()
+	if err != nil {
+		return fmt.Errorf("failed to read state root: %w", err)
+	}
+	var state *evm.TrieStateDB
+	if ok {
+		state, err = evm.NewTrieStateDBWithRoot(n.stateDB, root)
+		if err != nil {
+			return fmt.Errorf("failed to load state %.8s: %w", root, err)
+		}
+		n.logger.Infof("Loaded state %.8s", root)
+	} else {
+		alloc, err := n.genesis.Alloc()
+		if err != nil {
+			return err
+		}
+		state = evm.NewTrieStateDB(n.stateDB)
+		alloc.Apply(state)
+		root, err = state.Commit()
+		if err != nil {
+			return fmt.Errorf("failed to commit genesis state: %w", err)
+		}
+		if err := n.stateStore.SetStateRoot(root); err != nil {
+			return fmt.Errorf("failed to save genesis state root: %w", err)
+		}
+		n.logger.Infof("Created genesis state %.8s with %d accounts", root, len(alloc))
+	}
+	n.state = &stateMachine{
+		BatchExecutor: evm.NewBatchExecutor(n.genesis.ExecutionConfig(), state),
+		store:         n.stateStore,
+		logger:        n.logger,
+	}
+	return nil
+}
+
+// modules returns a builder with the consensus modules that are chosen in the genesis file.
+// The consensus and synchronizer keep their state in the state store.
+func (n *Node) modules(privKey hotstuff.PrivateKey) (modules.Builder, error) {
+	builder := modules.NewBuilder(n.config.ID, privKey)
+
+	rules, ok := modules.GetModule[consensus.Rules](n.genesis.Consensus)
+	if !ok {
+		return builder, fmt.Errorf("invalid consensus name: '%s'", n.genesis.Consensus)
+	}
+	cryptoImpl, ok := modules.GetModule[modules.CryptoBase](n.genesis.Crypto)
+	if !ok {
+		return builder, fmt.Errorf("invalid crypto name: '%s'", n.genesis.Crypto)
+	}
+	if n.signerConn != nil {
+		v, _ := n.genesis.Validator(n.config.ID)
+		pubKey, err := keygen.ParsePublicKey([]byte(v.PublicKey))
+		if err != nil {
+			return builder, err
+		}
+		cryptoImpl, err = remote.New(n.signerConn, n.config.ID, pubKey, cryptoImpl)
+		if err != nil {
+			return builder, err
+		}
+	}
+	leaderRotation, ok := modules.GetModule[modules.LeaderRotation](n.genesis.LeaderRotation)
+	if !ok {
+		return builder, fmt.Errorf("invalid leader-rotation algorithm: '%s'", n.genesis.LeaderRotation)
+	}
+
+	cs, err := consensus.NewPersistentWithStateStore(rules, n.stateStore)
+	if err != nil {
+		return builder, err
+	}
+	viewDuration := synchronizer.NewViewDuration(
+		n.config.TimeoutSamples,
+		float64(time.Duration(n.config.ViewTimeout))/float64(time.Millisecond),
+		float64(time.Duration(n.config.MaxTimeout))/float64(time.Millisecond),
+		n.config.TimeoutMultiplier,
+	)
+	sync, err := synchronizer.NewPersistentWithStateStore(viewDuration, n.stateStore)
+	if err != nil {
+		return builder, err
+	}
+
+	builder.Add(
+		eventloop.New(1000),
+		cs,
+		consensus.NewVotingMachine(),
+		crypto.NewCache(cryptoImpl, 100),
+		leaderRotation,
+		sync,
+		n.blockChain,
+		n.logger,
+	)
+	builder.Options().SetSharedRandomSeed(n.genesis.Seed)
+	return builder, nil
+}
+
+// Run starts the replica and runs it until the context is canceled.
+// The other replicas must be reachable before the replica connects to them.
+// When the context is canceled, the replica is stopped and the stores are closed.
+func (n *Node) Run(ctx context.Context) error {
+	// the replica is closed before the stores, also if the replica fails to start.
+	defer n.closeStores()
+	stop := n.replica.Close
+	defer func() { stop() }()
+
+	replicaListener, err := net.Listen("tcp", n.config.ListenAddress)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", n.config.ListenAddress, err)
+	}
+	clientListener, err := net.Listen("tcp", n.config.ClientAddress)
+	if err != nil {
+		_ = replicaListener.Close()
+		return fmt.Errorf("failed to listen on %s: %w", n.config.ClientAddress, err)
+	}
+	if n.credentials != nil {
+		if err := n.credentials.Watch(ctx, n.logger); err != nil {
+			_ = replicaListener.Close()
+			_ = clientListener.Close()
+			return err
+		}
+	}
+	n.replica.StartServers(replicaListener, clientListener)
+	n.logger.Infof("Listening on %s for replicas and on %s for clients", replicaListener.Addr(), clientListener.Addr())
+
+	if err := n.waitForPeers(ctx); err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		return err
+	}
+	if err := n.replica.Connect(n.replicas); err != nil {
+		return err
+	}
+	n.replica.Start()
+	stop = n.replica.Stop
+	n.logger.Info("Started replica")
+
+	<-ctx.Done()
+	n.logger.Info("Stopping replica")
+	return nil
+}
+
+// waitForPeers waits until the other replicas accept connections, or until the context is canceled.
+func (n *Node) waitForPeers(ctx context.Context) error {
+	for _, r := range n.replicas {
+		if r.ID == n.config.ID {
+			continue
+		}
+		for {
+			var d net.Dialer
+			dialCtx, cancel := context.WithTimeout(ctx, peerRetryInterval)
+			conn, err := d.DialContext(dialCtx, "tcp", r.Address)
+			cancel()
+			if err == nil {
+				_ = conn.Close()
+				break
+			}
+			n.logger.Infof("Waiting for replica %d at %s", r.ID, r.Address)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(peerRetryInterval):
+			}
+		}
+	}
+	return nil
+}
+
+// closeStores closes the blockchain, the state store and the EVM state, flushing them to disk,
+// and the connection to the remote signer.
+func (n *Node) closeStores() {
+	var errs []error
+	if closer, ok := n.blockChain.(io.Closer); ok {
+		errs = append(errs, closer.Close())
+	}
+	if n.stateDB != nil {
+		errs = append(errs, n.stateDB.Close())
+	}
+	if n.stateStore != nil {
+		errs = append(errs, n.stateStore.Close())
+	}
+	if n.signerConn != nil {
+		errs = append(errs, n.signerConn.Close())
+	}
+	if err := errors.Join(errs...); err != nil {
+		n.logger.Errorf("Failed to close stores: %v", err)
+	}
+}
+
+// stateMachine executes the committed blocks on the EVM, and stages the root of the resulting state,
+// which is saved with the executed block, such that the node continues from the same state after a restart.
+type stateMachine struct {
+	*evm.BatchExecutor
+	store  *blockchain.StateStore
+	logger logging.Logger
+}
+
+func (s *stateMachine) Exec(block *hotstuff.Block) {
+	s.BatchExecutor.Exec(block)
+	s.save()
+}
+
+func (s *stateMachine) Restore(stateRoot hotstuff.Hash) error {
+	if err := s.BatchExecutor.Restore(stateRoot); err != nil {
+		return err
+	}
+	s.save()
+	return nil
+}
+
+func (s *stateMachine) save() {
+	s.store.StageStateRoot(s.StateRoot())
+}
@@ -0,0 +1,227 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/crypto/keygen"
+	"github.com/relab/hotstuff/evm"
+	"github.com/relab/hotstuff/modules"
+	"github.com/relab/hotstuff/txpool"
+)
+
+const testAccount = "0x1000000000000000000000000000000000000001"
+
+func writeJSON(t *testing.T, path string, v any) {
+	t.Helper()
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// freeAddress returns a local address that is not in use.
+func freeAddress(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	return lis.Addr().String()
+}
+
+// createTestNetwork creates the configuration directories of n validators that share a genesis file.
+func createTestNetwork(t *testing.T, n int) []string {
+	t.Helper()
+	var (
+		dirs    []string
+		peers   []Peer
+		genesis = Genesis{
+			ChainID:  7,
+			Accounts: map[string]*big.Int{testAccount: big.NewInt(1000)},
+		}
+	)
+	for i := 1; i <= n; i++ {
+		id := hotstuff.ID(i)
+		dir := t.TempDir()
+		pk, err := keygen.GenerateECDSAPrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := keygen.WritePrivateKeyFile(pk, filepath.Join(dir, PrivateKeyFile)); err != nil {
+			t.Fatal(err)
+		}
+		pub, err := keygen.PublicKeyToPEM(&pk.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dirs = append(dirs, dir)
+		peers = append(peers, Peer{ID: id, Address: freeAddress(t)})
+		genesis.Validators = append(genesis.Validators, Validator{ID: id, PublicKey: string(pub)})
+	}
+	for i, dir := range dirs {
+		cfg := DefaultConfig(peers[i].ID)
+		cfg.ListenAddress = peers[i].Address
+		cfg.ClientAddress = "127.0.0.1:0"
+		cfg.Peers = peers
+		writeJSON(t, filepath.Join(dir, ConfigFile), cfg)
+		writeJSON(t, filepath.Join(dir, GenesisFile), genesis)
+	}
+	return dirs
+}
+
+// runNetwork runs the nodes in the configuration directories until the context is canceled.
+func runNetwork(ctx context.Context, t *testing.T, dirs []string) {
+	t.Helper()
+	errs := make(chan error, len(dirs))
+	for _, dir := range dirs {
+		n, err := New(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func() { errs <- n.Run(ctx) }()
+	}
+	for range dirs {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := createTestNetwork(t, 1)[0]
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ID != 1 {
+		t.Errorf("got ID %d, want 1", cfg.ID)
+	}
+	if want := filepath.Join(dir, "data"); cfg.DataDir != want {
+		t.Errorf("got data dir %s, want %s", cfg.DataDir, want)
+	}
+	if time.Duration(cfg.ViewTimeout) != 500*time.Millisecond {
+		t.Errorf("got view timeout %v, want 500ms", time.Duration(cfg.ViewTimeout))
+	}
+
+	writeJSON(t, filepath.Join(dir, ConfigFile), map[string]any{"listen_address": ":1234"})
+	if _, err := LoadConfig(dir); err == nil {
+		t.Error("expected an error for a configuration without a replica id")
+	}
+}
+
+func TestLoadGenesis(t *testing.T) {
+	dir := createTestNetwork(t, 1)[0]
+	path := filepath.Join(dir, GenesisFile)
+	genesis, err := LoadGenesis(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if genesis.Consensus != "chainedhotstuff" || genesis.Crypto != "ecdsa" {
+		t.Errorf("got %s and %s, want the default consensus and crypto", genesis.Consensus, genesis.Crypto)
+	}
+	if genesis.VotingPower() != nil {
+		t.Error("expected no voting power when all validators have one vote")
+	}
+	alloc, err := genesis.Alloc()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, _ := txpool.ParseAddress(testAccount)
+	if alloc[addr].Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("got balance %v, want 1000", alloc[addr])
+	}
+
+	if genesis.ExecutionConfig().Staking != nil {
+		t.Error("expected staking to be disabled when it is not configured")
+	}
+	genesis.Staking = &evm.StakingConfig{BlockReward: big.NewInt(10), EpochLength: 100}
+	writeJSON(t, path, genesis)
+	if genesis, err = LoadGenesis(path); err != nil {
+		t.Fatal(err)
+	}
+	if staking := genesis.ExecutionConfig().Staking; staking == nil || staking.BlockReward.Int64() != 10 || staking.EpochLength != 100 {
+		t.Errorf("got staking configuration %+v, want the one in the genesis file", staking)
+	}
+
+	if m, err := genesis.EpochManager(); m != nil || err != nil {
+		t.Errorf("got epoch manager %v, %v, want none when reconfiguration is not configured", m, err)
+	}
+	authority, _, err := keygen.GenerateED25519Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorityPEM, err := keygen.PublicKeyToPEM(authority)
+	if err != nil {
+		t.Fatal(err)
+	}
+	genesis.Reconfiguration = &Reconfiguration{EpochLength: 50, Authority: string(authorityPEM)}
+	writeJSON(t, path, genesis)
+	if genesis, err = LoadGenesis(path); err != nil {
+		t.Fatal(err)
+	}
+	if m, err := genesis.EpochManager(); err != nil || m.StartView(1) != 50 {
+		t.Errorf("got epoch manager %v, %v, want one with 50 views per epoch", m, err)
+	}
+
+	invalid := []Genesis{
+		{},
+		{Validators: []Validator{{ID: 1, PublicKey: "garbage"}}},
+		{Validators: append(genesis.Validators, genesis.Validators...)},
+		{Validators: genesis.Validators, Accounts: map[string]*big.Int{"0x1234": big.NewInt(1)}},
+		{Validators: genesis.Validators, Accounts: map[string]*big.Int{testAccount: big.NewInt(-1)}},
+		{Validators: genesis.Validators, Staking: &evm.StakingConfig{SlashPercent: 101}},
+		{Validators: genesis.Validators, Reconfiguration: &Reconfiguration{Authority: "garbage"}},
+		{Validators: genesis.Validators, Reconfiguration: &Reconfiguration{Authority: genesis.Validators[0].PublicKey}},
+	}
+	for i, g := range invalid {
+		writeJSON(t, path, g)
+		if _, err := LoadGenesis(path); err == nil {
+			t.Errorf("expected an error for invalid genesis file %d", i)
+		}
+	}
+}
+
+func TestRestart(t *testing.T) {
+	dirs := createTestNetwork(t, 2)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	runNetwork(ctx, t, dirs)
+
+	n, err := New(dirs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		n.replica.Close()
+		n.closeStores()
+	}()
+	var synchronizer modules.Synchronizer
+	n.replica.Modules().Get(&synchronizer)
+	if synchronizer.View() <= 1 {
+		t.Errorf("got view %d after restart, want the view that the node stopped in", synchronizer.View())
+	}
+	root, ok, err := n.stateStore.GetStateRoot()
+	if err != nil || !ok {
+		t.Fatalf("expected the state root to be stored: %v", err)
+	}
+	state, err := evm.NewTrieStateDBWithRoot(n.stateDB, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, _ := txpool.ParseAddress(testAccount)
+	if balance := state.GetBalance(addr); balance.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("got balance %v after restart, want 1000", balance)
+	}
+}
@@ -0,0 +1,248 @@
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/crypto/bls12"
+	"github.com/relab/hotstuff/crypto/keygen"
+	"github.com/relab/hotstuff/crypto/keystore"
+)
+
+// ComposeFile is the name of the Docker Compose file that is written by WriteComposeFile.
+const ComposeFile = "docker-compose.yml"
+
+// TestnetOptions describes a testnet that is generated by InitTestnet.
+type TestnetOptions struct {
+	// The number of validators.
+	Validators int
+	// The hosts that the validators run on. Validator i runs on host i modulo the number of hosts.
+	Hosts []string
+	// The port of the first validator. Validator i listens for replicas on BasePort+i-1,
+	// and for clients on BasePort+i-1+ClientPortOffset, such that validators on the same host use distinct ports.
+	BasePort int
+	// Controls whether the replicas use TLS.
+	TLS bool
+	// If it is not empty, the private keys of the validators are encrypted with this passphrase.
+	// The keys are encrypted with the light scrypt parameters, such that the validators start quickly.
+	Passphrase []byte
+	// The genesis file without validators. The validators are added by InitTestnet.
+	Genesis Genesis
+}
+
+// ClientPortOffset is the distance between the replica port and the client port of a validator.
+const ClientPortOffset = 10000
+
+// NodeDir returns the configuration directory of the validator with the given ID in the testnet directory.
+func NodeDir(dir string, id hotstuff.ID) string {
+	return filepath.Join(dir, "node"+strconv.Itoa(int(id)))
+}
+
+// InitTestnet generates a certificate authority, the keys and certificates of the validators,
+// a shared genesis file, and a configuration directory for each validator in the testnet directory.
+// It returns the configuration directories.
+func InitTestnet(dir string, opts TestnetOptions) ([]string, error) {
+	if opts.Validators < 1 {
+		return nil, fmt.Errorf("a testnet needs at least one validator")
+	}
+	if len(opts.Hosts) == 0 {
+		opts.Hosts = []string{"127.0.0.1"}
+	}
+	if opts.BasePort == 0 {
+		opts.BasePort = 30000
+	}
+	if opts.Genesis.Crypto == "" {
+		opts.Genesis.Crypto = "ecdsa"
+	}
+	if opts.Genesis.Seed == 0 {
+		opts.Genesis.Seed = rand.Int63()
+	}
+
+	caKey, ca, err := keygen.GenerateCA()
+	if err != nil {
+		return nil, err
+	}
+	caPEM := keygen.CertToPEM(ca)
+
+	genesis := opts.Genesis
+	genesis.Validators = nil
+	peers := make([]Peer, 0, opts.Validators)
+	keyChains := make([]keygen.KeyChain, 0, opts.Validators)
+	for i := 0; i < opts.Validators; i++ {
+		id := hotstuff.ID(i + 1)
+		host := opts.Hosts[i%len(opts.Hosts)]
+		keyChain, err := keygen.GenerateKeyChain(id, []string{host, "localhost", "127.0.0.1"}, genesis.Crypto, ca, caKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate keys for validator %d: %w", id, err)
+		}
+		if len(opts.Passphrase) > 0 {
+			keyChain.PrivateKey, err = encryptKey(keyChain.PrivateKey, opts.Passphrase)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encrypt key of validator %d: %w", id, err)
+			}
+		}
+		keyChains = append(keyChains, keyChain)
+		peers = append(peers, Peer{ID: id, Address: net.JoinHostPort(host, strconv.Itoa(opts.BasePort+i))})
+		genesis.Validators = append(genesis.Validators, Validator{ID: id, PublicKey: string(keyChain.PublicKey)})
+	}
+
+	var signingKeys map[hotstuff.ID]*bls12.ThresholdKeyShare
+	if genesis.Crypto == "bls12-threshold" {
+		ids := make([]hotstuff.ID, 0, len(peers))
+		for _, peer := range peers {
+			ids = append(ids, peer.ID)
+		}
+		var pub *bls12.ThresholdPublicKey
+		signingKeys, pub, err = keygen.GenerateThresholdKeys(ids, hotstuff.QuorumSize(len(ids)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate threshold keys: %w", err)
+		}
+		genesis.GroupPublicKey = string(keygen.ThresholdPublicKeyToPEM(pub))
+	}
+
+	dirs := make([]string, 0, opts.Validators)
+	for i, peer := range peers {
+		nodeDir := NodeDir(dir, peer.ID)
+		if err := os.MkdirAll(nodeDir, 0o755); err != nil {
+			return nil, err
+		}
+		cfg := DefaultConfig(peer.ID)
+		cfg.ListenAddress = ":" + strconv.Itoa(opts.BasePort+i)
+		cfg.ClientAddress = ":" + strconv.Itoa(opts.BasePort+i+ClientPortOffset)
+		cfg.TLS = opts.TLS
+		cfg.Peers = peers
+
+		files := []struct {
+			name string
+			data any
+		}{
+			{ConfigFile, cfg},
+			{GenesisFile, genesis},
+			{PrivateKeyFile, keyChains[i].PrivateKey},
+			{CertificateFile, keyChains[i].Certificate},
+			{CertificateKeyFile, keyChains[i].CertificateKey},
+			{CAFile, caPEM},
+		}
+		if share, ok := signingKeys[peer.ID]; ok {
+			files = append(files, struct {
+				name string
+				data any
+			}{SigningKeyFile, keygen.ThresholdKeyShareToPEM(share)})
+		}
+		for _, f := range files {
+			if err := writeFile(filepath.Join(nodeDir, f.name), f.data); err != nil {
+				return nil, err
+			}
+		}
+		dirs = append(dirs, nodeDir)
+	}
+	return dirs, nil
+}
+
+// encryptKey encrypts a PEM encoded private key with the passphrase.
+func encryptKey(keyPEM, passphrase []byte) ([]byte, error) {
+	key, err := keygen.ParsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return keygen.EncryptPrivateKeyToPEM(key, passphrase, keystore.LightScryptN, keystore.LightScryptP)
+}
+
+// writeFile writes PEM data as is, and other data as JSON.
+func writeFile(path string, data any) error {
+	b, ok := data.([]byte)
+	if !ok {
+		var err error
+		b, err = json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, b, 0o600)
+}
+
+var composeTemplate = template.Must(template.New(ComposeFile).Parse(`# Generated by 'hotstuff testnet init'. Use it together with scripts/docker-compose.yml:
+#
+#   docker compose -f scripts/docker-compose.yml -f {{.File}} up --build {{range .Nodes}}{{.Name}} {{end}}
+#
+# The image is built from scripts/Dockerfile.controller, which needs the SSH key scripts/id (see scripts/deploy_test.sh).
+services:
+{{- range .Nodes}}
+  {{.Name}}:
+    build:
+      context: ".."
+      dockerfile: "scripts/Dockerfile.controller"
+    hostname: "{{.Name}}"
+    volumes:
+      - "{{.Dir}}:/root/{{.Name}}"
+    entrypoint: ["hotstuff", "node", "--home", "/root/{{.Name}}"]
+    stop_signal: SIGTERM
+    networks:
+      hotstuff:
+{{- end}}
+`))
+
+// DockerHosts returns the hosts of the validators when the testnet runs in Docker Compose.
+// The host of each validator is the name of its service in the file that is written by WriteComposeFile.
+func DockerHosts(validators int) []string {
+	hosts := make([]string, validators)
+	for i := range hosts {
+		hosts[i] = filepath.Base(NodeDir("", hotstuff.ID(i+1)))
+	}
+	return hosts
+}
+
+// WriteComposeFile writes a Docker Compose file with a service for each validator to the testnet directory.
+// The file extends scripts/docker-compose.yml, and the testnet must be generated with the hosts from DockerHosts.
+func WriteComposeFile(dir string, validators int) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	type node struct{ Name, Dir string }
+	data := struct {
+		File  string
+		Nodes []node
+	}{File: filepath.Join(dir, ComposeFile)}
+	for i, host := range DockerHosts(validators) {
+		data.Nodes = append(data.Nodes, node{Name: host, Dir: NodeDir(abs, hotstuff.ID(i+1))})
+	}
+	f, err := os.Create(filepath.Join(dir, ComposeFile))
+	if err != nil {
+		return err
+	}
+	if err := composeTemplate.Execute(f, data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// defaultBalance is the balance of accounts that are funded without an explicit balance: 1000 ether.
+var defaultBalance = new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+
+// ParseAccounts parses a list of accounts to fund in the genesis file.
+// Each account is an address, optionally followed by '=' and the balance in wei.
+func ParseAccounts(accounts []string) (map[string]*big.Int, error) {
+	alloc := make(map[string]*big.Int, len(accounts))
+	for _, account := range accounts {
+		addr, value, ok := strings.Cut(account, "=")
+		balance := new(big.Int).Set(defaultBalance)
+		if ok {
+			if _, ok := balance.SetString(value, 10); !ok {
+				return nil, fmt.Errorf("invalid balance of account %s: %s", addr, value)
+			}
+		}
+		alloc[addr] = balance
+	}
+	return alloc, nil
+}
@@ -0,0 +1,183 @@
+package node
+
+import (
+	"context"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relab/hotstuff/modules"
+)
+
+func TestInitTestnet(t *testing.T) {
+	dir := t.TempDir()
+	_, port, _ := net.SplitHostPort(freeAddress(t))
+	basePort, _ := strconv.Atoi(port)
+	accounts, err := ParseAccounts([]string{testAccount + "=1000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dirs, err := InitTestnet(dir, TestnetOptions{
+		Validators: 4,
+		BasePort:   basePort,
+		TLS:        true,
+		Genesis:    Genesis{Crypto: "eddsa", Accounts: accounts},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) != 4 {
+		t.Fatalf("got %d configuration directories, want 4", len(dirs))
+	}
+
+	addresses := make(map[string]bool)
+	for i, dir := range dirs {
+		cfg, err := LoadConfig(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int(cfg.ID) != i+1 || len(cfg.Peers) != 4 {
+			t.Errorf("got replica %d with %d peers, want replica %d with 4 peers", cfg.ID, len(cfg.Peers), i+1)
+		}
+		addresses[cfg.ListenAddress] = true
+		addresses[cfg.ClientAddress] = true
+		genesis, err := LoadGenesis(filepath.Join(dir, GenesisFile))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if genesis.Crypto != "eddsa" || len(genesis.Validators) != 4 {
+			t.Errorf("got %s with %d validators, want eddsa with 4 validators", genesis.Crypto, len(genesis.Validators))
+		}
+	}
+	if len(addresses) != 8 {
+		t.Errorf("got %d distinct addresses, want 8", len(addresses))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	runNetwork(ctx, t, dirs)
+
+	n, err := New(dirs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		n.replica.Close()
+		n.closeStores()
+	}()
+	var synchronizer modules.Synchronizer
+	n.replica.Modules().Get(&synchronizer)
+	if synchronizer.View() <= 1 {
+		t.Errorf("got view %d after running the testnet, want a later view", synchronizer.View())
+	}
+}
+
+func TestInitTestnetThreshold(t *testing.T) {
+	dir := t.TempDir()
+	dirs, err := InitTestnet(dir, TestnetOptions{Validators: 4, Genesis: Genesis{Crypto: "bls12-threshold"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	genesis, err := LoadGenesis(filepath.Join(dirs[0], GenesisFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := genesis.ThresholdPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub.Threshold() != 3 {
+		t.Errorf("got threshold %d, want 3", pub.Threshold())
+	}
+	for _, dir := range dirs {
+		n, err := New(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n.replica.Close()
+		n.closeStores()
+	}
+
+	// a genesis file whose group key does not match the validators is rejected.
+	genesis.Validators = genesis.Validators[:3]
+	writeJSON(t, filepath.Join(dir, GenesisFile), genesis)
+	if _, err := LoadGenesis(filepath.Join(dir, GenesisFile)); err == nil {
+		t.Error("expected an error for a group key with too many participants")
+	}
+}
+
+func TestInitTestnetEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	dirs, err := InitTestnet(dir, TestnetOptions{Validators: 1, Passphrase: []byte("secret")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// without a passphrase file, the node prompts for the passphrase, which fails without a terminal.
+	if _, err := New(dirs[0]); err == nil {
+		t.Fatal("expected an error without a passphrase")
+	}
+
+	if err := os.WriteFile(filepath.Join(dirs[0], "passphrase"), []byte("secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(dirs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.PassphraseFile = "passphrase"
+	cfg.DataDir = "data"
+	writeJSON(t, filepath.Join(dirs[0], ConfigFile), cfg)
+	n, err := New(dirs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.replica.Close()
+	n.closeStores()
+}
+
+func TestWriteComposeFile(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := InitTestnet(dir, TestnetOptions{Validators: 2, Hosts: DockerHosts(2)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteComposeFile(dir, 2); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, ComposeFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"node1:", "node2:", "--home", NodeDir(dir, 2)} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("compose file does not contain %q", want)
+		}
+	}
+	cfg, err := LoadConfig(NodeDir(dir, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Peers[1].Address != "node2:30001" {
+		t.Errorf("got address %s, want node2:30001", cfg.Peers[1].Address)
+	}
+}
+
+func TestParseAccounts(t *testing.T) {
+	accounts, err := ParseAccounts([]string{testAccount, "0x2000000000000000000000000000000000000002=5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accounts[testAccount].Cmp(defaultBalance) != 0 {
+		t.Errorf("got balance %v, want the default balance", accounts[testAccount])
+	}
+	if accounts["0x2000000000000000000000000000000000000002"].Cmp(big.NewInt(5)) != 0 {
+		t.Errorf("got balance %v, want 5", accounts["0x2000000000000000000000000000000000000002"])
+	}
+	if _, err := ParseAccounts([]string{testAccount + "=ten"}); err == nil {
+		t.Error("expected an error for an invalid balance")
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/crypto/bls12"
 	"github.com/relab/hotstuff/crypto/keygen"
 	"github.com/relab/hotstuff/internal/config"
 	"github.com/relab/hotstuff/internal/latency"
@@ -31,6 +32,9 @@ type Experiment struct {
 	caKey *ecdsa.PrivateKey
 	ca    *x509.Certificate
 
+	// the committee public key used by clients to encrypt commands, if the encrypted mempool is enabled.
+	thresholdPublicKey *bls12.ThresholdPublicKey
+
 	cfg *config.ExperimentConfig
 }
 
@@ -131,6 +135,39 @@ func (e *Experiment) createReplicas(replicaMap config.ReplicaMap) (cfg *orchestr
 		return nil, err
 	}
 
+	var thresholdKeys map[hotstuff.ID]*bls12.ThresholdKeyShare
+	if e.cfg.EncryptedMempool {
+		var ids []hotstuff.ID
+		for _, opts := range replicaMap {
+			for _, opt := range opts {
+				ids = append(ids, opt.HotstuffID())
+			}
+		}
+		// any f+1 replicas can decrypt a command, such that at least one of them is correct.
+		thresholdKeys, e.thresholdPublicKey, err = keygen.GenerateThresholdKeys(ids, hotstuff.NumFaulty(len(ids))+1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		signingKeys      map[hotstuff.ID]*bls12.ThresholdKeyShare
+		signingPublicKey *bls12.ThresholdPublicKey
+	)
+	if e.cfg.Crypto == "bls12-threshold" {
+		var ids []hotstuff.ID
+		for _, opts := range replicaMap {
+			for _, opt := range opts {
+				ids = append(ids, opt.HotstuffID())
+			}
+		}
+		// a threshold signature must prove that a quorum of replicas signed.
+		signingKeys, signingPublicKey, err = keygen.GenerateThresholdKeys(ids, hotstuff.QuorumSize(len(ids)))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	cfg = &orchestrationpb.ReplicaConfiguration{Replicas: make(map[uint32]*orchestrationpb.ReplicaInfo)}
 
 	for host, opts := range replicaMap {
@@ -141,6 +178,12 @@ func (e *Experiment) createReplicas(replicaMap config.ReplicaMap) (cfg *orchestr
 			if err != nil {
 				return nil, err
 			}
+			if key, ok := thresholdKeys[opt.HotstuffID()]; ok {
+				opt.SetThresholdKeys(key, e.thresholdPublicKey)
+			}
+			if key, ok := signingKeys[opt.HotstuffID()]; ok {
+				opt.SetSigningKeys(key, signingPublicKey)
+			}
 			req.Replicas[opt.ID] = opt
 			e.logger.Infof("replica %d assigned to host %s", opt.ID, host)
 		}
@@ -259,6 +302,9 @@ func (e *Experiment) startClients(cfg *orchestrationpb.ReplicaConfiguration, src
 		for _, id := range clientMap[host] {
 			clientOpts := proto.Clone(srcClientOpt).(*orchestrationpb.ClientOpts)
 			clientOpts.ID = uint32(id)
+			if e.thresholdPublicKey != nil {
+				clientOpts.ThresholdPublicKey = keygen.ThresholdPublicKeyToPEM(e.thresholdPublicKey)
+			}
 			req.Clients[uint32(id)] = clientOpts
 			e.logger.Infof("client %d assigned to host %s", id, host)
 		}
@@ -1,6 +1,7 @@
 package orchestration_test
 
 import (
+	"fmt"
 	"io"
 	"math"
 	"net"
@@ -21,7 +22,9 @@ import (
 	"github.com/relab/hotstuff/internal/tree"
 	"github.com/relab/hotstuff/logging"
 	"github.com/relab/hotstuff/metrics"
+	"github.com/relab/hotstuff/metrics/types"
 	"github.com/relab/iago/iagotest"
+	"google.golang.org/protobuf/proto"
 )
 
 func makeCfg(
@@ -67,10 +70,16 @@ func makeCfg(
 
 func run(t *testing.T, cfg *config.ExperimentConfig) {
 	t.Helper()
+	runWithMetrics(t, cfg, metrics.NopLogger(), nil, 0)
+}
+
+// runWithMetrics runs the experiment with the given metrics enabled on the replicas.
+func runWithMetrics(t testing.TB, cfg *config.ExperimentConfig, dl metrics.Logger, enabledMetrics []string, interval time.Duration) {
+	t.Helper()
 
 	controllerStream, workerStream := net.Pipe()
 	workerProxy := orchestration.NewRemoteWorker(protostream.NewWriter(controllerStream), protostream.NewReader(controllerStream))
-	worker := orchestration.NewWorker(protostream.NewWriter(workerStream), protostream.NewReader(workerStream), metrics.NopLogger(), nil, 0)
+	worker := orchestration.NewWorker(protostream.NewWriter(workerStream), protostream.NewReader(workerStream), dl, enabledMetrics, interval)
 
 	experiment, err := orchestration.NewExperiment(
 		cfg,
@@ -116,6 +125,12 @@ func TestOrchestration(t *testing.T) {
 		{consensus: "simplehotstuff", crypto: "ecdsa", replicas: 4},
 		{consensus: "simplehotstuff", crypto: "eddsa", replicas: 4},
 		{consensus: "simplehotstuff", crypto: "bls12", replicas: 4},
+		{consensus: "jolteon", crypto: "ecdsa", replicas: 4},
+		{consensus: "jolteon", crypto: "eddsa", replicas: 4},
+		{consensus: "jolteon", crypto: "bls12", replicas: 4},
+		{consensus: "hotstuff2", crypto: "ecdsa", replicas: 4},
+		{consensus: "hotstuff2", crypto: "eddsa", replicas: 4},
+		{consensus: "hotstuff2", crypto: "bls12", replicas: 4},
 		{consensus: "chainedhotstuff", crypto: "ecdsa", byzantine: fork, replicas: 4},
 		{consensus: "chainedhotstuff", crypto: "ecdsa", byzantine: silence, replicas: 4},
 		{consensus: "chainedhotstuff", crypto: "ecdsa", mods: []string{"kauri"}, replicas: 7, branchFactor: 2},
@@ -147,6 +162,63 @@ func TestOrchestration(t *testing.T) {
 	}
 }
 
+// latencyLogger computes the mean of the consensus latency measurements of all replicas.
+type latencyLogger struct {
+	mut   sync.Mutex
+	sum   float64
+	count uint64
+}
+
+func (l *latencyLogger) Log(msg proto.Message) {
+	m, ok := msg.(*types.LatencyMeasurement)
+	if !ok || m.GetCount() == 0 {
+		return
+	}
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	l.sum += m.GetLatency() * float64(m.GetCount())
+	l.count += m.GetCount()
+}
+
+func (l *latencyLogger) Close() error { return nil }
+
+func (l *latencyLogger) mean() float64 {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	if l.count == 0 {
+		return 0
+	}
+	return l.sum / float64(l.count)
+}
+
+// BenchmarkConsensusLatency reports the mean consensus-latency metric with and without
+// the verification pipeline, which verifies messages before they reach the event loop.
+func BenchmarkConsensusLatency(b *testing.B) {
+	modes := []struct {
+		name string
+		mods []string
+	}{
+		{name: "event-loop"},
+		{name: "pipeline", mods: []string{"verification-pipeline"}},
+		{name: "pipeline+pool", mods: []string{"verification-pipeline", "verification-pool"}},
+	}
+	for _, replicas := range []int{4, 16} {
+		for _, crypto := range []string{"ecdsa", "eddsa", "bls12"} {
+			for _, mode := range modes {
+				b.Run(fmt.Sprintf("replicas=%d/crypto=%s/verification=%s", replicas, crypto, mode.name), func(b *testing.B) {
+					cfg := makeCfg(replicas, 2, "chainedhotstuff", crypto, "round-robin", nil, 0, false, mode.mods...)
+					cfg.Duration = 3 * time.Second
+					dl := &latencyLogger{}
+					for i := 0; i < b.N; i++ {
+						runWithMetrics(b, cfg, dl, []string{"consensus-latency"}, 100*time.Millisecond)
+					}
+					b.ReportMetric(dl.mean(), "ms/commit")
+				})
+			}
+		}
+	}
+}
+
 func TestDeployment(t *testing.T) {
 	if os.Getenv("GITHUB_ACTIONS") != "" && runtime.GOOS != "linux" {
 		t.Skip("GitHub Actions only supports linux containers on linux runners.")
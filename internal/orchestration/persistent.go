@@ -15,6 +15,7 @@ import (
 	"github.com/relab/hotstuff/consensus"
 	"github.com/relab/hotstuff/consensus/byzantine"
 	"github.com/relab/hotstuff/crypto"
+	"github.com/relab/hotstuff/crypto/bls12"
 	"github.com/relab/hotstuff/crypto/keygen"
 	"github.com/relab/hotstuff/eventloop"
 	"github.com/relab/hotstuff/internal/proto/orchestrationpb"
@@ -29,8 +30,10 @@ import (
 
 // PersistentWorkerConfig contains configuration for persistent storage
 type PersistentWorkerConfig struct {
-	DataDir       string // Base directory for all persistent data
-	UsePersistent bool   // Whether to use persistent storage
+	DataDir            string // Base directory for all persistent data
+	UsePersistent      bool   // Whether to use persistent storage
+	CheckpointInterval uint64 // Number of views between checkpoints; zero disables checkpoints
+	Retention          string // What to do with blocks below the latest checkpoint: all, archive or delete
 }
 
 // NewPersistentWorker creates a worker with persistent storage support
@@ -38,6 +41,7 @@ func NewPersistentWorker(config PersistentWorkerConfig, worker Worker) *Persiste
 	return &PersistentWorker{
 		Worker: worker,
 		config: config,
+		stores: make(map[hotstuff.ID]*blockchain.StateStore),
 	}
 }
 
@@ -45,6 +49,7 @@ func NewPersistentWorker(config PersistentWorkerConfig, worker Worker) *Persiste
 type PersistentWorker struct {
 	Worker
 	config PersistentWorkerConfig
+	stores map[hotstuff.ID]*blockchain.StateStore // the state stores of the replicas, closed when they are stopped
 }
 
 // createReplicaWithPersistence creates a replica with optional persistent storage
@@ -75,6 +80,24 @@ func (w *PersistentWorker) createReplicaWithPersistence(opts *orchestrationpb.Re
 		rootCAs = x509.NewCertPool()
 		rootCAs.AppendCertsFromPEM(opts.GetCertificateAuthority())
 	}
+	var (
+		thresholdKey *bls12.ThresholdKeyShare
+		thresholdPub *bls12.ThresholdPublicKey
+	)
+	if len(opts.GetThresholdKeyShare()) > 0 {
+		thresholdKey, err = keygen.ParseThresholdKeyShare(opts.GetThresholdKeyShare())
+		if err != nil {
+			return nil, err
+		}
+		thresholdPub, err = keygen.ParseThresholdPublicKey(opts.GetThresholdPublicKey())
+		if err != nil {
+			return nil, err
+		}
+	}
+	signingKeys, err := parseSigningKeys(opts)
+	if err != nil {
+		return nil, err
+	}
 
 	// prepare modules
 	builder := modules.NewBuilder(hotstuff.ID(opts.GetID()), privKey)
@@ -99,6 +122,9 @@ func (w *PersistentWorker) createReplicaWithPersistence(opts *orchestrationpb.Re
 	if !ok {
 		return nil, fmt.Errorf("invalid crypto name: '%s'", opts.GetCrypto())
 	}
+	if err := checkFairOrdering(opts); err != nil {
+		return nil, err
+	}
 
 	leaderRotation, ok := modules.GetModule[modules.LeaderRotation](opts.GetLeaderRotation())
 	if !ok {
@@ -132,6 +158,26 @@ func (w *PersistentWorker) createReplicaWithPersistence(opts *orchestrationpb.Re
 		return nil, fmt.Errorf("failed to create persistent blockchain: %w", err)
 	}
 
+	// The state store keeps track of the committed client commands and checkpoints across restarts
+	stateStore, err := blockchain.NewStateStore(replicaDataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create state store: %w", err)
+	}
+
+	retention, err := blockchain.ParseRetentionMode(w.config.Retention)
+	if err != nil {
+		_ = stateStore.Close()
+		return nil, err
+	}
+	var archive *blockchain.Archive
+	if retention == blockchain.RetainArchive {
+		archive, err = blockchain.NewArchive(filepath.Join(replicaDataDir, "archive.db"))
+		if err != nil {
+			_ = stateStore.Close()
+			return nil, fmt.Errorf("failed to create archive: %w", err)
+		}
+	}
+
 	builder.Add(
 		eventloop.New(1000),
 		consensus.New(consensusRules),
@@ -155,25 +201,42 @@ func (w *PersistentWorker) createReplicaWithPersistence(opts *orchestrationpb.Re
 	for _, n := range opts.GetModules() {
 		m, ok := modules.GetModuleUntyped(n)
 		if !ok {
+			_ = stateStore.Close()
 			return nil, fmt.Errorf("no module named '%s'", n)
 		}
 		builder.Add(m)
 	}
 
 	c := replica.Config{
-		ID:          hotstuff.ID(opts.GetID()),
-		PrivateKey:  privKey,
-		TLS:         opts.GetUseTLS(),
-		Certificate: &certificate,
-		RootCAs:     rootCAs,
-		Locations:   opts.GetLocations(),
-		BatchSize:   opts.GetBatchSize(),
+		ID:                 hotstuff.ID(opts.GetID()),
+		PrivateKey:         privKey,
+		TLS:                opts.GetUseTLS(),
+		Certificate:        &certificate,
+		RootCAs:            rootCAs,
+		Locations:          opts.GetLocations(),
+		BatchSize:          opts.GetBatchSize(),
+		FairOrdering:       opts.GetFairOrdering(),
+		MaxBatchBytes:      opts.GetMaxBatchBytes(),
+		MaxBatchDelay:      opts.GetMaxBatchDelay().AsDuration(),
+		AdaptiveBatching:   opts.GetAdaptiveBatching(),
+		ClientWindow:       opts.GetClientWindow(),
+		VotingPower:        votingPower(opts.GetVotingPower()),
+		Evidence:           evidencePool(opts.GetEvidence()),
+		Mempool:            opts.GetMempool(),
+		ThresholdKeyShare:  thresholdKey,
+		ThresholdPublicKey: thresholdPub,
+		SigningKeys:        signingKeys,
+		StateStore:         stateStore,
+		CheckpointInterval: hotstuff.View(w.config.CheckpointInterval),
+		Retention:          retention,
+		Archive:            archive,
 		ManagerOptions: []gorums.ManagerOption{
 			gorums.WithDialTimeout(opts.GetConnectTimeout().AsDuration()),
 		},
 	}
 
 	logger.Infof("Created replica with persistent storage in: %s", replicaDataDir)
+	w.stores[c.ID] = stateStore
 	return replica.New(c, builder), nil
 }
 
@@ -206,6 +269,7 @@ func (w *PersistentWorker) createReplicas(req *orchestrationpb.CreateReplicaRequ
 
 		r.StartServers(replicaListener, clientListener)
 		w.replicas[hotstuff.ID(cfg.GetID())] = r
+		w.syncs.add(hotstuff.ID(cfg.GetID()), r.BlockSync())
 
 		resp.Replicas[cfg.GetID()] = &orchestrationpb.ReplicaInfo{
 			ID:          cfg.GetID(),
@@ -217,6 +281,25 @@ func (w *PersistentWorker) createReplicas(req *orchestrationpb.CreateReplicaRequ
 	return resp, nil
 }
 
+// Override stopReplicas to close the state stores once the replicas are stopped
+func (w *PersistentWorker) stopReplicas(req *orchestrationpb.StopReplicaRequest) (*orchestrationpb.StopReplicaResponse, error) {
+	res, err := w.Worker.stopReplicas(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range req.GetIDs() {
+		store, ok := w.stores[hotstuff.ID(id)]
+		if !ok {
+			continue
+		}
+		delete(w.stores, hotstuff.ID(id))
+		if err := store.Close(); err != nil {
+			return nil, fmt.Errorf("failed to close the state store of replica %d: %w", id, err)
+		}
+	}
+	return res, nil
+}
+
 // Run overrides the Worker's Run method to handle persistent replicas
 func (w *PersistentWorker) Run() error {
 	for {
@@ -232,7 +315,7 @@ func (w *PersistentWorker) Run() error {
 		case *orchestrationpb.StartReplicaRequest:
 			res, err = w.startReplicas(req)
 		case *orchestrationpb.StopReplicaRequest:
-			res, err = w.stopReplicas(req)
+			res, err = w.stopReplicas(req) // Use overridden method
 		case *orchestrationpb.StartClientRequest:
 			res, err = w.startClients(req)
 		case *orchestrationpb.StopClientRequest:
@@ -8,18 +8,22 @@ import (
 	"io"
 	"net"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/relab/gorums"
 	"github.com/relab/hotstuff"
 	"github.com/relab/hotstuff/backend"
 	"github.com/relab/hotstuff/blockchain"
+	"github.com/relab/hotstuff/blocksync"
 	"github.com/relab/hotstuff/client"
 	"github.com/relab/hotstuff/consensus"
 	"github.com/relab/hotstuff/consensus/byzantine"
 	"github.com/relab/hotstuff/crypto"
+	"github.com/relab/hotstuff/crypto/bls12"
 	"github.com/relab/hotstuff/crypto/keygen"
 	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/evidence"
 	"github.com/relab/hotstuff/internal/latency"
 	"github.com/relab/hotstuff/internal/proto/orchestrationpb"
 	"github.com/relab/hotstuff/internal/protostream"
@@ -37,8 +41,9 @@ import (
 	// imported modules
 	_ "github.com/relab/hotstuff/consensus/chainedhotstuff"
 	_ "github.com/relab/hotstuff/consensus/fasthotstuff"
+	_ "github.com/relab/hotstuff/consensus/hotstuff2"
+	_ "github.com/relab/hotstuff/consensus/jolteon"
 	_ "github.com/relab/hotstuff/consensus/simplehotstuff"
-	_ "github.com/relab/hotstuff/crypto/bls12"
 	_ "github.com/relab/hotstuff/crypto/ecdsa"
 	_ "github.com/relab/hotstuff/crypto/eddsa"
 	_ "github.com/relab/hotstuff/kauri"
@@ -56,6 +61,39 @@ type Worker struct {
 
 	replicas map[hotstuff.ID]*replica.Replica
 	clients  map[hotstuff.ID]*client.Client
+	syncs    *replicaSyncs
+}
+
+// replicaSyncs holds the block sync modules of the running replicas.
+// It is shared by copies of the worker, and is read by the RPC server while the worker runs.
+type replicaSyncs struct {
+	mut   sync.Mutex
+	syncs map[hotstuff.ID]*blocksync.BlockSync
+}
+
+func (s *replicaSyncs) add(id hotstuff.ID, blockSync *blocksync.BlockSync) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.syncs[id] = blockSync
+}
+
+func (s *replicaSyncs) remove(id hotstuff.ID) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	delete(s.syncs, id)
+}
+
+// Progress returns the progress of the block sync of a replica that runs on the worker,
+// and false if none of the replicas is syncing.
+func (w *Worker) Progress() (blocksync.Progress, bool) {
+	w.syncs.mut.Lock()
+	defer w.syncs.mut.Unlock()
+	for _, blockSync := range w.syncs.syncs {
+		if progress, ok := blockSync.Progress(); ok {
+			return progress, true
+		}
+	}
+	return blocksync.Progress{}, false
 }
 
 // Run runs the worker until it receives a command to quit.
@@ -104,6 +142,7 @@ func NewWorker(send *protostream.Writer, recv *protostream.Reader, dl metrics.Lo
 		measurementInterval: measurementInterval,
 		replicas:            make(map[hotstuff.ID]*replica.Replica),
 		clients:             make(map[hotstuff.ID]*client.Client),
+		syncs:               &replicaSyncs{syncs: make(map[hotstuff.ID]*blocksync.BlockSync)},
 	}
 }
 
@@ -135,6 +174,7 @@ func (w *Worker) createReplicas(req *orchestrationpb.CreateReplicaRequest) (*orc
 
 		r.StartServers(replicaListener, clientListener)
 		w.replicas[hotstuff.ID(cfg.GetID())] = r
+		w.syncs.add(hotstuff.ID(cfg.GetID()), r.BlockSync())
 
 		resp.Replicas[cfg.GetID()] = &orchestrationpb.ReplicaInfo{
 			ID:          cfg.GetID(),
@@ -165,6 +205,24 @@ func (w *Worker) createReplica(opts *orchestrationpb.ReplicaOpts) (*replica.Repl
 		rootCAs = x509.NewCertPool()
 		rootCAs.AppendCertsFromPEM(opts.GetCertificateAuthority())
 	}
+	var (
+		thresholdKey *bls12.ThresholdKeyShare
+		thresholdPub *bls12.ThresholdPublicKey
+	)
+	if len(opts.GetThresholdKeyShare()) > 0 {
+		thresholdKey, err = keygen.ParseThresholdKeyShare(opts.GetThresholdKeyShare())
+		if err != nil {
+			return nil, err
+		}
+		thresholdPub, err = keygen.ParseThresholdPublicKey(opts.GetThresholdPublicKey())
+		if err != nil {
+			return nil, err
+		}
+	}
+	signingKeys, err := parseSigningKeys(opts)
+	if err != nil {
+		return nil, err
+	}
 	// prepare modules
 	builder := modules.NewBuilder(hotstuff.ID(opts.GetID()), privKey)
 
@@ -188,6 +246,9 @@ func (w *Worker) createReplica(opts *orchestrationpb.ReplicaOpts) (*replica.Repl
 	if !ok {
 		return nil, fmt.Errorf("invalid crypto name: '%s'", opts.GetCrypto())
 	}
+	if err := checkFairOrdering(opts); err != nil {
+		return nil, err
+	}
 
 	leaderRotation, ok := modules.GetModule[modules.LeaderRotation](opts.GetLeaderRotation())
 	if !ok {
@@ -236,13 +297,24 @@ func (w *Worker) createReplica(opts *orchestrationpb.ReplicaOpts) (*replica.Repl
 		builder.Add(m)
 	}
 	c := replica.Config{
-		ID:          hotstuff.ID(opts.GetID()),
-		PrivateKey:  privKey,
-		TLS:         opts.GetUseTLS(),
-		Certificate: &certificate,
-		RootCAs:     rootCAs,
-		Locations:   opts.GetLocations(),
-		BatchSize:   opts.GetBatchSize(),
+		ID:                 hotstuff.ID(opts.GetID()),
+		PrivateKey:         privKey,
+		TLS:                opts.GetUseTLS(),
+		Certificate:        &certificate,
+		RootCAs:            rootCAs,
+		Locations:          opts.GetLocations(),
+		BatchSize:          opts.GetBatchSize(),
+		FairOrdering:       opts.GetFairOrdering(),
+		MaxBatchBytes:      opts.GetMaxBatchBytes(),
+		MaxBatchDelay:      opts.GetMaxBatchDelay().AsDuration(),
+		AdaptiveBatching:   opts.GetAdaptiveBatching(),
+		ClientWindow:       opts.GetClientWindow(),
+		VotingPower:        votingPower(opts.GetVotingPower()),
+		Evidence:           evidencePool(opts.GetEvidence()),
+		Mempool:            opts.GetMempool(),
+		ThresholdKeyShare:  thresholdKey,
+		ThresholdPublicKey: thresholdPub,
+		SigningKeys:        signingKeys,
 		ManagerOptions: []gorums.ManagerOption{
 			gorums.WithDialTimeout(opts.GetConnectTimeout().AsDuration()),
 		},
@@ -250,6 +322,52 @@ func (w *Worker) createReplica(opts *orchestrationpb.ReplicaOpts) (*replica.Repl
 	return replica.New(c, builder), nil
 }
 
+// votingPower returns the voting power of each replica, given in order of ID.
+func votingPower(power []uint64) map[hotstuff.ID]uint64 {
+	if len(power) == 0 {
+		return nil
+	}
+	m := make(map[hotstuff.ID]uint64, len(power))
+	for i, p := range power {
+		m[hotstuff.ID(i+1)] = p
+	}
+	return m
+}
+
+// checkFairOrdering returns an error if fair ordering is used without threshold signatures.
+// The order of the commands in a block is derived from the signature of its certificate,
+// which must be the same for every quorum, see replica.Config.FairOrdering.
+func checkFairOrdering(opts *orchestrationpb.ReplicaOpts) error {
+	if opts.GetFairOrdering() && opts.GetCrypto() != "bls12-threshold" {
+		return fmt.Errorf("fair ordering requires the bls12-threshold crypto, not '%s'", opts.GetCrypto())
+	}
+	return nil
+}
+
+// evidencePool returns a new evidence pool if evidence is enabled, and nil otherwise.
+func evidencePool(enabled bool) *evidence.Pool {
+	if !enabled {
+		return nil
+	}
+	return evidence.New(evidence.DefaultWindow)
+}
+
+// parseSigningKeys returns the keys for threshold signatures, or nil if they are not set.
+func parseSigningKeys(opts *orchestrationpb.ReplicaOpts) (*bls12.SigningKeys, error) {
+	if len(opts.GetSigningKeyShare()) == 0 {
+		return nil, nil
+	}
+	share, err := keygen.ParseThresholdKeyShare(opts.GetSigningKeyShare())
+	if err != nil {
+		return nil, err
+	}
+	pub, err := keygen.ParseThresholdPublicKey(opts.GetSigningPublicKey())
+	if err != nil {
+		return nil, err
+	}
+	return &bls12.SigningKeys{Share: share, Public: pub}, nil
+}
+
 // createTree creates a tree based on the given replica options.
 func createTree(replicaOpts *orchestrationpb.ReplicaOpts) tree.Tree {
 	tree := tree.CreateTree(replicaOpts.HotstuffID(), int(replicaOpts.GetBranchFactor()), replicaOpts.TreePositionIDs())
@@ -298,6 +416,7 @@ func (w *Worker) stopReplicas(req *orchestrationpb.StopReplicaRequest) (*orchest
 			return nil, status.Errorf(codes.NotFound, "The replica with id %d was not found.", id)
 		}
 		r.Stop()
+		w.syncs.remove(hotstuff.ID(id))
 		res.Hashes[id] = r.GetHash()
 		res.Counts[id] = r.GetCmdCount()
 		// TODO: return test results
@@ -326,6 +445,13 @@ func (w *Worker) startClients(req *orchestrationpb.StartClientRequest) (*orchest
 			RateStepInterval: opts.GetRateStepInterval().AsDuration(),
 			Timeout:          opts.GetTimeout().AsDuration(),
 		}
+		if len(opts.GetThresholdPublicKey()) > 0 {
+			pub, err := keygen.ParseThresholdPublicKey(opts.GetThresholdPublicKey())
+			if err != nil {
+				return nil, err
+			}
+			c.ThresholdPublicKey = pub
+		}
 		mods := modules.NewBuilder(hotstuff.ID(opts.GetID()), nil)
 		mods.Add(eventloop.New(1000))
 
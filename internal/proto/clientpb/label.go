@@ -0,0 +1,12 @@
+package clientpb
+
+import "encoding/binary"
+
+// EncryptionLabel returns the label that binds an encrypted command to its client ID and sequence number.
+// This prevents a byzantine replica from replaying the ciphertext of a command as a different command.
+func (x *Command) EncryptionLabel() []byte {
+	var label [12]byte
+	binary.LittleEndian.PutUint32(label[:4], x.GetClientID())
+	binary.LittleEndian.PutUint64(label[4:], x.GetSequenceNumber())
+	return label[:]
+}
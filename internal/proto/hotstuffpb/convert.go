@@ -43,6 +43,18 @@ func QuorumSignatureToProto(sig hotstuff.QuorumSignature) *QuorumSignature {
 			Sig:          ms.ToBytes(),
 			Participants: ms.Bitfield().Bytes(),
 		}}
+
+	case *bls12.SignatureShare:
+		signature.Sig = &QuorumSignature_BLS12ThresholdSig{BLS12ThresholdSig: &BLS12ThresholdSignature{
+			Sig:    ms.ToBytes(),
+			Signer: uint32(ms.Signer()),
+		}}
+
+	case *bls12.GroupSignature:
+		signature.Sig = &QuorumSignature_BLS12ThresholdSig{BLS12ThresholdSig: &BLS12ThresholdSignature{
+			Sig:       ms.ToBytes(),
+			Threshold: uint32(ms.Threshold()),
+		}}
 	}
 	return signature
 }
@@ -74,6 +86,20 @@ func QuorumSignatureFromProto(sig *QuorumSignature) hotstuff.QuorumSignature {
 		}
 		return aggSig
 	}
+	if signature := sig.GetBLS12ThresholdSig(); signature != nil {
+		if signature.GetSigner() != 0 {
+			share, err := bls12.RestoreSignatureShare(hotstuff.ID(signature.GetSigner()), signature.GetSig())
+			if err != nil {
+				return nil
+			}
+			return share
+		}
+		groupSig, err := bls12.RestoreGroupSignature(int(signature.GetThreshold()), signature.GetSig())
+		if err != nil {
+			return nil
+		}
+		return groupSig
+	}
 	return nil
 }
 
@@ -118,6 +144,12 @@ func ProposalToProto(proposal hotstuff.ProposeMsg) *Proposal {
 	if proposal.AggregateQC != nil {
 		p.AggQC = AggregateQCToProto(*proposal.AggregateQC)
 	}
+	if proposal.Signature != nil {
+		p.Sig = QuorumSignatureToProto(proposal.Signature)
+	}
+	if proposal.TimeoutCert != nil {
+		p.TC = TimeoutCertToProto(*proposal.TimeoutCert)
+	}
 	return p
 }
 
@@ -128,6 +160,13 @@ func ProposalFromProto(p *Proposal) (proposal hotstuff.ProposeMsg) {
 		aggQC := AggregateQCFromProto(p.GetAggQC())
 		proposal.AggregateQC = &aggQC
 	}
+	if p.GetSig() != nil {
+		proposal.Signature = QuorumSignatureFromProto(p.GetSig())
+	}
+	if p.GetTC() != nil {
+		tc := TimeoutCertFromProto(p.GetTC())
+		proposal.TimeoutCert = &tc
+	}
 	return
 }
 
@@ -141,6 +180,7 @@ func BlockToProto(block *hotstuff.Block) *Block {
 		View:      uint64(block.View()),
 		Proposer:  uint32(block.Proposer()),
 		Timestamp: timestamppb.New(block.Timestamp()),
+		Metadata:  block.Metadata(),
 	}
 }
 
@@ -157,6 +197,9 @@ func BlockFromProto(block *Block) *hotstuff.Block {
 		hotstuff.ID(block.GetProposer()),
 	)
 	b.SetTimestamp(block.Timestamp.AsTime())
+	for key, value := range block.GetMetadata() {
+		b.SetMetadata(key, value)
+	}
 	return b
 }
 
@@ -188,15 +231,29 @@ func TimeoutMsgToProto(timeoutMsg hotstuff.TimeoutMsg) *TimeoutMsg {
 
 // TimeoutCertFromProto converts a timeout certificate from the protobuf type to the hotstuff type.
 func TimeoutCertFromProto(m *TimeoutCert) hotstuff.TimeoutCert {
-	return hotstuff.NewTimeoutCert(QuorumSignatureFromProto(m.GetSig()), hotstuff.View(m.GetView()))
+	if len(m.GetHighQCViews()) == 0 {
+		return hotstuff.NewTimeoutCert(QuorumSignatureFromProto(m.GetSig()), hotstuff.View(m.GetView()))
+	}
+	highQCViews := make(map[hotstuff.ID]hotstuff.View, len(m.GetHighQCViews()))
+	for id, view := range m.GetHighQCViews() {
+		highQCViews[hotstuff.ID(id)] = hotstuff.View(view)
+	}
+	return hotstuff.NewTimeoutCertWithHighQCs(QuorumSignatureFromProto(m.GetSig()), hotstuff.View(m.GetView()), highQCViews)
 }
 
 // TimeoutCertToProto converts a timeout certificate from the hotstuff type to the protobuf type.
 func TimeoutCertToProto(timeoutCert hotstuff.TimeoutCert) *TimeoutCert {
-	return &TimeoutCert{
+	m := &TimeoutCert{
 		View: uint64(timeoutCert.View()),
 		Sig:  QuorumSignatureToProto(timeoutCert.Signature()),
 	}
+	if highQCViews := timeoutCert.HighQCViews(); len(highQCViews) > 0 {
+		m.HighQCViews = make(map[uint32]uint64, len(highQCViews))
+		for id, view := range highQCViews {
+			m.HighQCViews[uint32(id)] = uint64(view)
+		}
+	}
+	return m
 }
 
 // AggregateQCFromProto converts an AggregateQC from the protobuf type to the hotstuff type.
@@ -246,3 +303,131 @@ func SyncInfoToProto(syncInfo hotstuff.SyncInfo) *SyncInfo {
 	}
 	return m
 }
+
+// DecryptionSharesFromProto converts a DecryptionShares message from the protobuf type to the hotstuff type.
+func DecryptionSharesFromProto(m *DecryptionShares) hotstuff.DecryptionShareMsg {
+	var hash hotstuff.Hash
+	copy(hash[:], m.GetBlockHash())
+	return hotstuff.DecryptionShareMsg{
+		BlockHash: hash,
+		Shares:    m.GetShares(),
+	}
+}
+
+// DecryptionSharesToProto converts a DecryptionShareMsg from the hotstuff type to the protobuf type.
+func DecryptionSharesToProto(msg hotstuff.DecryptionShareMsg) *DecryptionShares {
+	return &DecryptionShares{
+		BlockHash: msg.BlockHash[:],
+		Shares:    msg.Shares,
+	}
+}
+
+// EvidenceToProto converts Evidence from the hotstuff type to the protobuf type.
+func EvidenceToProto(evidence hotstuff.Evidence) *Evidence {
+	return &Evidence{
+		Offender:  uint32(evidence.Offender),
+		First:     BlockToProto(evidence.First),
+		FirstSig:  QuorumSignatureToProto(evidence.FirstSignature),
+		Second:    BlockToProto(evidence.Second),
+		SecondSig: QuorumSignatureToProto(evidence.SecondSignature),
+	}
+}
+
+// EvidenceFromProto converts Evidence from the protobuf type to the hotstuff type.
+// The blocks of the evidence are nil if they are missing from the message.
+func EvidenceFromProto(m *Evidence) hotstuff.Evidence {
+	evidence := hotstuff.Evidence{
+		Offender:        hotstuff.ID(m.GetOffender()),
+		FirstSignature:  QuorumSignatureFromProto(m.GetFirstSig()),
+		SecondSignature: QuorumSignatureFromProto(m.GetSecondSig()),
+	}
+	if m.GetFirst() != nil {
+		evidence.First = BlockFromProto(m.GetFirst())
+	}
+	if m.GetSecond() != nil {
+		evidence.Second = BlockFromProto(m.GetSecond())
+	}
+	return evidence
+}
+
+// CheckpointToProto converts a Checkpoint from the hotstuff type to the protobuf type.
+func CheckpointToProto(checkpoint hotstuff.Checkpoint) *Checkpoint {
+	return &Checkpoint{
+		QC:        QuorumCertToProto(checkpoint.QC),
+		StateRoot: checkpoint.StateRoot[:],
+	}
+}
+
+// CheckpointFromProto converts a Checkpoint from the protobuf type to the hotstuff type.
+func CheckpointFromProto(m *Checkpoint) hotstuff.Checkpoint {
+	var root hotstuff.Hash
+	copy(root[:], m.GetStateRoot())
+	return hotstuff.Checkpoint{
+		QC:        QuorumCertFromProto(m.GetQC()),
+		StateRoot: root,
+	}
+}
+
+// MempoolBatchToProto converts a MempoolBatch from the hotstuff type to the protobuf type.
+func MempoolBatchToProto(batch *hotstuff.MempoolBatch) *MempoolBatch {
+	parents := make([][]byte, len(batch.Parents))
+	for i := range batch.Parents {
+		parents[i] = batch.Parents[i][:]
+	}
+	return &MempoolBatch{
+		Author:  uint32(batch.Author),
+		Round:   batch.Round,
+		Parents: parents,
+		Data:    batch.Data,
+	}
+}
+
+// MempoolBatchFromProto converts a MempoolBatch from the protobuf type to the hotstuff type.
+func MempoolBatchFromProto(m *MempoolBatch) *hotstuff.MempoolBatch {
+	parents := make([]hotstuff.Hash, len(m.GetParents()))
+	for i, parent := range m.GetParents() {
+		copy(parents[i][:], parent)
+	}
+	return &hotstuff.MempoolBatch{
+		Author:  hotstuff.ID(m.GetAuthor()),
+		Round:   m.GetRound(),
+		Parents: parents,
+		Data:    m.GetData(),
+	}
+}
+
+// AvailabilityVoteToProto converts an AvailabilityVoteMsg from the hotstuff type to the protobuf type.
+func AvailabilityVoteToProto(vote hotstuff.AvailabilityVoteMsg) *AvailabilityVote {
+	return &AvailabilityVote{
+		Digest: vote.Digest[:],
+		Sig:    QuorumSignatureToProto(vote.Signature),
+	}
+}
+
+// AvailabilityVoteFromProto converts an AvailabilityVote from the protobuf type to the hotstuff type.
+func AvailabilityVoteFromProto(m *AvailabilityVote) hotstuff.AvailabilityVoteMsg {
+	var digest hotstuff.Hash
+	copy(digest[:], m.GetDigest())
+	return hotstuff.AvailabilityVoteMsg{
+		Digest:    digest,
+		Signature: QuorumSignatureFromProto(m.GetSig()),
+	}
+}
+
+// AvailabilityCertToProto converts an AvailabilityCert from the hotstuff type to the protobuf type.
+func AvailabilityCertToProto(cert hotstuff.AvailabilityCert) *AvailabilityCert {
+	digest := cert.Digest()
+	return &AvailabilityCert{
+		Digest: digest[:],
+		Author: uint32(cert.Author()),
+		Round:  cert.Round(),
+		Sig:    QuorumSignatureToProto(cert.Signature()),
+	}
+}
+
+// AvailabilityCertFromProto converts an AvailabilityCert from the protobuf type to the hotstuff type.
+func AvailabilityCertFromProto(m *AvailabilityCert) hotstuff.AvailabilityCert {
+	var digest hotstuff.Hash
+	copy(digest[:], m.GetDigest())
+	return hotstuff.NewAvailabilityCert(QuorumSignatureFromProto(m.GetSig()), digest, hotstuff.ID(m.GetAuthor()), m.GetRound())
+}
@@ -24,9 +24,13 @@ const (
 )
 
 type Proposal struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Block         *Block                 `protobuf:"bytes,1,opt,name=Block,proto3" json:"Block,omitempty"`
-	AggQC         *AggQC                 `protobuf:"bytes,2,opt,name=AggQC,proto3" json:"AggQC,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Block *Block                 `protobuf:"bytes,1,opt,name=Block,proto3" json:"Block,omitempty"`
+	AggQC *AggQC                 `protobuf:"bytes,2,opt,name=AggQC,proto3" json:"AggQC,omitempty"`
+	// The proposer's signature of the block.
+	Sig *QuorumSignature `protobuf:"bytes,3,opt,name=Sig,proto3" json:"Sig,omitempty"`
+	// The timeout certificate of the previous view, used by Jolteon.
+	TC            *TimeoutCert `protobuf:"bytes,4,opt,name=TC,proto3" json:"TC,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -75,6 +79,20 @@ func (x *Proposal) GetAggQC() *AggQC {
 	return nil
 }
 
+func (x *Proposal) GetSig() *QuorumSignature {
+	if x != nil {
+		return x.Sig
+	}
+	return nil
+}
+
+func (x *Proposal) GetTC() *TimeoutCert {
+	if x != nil {
+		return x.TC
+	}
+	return nil
+}
+
 type BlockHash struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Hash          []byte                 `protobuf:"bytes,1,opt,name=Hash,proto3" json:"Hash,omitempty"`
@@ -119,32 +137,31 @@ func (x *BlockHash) GetHash() []byte {
 	return nil
 }
 
-type Block struct {
+// BlockRange requests the blocks with views in [FromView, ToView]
+// on the branch that ends with the block identified by Tip.
+type BlockRange struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Parent        []byte                 `protobuf:"bytes,1,opt,name=Parent,proto3" json:"Parent,omitempty"`
-	QC            *QuorumCert            `protobuf:"bytes,2,opt,name=QC,proto3" json:"QC,omitempty"`
-	View          uint64                 `protobuf:"varint,3,opt,name=View,proto3" json:"View,omitempty"`
-	Command       []byte                 `protobuf:"bytes,4,opt,name=Command,proto3" json:"Command,omitempty"`
-	Proposer      uint32                 `protobuf:"varint,5,opt,name=Proposer,proto3" json:"Proposer,omitempty"`
-	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=Timestamp,proto3" json:"Timestamp,omitempty"`
+	FromView      uint64                 `protobuf:"varint,1,opt,name=FromView,proto3" json:"FromView,omitempty"`
+	ToView        uint64                 `protobuf:"varint,2,opt,name=ToView,proto3" json:"ToView,omitempty"`
+	Tip           []byte                 `protobuf:"bytes,3,opt,name=Tip,proto3" json:"Tip,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Block) Reset() {
-	*x = Block{}
+func (x *BlockRange) Reset() {
+	*x = BlockRange{}
 	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Block) String() string {
+func (x *BlockRange) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Block) ProtoMessage() {}
+func (*BlockRange) ProtoMessage() {}
 
-func (x *Block) ProtoReflect() protoreflect.Message {
+func (x *BlockRange) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -156,76 +173,56 @@ func (x *Block) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Block.ProtoReflect.Descriptor instead.
-func (*Block) Descriptor() ([]byte, []int) {
+// Deprecated: Use BlockRange.ProtoReflect.Descriptor instead.
+func (*BlockRange) Descriptor() ([]byte, []int) {
 	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *Block) GetParent() []byte {
-	if x != nil {
-		return x.Parent
-	}
-	return nil
-}
-
-func (x *Block) GetQC() *QuorumCert {
-	if x != nil {
-		return x.QC
-	}
-	return nil
-}
-
-func (x *Block) GetView() uint64 {
+func (x *BlockRange) GetFromView() uint64 {
 	if x != nil {
-		return x.View
+		return x.FromView
 	}
 	return 0
 }
 
-func (x *Block) GetCommand() []byte {
-	if x != nil {
-		return x.Command
-	}
-	return nil
-}
-
-func (x *Block) GetProposer() uint32 {
+func (x *BlockRange) GetToView() uint64 {
 	if x != nil {
-		return x.Proposer
+		return x.ToView
 	}
 	return 0
 }
 
-func (x *Block) GetTimestamp() *timestamppb.Timestamp {
+func (x *BlockRange) GetTip() []byte {
 	if x != nil {
-		return x.Timestamp
+		return x.Tip
 	}
 	return nil
 }
 
-type ECDSASignature struct {
+// Blocks is a part of the stream of blocks sent in response to FetchRange.
+// Last is set on the final part of the stream.
+type Blocks struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Signer        uint32                 `protobuf:"varint,1,opt,name=Signer,proto3" json:"Signer,omitempty"`
-	R             []byte                 `protobuf:"bytes,2,opt,name=R,proto3" json:"R,omitempty"`
-	S             []byte                 `protobuf:"bytes,3,opt,name=S,proto3" json:"S,omitempty"`
+	Blocks        []*Block               `protobuf:"bytes,1,rep,name=Blocks,proto3" json:"Blocks,omitempty"`
+	Last          bool                   `protobuf:"varint,2,opt,name=Last,proto3" json:"Last,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ECDSASignature) Reset() {
-	*x = ECDSASignature{}
+func (x *Blocks) Reset() {
+	*x = Blocks{}
 	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ECDSASignature) String() string {
+func (x *Blocks) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ECDSASignature) ProtoMessage() {}
+func (*Blocks) ProtoMessage() {}
 
-func (x *ECDSASignature) ProtoReflect() protoreflect.Message {
+func (x *Blocks) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -237,53 +234,48 @@ func (x *ECDSASignature) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ECDSASignature.ProtoReflect.Descriptor instead.
-func (*ECDSASignature) Descriptor() ([]byte, []int) {
+// Deprecated: Use Blocks.ProtoReflect.Descriptor instead.
+func (*Blocks) Descriptor() ([]byte, []int) {
 	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *ECDSASignature) GetSigner() uint32 {
-	if x != nil {
-		return x.Signer
-	}
-	return 0
-}
-
-func (x *ECDSASignature) GetR() []byte {
+func (x *Blocks) GetBlocks() []*Block {
 	if x != nil {
-		return x.R
+		return x.Blocks
 	}
 	return nil
 }
 
-func (x *ECDSASignature) GetS() []byte {
+func (x *Blocks) GetLast() bool {
 	if x != nil {
-		return x.S
+		return x.Last
 	}
-	return nil
+	return false
 }
 
-type BLS12Signature struct {
+// CheckpointRequest requests the checkpoint of the block identified by Hash.
+// If Hash is empty, the latest checkpoint is requested.
+type CheckpointRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Sig           []byte                 `protobuf:"bytes,1,opt,name=Sig,proto3" json:"Sig,omitempty"`
+	Hash          []byte                 `protobuf:"bytes,1,opt,name=Hash,proto3" json:"Hash,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *BLS12Signature) Reset() {
-	*x = BLS12Signature{}
+func (x *CheckpointRequest) Reset() {
+	*x = CheckpointRequest{}
 	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *BLS12Signature) String() string {
+func (x *CheckpointRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BLS12Signature) ProtoMessage() {}
+func (*CheckpointRequest) ProtoMessage() {}
 
-func (x *BLS12Signature) ProtoReflect() protoreflect.Message {
+func (x *CheckpointRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -295,40 +287,41 @@ func (x *BLS12Signature) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BLS12Signature.ProtoReflect.Descriptor instead.
-func (*BLS12Signature) Descriptor() ([]byte, []int) {
+// Deprecated: Use CheckpointRequest.ProtoReflect.Descriptor instead.
+func (*CheckpointRequest) Descriptor() ([]byte, []int) {
 	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *BLS12Signature) GetSig() []byte {
+func (x *CheckpointRequest) GetHash() []byte {
 	if x != nil {
-		return x.Sig
+		return x.Hash
 	}
 	return nil
 }
 
-type EDDSASignature struct {
+// Checkpoint is the state root after executing the block certified by QC.
+type Checkpoint struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Signer        uint32                 `protobuf:"varint,1,opt,name=Signer,proto3" json:"Signer,omitempty"`
-	Sig           []byte                 `protobuf:"bytes,2,opt,name=Sig,proto3" json:"Sig,omitempty"`
+	QC            *QuorumCert            `protobuf:"bytes,1,opt,name=QC,proto3" json:"QC,omitempty"`
+	StateRoot     []byte                 `protobuf:"bytes,2,opt,name=StateRoot,proto3" json:"StateRoot,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *EDDSASignature) Reset() {
-	*x = EDDSASignature{}
+func (x *Checkpoint) Reset() {
+	*x = Checkpoint{}
 	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *EDDSASignature) String() string {
+func (x *Checkpoint) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*EDDSASignature) ProtoMessage() {}
+func (*Checkpoint) ProtoMessage() {}
 
-func (x *EDDSASignature) ProtoReflect() protoreflect.Message {
+func (x *Checkpoint) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -340,51 +333,47 @@ func (x *EDDSASignature) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use EDDSASignature.ProtoReflect.Descriptor instead.
-func (*EDDSASignature) Descriptor() ([]byte, []int) {
+// Deprecated: Use Checkpoint.ProtoReflect.Descriptor instead.
+func (*Checkpoint) Descriptor() ([]byte, []int) {
 	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *EDDSASignature) GetSigner() uint32 {
+func (x *Checkpoint) GetQC() *QuorumCert {
 	if x != nil {
-		return x.Signer
+		return x.QC
 	}
-	return 0
+	return nil
 }
 
-func (x *EDDSASignature) GetSig() []byte {
+func (x *Checkpoint) GetStateRoot() []byte {
 	if x != nil {
-		return x.Sig
+		return x.StateRoot
 	}
 	return nil
 }
 
-type Signature struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Types that are valid to be assigned to Sig:
-	//
-	//	*Signature_ECDSASig
-	//	*Signature_BLS12Sig
-	//	*Signature_EDDSASig
-	Sig           isSignature_Sig `protobuf_oneof:"Sig"`
+// TrieNodesRequest requests the encoded trie nodes with the given hashes.
+type TrieNodesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hashes        [][]byte               `protobuf:"bytes,1,rep,name=Hashes,proto3" json:"Hashes,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Signature) Reset() {
-	*x = Signature{}
+func (x *TrieNodesRequest) Reset() {
+	*x = TrieNodesRequest{}
 	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Signature) String() string {
+func (x *TrieNodesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Signature) ProtoMessage() {}
+func (*TrieNodesRequest) ProtoMessage() {}
 
-func (x *Signature) ProtoReflect() protoreflect.Message {
+func (x *TrieNodesRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -396,89 +385,40 @@ func (x *Signature) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Signature.ProtoReflect.Descriptor instead.
-func (*Signature) Descriptor() ([]byte, []int) {
+// Deprecated: Use TrieNodesRequest.ProtoReflect.Descriptor instead.
+func (*TrieNodesRequest) Descriptor() ([]byte, []int) {
 	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *Signature) GetSig() isSignature_Sig {
-	if x != nil {
-		return x.Sig
-	}
-	return nil
-}
-
-func (x *Signature) GetECDSASig() *ECDSASignature {
-	if x != nil {
-		if x, ok := x.Sig.(*Signature_ECDSASig); ok {
-			return x.ECDSASig
-		}
-	}
-	return nil
-}
-
-func (x *Signature) GetBLS12Sig() *BLS12Signature {
-	if x != nil {
-		if x, ok := x.Sig.(*Signature_BLS12Sig); ok {
-			return x.BLS12Sig
-		}
-	}
-	return nil
-}
-
-func (x *Signature) GetEDDSASig() *EDDSASignature {
+func (x *TrieNodesRequest) GetHashes() [][]byte {
 	if x != nil {
-		if x, ok := x.Sig.(*Signature_EDDSASig); ok {
-			return x.EDDSASig
-		}
+		return x.Hashes
 	}
 	return nil
 }
 
-type isSignature_Sig interface {
-	isSignature_Sig()
-}
-
-type Signature_ECDSASig struct {
-	ECDSASig *ECDSASignature `protobuf:"bytes,1,opt,name=ECDSASig,proto3,oneof"`
-}
-
-type Signature_BLS12Sig struct {
-	BLS12Sig *BLS12Signature `protobuf:"bytes,2,opt,name=BLS12Sig,proto3,oneof"`
-}
-
-type Signature_EDDSASig struct {
-	EDDSASig *EDDSASignature `protobuf:"bytes,3,opt,name=EDDSASig,proto3,oneof"`
-}
-
-func (*Signature_ECDSASig) isSignature_Sig() {}
-
-func (*Signature_BLS12Sig) isSignature_Sig() {}
-
-func (*Signature_EDDSASig) isSignature_Sig() {}
-
-type PartialCert struct {
+// TrieNodes contains the encoded trie nodes that were found.
+type TrieNodes struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Sig           *QuorumSignature       `protobuf:"bytes,1,opt,name=Sig,proto3" json:"Sig,omitempty"`
-	Hash          []byte                 `protobuf:"bytes,2,opt,name=Hash,proto3" json:"Hash,omitempty"`
+	Nodes         [][]byte               `protobuf:"bytes,1,rep,name=Nodes,proto3" json:"Nodes,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PartialCert) Reset() {
-	*x = PartialCert{}
+func (x *TrieNodes) Reset() {
+	*x = TrieNodes{}
 	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PartialCert) String() string {
+func (x *TrieNodes) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PartialCert) ProtoMessage() {}
+func (*TrieNodes) ProtoMessage() {}
 
-func (x *PartialCert) ProtoReflect() protoreflect.Message {
+func (x *TrieNodes) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -490,46 +430,45 @@ func (x *PartialCert) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PartialCert.ProtoReflect.Descriptor instead.
-func (*PartialCert) Descriptor() ([]byte, []int) {
+// Deprecated: Use TrieNodes.ProtoReflect.Descriptor instead.
+func (*TrieNodes) Descriptor() ([]byte, []int) {
 	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *PartialCert) GetSig() *QuorumSignature {
-	if x != nil {
-		return x.Sig
-	}
-	return nil
-}
-
-func (x *PartialCert) GetHash() []byte {
+func (x *TrieNodes) GetNodes() [][]byte {
 	if x != nil {
-		return x.Hash
+		return x.Nodes
 	}
 	return nil
 }
 
-type ECDSAMultiSignature struct {
+type Block struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Sigs          []*ECDSASignature      `protobuf:"bytes,1,rep,name=Sigs,proto3" json:"Sigs,omitempty"`
+	Parent        []byte                 `protobuf:"bytes,1,opt,name=Parent,proto3" json:"Parent,omitempty"`
+	QC            *QuorumCert            `protobuf:"bytes,2,opt,name=QC,proto3" json:"QC,omitempty"`
+	View          uint64                 `protobuf:"varint,3,opt,name=View,proto3" json:"View,omitempty"`
+	Command       []byte                 `protobuf:"bytes,4,opt,name=Command,proto3" json:"Command,omitempty"`
+	Proposer      uint32                 `protobuf:"varint,5,opt,name=Proposer,proto3" json:"Proposer,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=Timestamp,proto3" json:"Timestamp,omitempty"`
+	Metadata      map[string][]byte      `protobuf:"bytes,7,rep,name=Metadata,proto3" json:"Metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ECDSAMultiSignature) Reset() {
-	*x = ECDSAMultiSignature{}
+func (x *Block) Reset() {
+	*x = Block{}
 	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ECDSAMultiSignature) String() string {
+func (x *Block) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ECDSAMultiSignature) ProtoMessage() {}
+func (*Block) ProtoMessage() {}
 
-func (x *ECDSAMultiSignature) ProtoReflect() protoreflect.Message {
+func (x *Block) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -541,43 +480,87 @@ func (x *ECDSAMultiSignature) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ECDSAMultiSignature.ProtoReflect.Descriptor instead.
-func (*ECDSAMultiSignature) Descriptor() ([]byte, []int) {
+// Deprecated: Use Block.ProtoReflect.Descriptor instead.
+func (*Block) Descriptor() ([]byte, []int) {
 	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *ECDSAMultiSignature) GetSigs() []*ECDSASignature {
+func (x *Block) GetParent() []byte {
 	if x != nil {
-		return x.Sigs
+		return x.Parent
 	}
 	return nil
 }
 
-type EDDSAMultiSignature struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Sigs          []*EDDSASignature      `protobuf:"bytes,1,rep,name=Sigs,proto3" json:"Sigs,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *Block) GetQC() *QuorumCert {
+	if x != nil {
+		return x.QC
+	}
+	return nil
 }
 
-func (x *EDDSAMultiSignature) Reset() {
-	*x = EDDSAMultiSignature{}
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[9]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *Block) GetView() uint64 {
+	if x != nil {
+		return x.View
+	}
+	return 0
 }
 
-func (x *EDDSAMultiSignature) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *Block) GetCommand() []byte {
+	if x != nil {
+		return x.Command
+	}
+	return nil
 }
 
-func (*EDDSAMultiSignature) ProtoMessage() {}
-
-func (x *EDDSAMultiSignature) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[9]
+func (x *Block) GetProposer() uint32 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
+		return x.Proposer
+	}
+	return 0
+}
+
+func (x *Block) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Block) GetMetadata() map[string][]byte {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type ECDSASignature struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Signer        uint32                 `protobuf:"varint,1,opt,name=Signer,proto3" json:"Signer,omitempty"`
+	R             []byte                 `protobuf:"bytes,2,opt,name=R,proto3" json:"R,omitempty"`
+	S             []byte                 `protobuf:"bytes,3,opt,name=S,proto3" json:"S,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ECDSASignature) Reset() {
+	*x = ECDSASignature{}
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ECDSASignature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ECDSASignature) ProtoMessage() {}
+
+func (x *ECDSASignature) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
 		}
 		return ms
@@ -585,41 +568,867 @@ func (x *EDDSAMultiSignature) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use EDDSAMultiSignature.ProtoReflect.Descriptor instead.
-func (*EDDSAMultiSignature) Descriptor() ([]byte, []int) {
+// Deprecated: Use ECDSASignature.ProtoReflect.Descriptor instead.
+func (*ECDSASignature) Descriptor() ([]byte, []int) {
 	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *EDDSAMultiSignature) GetSigs() []*EDDSASignature {
+func (x *ECDSASignature) GetSigner() uint32 {
 	if x != nil {
-		return x.Sigs
+		return x.Signer
+	}
+	return 0
+}
+
+func (x *ECDSASignature) GetR() []byte {
+	if x != nil {
+		return x.R
 	}
 	return nil
 }
 
-type BLS12AggregateSignature struct {
+func (x *ECDSASignature) GetS() []byte {
+	if x != nil {
+		return x.S
+	}
+	return nil
+}
+
+type BLS12Signature struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Sig           []byte                 `protobuf:"bytes,1,opt,name=Sig,proto3" json:"Sig,omitempty"`
-	Participants  []byte                 `protobuf:"bytes,2,opt,name=participants,proto3" json:"participants,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *BLS12AggregateSignature) Reset() {
-	*x = BLS12AggregateSignature{}
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[10]
+func (x *BLS12Signature) Reset() {
+	*x = BLS12Signature{}
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BLS12Signature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BLS12Signature) ProtoMessage() {}
+
+func (x *BLS12Signature) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BLS12Signature.ProtoReflect.Descriptor instead.
+func (*BLS12Signature) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *BLS12Signature) GetSig() []byte {
+	if x != nil {
+		return x.Sig
+	}
+	return nil
+}
+
+type EDDSASignature struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Signer        uint32                 `protobuf:"varint,1,opt,name=Signer,proto3" json:"Signer,omitempty"`
+	Sig           []byte                 `protobuf:"bytes,2,opt,name=Sig,proto3" json:"Sig,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EDDSASignature) Reset() {
+	*x = EDDSASignature{}
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EDDSASignature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EDDSASignature) ProtoMessage() {}
+
+func (x *EDDSASignature) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EDDSASignature.ProtoReflect.Descriptor instead.
+func (*EDDSASignature) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *EDDSASignature) GetSigner() uint32 {
+	if x != nil {
+		return x.Signer
+	}
+	return 0
+}
+
+func (x *EDDSASignature) GetSig() []byte {
+	if x != nil {
+		return x.Sig
+	}
+	return nil
+}
+
+type Signature struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Sig:
+	//
+	//	*Signature_ECDSASig
+	//	*Signature_BLS12Sig
+	//	*Signature_EDDSASig
+	Sig           isSignature_Sig `protobuf_oneof:"Sig"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Signature) Reset() {
+	*x = Signature{}
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Signature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Signature) ProtoMessage() {}
+
+func (x *Signature) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Signature.ProtoReflect.Descriptor instead.
+func (*Signature) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Signature) GetSig() isSignature_Sig {
+	if x != nil {
+		return x.Sig
+	}
+	return nil
+}
+
+func (x *Signature) GetECDSASig() *ECDSASignature {
+	if x != nil {
+		if x, ok := x.Sig.(*Signature_ECDSASig); ok {
+			return x.ECDSASig
+		}
+	}
+	return nil
+}
+
+func (x *Signature) GetBLS12Sig() *BLS12Signature {
+	if x != nil {
+		if x, ok := x.Sig.(*Signature_BLS12Sig); ok {
+			return x.BLS12Sig
+		}
+	}
+	return nil
+}
+
+func (x *Signature) GetEDDSASig() *EDDSASignature {
+	if x != nil {
+		if x, ok := x.Sig.(*Signature_EDDSASig); ok {
+			return x.EDDSASig
+		}
+	}
+	return nil
+}
+
+type isSignature_Sig interface {
+	isSignature_Sig()
+}
+
+type Signature_ECDSASig struct {
+	ECDSASig *ECDSASignature `protobuf:"bytes,1,opt,name=ECDSASig,proto3,oneof"`
+}
+
+type Signature_BLS12Sig struct {
+	BLS12Sig *BLS12Signature `protobuf:"bytes,2,opt,name=BLS12Sig,proto3,oneof"`
+}
+
+type Signature_EDDSASig struct {
+	EDDSASig *EDDSASignature `protobuf:"bytes,3,opt,name=EDDSASig,proto3,oneof"`
+}
+
+func (*Signature_ECDSASig) isSignature_Sig() {}
+
+func (*Signature_BLS12Sig) isSignature_Sig() {}
+
+func (*Signature_EDDSASig) isSignature_Sig() {}
+
+type PartialCert struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sig           *QuorumSignature       `protobuf:"bytes,1,opt,name=Sig,proto3" json:"Sig,omitempty"`
+	Hash          []byte                 `protobuf:"bytes,2,opt,name=Hash,proto3" json:"Hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PartialCert) Reset() {
+	*x = PartialCert{}
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PartialCert) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PartialCert) ProtoMessage() {}
+
+func (x *PartialCert) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PartialCert.ProtoReflect.Descriptor instead.
+func (*PartialCert) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *PartialCert) GetSig() *QuorumSignature {
+	if x != nil {
+		return x.Sig
+	}
+	return nil
+}
+
+func (x *PartialCert) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+type ECDSAMultiSignature struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sigs          []*ECDSASignature      `protobuf:"bytes,1,rep,name=Sigs,proto3" json:"Sigs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ECDSAMultiSignature) Reset() {
+	*x = ECDSAMultiSignature{}
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ECDSAMultiSignature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ECDSAMultiSignature) ProtoMessage() {}
+
+func (x *ECDSAMultiSignature) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ECDSAMultiSignature.ProtoReflect.Descriptor instead.
+func (*ECDSAMultiSignature) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ECDSAMultiSignature) GetSigs() []*ECDSASignature {
+	if x != nil {
+		return x.Sigs
+	}
+	return nil
+}
+
+type EDDSAMultiSignature struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sigs          []*EDDSASignature      `protobuf:"bytes,1,rep,name=Sigs,proto3" json:"Sigs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EDDSAMultiSignature) Reset() {
+	*x = EDDSAMultiSignature{}
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EDDSAMultiSignature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EDDSAMultiSignature) ProtoMessage() {}
+
+func (x *EDDSAMultiSignature) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EDDSAMultiSignature.ProtoReflect.Descriptor instead.
+func (*EDDSAMultiSignature) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *EDDSAMultiSignature) GetSigs() []*EDDSASignature {
+	if x != nil {
+		return x.Sigs
+	}
+	return nil
+}
+
+type BLS12AggregateSignature struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sig           []byte                 `protobuf:"bytes,1,opt,name=Sig,proto3" json:"Sig,omitempty"`
+	Participants  []byte                 `protobuf:"bytes,2,opt,name=participants,proto3" json:"participants,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BLS12AggregateSignature) Reset() {
+	*x = BLS12AggregateSignature{}
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BLS12AggregateSignature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BLS12AggregateSignature) ProtoMessage() {}
+
+func (x *BLS12AggregateSignature) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BLS12AggregateSignature.ProtoReflect.Descriptor instead.
+func (*BLS12AggregateSignature) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *BLS12AggregateSignature) GetSig() []byte {
+	if x != nil {
+		return x.Sig
+	}
+	return nil
+}
+
+func (x *BLS12AggregateSignature) GetParticipants() []byte {
+	if x != nil {
+		return x.Participants
+	}
+	return nil
+}
+
+// BLS12ThresholdSignature is either a signature share, which has a signer,
+// or a threshold signature, which has no signer and is combined from a threshold number of shares.
+type BLS12ThresholdSignature struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sig           []byte                 `protobuf:"bytes,1,opt,name=Sig,proto3" json:"Sig,omitempty"`
+	Signer        uint32                 `protobuf:"varint,2,opt,name=Signer,proto3" json:"Signer,omitempty"`
+	Threshold     uint32                 `protobuf:"varint,3,opt,name=Threshold,proto3" json:"Threshold,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BLS12ThresholdSignature) Reset() {
+	*x = BLS12ThresholdSignature{}
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BLS12ThresholdSignature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BLS12ThresholdSignature) ProtoMessage() {}
+
+func (x *BLS12ThresholdSignature) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BLS12ThresholdSignature.ProtoReflect.Descriptor instead.
+func (*BLS12ThresholdSignature) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *BLS12ThresholdSignature) GetSig() []byte {
+	if x != nil {
+		return x.Sig
+	}
+	return nil
+}
+
+func (x *BLS12ThresholdSignature) GetSigner() uint32 {
+	if x != nil {
+		return x.Signer
+	}
+	return 0
+}
+
+func (x *BLS12ThresholdSignature) GetThreshold() uint32 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+type QuorumSignature struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Sig:
+	//
+	//	*QuorumSignature_ECDSASigs
+	//	*QuorumSignature_BLS12Sig
+	//	*QuorumSignature_EDDSASigs
+	//	*QuorumSignature_BLS12ThresholdSig
+	Sig           isQuorumSignature_Sig `protobuf_oneof:"Sig"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuorumSignature) Reset() {
+	*x = QuorumSignature{}
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuorumSignature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuorumSignature) ProtoMessage() {}
+
+func (x *QuorumSignature) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuorumSignature.ProtoReflect.Descriptor instead.
+func (*QuorumSignature) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *QuorumSignature) GetSig() isQuorumSignature_Sig {
+	if x != nil {
+		return x.Sig
+	}
+	return nil
+}
+
+func (x *QuorumSignature) GetECDSASigs() *ECDSAMultiSignature {
+	if x != nil {
+		if x, ok := x.Sig.(*QuorumSignature_ECDSASigs); ok {
+			return x.ECDSASigs
+		}
+	}
+	return nil
+}
+
+func (x *QuorumSignature) GetBLS12Sig() *BLS12AggregateSignature {
+	if x != nil {
+		if x, ok := x.Sig.(*QuorumSignature_BLS12Sig); ok {
+			return x.BLS12Sig
+		}
+	}
+	return nil
+}
+
+func (x *QuorumSignature) GetEDDSASigs() *EDDSAMultiSignature {
+	if x != nil {
+		if x, ok := x.Sig.(*QuorumSignature_EDDSASigs); ok {
+			return x.EDDSASigs
+		}
+	}
+	return nil
+}
+
+func (x *QuorumSignature) GetBLS12ThresholdSig() *BLS12ThresholdSignature {
+	if x != nil {
+		if x, ok := x.Sig.(*QuorumSignature_BLS12ThresholdSig); ok {
+			return x.BLS12ThresholdSig
+		}
+	}
+	return nil
+}
+
+type isQuorumSignature_Sig interface {
+	isQuorumSignature_Sig()
+}
+
+type QuorumSignature_ECDSASigs struct {
+	ECDSASigs *ECDSAMultiSignature `protobuf:"bytes,1,opt,name=ECDSASigs,proto3,oneof"`
+}
+
+type QuorumSignature_BLS12Sig struct {
+	BLS12Sig *BLS12AggregateSignature `protobuf:"bytes,2,opt,name=BLS12Sig,proto3,oneof"`
+}
+
+type QuorumSignature_EDDSASigs struct {
+	EDDSASigs *EDDSAMultiSignature `protobuf:"bytes,3,opt,name=EDDSASigs,proto3,oneof"`
+}
+
+type QuorumSignature_BLS12ThresholdSig struct {
+	BLS12ThresholdSig *BLS12ThresholdSignature `protobuf:"bytes,4,opt,name=BLS12ThresholdSig,proto3,oneof"`
+}
+
+func (*QuorumSignature_ECDSASigs) isQuorumSignature_Sig() {}
+
+func (*QuorumSignature_BLS12Sig) isQuorumSignature_Sig() {}
+
+func (*QuorumSignature_EDDSASigs) isQuorumSignature_Sig() {}
+
+func (*QuorumSignature_BLS12ThresholdSig) isQuorumSignature_Sig() {}
+
+type QuorumCert struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sig           *QuorumSignature       `protobuf:"bytes,1,opt,name=Sig,proto3" json:"Sig,omitempty"`
+	View          uint64                 `protobuf:"varint,2,opt,name=View,proto3" json:"View,omitempty"`
+	Hash          []byte                 `protobuf:"bytes,3,opt,name=Hash,proto3" json:"Hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuorumCert) Reset() {
+	*x = QuorumCert{}
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuorumCert) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuorumCert) ProtoMessage() {}
+
+func (x *QuorumCert) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuorumCert.ProtoReflect.Descriptor instead.
+func (*QuorumCert) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *QuorumCert) GetSig() *QuorumSignature {
+	if x != nil {
+		return x.Sig
+	}
+	return nil
+}
+
+func (x *QuorumCert) GetView() uint64 {
+	if x != nil {
+		return x.View
+	}
+	return 0
+}
+
+func (x *QuorumCert) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+type TimeoutCert struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Sig   *QuorumSignature       `protobuf:"bytes,1,opt,name=Sig,proto3" json:"Sig,omitempty"`
+	View  uint64                 `protobuf:"varint,2,opt,name=View,proto3" json:"View,omitempty"`
+	// The view of the high QC of each signer, used by Jolteon.
+	HighQCViews   map[uint32]uint64 `protobuf:"bytes,3,rep,name=HighQCViews,proto3" json:"HighQCViews,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TimeoutCert) Reset() {
+	*x = TimeoutCert{}
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TimeoutCert) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimeoutCert) ProtoMessage() {}
+
+func (x *TimeoutCert) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimeoutCert.ProtoReflect.Descriptor instead.
+func (*TimeoutCert) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *TimeoutCert) GetSig() *QuorumSignature {
+	if x != nil {
+		return x.Sig
+	}
+	return nil
+}
+
+func (x *TimeoutCert) GetView() uint64 {
+	if x != nil {
+		return x.View
+	}
+	return 0
+}
+
+func (x *TimeoutCert) GetHighQCViews() map[uint32]uint64 {
+	if x != nil {
+		return x.HighQCViews
+	}
+	return nil
+}
+
+type TimeoutMsg struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	View          uint64                 `protobuf:"varint,1,opt,name=View,proto3" json:"View,omitempty"`
+	SyncInfo      *SyncInfo              `protobuf:"bytes,2,opt,name=SyncInfo,proto3" json:"SyncInfo,omitempty"`
+	ViewSig       *QuorumSignature       `protobuf:"bytes,3,opt,name=ViewSig,proto3" json:"ViewSig,omitempty"`
+	MsgSig        *QuorumSignature       `protobuf:"bytes,4,opt,name=MsgSig,proto3" json:"MsgSig,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TimeoutMsg) Reset() {
+	*x = TimeoutMsg{}
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TimeoutMsg) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimeoutMsg) ProtoMessage() {}
+
+func (x *TimeoutMsg) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimeoutMsg.ProtoReflect.Descriptor instead.
+func (*TimeoutMsg) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *TimeoutMsg) GetView() uint64 {
+	if x != nil {
+		return x.View
+	}
+	return 0
+}
+
+func (x *TimeoutMsg) GetSyncInfo() *SyncInfo {
+	if x != nil {
+		return x.SyncInfo
+	}
+	return nil
+}
+
+func (x *TimeoutMsg) GetViewSig() *QuorumSignature {
+	if x != nil {
+		return x.ViewSig
+	}
+	return nil
+}
+
+func (x *TimeoutMsg) GetMsgSig() *QuorumSignature {
+	if x != nil {
+		return x.MsgSig
+	}
+	return nil
+}
+
+type SyncInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	QC            *QuorumCert            `protobuf:"bytes,1,opt,name=QC,proto3" json:"QC,omitempty"`
+	TC            *TimeoutCert           `protobuf:"bytes,2,opt,name=TC,proto3" json:"TC,omitempty"`
+	AggQC         *AggQC                 `protobuf:"bytes,3,opt,name=AggQC,proto3" json:"AggQC,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncInfo) Reset() {
+	*x = SyncInfo{}
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncInfo) ProtoMessage() {}
+
+func (x *SyncInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncInfo.ProtoReflect.Descriptor instead.
+func (*SyncInfo) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SyncInfo) GetQC() *QuorumCert {
+	if x != nil {
+		return x.QC
+	}
+	return nil
+}
+
+func (x *SyncInfo) GetTC() *TimeoutCert {
+	if x != nil {
+		return x.TC
+	}
+	return nil
+}
+
+func (x *SyncInfo) GetAggQC() *AggQC {
+	if x != nil {
+		return x.AggQC
+	}
+	return nil
+}
+
+type AggQC struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	QCs           map[uint32]*QuorumCert `protobuf:"bytes,1,rep,name=QCs,proto3" json:"QCs,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Sig           *QuorumSignature       `protobuf:"bytes,2,opt,name=Sig,proto3" json:"Sig,omitempty"`
+	View          uint64                 `protobuf:"varint,3,opt,name=View,proto3" json:"View,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AggQC) Reset() {
+	*x = AggQC{}
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *BLS12AggregateSignature) String() string {
+func (x *AggQC) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BLS12AggregateSignature) ProtoMessage() {}
+func (*AggQC) ProtoMessage() {}
 
-func (x *BLS12AggregateSignature) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[10]
+func (x *AggQC) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -630,52 +1439,59 @@ func (x *BLS12AggregateSignature) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BLS12AggregateSignature.ProtoReflect.Descriptor instead.
-func (*BLS12AggregateSignature) Descriptor() ([]byte, []int) {
-	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use AggQC.ProtoReflect.Descriptor instead.
+func (*AggQC) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{23}
 }
 
-func (x *BLS12AggregateSignature) GetSig() []byte {
+func (x *AggQC) GetQCs() map[uint32]*QuorumCert {
 	if x != nil {
-		return x.Sig
+		return x.QCs
 	}
 	return nil
 }
 
-func (x *BLS12AggregateSignature) GetParticipants() []byte {
+func (x *AggQC) GetSig() *QuorumSignature {
 	if x != nil {
-		return x.Participants
+		return x.Sig
 	}
 	return nil
 }
 
-type QuorumSignature struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Types that are valid to be assigned to Sig:
-	//
-	//	*QuorumSignature_ECDSASigs
-	//	*QuorumSignature_BLS12Sig
-	//	*QuorumSignature_EDDSASigs
-	Sig           isQuorumSignature_Sig `protobuf_oneof:"Sig"`
+func (x *AggQC) GetView() uint64 {
+	if x != nil {
+		return x.View
+	}
+	return 0
+}
+
+// Evidence proves that the offender signed two different blocks in the same view.
+type Evidence struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Offender      uint32                 `protobuf:"varint,1,opt,name=Offender,proto3" json:"Offender,omitempty"`
+	First         *Block                 `protobuf:"bytes,2,opt,name=First,proto3" json:"First,omitempty"`
+	FirstSig      *QuorumSignature       `protobuf:"bytes,3,opt,name=FirstSig,proto3" json:"FirstSig,omitempty"`
+	Second        *Block                 `protobuf:"bytes,4,opt,name=Second,proto3" json:"Second,omitempty"`
+	SecondSig     *QuorumSignature       `protobuf:"bytes,5,opt,name=SecondSig,proto3" json:"SecondSig,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *QuorumSignature) Reset() {
-	*x = QuorumSignature{}
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[11]
+func (x *Evidence) Reset() {
+	*x = Evidence{}
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *QuorumSignature) String() string {
+func (x *Evidence) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*QuorumSignature) ProtoMessage() {}
+func (*Evidence) ProtoMessage() {}
 
-func (x *QuorumSignature) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[11]
+func (x *Evidence) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -686,91 +1502,73 @@ func (x *QuorumSignature) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use QuorumSignature.ProtoReflect.Descriptor instead.
-func (*QuorumSignature) Descriptor() ([]byte, []int) {
-	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use Evidence.ProtoReflect.Descriptor instead.
+func (*Evidence) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *QuorumSignature) GetSig() isQuorumSignature_Sig {
+func (x *Evidence) GetOffender() uint32 {
 	if x != nil {
-		return x.Sig
+		return x.Offender
 	}
-	return nil
+	return 0
 }
 
-func (x *QuorumSignature) GetECDSASigs() *ECDSAMultiSignature {
+func (x *Evidence) GetFirst() *Block {
 	if x != nil {
-		if x, ok := x.Sig.(*QuorumSignature_ECDSASigs); ok {
-			return x.ECDSASigs
-		}
+		return x.First
 	}
 	return nil
 }
 
-func (x *QuorumSignature) GetBLS12Sig() *BLS12AggregateSignature {
+func (x *Evidence) GetFirstSig() *QuorumSignature {
 	if x != nil {
-		if x, ok := x.Sig.(*QuorumSignature_BLS12Sig); ok {
-			return x.BLS12Sig
-		}
+		return x.FirstSig
 	}
 	return nil
 }
 
-func (x *QuorumSignature) GetEDDSASigs() *EDDSAMultiSignature {
+func (x *Evidence) GetSecond() *Block {
 	if x != nil {
-		if x, ok := x.Sig.(*QuorumSignature_EDDSASigs); ok {
-			return x.EDDSASigs
-		}
+		return x.Second
 	}
 	return nil
 }
 
-type isQuorumSignature_Sig interface {
-	isQuorumSignature_Sig()
-}
-
-type QuorumSignature_ECDSASigs struct {
-	ECDSASigs *ECDSAMultiSignature `protobuf:"bytes,1,opt,name=ECDSASigs,proto3,oneof"`
-}
-
-type QuorumSignature_BLS12Sig struct {
-	BLS12Sig *BLS12AggregateSignature `protobuf:"bytes,2,opt,name=BLS12Sig,proto3,oneof"`
-}
-
-type QuorumSignature_EDDSASigs struct {
-	EDDSASigs *EDDSAMultiSignature `protobuf:"bytes,3,opt,name=EDDSASigs,proto3,oneof"`
+func (x *Evidence) GetSecondSig() *QuorumSignature {
+	if x != nil {
+		return x.SecondSig
+	}
+	return nil
 }
 
-func (*QuorumSignature_ECDSASigs) isQuorumSignature_Sig() {}
-
-func (*QuorumSignature_BLS12Sig) isQuorumSignature_Sig() {}
-
-func (*QuorumSignature_EDDSASigs) isQuorumSignature_Sig() {}
-
-type QuorumCert struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Sig           *QuorumSignature       `protobuf:"bytes,1,opt,name=Sig,proto3" json:"Sig,omitempty"`
-	View          uint64                 `protobuf:"varint,2,opt,name=View,proto3" json:"View,omitempty"`
-	Hash          []byte                 `protobuf:"bytes,3,opt,name=Hash,proto3" json:"Hash,omitempty"`
+// DecryptionShares contains a replica's threshold decryption shares for the
+// encrypted commands of a committed block.
+type DecryptionShares struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	BlockHash []byte                 `protobuf:"bytes,1,opt,name=BlockHash,proto3" json:"BlockHash,omitempty"`
+	// One share per command in the block, in the order of the batch.
+	// A share is empty if the command could not be decrypted.
+	Shares        [][]byte `protobuf:"bytes,2,rep,name=Shares,proto3" json:"Shares,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *QuorumCert) Reset() {
-	*x = QuorumCert{}
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[12]
+func (x *DecryptionShares) Reset() {
+	*x = DecryptionShares{}
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *QuorumCert) String() string {
+func (x *DecryptionShares) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*QuorumCert) ProtoMessage() {}
+func (*DecryptionShares) ProtoMessage() {}
 
-func (x *QuorumCert) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[12]
+func (x *DecryptionShares) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -781,55 +1579,52 @@ func (x *QuorumCert) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use QuorumCert.ProtoReflect.Descriptor instead.
-func (*QuorumCert) Descriptor() ([]byte, []int) {
-	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use DecryptionShares.ProtoReflect.Descriptor instead.
+func (*DecryptionShares) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *QuorumCert) GetSig() *QuorumSignature {
+func (x *DecryptionShares) GetBlockHash() []byte {
 	if x != nil {
-		return x.Sig
+		return x.BlockHash
 	}
 	return nil
 }
 
-func (x *QuorumCert) GetView() uint64 {
-	if x != nil {
-		return x.View
-	}
-	return 0
-}
-
-func (x *QuorumCert) GetHash() []byte {
+func (x *DecryptionShares) GetShares() [][]byte {
 	if x != nil {
-		return x.Hash
+		return x.Shares
 	}
 	return nil
 }
 
-type TimeoutCert struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Sig           *QuorumSignature       `protobuf:"bytes,1,opt,name=Sig,proto3" json:"Sig,omitempty"`
-	View          uint64                 `protobuf:"varint,2,opt,name=View,proto3" json:"View,omitempty"`
+// MempoolBatch is a batch of commands disseminated by the mempool.
+type MempoolBatch struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Author uint32                 `protobuf:"varint,1,opt,name=Author,proto3" json:"Author,omitempty"`
+	Round  uint64                 `protobuf:"varint,2,opt,name=Round,proto3" json:"Round,omitempty"`
+	// The digests of the certified batches of the previous round.
+	Parents       [][]byte `protobuf:"bytes,3,rep,name=Parents,proto3" json:"Parents,omitempty"`
+	Data          []byte   `protobuf:"bytes,4,opt,name=Data,proto3" json:"Data,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TimeoutCert) Reset() {
-	*x = TimeoutCert{}
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[13]
+func (x *MempoolBatch) Reset() {
+	*x = MempoolBatch{}
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TimeoutCert) String() string {
+func (x *MempoolBatch) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TimeoutCert) ProtoMessage() {}
+func (*MempoolBatch) ProtoMessage() {}
 
-func (x *TimeoutCert) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[13]
+func (x *MempoolBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -840,50 +1635,64 @@ func (x *TimeoutCert) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TimeoutCert.ProtoReflect.Descriptor instead.
-func (*TimeoutCert) Descriptor() ([]byte, []int) {
-	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use MempoolBatch.ProtoReflect.Descriptor instead.
+func (*MempoolBatch) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{26}
 }
 
-func (x *TimeoutCert) GetSig() *QuorumSignature {
+func (x *MempoolBatch) GetAuthor() uint32 {
 	if x != nil {
-		return x.Sig
+		return x.Author
 	}
-	return nil
+	return 0
 }
 
-func (x *TimeoutCert) GetView() uint64 {
+func (x *MempoolBatch) GetRound() uint64 {
 	if x != nil {
-		return x.View
+		return x.Round
 	}
 	return 0
 }
 
-type TimeoutMsg struct {
+func (x *MempoolBatch) GetParents() [][]byte {
+	if x != nil {
+		return x.Parents
+	}
+	return nil
+}
+
+func (x *MempoolBatch) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// AvailabilityVote is sent to the author of a batch by a replica that has
+// stored the batch.
+type AvailabilityVote struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	View          uint64                 `protobuf:"varint,1,opt,name=View,proto3" json:"View,omitempty"`
-	SyncInfo      *SyncInfo              `protobuf:"bytes,2,opt,name=SyncInfo,proto3" json:"SyncInfo,omitempty"`
-	ViewSig       *QuorumSignature       `protobuf:"bytes,3,opt,name=ViewSig,proto3" json:"ViewSig,omitempty"`
-	MsgSig        *QuorumSignature       `protobuf:"bytes,4,opt,name=MsgSig,proto3" json:"MsgSig,omitempty"`
+	Digest        []byte                 `protobuf:"bytes,1,opt,name=Digest,proto3" json:"Digest,omitempty"`
+	Sig           *QuorumSignature       `protobuf:"bytes,2,opt,name=Sig,proto3" json:"Sig,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TimeoutMsg) Reset() {
-	*x = TimeoutMsg{}
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[14]
+func (x *AvailabilityVote) Reset() {
+	*x = AvailabilityVote{}
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TimeoutMsg) String() string {
+func (x *AvailabilityVote) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TimeoutMsg) ProtoMessage() {}
+func (*AvailabilityVote) ProtoMessage() {}
 
-func (x *TimeoutMsg) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[14]
+func (x *AvailabilityVote) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -894,63 +1703,50 @@ func (x *TimeoutMsg) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TimeoutMsg.ProtoReflect.Descriptor instead.
-func (*TimeoutMsg) Descriptor() ([]byte, []int) {
-	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{14}
-}
-
-func (x *TimeoutMsg) GetView() uint64 {
-	if x != nil {
-		return x.View
-	}
-	return 0
-}
-
-func (x *TimeoutMsg) GetSyncInfo() *SyncInfo {
-	if x != nil {
-		return x.SyncInfo
-	}
-	return nil
+// Deprecated: Use AvailabilityVote.ProtoReflect.Descriptor instead.
+func (*AvailabilityVote) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{27}
 }
 
-func (x *TimeoutMsg) GetViewSig() *QuorumSignature {
+func (x *AvailabilityVote) GetDigest() []byte {
 	if x != nil {
-		return x.ViewSig
+		return x.Digest
 	}
 	return nil
 }
 
-func (x *TimeoutMsg) GetMsgSig() *QuorumSignature {
+func (x *AvailabilityVote) GetSig() *QuorumSignature {
 	if x != nil {
-		return x.MsgSig
+		return x.Sig
 	}
 	return nil
 }
 
-type SyncInfo struct {
+type AvailabilityCert struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	QC            *QuorumCert            `protobuf:"bytes,1,opt,name=QC,proto3" json:"QC,omitempty"`
-	TC            *TimeoutCert           `protobuf:"bytes,2,opt,name=TC,proto3" json:"TC,omitempty"`
-	AggQC         *AggQC                 `protobuf:"bytes,3,opt,name=AggQC,proto3" json:"AggQC,omitempty"`
+	Digest        []byte                 `protobuf:"bytes,1,opt,name=Digest,proto3" json:"Digest,omitempty"`
+	Author        uint32                 `protobuf:"varint,2,opt,name=Author,proto3" json:"Author,omitempty"`
+	Round         uint64                 `protobuf:"varint,3,opt,name=Round,proto3" json:"Round,omitempty"`
+	Sig           *QuorumSignature       `protobuf:"bytes,4,opt,name=Sig,proto3" json:"Sig,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SyncInfo) Reset() {
-	*x = SyncInfo{}
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[15]
+func (x *AvailabilityCert) Reset() {
+	*x = AvailabilityCert{}
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SyncInfo) String() string {
+func (x *AvailabilityCert) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SyncInfo) ProtoMessage() {}
+func (*AvailabilityCert) ProtoMessage() {}
 
-func (x *SyncInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[15]
+func (x *AvailabilityCert) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -961,56 +1757,62 @@ func (x *SyncInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SyncInfo.ProtoReflect.Descriptor instead.
-func (*SyncInfo) Descriptor() ([]byte, []int) {
-	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use AvailabilityCert.ProtoReflect.Descriptor instead.
+func (*AvailabilityCert) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{28}
 }
 
-func (x *SyncInfo) GetQC() *QuorumCert {
+func (x *AvailabilityCert) GetDigest() []byte {
 	if x != nil {
-		return x.QC
+		return x.Digest
 	}
 	return nil
 }
 
-func (x *SyncInfo) GetTC() *TimeoutCert {
+func (x *AvailabilityCert) GetAuthor() uint32 {
 	if x != nil {
-		return x.TC
+		return x.Author
 	}
-	return nil
+	return 0
 }
 
-func (x *SyncInfo) GetAggQC() *AggQC {
+func (x *AvailabilityCert) GetRound() uint64 {
 	if x != nil {
-		return x.AggQC
+		return x.Round
+	}
+	return 0
+}
+
+func (x *AvailabilityCert) GetSig() *QuorumSignature {
+	if x != nil {
+		return x.Sig
 	}
 	return nil
 }
 
-type AggQC struct {
+// AvailabilityCerts is the command of a block when the mempool is used.
+type AvailabilityCerts struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	QCs           map[uint32]*QuorumCert `protobuf:"bytes,1,rep,name=QCs,proto3" json:"QCs,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Sig           *QuorumSignature       `protobuf:"bytes,2,opt,name=Sig,proto3" json:"Sig,omitempty"`
-	View          uint64                 `protobuf:"varint,3,opt,name=View,proto3" json:"View,omitempty"`
+	Certs         []*AvailabilityCert    `protobuf:"bytes,1,rep,name=Certs,proto3" json:"Certs,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AggQC) Reset() {
-	*x = AggQC{}
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[16]
+func (x *AvailabilityCerts) Reset() {
+	*x = AvailabilityCerts{}
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AggQC) String() string {
+func (x *AvailabilityCerts) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AggQC) ProtoMessage() {}
+func (*AvailabilityCerts) ProtoMessage() {}
 
-func (x *AggQC) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[16]
+func (x *AvailabilityCerts) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1021,32 +1823,18 @@ func (x *AggQC) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AggQC.ProtoReflect.Descriptor instead.
-func (*AggQC) Descriptor() ([]byte, []int) {
-	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{16}
-}
-
-func (x *AggQC) GetQCs() map[uint32]*QuorumCert {
-	if x != nil {
-		return x.QCs
-	}
-	return nil
+// Deprecated: Use AvailabilityCerts.ProtoReflect.Descriptor instead.
+func (*AvailabilityCerts) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{29}
 }
 
-func (x *AggQC) GetSig() *QuorumSignature {
+func (x *AvailabilityCerts) GetCerts() []*AvailabilityCert {
 	if x != nil {
-		return x.Sig
+		return x.Certs
 	}
 	return nil
 }
 
-func (x *AggQC) GetView() uint64 {
-	if x != nil {
-		return x.View
-	}
-	return 0
-}
-
 var File_internal_proto_hotstuffpb_hotstuff_proto protoreflect.FileDescriptor
 
 var file_internal_proto_hotstuffpb_hotstuff_proto_rawDesc = []byte{
@@ -1058,152 +1846,295 @@ var file_internal_proto_hotstuffpb_hotstuff_proto_rawDesc = []byte{
 	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74,
 	0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
 	0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x22, 0x5c, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x12, 0x27,
-	0x0a, 0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e,
-	0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
-	0x52, 0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x27, 0x0a, 0x05, 0x41, 0x67, 0x67, 0x51, 0x43,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66,
-	0x66, 0x70, 0x62, 0x2e, 0x41, 0x67, 0x67, 0x51, 0x43, 0x52, 0x05, 0x41, 0x67, 0x67, 0x51, 0x43,
-	0x22, 0x1f, 0x0a, 0x09, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x12, 0x12, 0x0a,
-	0x04, 0x48, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x48, 0x61, 0x73,
-	0x68, 0x22, 0xcb, 0x01, 0x0a, 0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x16, 0x0a, 0x06, 0x50,
-	0x61, 0x72, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x50, 0x61, 0x72,
-	0x65, 0x6e, 0x74, 0x12, 0x26, 0x0a, 0x02, 0x51, 0x43, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x16, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x51, 0x75, 0x6f,
-	0x72, 0x75, 0x6d, 0x43, 0x65, 0x72, 0x74, 0x52, 0x02, 0x51, 0x43, 0x12, 0x12, 0x0a, 0x04, 0x56,
-	0x69, 0x65, 0x77, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x56, 0x69, 0x65, 0x77, 0x12,
-	0x18, 0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c,
-	0x52, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x50, 0x72, 0x6f,
-	0x70, 0x6f, 0x73, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x50, 0x72, 0x6f,
-	0x70, 0x6f, 0x73, 0x65, 0x72, 0x12, 0x38, 0x0a, 0x09, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
-	0x6d, 0x70, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
-	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
-	0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x22,
-	0x44, 0x0a, 0x0e, 0x45, 0x43, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
-	0x65, 0x12, 0x16, 0x0a, 0x06, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0d, 0x52, 0x06, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x12, 0x0c, 0x0a, 0x01, 0x52, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0c, 0x52, 0x01, 0x52, 0x12, 0x0c, 0x0a, 0x01, 0x53, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x0c, 0x52, 0x01, 0x53, 0x22, 0x22, 0x0a, 0x0e, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x53, 0x69,
-	0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x53, 0x69, 0x67, 0x22, 0x3a, 0x0a, 0x0e, 0x45, 0x44, 0x44,
-	0x53, 0x41, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x53,
-	0x69, 0x67, 0x6e, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x53, 0x69, 0x67,
-	0x6e, 0x65, 0x72, 0x12, 0x10, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
-	0x52, 0x03, 0x53, 0x69, 0x67, 0x22, 0xc0, 0x01, 0x0a, 0x09, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74,
-	0x75, 0x72, 0x65, 0x12, 0x38, 0x0a, 0x08, 0x45, 0x43, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66,
-	0x70, 0x62, 0x2e, 0x45, 0x43, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
-	0x65, 0x48, 0x00, 0x52, 0x08, 0x45, 0x43, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x12, 0x38, 0x0a,
-	0x08, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x53, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x1a, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x4c, 0x53,
-	0x31, 0x32, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x48, 0x00, 0x52, 0x08, 0x42,
-	0x4c, 0x53, 0x31, 0x32, 0x53, 0x69, 0x67, 0x12, 0x38, 0x0a, 0x08, 0x45, 0x44, 0x44, 0x53, 0x41,
-	0x53, 0x69, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x68, 0x6f, 0x74, 0x73,
-	0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x45, 0x44, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x6e,
-	0x61, 0x74, 0x75, 0x72, 0x65, 0x48, 0x00, 0x52, 0x08, 0x45, 0x44, 0x44, 0x53, 0x41, 0x53, 0x69,
-	0x67, 0x42, 0x05, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x22, 0x50, 0x0a, 0x0b, 0x50, 0x61, 0x72, 0x74,
-	0x69, 0x61, 0x6c, 0x43, 0x65, 0x72, 0x74, 0x12, 0x2d, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70,
-	0x62, 0x2e, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
-	0x65, 0x52, 0x03, 0x53, 0x69, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x61, 0x73, 0x68, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68, 0x22, 0x45, 0x0a, 0x13, 0x45, 0x43,
-	0x44, 0x53, 0x41, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
-	0x65, 0x12, 0x2e, 0x0a, 0x04, 0x53, 0x69, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
-	0x1a, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x45, 0x43, 0x44,
-	0x53, 0x41, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x04, 0x53, 0x69, 0x67,
-	0x73, 0x22, 0x45, 0x0a, 0x13, 0x45, 0x44, 0x44, 0x53, 0x41, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x53,
-	0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x2e, 0x0a, 0x04, 0x53, 0x69, 0x67, 0x73,
-	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66,
-	0x66, 0x70, 0x62, 0x2e, 0x45, 0x44, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75,
-	0x72, 0x65, 0x52, 0x04, 0x53, 0x69, 0x67, 0x73, 0x22, 0x4f, 0x0a, 0x17, 0x42, 0x4c, 0x53, 0x31,
-	0x32, 0x41, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74,
-	0x75, 0x72, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
-	0x52, 0x03, 0x53, 0x69, 0x67, 0x12, 0x22, 0x0a, 0x0c, 0x70, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69,
-	0x70, 0x61, 0x6e, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c, 0x70, 0x61, 0x72,
-	0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x73, 0x22, 0xdd, 0x01, 0x0a, 0x0f, 0x51, 0x75,
-	0x6f, 0x72, 0x75, 0x6d, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x3f, 0x0a,
-	0x09, 0x45, 0x43, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x1f, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x45, 0x43,
-	0x44, 0x53, 0x41, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
-	0x65, 0x48, 0x00, 0x52, 0x09, 0x45, 0x43, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x73, 0x12, 0x41,
-	0x0a, 0x08, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x53, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x23, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x4c,
-	0x53, 0x31, 0x32, 0x41, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x53, 0x69, 0x67, 0x6e,
-	0x61, 0x74, 0x75, 0x72, 0x65, 0x48, 0x00, 0x52, 0x08, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x53, 0x69,
-	0x67, 0x12, 0x3f, 0x0a, 0x09, 0x45, 0x44, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x73, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70,
-	0x62, 0x2e, 0x45, 0x44, 0x44, 0x53, 0x41, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x53, 0x69, 0x67, 0x6e,
-	0x61, 0x74, 0x75, 0x72, 0x65, 0x48, 0x00, 0x52, 0x09, 0x45, 0x44, 0x44, 0x53, 0x41, 0x53, 0x69,
-	0x67, 0x73, 0x42, 0x05, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x22, 0x63, 0x0a, 0x0a, 0x51, 0x75, 0x6f,
-	0x72, 0x75, 0x6d, 0x43, 0x65, 0x72, 0x74, 0x12, 0x2d, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70,
-	0x62, 0x2e, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
-	0x65, 0x52, 0x03, 0x53, 0x69, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x56, 0x69, 0x65, 0x77, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x56, 0x69, 0x65, 0x77, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x61,
-	0x73, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68, 0x22, 0x50,
-	0x0a, 0x0b, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x43, 0x65, 0x72, 0x74, 0x12, 0x2d, 0x0a,
-	0x03, 0x53, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x68, 0x6f, 0x74,
-	0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x53, 0x69,
-	0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x03, 0x53, 0x69, 0x67, 0x12, 0x12, 0x0a, 0x04,
-	0x56, 0x69, 0x65, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x56, 0x69, 0x65, 0x77,
-	0x22, 0xbe, 0x01, 0x0a, 0x0a, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x4d, 0x73, 0x67, 0x12,
-	0x12, 0x0a, 0x04, 0x56, 0x69, 0x65, 0x77, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x56,
-	0x69, 0x65, 0x77, 0x12, 0x30, 0x0a, 0x08, 0x53, 0x79, 0x6e, 0x63, 0x49, 0x6e, 0x66, 0x6f, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66,
-	0x70, 0x62, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x08, 0x53, 0x79, 0x6e,
-	0x63, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x35, 0x0a, 0x07, 0x56, 0x69, 0x65, 0x77, 0x53, 0x69, 0x67,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66,
-	0x66, 0x70, 0x62, 0x2e, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74,
-	0x75, 0x72, 0x65, 0x52, 0x07, 0x56, 0x69, 0x65, 0x77, 0x53, 0x69, 0x67, 0x12, 0x33, 0x0a, 0x06,
-	0x4d, 0x73, 0x67, 0x53, 0x69, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x68,
-	0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d,
-	0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x06, 0x4d, 0x73, 0x67, 0x53, 0x69,
-	0x67, 0x22, 0x84, 0x01, 0x0a, 0x08, 0x53, 0x79, 0x6e, 0x63, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x26,
+	0x74, 0x6f, 0x22, 0xb4, 0x01, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x12,
+	0x27, 0x0a, 0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11,
+	0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x52, 0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x27, 0x0a, 0x05, 0x41, 0x67, 0x67, 0x51,
+	0x43, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75,
+	0x66, 0x66, 0x70, 0x62, 0x2e, 0x41, 0x67, 0x67, 0x51, 0x43, 0x52, 0x05, 0x41, 0x67, 0x67, 0x51,
+	0x43, 0x12, 0x2d, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b,
+	0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x51, 0x75, 0x6f, 0x72,
+	0x75, 0x6d, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x03, 0x53, 0x69, 0x67,
+	0x12, 0x27, 0x0a, 0x02, 0x54, 0x43, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x68,
+	0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75,
+	0x74, 0x43, 0x65, 0x72, 0x74, 0x52, 0x02, 0x54, 0x43, 0x22, 0x1f, 0x0a, 0x09, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x61, 0x73, 0x68, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68, 0x22, 0x52, 0x0a, 0x0a, 0x42, 0x6c,
+	0x6f, 0x63, 0x6b, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x46, 0x72, 0x6f, 0x6d,
+	0x56, 0x69, 0x65, 0x77, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x46, 0x72, 0x6f, 0x6d,
+	0x56, 0x69, 0x65, 0x77, 0x12, 0x16, 0x0a, 0x06, 0x54, 0x6f, 0x56, 0x69, 0x65, 0x77, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x54, 0x6f, 0x56, 0x69, 0x65, 0x77, 0x12, 0x10, 0x0a, 0x03,
+	0x54, 0x69, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x54, 0x69, 0x70, 0x22, 0x47,
+	0x0a, 0x06, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x29, 0x0a, 0x06, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74,
+	0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x06, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x4c, 0x61, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x04, 0x4c, 0x61, 0x73, 0x74, 0x22, 0x27, 0x0a, 0x11, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x70, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04,
+	0x48, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68,
+	0x22, 0x52, 0x0a, 0x0a, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x26,
 	0x0a, 0x02, 0x51, 0x43, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x68, 0x6f, 0x74,
 	0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x43, 0x65,
-	0x72, 0x74, 0x52, 0x02, 0x51, 0x43, 0x12, 0x27, 0x0a, 0x02, 0x54, 0x43, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x17, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e,
-	0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x43, 0x65, 0x72, 0x74, 0x52, 0x02, 0x54, 0x43, 0x12,
-	0x27, 0x0a, 0x05, 0x41, 0x67, 0x67, 0x51, 0x43, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11,
-	0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x41, 0x67, 0x67, 0x51,
-	0x43, 0x52, 0x05, 0x41, 0x67, 0x67, 0x51, 0x43, 0x22, 0xc8, 0x01, 0x0a, 0x05, 0x41, 0x67, 0x67,
-	0x51, 0x43, 0x12, 0x2c, 0x0a, 0x03, 0x51, 0x43, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
-	0x1a, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x41, 0x67, 0x67,
-	0x51, 0x43, 0x2e, 0x51, 0x43, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x03, 0x51, 0x43, 0x73,
-	0x12, 0x2d, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e,
+	0x72, 0x74, 0x52, 0x02, 0x51, 0x43, 0x12, 0x1c, 0x0a, 0x09, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52,
+	0x6f, 0x6f, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x52, 0x6f, 0x6f, 0x74, 0x22, 0x2a, 0x0a, 0x10, 0x54, 0x72, 0x69, 0x65, 0x4e, 0x6f, 0x64, 0x65,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x48, 0x61, 0x73, 0x68,
+	0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x06, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73,
+	0x22, 0x21, 0x0a, 0x09, 0x54, 0x72, 0x69, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x14, 0x0a,
+	0x05, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x05, 0x4e, 0x6f,
+	0x64, 0x65, 0x73, 0x22, 0xc5, 0x02, 0x0a, 0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x16, 0x0a,
+	0x06, 0x50, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x50,
+	0x61, 0x72, 0x65, 0x6e, 0x74, 0x12, 0x26, 0x0a, 0x02, 0x51, 0x43, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x16, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x51,
+	0x75, 0x6f, 0x72, 0x75, 0x6d, 0x43, 0x65, 0x72, 0x74, 0x52, 0x02, 0x51, 0x43, 0x12, 0x12, 0x0a,
+	0x04, 0x56, 0x69, 0x65, 0x77, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x56, 0x69, 0x65,
+	0x77, 0x12, 0x18, 0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x50,
+	0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x50,
+	0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x72, 0x12, 0x38, 0x0a, 0x09, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x12, 0x3b, 0x0a, 0x08, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x07, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62,
+	0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x1a, 0x3b,
+	0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x44, 0x0a, 0x0e, 0x45,
+	0x43, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x16, 0x0a,
+	0x06, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x53,
+	0x69, 0x67, 0x6e, 0x65, 0x72, 0x12, 0x0c, 0x0a, 0x01, 0x52, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x01, 0x52, 0x12, 0x0c, 0x0a, 0x01, 0x53, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x01,
+	0x53, 0x22, 0x22, 0x0a, 0x0e, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74,
+	0x75, 0x72, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x03, 0x53, 0x69, 0x67, 0x22, 0x3a, 0x0a, 0x0e, 0x45, 0x44, 0x44, 0x53, 0x41, 0x53, 0x69,
+	0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x53, 0x69, 0x67, 0x6e, 0x65,
+	0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x12,
+	0x10, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x53, 0x69,
+	0x67, 0x22, 0xc0, 0x01, 0x0a, 0x09, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12,
+	0x38, 0x0a, 0x08, 0x45, 0x43, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x45,
+	0x43, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x48, 0x00, 0x52,
+	0x08, 0x45, 0x43, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x12, 0x38, 0x0a, 0x08, 0x42, 0x4c, 0x53,
+	0x31, 0x32, 0x53, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x68, 0x6f,
+	0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x53, 0x69,
+	0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x48, 0x00, 0x52, 0x08, 0x42, 0x4c, 0x53, 0x31, 0x32,
+	0x53, 0x69, 0x67, 0x12, 0x38, 0x0a, 0x08, 0x45, 0x44, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66,
+	0x70, 0x62, 0x2e, 0x45, 0x44, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
+	0x65, 0x48, 0x00, 0x52, 0x08, 0x45, 0x44, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x42, 0x05, 0x0a,
+	0x03, 0x53, 0x69, 0x67, 0x22, 0x50, 0x0a, 0x0b, 0x50, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x43,
+	0x65, 0x72, 0x74, 0x12, 0x2d, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1b, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x51, 0x75,
+	0x6f, 0x72, 0x75, 0x6d, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x03, 0x53,
+	0x69, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x04, 0x48, 0x61, 0x73, 0x68, 0x22, 0x45, 0x0a, 0x13, 0x45, 0x43, 0x44, 0x53, 0x41, 0x4d,
+	0x75, 0x6c, 0x74, 0x69, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x2e, 0x0a,
+	0x04, 0x53, 0x69, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x68, 0x6f,
+	0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x45, 0x43, 0x44, 0x53, 0x41, 0x53, 0x69,
+	0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x04, 0x53, 0x69, 0x67, 0x73, 0x22, 0x45, 0x0a,
+	0x13, 0x45, 0x44, 0x44, 0x53, 0x41, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x53, 0x69, 0x67, 0x6e, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x12, 0x2e, 0x0a, 0x04, 0x53, 0x69, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e,
+	0x45, 0x44, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x04,
+	0x53, 0x69, 0x67, 0x73, 0x22, 0x4f, 0x0a, 0x17, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x41, 0x67, 0x67,
+	0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12,
+	0x10, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x53, 0x69,
+	0x67, 0x12, 0x22, 0x0a, 0x0c, 0x70, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74,
+	0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c, 0x70, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69,
+	0x70, 0x61, 0x6e, 0x74, 0x73, 0x22, 0x61, 0x0a, 0x17, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x54, 0x68,
+	0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65,
+	0x12, 0x10, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x53,
+	0x69, 0x67, 0x12, 0x16, 0x0a, 0x06, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x06, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x54, 0x68,
+	0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x54,
+	0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x22, 0xb2, 0x02, 0x0a, 0x0f, 0x51, 0x75, 0x6f,
+	0x72, 0x75, 0x6d, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x3f, 0x0a, 0x09,
+	0x45, 0x43, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1f, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x45, 0x43, 0x44,
+	0x53, 0x41, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65,
+	0x48, 0x00, 0x52, 0x09, 0x45, 0x43, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x73, 0x12, 0x41, 0x0a,
+	0x08, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x53, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x23, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x4c, 0x53,
+	0x31, 0x32, 0x41, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x53, 0x69, 0x67, 0x6e, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x48, 0x00, 0x52, 0x08, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x53, 0x69, 0x67,
+	0x12, 0x3f, 0x0a, 0x09, 0x45, 0x44, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62,
+	0x2e, 0x45, 0x44, 0x44, 0x53, 0x41, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x53, 0x69, 0x67, 0x6e, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x48, 0x00, 0x52, 0x09, 0x45, 0x44, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67,
+	0x73, 0x12, 0x53, 0x0a, 0x11, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68,
+	0x6f, 0x6c, 0x64, 0x53, 0x69, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x68,
+	0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x54,
+	0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
+	0x65, 0x48, 0x00, 0x52, 0x11, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68,
+	0x6f, 0x6c, 0x64, 0x53, 0x69, 0x67, 0x42, 0x05, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x22, 0x63, 0x0a,
+	0x0a, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x43, 0x65, 0x72, 0x74, 0x12, 0x2d, 0x0a, 0x03, 0x53,
+	0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74,
+	0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x53, 0x69, 0x67, 0x6e,
+	0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x03, 0x53, 0x69, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x56, 0x69,
+	0x65, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x56, 0x69, 0x65, 0x77, 0x12, 0x12,
+	0x0a, 0x04, 0x48, 0x61, 0x73, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x48, 0x61,
+	0x73, 0x68, 0x22, 0xdc, 0x01, 0x0a, 0x0b, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x43, 0x65,
+	0x72, 0x74, 0x12, 0x2d, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1b, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x51, 0x75, 0x6f,
+	0x72, 0x75, 0x6d, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x03, 0x53, 0x69,
+	0x67, 0x12, 0x12, 0x0a, 0x04, 0x56, 0x69, 0x65, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x04, 0x56, 0x69, 0x65, 0x77, 0x12, 0x4a, 0x0a, 0x0b, 0x48, 0x69, 0x67, 0x68, 0x51, 0x43, 0x56,
+	0x69, 0x65, 0x77, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x68, 0x6f, 0x74,
+	0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x43,
+	0x65, 0x72, 0x74, 0x2e, 0x48, 0x69, 0x67, 0x68, 0x51, 0x43, 0x56, 0x69, 0x65, 0x77, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x0b, 0x48, 0x69, 0x67, 0x68, 0x51, 0x43, 0x56, 0x69, 0x65, 0x77,
+	0x73, 0x1a, 0x3e, 0x0a, 0x10, 0x48, 0x69, 0x67, 0x68, 0x51, 0x43, 0x56, 0x69, 0x65, 0x77, 0x73,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38,
+	0x01, 0x22, 0xbe, 0x01, 0x0a, 0x0a, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x4d, 0x73, 0x67,
+	0x12, 0x12, 0x0a, 0x04, 0x56, 0x69, 0x65, 0x77, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04,
+	0x56, 0x69, 0x65, 0x77, 0x12, 0x30, 0x0a, 0x08, 0x53, 0x79, 0x6e, 0x63, 0x49, 0x6e, 0x66, 0x6f,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66,
+	0x66, 0x70, 0x62, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x08, 0x53, 0x79,
+	0x6e, 0x63, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x35, 0x0a, 0x07, 0x56, 0x69, 0x65, 0x77, 0x53, 0x69,
+	0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75,
+	0x66, 0x66, 0x70, 0x62, 0x2e, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x53, 0x69, 0x67, 0x6e, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x52, 0x07, 0x56, 0x69, 0x65, 0x77, 0x53, 0x69, 0x67, 0x12, 0x33, 0x0a,
+	0x06, 0x4d, 0x73, 0x67, 0x53, 0x69, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e,
 	0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x51, 0x75, 0x6f, 0x72, 0x75,
-	0x6d, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x03, 0x53, 0x69, 0x67, 0x12,
-	0x12, 0x0a, 0x04, 0x56, 0x69, 0x65, 0x77, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x56,
-	0x69, 0x65, 0x77, 0x1a, 0x4e, 0x0a, 0x08, 0x51, 0x43, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
-	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x6b, 0x65,
-	0x79, 0x12, 0x2c, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x16, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x51, 0x75,
-	0x6f, 0x72, 0x75, 0x6d, 0x43, 0x65, 0x72, 0x74, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a,
-	0x02, 0x38, 0x01, 0x32, 0xc1, 0x02, 0x0a, 0x08, 0x48, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66,
-	0x12, 0x3d, 0x0a, 0x07, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x12, 0x14, 0x2e, 0x68, 0x6f,
-	0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61,
-	0x6c, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x04, 0x98, 0xb5, 0x18, 0x01, 0x12,
-	0x3d, 0x0a, 0x04, 0x56, 0x6f, 0x74, 0x65, 0x12, 0x17, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75,
-	0x66, 0x66, 0x70, 0x62, 0x2e, 0x50, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x43, 0x65, 0x72, 0x74,
-	0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
-	0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x04, 0x90, 0xb5, 0x18, 0x01, 0x12, 0x3f,
-	0x0a, 0x07, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x16, 0x2e, 0x68, 0x6f, 0x74, 0x73,
-	0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x4d, 0x73,
-	0x67, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x04, 0x98, 0xb5, 0x18, 0x01, 0x12,
-	0x3d, 0x0a, 0x07, 0x4e, 0x65, 0x77, 0x56, 0x69, 0x65, 0x77, 0x12, 0x14, 0x2e, 0x68, 0x6f, 0x74,
-	0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x49, 0x6e, 0x66, 0x6f,
-	0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
-	0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x04, 0x90, 0xb5, 0x18, 0x01, 0x12, 0x37,
-	0x0a, 0x05, 0x46, 0x65, 0x74, 0x63, 0x68, 0x12, 0x15, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75,
-	0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x1a, 0x11,
-	0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63,
-	0x6b, 0x22, 0x04, 0xa0, 0xb5, 0x18, 0x01, 0x42, 0x35, 0x5a, 0x33, 0x67, 0x69, 0x74, 0x68, 0x75,
-	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x72, 0x65, 0x6c, 0x61, 0x62, 0x2f, 0x68, 0x6f, 0x74, 0x73,
-	0x74, 0x75, 0x66, 0x66, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x2f, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x62, 0x06,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6d, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x06, 0x4d, 0x73, 0x67, 0x53,
+	0x69, 0x67, 0x22, 0x84, 0x01, 0x0a, 0x08, 0x53, 0x79, 0x6e, 0x63, 0x49, 0x6e, 0x66, 0x6f, 0x12,
+	0x26, 0x0a, 0x02, 0x51, 0x43, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x68, 0x6f,
+	0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x43,
+	0x65, 0x72, 0x74, 0x52, 0x02, 0x51, 0x43, 0x12, 0x27, 0x0a, 0x02, 0x54, 0x43, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x43, 0x65, 0x72, 0x74, 0x52, 0x02, 0x54, 0x43,
+	0x12, 0x27, 0x0a, 0x05, 0x41, 0x67, 0x67, 0x51, 0x43, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x11, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x41, 0x67, 0x67,
+	0x51, 0x43, 0x52, 0x05, 0x41, 0x67, 0x67, 0x51, 0x43, 0x22, 0xc8, 0x01, 0x0a, 0x05, 0x41, 0x67,
+	0x67, 0x51, 0x43, 0x12, 0x2c, 0x0a, 0x03, 0x51, 0x43, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x41, 0x67,
+	0x67, 0x51, 0x43, 0x2e, 0x51, 0x43, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x03, 0x51, 0x43,
+	0x73, 0x12, 0x2d, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b,
+	0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x51, 0x75, 0x6f, 0x72,
+	0x75, 0x6d, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x03, 0x53, 0x69, 0x67,
+	0x12, 0x12, 0x0a, 0x04, 0x56, 0x69, 0x65, 0x77, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04,
+	0x56, 0x69, 0x65, 0x77, 0x1a, 0x4e, 0x0a, 0x08, 0x51, 0x43, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x2c, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x16, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x51,
+	0x75, 0x6f, 0x72, 0x75, 0x6d, 0x43, 0x65, 0x72, 0x74, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x22, 0xee, 0x01, 0x0a, 0x08, 0x45, 0x76, 0x69, 0x64, 0x65, 0x6e, 0x63,
+	0x65, 0x12, 0x1a, 0x0a, 0x08, 0x4f, 0x66, 0x66, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x08, 0x4f, 0x66, 0x66, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x27, 0x0a,
+	0x05, 0x46, 0x69, 0x72, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x68,
+	0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52,
+	0x05, 0x46, 0x69, 0x72, 0x73, 0x74, 0x12, 0x37, 0x0a, 0x08, 0x46, 0x69, 0x72, 0x73, 0x74, 0x53,
+	0x69, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74,
+	0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x53, 0x69, 0x67, 0x6e,
+	0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x08, 0x46, 0x69, 0x72, 0x73, 0x74, 0x53, 0x69, 0x67, 0x12,
+	0x29, 0x0a, 0x06, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x11, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x52, 0x06, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x12, 0x39, 0x0a, 0x09, 0x53, 0x65,
+	0x63, 0x6f, 0x6e, 0x64, 0x53, 0x69, 0x67, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e,
+	0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x51, 0x75, 0x6f, 0x72, 0x75,
+	0x6d, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x09, 0x53, 0x65, 0x63, 0x6f,
+	0x6e, 0x64, 0x53, 0x69, 0x67, 0x22, 0x48, 0x0a, 0x10, 0x44, 0x65, 0x63, 0x72, 0x79, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x53, 0x68, 0x61, 0x72, 0x65, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x42, 0x6c,
+	0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x53, 0x68, 0x61, 0x72, 0x65,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x06, 0x53, 0x68, 0x61, 0x72, 0x65, 0x73, 0x22,
+	0x6a, 0x0a, 0x0c, 0x4d, 0x65, 0x6d, 0x70, 0x6f, 0x6f, 0x6c, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12,
+	0x16, 0x0a, 0x06, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x06, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x52, 0x6f, 0x75, 0x6e, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x18, 0x0a,
+	0x07, 0x50, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x07,
+	0x50, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x44, 0x61, 0x74, 0x61, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x44, 0x61, 0x74, 0x61, 0x22, 0x59, 0x0a, 0x10, 0x41,
+	0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x56, 0x6f, 0x74, 0x65, 0x12,
+	0x16, 0x0a, 0x06, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x06, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x2d, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70,
+	0x62, 0x2e, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
+	0x65, 0x52, 0x03, 0x53, 0x69, 0x67, 0x22, 0x87, 0x01, 0x0a, 0x10, 0x41, 0x76, 0x61, 0x69, 0x6c,
+	0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x43, 0x65, 0x72, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x44,
+	0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x44, 0x69, 0x67,
+	0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x06, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x52,
+	0x6f, 0x75, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x52, 0x6f, 0x75, 0x6e,
+	0x64, 0x12, 0x2d, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b,
+	0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x51, 0x75, 0x6f, 0x72,
+	0x75, 0x6d, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x03, 0x53, 0x69, 0x67,
+	0x22, 0x47, 0x0a, 0x11, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79,
+	0x43, 0x65, 0x72, 0x74, 0x73, 0x12, 0x32, 0x0a, 0x05, 0x43, 0x65, 0x72, 0x74, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70,
+	0x62, 0x2e, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x43, 0x65,
+	0x72, 0x74, 0x52, 0x05, 0x43, 0x65, 0x72, 0x74, 0x73, 0x32, 0xe3, 0x07, 0x0a, 0x08, 0x48, 0x6f,
+	0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x12, 0x3d, 0x0a, 0x07, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73,
+	0x65, 0x12, 0x14, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x50,
+	0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22,
+	0x04, 0x98, 0xb5, 0x18, 0x01, 0x12, 0x3d, 0x0a, 0x04, 0x56, 0x6f, 0x74, 0x65, 0x12, 0x17, 0x2e,
+	0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x50, 0x61, 0x72, 0x74, 0x69,
+	0x61, 0x6c, 0x43, 0x65, 0x72, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x04,
+	0x90, 0xb5, 0x18, 0x01, 0x12, 0x3f, 0x0a, 0x07, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12,
+	0x16, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x54, 0x69, 0x6d,
+	0x65, 0x6f, 0x75, 0x74, 0x4d, 0x73, 0x67, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22,
+	0x04, 0x98, 0xb5, 0x18, 0x01, 0x12, 0x3d, 0x0a, 0x07, 0x4e, 0x65, 0x77, 0x56, 0x69, 0x65, 0x77,
+	0x12, 0x14, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x53, 0x79,
+	0x6e, 0x63, 0x49, 0x6e, 0x66, 0x6f, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x04,
+	0x90, 0xb5, 0x18, 0x01, 0x12, 0x37, 0x0a, 0x05, 0x46, 0x65, 0x74, 0x63, 0x68, 0x12, 0x15, 0x2e,
+	0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x48, 0x61, 0x73, 0x68, 0x1a, 0x11, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70,
+	0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x22, 0x04, 0xa0, 0xb5, 0x18, 0x01, 0x12, 0x40, 0x0a,
+	0x0a, 0x46, 0x65, 0x74, 0x63, 0x68, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x16, 0x2e, 0x68, 0x6f,
+	0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x61,
+	0x6e, 0x67, 0x65, 0x1a, 0x12, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62,
+	0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x22, 0x04, 0xa8, 0xb5, 0x18, 0x01, 0x30, 0x01, 0x12,
+	0x4e, 0x0a, 0x0f, 0x46, 0x65, 0x74, 0x63, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69,
+	0x6e, 0x74, 0x12, 0x1d, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e,
+	0x43, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x16, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x43,
+	0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x22, 0x04, 0x88, 0xb5, 0x18, 0x01, 0x12,
+	0x4b, 0x0a, 0x0e, 0x46, 0x65, 0x74, 0x63, 0x68, 0x54, 0x72, 0x69, 0x65, 0x4e, 0x6f, 0x64, 0x65,
+	0x73, 0x12, 0x1c, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x54,
+	0x72, 0x69, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x15, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x54, 0x72, 0x69,
+	0x65, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x22, 0x04, 0x88, 0xb5, 0x18, 0x01, 0x12, 0x4d, 0x0a, 0x0f,
+	0x44, 0x65, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x68, 0x61, 0x72, 0x65, 0x12,
+	0x1c, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x44, 0x65, 0x63,
+	0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x68, 0x61, 0x72, 0x65, 0x73, 0x1a, 0x16, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x04, 0x98, 0xb5, 0x18, 0x01, 0x12, 0x3e, 0x0a, 0x08, 0x45,
+	0x76, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x14, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75,
+	0x66, 0x66, 0x70, 0x62, 0x2e, 0x45, 0x76, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x1a, 0x16, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x04, 0x98, 0xb5, 0x18, 0x01, 0x12, 0x46, 0x0a, 0x0c, 0x4d,
+	0x65, 0x6d, 0x70, 0x6f, 0x6f, 0x6c, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x18, 0x2e, 0x68, 0x6f,
+	0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x4d, 0x65, 0x6d, 0x70, 0x6f, 0x6f, 0x6c,
+	0x42, 0x61, 0x74, 0x63, 0x68, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x04, 0x98,
+	0xb5, 0x18, 0x01, 0x12, 0x4e, 0x0a, 0x10, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c,
+	0x69, 0x74, 0x79, 0x56, 0x6f, 0x74, 0x65, 0x12, 0x1c, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75,
+	0x66, 0x66, 0x70, 0x62, 0x2e, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74,
+	0x79, 0x56, 0x6f, 0x74, 0x65, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x04, 0x90,
+	0xb5, 0x18, 0x01, 0x12, 0x4e, 0x0a, 0x10, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c,
+	0x69, 0x74, 0x79, 0x43, 0x65, 0x72, 0x74, 0x12, 0x1c, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75,
+	0x66, 0x66, 0x70, 0x62, 0x2e, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74,
+	0x79, 0x43, 0x65, 0x72, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x04, 0x98,
+	0xb5, 0x18, 0x01, 0x12, 0x4a, 0x0a, 0x11, 0x46, 0x65, 0x74, 0x63, 0x68, 0x4d, 0x65, 0x6d, 0x70,
+	0x6f, 0x6f, 0x6c, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x15, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74,
+	0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x1a,
+	0x18, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x4d, 0x65, 0x6d,
+	0x70, 0x6f, 0x6f, 0x6c, 0x42, 0x61, 0x74, 0x63, 0x68, 0x22, 0x04, 0x88, 0xb5, 0x18, 0x01, 0x42,
+	0x35, 0x5a, 0x33, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x72, 0x65,
+	0x6c, 0x61, 0x62, 0x2f, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x2f, 0x69, 0x6e, 0x74,
+	0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x68, 0x6f, 0x74, 0x73,
+	0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1218,69 +2149,116 @@ func file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP() []byte {
 	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescData
 }
 
-var file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes = make([]protoimpl.MessageInfo, 33)
 var file_internal_proto_hotstuffpb_hotstuff_proto_goTypes = []any{
 	(*Proposal)(nil),                // 0: hotstuffpb.Proposal
 	(*BlockHash)(nil),               // 1: hotstuffpb.BlockHash
-	(*Block)(nil),                   // 2: hotstuffpb.Block
-	(*ECDSASignature)(nil),          // 3: hotstuffpb.ECDSASignature
-	(*BLS12Signature)(nil),          // 4: hotstuffpb.BLS12Signature
-	(*EDDSASignature)(nil),          // 5: hotstuffpb.EDDSASignature
-	(*Signature)(nil),               // 6: hotstuffpb.Signature
-	(*PartialCert)(nil),             // 7: hotstuffpb.PartialCert
-	(*ECDSAMultiSignature)(nil),     // 8: hotstuffpb.ECDSAMultiSignature
-	(*EDDSAMultiSignature)(nil),     // 9: hotstuffpb.EDDSAMultiSignature
-	(*BLS12AggregateSignature)(nil), // 10: hotstuffpb.BLS12AggregateSignature
-	(*QuorumSignature)(nil),         // 11: hotstuffpb.QuorumSignature
-	(*QuorumCert)(nil),              // 12: hotstuffpb.QuorumCert
-	(*TimeoutCert)(nil),             // 13: hotstuffpb.TimeoutCert
-	(*TimeoutMsg)(nil),              // 14: hotstuffpb.TimeoutMsg
-	(*SyncInfo)(nil),                // 15: hotstuffpb.SyncInfo
-	(*AggQC)(nil),                   // 16: hotstuffpb.AggQC
-	nil,                             // 17: hotstuffpb.AggQC.QCsEntry
-	(*timestamppb.Timestamp)(nil),   // 18: google.protobuf.Timestamp
-	(*emptypb.Empty)(nil),           // 19: google.protobuf.Empty
+	(*BlockRange)(nil),              // 2: hotstuffpb.BlockRange
+	(*Blocks)(nil),                  // 3: hotstuffpb.Blocks
+	(*CheckpointRequest)(nil),       // 4: hotstuffpb.CheckpointRequest
+	(*Checkpoint)(nil),              // 5: hotstuffpb.Checkpoint
+	(*TrieNodesRequest)(nil),        // 6: hotstuffpb.TrieNodesRequest
+	(*TrieNodes)(nil),               // 7: hotstuffpb.TrieNodes
+	(*Block)(nil),                   // 8: hotstuffpb.Block
+	(*ECDSASignature)(nil),          // 9: hotstuffpb.ECDSASignature
+	(*BLS12Signature)(nil),          // 10: hotstuffpb.BLS12Signature
+	(*EDDSASignature)(nil),          // 11: hotstuffpb.EDDSASignature
+	(*Signature)(nil),               // 12: hotstuffpb.Signature
+	(*PartialCert)(nil),             // 13: hotstuffpb.PartialCert
+	(*ECDSAMultiSignature)(nil),     // 14: hotstuffpb.ECDSAMultiSignature
+	(*EDDSAMultiSignature)(nil),     // 15: hotstuffpb.EDDSAMultiSignature
+	(*BLS12AggregateSignature)(nil), // 16: hotstuffpb.BLS12AggregateSignature
+	(*BLS12ThresholdSignature)(nil), // 17: hotstuffpb.BLS12ThresholdSignature
+	(*QuorumSignature)(nil),         // 18: hotstuffpb.QuorumSignature
+	(*QuorumCert)(nil),              // 19: hotstuffpb.QuorumCert
+	(*TimeoutCert)(nil),             // 20: hotstuffpb.TimeoutCert
+	(*TimeoutMsg)(nil),              // 21: hotstuffpb.TimeoutMsg
+	(*SyncInfo)(nil),                // 22: hotstuffpb.SyncInfo
+	(*AggQC)(nil),                   // 23: hotstuffpb.AggQC
+	(*Evidence)(nil),                // 24: hotstuffpb.Evidence
+	(*DecryptionShares)(nil),        // 25: hotstuffpb.DecryptionShares
+	(*MempoolBatch)(nil),            // 26: hotstuffpb.MempoolBatch
+	(*AvailabilityVote)(nil),        // 27: hotstuffpb.AvailabilityVote
+	(*AvailabilityCert)(nil),        // 28: hotstuffpb.AvailabilityCert
+	(*AvailabilityCerts)(nil),       // 29: hotstuffpb.AvailabilityCerts
+	nil,                             // 30: hotstuffpb.Block.MetadataEntry
+	nil,                             // 31: hotstuffpb.TimeoutCert.HighQCViewsEntry
+	nil,                             // 32: hotstuffpb.AggQC.QCsEntry
+	(*timestamppb.Timestamp)(nil),   // 33: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),           // 34: google.protobuf.Empty
 }
 var file_internal_proto_hotstuffpb_hotstuff_proto_depIdxs = []int32{
-	2,  // 0: hotstuffpb.Proposal.Block:type_name -> hotstuffpb.Block
-	16, // 1: hotstuffpb.Proposal.AggQC:type_name -> hotstuffpb.AggQC
-	12, // 2: hotstuffpb.Block.QC:type_name -> hotstuffpb.QuorumCert
-	18, // 3: hotstuffpb.Block.Timestamp:type_name -> google.protobuf.Timestamp
-	3,  // 4: hotstuffpb.Signature.ECDSASig:type_name -> hotstuffpb.ECDSASignature
-	4,  // 5: hotstuffpb.Signature.BLS12Sig:type_name -> hotstuffpb.BLS12Signature
-	5,  // 6: hotstuffpb.Signature.EDDSASig:type_name -> hotstuffpb.EDDSASignature
-	11, // 7: hotstuffpb.PartialCert.Sig:type_name -> hotstuffpb.QuorumSignature
-	3,  // 8: hotstuffpb.ECDSAMultiSignature.Sigs:type_name -> hotstuffpb.ECDSASignature
-	5,  // 9: hotstuffpb.EDDSAMultiSignature.Sigs:type_name -> hotstuffpb.EDDSASignature
-	8,  // 10: hotstuffpb.QuorumSignature.ECDSASigs:type_name -> hotstuffpb.ECDSAMultiSignature
-	10, // 11: hotstuffpb.QuorumSignature.BLS12Sig:type_name -> hotstuffpb.BLS12AggregateSignature
-	9,  // 12: hotstuffpb.QuorumSignature.EDDSASigs:type_name -> hotstuffpb.EDDSAMultiSignature
-	11, // 13: hotstuffpb.QuorumCert.Sig:type_name -> hotstuffpb.QuorumSignature
-	11, // 14: hotstuffpb.TimeoutCert.Sig:type_name -> hotstuffpb.QuorumSignature
-	15, // 15: hotstuffpb.TimeoutMsg.SyncInfo:type_name -> hotstuffpb.SyncInfo
-	11, // 16: hotstuffpb.TimeoutMsg.ViewSig:type_name -> hotstuffpb.QuorumSignature
-	11, // 17: hotstuffpb.TimeoutMsg.MsgSig:type_name -> hotstuffpb.QuorumSignature
-	12, // 18: hotstuffpb.SyncInfo.QC:type_name -> hotstuffpb.QuorumCert
-	13, // 19: hotstuffpb.SyncInfo.TC:type_name -> hotstuffpb.TimeoutCert
-	16, // 20: hotstuffpb.SyncInfo.AggQC:type_name -> hotstuffpb.AggQC
-	17, // 21: hotstuffpb.AggQC.QCs:type_name -> hotstuffpb.AggQC.QCsEntry
-	11, // 22: hotstuffpb.AggQC.Sig:type_name -> hotstuffpb.QuorumSignature
-	12, // 23: hotstuffpb.AggQC.QCsEntry.value:type_name -> hotstuffpb.QuorumCert
-	0,  // 24: hotstuffpb.Hotstuff.Propose:input_type -> hotstuffpb.Proposal
-	7,  // 25: hotstuffpb.Hotstuff.Vote:input_type -> hotstuffpb.PartialCert
-	14, // 26: hotstuffpb.Hotstuff.Timeout:input_type -> hotstuffpb.TimeoutMsg
-	15, // 27: hotstuffpb.Hotstuff.NewView:input_type -> hotstuffpb.SyncInfo
-	1,  // 28: hotstuffpb.Hotstuff.Fetch:input_type -> hotstuffpb.BlockHash
-	19, // 29: hotstuffpb.Hotstuff.Propose:output_type -> google.protobuf.Empty
-	19, // 30: hotstuffpb.Hotstuff.Vote:output_type -> google.protobuf.Empty
-	19, // 31: hotstuffpb.Hotstuff.Timeout:output_type -> google.protobuf.Empty
-	19, // 32: hotstuffpb.Hotstuff.NewView:output_type -> google.protobuf.Empty
-	2,  // 33: hotstuffpb.Hotstuff.Fetch:output_type -> hotstuffpb.Block
-	29, // [29:34] is the sub-list for method output_type
-	24, // [24:29] is the sub-list for method input_type
-	24, // [24:24] is the sub-list for extension type_name
-	24, // [24:24] is the sub-list for extension extendee
-	0,  // [0:24] is the sub-list for field type_name
+	8,  // 0: hotstuffpb.Proposal.Block:type_name -> hotstuffpb.Block
+	23, // 1: hotstuffpb.Proposal.AggQC:type_name -> hotstuffpb.AggQC
+	18, // 2: hotstuffpb.Proposal.Sig:type_name -> hotstuffpb.QuorumSignature
+	20, // 3: hotstuffpb.Proposal.TC:type_name -> hotstuffpb.TimeoutCert
+	8,  // 4: hotstuffpb.Blocks.Blocks:type_name -> hotstuffpb.Block
+	19, // 5: hotstuffpb.Checkpoint.QC:type_name -> hotstuffpb.QuorumCert
+	19, // 6: hotstuffpb.Block.QC:type_name -> hotstuffpb.QuorumCert
+	33, // 7: hotstuffpb.Block.Timestamp:type_name -> google.protobuf.Timestamp
+	30, // 8: hotstuffpb.Block.Metadata:type_name -> hotstuffpb.Block.MetadataEntry
+	9,  // 9: hotstuffpb.Signature.ECDSASig:type_name -> hotstuffpb.ECDSASignature
+	10, // 10: hotstuffpb.Signature.BLS12Sig:type_name -> hotstuffpb.BLS12Signature
+	11, // 11: hotstuffpb.Signature.EDDSASig:type_name -> hotstuffpb.EDDSASignature
+	18, // 12: hotstuffpb.PartialCert.Sig:type_name -> hotstuffpb.QuorumSignature
+	9,  // 13: hotstuffpb.ECDSAMultiSignature.Sigs:type_name -> hotstuffpb.ECDSASignature
+	11, // 14: hotstuffpb.EDDSAMultiSignature.Sigs:type_name -> hotstuffpb.EDDSASignature
+	14, // 15: hotstuffpb.QuorumSignature.ECDSASigs:type_name -> hotstuffpb.ECDSAMultiSignature
+	16, // 16: hotstuffpb.QuorumSignature.BLS12Sig:type_name -> hotstuffpb.BLS12AggregateSignature
+	15, // 17: hotstuffpb.QuorumSignature.EDDSASigs:type_name -> hotstuffpb.EDDSAMultiSignature
+	17, // 18: hotstuffpb.QuorumSignature.BLS12ThresholdSig:type_name -> hotstuffpb.BLS12ThresholdSignature
+	18, // 19: hotstuffpb.QuorumCert.Sig:type_name -> hotstuffpb.QuorumSignature
+	18, // 20: hotstuffpb.TimeoutCert.Sig:type_name -> hotstuffpb.QuorumSignature
+	31, // 21: hotstuffpb.TimeoutCert.HighQCViews:type_name -> hotstuffpb.TimeoutCert.HighQCViewsEntry
+	22, // 22: hotstuffpb.TimeoutMsg.SyncInfo:type_name -> hotstuffpb.SyncInfo
+	18, // 23: hotstuffpb.TimeoutMsg.ViewSig:type_name -> hotstuffpb.QuorumSignature
+	18, // 24: hotstuffpb.TimeoutMsg.MsgSig:type_name -> hotstuffpb.QuorumSignature
+	19, // 25: hotstuffpb.SyncInfo.QC:type_name -> hotstuffpb.QuorumCert
+	20, // 26: hotstuffpb.SyncInfo.TC:type_name -> hotstuffpb.TimeoutCert
+	23, // 27: hotstuffpb.SyncInfo.AggQC:type_name -> hotstuffpb.AggQC
+	32, // 28: hotstuffpb.AggQC.QCs:type_name -> hotstuffpb.AggQC.QCsEntry
+	18, // 29: hotstuffpb.AggQC.Sig:type_name -> hotstuffpb.QuorumSignature
+	8,  // 30: hotstuffpb.Evidence.First:type_name -> hotstuffpb.Block
+	18, // 31: hotstuffpb.Evidence.FirstSig:type_name -> hotstuffpb.QuorumSignature
+	8,  // 32: hotstuffpb.Evidence.Second:type_name -> hotstuffpb.Block
+	18, // 33: hotstuffpb.Evidence.SecondSig:type_name -> hotstuffpb.QuorumSignature
+	18, // 34: hotstuffpb.AvailabilityVote.Sig:type_name -> hotstuffpb.QuorumSignature
+	18, // 35: hotstuffpb.AvailabilityCert.Sig:type_name -> hotstuffpb.QuorumSignature
+	28, // 36: hotstuffpb.AvailabilityCerts.Certs:type_name -> hotstuffpb.AvailabilityCert
+	19, // 37: hotstuffpb.AggQC.QCsEntry.value:type_name -> hotstuffpb.QuorumCert
+	0,  // 38: hotstuffpb.Hotstuff.Propose:input_type -> hotstuffpb.Proposal
+	13, // 39: hotstuffpb.Hotstuff.Vote:input_type -> hotstuffpb.PartialCert
+	21, // 40: hotstuffpb.Hotstuff.Timeout:input_type -> hotstuffpb.TimeoutMsg
+	22, // 41: hotstuffpb.Hotstuff.NewView:input_type -> hotstuffpb.SyncInfo
+	1,  // 42: hotstuffpb.Hotstuff.Fetch:input_type -> hotstuffpb.BlockHash
+	2,  // 43: hotstuffpb.Hotstuff.FetchRange:input_type -> hotstuffpb.BlockRange
+	4,  // 44: hotstuffpb.Hotstuff.FetchCheckpoint:input_type -> hotstuffpb.CheckpointRequest
+	6,  // 45: hotstuffpb.Hotstuff.FetchTrieNodes:input_type -> hotstuffpb.TrieNodesRequest
+	25, // 46: hotstuffpb.Hotstuff.DecryptionShare:input_type -> hotstuffpb.DecryptionShares
+	24, // 47: hotstuffpb.Hotstuff.Evidence:input_type -> hotstuffpb.Evidence
+	26, // 48: hotstuffpb.Hotstuff.MempoolBatch:input_type -> hotstuffpb.MempoolBatch
+	27, // 49: hotstuffpb.Hotstuff.AvailabilityVote:input_type -> hotstuffpb.AvailabilityVote
+	28, // 50: hotstuffpb.Hotstuff.AvailabilityCert:input_type -> hotstuffpb.AvailabilityCert
+	1,  // 51: hotstuffpb.Hotstuff.FetchMempoolBatch:input_type -> hotstuffpb.BlockHash
+	34, // 52: hotstuffpb.Hotstuff.Propose:output_type -> google.protobuf.Empty
+	34, // 53: hotstuffpb.Hotstuff.Vote:output_type -> google.protobuf.Empty
+	34, // 54: hotstuffpb.Hotstuff.Timeout:output_type -> google.protobuf.Empty
+	34, // 55: hotstuffpb.Hotstuff.NewView:output_type -> google.protobuf.Empty
+	8,  // 56: hotstuffpb.Hotstuff.Fetch:output_type -> hotstuffpb.Block
+	3,  // 57: hotstuffpb.Hotstuff.FetchRange:output_type -> hotstuffpb.Blocks
+	5,  // 58: hotstuffpb.Hotstuff.FetchCheckpoint:output_type -> hotstuffpb.Checkpoint
+	7,  // 59: hotstuffpb.Hotstuff.FetchTrieNodes:output_type -> hotstuffpb.TrieNodes
+	34, // 60: hotstuffpb.Hotstuff.DecryptionShare:output_type -> google.protobuf.Empty
+	34, // 61: hotstuffpb.Hotstuff.Evidence:output_type -> google.protobuf.Empty
+	34, // 62: hotstuffpb.Hotstuff.MempoolBatch:output_type -> google.protobuf.Empty
+	34, // 63: hotstuffpb.Hotstuff.AvailabilityVote:output_type -> google.protobuf.Empty
+	34, // 64: hotstuffpb.Hotstuff.AvailabilityCert:output_type -> google.protobuf.Empty
+	26, // 65: hotstuffpb.Hotstuff.FetchMempoolBatch:output_type -> hotstuffpb.MempoolBatch
+	52, // [52:66] is the sub-list for method output_type
+	38, // [38:52] is the sub-list for method input_type
+	38, // [38:38] is the sub-list for extension type_name
+	38, // [38:38] is the sub-list for extension extendee
+	0,  // [0:38] is the sub-list for field type_name
 }
 
 func init() { file_internal_proto_hotstuffpb_hotstuff_proto_init() }
@@ -1288,15 +2266,16 @@ func file_internal_proto_hotstuffpb_hotstuff_proto_init() {
 	if File_internal_proto_hotstuffpb_hotstuff_proto != nil {
 		return
 	}
-	file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[6].OneofWrappers = []any{
+	file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[12].OneofWrappers = []any{
 		(*Signature_ECDSASig)(nil),
 		(*Signature_BLS12Sig)(nil),
 		(*Signature_EDDSASig)(nil),
 	}
-	file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[11].OneofWrappers = []any{
+	file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[18].OneofWrappers = []any{
 		(*QuorumSignature_ECDSASigs)(nil),
 		(*QuorumSignature_BLS12Sig)(nil),
 		(*QuorumSignature_EDDSASigs)(nil),
+		(*QuorumSignature_BLS12ThresholdSig)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -1304,7 +2283,7 @@ func file_internal_proto_hotstuffpb_hotstuff_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_internal_proto_hotstuffpb_hotstuff_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   18,
+			NumMessages:   33,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
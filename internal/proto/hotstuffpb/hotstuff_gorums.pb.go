@@ -10,7 +10,9 @@ import (
 	context "context"
 	fmt "fmt"
 	gorums "github.com/relab/gorums"
+	ordering "github.com/relab/gorums/ordering"
 	encoding "google.golang.org/grpc/encoding"
+	proto "google.golang.org/protobuf/proto"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
@@ -145,6 +147,28 @@ type Node struct {
 	*gorums.RawNode
 }
 
+// FetchRange asynchronously invokes a correctable quorum call on each node
+// in configuration c and returns a CorrectableStreamBlocks, which can be used
+// to inspect any replies or errors when available.
+// This method supports server-side preliminary replies (correctable stream).
+func (c *Configuration) FetchRange(ctx context.Context, in *BlockRange) *CorrectableStreamBlocks {
+	cd := gorums.CorrectableCallData{
+		Message:      in,
+		Method:       "hotstuffpb.Hotstuff.FetchRange",
+		ServerStream: true,
+	}
+	cd.QuorumFunction = func(req protoreflect.ProtoMessage, replies map[uint32]protoreflect.ProtoMessage) (protoreflect.ProtoMessage, int, bool) {
+		r := make(map[uint32]*Blocks, len(replies))
+		for k, v := range replies {
+			r[k] = v.(*Blocks)
+		}
+		return c.qspec.FetchRangeQF(req.(*BlockRange), r)
+	}
+
+	corr := c.RawConfiguration.CorrectableCall(ctx, cd)
+	return &CorrectableStreamBlocks{corr}
+}
+
 // Reference imports to suppress errors if they are not otherwise used.
 var _ emptypb.Empty
 
@@ -173,6 +197,62 @@ func (c *Configuration) Timeout(ctx context.Context, in *TimeoutMsg, opts ...gor
 	c.RawConfiguration.Multicast(ctx, cd, opts...)
 }
 
+// Reference imports to suppress errors if they are not otherwise used.
+var _ emptypb.Empty
+
+// DecryptionShare is a quorum call invoked on all nodes in configuration c,
+// with the same argument in, and returns a combined result.
+func (c *Configuration) DecryptionShare(ctx context.Context, in *DecryptionShares, opts ...gorums.CallOption) {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "hotstuffpb.Hotstuff.DecryptionShare",
+	}
+
+	c.RawConfiguration.Multicast(ctx, cd, opts...)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ emptypb.Empty
+
+// Evidence is a quorum call invoked on all nodes in configuration c,
+// with the same argument in, and returns a combined result.
+func (c *Configuration) Evidence(ctx context.Context, in *Evidence, opts ...gorums.CallOption) {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "hotstuffpb.Hotstuff.Evidence",
+	}
+
+	c.RawConfiguration.Multicast(ctx, cd, opts...)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ emptypb.Empty
+
+// MempoolBatch is a quorum call invoked on all nodes in configuration c,
+// with the same argument in, and returns a combined result.
+func (c *Configuration) MempoolBatch(ctx context.Context, in *MempoolBatch, opts ...gorums.CallOption) {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "hotstuffpb.Hotstuff.MempoolBatch",
+	}
+
+	c.RawConfiguration.Multicast(ctx, cd, opts...)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ emptypb.Empty
+
+// AvailabilityCert is a quorum call invoked on all nodes in configuration c,
+// with the same argument in, and returns a combined result.
+func (c *Configuration) AvailabilityCert(ctx context.Context, in *AvailabilityCert, opts ...gorums.CallOption) {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "hotstuffpb.Hotstuff.AvailabilityCert",
+	}
+
+	c.RawConfiguration.Multicast(ctx, cd, opts...)
+}
+
 // QuorumSpec is the interface of quorum functions for Hotstuff.
 type QuorumSpec interface {
 	gorums.ConfigOption
@@ -183,6 +263,13 @@ type QuorumSpec interface {
 	// be used by the quorum function. If the in parameter is not needed
 	// you should implement your quorum function with '_ *BlockHash'.
 	FetchQF(in *BlockHash, replies map[uint32]*Block) (*Block, bool)
+
+	// FetchRangeQF is the quorum function for the FetchRange
+	// correctable stream quorum call method. The in parameter is the request object
+	// supplied to the FetchRange method at call time, and may or may not
+	// be used by the quorum function. If the in parameter is not needed
+	// you should implement your quorum function with '_ *BlockRange'.
+	FetchRangeQF(in *BlockRange, replies map[uint32]*Blocks) (*Blocks, int, bool)
 }
 
 // Fetch is a quorum call invoked on all nodes in configuration c,
@@ -207,6 +294,51 @@ func (c *Configuration) Fetch(ctx context.Context, in *BlockHash) (resp *Block,
 	return res.(*Block), err
 }
 
+// FetchCheckpoint is a quorum call invoked on all nodes in configuration c,
+// with the same argument in, and returns a combined result.
+func (n *Node) FetchCheckpoint(ctx context.Context, in *CheckpointRequest) (resp *Checkpoint, err error) {
+	cd := gorums.CallData{
+		Message: in,
+		Method:  "hotstuffpb.Hotstuff.FetchCheckpoint",
+	}
+
+	res, err := n.RawNode.RPCCall(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*Checkpoint), err
+}
+
+// FetchTrieNodes is a quorum call invoked on all nodes in configuration c,
+// with the same argument in, and returns a combined result.
+func (n *Node) FetchTrieNodes(ctx context.Context, in *TrieNodesRequest) (resp *TrieNodes, err error) {
+	cd := gorums.CallData{
+		Message: in,
+		Method:  "hotstuffpb.Hotstuff.FetchTrieNodes",
+	}
+
+	res, err := n.RawNode.RPCCall(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*TrieNodes), err
+}
+
+// FetchMempoolBatch is a quorum call invoked on all nodes in configuration c,
+// with the same argument in, and returns a combined result.
+func (n *Node) FetchMempoolBatch(ctx context.Context, in *BlockHash) (resp *MempoolBatch, err error) {
+	cd := gorums.CallData{
+		Message: in,
+		Method:  "hotstuffpb.Hotstuff.FetchMempoolBatch",
+	}
+
+	res, err := n.RawNode.RPCCall(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*MempoolBatch), err
+}
+
 // Hotstuff is the server-side API for the Hotstuff Service
 type Hotstuff interface {
 	Propose(ctx gorums.ServerCtx, request *Proposal)
@@ -214,6 +346,15 @@ type Hotstuff interface {
 	Timeout(ctx gorums.ServerCtx, request *TimeoutMsg)
 	NewView(ctx gorums.ServerCtx, request *SyncInfo)
 	Fetch(ctx gorums.ServerCtx, request *BlockHash) (response *Block, err error)
+	FetchRange(ctx gorums.ServerCtx, request *BlockRange, send func(response *Blocks) error) error
+	FetchCheckpoint(ctx gorums.ServerCtx, request *CheckpointRequest) (response *Checkpoint, err error)
+	FetchTrieNodes(ctx gorums.ServerCtx, request *TrieNodesRequest) (response *TrieNodes, err error)
+	DecryptionShare(ctx gorums.ServerCtx, request *DecryptionShares)
+	Evidence(ctx gorums.ServerCtx, request *Evidence)
+	MempoolBatch(ctx gorums.ServerCtx, request *MempoolBatch)
+	AvailabilityVote(ctx gorums.ServerCtx, request *AvailabilityVote)
+	AvailabilityCert(ctx gorums.ServerCtx, request *AvailabilityCert)
+	FetchMempoolBatch(ctx gorums.ServerCtx, request *BlockHash) (response *MempoolBatch, err error)
 }
 
 func RegisterHotstuffServer(srv *gorums.Server, impl Hotstuff) {
@@ -243,6 +384,61 @@ func RegisterHotstuffServer(srv *gorums.Server, impl Hotstuff) {
 		resp, err := impl.Fetch(ctx, req)
 		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
 	})
+	srv.RegisterHandler("hotstuffpb.Hotstuff.FetchRange", func(ctx gorums.ServerCtx, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*BlockRange)
+		defer ctx.Release()
+		err := impl.FetchRange(ctx, req, func(resp *Blocks) error {
+			// create a copy of the metadata, to avoid a data race between WrapMessage and SendMsg
+			md := proto.Clone(in.Metadata)
+			return gorums.SendMessage(ctx, finished, gorums.WrapMessage(md.(*ordering.Metadata), resp, nil))
+		})
+		if err != nil {
+			gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, nil, err))
+		}
+	})
+	srv.RegisterHandler("hotstuffpb.Hotstuff.FetchCheckpoint", func(ctx gorums.ServerCtx, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*CheckpointRequest)
+		defer ctx.Release()
+		resp, err := impl.FetchCheckpoint(ctx, req)
+		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
+	})
+	srv.RegisterHandler("hotstuffpb.Hotstuff.FetchTrieNodes", func(ctx gorums.ServerCtx, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*TrieNodesRequest)
+		defer ctx.Release()
+		resp, err := impl.FetchTrieNodes(ctx, req)
+		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
+	})
+	srv.RegisterHandler("hotstuffpb.Hotstuff.DecryptionShare", func(ctx gorums.ServerCtx, in *gorums.Message, _ chan<- *gorums.Message) {
+		req := in.Message.(*DecryptionShares)
+		defer ctx.Release()
+		impl.DecryptionShare(ctx, req)
+	})
+	srv.RegisterHandler("hotstuffpb.Hotstuff.Evidence", func(ctx gorums.ServerCtx, in *gorums.Message, _ chan<- *gorums.Message) {
+		req := in.Message.(*Evidence)
+		defer ctx.Release()
+		impl.Evidence(ctx, req)
+	})
+	srv.RegisterHandler("hotstuffpb.Hotstuff.MempoolBatch", func(ctx gorums.ServerCtx, in *gorums.Message, _ chan<- *gorums.Message) {
+		req := in.Message.(*MempoolBatch)
+		defer ctx.Release()
+		impl.MempoolBatch(ctx, req)
+	})
+	srv.RegisterHandler("hotstuffpb.Hotstuff.AvailabilityVote", func(ctx gorums.ServerCtx, in *gorums.Message, _ chan<- *gorums.Message) {
+		req := in.Message.(*AvailabilityVote)
+		defer ctx.Release()
+		impl.AvailabilityVote(ctx, req)
+	})
+	srv.RegisterHandler("hotstuffpb.Hotstuff.AvailabilityCert", func(ctx gorums.ServerCtx, in *gorums.Message, _ chan<- *gorums.Message) {
+		req := in.Message.(*AvailabilityCert)
+		defer ctx.Release()
+		impl.AvailabilityCert(ctx, req)
+	})
+	srv.RegisterHandler("hotstuffpb.Hotstuff.FetchMempoolBatch", func(ctx gorums.ServerCtx, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*BlockHash)
+		defer ctx.Release()
+		resp, err := impl.FetchMempoolBatch(ctx, req)
+		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
+	})
 }
 
 type internalBlock struct {
@@ -251,6 +447,30 @@ type internalBlock struct {
 	err   error
 }
 
+type internalBlocks struct {
+	nid   uint32
+	reply *Blocks
+	err   error
+}
+
+// CorrectableStreamBlocks is a correctable object for processing replies.
+type CorrectableStreamBlocks struct {
+	*gorums.Correctable
+}
+
+// Get returns the reply, level and any error associated with the
+// called method. The method does not block until a (possibly
+// intermediate) reply or error is available. Level is set to LevelNotSet if no
+// reply has yet been received. The Done or Watch methods should be used to
+// ensure that a reply is available.
+func (c *CorrectableStreamBlocks) Get() (*Blocks, int, error) {
+	resp, level, err := c.Correctable.Get()
+	if err != nil {
+		return nil, level, err
+	}
+	return resp.(*Blocks), level, err
+}
+
 // Reference imports to suppress errors if they are not otherwise used.
 var _ emptypb.Empty
 
@@ -278,3 +498,17 @@ func (n *Node) NewView(ctx context.Context, in *SyncInfo, opts ...gorums.CallOpt
 
 	n.RawNode.Unicast(ctx, cd, opts...)
 }
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ emptypb.Empty
+
+// AvailabilityVote is a quorum call invoked on all nodes in configuration c,
+// with the same argument in, and returns a combined result.
+func (n *Node) AvailabilityVote(ctx context.Context, in *AvailabilityVote, opts ...gorums.CallOption) {
+	cd := gorums.CallData{
+		Message: in,
+		Method:  "hotstuffpb.Hotstuff.AvailabilityVote",
+	}
+
+	n.RawNode.Unicast(ctx, cd, opts...)
+}
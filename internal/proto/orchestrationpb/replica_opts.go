@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/crypto/bls12"
 	"github.com/relab/hotstuff/crypto/keygen"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/durationpb"
@@ -83,6 +84,16 @@ func (x *ReplicaOpts) SetReplicaCertificates(host string, ca *x509.Certificate,
 	return nil
 }
 
+func (x *ReplicaOpts) SetThresholdKeys(share *bls12.ThresholdKeyShare, pub *bls12.ThresholdPublicKey) {
+	x.ThresholdKeyShare = keygen.ThresholdKeyShareToPEM(share)
+	x.ThresholdPublicKey = keygen.ThresholdPublicKeyToPEM(pub)
+}
+
+func (x *ReplicaOpts) SetSigningKeys(share *bls12.ThresholdKeyShare, pub *bls12.ThresholdPublicKey) {
+	x.SigningKeyShare = keygen.ThresholdKeyShareToPEM(share)
+	x.SigningPublicKey = keygen.ThresholdPublicKeyToPEM(pub)
+}
+
 func (x *ReplicaOpts) StringID() string {
 	return strconv.Itoa(int(x.GetID()))
 }
@@ -0,0 +1,303 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.2
+// 	protoc        v6.30.2
+// source: internal/proto/signerpb/signer.proto
+
+package signerpb
+
+import (
+	hotstuffpb "github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// SigningKind describes what a signed message is.
+type SigningKind int32
+
+const (
+	SigningKind_Other   SigningKind = 0
+	SigningKind_Block   SigningKind = 1
+	SigningKind_Timeout SigningKind = 2
+)
+
+// Enum value maps for SigningKind.
+var (
+	SigningKind_name = map[int32]string{
+		0: "Other",
+		1: "Block",
+		2: "Timeout",
+	}
+	SigningKind_value = map[string]int32{
+		"Other":   0,
+		"Block":   1,
+		"Timeout": 2,
+	}
+)
+
+func (x SigningKind) Enum() *SigningKind {
+	p := new(SigningKind)
+	*p = x
+	return p
+}
+
+func (x SigningKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SigningKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_internal_proto_signerpb_signer_proto_enumTypes[0].Descriptor()
+}
+
+func (SigningKind) Type() protoreflect.EnumType {
+	return &file_internal_proto_signerpb_signer_proto_enumTypes[0]
+}
+
+func (x SigningKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SigningKind.Descriptor instead.
+func (SigningKind) EnumDescriptor() ([]byte, []int) {
+	return file_internal_proto_signerpb_signer_proto_rawDescGZIP(), []int{0}
+}
+
+type SignRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       []byte                 `protobuf:"bytes,1,opt,name=Message,proto3" json:"Message,omitempty"`
+	Kind          SigningKind            `protobuf:"varint,2,opt,name=Kind,proto3,enum=signerpb.SigningKind" json:"Kind,omitempty"`
+	View          uint64                 `protobuf:"varint,3,opt,name=View,proto3" json:"View,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignRequest) Reset() {
+	*x = SignRequest{}
+	mi := &file_internal_proto_signerpb_signer_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignRequest) ProtoMessage() {}
+
+func (x *SignRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_signerpb_signer_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignRequest.ProtoReflect.Descriptor instead.
+func (*SignRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_signerpb_signer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SignRequest) GetMessage() []byte {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *SignRequest) GetKind() SigningKind {
+	if x != nil {
+		return x.Kind
+	}
+	return SigningKind_Other
+}
+
+func (x *SignRequest) GetView() uint64 {
+	if x != nil {
+		return x.View
+	}
+	return 0
+}
+
+// SignerInfo identifies the replica that a signer signs for.
+// Metadata is the connection metadata that the replica must send to the other replicas,
+// such as the proof of possession of a BLS12 key.
+type SignerInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ID            uint32                 `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	PublicKey     []byte                 `protobuf:"bytes,2,opt,name=PublicKey,proto3" json:"PublicKey,omitempty"`
+	Metadata      map[string][]byte      `protobuf:"bytes,3,rep,name=Metadata,proto3" json:"Metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignerInfo) Reset() {
+	*x = SignerInfo{}
+	mi := &file_internal_proto_signerpb_signer_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignerInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignerInfo) ProtoMessage() {}
+
+func (x *SignerInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_signerpb_signer_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignerInfo.ProtoReflect.Descriptor instead.
+func (*SignerInfo) Descriptor() ([]byte, []int) {
+	return file_internal_proto_signerpb_signer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SignerInfo) GetID() uint32 {
+	if x != nil {
+		return x.ID
+	}
+	return 0
+}
+
+func (x *SignerInfo) GetPublicKey() []byte {
+	if x != nil {
+		return x.PublicKey
+	}
+	return nil
+}
+
+func (x *SignerInfo) GetMetadata() map[string][]byte {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+var File_internal_proto_signerpb_signer_proto protoreflect.FileDescriptor
+
+var file_internal_proto_signerpb_signer_proto_rawDesc = []byte{
+	0x0a, 0x24, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2f, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x70, 0x62, 0x2f, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x08, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x70, 0x62,
+	0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x19, 0x68,
+	0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2f, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75,
+	0x66, 0x66, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x66, 0x0a, 0x0b, 0x53, 0x69, 0x67, 0x6e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x12, 0x29, 0x0a, 0x04, 0x4b, 0x69, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x15, 0x2e, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x70, 0x62, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x69,
+	0x6e, 0x67, 0x4b, 0x69, 0x6e, 0x64, 0x52, 0x04, 0x4b, 0x69, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04,
+	0x56, 0x69, 0x65, 0x77, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x56, 0x69, 0x65, 0x77,
+	0x22, 0xb7, 0x01, 0x0a, 0x0a, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x12,
+	0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x49, 0x44, 0x12,
+	0x1c, 0x0a, 0x09, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x09, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12, 0x3e, 0x0a,
+	0x08, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x22, 0x2e, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x70, 0x62, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x65,
+	0x72, 0x49, 0x6e, 0x66, 0x6f, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x52, 0x08, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x1a, 0x3b, 0x0a,
+	0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79,
+	0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x2a, 0x30, 0x0a, 0x0b, 0x53, 0x69,
+	0x67, 0x6e, 0x69, 0x6e, 0x67, 0x4b, 0x69, 0x6e, 0x64, 0x12, 0x09, 0x0a, 0x05, 0x4f, 0x74, 0x68,
+	0x65, 0x72, 0x10, 0x00, 0x12, 0x09, 0x0a, 0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x10, 0x01, 0x12,
+	0x0b, 0x0a, 0x07, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x10, 0x02, 0x32, 0x7a, 0x0a, 0x06,
+	0x53, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x12, 0x34, 0x0a, 0x04, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x16,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x14, 0x2e, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x70,
+	0x62, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x3a, 0x0a, 0x04,
+	0x53, 0x69, 0x67, 0x6e, 0x12, 0x15, 0x2e, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x70, 0x62, 0x2e,
+	0x53, 0x69, 0x67, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x68, 0x6f,
+	0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x53,
+	0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x42, 0x33, 0x5a, 0x31, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x72, 0x65, 0x6c, 0x61, 0x62, 0x2f, 0x68, 0x6f, 0x74,
+	0x73, 0x74, 0x75, 0x66, 0x66, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x70, 0x62, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_internal_proto_signerpb_signer_proto_rawDescOnce sync.Once
+	file_internal_proto_signerpb_signer_proto_rawDescData = file_internal_proto_signerpb_signer_proto_rawDesc
+)
+
+func file_internal_proto_signerpb_signer_proto_rawDescGZIP() []byte {
+	file_internal_proto_signerpb_signer_proto_rawDescOnce.Do(func() {
+		file_internal_proto_signerpb_signer_proto_rawDescData = protoimpl.X.CompressGZIP(file_internal_proto_signerpb_signer_proto_rawDescData)
+	})
+	return file_internal_proto_signerpb_signer_proto_rawDescData
+}
+
+var file_internal_proto_signerpb_signer_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_internal_proto_signerpb_signer_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_internal_proto_signerpb_signer_proto_goTypes = []any{
+	(SigningKind)(0),                   // 0: signerpb.SigningKind
+	(*SignRequest)(nil),                // 1: signerpb.SignRequest
+	(*SignerInfo)(nil),                 // 2: signerpb.SignerInfo
+	nil,                                // 3: signerpb.SignerInfo.MetadataEntry
+	(*emptypb.Empty)(nil),              // 4: google.protobuf.Empty
+	(*hotstuffpb.QuorumSignature)(nil), // 5: hotstuffpb.QuorumSignature
+}
+var file_internal_proto_signerpb_signer_proto_depIdxs = []int32{
+	0, // 0: signerpb.SignRequest.Kind:type_name -> signerpb.SigningKind
+	3, // 1: signerpb.SignerInfo.Metadata:type_name -> signerpb.SignerInfo.MetadataEntry
+	4, // 2: signerpb.Signer.Info:input_type -> google.protobuf.Empty
+	1, // 3: signerpb.Signer.Sign:input_type -> signerpb.SignRequest
+	2, // 4: signerpb.Signer.Info:output_type -> signerpb.SignerInfo
+	5, // 5: signerpb.Signer.Sign:output_type -> hotstuffpb.QuorumSignature
+	4, // [4:6] is the sub-list for method output_type
+	2, // [2:4] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_internal_proto_signerpb_signer_proto_init() }
+func file_internal_proto_signerpb_signer_proto_init() {
+	if File_internal_proto_signerpb_signer_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_internal_proto_signerpb_signer_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_internal_proto_signerpb_signer_proto_goTypes,
+		DependencyIndexes: file_internal_proto_signerpb_signer_proto_depIdxs,
+		EnumInfos:         file_internal_proto_signerpb_signer_proto_enumTypes,
+		MessageInfos:      file_internal_proto_signerpb_signer_proto_msgTypes,
+	}.Build()
+	File_internal_proto_signerpb_signer_proto = out.File
+	file_internal_proto_signerpb_signer_proto_rawDesc = nil
+	file_internal_proto_signerpb_signer_proto_goTypes = nil
+	file_internal_proto_signerpb_signer_proto_depIdxs = nil
+}
@@ -0,0 +1,169 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v6.30.2
+// source: internal/proto/signerpb/signer.proto
+
+package signerpb
+
+import (
+	context "context"
+	hotstuffpb "github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Signer_Info_FullMethodName = "/signerpb.Signer/Info"
+	Signer_Sign_FullMethodName = "/signerpb.Signer/Sign"
+)
+
+// SignerClient is the client API for Signer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Signer signs messages on behalf of a replica, such that the replica's private key is kept out of its process.
+type SignerClient interface {
+	// Info returns the identity of the replica that the signer signs for.
+	Info(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*SignerInfo, error)
+	// Sign signs a message, unless it conflicts with a message that the signer has signed before.
+	Sign(ctx context.Context, in *SignRequest, opts ...grpc.CallOption) (*hotstuffpb.QuorumSignature, error)
+}
+
+type signerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSignerClient(cc grpc.ClientConnInterface) SignerClient {
+	return &signerClient{cc}
+}
+
+func (c *signerClient) Info(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*SignerInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SignerInfo)
+	err := c.cc.Invoke(ctx, Signer_Info_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signerClient) Sign(ctx context.Context, in *SignRequest, opts ...grpc.CallOption) (*hotstuffpb.QuorumSignature, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(hotstuffpb.QuorumSignature)
+	err := c.cc.Invoke(ctx, Signer_Sign_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SignerServer is the server API for Signer service.
+// All implementations must embed UnimplementedSignerServer
+// for forward compatibility.
+//
+// Signer signs messages on behalf of a replica, such that the replica's private key is kept out of its process.
+type SignerServer interface {
+	// Info returns the identity of the replica that the signer signs for.
+	Info(context.Context, *emptypb.Empty) (*SignerInfo, error)
+	// Sign signs a message, unless it conflicts with a message that the signer has signed before.
+	Sign(context.Context, *SignRequest) (*hotstuffpb.QuorumSignature, error)
+	mustEmbedUnimplementedSignerServer()
+}
+
+// UnimplementedSignerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSignerServer struct{}
+
+func (UnimplementedSignerServer) Info(context.Context, *emptypb.Empty) (*SignerInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Info not implemented")
+}
+func (UnimplementedSignerServer) Sign(context.Context, *SignRequest) (*hotstuffpb.QuorumSignature, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Sign not implemented")
+}
+func (UnimplementedSignerServer) mustEmbedUnimplementedSignerServer() {}
+func (UnimplementedSignerServer) testEmbeddedByValue()                {}
+
+// UnsafeSignerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SignerServer will
+// result in compilation errors.
+type UnsafeSignerServer interface {
+	mustEmbedUnimplementedSignerServer()
+}
+
+func RegisterSignerServer(s grpc.ServiceRegistrar, srv SignerServer) {
+	// If the following call pancis, it indicates UnimplementedSignerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Signer_ServiceDesc, srv)
+}
+
+func _Signer_Info_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SignerServer).Info(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Signer_Info_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SignerServer).Info(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signer_Sign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SignerServer).Sign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Signer_Sign_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SignerServer).Sign(ctx, req.(*SignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Signer_ServiceDesc is the grpc.ServiceDesc for Signer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Signer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "signerpb.Signer",
+	HandlerType: (*SignerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Info",
+			Handler:    _Signer_Info_Handler,
+		},
+		{
+			MethodName: "Sign",
+			Handler:    _Signer_Sign_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "internal/proto/signerpb/signer.proto",
+}
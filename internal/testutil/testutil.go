@@ -54,7 +54,7 @@ func (bc *testBlockChain) Extends(block, ancestor *hotstuff.Block) bool {
 	return true // simplified for testing
 }
 
-func (bc *testBlockChain) PruneToHeight(height hotstuff.View) {}
+func (bc *testBlockChain) PruneToHeight(height hotstuff.View) []*hotstuff.Block { return nil }
 
 // TestModules registers default modules for testing to the given builder.
 func TestModules(t *testing.T, ctrl *gomock.Controller, id hotstuff.ID, _ hotstuff.PrivateKey, builder *modules.Builder) {
@@ -33,6 +33,7 @@ type Kauri struct {
 	configuration  *backend.Config
 	server         *backend.Server
 	logger         logging.Logger
+	power          modules.VotingPower
 
 	aggContrib  hotstuff.QuorumSignature
 	aggSent     bool
@@ -62,6 +63,7 @@ func (k *Kauri) InitModule(mods *modules.Core) {
 		&k.leaderRotation,
 		&k.synchronizer,
 	)
+	mods.TryGet(&k.power)
 
 	k.opts.SetShouldUseTree()
 	k.eventLoop.RegisterHandler(backend.ConnectedEvent{}, func(_ any) {
@@ -230,7 +232,7 @@ func (k *Kauri) mergeContribution(currentSignature hotstuff.QuorumSignature) err
 		return fmt.Errorf("failed to combine signatures: %v", err)
 	}
 	k.aggContrib = combSignature
-	if combSignature.Participants().Len() >= k.configuration.QuorumSize() {
+	if modules.HasQuorum(k.configuration, k.power, k.currentView, combSignature.Participants()) {
 		k.logger.Debug("Aggregated Complete QC and sending the event")
 		k.eventLoop.AddEvent(hotstuff.NewViewMsg{
 			SyncInfo: hotstuff.NewSyncInfo().WithQC(hotstuff.NewQuorumCert(
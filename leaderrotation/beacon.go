@@ -0,0 +1,130 @@
+package leaderrotation
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"maps"
+	"math/big"
+	"slices"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+)
+
+func init() {
+	modules.RegisterModule("beacon", NewBeacon)
+}
+
+var beaconDomain = []byte("hotstuff-beacon")
+
+// beacon is a leader rotation that elects the leader of each view at random using a randomness beacon.
+//
+// The randomness of a view is derived from the quorum certificate of a committed block:
+// the leader of view v is chosen using the most recent committed block whose view is at most v-(ChainLength+1),
+// which is the commit head of the replicas when they vote in view v-1.
+// When the replicas use threshold signatures (bls12-threshold), the signature of a quorum certificate is
+// unique for the certified block, and acts as a verifiable random function that is evaluated jointly by a quorum.
+// Hence, no replica can bias the randomness, and nobody can predict the leader of a view until shortly before.
+// With other signature schemes, the replica that combines a certificate can choose among the quorums that voted,
+// and thus bias the randomness.
+//
+// While no blocks are committed, the leaders are derived from the last committed block.
+// The leaders are chosen with probability proportional to their voting power when a VotingPower module is present.
+type beacon struct {
+	blockChain    modules.BlockChain
+	configuration modules.Configuration
+	consensus     modules.Consensus
+	opts          *modules.Options
+	logger        logging.Logger
+	power         modules.VotingPower
+	epochs        modules.EpochManager
+}
+
+// InitModule gives the module a reference to the Core object.
+func (b *beacon) InitModule(mods *modules.Core) {
+	mods.Get(
+		&b.blockChain,
+		&b.configuration,
+		&b.consensus,
+		&b.opts,
+		&b.logger,
+	)
+	mods.TryGet(&b.power)
+	mods.TryGet(&b.epochs)
+}
+
+// Randomness returns the random value of the given view.
+func (b *beacon) Randomness(view hotstuff.View) hotstuff.Hash {
+	h := sha256.New()
+	h.Write(beaconDomain)
+	if sig := b.source(view).QuorumCert().Signature(); sig != nil {
+		h.Write(sig.ToBytes())
+	} else {
+		// before the first certificate, use the seed that the replicas were configured with.
+		_ = binary.Write(h, binary.BigEndian, b.opts.SharedRandomSeed())
+	}
+	_ = binary.Write(h, binary.BigEndian, view)
+	var r hotstuff.Hash
+	h.Sum(r[:0])
+	return r
+}
+
+// source returns the most recent committed block whose view is at most view-(ChainLength+1).
+func (b *beacon) source(view hotstuff.View) *hotstuff.Block {
+	block, ok := ancestorAt(b.blockChain, b.consensus.CommittedBlock(), view, hotstuff.View(b.consensus.ChainLength()+1))
+	if !ok {
+		b.logger.Warnf("failed to find committed block; using genesis for the randomness of view %d", view)
+		return hotstuff.GetGenesis()
+	}
+	return block
+}
+
+// GetLeader returns the id of the leader in the given view.
+func (b *beacon) GetLeader(view hotstuff.View) hotstuff.ID {
+	ids := b.candidates(view)
+	weights := make([]uint64, len(ids))
+	var total uint64
+	for i, id := range ids {
+		weights[i] = 1
+		if b.power != nil {
+			weights[i] = b.power.Power(view, id)
+		}
+		total += weights[i]
+	}
+	if total == 0 {
+		b.logger.Error("no candidates with voting power; using round-robin")
+		return chooseRoundRobin(view, b.configuration.Len())
+	}
+
+	r := b.Randomness(view)
+	x := new(big.Int).SetBytes(r[:])
+	x.Mod(x, new(big.Int).SetUint64(total))
+	pick := x.Uint64()
+	for i, id := range ids {
+		if pick < weights[i] {
+			b.logger.Debugf("chose id %d for view %d", id, view)
+			return id
+		}
+		pick -= weights[i]
+	}
+	panic("unreachable")
+}
+
+// candidates returns the IDs of the validators of the view in ascending order.
+func (b *beacon) candidates(view hotstuff.View) []hotstuff.ID {
+	if b.epochs != nil {
+		if ids := slices.Sorted(maps.Keys(b.epochs.Validators(view))); len(ids) > 0 {
+			return ids
+		}
+	}
+	return slices.Sorted(maps.Keys(b.configuration.Replicas()))
+}
+
+// NewBeacon returns a new leader rotation that elects leaders at random using a randomness beacon.
+// The returned module also implements modules.RandomnessBeacon.
+func NewBeacon() modules.LeaderRotation {
+	return &beacon{}
+}
+
+var _ modules.RandomnessBeacon = (*beacon)(nil)
@@ -0,0 +1,111 @@
+package leaderrotation_test
+
+import (
+	"testing"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/blockchain"
+	"github.com/relab/hotstuff/crypto"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/internal/mocks"
+	"github.com/relab/hotstuff/leaderrotation"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+	"go.uber.org/mock/gomock"
+)
+
+// testSignature is a quorum signature with arbitrary bytes.
+type testSignature []byte
+
+func (s testSignature) ToBytes() []byte              { return s }
+func (s testSignature) Participants() hotstuff.IDSet { return &crypto.Bitfield{} }
+
+// testPower gives all voting power to a single replica.
+type testPower hotstuff.ID
+
+func (p testPower) Power(_ hotstuff.View, id hotstuff.ID) uint64 {
+	if id == hotstuff.ID(p) {
+		return 10
+	}
+	return 0
+}
+
+func (p testPower) TotalPower(_ hotstuff.View) uint64 { return 10 }
+
+// createChain creates a chain of blocks in views 1 to n, where the signature of each certificate is given by sig.
+func createChain(n int, sig func(view hotstuff.View) []byte) []*hotstuff.Block {
+	blocks := []*hotstuff.Block{hotstuff.GetGenesis()}
+	for view := hotstuff.View(1); view <= hotstuff.View(n); view++ {
+		parent := blocks[len(blocks)-1]
+		qc := hotstuff.NewQuorumCert(testSignature(sig(parent.View())), parent.View(), parent.Hash())
+		blocks = append(blocks, hotstuff.NewBlock(parent.Hash(), qc, "", view, 1))
+	}
+	return blocks
+}
+
+func createBeacon(t *testing.T, blocks []*hotstuff.Block, commitHead **hotstuff.Block, extra ...any) modules.LeaderRotation {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	cfg := mocks.NewMockConfiguration(ctrl)
+	cfg.EXPECT().Len().AnyTimes().Return(4)
+	cfg.EXPECT().Replicas().AnyTimes().Return(map[hotstuff.ID]modules.Replica{1: nil, 2: nil, 3: nil, 4: nil})
+	cs := mocks.NewMockConsensus(ctrl)
+	cs.EXPECT().ChainLength().AnyTimes().Return(3)
+	cs.EXPECT().CommittedBlock().AnyTimes().DoAndReturn(func() *hotstuff.Block { return *commitHead })
+
+	chain := blockchain.New()
+	for _, block := range blocks {
+		chain.Store(block)
+	}
+	leaderRotation := leaderrotation.NewBeacon()
+	builder := modules.NewBuilder(1, nil)
+	builder.Add(cfg, cs, chain, eventloop.New(10), logging.New("test"), leaderRotation)
+	builder.Add(extra...)
+	builder.Build()
+	return leaderRotation
+}
+
+func TestBeaconAgreement(t *testing.T) {
+	blocks := createChain(50, func(view hotstuff.View) []byte { return []byte{byte(view), 1} })
+	commitHead := blocks[0]
+	leaderRotation := createBeacon(t, blocks, &commitHead)
+
+	// the leaders must not change when more blocks are committed.
+	leaders := make(map[hotstuff.View]hotstuff.ID)
+	counts := make(map[hotstuff.ID]int)
+	for view := hotstuff.View(5); view <= 50; view++ {
+		commitHead = blocks[view-4]
+		leaders[view] = leaderRotation.GetLeader(view)
+		counts[leaders[view]]++
+	}
+	commitHead = blocks[50]
+	for view, leader := range leaders {
+		if got := leaderRotation.GetLeader(view); got != leader {
+			t.Errorf("got leader %d of view %d after committing more blocks, want %d", got, view, leader)
+		}
+	}
+	if len(counts) != 4 {
+		t.Errorf("got leaders %v, want all replicas to be leaders", counts)
+	}
+
+	// the randomness depends on the signatures of the certificates.
+	other := createChain(50, func(view hotstuff.View) []byte { return []byte{byte(view), 2} })
+	beacon := leaderRotation.(modules.RandomnessBeacon)
+	otherBeacon := createBeacon(t, other, &other[50]).(modules.RandomnessBeacon)
+	if beacon.Randomness(20) == otherBeacon.Randomness(20) {
+		t.Error("got the same randomness for certificates with different signatures")
+	}
+	if beacon.Randomness(20) == beacon.Randomness(21) {
+		t.Error("got the same randomness for different views")
+	}
+}
+
+func TestBeaconVotingPower(t *testing.T) {
+	blocks := createChain(20, func(view hotstuff.View) []byte { return []byte{byte(view)} })
+	leaderRotation := createBeacon(t, blocks, &blocks[20], testPower(3))
+	for view := hotstuff.View(1); view <= 20; view++ {
+		if leader := leaderRotation.GetLeader(view); leader != 3 {
+			t.Errorf("got leader %d of view %d, want the only replica with voting power", leader, view)
+		}
+	}
+}
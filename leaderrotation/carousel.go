@@ -68,6 +68,14 @@ func (c carousel) GetLeader(round hotstuff.View) hotstuff.ID {
 			candidates = append(candidates, id)
 		}
 	})
+	// a threshold signature does not reveal its signers, so any replica that is not a recent leader is a candidate.
+	if len(candidates) == 0 {
+		for id := range c.configuration.Replicas() {
+			if !lastAuthors.Contains(id) {
+				candidates = append(candidates, id)
+			}
+		}
+	}
 	slices.Sort(candidates)
 
 	seed := c.opts.SharedRandomSeed() + int64(round)
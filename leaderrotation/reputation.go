@@ -1,6 +1,9 @@
 package leaderrotation
 
 import (
+	"bytes"
+	"encoding/binary"
+	"maps"
 	"math/rand"
 	"slices"
 
@@ -15,78 +18,97 @@ func init() {
 	modules.RegisterModule("reputation", NewRepBased)
 }
 
-type reputationsMap map[hotstuff.ID]float64
+const (
+	// reputationKey is the key of the reputations in the metadata of a block.
+	reputationKey = "reputation"
+	// reputationUnit is the initial reputation of each replica.
+	reputationUnit int64 = 1000
+	// maxReputation bounds the reputations, such that replicas with a long history still lose reputation when they time out.
+	maxReputation = 100 * reputationUnit
+	// timeoutPenalty is subtracted from the reputation of the leader of a view that timed out.
+	timeoutPenalty = reputationUnit
+)
 
+type reputationsMap map[hotstuff.ID]int64
+
+// repBased is a leader rotation that chooses leaders with probability proportional to their reputation.
+//
+// The reputations are stored in the metadata of each block, such that the leader of any view can be looked up
+// after a restart, and the replicas agree on the reputations without trusting the proposer.
+// The reputations of a block are computed from the reputations of its parent:
+// the replicas that voted for the parent gain reputation, depending on the size of the quorum,
+// and the leaders of the views that timed out between the parent and the block lose reputation.
+// These views were skipped with timeout certificates, since a replica needs a certificate for the previous view to propose.
+//
+// The leader of view v is chosen among the voters of the most recent committed block whose view is at most v-(ChainLength+1),
+// using the reputations of that block.
 type repBased struct {
-	configuration  modules.Configuration
-	consensus      modules.Consensus
-	opts           *modules.Options
-	logger         logging.Logger
-	prevCommitHead *hotstuff.Block
-	reputations    reputationsMap // latest reputations
+	blockChain    modules.BlockChain
+	configuration modules.Configuration
+	consensus     modules.Consensus
+	opts          *modules.Options
+	logger        logging.Logger
+	power         modules.VotingPower
 }
 
 // InitModule gives the module a reference to the Core object.
 // It also allows the module to set module options using the OptionsBuilder
 func (r *repBased) InitModule(mods *modules.Core) {
 	mods.Get(
+		&r.blockChain,
 		&r.configuration,
 		&r.consensus,
 		&r.opts,
 		&r.logger,
 	)
+	mods.TryGet(&r.power)
 }
 
-// TODO: should GetLeader be thread-safe?
-
 // GetLeader returns the id of the leader in the given view
 func (r *repBased) GetLeader(view hotstuff.View) hotstuff.ID {
-	block := r.consensus.CommittedBlock()
-	if block.View() > view-hotstuff.View(r.consensus.ChainLength()) {
-		// TODO: it could be possible to lookup leaders for older views if we
-		// store a copy of the reputations in a metadata field of each block.
-		r.logger.Error("looking up leaders of old views is not supported")
-		return 0
-	}
+	return r.leaderOf(r.consensus.CommittedBlock(), view)
+}
 
+// leaderOf returns the leader of the view on the branch that ends with the tip.
+func (r *repBased) leaderOf(tip *hotstuff.Block, view hotstuff.View) hotstuff.ID {
 	numReplicas := r.configuration.Len()
+	block, ok := ancestorAt(r.blockChain, tip, view, hotstuff.View(r.consensus.ChainLength()+1))
+	if !ok {
+		r.logger.Warnf("failed to find the reputations of view %d; using round-robin", view)
+		return chooseRoundRobin(view, numReplicas)
+	}
 	// use round-robin for the first few views until we get a signature
 	if block.QuorumCert().Signature() == nil {
 		return chooseRoundRobin(view, numReplicas)
 	}
 
+	reputations := r.reputations(block)
+	power := r.votingPower(view)
+	weights := make([]wr.Choice, 0, numReplicas)
+	addWeight := func(id hotstuff.ID) {
+		if reputation := reputations[id]; reputation > 0 {
+			weights = append(weights, wr.Choice{Item: id, Weight: uint(uint64(reputation) * power(id))})
+		}
+	}
 	voters := block.QuorumCert().Signature().Participants()
-	numVotes := voters.Len()
-
-	frac := float64((2.0 / 3.0) * float64(numReplicas))
-	reputation := ((float64(numVotes) - frac) / frac)
-
-	weights := make([]wr.Choice, 0, numVotes)
-	voters.ForEach(func(voterID hotstuff.ID) {
-		// we should only update the reputations once for each commit head.
-		if r.prevCommitHead.View() < block.View() {
-			r.reputations[voterID] += reputation
+	voters.ForEach(addWeight)
+	// a threshold signature does not reveal its signers, so every replica is a candidate.
+	if voters.Len() == 0 {
+		for _, id := range slices.Sorted(maps.Keys(r.configuration.Replicas())) {
+			addWeight(id)
 		}
-		weights = append(weights, wr.Choice{
-			Item:   voterID,
-			Weight: uint(r.reputations[voterID] * 10),
-		})
-	})
+	}
 
 	slices.SortFunc(weights, func(a, b wr.Choice) int {
-		return int(a.Item.(hotstuff.ID) - b.Item.(hotstuff.ID))
+		return int(a.Item.(hotstuff.ID)) - int(b.Item.(hotstuff.ID))
 	})
 
-	if r.prevCommitHead.View() < block.View() {
-		r.prevCommitHead = block
-	}
-
 	r.logger.Debug(weights)
 
 	chooser, err := wr.NewChooser(weights...)
 	if err != nil {
-		r.logger.Error("weightedrand error: ", err)
-		return 0
+		r.logger.Warnf("no candidates with reputation in view %d; using round-robin: %v", view, err)
+		return chooseRoundRobin(view, numReplicas)
 	}
 
 	seed := r.opts.SharedRandomSeed() + int64(view)
@@ -98,10 +120,97 @@ func (r *repBased) GetLeader(view hotstuff.View) hotstuff.ID {
 	return leader
 }
 
-// NewRepBased returns a new random reputation-based leader rotation implementation
-func NewRepBased() modules.LeaderRotation {
-	return &repBased{
-		reputations:    make(reputationsMap),
-		prevCommitHead: hotstuff.GetGenesis(),
+// Extend adds the reputations to a block that the replica proposes.
+func (r *repBased) Extend(block *hotstuff.Block) {
+	parent, ok := r.blockChain.Get(block.Parent())
+	if !ok {
+		r.logger.Errorf("failed to find the parent of the proposed block; cannot compute reputations")
+		return
+	}
+	block.SetMetadata(reputationKey, encodeReputations(r.nextReputations(parent, block)))
+}
+
+// Verify returns true if the reputations of the block are computed correctly from the block's parent.
+func (r *repBased) Verify(block *hotstuff.Block) bool {
+	parent, ok := r.blockChain.Get(block.Parent())
+	if !ok {
+		return false
+	}
+	return bytes.Equal(block.Metadata()[reputationKey], encodeReputations(r.nextReputations(parent, block)))
+}
+
+// nextReputations computes the reputations of the block from the reputations of its parent.
+func (r *repBased) nextReputations(parent, block *hotstuff.Block) reputationsMap {
+	reputations := r.reputations(parent)
+
+	if signature := block.QuorumCert().Signature(); signature != nil {
+		voters := signature.Participants()
+		votes := int64(hotstuff.SetPower(voters, r.votingPower(parent.View())))
+		total := int64(r.configuration.Len())
+		if r.power != nil {
+			total = int64(r.power.TotalPower(parent.View()))
+		}
+		// a quorum of two thirds gives no reputation, and a unanimous vote gives half a unit.
+		reputation := (3*votes - 2*total) * reputationUnit / (2 * total)
+		voters.ForEach(func(id hotstuff.ID) {
+			reputations[id] = min(reputations[id]+reputation, maxReputation)
+		})
+	}
+
+	for view := parent.View() + 1; view < block.View(); view++ {
+		reputations[r.leaderOf(parent, view)] -= timeoutPenalty
+	}
+	return reputations
+}
+
+// reputations returns the reputations that are stored in the block.
+// The genesis block, and blocks that were proposed without reputations, give every replica the initial reputation.
+func (r *repBased) reputations(block *hotstuff.Block) reputationsMap {
+	if reputations, ok := decodeReputations(block.Metadata()[reputationKey]); ok {
+		return reputations
+	}
+	reputations := make(reputationsMap, r.configuration.Len())
+	for id := range r.configuration.Replicas() {
+		reputations[id] = reputationUnit
+	}
+	return reputations
+}
+
+// votingPower returns a function that returns the voting power of a replica in the given view.
+// Without a VotingPower module, every replica has one vote.
+func (r *repBased) votingPower(view hotstuff.View) func(hotstuff.ID) uint64 {
+	if r.power == nil {
+		return func(hotstuff.ID) uint64 { return 1 }
 	}
+	return func(id hotstuff.ID) uint64 { return r.power.Power(view, id) }
 }
+
+// encodeReputations encodes the reputations in ascending order of ID.
+func encodeReputations(reputations reputationsMap) []byte {
+	buf := make([]byte, 0, 12*len(reputations))
+	for _, id := range slices.Sorted(maps.Keys(reputations)) {
+		buf = binary.BigEndian.AppendUint32(buf, uint32(id))
+		buf = binary.BigEndian.AppendUint64(buf, uint64(reputations[id]))
+	}
+	return buf
+}
+
+func decodeReputations(b []byte) (reputationsMap, bool) {
+	if len(b) == 0 || len(b)%12 != 0 {
+		return nil, false
+	}
+	reputations := make(reputationsMap, len(b)/12)
+	for ; len(b) > 0; b = b[12:] {
+		reputations[hotstuff.ID(binary.BigEndian.Uint32(b))] = int64(binary.BigEndian.Uint64(b[4:]))
+	}
+	return reputations, true
+}
+
+// NewRepBased returns a new random reputation-based leader rotation implementation.
+// The returned module also implements modules.BlockExtension, and must be used together with a consensus module
+// that extends the blocks that it proposes.
+func NewRepBased() modules.LeaderRotation {
+	return &repBased{}
+}
+
+var _ modules.BlockExtension = (*repBased)(nil)
@@ -0,0 +1,142 @@
+package leaderrotation_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/blockchain"
+	"github.com/relab/hotstuff/crypto"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/internal/mocks"
+	"github.com/relab/hotstuff/leaderrotation"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+	"go.uber.org/mock/gomock"
+)
+
+// voterSignature is a quorum signature with arbitrary bytes and the given voters.
+type voterSignature struct {
+	b      []byte
+	voters crypto.Bitfield
+}
+
+func (s voterSignature) ToBytes() []byte              { return s.b }
+func (s voterSignature) Participants() hotstuff.IDSet { return &s.voters }
+
+// createRepBased initializes a reputation leader rotation with a blockchain that contains the blocks.
+// The committed block is the block that commitHead points to.
+func createRepBased(t *testing.T, blocks []*hotstuff.Block, commitHead **hotstuff.Block) (modules.LeaderRotation, modules.BlockChain) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	cfg := mocks.NewMockConfiguration(ctrl)
+	cfg.EXPECT().Len().AnyTimes().Return(4)
+	cfg.EXPECT().Replicas().AnyTimes().Return(map[hotstuff.ID]modules.Replica{1: nil, 2: nil, 3: nil, 4: nil})
+	cs := mocks.NewMockConsensus(ctrl)
+	cs.EXPECT().ChainLength().AnyTimes().Return(3)
+	cs.EXPECT().CommittedBlock().AnyTimes().DoAndReturn(func() *hotstuff.Block { return *commitHead })
+
+	chain := blockchain.New()
+	for _, block := range blocks {
+		chain.Store(block)
+	}
+	leaderRotation := leaderrotation.NewRepBased()
+	builder := modules.NewBuilder(1, nil)
+	builder.Add(cfg, cs, chain, eventloop.New(10), logging.New("test"), leaderRotation)
+	builder.Build()
+	return leaderRotation, chain
+}
+
+// proposeChain proposes a block in each of the views, where replicas 1, 2 and 3 vote for each block.
+// The metadata of the blocks is added by the reputation leader rotation.
+func proposeChain(t *testing.T, views []hotstuff.View) []*hotstuff.Block {
+	t.Helper()
+	blocks := []*hotstuff.Block{hotstuff.GetGenesis()}
+	commitHead := hotstuff.GetGenesis()
+	leaderRotation, chain := createRepBased(t, nil, &commitHead)
+	extension := leaderRotation.(modules.BlockExtension)
+	for _, view := range views {
+		parent := blocks[len(blocks)-1]
+		var voters crypto.Bitfield
+		voters.Add(1)
+		voters.Add(2)
+		voters.Add(3)
+		qc := hotstuff.NewQuorumCert(voterSignature{b: []byte{byte(parent.View())}, voters: voters}, parent.View(), parent.Hash())
+		block := hotstuff.NewBlock(parent.Hash(), qc, "", view, 1)
+		extension.Extend(block)
+		if !extension.Verify(block) {
+			t.Fatalf("failed to verify the metadata of block in view %d", view)
+		}
+		chain.Store(block)
+		blocks = append(blocks, block)
+		commitHead = block
+	}
+	return blocks
+}
+
+func reputation(t *testing.T, block *hotstuff.Block, id hotstuff.ID) int64 {
+	t.Helper()
+	b := block.Metadata()["reputation"]
+	for ; len(b) >= 12; b = b[12:] {
+		if hotstuff.ID(binary.BigEndian.Uint32(b)) == id {
+			return int64(binary.BigEndian.Uint64(b[4:]))
+		}
+	}
+	t.Fatalf("block in view %d has no reputation for replica %d", block.View(), id)
+	return 0
+}
+
+func TestReputationHistoricalLookup(t *testing.T) {
+	views := make([]hotstuff.View, 0, 30)
+	for view := hotstuff.View(1); view <= 30; view++ {
+		views = append(views, view)
+	}
+	blocks := proposeChain(t, views)
+
+	// the leaders are looked up by a new instance, as after a restart, and do not change as more blocks are committed.
+	commitHead := blocks[0]
+	leaderRotation, _ := createRepBased(t, blocks, &commitHead)
+	leaders := make(map[hotstuff.View]hotstuff.ID)
+	for view := hotstuff.View(5); view <= 30; view++ {
+		commitHead = blocks[view-4]
+		leaders[view] = leaderRotation.GetLeader(view)
+	}
+	commitHead = blocks[30]
+	for view, leader := range leaders {
+		if leader == 0 || leader == 4 {
+			t.Errorf("got leader %d of view %d, want one of the voters", leader, view)
+		}
+		if got := leaderRotation.GetLeader(view); got != leader {
+			t.Errorf("got leader %d of view %d after committing more blocks, want %d", got, view, leader)
+		}
+	}
+
+	// a block with invalid reputations is rejected.
+	extension := leaderRotation.(modules.BlockExtension)
+	block := hotstuff.NewBlock(blocks[30].Hash(), blocks[30].QuorumCert(), "", 31, 1)
+	block.SetMetadata("reputation", blocks[30].Metadata()["reputation"])
+	if extension.Verify(block) {
+		t.Error("verified block with invalid reputations")
+	}
+}
+
+func TestReputationTimeoutPenalty(t *testing.T) {
+	// views 10 and 11 time out.
+	views := []hotstuff.View{1, 2, 3, 4, 5, 6, 7, 8, 9, 12}
+	blocks := proposeChain(t, views)
+	commitHead := blocks[len(blocks)-1]
+	leaderRotation, _ := createRepBased(t, blocks, &commitHead)
+
+	before, after := blocks[len(blocks)-2], blocks[len(blocks)-1]
+	penalties := make(map[hotstuff.ID]int64)
+	for _, view := range []hotstuff.View{10, 11} {
+		penalties[leaderRotation.GetLeader(view)]++
+	}
+	for id := hotstuff.ID(1); id <= 3; id++ {
+		// each voter gains an eighth of a unit for a quorum of three out of four replicas.
+		want := reputation(t, before, id) + 125 - 1000*penalties[id]
+		if got := reputation(t, after, id); got != want {
+			t.Errorf("got reputation %d of replica %d, want %d", got, id, want)
+		}
+	}
+}
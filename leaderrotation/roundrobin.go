@@ -1,6 +1,9 @@
 package leaderrotation
 
 import (
+	"maps"
+	"slices"
+
 	"github.com/relab/hotstuff"
 	"github.com/relab/hotstuff/modules"
 )
@@ -11,15 +14,23 @@ func init() {
 
 type roundRobin struct {
 	configuration modules.Configuration
+	epochs        modules.EpochManager
 }
 
 func (rr *roundRobin) InitModule(mods *modules.Core) {
 	mods.Get(&rr.configuration)
+	mods.TryGet(&rr.epochs)
 }
 
 // GetLeader returns the id of the leader in the given view
 func (rr roundRobin) GetLeader(view hotstuff.View) hotstuff.ID {
-	// TODO: does not support reconfiguration
+	if rr.epochs != nil {
+		// rotate among the validators of the view's epoch, in ascending order of ID.
+		ids := slices.Sorted(maps.Keys(rr.epochs.Validators(view)))
+		if len(ids) > 0 {
+			return ids[view%hotstuff.View(len(ids))]
+		}
+	}
 	// assume IDs start at 1
 	return chooseRoundRobin(view, rr.configuration.Len())
 }
@@ -32,3 +43,17 @@ func NewRoundRobin() modules.LeaderRotation {
 func chooseRoundRobin(view hotstuff.View, numReplicas int) hotstuff.ID {
 	return hotstuff.ID(view%hotstuff.View(numReplicas) + 1)
 }
+
+// ancestorAt returns the most recent block on the branch that ends with the tip whose view is at most view-lookback.
+// It returns the genesis block if there is no such block.
+func ancestorAt(blockChain modules.BlockChain, tip *hotstuff.Block, view, lookback hotstuff.View) (*hotstuff.Block, bool) {
+	block := tip
+	for block.View()+lookback > view && block != hotstuff.GetGenesis() {
+		parent, ok := blockChain.Get(block.Parent())
+		if !ok {
+			return nil, false
+		}
+		block = parent
+	}
+	return block, true
+}
@@ -0,0 +1,279 @@
+package mempool
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"github.com/relab/hotstuff/modules"
+)
+
+// fetchTimeout is the time to wait for a replica to respond to a request for a batch.
+const fetchTimeout = time.Second
+
+// batchFetchedEvent is sent on the event loop when a missing batch has been fetched, or when fetching it failed.
+type batchFetchedEvent struct{}
+
+// pendingBlock is a committed block whose batches have not been delivered yet.
+type pendingBlock struct {
+	block *hotstuff.Block
+	cert  hotstuff.QuorumCert
+	certs []hotstuff.AvailabilityCert
+}
+
+// WrapExecutor returns an executor that replaces the availability certificates in the committed blocks
+// with the commands of the batches in their causal history, and then passes the blocks on to the given executor.
+// Blocks are passed on in the order that they were committed.
+func (m *Mempool) WrapExecutor(executor modules.ExecutorExt) modules.ExecutorExt {
+	m.executor = executor
+	return &mempoolExecutor{pool: m, executor: executor}
+}
+
+// mempoolExecutor delivers the batches of committed blocks to another executor.
+type mempoolExecutor struct {
+	pool     *Mempool
+	executor modules.ExecutorExt
+}
+
+func (e *mempoolExecutor) InitModule(mods *modules.Core) {
+	if m, ok := e.executor.(modules.Module); ok {
+		m.InitModule(mods)
+	}
+}
+
+func (e *mempoolExecutor) Exec(block *hotstuff.Block) {
+	e.ExecCertified(block, hotstuff.QuorumCert{})
+}
+
+func (e *mempoolExecutor) ExecCertified(block *hotstuff.Block, cert hotstuff.QuorumCert) {
+	m := e.pool
+	certs, err := m.decode(block.Command())
+	if err != nil {
+		m.logger.Errorf("Failed to unmarshal availability certificates: %v", err)
+	}
+	m.pending = append(m.pending, &pendingBlock{block: block, cert: cert, certs: certs})
+	m.tryDeliver()
+}
+
+// tryDeliver delivers the pending blocks in order, until it reaches a block with batches that are missing.
+// The missing batches are fetched from the other replicas.
+func (m *Mempool) tryDeliver() {
+	for len(m.pending) > 0 {
+		p := m.pending[0]
+		batches, missing := m.history(p.certs)
+		if len(missing) > 0 {
+			m.fetch(missing)
+			return
+		}
+		m.pending = m.pending[1:]
+		m.deliver(p, batches)
+	}
+}
+
+// history returns the batches in the causal history of the certificates that have not been delivered,
+// in order of round and author, and the digests of the batches in the history that are missing.
+func (m *Mempool) history(certs []hotstuff.AvailabilityCert) (batches []*hotstuff.MempoolBatch, missing []hotstuff.Hash) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	visited := make(map[hotstuff.Hash]struct{})
+	var visit func(digest hotstuff.Hash, round uint64)
+	visit = func(digest hotstuff.Hash, round uint64) {
+		if round < m.gcRound {
+			return
+		}
+		if _, ok := visited[digest]; ok {
+			return
+		}
+		visited[digest] = struct{}{}
+		if _, ok := m.delivered[digest]; ok {
+			return
+		}
+		batch, ok := m.batches[digest]
+		if !ok {
+			missing = append(missing, digest)
+			return
+		}
+		batches = append(batches, batch)
+		for _, parent := range batch.Parents {
+			visit(parent, batch.Round-1)
+		}
+	}
+	for _, cert := range certs {
+		visit(cert.Digest(), cert.Round())
+	}
+
+	slices.SortFunc(batches, func(a, b *hotstuff.MempoolBatch) int {
+		if a.Round != b.Round {
+			if a.Round < b.Round {
+				return -1
+			}
+			return 1
+		}
+		return int(a.Author) - int(b.Author)
+	})
+	return batches, missing
+}
+
+// fetch requests the missing batches from the replicas that certified them, or from all replicas
+// if the certificate of a batch is unknown.
+func (m *Mempool) fetch(missing []hotstuff.Hash) {
+	if m.sender == nil {
+		return
+	}
+	for _, digest := range missing {
+		m.mut.Lock()
+		if _, ok := m.fetching[digest]; ok {
+			m.mut.Unlock()
+			continue
+		}
+		m.fetching[digest] = struct{}{}
+		var ids []hotstuff.ID
+		if cert, ok := m.certs[digest]; ok {
+			cert.Signature().Participants().ForEach(func(id hotstuff.ID) { ids = append(ids, id) })
+		}
+		// a threshold signature does not reveal the replicas that stored the batch.
+		if len(ids) == 0 {
+			for id := range m.configuration.Replicas() {
+				ids = append(ids, id)
+			}
+		}
+		m.mut.Unlock()
+		slices.Sort(ids)
+
+		m.logger.Debugf("Fetching batch %.8s", digest)
+		go m.fetchFrom(m.eventLoop.Context(), digest, ids)
+	}
+}
+
+// fetchFrom requests the batch from each of the replicas in turn, until one of them responds with the batch.
+func (m *Mempool) fetchFrom(ctx context.Context, digest hotstuff.Hash, ids []hotstuff.ID) {
+	defer func() {
+		m.mut.Lock()
+		delete(m.fetching, digest)
+		m.mut.Unlock()
+	}()
+	for _, id := range ids {
+		if id == m.opts.ID() {
+			continue
+		}
+		fetchCtx, cancel := context.WithTimeout(ctx, fetchTimeout)
+		batch, err := m.sender.FetchMempoolBatch(fetchCtx, id, digest)
+		cancel()
+		if err != nil || batch.Digest() != digest {
+			continue
+		}
+		m.mut.Lock()
+		m.batches[digest] = batch
+		m.link(digest)
+		m.mut.Unlock()
+		m.eventLoop.AddEvent(batchFetchedEvent{})
+		return
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	m.logger.Infof("Failed to fetch batch %.8s", digest)
+	// try again later.
+	time.AfterFunc(fetchTimeout, func() { m.eventLoop.AddEvent(batchFetchedEvent{}) })
+}
+
+// deliver passes the block on to the executor, with the commands of the batches in place of the certificates.
+func (m *Mempool) deliver(p *pendingBlock, batches []*hotstuff.MempoolBatch) {
+	merged := new(clientpb.Batch)
+	var highest uint64
+	for _, batch := range batches {
+		b := new(clientpb.Batch)
+		if err := m.unmarshaler.Unmarshal(batch.Data, b); err != nil {
+			m.logger.Infof("Failed to unmarshal batch from replica %d in round %d: %v", batch.Author, batch.Round, err)
+		} else {
+			merged.Commands = append(merged.Commands, b.GetCommands()...)
+			merged.Evidence = append(merged.Evidence, b.GetEvidence()...)
+		}
+		highest = max(highest, batch.Round)
+	}
+
+	m.mut.Lock()
+	delivered := make(map[hotstuff.Hash]uint64, len(batches))
+	for _, batch := range batches {
+		m.delivered[batch.Digest()] = batch.Round
+		delivered[batch.Digest()] = batch.Round
+	}
+	if highest > gcDepth {
+		m.collectGarbage(highest - gcDepth)
+	}
+	gcRound := m.gcRound
+	m.mut.Unlock()
+
+	b, err := m.marshaler.Marshal(merged)
+	if err != nil {
+		m.logger.Errorf("Failed to marshal batch: %v", err)
+		return
+	}
+	// the block keeps the hash of the committed block, such that it matches the certificate.
+	block := p.block.WithCommand(hotstuff.Command(b))
+
+	if ce, ok := m.executor.(modules.CertifiedExecutor); ok && p.cert.BlockHash() == p.block.Hash() {
+		ce.ExecCertified(block, p.cert)
+	} else {
+		m.executor.Exec(block)
+	}
+
+	// the batches are recorded as delivered after they have been executed. If the replica crashes in between,
+	// the commands are delivered again after the restart, and the client sessions discard the duplicates.
+	if m.store != nil {
+		if err := m.store.AddDeliveredBatches(delivered, gcRound); err != nil {
+			m.logger.Errorf("Failed to save delivered batches: %v", err)
+		}
+	}
+}
+
+// collectGarbage removes the batches and certificates from the rounds below the given round.
+// The caller must hold the lock.
+func (m *Mempool) collectGarbage(round uint64) {
+	if round <= m.gcRound {
+		return
+	}
+	m.gcRound = round
+	for digest, batch := range m.batches {
+		if batch.Round < round {
+			delete(m.batches, digest)
+			delete(m.waiting, digest)
+			delete(m.votes, digest)
+		}
+	}
+	for digest, cert := range m.certs {
+		if cert.Round() < round {
+			delete(m.certs, digest)
+			delete(m.referenced, digest)
+			delete(m.proposed, digest)
+		}
+	}
+	for digest, r := range m.delivered {
+		if r < round {
+			delete(m.delivered, digest)
+		}
+	}
+	for r := range m.rounds {
+		if r < round {
+			delete(m.rounds, r)
+		}
+	}
+	for k := range m.voted {
+		if k.round < round {
+			delete(m.voted, k)
+		}
+	}
+	for k := range m.received {
+		if k.round < round {
+			delete(m.received, k)
+		}
+	}
+}
+
+var (
+	_ modules.ExecutorExt       = (*mempoolExecutor)(nil)
+	_ modules.CertifiedExecutor = (*mempoolExecutor)(nil)
+)
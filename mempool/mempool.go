@@ -0,0 +1,687 @@
+// Package mempool implements a data-dissemination layer that is decoupled from the ordering of the commands.
+//
+// Each replica takes batches of client commands from its command queue and broadcasts them to the other replicas,
+// which store the batch and reply with a signed availability vote. Once the author of a batch has collected votes
+// from a quorum, it combines them into an availability certificate and broadcasts it. Since a quorum of replicas
+// have stored the batch, any replica can retrieve it later. Each batch belongs to a round, and references the
+// certified batches of the previous round, such that the certified batches form a DAG. A replica only creates a
+// batch for the next round once it knows the certificates of a quorum of the batches in the current round.
+//
+// The consensus protocol orders the availability certificates instead of the commands: the Mempool acts as the
+// command queue, and proposes the certificates of the batches at the tips of the DAG. When a block is committed,
+// the executor returned by WrapExecutor delivers the batches in the causal history of its certificates that have
+// not been delivered before, in order of round and author. The batches that the replica is missing are fetched
+// from the replicas that certified them.
+//
+// See Narwhal and Tusk: https://arxiv.org/abs/2105.11827
+package mempool
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/blockchain"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+	"google.golang.org/protobuf/proto"
+)
+
+// gcDepth is the number of rounds below the highest delivered round for which the batches are kept.
+// Batches from older rounds are considered delivered.
+const gcDepth = 50
+
+// maxRoundsAhead is the number of rounds after the highest round with the certificates of a quorum of batches
+// for which batches from other replicas are stored. A correct replica only creates a batch for a round once it
+// knows the certificates of a quorum of batches in the previous round, so batches from later rounds are either
+// from a faulty replica, or from a replica that this replica lags behind. The latter are fetched when they are delivered.
+const maxRoundsAhead = 2
+
+// key identifies the batch of an author in a round.
+type key struct {
+	round  uint64
+	author hotstuff.ID
+}
+
+// Mempool disseminates batches of commands and collects availability certificates for them.
+type Mempool struct {
+	configuration modules.Configuration
+	crypto        modules.Crypto
+	eventLoop     *eventloop.EventLoop
+	logger        logging.Logger
+	opts          *modules.Options
+	power         modules.VotingPower
+	synchronizer  modules.Synchronizer
+	sender        modules.MempoolSender // nil if the configuration cannot disseminate batches.
+	store         *blockchain.StateStore
+
+	queue       modules.CommandQueue
+	executor    modules.ExecutorExt // the executor that the delivered blocks are passed on to.
+	marshaler   proto.MarshalOptions
+	unmarshaler proto.UnmarshalOptions
+
+	mut        sync.Mutex
+	changed    chan struct{} // closed when a batch is certified.
+	round      uint64        // the round of the latest batch created by this replica.
+	highest    uint64        // the highest round of a batch created by another replica.
+	quorum     uint64        // the highest round with certificates for the batches of a quorum of authors.
+	cancelGet  context.CancelFunc
+	getRound   uint64
+	batches    map[hotstuff.Hash]*hotstuff.MempoolBatch
+	certs      map[hotstuff.Hash]hotstuff.AvailabilityCert
+	rounds     map[uint64]map[hotstuff.ID]hotstuff.Hash                   // the certified batches of each round.
+	votes      map[hotstuff.Hash]map[hotstuff.ID]hotstuff.QuorumSignature // the votes for own batches that are not yet certified.
+	received   map[key]hotstuff.Hash                                      // the first batch received from each author in each round.
+	voted      map[key]hotstuff.Hash                                      // the batches that this replica has voted for.
+	waiting    map[hotstuff.Hash]struct{}                                 // batches that wait for the certificates of their parents.
+	referenced map[hotstuff.Hash]struct{}                                 // certified batches that are parents of a certified batch.
+	proposed   map[hotstuff.Hash]struct{}                                 // certified batches that have been proposed.
+	delivered  map[hotstuff.Hash]uint64                                   // the round of each delivered batch.
+	gcRound    uint64                                                     // batches below this round are considered delivered.
+	fetching   map[hotstuff.Hash]struct{}
+
+	pending []*pendingBlock // committed blocks whose batches have not been delivered yet.
+}
+
+// Option configures a Mempool.
+type Option func(*Mempool)
+
+// WithStateStore persists the delivered batches in the state store,
+// such that they are not delivered again after a restart.
+func WithStateStore(store *blockchain.StateStore) Option {
+	return func(m *Mempool) {
+		m.store = store
+	}
+}
+
+// New returns a new Mempool that takes the batches of commands that it disseminates from the queue.
+func New(queue modules.CommandQueue, opts ...Option) *Mempool {
+	m := &Mempool{
+		queue:       queue,
+		marshaler:   proto.MarshalOptions{Deterministic: true},
+		unmarshaler: proto.UnmarshalOptions{DiscardUnknown: true},
+		changed:     make(chan struct{}),
+		batches:     make(map[hotstuff.Hash]*hotstuff.MempoolBatch),
+		certs:       make(map[hotstuff.Hash]hotstuff.AvailabilityCert),
+		rounds:      make(map[uint64]map[hotstuff.ID]hotstuff.Hash),
+		votes:       make(map[hotstuff.Hash]map[hotstuff.ID]hotstuff.QuorumSignature),
+		received:    make(map[key]hotstuff.Hash),
+		voted:       make(map[key]hotstuff.Hash),
+		waiting:     make(map[hotstuff.Hash]struct{}),
+		referenced:  make(map[hotstuff.Hash]struct{}),
+		proposed:    make(map[hotstuff.Hash]struct{}),
+		delivered:   make(map[hotstuff.Hash]uint64),
+		fetching:    make(map[hotstuff.Hash]struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// InitModule gives the module access to the other modules.
+func (m *Mempool) InitModule(mods *modules.Core) {
+	mods.Get(
+		&m.configuration,
+		&m.crypto,
+		&m.eventLoop,
+		&m.logger,
+		&m.opts,
+		&m.synchronizer,
+	)
+	mods.TryGet(&m.power)
+	m.sender, _ = m.configuration.(modules.MempoolSender)
+
+	if m.store != nil {
+		delivered, gcRound, err := m.store.GetDeliveredBatches()
+		if err != nil {
+			m.logger.Errorf("Failed to load delivered batches: %v", err)
+		}
+		m.delivered, m.gcRound = delivered, gcRound
+	}
+
+	// The batches, votes, and certificates are handled outside the event loop,
+	// since the event loop may be blocked in Get while waiting for new certificates.
+	m.eventLoop.RegisterHandler(hotstuff.MempoolBatchMsg{}, func(event any) {
+		m.OnBatch(event.(hotstuff.MempoolBatchMsg))
+	}, eventloop.UnsafeRunInAddEvent())
+	m.eventLoop.RegisterHandler(hotstuff.AvailabilityVoteMsg{}, func(event any) {
+		m.OnVote(event.(hotstuff.AvailabilityVoteMsg))
+	}, eventloop.UnsafeRunInAddEvent())
+	m.eventLoop.RegisterHandler(hotstuff.AvailabilityCertMsg{}, func(event any) {
+		m.OnCert(event.(hotstuff.AvailabilityCertMsg))
+	}, eventloop.UnsafeRunInAddEvent())
+
+	// The committed blocks are delivered by the event loop once their batches have arrived.
+	m.eventLoop.RegisterHandler(hotstuff.MempoolBatchMsg{}, func(_ any) {
+		m.tryDeliver()
+	})
+	m.eventLoop.RegisterHandler(batchFetchedEvent{}, func(_ any) {
+		m.tryDeliver()
+	})
+}
+
+// Run creates and disseminates batches until the context is canceled.
+func (m *Mempool) Run(ctx context.Context) {
+	for {
+		round, parents, ok := m.nextRound(ctx)
+		if !ok {
+			return
+		}
+		data, ok := m.nextData(ctx, round)
+		if !ok {
+			return
+		}
+		m.disseminate(&hotstuff.MempoolBatch{
+			Author:  m.opts.ID(),
+			Round:   round,
+			Parents: parents,
+			Data:    data,
+		})
+	}
+}
+
+// nextRound waits until the replica knows the certificates of a quorum of batches in the round of its latest batch,
+// and returns the next round and the digests of the certified batches that the batch of the next round references.
+func (m *Mempool) nextRound(ctx context.Context) (round uint64, parents []hotstuff.Hash, ok bool) {
+	m.mut.Lock()
+	for m.quorum < m.round {
+		changed := m.changed
+		m.mut.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return 0, nil, false
+		}
+		m.mut.Lock()
+	}
+	defer m.mut.Unlock()
+
+	round = m.quorum + 1
+	if round > 1 {
+		parents = sortedDigests(m.rounds[m.quorum])
+	}
+	return round, parents, true
+}
+
+// nextData returns the next batch of commands from the queue.
+// If another replica has already created a batch in the round, or if a proposed batch of commands
+// has not been delivered yet, an empty batch is returned when no commands are ready, such that
+// the DAG, and thereby the consensus protocol, can progress.
+func (m *Mempool) nextData(ctx context.Context, round uint64) (data []byte, ok bool) {
+	getCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	m.mut.Lock()
+	if m.highest >= round || m.awaitingCommit() {
+		cancel()
+	} else {
+		m.cancelGet, m.getRound = cancel, round
+	}
+	m.mut.Unlock()
+
+	cmd, ok := m.queue.Get(getCtx)
+
+	m.mut.Lock()
+	m.cancelGet = nil
+	m.mut.Unlock()
+
+	if ctx.Err() != nil {
+		return nil, false
+	}
+	if !ok {
+		return nil, true
+	}
+	return []byte(cmd), true
+}
+
+// awaitingCommit returns true if a proposed batch of commands has not been delivered yet.
+// The consensus protocol may need further proposals to commit the batch.
+// The caller must hold the lock.
+func (m *Mempool) awaitingCommit() bool {
+	for digest := range m.proposed {
+		if _, ok := m.delivered[digest]; ok {
+			continue
+		}
+		if batch, ok := m.batches[digest]; !ok || len(batch.Data) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// disseminate sends the batch to the other replicas, and votes for it.
+func (m *Mempool) disseminate(batch *hotstuff.MempoolBatch) {
+	digest := batch.Digest()
+	m.mut.Lock()
+	m.round = batch.Round
+	m.votes[digest] = make(map[hotstuff.ID]hotstuff.QuorumSignature)
+	m.mut.Unlock()
+
+	m.logger.Debugf("Disseminating %v", batch)
+	if m.sender != nil {
+		m.sender.MempoolBatch(batch)
+	}
+	m.OnBatch(hotstuff.MempoolBatchMsg{ID: m.opts.ID(), Batch: batch})
+}
+
+// OnBatch stores a batch, and votes for it once the replica knows the certificates of its parents.
+// Only the first batch of each author in each round is stored, and only if its round is at most
+// maxRoundsAhead rounds ahead of this replica and its parents are not known to be invalid.
+func (m *Mempool) OnBatch(msg hotstuff.MempoolBatchMsg) {
+	batch := msg.Batch
+	if batch == nil || batch.Author != msg.ID {
+		return
+	}
+	digest := batch.Digest()
+	k := key{round: batch.Round, author: batch.Author}
+
+	m.mut.Lock()
+	if batch.Round < m.gcRound || batch.Round > m.quorum+maxRoundsAhead {
+		m.mut.Unlock()
+		return
+	}
+	if first, ok := m.received[k]; ok {
+		if first != digest {
+			m.logger.Infof("Replica %d sent more than one batch in round %d", batch.Author, batch.Round)
+		}
+		m.mut.Unlock()
+		return
+	}
+	if !m.validParents(batch) {
+		m.mut.Unlock()
+		m.logger.Infof("Batch from replica %d in round %d has invalid parents", batch.Author, batch.Round)
+		return
+	}
+	m.received[k] = digest
+	if _, ok := m.batches[digest]; !ok {
+		m.batches[digest] = batch
+		m.link(digest)
+	}
+	if batch.Author != m.opts.ID() && batch.Round > m.highest {
+		m.highest = batch.Round
+		if m.cancelGet != nil && m.highest >= m.getRound {
+			m.cancelGet()
+		}
+	}
+	m.waiting[digest] = struct{}{}
+	votes := m.vote()
+	m.mut.Unlock()
+
+	m.sendVotes(votes)
+}
+
+// validParents returns false if the parents of the batch can never be the certified batches of a quorum
+// of authors in the previous round. The parents are checked again once their certificates are known.
+// The caller must hold the lock.
+func (m *Mempool) validParents(batch *hotstuff.MempoolBatch) bool {
+	if batch.Round <= 1 {
+		return len(batch.Parents) == 0
+	}
+	if len(batch.Parents) == 0 || len(batch.Parents) > m.configuration.Len() {
+		return false
+	}
+	seen := make(map[hotstuff.Hash]struct{}, len(batch.Parents))
+	for _, parent := range batch.Parents {
+		if _, ok := seen[parent]; ok {
+			return false
+		}
+		seen[parent] = struct{}{}
+	}
+	_, valid := m.parentsCertified(batch)
+	return valid
+}
+
+// OnVote collects the votes for the batches of this replica, and certifies a batch once a quorum has voted for it.
+func (m *Mempool) OnVote(vote hotstuff.AvailabilityVoteMsg) {
+	m.mut.Lock()
+	batch, ok := m.batches[vote.Digest]
+	votes, collecting := m.votes[vote.Digest]
+	if !ok || !collecting {
+		m.mut.Unlock()
+		return
+	}
+	if _, ok := votes[vote.ID]; ok {
+		m.mut.Unlock()
+		return
+	}
+	m.mut.Unlock()
+
+	if vote.Signature == nil {
+		return
+	}
+	if signers := vote.Signature.Participants(); signers.Len() != 1 || !signers.Contains(vote.ID) {
+		return
+	}
+	if !m.crypto.Verify(vote.Signature, hotstuff.AvailabilityBytes(vote.Digest, batch.Author, batch.Round)) {
+		m.logger.Infof("Invalid availability vote from replica %d", vote.ID)
+		return
+	}
+
+	m.mut.Lock()
+	votes, collecting = m.votes[vote.Digest]
+	if !collecting {
+		m.mut.Unlock()
+		return
+	}
+	votes[vote.ID] = vote.Signature
+	signers := hotstuff.NewIDSet()
+	sigs := make([]hotstuff.QuorumSignature, 0, len(votes))
+	for id, sig := range votes {
+		signers.Add(id)
+		sigs = append(sigs, sig)
+	}
+	if !modules.HasQuorum(m.configuration, m.power, m.synchronizer.View(), signers) {
+		m.mut.Unlock()
+		return
+	}
+	delete(m.votes, vote.Digest)
+	m.mut.Unlock()
+
+	sig, err := m.crypto.Combine(sigs...)
+	if err != nil {
+		m.logger.Errorf("Failed to combine availability votes: %v", err)
+		return
+	}
+	cert := hotstuff.NewAvailabilityCert(sig, vote.Digest, batch.Author, batch.Round)
+	m.logger.Debugf("Certified %v", cert)
+	if m.sender != nil {
+		m.sender.AvailabilityCert(cert)
+	}
+	m.addCert(cert)
+}
+
+// OnCert adds a certificate received from another replica to the DAG.
+func (m *Mempool) OnCert(msg hotstuff.AvailabilityCertMsg) {
+	m.mut.Lock()
+	_, known := m.certs[msg.Cert.Digest()]
+	m.mut.Unlock()
+	if known {
+		return
+	}
+	if !m.VerifyCert(msg.Cert) {
+		m.logger.Infof("Invalid availability certificate from replica %d", msg.ID)
+		return
+	}
+	m.addCert(msg.Cert)
+}
+
+// VerifyCert returns true if the certificate is signed by a quorum of replicas.
+func (m *Mempool) VerifyCert(cert hotstuff.AvailabilityCert) bool {
+	sig := cert.Signature()
+	if sig == nil || !modules.HasQuorumSignature(m.configuration, m.power, m.synchronizer.View(), sig) {
+		return false
+	}
+	return m.crypto.Verify(sig, cert.ToBytes())
+}
+
+// addCert adds a verified certificate to the DAG.
+func (m *Mempool) addCert(cert hotstuff.AvailabilityCert) {
+	digest := cert.Digest()
+
+	m.mut.Lock()
+	if _, ok := m.certs[digest]; ok || cert.Round() < m.gcRound {
+		m.mut.Unlock()
+		return
+	}
+	m.certs[digest] = cert
+	round, ok := m.rounds[cert.Round()]
+	if !ok {
+		round = make(map[hotstuff.ID]hotstuff.Hash)
+		m.rounds[cert.Round()] = round
+	}
+	round[cert.Author()] = digest
+	if cert.Round() > m.quorum && modules.HasQuorum(m.configuration, m.power, m.synchronizer.View(), authors(round)) {
+		m.quorum = cert.Round()
+	}
+	m.link(digest)
+	votes := m.vote()
+	close(m.changed)
+	m.changed = make(chan struct{})
+	m.mut.Unlock()
+
+	m.sendVotes(votes)
+}
+
+// link records that the parents of the batch are referenced, if the batch is certified.
+// The caller must hold the lock.
+func (m *Mempool) link(digest hotstuff.Hash) {
+	batch, ok := m.batches[digest]
+	if !ok {
+		return
+	}
+	if _, ok := m.certs[digest]; !ok {
+		return
+	}
+	for _, parent := range batch.Parents {
+		m.referenced[parent] = struct{}{}
+	}
+}
+
+// vote creates votes for the waiting batches whose parents are certified.
+// A replica votes for at most one batch of each author in each round.
+// The caller must hold the lock.
+func (m *Mempool) vote() (votes []hotstuff.AvailabilityVoteMsg) {
+	for digest := range m.waiting {
+		batch := m.batches[digest]
+		k := key{round: batch.Round, author: batch.Author}
+		if _, ok := m.voted[k]; ok {
+			delete(m.waiting, digest)
+			continue
+		}
+		ready, valid := m.parentsCertified(batch)
+		if !valid {
+			m.logger.Infof("Batch from replica %d in round %d has invalid parents", batch.Author, batch.Round)
+			delete(m.waiting, digest)
+			continue
+		}
+		if !ready {
+			continue
+		}
+		delete(m.waiting, digest)
+		sig, err := m.crypto.Sign(hotstuff.AvailabilityBytes(digest, batch.Author, batch.Round))
+		if err != nil {
+			m.logger.Errorf("Failed to sign availability vote: %v", err)
+			continue
+		}
+		m.voted[k] = digest
+		votes = append(votes, hotstuff.AvailabilityVoteMsg{ID: m.opts.ID(), Digest: digest, Signature: sig})
+	}
+	return votes
+}
+
+// parentsCertified returns ready = true if the parents of the batch are certified batches of the previous round
+// from a quorum of authors, and valid = false if the parents can never be.
+// The caller must hold the lock.
+func (m *Mempool) parentsCertified(batch *hotstuff.MempoolBatch) (ready, valid bool) {
+	if batch.Round <= 1 {
+		return len(batch.Parents) == 0, len(batch.Parents) == 0
+	}
+	parents := hotstuff.NewIDSet()
+	for _, parent := range batch.Parents {
+		cert, ok := m.certs[parent]
+		if !ok {
+			return false, true
+		}
+		if cert.Round() != batch.Round-1 || parents.Contains(cert.Author()) {
+			return false, false
+		}
+		parents.Add(cert.Author())
+	}
+	ok := modules.HasQuorum(m.configuration, m.power, m.synchronizer.View(), parents)
+	return ok, ok
+}
+
+// sendVotes sends the votes to the authors of the batches.
+func (m *Mempool) sendVotes(votes []hotstuff.AvailabilityVoteMsg) {
+	for _, vote := range votes {
+		m.mut.Lock()
+		author := m.batches[vote.Digest].Author
+		m.mut.Unlock()
+		if author == m.opts.ID() {
+			m.OnVote(vote)
+		} else if m.sender != nil {
+			m.sender.AvailabilityVote(author, vote)
+		}
+	}
+}
+
+// Get returns the availability certificates of the certified batches at the tips of the DAG that have not been
+// proposed or delivered yet. Get waits until there is at least one such certificate, or until the context is canceled.
+func (m *Mempool) Get(ctx context.Context) (cmd hotstuff.Command, ok bool) {
+	m.mut.Lock()
+	certs := m.tips()
+	for len(certs) == 0 {
+		changed := m.changed
+		m.mut.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return "", false
+		}
+		m.mut.Lock()
+		certs = m.tips()
+	}
+	m.mut.Unlock()
+
+	b, err := m.marshaler.Marshal(certsToProto(certs))
+	if err != nil {
+		m.logger.Errorf("Failed to marshal availability certificates: %v", err)
+		return "", false
+	}
+	return hotstuff.Command(b), true
+}
+
+// tips returns the certificates of the batches that are not referenced by another certified batch,
+// and that have not been proposed or delivered, in order of round and author.
+// The caller must hold the lock.
+func (m *Mempool) tips() (certs []hotstuff.AvailabilityCert) {
+	for digest, cert := range m.certs {
+		if _, ok := m.referenced[digest]; ok {
+			continue
+		}
+		if _, ok := m.proposed[digest]; ok {
+			continue
+		}
+		if _, ok := m.delivered[digest]; ok {
+			continue
+		}
+		certs = append(certs, cert)
+	}
+	sortCerts(certs)
+	return certs
+}
+
+// Accept returns true if the command consists of valid availability certificates.
+func (m *Mempool) Accept(cmd hotstuff.Command) bool {
+	certs, err := m.decode(cmd)
+	if err != nil {
+		m.logger.Infof("Failed to unmarshal availability certificates: %v", err)
+		return false
+	}
+	if len(certs) == 0 {
+		return false
+	}
+	for _, cert := range certs {
+		m.mut.Lock()
+		_, known := m.certs[cert.Digest()]
+		m.mut.Unlock()
+		if !known && !m.VerifyCert(cert) {
+			m.logger.Infof("Invalid availability certificate in proposal: %v", cert)
+			return false
+		}
+	}
+	return true
+}
+
+// Proposed records that the certificates in the command have been proposed, such that they are not proposed again.
+// If the replica is waiting for commands to create its next batch, it creates an empty batch instead,
+// such that there are certificates for the proposals that are needed to commit the proposed batches.
+func (m *Mempool) Proposed(cmd hotstuff.Command) {
+	certs, err := m.decode(cmd)
+	if err != nil {
+		return
+	}
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	for _, cert := range certs {
+		m.proposed[cert.Digest()] = struct{}{}
+	}
+	if m.cancelGet != nil && m.awaitingCommit() {
+		m.cancelGet()
+	}
+}
+
+// MempoolBatch returns the batch with the given digest.
+func (m *Mempool) MempoolBatch(digest hotstuff.Hash) (*hotstuff.MempoolBatch, bool) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	batch, ok := m.batches[digest]
+	return batch, ok
+}
+
+// decode returns the availability certificates in the command.
+func (m *Mempool) decode(cmd hotstuff.Command) ([]hotstuff.AvailabilityCert, error) {
+	msg := new(hotstuffpb.AvailabilityCerts)
+	if err := m.unmarshaler.Unmarshal([]byte(cmd), msg); err != nil {
+		return nil, err
+	}
+	certs := make([]hotstuff.AvailabilityCert, len(msg.GetCerts()))
+	for i, cert := range msg.GetCerts() {
+		certs[i] = hotstuffpb.AvailabilityCertFromProto(cert)
+	}
+	return certs, nil
+}
+
+func certsToProto(certs []hotstuff.AvailabilityCert) *hotstuffpb.AvailabilityCerts {
+	msg := &hotstuffpb.AvailabilityCerts{Certs: make([]*hotstuffpb.AvailabilityCert, len(certs))}
+	for i, cert := range certs {
+		msg.Certs[i] = hotstuffpb.AvailabilityCertToProto(cert)
+	}
+	return msg
+}
+
+// sortCerts sorts the certificates by round and author.
+func sortCerts(certs []hotstuff.AvailabilityCert) {
+	slices.SortFunc(certs, func(a, b hotstuff.AvailabilityCert) int {
+		if a.Round() != b.Round() {
+			if a.Round() < b.Round() {
+				return -1
+			}
+			return 1
+		}
+		return int(a.Author()) - int(b.Author())
+	})
+}
+
+// sortedDigests returns the digests of the batches in order of author.
+func sortedDigests(batches map[hotstuff.ID]hotstuff.Hash) []hotstuff.Hash {
+	ids := make([]hotstuff.ID, 0, len(batches))
+	for id := range batches {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	digests := make([]hotstuff.Hash, len(ids))
+	for i, id := range ids {
+		digests[i] = batches[id]
+	}
+	return digests
+}
+
+// authors returns the set of authors of the batches.
+func authors(batches map[hotstuff.ID]hotstuff.Hash) hotstuff.IDSet {
+	set := hotstuff.NewIDSet()
+	for id := range batches {
+		set.Add(id)
+	}
+	return set
+}
+
+var (
+	_ modules.CommandQueue    = (*Mempool)(nil)
+	_ modules.Acceptor        = (*Mempool)(nil)
+	_ modules.MempoolProvider = (*Mempool)(nil)
+)
@@ -0,0 +1,401 @@
+package mempool
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/crypto"
+	"github.com/relab/hotstuff/crypto/eddsa"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+	"google.golang.org/protobuf/proto"
+)
+
+// testReplica holds the public key of a replica.
+type testReplica struct {
+	modules.Replica
+	id  hotstuff.ID
+	key ed25519.PublicKey
+}
+
+func (r testReplica) ID() hotstuff.ID               { return r.id }
+func (r testReplica) PublicKey() hotstuff.PublicKey { return r.key }
+
+// testBlockChain is never used by the mempool.
+type testBlockChain struct {
+	modules.BlockChain
+}
+
+type testSynchronizer struct {
+	modules.Synchronizer
+}
+
+func (testSynchronizer) View() hotstuff.View { return 1 }
+
+// testNetwork delivers the messages between the pools when run is called.
+type testNetwork struct {
+	replicas  map[hotstuff.ID]modules.Replica
+	pools     map[hotstuff.ID]*Mempool
+	executors map[hotstuff.ID]modules.ExecutorExt // the executors returned by WrapExecutor.
+	queue     []func()
+}
+
+func (n *testNetwork) run() {
+	for len(n.queue) > 0 {
+		f := n.queue[0]
+		n.queue = n.queue[1:]
+		f()
+	}
+}
+
+// testConfig sends the messages of a pool through the network.
+type testConfig struct {
+	modules.Configuration
+	id  hotstuff.ID
+	net *testNetwork
+}
+
+func (cfg *testConfig) Replicas() map[hotstuff.ID]modules.Replica { return cfg.net.replicas }
+
+func (cfg *testConfig) Replica(id hotstuff.ID) (modules.Replica, bool) {
+	replica, ok := cfg.net.replicas[id]
+	return replica, ok
+}
+
+func (cfg *testConfig) Len() int        { return len(cfg.net.pools) }
+func (cfg *testConfig) QuorumSize() int { return hotstuff.QuorumSize(len(cfg.net.pools)) }
+
+func (cfg *testConfig) MempoolBatch(batch *hotstuff.MempoolBatch) {
+	for id, pool := range cfg.net.pools {
+		if id != cfg.id {
+			cfg.net.queue = append(cfg.net.queue, func() { pool.OnBatch(hotstuff.MempoolBatchMsg{ID: cfg.id, Batch: batch}) })
+		}
+	}
+}
+
+func (cfg *testConfig) AvailabilityVote(author hotstuff.ID, vote hotstuff.AvailabilityVoteMsg) {
+	pool := cfg.net.pools[author]
+	cfg.net.queue = append(cfg.net.queue, func() { pool.OnVote(vote) })
+}
+
+func (cfg *testConfig) AvailabilityCert(cert hotstuff.AvailabilityCert) {
+	for id, pool := range cfg.net.pools {
+		if id != cfg.id {
+			cfg.net.queue = append(cfg.net.queue, func() { pool.OnCert(hotstuff.AvailabilityCertMsg{ID: cfg.id, Cert: cert}) })
+		}
+	}
+}
+
+func (cfg *testConfig) FetchMempoolBatch(_ context.Context, id hotstuff.ID, digest hotstuff.Hash) (*hotstuff.MempoolBatch, error) {
+	if batch, ok := cfg.net.pools[id].MempoolBatch(digest); ok {
+		return batch, nil
+	}
+	return nil, errors.New("not found")
+}
+
+// testExecutor records the executed blocks.
+type testExecutor struct {
+	blocks []*hotstuff.Block
+}
+
+func (e *testExecutor) Exec(block *hotstuff.Block) {
+	e.blocks = append(e.blocks, block)
+}
+
+func newTestNetwork(t *testing.T, n int) (*testNetwork, map[hotstuff.ID]*testExecutor) {
+	t.Helper()
+	net := &testNetwork{
+		replicas:  make(map[hotstuff.ID]modules.Replica),
+		pools:     make(map[hotstuff.ID]*Mempool),
+		executors: make(map[hotstuff.ID]modules.ExecutorExt),
+	}
+	keys := make(map[hotstuff.ID]ed25519.PrivateKey)
+	for i := 1; i <= n; i++ {
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		net.replicas[hotstuff.ID(i)] = testReplica{id: hotstuff.ID(i), key: pub}
+		keys[hotstuff.ID(i)] = priv
+	}
+	executors := make(map[hotstuff.ID]*testExecutor)
+	for i := 1; i <= n; i++ {
+		id := hotstuff.ID(i)
+		pool := New(nil)
+		executors[id] = &testExecutor{}
+		net.executors[id] = pool.WrapExecutor(executors[id])
+		builder := modules.NewBuilder(id, keys[id])
+		builder.Add(
+			eventloop.New(100),
+			logging.New("test"),
+			testBlockChain{},
+			crypto.New(eddsa.New()),
+			&testConfig{id: id, net: net},
+			testSynchronizer{},
+			pool,
+			net.executors[id],
+		)
+		builder.Build()
+		net.pools[id] = pool
+	}
+	return net, executors
+}
+
+// testData returns a batch with a single command that identifies the author and round of the batch.
+func testData(t *testing.T, author hotstuff.ID, round uint64) []byte {
+	t.Helper()
+	b, err := proto.Marshal(&clientpb.Batch{Commands: []*clientpb.Command{{ClientID: uint32(author), SequenceNumber: round}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// createRound lets each of the authors create a batch for the next round and runs the network.
+func createRound(t *testing.T, net *testNetwork, authors ...hotstuff.ID) {
+	t.Helper()
+	for _, id := range authors {
+		pool := net.pools[id]
+		round, parents, ok := pool.nextRound(context.Background())
+		if !ok {
+			t.Fatalf("replica %d cannot create a batch", id)
+		}
+		pool.disseminate(&hotstuff.MempoolBatch{Author: id, Round: round, Parents: parents, Data: testData(t, id, round)})
+	}
+	net.run()
+}
+
+func getCerts(t *testing.T, pool *Mempool) (hotstuff.Command, []hotstuff.AvailabilityCert) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	cmd, ok := pool.Get(ctx)
+	if !ok {
+		t.Fatal("no certificates")
+	}
+	certs, err := pool.decode(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cmd, certs
+}
+
+func commit(cmd hotstuff.Command) *hotstuff.Block {
+	genesis := hotstuff.GetGenesis()
+	return hotstuff.NewBlock(genesis.Hash(), hotstuff.NewQuorumCert(nil, 0, genesis.Hash()), cmd, 1, 1)
+}
+
+// delivered returns the author and round of each command in the executed blocks.
+func delivered(t *testing.T, executor *testExecutor) (got [][2]uint64) {
+	t.Helper()
+	for _, block := range executor.blocks {
+		batch := new(clientpb.Batch)
+		if err := proto.Unmarshal([]byte(block.Command()), batch); err != nil {
+			t.Fatal(err)
+		}
+		for _, cmd := range batch.GetCommands() {
+			got = append(got, [2]uint64{uint64(cmd.GetClientID()), cmd.GetSequenceNumber()})
+		}
+	}
+	return got
+}
+
+func TestCertification(t *testing.T) {
+	net, _ := newTestNetwork(t, 4)
+	createRound(t, net, 1, 2, 3, 4)
+
+	for id, pool := range net.pools {
+		if pool.quorum != 1 {
+			t.Errorf("replica %d: quorum round is %d, want 1", id, pool.quorum)
+		}
+		if _, certs := getCerts(t, pool); len(certs) != 4 {
+			t.Errorf("replica %d: got %d certificates, want 4", id, len(certs))
+		}
+	}
+
+	// the batches of the second round reference the certified batches of the first round.
+	createRound(t, net, 1, 2, 3)
+	_, certs := getCerts(t, net.pools[4])
+	if len(certs) != 3 {
+		t.Fatalf("got %d certificates, want 3", len(certs))
+	}
+	for i, cert := range certs {
+		if cert.Round() != 2 || cert.Author() != hotstuff.ID(i+1) {
+			t.Errorf("certificate %d is for %v", i, cert)
+		}
+		if cert.Signature().Participants().Len() < 3 {
+			t.Errorf("certificate %d is signed by fewer than a quorum", i)
+		}
+	}
+	batch, ok := net.pools[4].MempoolBatch(certs[0].Digest())
+	if !ok || len(batch.Parents) != 4 {
+		t.Errorf("the batch does not reference the first round")
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	net, executors := newTestNetwork(t, 4)
+	createRound(t, net, 1, 2, 3, 4)
+	createRound(t, net, 1, 2, 3)
+
+	cmd, _ := getCerts(t, net.pools[1])
+	net.pools[1].Proposed(cmd)
+	net.pools[1].mut.Lock()
+	awaiting := net.pools[1].awaitingCommit()
+	net.pools[1].mut.Unlock()
+	if !awaiting {
+		t.Error("the proposed batches are not awaiting commit")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, ok := net.pools[1].Get(ctx); ok {
+		t.Error("proposed certificates were returned again")
+	}
+
+	// replica 2 has lost a batch, which must be fetched before the block is delivered.
+	lost := net.pools[2].rounds[1][4]
+	net.pools[2].mut.Lock()
+	delete(net.pools[2].batches, lost)
+	net.pools[2].mut.Unlock()
+
+	want := [][2]uint64{{1, 1}, {2, 1}, {3, 1}, {4, 1}, {1, 2}, {2, 2}, {3, 2}}
+	check := func(id hotstuff.ID) {
+		t.Helper()
+		got := delivered(t, executors[id])
+		if len(got) != len(want) {
+			t.Fatalf("replica %d: delivered %v, want %v", id, got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("replica %d: delivered %v, want %v", id, got, want)
+			}
+		}
+	}
+	for id, executor := range net.executors {
+		executor.Exec(commit(cmd))
+		if id != 2 {
+			check(id)
+		}
+	}
+
+	if len(executors[2].blocks) != 0 {
+		t.Fatal("replica 2 delivered a block with a missing batch")
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, ok := net.pools[2].MempoolBatch(lost); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("replica 2 did not fetch the missing batch")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	net.pools[2].tryDeliver()
+	check(2)
+	net.pools[1].mut.Lock()
+	awaiting = net.pools[1].awaitingCommit()
+	net.pools[1].mut.Unlock()
+	if awaiting {
+		t.Error("the delivered batches are still awaiting commit")
+	}
+
+	// the delivered batches are not delivered again.
+	createRound(t, net, 4)
+	cmd, certs := getCerts(t, net.pools[4])
+	if len(certs) != 1 || certs[0].Round() != 3 || certs[0].Author() != 4 {
+		t.Fatalf("got certificates %v, want the batch of replica 4 in round 3", certs)
+	}
+	block := commit(cmd)
+	net.executors[4].Exec(block)
+	if got := delivered(t, executors[4]); len(got) != len(want)+1 || got[len(want)] != [2]uint64{4, 3} {
+		t.Errorf("delivered %v", got)
+	}
+	if got := executors[4].blocks[len(executors[4].blocks)-1]; got.Hash() != block.Hash() {
+		t.Errorf("delivered block has hash %.8s, want the hash of the committed block %.8s", got.Hash(), block.Hash())
+	}
+}
+
+func TestOnBatch(t *testing.T) {
+	net, _ := newTestNetwork(t, 4)
+	createRound(t, net, 1, 2, 3, 4)
+	pool := net.pools[1]
+	_, certs := getCerts(t, pool)
+	parents := make([]hotstuff.Hash, len(certs))
+	for i, cert := range certs {
+		parents[i] = cert.Digest()
+	}
+
+	tests := []struct {
+		name  string
+		batch *hotstuff.MempoolBatch
+	}{
+		{name: "FarRound", batch: &hotstuff.MempoolBatch{Author: 2, Round: 1 + maxRoundsAhead + 1, Parents: parents}},
+		{name: "FirstRoundWithParents", batch: &hotstuff.MempoolBatch{Author: 2, Round: 1, Parents: parents}},
+		{name: "DuplicateParents", batch: &hotstuff.MempoolBatch{Author: 2, Round: 2, Parents: []hotstuff.Hash{parents[0], parents[0], parents[1]}}},
+		{name: "ParentsFromWrongRound", batch: &hotstuff.MempoolBatch{Author: 2, Round: 3, Parents: parents}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool.OnBatch(hotstuff.MempoolBatchMsg{ID: tt.batch.Author, Batch: tt.batch})
+			if _, ok := pool.MempoolBatch(tt.batch.Digest()); ok {
+				t.Error("the invalid batch was stored")
+			}
+			if pool.highest > 1 {
+				t.Errorf("the highest round was raised to %d by an invalid batch", pool.highest)
+			}
+		})
+	}
+
+	t.Run("Equivocation", func(t *testing.T) {
+		first := &hotstuff.MempoolBatch{Author: 3, Round: 2, Parents: parents, Data: testData(t, 3, 2)}
+		second := &hotstuff.MempoolBatch{Author: 3, Round: 2, Parents: parents, Data: testData(t, 3, 3)}
+		pool.OnBatch(hotstuff.MempoolBatchMsg{ID: 3, Batch: first})
+		pool.OnBatch(hotstuff.MempoolBatchMsg{ID: 3, Batch: second})
+		if _, ok := pool.MempoolBatch(first.Digest()); !ok {
+			t.Error("the first batch was not stored")
+		}
+		if _, ok := pool.MempoolBatch(second.Digest()); ok {
+			t.Error("a second batch from the same author in the same round was stored")
+		}
+	})
+}
+
+func TestAccept(t *testing.T) {
+	net, _ := newTestNetwork(t, 4)
+	createRound(t, net, 1, 2, 3, 4)
+	cmd, certs := getCerts(t, net.pools[1])
+
+	// a replica that has not seen the certificates verifies them.
+	other := net.pools[4]
+	other.mut.Lock()
+	other.certs = make(map[hotstuff.Hash]hotstuff.AvailabilityCert)
+	other.mut.Unlock()
+	if !other.Accept(cmd) {
+		t.Error("valid certificates were not accepted")
+	}
+	if other.Accept("invalid") {
+		t.Error("an invalid command was accepted")
+	}
+	empty, _ := proto.Marshal(certsToProto(nil))
+	if other.Accept(hotstuff.Command(empty)) {
+		t.Error("a command without certificates was accepted")
+	}
+
+	sig, err := net.pools[1].crypto.Sign(certs[0].ToBytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	weak := hotstuff.NewAvailabilityCert(sig, certs[0].Digest(), certs[0].Author(), certs[0].Round())
+	b, _ := proto.Marshal(certsToProto([]hotstuff.AvailabilityCert{weak}))
+	if other.Accept(hotstuff.Command(b)) {
+		t.Error("a certificate signed by fewer than a quorum was accepted")
+	}
+}
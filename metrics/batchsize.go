@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/metrics/types"
+	"github.com/relab/hotstuff/modules"
+)
+
+func init() {
+	RegisterReplicaMetric("batch-size", func() any {
+		return &BatchSize{}
+	})
+}
+
+// BatchSize measures the size of the batches proposed by the replica, and the effective batch size.
+type BatchSize struct {
+	metricsLogger Logger
+	opts          *modules.Options
+
+	commands  Welford
+	bytes     Welford
+	batchSize int
+}
+
+// InitModule gives the module access to the other modules.
+func (bs *BatchSize) InitModule(mods *modules.Core) {
+	var (
+		eventLoop *eventloop.EventLoop
+		logger    logging.Logger
+	)
+
+	mods.Get(
+		&bs.metricsLogger,
+		&bs.opts,
+		&eventLoop,
+		&logger,
+	)
+
+	eventLoop.RegisterHandler(hotstuff.BatchEvent{}, func(event any) {
+		bs.recordBatch(event.(hotstuff.BatchEvent))
+	})
+
+	eventLoop.RegisterHandler(types.TickEvent{}, func(event any) {
+		bs.tick(event.(types.TickEvent))
+	}, eventloop.Prioritize())
+
+	logger.Info("BatchSize metric enabled")
+}
+
+func (bs *BatchSize) recordBatch(event hotstuff.BatchEvent) {
+	bs.commands.Update(float64(event.Commands))
+	bs.bytes.Update(float64(event.Bytes))
+	bs.batchSize = event.BatchSize
+}
+
+func (bs *BatchSize) tick(_ types.TickEvent) {
+	commands, _, count := bs.commands.Get()
+	bytes, _, _ := bs.bytes.Get()
+	bs.metricsLogger.Log(&types.BatchSizeMeasurement{
+		Event:     types.NewReplicaEvent(uint32(bs.opts.ID()), time.Now()),
+		Commands:  commands,
+		Bytes:     bytes,
+		Count:     count,
+		BatchSize: uint64(bs.batchSize),
+	})
+	bs.commands.Reset()
+	bs.bytes.Reset()
+}
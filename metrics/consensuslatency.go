@@ -33,7 +33,7 @@ func (lr *ConsensusLatency) InitModule(mods *modules.Core) {
 
 	mods.Get(
 		&lr.metricsLogger,
-		opts,
+		&opts,
 		&eventLoop,
 		&logger,
 	)
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.35.1
+// 	protoc-gen-go v1.36.2
 // 	protoc        v4.25.1
 // source: metrics/types/types.proto
 
@@ -23,11 +23,10 @@ const (
 )
 
 type StartEvent struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *Event                 `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Event *Event `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *StartEvent) Reset() {
@@ -71,13 +70,12 @@ func (x *StartEvent) GetEvent() *Event {
 // It contains the ID of the replica/client, the type (replica/client),
 // the timestamp of the event, and the data.
 type Event struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ID            uint32                 `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Client        bool                   `protobuf:"varint,2,opt,name=Client,proto3" json:"Client,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=Timestamp,proto3" json:"Timestamp,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	ID        uint32                 `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
-	Client    bool                   `protobuf:"varint,2,opt,name=Client,proto3" json:"Client,omitempty"`
-	Timestamp *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=Timestamp,proto3" json:"Timestamp,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Event) Reset() {
@@ -132,14 +130,13 @@ func (x *Event) GetTimestamp() *timestamppb.Timestamp {
 }
 
 type ThroughputMeasurement struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *Event                 `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
+	Commits       uint64                 `protobuf:"varint,2,opt,name=Commits,proto3" json:"Commits,omitempty"`
+	Commands      uint64                 `protobuf:"varint,3,opt,name=Commands,proto3" json:"Commands,omitempty"`
+	Duration      *durationpb.Duration   `protobuf:"bytes,4,opt,name=Duration,proto3" json:"Duration,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Event    *Event               `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
-	Commits  uint64               `protobuf:"varint,2,opt,name=Commits,proto3" json:"Commits,omitempty"`
-	Commands uint64               `protobuf:"varint,3,opt,name=Commands,proto3" json:"Commands,omitempty"`
-	Duration *durationpb.Duration `protobuf:"bytes,4,opt,name=Duration,proto3" json:"Duration,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ThroughputMeasurement) Reset() {
@@ -201,14 +198,13 @@ func (x *ThroughputMeasurement) GetDuration() *durationpb.Duration {
 }
 
 type LatencyMeasurement struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *Event                 `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
+	Latency       float64                `protobuf:"fixed64,2,opt,name=Latency,proto3" json:"Latency,omitempty"`
+	Variance      float64                `protobuf:"fixed64,3,opt,name=Variance,proto3" json:"Variance,omitempty"`
+	Count         uint64                 `protobuf:"varint,4,opt,name=Count,proto3" json:"Count,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Event    *Event  `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
-	Latency  float64 `protobuf:"fixed64,2,opt,name=Latency,proto3" json:"Latency,omitempty"`
-	Variance float64 `protobuf:"fixed64,3,opt,name=Variance,proto3" json:"Variance,omitempty"`
-	Count    uint64  `protobuf:"varint,4,opt,name=Count,proto3" json:"Count,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *LatencyMeasurement) Reset() {
@@ -269,21 +265,100 @@ func (x *LatencyMeasurement) GetCount() uint64 {
 	return 0
 }
 
-type ViewTimeouts struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+type BatchSizeMeasurement struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Event *Event                 `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
+	// Mean number of commands per proposed batch.
+	Commands float64 `protobuf:"fixed64,2,opt,name=Commands,proto3" json:"Commands,omitempty"`
+	// Mean size of the proposed batches in bytes.
+	Bytes float64 `protobuf:"fixed64,3,opt,name=Bytes,proto3" json:"Bytes,omitempty"`
+	// Number of proposed batches since last reading.
+	Count uint64 `protobuf:"varint,4,opt,name=Count,proto3" json:"Count,omitempty"`
+	// The effective batch size at the time of the reading.
+	BatchSize     uint64 `protobuf:"varint,5,opt,name=BatchSize,proto3" json:"BatchSize,omitempty"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchSizeMeasurement) Reset() {
+	*x = BatchSizeMeasurement{}
+	mi := &file_metrics_types_types_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchSizeMeasurement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchSizeMeasurement) ProtoMessage() {}
+
+func (x *BatchSizeMeasurement) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_types_types_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchSizeMeasurement.ProtoReflect.Descriptor instead.
+func (*BatchSizeMeasurement) Descriptor() ([]byte, []int) {
+	return file_metrics_types_types_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchSizeMeasurement) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *BatchSizeMeasurement) GetCommands() float64 {
+	if x != nil {
+		return x.Commands
+	}
+	return 0
+}
+
+func (x *BatchSizeMeasurement) GetBytes() float64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
 
-	Event *Event `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
+func (x *BatchSizeMeasurement) GetCount() uint64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *BatchSizeMeasurement) GetBatchSize() uint64 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+type ViewTimeouts struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Event *Event                 `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
 	// Number of views since last reading.
 	Views uint64 `protobuf:"varint,2,opt,name=Views,proto3" json:"Views,omitempty"`
 	// Number of view timeouts.
-	Timeouts uint64 `protobuf:"varint,3,opt,name=Timeouts,proto3" json:"Timeouts,omitempty"`
+	Timeouts      uint64 `protobuf:"varint,3,opt,name=Timeouts,proto3" json:"Timeouts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ViewTimeouts) Reset() {
 	*x = ViewTimeouts{}
-	mi := &file_metrics_types_types_proto_msgTypes[4]
+	mi := &file_metrics_types_types_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -295,7 +370,7 @@ func (x *ViewTimeouts) String() string {
 func (*ViewTimeouts) ProtoMessage() {}
 
 func (x *ViewTimeouts) ProtoReflect() protoreflect.Message {
-	mi := &file_metrics_types_types_proto_msgTypes[4]
+	mi := &file_metrics_types_types_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -308,7 +383,7 @@ func (x *ViewTimeouts) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ViewTimeouts.ProtoReflect.Descriptor instead.
 func (*ViewTimeouts) Descriptor() ([]byte, []int) {
-	return file_metrics_types_types_proto_rawDescGZIP(), []int{4}
+	return file_metrics_types_types_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *ViewTimeouts) GetEvent() *Event {
@@ -370,16 +445,26 @@ var file_metrics_types_types_proto_rawDesc = []byte{
 	0x12, 0x1a, 0x0a, 0x08, 0x56, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01,
 	0x28, 0x01, 0x52, 0x08, 0x56, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x14, 0x0a, 0x05,
 	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x43, 0x6f, 0x75,
-	0x6e, 0x74, 0x22, 0x64, 0x0a, 0x0c, 0x56, 0x69, 0x65, 0x77, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75,
-	0x74, 0x73, 0x12, 0x22, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x0c, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52,
-	0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x56, 0x69, 0x65, 0x77, 0x73, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x56, 0x69, 0x65, 0x77, 0x73, 0x12, 0x1a, 0x0a, 0x08,
-	0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08,
-	0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73, 0x42, 0x29, 0x5a, 0x27, 0x67, 0x69, 0x74, 0x68,
-	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x72, 0x65, 0x6c, 0x61, 0x62, 0x2f, 0x68, 0x6f, 0x74,
-	0x73, 0x74, 0x75, 0x66, 0x66, 0x2f, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2f, 0x74, 0x79,
-	0x70, 0x65, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6e, 0x74, 0x22, 0xa0, 0x01, 0x0a, 0x14, 0x42, 0x61, 0x74, 0x63, 0x68, 0x53, 0x69, 0x7a, 0x65,
+	0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x22, 0x0a, 0x05, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x74, 0x79, 0x70,
+	0x65, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12,
+	0x1a, 0x0a, 0x08, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x08, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x42,
+	0x79, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x42, 0x79, 0x74, 0x65,
+	0x73, 0x12, 0x14, 0x0a, 0x05, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x05, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x53, 0x69, 0x7a, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x53, 0x69, 0x7a, 0x65, 0x22, 0x64, 0x0a, 0x0c, 0x56, 0x69, 0x65, 0x77, 0x54, 0x69, 0x6d,
+	0x65, 0x6f, 0x75, 0x74, 0x73, 0x12, 0x22, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x45, 0x76, 0x65,
+	0x6e, 0x74, 0x52, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x56, 0x69, 0x65,
+	0x77, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x56, 0x69, 0x65, 0x77, 0x73, 0x12,
+	0x1a, 0x0a, 0x08, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x08, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73, 0x42, 0x29, 0x5a, 0x27, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x72, 0x65, 0x6c, 0x61, 0x62, 0x2f,
+	0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x2f, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73,
+	0x2f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -394,28 +479,30 @@ func file_metrics_types_types_proto_rawDescGZIP() []byte {
 	return file_metrics_types_types_proto_rawDescData
 }
 
-var file_metrics_types_types_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_metrics_types_types_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_metrics_types_types_proto_goTypes = []any{
 	(*StartEvent)(nil),            // 0: types.StartEvent
 	(*Event)(nil),                 // 1: types.Event
 	(*ThroughputMeasurement)(nil), // 2: types.ThroughputMeasurement
 	(*LatencyMeasurement)(nil),    // 3: types.LatencyMeasurement
-	(*ViewTimeouts)(nil),          // 4: types.ViewTimeouts
-	(*timestamppb.Timestamp)(nil), // 5: google.protobuf.Timestamp
-	(*durationpb.Duration)(nil),   // 6: google.protobuf.Duration
+	(*BatchSizeMeasurement)(nil),  // 4: types.BatchSizeMeasurement
+	(*ViewTimeouts)(nil),          // 5: types.ViewTimeouts
+	(*timestamppb.Timestamp)(nil), // 6: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 7: google.protobuf.Duration
 }
 var file_metrics_types_types_proto_depIdxs = []int32{
 	1, // 0: types.StartEvent.Event:type_name -> types.Event
-	5, // 1: types.Event.Timestamp:type_name -> google.protobuf.Timestamp
+	6, // 1: types.Event.Timestamp:type_name -> google.protobuf.Timestamp
 	1, // 2: types.ThroughputMeasurement.Event:type_name -> types.Event
-	6, // 3: types.ThroughputMeasurement.Duration:type_name -> google.protobuf.Duration
+	7, // 3: types.ThroughputMeasurement.Duration:type_name -> google.protobuf.Duration
 	1, // 4: types.LatencyMeasurement.Event:type_name -> types.Event
-	1, // 5: types.ViewTimeouts.Event:type_name -> types.Event
-	6, // [6:6] is the sub-list for method output_type
-	6, // [6:6] is the sub-list for method input_type
-	6, // [6:6] is the sub-list for extension type_name
-	6, // [6:6] is the sub-list for extension extendee
-	0, // [0:6] is the sub-list for field type_name
+	1, // 5: types.BatchSizeMeasurement.Event:type_name -> types.Event
+	1, // 6: types.ViewTimeouts.Event:type_name -> types.Event
+	7, // [7:7] is the sub-list for method output_type
+	7, // [7:7] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_metrics_types_types_proto_init() }
@@ -429,7 +516,7 @@ func file_metrics_types_types_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_metrics_types_types_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
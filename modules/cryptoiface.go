@@ -14,6 +14,40 @@ type CryptoBase interface {
 	BatchVerify(signature hotstuff.QuorumSignature, batch map[hotstuff.ID][]byte) bool
 }
 
+// SigningKind describes what a signed message is.
+type SigningKind uint8
+
+const (
+	// SignOther is a message that cannot conflict with other messages, such as a mempool availability vote.
+	SignOther SigningKind = iota
+	// SignBlock is a block that the replica proposes or votes for.
+	SignBlock
+	// SignTimeout is a timeout message, or the view of a timeout message.
+	SignTimeout
+)
+
+// SigningContext describes a message that is signed, such that a signer can refuse to sign conflicting messages.
+type SigningContext struct {
+	Kind SigningKind
+	View hotstuff.View
+}
+
+// ContextSigner is an optional interface for CryptoBase implementations that need to know what they sign,
+// such as remote signers that protect against signing two different blocks in the same view.
+type ContextSigner interface {
+	// SignWithContext creates a cryptographic signature of the given message, which is described by the context.
+	SignWithContext(ctx SigningContext, message []byte) (signature hotstuff.QuorumSignature, err error)
+}
+
+// SignWithContext signs the message with the signer, and tells the signer what the message is
+// if it implements ContextSigner.
+func SignWithContext(signer CryptoBase, ctx SigningContext, message []byte) (signature hotstuff.QuorumSignature, err error) {
+	if cs, ok := signer.(ContextSigner); ok {
+		return cs.SignWithContext(ctx, message)
+	}
+	return signer.Sign(message)
+}
+
 // Crypto implements the methods required to create and verify signatures and certificates.
 // This is a higher level interface that is implemented by the crypto package itself.
 type Crypto interface {
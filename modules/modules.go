@@ -46,6 +46,14 @@ type ExecutorExt interface {
 	Exec(block *hotstuff.Block)
 }
 
+// CertifiedExecutor is an optional extension of ExecutorExt.
+// If the executor implements this interface, the consensus protocol calls ExecCertified instead of Exec,
+// passing along the quorum certificate that certified the committed block.
+type CertifiedExecutor interface {
+	// ExecCertified executes the command in the block that was certified by the given quorum certificate.
+	ExecCertified(block *hotstuff.Block, cert hotstuff.QuorumCert)
+}
+
 //go:generate mockgen -destination=../internal/mocks/forkhandler_mock.go -package=mocks . ForkHandler
 
 // ForkHandler handles commands that do not get committed due to a forked blockchain.
@@ -125,6 +133,48 @@ type Configuration interface {
 	SubConfig(ids []hotstuff.ID) (sub Configuration, err error)
 }
 
+// EvidenceSender is an optional interface for configurations that can gossip evidence of misbehaving replicas.
+type EvidenceSender interface {
+	// Evidence sends the evidence to all replicas in the configuration.
+	Evidence(evidence hotstuff.Evidence)
+}
+
+// MempoolSender is an optional interface for configurations that can disseminate the batches of the mempool.
+type MempoolSender interface {
+	// MempoolBatch sends the batch to all replicas in the configuration.
+	MempoolBatch(batch *hotstuff.MempoolBatch)
+	// AvailabilityVote sends the vote to the author of the batch.
+	AvailabilityVote(author hotstuff.ID, vote hotstuff.AvailabilityVoteMsg)
+	// AvailabilityCert sends the certificate to all replicas in the configuration.
+	AvailabilityCert(cert hotstuff.AvailabilityCert)
+	// FetchMempoolBatch requests the batch with the given digest from the replica with the given ID.
+	FetchMempoolBatch(ctx context.Context, id hotstuff.ID, digest hotstuff.Hash) (*hotstuff.MempoolBatch, error)
+}
+
+// RangeFetcher is an optional interface for configurations that can fetch a range of blocks from a single replica.
+type RangeFetcher interface {
+	// FetchRange requests the blocks with views in the range [from, to] on the branch that ends with the tip block
+	// from the replica with the given ID. The blocks are returned in ascending order of view.
+	FetchRange(ctx context.Context, id hotstuff.ID, tip hotstuff.Hash, from, to hotstuff.View) ([]*hotstuff.Block, error)
+}
+
+// Reconfigurable is an optional interface for configurations whose set of replicas can change at runtime.
+type Reconfigurable interface {
+	// Reconfigure replaces the replicas of the configuration with the given validators.
+	// Connections to the replicas that remain in the configuration are kept.
+	Reconfigure(validators []hotstuff.Validator) error
+}
+
+// SnapshotFetcher is an optional interface for configurations that can download state snapshots from a single replica.
+type SnapshotFetcher interface {
+	// FetchCheckpoint requests the checkpoint of the block with the given hash from the replica with the given ID.
+	// If the hash is the zero hash, the latest checkpoint of the replica is requested.
+	FetchCheckpoint(ctx context.Context, id hotstuff.ID, hash hotstuff.Hash) (hotstuff.Checkpoint, error)
+	// FetchTrieNodes requests the encoded trie nodes with the given hashes from the replica with the given ID.
+	// Nodes that the replica does not have are left out of the response.
+	FetchTrieNodes(ctx context.Context, id hotstuff.ID, hashes []hotstuff.Hash) ([][]byte, error)
+}
+
 //go:generate mockgen -destination=../internal/mocks/consensus_mock.go -package=mocks . Consensus
 
 // Consensus implements a byzantine consensus protocol, such as HotStuff.
@@ -200,6 +250,137 @@ func (fhw forkHandlerWrapper) Fork(block *hotstuff.Block) {
 	fhw.forkHandler.Fork(block.Command())
 }
 
+// BlockSync downloads the blocks that a lagging replica is missing from the other replicas.
+type BlockSync interface {
+	// Syncing returns true while the replica is downloading blocks.
+	// The replica does not vote while it is syncing.
+	Syncing() bool
+}
+
+// SnapshotProvider serves the state snapshots of the replica to other replicas.
+type SnapshotProvider interface {
+	// Checkpoint returns the checkpoint of the block with the given hash,
+	// or the latest checkpoint if the hash is the zero hash.
+	Checkpoint(hash hotstuff.Hash) (hotstuff.Checkpoint, bool)
+	// TrieNodes returns the encoded trie nodes with the given hashes. Nodes that are not found are left out.
+	TrieNodes(hashes []hotstuff.Hash) [][]byte
+}
+
+// MempoolProvider serves the batches of the mempool to other replicas.
+type MempoolProvider interface {
+	// MempoolBatch returns the batch with the given digest.
+	MempoolBatch(digest hotstuff.Hash) (*hotstuff.MempoolBatch, bool)
+}
+
+// Pruner is an optional interface for modules that store the bodies or receipts of committed blocks.
+type Pruner interface {
+	// PruneBelow removes the data of the committed blocks with a view lower than the given view.
+	// If archive is not nil, each removed entry is passed to archive before it is removed.
+	PruneBelow(view hotstuff.View, archive func(key, value []byte) error) error
+}
+
+// StateSync downloads the application state of a certified checkpoint from the other replicas,
+// such that a new replica does not have to download and execute every block since genesis.
+type StateSync interface {
+	// Sync starts downloading the state of the latest checkpoint.
+	// A StateSyncedEvent is raised when the state has been installed.
+	Sync()
+	// Syncing returns true while the replica is downloading the state.
+	Syncing() bool
+}
+
+// EpochManager tracks the validator set of each epoch, such that certificates are verified
+// against the public keys of the replicas that were validators in the view of the certificate.
+type EpochManager interface {
+	// Epoch returns the epoch that the view belongs to.
+	Epoch(view hotstuff.View) hotstuff.Epoch
+	// Validators returns the public keys of the validators of the epoch that the view belongs to.
+	Validators(view hotstuff.View) map[hotstuff.ID]hotstuff.PublicKey
+}
+
+// KeyedVerifier is an optional interface for CryptoBase implementations that can verify a quorum signature
+// against a given set of public keys, rather than the public keys of the replicas in the current configuration.
+type KeyedVerifier interface {
+	// VerifyWithKeys verifies the quorum signature against the message using the given public keys.
+	VerifyWithKeys(signature hotstuff.QuorumSignature, message []byte, keys map[hotstuff.ID]hotstuff.PublicKey) bool
+}
+
+// BatchVerifier is an optional interface for CryptoBase implementations that can verify signatures
+// on different messages together faster than one by one.
+type BatchVerifier interface {
+	// VerifyBatch returns true if every signature is valid for the message at the same index.
+	// Each signature must be created by a single replica.
+	// If it returns false, at least one of the signatures is invalid, but not necessarily all of them.
+	VerifyBatch(signatures []hotstuff.QuorumSignature, messages [][]byte) bool
+}
+
+// VerificationPool is an optional module that verifies signatures on a bounded number of workers,
+// which are shared by the modules that verify signatures.
+type VerificationPool interface {
+	// Submit queues the signature for verification, and calls done with the result on one of the workers.
+	// Signatures that are queued while the workers are busy are verified together.
+	Submit(signature hotstuff.QuorumSignature, message []byte, done func(valid bool))
+	// ForEach calls f for each index in [0, n), on the calling goroutine and on the idle workers,
+	// and returns when all calls have returned.
+	ForEach(n int, f func(i int))
+}
+
+// EvidencePool is an optional module that collects evidence of replicas that signed conflicting blocks,
+// such that the evidence can be included in a later block and the offenders can be punished.
+type EvidencePool interface {
+	// Pending returns the evidence that has been verified, but not yet committed.
+	Pending() []hotstuff.Evidence
+	// Verify returns an error if the evidence does not prove that the offender signed two different blocks in the same view.
+	Verify(evidence hotstuff.Evidence) error
+}
+
+// VotingPower is an optional module that assigns a voting power to each validator.
+// When it is present, a set of replicas is a quorum if they hold more than two thirds of the total voting power,
+// instead of when they are at least Configuration.QuorumSize replicas.
+type VotingPower interface {
+	// Power returns the voting power of the replica in the given view.
+	Power(view hotstuff.View, id hotstuff.ID) uint64
+	// TotalPower returns the total voting power of the validators in the given view.
+	TotalPower(view hotstuff.View) uint64
+}
+
+// BlockExtension is an optional module that extends the blocks with metadata.
+// The consensus module calls Extend for the blocks that the replica proposes,
+// and does not vote for blocks whose metadata is rejected by Verify.
+type BlockExtension interface {
+	// Extend adds metadata to a new block, before the block is signed.
+	Extend(block *hotstuff.Block)
+	// Verify returns true if the metadata of the block is valid.
+	Verify(block *hotstuff.Block) bool
+}
+
+// RandomnessBeacon is an optional module that provides shared randomness for each view.
+// The randomness of a view cannot be predicted until a few views earlier,
+// and replicas that have committed the same blocks agree on it.
+type RandomnessBeacon interface {
+	// Randomness returns the random value of the given view.
+	Randomness(view hotstuff.View) hotstuff.Hash
+}
+
+// HasQuorum returns true if the replicas in the set form a quorum in the given view.
+// If power is nil, every replica in the configuration has one vote.
+func HasQuorum(configuration Configuration, power VotingPower, view hotstuff.View, set hotstuff.IDSet) bool {
+	if power == nil {
+		return set.Len() >= configuration.QuorumSize()
+	}
+	sum := hotstuff.SetPower(set, func(id hotstuff.ID) uint64 { return power.Power(view, id) })
+	return hotstuff.HasQuorumPower(sum, power.TotalPower(view))
+}
+
+// HasQuorumSignature returns true if the signature is signed by a quorum of replicas.
+// A threshold signature can only be created by a quorum of replicas, so it does not list its participants.
+func HasQuorumSignature(configuration Configuration, power VotingPower, view hotstuff.View, signature hotstuff.QuorumSignature) bool {
+	if _, ok := signature.(hotstuff.ThresholdSignature); ok {
+		return true
+	}
+	return HasQuorum(configuration, power, view, signature.Participants())
+}
+
 // Kauri module implements the Kauri protocol
 type Kauri interface {
 	Begin(s hotstuff.PartialCert, p hotstuff.ProposeMsg)
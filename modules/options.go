@@ -39,7 +39,10 @@ type Options struct {
 	privateKey hotstuff.PrivateKey
 
 	shouldUseAggQC        bool
+	shouldUseHighQCViews  bool
 	shouldVerifyVotesSync bool
+	shouldWaitForHighQC   bool
+	thresholdSignatures   bool
 
 	sharedRandomSeed   int64
 	connectionMetadata map[string]string
@@ -99,6 +102,19 @@ func (opts *Options) ShouldUseAggQC() bool {
 	return opts.shouldUseAggQC
 }
 
+// ShouldUseHighQCViews returns true if timeout certificates should include the view of the high QC of each signer.
+// This is true for Jolteon: https://arxiv.org/abs/2106.10362
+func (opts *Options) ShouldUseHighQCViews() bool {
+	return opts.shouldUseHighQCViews
+}
+
+// ShouldWaitForHighQC returns true if a leader that enters a view after a timeout should wait for
+// the highQCs of a quorum of replicas before proposing.
+// This is true for HotStuff-2: https://eprint.iacr.org/2023/397
+func (opts *Options) ShouldWaitForHighQC() bool {
+	return opts.shouldWaitForHighQC
+}
+
 // ShouldVerifyVotesSync returns true if votes should be verified synchronously.
 // Enabling this should make the voting machine process votes synchronously.
 func (opts *Options) ShouldVerifyVotesSync() bool {
@@ -116,8 +132,40 @@ func (opts *Options) ConnectionMetadata() map[string]string {
 }
 
 // SetShouldUseAggQC sets the ShouldUseAggQC setting to true.
+// It panics if threshold signatures are used, see SetThresholdSignatures.
 func (opts *Options) SetShouldUseAggQC() {
 	opts.shouldUseAggQC = true
+	opts.checkThresholdSignatures()
+}
+
+// SetShouldUseHighQCViews sets the ShouldUseHighQCViews setting to true.
+// It panics if threshold signatures are used, see SetThresholdSignatures.
+func (opts *Options) SetShouldUseHighQCViews() {
+	opts.shouldUseHighQCViews = true
+	opts.checkThresholdSignatures()
+}
+
+// SetThresholdSignatures records that the certificates are threshold signatures,
+// which can only be combined from signatures of the same message.
+// Since the modules are initialized in the order they were added, both this method and the setters
+// of the options that need signatures of different messages check the combination, such that a replica
+// with an incompatible consensus protocol fails when it is built, regardless of the order of the modules.
+func (opts *Options) SetThresholdSignatures() {
+	opts.thresholdSignatures = true
+	opts.checkThresholdSignatures()
+}
+
+// checkThresholdSignatures panics if threshold signatures are used together with
+// a consensus protocol whose certificates combine signatures of different messages.
+func (opts *Options) checkThresholdSignatures() {
+	if opts.thresholdSignatures && (opts.shouldUseAggQC || opts.shouldUseHighQCViews) {
+		panic("modules: the consensus protocol combines signatures of different messages, which threshold signatures cannot do")
+	}
+}
+
+// SetShouldWaitForHighQC sets the ShouldWaitForHighQC setting to true.
+func (opts *Options) SetShouldWaitForHighQC() {
+	opts.shouldWaitForHighQC = true
 }
 
 // SetShouldVerifyVotesSync sets the ShouldVerifyVotesSync setting to true.
@@ -2,6 +2,7 @@ package replica
 
 import (
 	"crypto/sha256"
+	"errors"
 	"hash"
 	"net"
 	"sync"
@@ -9,6 +10,7 @@ import (
 	"github.com/relab/hotstuff"
 
 	"github.com/relab/gorums"
+	"github.com/relab/hotstuff/blockchain"
 	"github.com/relab/hotstuff/eventloop"
 	"github.com/relab/hotstuff/internal/proto/clientpb"
 	"github.com/relab/hotstuff/logging"
@@ -28,6 +30,7 @@ type clientSrv struct {
 	srv          *gorums.Server
 	awaitingCmds map[cmdID]chan<- error
 	cmdCache     *cmdCache
+	stateStore   *blockchain.StateStore
 	hash         hash.Hash
 	cmdCount     uint32
 }
@@ -37,7 +40,8 @@ func newClientServer(conf Config, srvOpts []gorums.ServerOption) (srv *clientSrv
 	srv = &clientSrv{
 		awaitingCmds: make(map[cmdID]chan<- error),
 		srv:          gorums.NewServer(srvOpts...),
-		cmdCache:     newCmdCache(int(conf.BatchSize)),
+		cmdCache:     newCmdCache(conf),
+		stateStore:   conf.StateStore,
 		hash:         sha256.New(),
 	}
 	clientpb.RegisterClientServer(srv.srv, srv)
@@ -50,7 +54,15 @@ func (srv *clientSrv) InitModule(mods *modules.Core) {
 		&srv.eventLoop,
 		&srv.logger,
 	)
-	srv.cmdCache.InitModule(mods)
+
+	if srv.stateStore != nil {
+		sessions, err := srv.stateStore.GetClientSessions()
+		if err != nil {
+			srv.logger.Errorf("Failed to load client sessions: %v", err)
+			return
+		}
+		srv.cmdCache.sessions.restore(sessions)
+	}
 }
 
 func (srv *clientSrv) Start(addr string) error {
@@ -83,23 +95,40 @@ func (srv *clientSrv) ExecCommand(ctx gorums.ServerCtx, cmd *clientpb.Command) (
 	srv.awaitingCmds[id] = c
 	srv.mut.Unlock()
 
-	srv.cmdCache.addCommand(cmd)
+	switch err := srv.cmdCache.addCommand(cmd); {
+	case errors.Is(err, errCommitted):
+		// the command was committed before, e.g., before the replica restarted.
+		srv.mut.Lock()
+		delete(srv.awaitingCmds, id)
+		srv.mut.Unlock()
+		return &emptypb.Empty{}, nil
+	case err != nil:
+		srv.mut.Lock()
+		delete(srv.awaitingCmds, id)
+		srv.mut.Unlock()
+		return &emptypb.Empty{}, status.Error(codes.OutOfRange, err.Error())
+	}
 	ctx.Release()
 	err := <-c
 	return &emptypb.Empty{}, err
 }
 
-func (srv *clientSrv) Exec(cmd hotstuff.Command) {
+// Exec executes the commands of the block, and persists the block together with the client sessions.
+func (srv *clientSrv) Exec(block *hotstuff.Block) {
 	batch := new(clientpb.Batch)
-	err := proto.UnmarshalOptions{AllowPartial: true}.Unmarshal([]byte(cmd), batch)
+	err := proto.UnmarshalOptions{AllowPartial: true}.Unmarshal([]byte(block.Command()), batch)
 	if err != nil {
 		srv.logger.Errorf("Failed to unmarshal command: %v", err)
 		return
 	}
 
-	srv.eventLoop.AddEvent(hotstuff.CommitEvent{Commands: len(batch.GetCommands())})
+	// commands that were committed before, e.g., in a different block or before a restart, are not executed again.
+	cmds := srv.cmdCache.commit(batch)
+	srv.persist(block, cmds)
+
+	srv.eventLoop.AddEvent(hotstuff.CommitEvent{Commands: len(cmds)})
 
-	for _, cmd := range batch.GetCommands() {
+	for _, cmd := range cmds {
 		_, _ = srv.hash.Write(cmd.Data)
 		srv.cmdCount++
 		srv.mut.Lock()
@@ -114,6 +143,25 @@ func (srv *clientSrv) Exec(cmd hotstuff.Command) {
 	srv.logger.Debugf("Hash: %.8x", srv.hash.Sum(nil))
 }
 
+// persist saves the executed block and the sessions of the clients whose commands were committed in one write,
+// such that a crash cannot persist one without the other.
+func (srv *clientSrv) persist(block *hotstuff.Block, cmds []*clientpb.Command) {
+	if srv.stateStore == nil {
+		return
+	}
+	sessions := make(map[uint32]blockchain.ClientSession)
+	srv.cmdCache.mut.Lock()
+	for _, cmd := range cmds {
+		sessions[cmd.GetClientID()] = srv.cmdCache.sessions.get(cmd.GetClientID())
+	}
+	srv.cmdCache.mut.Unlock()
+	if err := srv.stateStore.SetExecuted(block.Hash(), sessions); err != nil {
+		srv.logger.Errorf("Failed to save executed block: %v", err)
+	}
+}
+
+// Fork puts the commands of the forked batch back in the command cache, such that they can be proposed again.
+// The clients keep waiting for the commands to be committed.
 func (srv *clientSrv) Fork(cmd hotstuff.Command) {
 	batch := new(clientpb.Batch)
 	err := proto.UnmarshalOptions{AllowPartial: true}.Unmarshal([]byte(cmd), batch)
@@ -122,13 +170,5 @@ func (srv *clientSrv) Fork(cmd hotstuff.Command) {
 		return
 	}
 
-	for _, cmd := range batch.GetCommands() {
-		srv.mut.Lock()
-		id := cmdID{cmd.GetClientID(), cmd.GetSequenceNumber()}
-		if done, ok := srv.awaitingCmds[id]; ok {
-			done <- status.Error(codes.Aborted, "blockchain was forked")
-			delete(srv.awaitingCmds, id)
-		}
-		srv.mut.Unlock()
-	}
+	srv.cmdCache.requeue(batch)
 }
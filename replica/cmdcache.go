@@ -3,33 +3,77 @@ package replica
 import (
 	"container/list"
 	"context"
+	"errors"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/relab/hotstuff"
 
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/evidence"
 	"github.com/relab/hotstuff/internal/proto/clientpb"
 	"github.com/relab/hotstuff/logging"
 	"github.com/relab/hotstuff/modules"
 	"google.golang.org/protobuf/proto"
 )
 
+// In adaptive batching mode, the effective batch size is adjusted based on the observed commit latency,
+// using additive increase and multiplicative decrease. The effective batch size is halved when the smoothed
+// commit latency exceeds twice the lowest commit latency among the recent blocks, and is otherwise increased
+// by one for each committed block, up to the configured batch size. The commit latency is measured with the
+// local clock, from when the replica received the proposal, so the clocks of the proposers do not matter.
+
+const (
+	// latencyFactor is the factor by which the smoothed commit latency may exceed the baseline commit latency
+	// before the effective batch size is decreased.
+	latencyFactor = 2
+	// latencyWindow is the number of recent commit latencies that the baseline is taken from,
+	// such that the baseline follows the network when its latency increases.
+	latencyWindow = 64
+)
+
+var (
+	errCommitted     = errors.New("command has already been committed")
+	errOutsideWindow = errors.New("sequence number is outside the client's window")
+)
+
 type cmdCache struct {
-	logger logging.Logger
+	logger    logging.Logger
+	eventLoop *eventloop.EventLoop
+	evidence  modules.EvidencePool // nil if evidence is not collected.
 
 	mut           sync.Mutex
 	c             chan struct{}
-	batchSize     int
-	serialNumbers map[uint32]uint64 // highest proposed serial number per client ID
+	batchSize     int                // maximum number of commands in a batch
+	maxBatchBytes int                // maximum size of a batch in bytes, or 0 for no limit
+	maxBatchDelay time.Duration      // maximum time to wait for a full batch, or 0 to wait indefinitely
+	fairOrdering  bool               // propose and accept batches in canonical order only
+	adaptive      bool               // adjust the effective batch size based on the commit latency
+	effective     int                // the effective batch size
+	latencies     []time.Duration    // the recent commit latencies, up to latencyWindow
+	nextLatency   int                // the index of the oldest latency once the window is full
+	avgLatency    time.Duration      // the smoothed commit latency
+	sessions      *clientSessions    // the committed commands of each client
+	proposed      map[cmdID]struct{} // commands that have been proposed, but not committed
 	cache         list.List
+	cacheBytes    int // the size of the commands in the cache in bytes
 	marshaler     proto.MarshalOptions
 	unmarshaler   proto.UnmarshalOptions
 }
 
-func newCmdCache(batchSize int) *cmdCache {
+func newCmdCache(conf Config) *cmdCache {
+	batchSize := max(int(conf.BatchSize), 1)
 	return &cmdCache{
-		c:             make(chan struct{}),
+		c:             make(chan struct{}, 1),
 		batchSize:     batchSize,
-		serialNumbers: make(map[uint32]uint64),
+		maxBatchBytes: int(conf.MaxBatchBytes),
+		maxBatchDelay: conf.MaxBatchDelay,
+		fairOrdering:  conf.FairOrdering,
+		adaptive:      conf.AdaptiveBatching,
+		effective:     batchSize,
+		sessions:      newClientSessions(conf.ClientWindow),
+		proposed:      make(map[cmdID]struct{}),
 		marshaler:     proto.MarshalOptions{Deterministic: true},
 		unmarshaler:   proto.UnmarshalOptions{DiscardUnknown: true},
 	}
@@ -37,37 +81,120 @@ func newCmdCache(batchSize int) *cmdCache {
 
 // InitModule gives the module access to the other modules.
 func (c *cmdCache) InitModule(mods *modules.Core) {
-	mods.Get(&c.logger)
+	mods.Get(
+		&c.logger,
+		&c.eventLoop,
+	)
+	mods.TryGet(&c.evidence)
+
+	if c.adaptive {
+		c.eventLoop.RegisterHandler(hotstuff.ConsensusLatencyEvent{}, func(event any) {
+			c.updateBatchSize(event.(hotstuff.ConsensusLatencyEvent).LocalLatency)
+		})
+	}
 }
 
-func (c *cmdCache) addCommand(cmd *clientpb.Command) {
+// updateBatchSize adjusts the effective batch size based on the commit latency of a block.
+// Latencies that are not positive are ignored, since the block was not received as a proposal.
+func (c *cmdCache) updateBatchSize(latency time.Duration) {
+	if latency <= 0 {
+		return
+	}
+
 	c.mut.Lock()
 	defer c.mut.Unlock()
-	if serialNo := c.serialNumbers[cmd.GetClientID()]; serialNo >= cmd.GetSequenceNumber() {
-		// command is too old
-		return
+
+	if len(c.latencies) < latencyWindow {
+		c.latencies = append(c.latencies, latency)
+	} else {
+		c.latencies[c.nextLatency] = latency
+		c.nextLatency = (c.nextLatency + 1) % latencyWindow
+	}
+	baseline := slices.Min(c.latencies)
+
+	if c.avgLatency == 0 {
+		c.avgLatency = latency
+	} else {
+		c.avgLatency = (7*c.avgLatency + latency) / 8
+	}
+
+	if c.avgLatency > latencyFactor*baseline {
+		c.effective = max(c.effective/2, 1)
+		// start a new measurement with the new batch size.
+		c.avgLatency = 0
+	} else {
+		c.effective = min(c.effective+1, c.batchSize)
+	}
+}
+
+// ready returns true if a batch should be sent.
+// If the deadline has expired, a batch is sent as soon as there is at least one command.
+func (c *cmdCache) ready(expired bool) bool {
+	if c.cache.Len() >= c.effective {
+		return true
+	}
+	if c.maxBatchBytes > 0 && c.cacheBytes >= c.maxBatchBytes {
+		return true
+	}
+	return expired && c.cache.Len() > 0
+}
+
+// addCommand adds the command to the cache.
+func (c *cmdCache) addCommand(cmd *clientpb.Command) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if c.sessions.isCommitted(cmd.GetClientID(), cmd.GetSequenceNumber()) {
+		return errCommitted
+	}
+	if !c.sessions.inWindow(cmd.GetClientID(), cmd.GetSequenceNumber()) {
+		return errOutsideWindow
+	}
+	if _, ok := c.proposed[cmdID{cmd.GetClientID(), cmd.GetSequenceNumber()}]; ok {
+		// the command is already part of a pending proposal
+		return nil
 	}
 	c.cache.PushBack(cmd)
-	if c.cache.Len() >= c.batchSize {
-		// notify Get that we are ready to send a new batch.
-		select {
-		case c.c <- struct{}{}:
-		default:
-		}
+	c.cacheBytes += proto.Size(cmd)
+	// notify Get that it should check if a new batch is ready.
+	select {
+	case c.c <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// isStale returns true if the command has already been committed, or is part of a pending proposal.
+func (c *cmdCache) isStale(cmd *clientpb.Command) bool {
+	if _, ok := c.proposed[cmdID{cmd.GetClientID(), cmd.GetSequenceNumber()}]; ok {
+		return true
 	}
+	return c.sessions.isCommitted(cmd.GetClientID(), cmd.GetSequenceNumber())
 }
 
 // Get returns a batch of commands to propose.
+// Get waits until the effective batch size or the byte limit is reached, or until the max batch delay has passed.
 func (c *cmdCache) Get(ctx context.Context) (cmd hotstuff.Command, ok bool) {
 	batch := new(clientpb.Batch)
 
+	var (
+		deadline <-chan time.Time
+		expired  bool
+	)
+	if c.maxBatchDelay > 0 {
+		timer := time.NewTimer(c.maxBatchDelay)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
 	c.mut.Lock()
 awaitBatch:
 	// wait until we can send a new batch.
-	for c.cache.Len() <= c.batchSize {
+	for !c.ready(expired) {
 		c.mut.Unlock()
 		select {
 		case <-c.c:
+		case <-deadline:
+			expired = true
 		case <-ctx.Done():
 			return
 		}
@@ -76,19 +203,26 @@ awaitBatch:
 
 	// Get the batch. Note that we may not be able to fill the batch, but that should be fine as long as we can send
 	// at least one command.
-	for i := 0; i < c.batchSize; i++ {
+	batchBytes := 0
+	for len(batch.Commands) < c.effective {
 		elem := c.cache.Front()
 		if elem == nil {
 			break
 		}
-		c.cache.Remove(elem)
 		cmd := elem.Value.(*clientpb.Command)
-		if serialNo := c.serialNumbers[cmd.GetClientID()]; serialNo >= cmd.GetSequenceNumber() {
+		size := proto.Size(cmd)
+		if c.maxBatchBytes > 0 && len(batch.Commands) > 0 && batchBytes+size > c.maxBatchBytes {
+			break
+		}
+		c.cache.Remove(elem)
+		c.cacheBytes -= size
+		if c.isStale(cmd) {
 			// command is too old
-			i--
 			continue
 		}
+		c.proposed[cmdID{cmd.GetClientID(), cmd.GetSequenceNumber()}] = struct{}{}
 		batch.Commands = append(batch.Commands, cmd)
+		batchBytes += size
 	}
 
 	// if we still got no (new) commands, try to wait again
@@ -96,7 +230,20 @@ awaitBatch:
 		goto awaitBatch
 	}
 
-	defer c.mut.Unlock()
+	effective := c.effective
+	c.mut.Unlock()
+
+	// in fair ordering mode, the batch only commits to the set of commands.
+	if c.fairOrdering {
+		sortCanonical(batch)
+	}
+
+	// include the evidence that has not yet been committed, so that the offenders can be slashed.
+	if c.evidence != nil {
+		if err := evidence.ToBatch(batch, c.evidence.Pending()); err != nil {
+			c.logger.Errorf("Failed to add evidence to batch: %v", err)
+		}
+	}
 
 	// otherwise, we should have at least one command
 	b, err := c.marshaler.Marshal(batch)
@@ -105,6 +252,8 @@ awaitBatch:
 		return "", false
 	}
 
+	c.eventLoop.AddEvent(hotstuff.BatchEvent{Commands: len(batch.Commands), Bytes: len(b), BatchSize: effective})
+
 	cmd = hotstuff.Command(b)
 	return cmd, true
 }
@@ -118,20 +267,33 @@ func (c *cmdCache) Accept(cmd hotstuff.Command) bool {
 		return false
 	}
 
+	if c.fairOrdering && !isCanonical(batch) {
+		// the leader tried to choose the order of the commands
+		c.logger.Info("Batch is not in canonical order")
+		return false
+	}
+
 	c.mut.Lock()
 	defer c.mut.Unlock()
 
 	for _, cmd := range batch.GetCommands() {
-		if serialNo := c.serialNumbers[cmd.GetClientID()]; serialNo >= cmd.GetSequenceNumber() {
+		if c.sessions.isCommitted(cmd.GetClientID(), cmd.GetSequenceNumber()) {
 			// command is too old, can't accept
 			return false
 		}
+		if !c.sessions.inWindow(cmd.GetClientID(), cmd.GetSequenceNumber()) {
+			// the leader proposed a command too far ahead of the client's committed commands
+			return false
+		}
 	}
 
+	// Commands that are part of a pending proposal are accepted, since the pending proposal may be forked.
+	// If both proposals are committed, the command is only executed once.
 	return true
 }
 
-// Proposed updates the serial numbers such that we will not accept the given batch again.
+// Proposed records the commands in the batch such that we will not propose them again,
+// unless the batch is forked.
 func (c *cmdCache) Proposed(cmd hotstuff.Command) {
 	batch := new(clientpb.Batch)
 	err := c.unmarshaler.Unmarshal([]byte(cmd), batch)
@@ -144,10 +306,51 @@ func (c *cmdCache) Proposed(cmd hotstuff.Command) {
 	defer c.mut.Unlock()
 
 	for _, cmd := range batch.GetCommands() {
-		if serialNo := c.serialNumbers[cmd.GetClientID()]; serialNo < cmd.GetSequenceNumber() {
-			c.serialNumbers[cmd.GetClientID()] = cmd.GetSequenceNumber()
+		c.proposed[cmdID{cmd.GetClientID(), cmd.GetSequenceNumber()}] = struct{}{}
+	}
+}
+
+// commit records that the commands in the batch have been committed,
+// and returns the commands that were not committed before.
+func (c *cmdCache) commit(batch *clientpb.Batch) (cmds []*clientpb.Command) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	for _, cmd := range batch.GetCommands() {
+		delete(c.proposed, cmdID{cmd.GetClientID(), cmd.GetSequenceNumber()})
+		if c.sessions.commit(cmd.GetClientID(), cmd.GetSequenceNumber()) {
+			cmds = append(cmds, cmd)
 		}
 	}
+	return cmds
+}
+
+// requeue adds the commands of a forked batch back to the front of the cache,
+// such that they can be proposed again.
+func (c *cmdCache) requeue(batch *clientpb.Batch) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	cmds := batch.GetCommands()
+	for i := len(cmds) - 1; i >= 0; i-- {
+		cmd := cmds[i]
+		id := cmdID{cmd.GetClientID(), cmd.GetSequenceNumber()}
+		if _, ok := c.proposed[id]; !ok {
+			// the command was never removed from the cache.
+			continue
+		}
+		delete(c.proposed, id)
+		if c.sessions.isCommitted(id.clientID, id.sequenceNum) {
+			continue
+		}
+		c.cache.PushFront(cmd)
+		c.cacheBytes += proto.Size(cmd)
+	}
+
+	select {
+	case c.c <- struct{}{}:
+	default:
+	}
 }
 
 var _ modules.Acceptor = (*cmdCache)(nil)
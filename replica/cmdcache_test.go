@@ -0,0 +1,118 @@
+package replica
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+	"google.golang.org/protobuf/proto"
+)
+
+func newTestCmdCache(conf Config) *cmdCache {
+	cache := newCmdCache(conf)
+	builder := modules.NewBuilder(1, nil)
+	builder.Add(eventloop.New(100), logging.New("test"), cache)
+	builder.Build()
+	return cache
+}
+
+func batchLen(t *testing.T, cmd hotstuff.Command) int {
+	t.Helper()
+	batch := new(clientpb.Batch)
+	if err := proto.Unmarshal([]byte(cmd), batch); err != nil {
+		t.Fatal(err)
+	}
+	return len(batch.GetCommands())
+}
+
+// TestCmdCacheMaxBatchDelay checks that a partial batch is proposed once the max batch delay has passed.
+func TestCmdCacheMaxBatchDelay(t *testing.T) {
+	cache := newTestCmdCache(Config{BatchSize: 100, MaxBatchDelay: 10 * time.Millisecond})
+	cache.addCommand(&clientpb.Command{ClientID: 1, SequenceNumber: 1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	cmd, ok := cache.Get(ctx)
+	if !ok {
+		t.Fatal("no batch was proposed before the deadline")
+	}
+	if n := batchLen(t, cmd); n != 1 {
+		t.Errorf("got %d commands, want 1", n)
+	}
+}
+
+// TestCmdCacheMaxBatchBytes checks that the size of a batch does not exceed the byte limit.
+func TestCmdCacheMaxBatchBytes(t *testing.T) {
+	cmd := &clientpb.Command{ClientID: 1, SequenceNumber: 1, Data: make([]byte, 100)}
+	cache := newTestCmdCache(Config{BatchSize: 100, MaxBatchBytes: uint32(3 * proto.Size(cmd))})
+	for i := uint64(1); i <= 5; i++ {
+		cache.addCommand(&clientpb.Command{ClientID: 1, SequenceNumber: i, Data: make([]byte, 100)})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	batch, ok := cache.Get(ctx)
+	if !ok {
+		t.Fatal("no batch was proposed when the byte limit was reached")
+	}
+	if n := batchLen(t, batch); n != 3 {
+		t.Errorf("got %d commands, want 3", n)
+	}
+}
+
+// TestCmdCacheAdaptiveBatchSize checks that the effective batch size decreases when the commit latency increases,
+// and increases again when the commit latency is low.
+func TestCmdCacheAdaptiveBatchSize(t *testing.T) {
+	cache := newTestCmdCache(Config{BatchSize: 16, AdaptiveBatching: true})
+	cache.updateBatchSize(10 * time.Millisecond)
+	if cache.effective != 16 {
+		t.Fatalf("effective batch size is %d, want 16", cache.effective)
+	}
+
+	for range 4 {
+		cache.updateBatchSize(100 * time.Millisecond)
+	}
+	if cache.effective >= 16 {
+		t.Fatalf("effective batch size did not decrease: %d", cache.effective)
+	}
+
+	decreased := cache.effective
+	cache.updateBatchSize(10 * time.Millisecond)
+	if cache.effective != decreased+1 {
+		t.Errorf("effective batch size is %d, want %d", cache.effective, decreased+1)
+	}
+
+	// blocks that were not received as proposals have no latency.
+	increased := cache.effective
+	cache.updateBatchSize(0)
+	cache.updateBatchSize(-time.Second)
+	if cache.effective != increased {
+		t.Errorf("effective batch size changed to %d on a non-positive latency, want %d", cache.effective, increased)
+	}
+}
+
+// TestCmdCacheAdaptiveBaseline checks that the baseline latency follows the network when its latency increases,
+// such that the effective batch size grows again.
+func TestCmdCacheAdaptiveBaseline(t *testing.T) {
+	cache := newTestCmdCache(Config{BatchSize: 16, AdaptiveBatching: true})
+	cache.updateBatchSize(10 * time.Millisecond)
+	for range latencyWindow - 1 {
+		cache.updateBatchSize(100 * time.Millisecond)
+	}
+	if cache.effective != 1 {
+		t.Fatalf("effective batch size is %d, want 1", cache.effective)
+	}
+
+	// the low latency has left the window.
+	for range 4 {
+		cache.updateBatchSize(100 * time.Millisecond)
+	}
+	if cache.effective != 5 {
+		t.Errorf("effective batch size is %d, want 5", cache.effective)
+	}
+}
@@ -0,0 +1,296 @@
+package replica
+
+import (
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/crypto/bls12"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+	"google.golang.org/protobuf/proto"
+)
+
+// In encrypted mempool mode, clients encrypt the data of their commands to the public key of the committee,
+// and the replicas order the ciphertexts. The private key of the committee is shared among the replicas,
+// such that any threshold number of replicas can decrypt a ciphertext, but fewer cannot.
+// A replica only releases its decryption shares for the commands of a block once the block is committed.
+// Thus, the contents of a command are not known to anyone until its position in the order is final.
+//
+// Since a replica cannot execute a block before it has enough decryption shares, shares that are lost
+// must be sent again. A replica that has waited for the shares of its oldest pending block for a whole
+// shareRequestInterval broadcasts a request for them, which is a DecryptionShareMsg without shares.
+// The replicas that have released their shares for the block answer by sending them again,
+// at most once per interval, such that requests cannot be used to flood the network.
+
+const (
+	// maxBufferedShares is the maximum number of blocks for which decryption shares are buffered
+	// before the block is committed locally, and for which the replica's own shares are kept after release.
+	maxBufferedShares = 1000
+	// shareRequestInterval is the time between requests for the decryption shares of a pending block.
+	shareRequestInterval = time.Second
+)
+
+// shareRequestEvent is sent by the ticker when the replica should request missing decryption shares.
+type shareRequestEvent struct{}
+
+// shareSender sends decryption shares to the other replicas.
+type shareSender interface {
+	DecryptionShare(msg hotstuff.DecryptionShareMsg)
+}
+
+// pendingBlock is a committed block whose commands have not been decrypted yet.
+type pendingBlock struct {
+	block       *hotstuff.Block
+	cert        hotstuff.QuorumCert
+	batch       *clientpb.Batch
+	ciphertexts []*bls12.Ciphertext // nil for commands that are not valid ciphertexts.
+	shares      [][]*bls12.DecryptionShare
+	senders     hotstuff.IDSet
+	waited      bool // set at the first tick while the block is pending.
+}
+
+// releasedShares are the replica's own decryption shares for a block.
+type releasedShares struct {
+	msg      hotstuff.DecryptionShareMsg
+	answered bool // set when the shares are sent again, and cleared at every tick.
+}
+
+// decryptingExecutor decrypts the commands of committed blocks and forwards the decrypted blocks to the executor.
+// Blocks are forwarded in the order that they were committed.
+type decryptingExecutor struct {
+	eventLoop *eventloop.EventLoop
+	logger    logging.Logger
+	opts      *modules.Options
+
+	executor modules.ExecutorExt
+	sender   shareSender
+	key      *bls12.ThresholdKeyShare
+	pub      *bls12.ThresholdPublicKey
+
+	pending  []*pendingBlock
+	buffered map[hotstuff.Hash]map[hotstuff.ID]hotstuff.DecryptionShareMsg // the first message from each sender.
+	order    []hotstuff.Hash                                               // the order in which blocks were added to buffered.
+
+	released      map[hotstuff.Hash]*releasedShares
+	releasedOrder []hotstuff.Hash // the order in which blocks were added to released.
+}
+
+// newDecryptingExecutor returns a new decryptingExecutor.
+func newDecryptingExecutor(executor modules.ExecutorExt, sender shareSender, key *bls12.ThresholdKeyShare, pub *bls12.ThresholdPublicKey) *decryptingExecutor {
+	return &decryptingExecutor{
+		executor: executor,
+		sender:   sender,
+		key:      key,
+		pub:      pub,
+		buffered: make(map[hotstuff.Hash]map[hotstuff.ID]hotstuff.DecryptionShareMsg),
+		released: make(map[hotstuff.Hash]*releasedShares),
+	}
+}
+
+// InitModule gives the module access to the other modules.
+func (de *decryptingExecutor) InitModule(mods *modules.Core) {
+	mods.Get(
+		&de.eventLoop,
+		&de.logger,
+		&de.opts,
+	)
+	if m, ok := de.executor.(modules.Module); ok {
+		m.InitModule(mods)
+	}
+
+	de.eventLoop.RegisterHandler(hotstuff.DecryptionShareMsg{}, func(event any) {
+		de.OnDecryptionShare(event.(hotstuff.DecryptionShareMsg))
+	})
+	de.eventLoop.RegisterHandler(shareRequestEvent{}, func(_ any) {
+		de.requestShares()
+	})
+	de.eventLoop.AddTicker(shareRequestInterval, func(_ time.Time) any { return shareRequestEvent{} })
+}
+
+// Exec releases the decryption shares for the committed block.
+func (de *decryptingExecutor) Exec(block *hotstuff.Block) {
+	de.ExecCertified(block, hotstuff.QuorumCert{})
+}
+
+// ExecCertified releases the decryption shares for the committed block.
+// The certificate is passed on to the executor once the block has been decrypted.
+func (de *decryptingExecutor) ExecCertified(block *hotstuff.Block, cert hotstuff.QuorumCert) {
+	batch := new(clientpb.Batch)
+	err := proto.UnmarshalOptions{AllowPartial: true}.Unmarshal([]byte(block.Command()), batch)
+	if err != nil {
+		de.logger.Errorf("Failed to unmarshal command: %v", err)
+		return
+	}
+
+	p := &pendingBlock{
+		block:       block,
+		cert:        cert,
+		batch:       batch,
+		ciphertexts: make([]*bls12.Ciphertext, len(batch.GetCommands())),
+		shares:      make([][]*bls12.DecryptionShare, len(batch.GetCommands())),
+		senders:     hotstuff.NewIDSet(),
+	}
+	msg := hotstuff.DecryptionShareMsg{
+		ID:        de.opts.ID(),
+		BlockHash: block.Hash(),
+		Shares:    make([][]byte, len(batch.GetCommands())),
+	}
+	for i, cmd := range batch.GetCommands() {
+		ct := &bls12.Ciphertext{}
+		if err := ct.FromBytes(cmd.GetData()); err != nil || !ct.Verify(cmd.EncryptionLabel()) {
+			de.logger.Infof("Command %d from client %d is not a valid ciphertext", cmd.GetSequenceNumber(), cmd.GetClientID())
+			continue
+		}
+		p.ciphertexts[i] = ct
+		msg.Shares[i] = de.key.DecryptionShare(ct).ToBytes()
+	}
+	de.pending = append(de.pending, p)
+
+	de.addShares(p, msg)
+	for _, buffered := range de.buffered[block.Hash()] {
+		de.addShares(p, buffered)
+	}
+	delete(de.buffered, block.Hash())
+
+	if len(batch.GetCommands()) > 0 {
+		de.release(msg)
+	}
+	de.tryDeliver()
+}
+
+// release sends the replica's own decryption shares for a block, and keeps them such that they can be sent again.
+func (de *decryptingExecutor) release(msg hotstuff.DecryptionShareMsg) {
+	if _, ok := de.released[msg.BlockHash]; !ok {
+		if len(de.releasedOrder) >= maxBufferedShares {
+			delete(de.released, de.releasedOrder[0])
+			de.releasedOrder = de.releasedOrder[1:]
+		}
+		de.releasedOrder = append(de.releasedOrder, msg.BlockHash)
+	}
+	de.released[msg.BlockHash] = &releasedShares{msg: msg}
+	de.sender.DecryptionShare(msg)
+}
+
+// requestShares requests the decryption shares of the oldest pending block,
+// if the replica has waited for them since the previous tick.
+func (de *decryptingExecutor) requestShares() {
+	for _, r := range de.released {
+		r.answered = false
+	}
+	if len(de.pending) == 0 {
+		return
+	}
+	p := de.pending[0]
+	if !p.waited {
+		p.waited = true
+		return
+	}
+	de.logger.Debugf("Requesting decryption shares for block %.8s", p.block.Hash())
+	de.sender.DecryptionShare(hotstuff.DecryptionShareMsg{ID: de.opts.ID(), BlockHash: p.block.Hash()})
+}
+
+// OnDecryptionShare handles decryption shares from other replicas, and requests for the replica's own shares.
+func (de *decryptingExecutor) OnDecryptionShare(msg hotstuff.DecryptionShareMsg) {
+	if len(msg.Shares) == 0 {
+		// a replica is missing the shares of the block.
+		if r, ok := de.released[msg.BlockHash]; ok && !r.answered {
+			r.answered = true
+			de.sender.DecryptionShare(r.msg)
+		}
+		return
+	}
+	for _, p := range de.pending {
+		if p.block.Hash() == msg.BlockHash {
+			de.addShares(p, msg)
+			de.tryDeliver()
+			return
+		}
+	}
+
+	// the block has not been committed locally yet.
+	// Only the first message from each sender is kept, since addShares ignores the others.
+	msgs, ok := de.buffered[msg.BlockHash]
+	if !ok {
+		if len(de.order) >= maxBufferedShares {
+			delete(de.buffered, de.order[0])
+			de.order = de.order[1:]
+		}
+		de.order = append(de.order, msg.BlockHash)
+		msgs = make(map[hotstuff.ID]hotstuff.DecryptionShareMsg)
+		de.buffered[msg.BlockHash] = msgs
+	}
+	if _, ok := msgs[msg.ID]; !ok {
+		msgs[msg.ID] = msg
+	}
+}
+
+// addShares verifies the decryption shares in the message and adds the valid shares to the pending block.
+func (de *decryptingExecutor) addShares(p *pendingBlock, msg hotstuff.DecryptionShareMsg) {
+	if p.senders.Contains(msg.ID) || len(msg.Shares) != len(p.ciphertexts) {
+		return
+	}
+	p.senders.Add(msg.ID)
+	for i, ct := range p.ciphertexts {
+		if ct == nil || len(p.shares[i]) >= de.pub.Threshold() {
+			continue
+		}
+		share, err := bls12.RestoreDecryptionShare(msg.ID, msg.Shares[i])
+		if err != nil || !de.pub.VerifyShare(ct, share) {
+			de.logger.Infof("Invalid decryption share from replica %d for block %.8s", msg.ID, msg.BlockHash)
+			continue
+		}
+		p.shares[i] = append(p.shares[i], share)
+	}
+}
+
+// tryDeliver decrypts the pending blocks in order and forwards them to the executor,
+// until it reaches a block that does not have enough decryption shares.
+func (de *decryptingExecutor) tryDeliver() {
+	for len(de.pending) > 0 {
+		p := de.pending[0]
+		for i, ct := range p.ciphertexts {
+			if ct != nil && len(p.shares[i]) < de.pub.Threshold() {
+				return
+			}
+		}
+		de.pending = de.pending[1:]
+		de.deliver(p)
+	}
+}
+
+// deliver decrypts the commands of the block and forwards the decrypted block to the executor.
+// Commands that cannot be decrypted are executed with empty data, such that the clients get a reply.
+func (de *decryptingExecutor) deliver(p *pendingBlock) {
+	for i, cmd := range p.batch.GetCommands() {
+		ct := p.ciphertexts[i]
+		if ct == nil {
+			cmd.Data = nil
+			continue
+		}
+		data, err := de.pub.Decrypt(ct, p.shares[i], cmd.EncryptionLabel())
+		if err != nil {
+			de.logger.Errorf("Failed to decrypt command %d from client %d: %v", cmd.GetSequenceNumber(), cmd.GetClientID(), err)
+		}
+		cmd.Data = data
+	}
+
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(p.batch)
+	if err != nil {
+		de.logger.Errorf("Failed to marshal batch: %v", err)
+		return
+	}
+	block := p.block.WithCommand(hotstuff.Command(b))
+
+	if ce, ok := de.executor.(modules.CertifiedExecutor); ok && p.cert.BlockHash() == p.block.Hash() {
+		ce.ExecCertified(block, p.cert)
+	} else {
+		de.executor.Exec(block)
+	}
+}
+
+var (
+	_ modules.ExecutorExt       = (*decryptingExecutor)(nil)
+	_ modules.CertifiedExecutor = (*decryptingExecutor)(nil)
+)
@@ -0,0 +1,218 @@
+package replica
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/crypto/bls12"
+	"github.com/relab/hotstuff/crypto/keygen"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/evm"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+	"github.com/relab/hotstuff/txpool"
+)
+
+// testNetwork delivers decryption shares directly to the other replicas.
+type testNetwork struct {
+	replicas map[hotstuff.ID]*decryptingExecutor
+	// replicas that do not send their shares.
+	silent hotstuff.IDSet
+	// down is set when all messages are lost.
+	down bool
+}
+
+type testSender struct {
+	id  hotstuff.ID
+	net *testNetwork
+}
+
+func (s testSender) DecryptionShare(msg hotstuff.DecryptionShareMsg) {
+	if s.net.down || s.net.silent.Contains(s.id) {
+		return
+	}
+	for id, de := range s.net.replicas {
+		if id != s.id {
+			de.OnDecryptionShare(msg)
+		}
+	}
+}
+
+func encryptCommand(t *testing.T, pub *bls12.ThresholdPublicKey, cmd *clientpb.Command) *clientpb.Command {
+	t.Helper()
+	ct, err := bls12.Encrypt(pub, cmd.GetData(), cmd.EncryptionLabel())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &clientpb.Command{ClientID: cmd.GetClientID(), SequenceNumber: cmd.GetSequenceNumber(), Data: ct.ToBytes()}
+}
+
+// encryptedTest is a network of replicas that execute blocks of encrypted transactions.
+type encryptedTest struct {
+	ids    []hotstuff.ID
+	net    *testNetwork
+	states map[hotstuff.ID]*evm.InMemoryStateDB
+	block  *hotstuff.Block
+	to     txpool.Address
+	value  *big.Int
+}
+
+// newEncryptedTest returns a network of four replicas and a block with an encrypted transaction,
+// and a ciphertext that was created for a different command.
+func newEncryptedTest(t *testing.T) *encryptedTest {
+	t.Helper()
+	ids := []hotstuff.ID{1, 2, 3, 4}
+	keys, pub, err := keygen.GenerateThresholdKeys(ids, hotstuff.NumFaulty(len(ids))+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	to := txpool.Address{0x10}
+	tx := &txpool.Transaction{
+		Nonce:    0,
+		GasPrice: big.NewInt(2000000000),
+		GasLimit: 21000,
+		To:       &to,
+		Value:    big.NewInt(1000),
+		Data:     []byte{},
+		ChainID:  big.NewInt(1337),
+	}
+	txData, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the simplified sender recovery derives the sender from the transaction hash.
+	decoded := new(txpool.Transaction)
+	if err := json.Unmarshal(txData, decoded); err != nil {
+		t.Fatal(err)
+	}
+	hash := decoded.Hash()
+	var from txpool.Address
+	copy(from[:], hash[:20])
+
+	net := &testNetwork{replicas: make(map[hotstuff.ID]*decryptingExecutor), silent: hotstuff.NewIDSet()}
+	states := make(map[hotstuff.ID]*evm.InMemoryStateDB)
+	for _, id := range ids {
+		states[id] = evm.NewInMemoryStateDB()
+		states[id].CreateAccount(from)
+		states[id].SetBalance(from, big.NewInt(1e18))
+
+		batchExecutor := evm.NewBatchExecutor(evm.ExecutionConfig{
+			GasLimit: 8000000,
+			BaseFee:  big.NewInt(1000000000),
+			ChainID:  big.NewInt(1337),
+		}, states[id])
+		de := newDecryptingExecutor(batchExecutor, testSender{id, net}, keys[id], pub)
+
+		builder := modules.NewBuilder(id, nil)
+		builder.Add(eventloop.New(100), logging.New("test"), de)
+		builder.Build()
+		net.replicas[id] = de
+	}
+
+	cmds := []*clientpb.Command{
+		encryptCommand(t, pub, &clientpb.Command{ClientID: 1, SequenceNumber: 1, Data: txData}),
+		// a ciphertext that was created for a different command is ignored.
+		{ClientID: 2, SequenceNumber: 1, Data: encryptCommand(t, pub, &clientpb.Command{ClientID: 1, SequenceNumber: 2, Data: txData}).GetData()},
+	}
+	block := hotstuff.NewBlock(
+		hotstuff.GetGenesis().Hash(),
+		hotstuff.NewQuorumCert(nil, 0, hotstuff.GetGenesis().Hash()),
+		marshalBatch(t, cmds),
+		1, 1,
+	)
+	return &encryptedTest{ids: ids, net: net, states: states, block: block, to: to, value: tx.Value}
+}
+
+// checkExecuted checks that every replica has executed the transaction and has no pending blocks.
+func (et *encryptedTest) checkExecuted(t *testing.T) {
+	t.Helper()
+	for _, id := range et.ids {
+		if got := et.states[id].GetBalance(et.to); got.Cmp(et.value) != 0 {
+			t.Errorf("replica %d: balance of recipient is %v, want %v", id, got, et.value)
+		}
+		if n := len(et.net.replicas[id].pending); n != 0 {
+			t.Errorf("replica %d: %d blocks are still pending", id, n)
+		}
+	}
+}
+
+// TestEncryptedMempool checks that committed blocks of encrypted transactions are only decrypted
+// once enough replicas have released their decryption shares, and are then executed by the EVM.
+func TestEncryptedMempool(t *testing.T) {
+	et := newEncryptedTest(t)
+
+	// replicas 3 and 4 do not release their shares.
+	et.net.silent.Add(3)
+	et.net.silent.Add(4)
+
+	// a single share is not enough to decrypt the commands.
+	et.net.replicas[1].Exec(et.block)
+	for _, id := range et.ids {
+		if et.states[id].GetBalance(et.to).Sign() != 0 {
+			t.Fatalf("replica %d executed the transaction before enough shares were released", id)
+		}
+	}
+
+	// replica 2 has buffered the share from replica 1, and replica 1 receives the share from replica 2.
+	// replicas 3 and 4 have buffered the shares from replicas 1 and 2.
+	for _, id := range []hotstuff.ID{2, 3, 4} {
+		et.net.replicas[id].Exec(et.block)
+	}
+	et.checkExecuted(t)
+}
+
+// TestEncryptedMempoolLostShares checks that replicas request the decryption shares that were lost,
+// and that the other replicas send their shares again.
+func TestEncryptedMempoolLostShares(t *testing.T) {
+	et := newEncryptedTest(t)
+
+	// all shares are lost.
+	et.net.down = true
+	for _, id := range et.ids {
+		et.net.replicas[id].Exec(et.block)
+	}
+	et.net.down = false
+
+	// the shares are requested at the second tick after the block was committed.
+	for _, id := range et.ids {
+		et.net.replicas[id].requestShares()
+		if n := len(et.net.replicas[id].pending); n != 1 {
+			t.Fatalf("replica %d: %d blocks are pending, want 1", id, n)
+		}
+	}
+	for _, id := range et.ids {
+		et.net.replicas[id].requestShares()
+	}
+	et.checkExecuted(t)
+}
+
+// TestEncryptedMempoolRepeatedShares checks that a replica only buffers one message from each sender
+// for a block that it has not committed yet, such that a byzantine replica cannot fill its memory
+// by sending the same message again.
+func TestEncryptedMempoolRepeatedShares(t *testing.T) {
+	et := newEncryptedTest(t)
+	et.net.silent.Add(3)
+	et.net.silent.Add(4)
+
+	de := et.net.replicas[2]
+	for i := range 100 {
+		de.OnDecryptionShare(hotstuff.DecryptionShareMsg{ID: 4, BlockHash: et.block.Hash(), Shares: [][]byte{{byte(i)}, {byte(i)}}})
+	}
+	if n := len(de.buffered[et.block.Hash()]); n != 1 {
+		t.Fatalf("buffered %d messages from a single sender, want 1", n)
+	}
+
+	// the share from replica 1 is still buffered, and is enough to decrypt the block together with the share of replica 2.
+	et.net.replicas[1].Exec(et.block)
+	if n := len(de.buffered[et.block.Hash()]); n != 2 {
+		t.Fatalf("buffered %d messages, want 2", n)
+	}
+	de.Exec(et.block)
+	if got := et.states[2].GetBalance(et.to); got.Cmp(et.value) != 0 {
+		t.Errorf("balance of recipient is %v, want %v", got, et.value)
+	}
+}
@@ -0,0 +1,191 @@
+package replica
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+	"google.golang.org/protobuf/proto"
+)
+
+// In fair ordering mode, the leader only decides which commands are included in a batch, not their order.
+// Batches are proposed in a canonical order (sorted by client ID and sequence number), such that the proposal
+// is only a commitment to the set of commands. Replicas refuse to vote for batches that are not in canonical order.
+// The final execution order is derived after commit from a seed that includes the signature of the quorum certificate
+// of the block, which is not known to the leader when it proposes the batch.
+//
+// The seed must be unique for the block, or the replica that combines the certificate could choose among
+// the signatures of different quorums, or ask the signers to sign again, until it gets an order that it prefers.
+// Therefore, fair ordering requires threshold signatures (bls12-threshold), where every quorum creates the same
+// signature. All replicas execute a committed block with the certificate that its child on the committed branch
+// carries, so they agree on the seed.
+
+// fairOrderDomain separates the seeds of the fair order from other hashes of the certificate.
+var fairOrderDomain = []byte("hotstuff-fair-order")
+
+// commandLess defines the canonical order of commands in a batch.
+func commandLess(a, b *clientpb.Command) bool {
+	if a.GetClientID() != b.GetClientID() {
+		return a.GetClientID() < b.GetClientID()
+	}
+	return a.GetSequenceNumber() < b.GetSequenceNumber()
+}
+
+// sortCanonical sorts the commands of the batch in canonical order.
+func sortCanonical(batch *clientpb.Batch) {
+	cmds := batch.GetCommands()
+	sort.Slice(cmds, func(i, j int) bool { return commandLess(cmds[i], cmds[j]) })
+}
+
+// isCanonical returns true if the commands of the batch are in strictly increasing canonical order.
+func isCanonical(batch *clientpb.Batch) bool {
+	cmds := batch.GetCommands()
+	for i := 1; i < len(cmds); i++ {
+		if !commandLess(cmds[i-1], cmds[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// fairOrderSeed returns the seed used to order the commands of a block that was certified by cert.
+// It returns false if the signature of the certificate is not a threshold signature, and thus not unique.
+func fairOrderSeed(block *hotstuff.Block, cert hotstuff.QuorumCert) ([]byte, bool) {
+	sig, ok := cert.Signature().(hotstuff.ThresholdSignature)
+	if !ok {
+		return nil, false
+	}
+	hash := block.Hash()
+	h := sha256.New()
+	_, _ = h.Write(fairOrderDomain)
+	_, _ = h.Write(hash[:])
+	_, _ = h.Write(sig.ToBytes())
+	return h.Sum(nil), true
+}
+
+// fairOrder reorders the commands of the batch according to the seed.
+// Each command is assigned the key H(seed || clientID || sequenceNumber), and the commands are sorted by key.
+func fairOrder(batch *clientpb.Batch, seed []byte) {
+	cmds := batch.GetCommands()
+	keys := make(map[*clientpb.Command][]byte, len(cmds))
+	var buf [12]byte
+	for _, cmd := range cmds {
+		binary.LittleEndian.PutUint32(buf[:4], cmd.GetClientID())
+		binary.LittleEndian.PutUint64(buf[4:], cmd.GetSequenceNumber())
+		h := sha256.New()
+		_, _ = h.Write(seed)
+		_, _ = h.Write(buf[:])
+		keys[cmd] = h.Sum(nil)
+	}
+	sort.SliceStable(cmds, func(i, j int) bool {
+		return bytes.Compare(keys[cmds[i]], keys[cmds[j]]) < 0
+	})
+}
+
+// fairExecutor executes committed batches in the order derived from the certificate of the block,
+// instead of the order chosen by the leader.
+type fairExecutor struct {
+	blockChain   modules.BlockChain
+	logger       logging.Logger
+	synchronizer modules.Synchronizer
+
+	executor modules.ExecutorExt
+	halted   bool // set if a block could not be executed in the same order as the other replicas.
+}
+
+// newFairExecutor returns a new fairExecutor that forwards the reordered batches to the executor.
+func newFairExecutor(executor modules.ExecutorExt) *fairExecutor {
+	return &fairExecutor{executor: executor}
+}
+
+// InitModule gives the module access to the other modules.
+func (fe *fairExecutor) InitModule(mods *modules.Core) {
+	mods.Get(
+		&fe.blockChain,
+		&fe.logger,
+		&fe.synchronizer,
+	)
+	if m, ok := fe.executor.(modules.Module); ok {
+		m.InitModule(mods)
+	}
+}
+
+// Exec executes the command in the block in the order derived from its certificate,
+// which is found in the child of the block.
+func (fe *fairExecutor) Exec(block *hotstuff.Block) {
+	fe.ExecCertified(block, hotstuff.QuorumCert{})
+}
+
+// ExecCertified executes the command in the block in the order derived from the certificate.
+// If the certificate does not certify the block, it is looked up in the child of the block.
+// If it cannot be found, the executor stops, since the replica cannot execute the block,
+// or any later block, in the same order as the other replicas.
+func (fe *fairExecutor) ExecCertified(block *hotstuff.Block, cert hotstuff.QuorumCert) {
+	if fe.halted {
+		fe.logger.Errorf("Not executing block %.8s, since an earlier block was not executed", block.Hash())
+		return
+	}
+	if cert.BlockHash() != block.Hash() {
+		var ok bool
+		if cert, ok = fe.certificate(block); !ok {
+			fe.logger.Errorf("Stopped executing at block %.8s, since its certificate is unknown", block.Hash())
+			fe.halted = true
+			return
+		}
+	}
+	next := block
+	if seed, ok := fairOrderSeed(block, cert); ok {
+		var err error
+		if next, err = fe.reorder(block, seed); err != nil {
+			fe.logger.Errorf("Failed to reorder block %.8s: %v", block.Hash(), err)
+			return
+		}
+	} else {
+		// the certificate was verified, so every replica has the same kind of signature and makes the same choice.
+		fe.logger.Errorf("Executing block %.8s in canonical order, since its certificate is not a threshold signature", block.Hash())
+	}
+	if ce, ok := fe.executor.(modules.CertifiedExecutor); ok {
+		ce.ExecCertified(next, cert)
+	} else {
+		fe.executor.Exec(next)
+	}
+}
+
+// certificate returns the certificate of the committed block, which is carried by its child.
+// The child is found on the branch that leads to the block of the highQC.
+func (fe *fairExecutor) certificate(block *hotstuff.Block) (hotstuff.QuorumCert, bool) {
+	child, ok := fe.blockChain.LocalGet(fe.synchronizer.HighQC().BlockHash())
+	for ok && child.View() > block.View() {
+		if child.Parent() == block.Hash() {
+			return child.QuorumCert(), true
+		}
+		child, ok = fe.blockChain.LocalGet(child.Parent())
+	}
+	return hotstuff.QuorumCert{}, false
+}
+
+// reorder returns a copy of the block whose commands are ordered according to the seed.
+func (fe *fairExecutor) reorder(block *hotstuff.Block, seed []byte) (*hotstuff.Block, error) {
+	batch := new(clientpb.Batch)
+	err := proto.UnmarshalOptions{AllowPartial: true}.Unmarshal([]byte(block.Command()), batch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal command: %w", err)
+	}
+	fairOrder(batch, seed)
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(batch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch: %w", err)
+	}
+	return block.WithCommand(hotstuff.Command(b)), nil
+}
+
+var (
+	_ modules.ExecutorExt       = (*fairExecutor)(nil)
+	_ modules.CertifiedExecutor = (*fairExecutor)(nil)
+)
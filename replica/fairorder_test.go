@@ -0,0 +1,240 @@
+package replica
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/crypto/bls12"
+	"github.com/relab/hotstuff/crypto/keygen"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+	"google.golang.org/protobuf/proto"
+)
+
+// recordingExecutor records the commands in the order they were executed.
+type recordingExecutor struct {
+	executed []cmdID
+}
+
+func (r *recordingExecutor) Exec(cmd hotstuff.Command) {
+	batch := new(clientpb.Batch)
+	if err := proto.Unmarshal([]byte(cmd), batch); err != nil {
+		panic(err)
+	}
+	for _, c := range batch.GetCommands() {
+		r.executed = append(r.executed, cmdID{c.GetClientID(), c.GetSequenceNumber()})
+	}
+}
+
+// testSignature is a quorum signature that only consists of its bytes.
+type testSignature []byte
+
+func (s testSignature) ToBytes() []byte { return s }
+
+func (testSignature) Participants() hotstuff.IDSet { return hotstuff.NewIDSet() }
+
+// testThresholdSignature is a threshold signature that only consists of its bytes.
+type testThresholdSignature struct{ testSignature }
+
+func (testThresholdSignature) Threshold() int { return 3 }
+
+func testCommands() []*clientpb.Command {
+	var cmds []*clientpb.Command
+	for client := uint32(1); client <= 4; client++ {
+		for seq := uint64(1); seq <= 4; seq++ {
+			cmds = append(cmds, &clientpb.Command{ClientID: client, SequenceNumber: seq, Data: []byte{byte(client), byte(seq)}})
+		}
+	}
+	return cmds
+}
+
+func marshalBatch(t *testing.T, cmds []*clientpb.Command) hotstuff.Command {
+	t.Helper()
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(&clientpb.Batch{Commands: cmds})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hotstuff.Command(b)
+}
+
+// TestFairOrderingByzantineLeader checks that replicas in fair ordering mode refuse to vote for
+// a batch whose order was chosen by a byzantine leader.
+func TestFairOrderingByzantineLeader(t *testing.T) {
+	cache := newCmdCache(Config{BatchSize: 16, FairOrdering: true})
+	cache.logger = logging.New("test")
+
+	// the byzantine leader puts its preferred commands first.
+	cmds := testCommands()
+	slices.Reverse(cmds)
+	if cache.Accept(marshalBatch(t, cmds)) {
+		t.Error("accepted batch ordered by the leader")
+	}
+
+	// a batch with duplicated commands is not canonical either.
+	cmds = testCommands()
+	if cache.Accept(marshalBatch(t, append(cmds, cmds[0]))) {
+		t.Error("accepted batch with duplicate commands")
+	}
+
+	if !cache.Accept(marshalBatch(t, testCommands())) {
+		t.Error("did not accept batch in canonical order")
+	}
+}
+
+// TestFairOrderingExec checks that the execution order is derived from the quorum certificate
+// and is the same at all replicas.
+func TestFairOrderingExec(t *testing.T) {
+	block := hotstuff.NewBlock(
+		hotstuff.GetGenesis().Hash(),
+		hotstuff.NewQuorumCert(nil, 0, hotstuff.GetGenesis().Hash()),
+		marshalBatch(t, testCommands()),
+		1, 1,
+	)
+
+	exec := func(cert hotstuff.QuorumCert) []cmdID {
+		r := &recordingExecutor{}
+		fe := newFairExecutor(modules.ExtendedExecutor(r))
+		fe.logger = logging.New("test")
+		fe.ExecCertified(block, cert)
+		return r.executed
+	}
+
+	qc := hotstuff.NewQuorumCert(testThresholdSignature{testSignature("signature")}, 1, block.Hash())
+	order := exec(qc)
+	if len(order) != len(testCommands()) {
+		t.Fatalf("executed %d commands, want %d", len(order), len(testCommands()))
+	}
+	if !slices.Equal(order, exec(qc)) {
+		t.Error("replicas executed the same certified block in different orders")
+	}
+
+	var canonical []cmdID
+	for _, cmd := range testCommands() {
+		canonical = append(canonical, cmdID{cmd.GetClientID(), cmd.GetSequenceNumber()})
+	}
+	if slices.Equal(order, canonical) {
+		t.Error("commands were executed in the proposed order")
+	}
+
+	// a different certificate for the same block results in a different order,
+	// so the leader cannot predict the order when it proposes the batch.
+	if slices.Equal(order, exec(hotstuff.NewQuorumCert(testThresholdSignature{testSignature("other signature")}, 1, block.Hash()))) {
+		t.Error("order does not depend on the quorum certificate")
+	}
+
+	// a signature that is not unique could be chosen by the replica that combined it, so it is not used.
+	if !slices.Equal(canonical, exec(hotstuff.NewQuorumCert(testSignature("signature"), 1, block.Hash()))) {
+		t.Error("order was derived from a signature that is not a threshold signature")
+	}
+}
+
+// TestFairOrderingByzantineAggregator checks that the replica that combines the votes for a block
+// cannot influence the execution order by choosing which quorum of votes to combine.
+func TestFairOrderingByzantineAggregator(t *testing.T) {
+	ids := []hotstuff.ID{1, 2, 3, 4}
+	threshold := hotstuff.QuorumSize(len(ids))
+	shares, pub, err := keygen.GenerateThresholdKeys(ids, threshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signers := make([]modules.CryptoBase, 0, len(ids))
+	for _, id := range ids {
+		signer := bls12.NewThreshold()
+		builder := modules.NewBuilder(id, nil)
+		builder.Add(logging.New("test"), &bls12.SigningKeys{Share: shares[id], Public: pub}, signer)
+		builder.Build()
+		signers = append(signers, signer)
+	}
+
+	block := hotstuff.NewBlock(
+		hotstuff.GetGenesis().Hash(),
+		hotstuff.NewQuorumCert(nil, 0, hotstuff.GetGenesis().Hash()),
+		marshalBatch(t, testCommands()),
+		1, 1,
+	)
+	votes := make([]hotstuff.QuorumSignature, 0, len(signers))
+	for _, signer := range signers {
+		vote, err := signer.Sign(block.ToBytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		votes = append(votes, vote)
+	}
+
+	// the byzantine aggregator tries every quorum of votes.
+	var want []cmdID
+	for i := 0; i+threshold <= len(votes); i++ {
+		sig, err := signers[0].Combine(votes[i : i+threshold]...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := &recordingExecutor{}
+		fe := newFairExecutor(modules.ExtendedExecutor(r))
+		fe.logger = logging.New("test")
+		fe.ExecCertified(block, hotstuff.NewQuorumCert(sig, 1, block.Hash()))
+		if want == nil {
+			want = r.executed
+		} else if !slices.Equal(want, r.executed) {
+			t.Errorf("quorum %d resulted in a different order", i)
+		}
+	}
+}
+
+// testChain is a block chain that only stores blocks locally.
+type testChain struct {
+	modules.BlockChain
+	blocks map[hotstuff.Hash]*hotstuff.Block
+}
+
+func (c testChain) LocalGet(hash hotstuff.Hash) (*hotstuff.Block, bool) {
+	block, ok := c.blocks[hash]
+	return block, ok
+}
+
+// testSynchronizer is a synchronizer with a fixed highQC.
+type testSynchronizer struct {
+	modules.Synchronizer
+	highQC hotstuff.QuorumCert
+}
+
+func (s testSynchronizer) HighQC() hotstuff.QuorumCert { return s.highQC }
+
+// TestFairOrderingUnknownCertificate checks that a block that is executed without its certificate
+// is executed in the order derived from the certificate in its child, and that the executor stops
+// if the certificate cannot be found, instead of executing the block in a different order.
+func TestFairOrderingUnknownCertificate(t *testing.T) {
+	genesis := hotstuff.GetGenesis()
+	block := hotstuff.NewBlock(genesis.Hash(), hotstuff.NewQuorumCert(nil, 0, genesis.Hash()), marshalBatch(t, testCommands()), 1, 1)
+	qc := hotstuff.NewQuorumCert(testThresholdSignature{testSignature("signature")}, 1, block.Hash())
+	child := hotstuff.NewBlock(block.Hash(), qc, "", 2, 1)
+	grandchild := hotstuff.NewBlock(child.Hash(), hotstuff.NewQuorumCert(testThresholdSignature{testSignature("child")}, 2, child.Hash()), "", 3, 1)
+	chain := testChain{blocks: map[hotstuff.Hash]*hotstuff.Block{block.Hash(): block, child.Hash(): child, grandchild.Hash(): grandchild}}
+
+	newExecutor := func(r *recordingExecutor, highQC hotstuff.QuorumCert) *fairExecutor {
+		fe := newFairExecutor(modules.ExtendedExecutor(r))
+		fe.logger = logging.New("test")
+		fe.blockChain = chain
+		fe.synchronizer = testSynchronizer{highQC: highQC}
+		return fe
+	}
+
+	want := &recordingExecutor{}
+	newExecutor(want, qc).ExecCertified(block, qc)
+
+	got := &recordingExecutor{}
+	newExecutor(got, grandchild.QuorumCert()).Exec(block)
+	if !slices.Equal(want.executed, got.executed) {
+		t.Error("block was not executed in the order derived from the certificate in its child")
+	}
+
+	// the highQC does not lead to the child, so the certificate is unknown.
+	got = &recordingExecutor{}
+	fe := newExecutor(got, hotstuff.NewQuorumCert(nil, 0, genesis.Hash()))
+	fe.Exec(block)
+	fe.ExecCertified(child, grandchild.QuorumCert())
+	if len(got.executed) != 0 {
+		t.Errorf("executed %d commands after the certificate of a block was not found", len(got.executed))
+	}
+}
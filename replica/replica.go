@@ -6,13 +6,23 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"net"
+	"time"
 
+	"github.com/relab/hotstuff/epoch"
 	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/evidence"
+	"github.com/relab/hotstuff/mempool"
 	"github.com/relab/hotstuff/modules"
 
 	"github.com/relab/gorums"
 	"github.com/relab/hotstuff"
 	"github.com/relab/hotstuff/backend"
+	"github.com/relab/hotstuff/blockchain"
+	"github.com/relab/hotstuff/blocksync"
+	"github.com/relab/hotstuff/crypto/bls12"
+	"github.com/relab/hotstuff/snapshot"
+	"github.com/relab/hotstuff/stake"
+	"github.com/relab/hotstuff/trie"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -36,8 +46,69 @@ type Config struct {
 	Certificate *tls.Certificate
 	// The root certificates trusted by the replica.
 	RootCAs *x509.CertPool
+	// The TLS credentials, which may be reloaded while the replica is running.
+	// If it is nil, the credentials are created from Certificate and RootCAs.
+	Credentials *backend.Credentials
 	// The number of client commands that should be batched together in a block.
 	BatchSize uint32
+	// The maximum size of a batch in bytes. Zero means no limit.
+	MaxBatchBytes uint32
+	// The maximum time to wait for a full batch before proposing a smaller batch.
+	// Zero means that the replica waits until the batch is full.
+	MaxBatchDelay time.Duration
+	// Controls whether the effective batch size is adjusted based on the observed commit latency.
+	AdaptiveBatching bool
+	// The number of sequence numbers above the highest contiguous committed sequence number
+	// that a client may have in flight. Zero means that a default window is used.
+	ClientWindow uint64
+	// The state store used to persist which client commands have been committed, such that
+	// commands are not executed again after a restart. Optional; the replica does not close the store,
+	// so the caller must close it after the replica is closed.
+	StateStore *blockchain.StateStore
+	// Controls whether the order of commands in a batch is derived from the quorum certificate of the block,
+	// rather than chosen by the leader. It requires threshold signatures, since the signature of
+	// the certificate must not depend on the quorum that created it.
+	FairOrdering bool
+	// The replica's share of the committee private key used to decrypt commands.
+	// If set, the commands are encrypted to the committee public key and decrypted after commit.
+	ThresholdKeyShare *bls12.ThresholdKeyShare
+	// The committee public key used to verify decryption shares.
+	ThresholdPublicKey *bls12.ThresholdPublicKey
+	// The keys used by the bls12-threshold crypto module to create and verify threshold signatures.
+	// They must be created separately from the keys used to decrypt commands.
+	SigningKeys *bls12.SigningKeys
+	// The state machine that executes the committed blocks, and the trie database that stores its state.
+	// If set, the replica serves snapshots of the state to other replicas, and a new replica downloads
+	// the state of a recent checkpoint instead of executing all blocks since genesis.
+	// The state machine executes the commands of the committed blocks after the mempool has delivered the batches,
+	// and after the commands are decrypted and fair ordered, in the same order as the executor that replies to the clients.
+	StateMachine snapshot.StateMachine
+	StateDB      *trie.BadgerTrieDB
+	// Returns the roots of the sub-tries referenced by the leaves of the state trie, such as evm.AccountStorageRoot.
+	StateLeaf trie.LeafCallback
+	// The number of views between checkpoints. Zero means that a checkpoint is recorded for every committed
+	// block if a state machine is set, and that no checkpoints are recorded otherwise.
+	// The checkpoints are persisted in the state store, if it is set.
+	CheckpointInterval hotstuff.View
+	// Controls what happens to the blocks and receipts below the latest checkpoint.
+	// The blocks are only pruned if StateMachine is set, such that lagging replicas can download the state instead.
+	Retention blockchain.RetentionMode
+	// The archive that pruned blocks and receipts are moved to if the retention mode is blockchain.RetainArchive.
+	// Optional; the replica closes the archive when it is closed.
+	Archive *blockchain.Archive
+	// The epoch manager that applies committed reconfiguration transactions.
+	// If set, the validator set can be changed at epoch boundaries without restarting the replicas.
+	Epochs *epoch.Manager
+	// The voting power of each replica. Replicas that are not in the map have one vote.
+	// If set, a quorum consists of the replicas that hold more than two thirds of the total voting power.
+	// This is ignored if Epochs is set, since the voting power is then part of the validator set of each epoch.
+	VotingPower map[hotstuff.ID]uint64
+	// The evidence pool that detects replicas that sign conflicting blocks in the same view.
+	// If set, the pending evidence is included in the proposed batches so that the offenders can be slashed.
+	Evidence *evidence.Pool
+	// Controls whether the client commands are disseminated by the mempool, separately from the proposals.
+	// If set, the proposals contain the availability certificates of the batches instead of the commands.
+	Mempool bool
 	// Options for the client server.
 	ClientServerOptions []gorums.ServerOption
 	// Options for the replica server.
@@ -54,6 +125,9 @@ type Replica struct {
 	cfg       *backend.Config
 	hsSrv     *backend.Server
 	hs        *modules.Core
+	blockSync *blocksync.BlockSync
+
+	archive *blockchain.Archive
 
 	execHandlers map[cmdID]func(*emptypb.Empty, error)
 	cancel       context.CancelFunc
@@ -64,9 +138,14 @@ type Replica struct {
 func New(conf Config, builder modules.Builder) (replica *Replica) {
 	clientSrvOpts := conf.ClientServerOptions
 
+	creds := conf.Credentials
+	if conf.TLS && creds == nil {
+		creds = backend.NewCredentials(conf.Certificate, conf.RootCAs)
+	}
+
 	if conf.TLS {
 		clientSrvOpts = append(clientSrvOpts, gorums.WithGRPCServerOptions(
-			grpc.Creds(credentials.NewServerTLSFromCert(conf.Certificate)),
+			grpc.Creds(creds.ServerCredentials(tls.NoClientCert)),
 		))
 	}
 
@@ -74,6 +153,8 @@ func New(conf Config, builder modules.Builder) (replica *Replica) {
 
 	srv := &Replica{
 		clientSrv:    clientSrv,
+		archive:      conf.Archive,
+		blockSync:    blocksync.New(),
 		execHandlers: make(map[cmdID]func(*emptypb.Empty, error)),
 		cancel:       func() {},
 		done:         make(chan struct{}),
@@ -82,11 +163,7 @@ func New(conf Config, builder modules.Builder) (replica *Replica) {
 	replicaSrvOpts := conf.ReplicaServerOptions
 	if conf.TLS {
 		replicaSrvOpts = append(replicaSrvOpts, gorums.WithGRPCServerOptions(
-			grpc.Creds(credentials.NewTLS(&tls.Config{
-				Certificates: []tls.Certificate{*conf.Certificate},
-				ClientCAs:    conf.RootCAs,
-				ClientAuth:   tls.RequireAndVerifyClientCert,
-			})),
+			grpc.Creds(creds.ServerCredentials(tls.RequireAndVerifyClientCert)),
 		))
 	}
 
@@ -95,34 +172,98 @@ func New(conf Config, builder modules.Builder) (replica *Replica) {
 		backend.WithGorumsServerOptions(replicaSrvOpts...),
 	)
 
-	var creds credentials.TransportCredentials
+	var managerCreds credentials.TransportCredentials
 	managerOpts := conf.ManagerOptions
 	if conf.TLS {
-		creds = credentials.NewTLS(&tls.Config{
-			RootCAs:      conf.RootCAs,
-			Certificates: []tls.Certificate{*conf.Certificate},
-		})
+		managerCreds = creds.ClientCredentials()
+	}
+	srv.cfg = backend.NewConfig(managerCreds, managerOpts...)
+
+	// The executors are wrapped from the inside out: every executor sees the commands
+	// after the outer executors have decrypted and reordered them, such that the state machine
+	// executes the same commands, in the same order, as the executor that replies to the clients.
+	var executor modules.ExecutorExt = srv.clientSrv
+	if conf.StateMachine != nil || conf.CheckpointInterval > 0 {
+		opts := []snapshot.ExecutorOption{
+			snapshot.WithCheckpointInterval(conf.CheckpointInterval),
+			snapshot.WithRetention(conf.Retention, conf.Archive),
+		}
+		if conf.StateStore != nil {
+			opts = append(opts, snapshot.WithStateStore(conf.StateStore))
+		}
+		snap := snapshot.NewExecutor(executor, conf.StateMachine, conf.StateDB, opts...)
+		executor = snap
+		// the snapshot executor is initialized by the executors that wrap it,
+		// so the server finds the checkpoints through a value that is not a module.
+		builder.Add(snapshotProvider{snap})
+	}
+	if conf.StateMachine != nil {
+		builder.Add(snapshot.NewSync(conf.StateDB, conf.StateLeaf))
+	}
+	if conf.Evidence != nil {
+		executor = conf.Evidence.WrapExecutor(executor)
+		builder.Add(conf.Evidence)
+	}
+	if conf.Epochs != nil {
+		executor = conf.Epochs.WrapExecutor(executor)
+		builder.Add(conf.Epochs)
+	} else if len(conf.VotingPower) > 0 {
+		builder.Add(stake.New(conf.VotingPower))
+	}
+	if conf.SigningKeys != nil {
+		builder.Add(conf.SigningKeys)
+	}
+	if conf.FairOrdering {
+		executor = newFairExecutor(executor)
+	}
+	if conf.ThresholdKeyShare != nil {
+		executor = newDecryptingExecutor(executor, srv.cfg, conf.ThresholdKeyShare, conf.ThresholdPublicKey)
+	}
+	var pool *mempool.Mempool
+	if conf.Mempool {
+		// the batches must be delivered before any of the other executors look at the commands.
+		var opts []mempool.Option
+		if conf.StateStore != nil {
+			opts = append(opts, mempool.WithStateStore(conf.StateStore))
+		}
+		pool = mempool.New(srv.clientSrv.cmdCache, opts...)
+		executor = pool.WrapExecutor(executor)
 	}
-	srv.cfg = backend.NewConfig(creds, managerOpts...)
 
 	builder.Add(
 		srv.cfg,   // configuration
 		srv.hsSrv, // event handling
+		srv.blockSync,
 
-		modules.ExtendedExecutor(srv.clientSrv),
+		executor,
 		modules.ExtendedForkHandler(srv.clientSrv),
 		srv.clientSrv.cmdCache,
 	)
+	if pool != nil {
+		// the mempool replaces the command cache as the command queue and acceptor.
+		builder.Add(pool)
+	}
 	srv.hs = builder.Build()
 
 	return srv
 }
 
+// snapshotProvider exposes the checkpoints of a snapshot executor without exposing its InitModule method.
+type snapshotProvider struct {
+	modules.SnapshotProvider
+}
+
 // Modules returns the Modules object of this replica.
 func (srv *Replica) Modules() *modules.Core {
 	return srv.hs
 }
 
+// BlockSync returns the module that downloads the missing blocks when the replica lags behind,
+// which reports the progress of the download.
+func (srv *Replica) BlockSync() *blocksync.BlockSync {
+	return srv.blockSync
+}
+
 // StartServers starts the client and replica servers.
 func (srv *Replica) StartServers(replicaListen, clientListen net.Listener) {
 	srv.hsSrv.StartOnListener(replicaListen)
@@ -159,6 +300,10 @@ func (srv *Replica) Run(ctx context.Context) {
 	)
 	srv.hs.Get(&synchronizer, &eventLoop)
 
+	var pool *mempool.Mempool
+	if srv.hs.TryGet(&pool) {
+		go pool.Run(ctx)
+	}
 	synchronizer.Start(ctx)
 	eventLoop.Run(ctx)
 }
@@ -168,6 +313,9 @@ func (srv *Replica) Close() {
 	srv.clientSrv.Stop()
 	srv.cfg.Close()
 	srv.hsSrv.Stop()
+	if srv.archive != nil {
+		_ = srv.archive.Close()
+	}
 }
 
 // GetHash returns the hash of all executed commands.
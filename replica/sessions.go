@@ -0,0 +1,99 @@
+package replica
+
+import (
+	"slices"
+
+	"github.com/relab/hotstuff/blockchain"
+)
+
+// defaultClientWindow is the default number of sequence numbers beyond the highest contiguous committed
+// sequence number that a client may have in flight.
+const defaultClientWindow = 1024
+
+// clientSessions tracks which commands have been committed for each client.
+// Commands are deduplicated based on the committed state, which is the same at all correct replicas,
+// rather than on the commands that have been proposed.
+// A client may have commands committed out of order, but only within a bounded window
+// above the highest sequence number such that all commands up to it have been committed.
+type clientSessions struct {
+	window   uint64
+	sessions map[uint32]*blockchain.ClientSession
+}
+
+func newClientSessions(window uint64) *clientSessions {
+	if window == 0 {
+		window = defaultClientWindow
+	}
+	return &clientSessions{
+		window:   window,
+		sessions: make(map[uint32]*blockchain.ClientSession),
+	}
+}
+
+// restore replaces the sessions with the given sessions.
+func (cs *clientSessions) restore(sessions map[uint32]blockchain.ClientSession) {
+	for clientID, session := range sessions {
+		cs.sessions[clientID] = &blockchain.ClientSession{
+			Committed:  session.Committed,
+			OutOfOrder: slices.Clone(session.OutOfOrder),
+		}
+	}
+}
+
+// isCommitted returns true if the command has been committed.
+func (cs *clientSessions) isCommitted(clientID uint32, seq uint64) bool {
+	session, ok := cs.sessions[clientID]
+	if !ok {
+		return false
+	}
+	if seq <= session.Committed {
+		return true
+	}
+	_, found := slices.BinarySearch(session.OutOfOrder, seq)
+	return found
+}
+
+// inWindow returns true if the sequence number is within the window of the client.
+func (cs *clientSessions) inWindow(clientID uint32, seq uint64) bool {
+	var committed uint64
+	if session, ok := cs.sessions[clientID]; ok {
+		committed = session.Committed
+	}
+	return seq <= committed+cs.window
+}
+
+// commit records that the command has been committed.
+// It returns false if the command was already committed.
+func (cs *clientSessions) commit(clientID uint32, seq uint64) bool {
+	if cs.isCommitted(clientID, seq) {
+		return false
+	}
+	session, ok := cs.sessions[clientID]
+	if !ok {
+		session = &blockchain.ClientSession{}
+		cs.sessions[clientID] = session
+	}
+	i, _ := slices.BinarySearch(session.OutOfOrder, seq)
+	session.OutOfOrder = slices.Insert(session.OutOfOrder, i, seq)
+
+	// advance the committed sequence number past the commands that are no longer out of order.
+	n := 0
+	for n < len(session.OutOfOrder) && session.OutOfOrder[n] == session.Committed+1 {
+		session.Committed++
+		n++
+	}
+	session.OutOfOrder = session.OutOfOrder[n:]
+	return true
+}
+
+// get returns a copy of the session of the client.
+func (cs *clientSessions) get(clientID uint32) blockchain.ClientSession {
+	session, ok := cs.sessions[clientID]
+	if !ok {
+		return blockchain.ClientSession{}
+	}
+	return blockchain.ClientSession{
+		Committed:  session.Committed,
+		OutOfOrder: slices.Clone(session.OutOfOrder),
+	}
+}
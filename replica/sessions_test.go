@@ -0,0 +1,123 @@
+package replica
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/blockchain"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+)
+
+func TestClientSessionsOutOfOrder(t *testing.T) {
+	sessions := newClientSessions(4)
+	for _, seq := range []uint64{1, 3, 4} {
+		if !sessions.commit(1, seq) {
+			t.Errorf("command %d was already committed", seq)
+		}
+	}
+	if sessions.commit(1, 3) {
+		t.Error("command 3 was committed twice")
+	}
+	if got := sessions.get(1); got.Committed != 1 || !slices.Equal(got.OutOfOrder, []uint64{3, 4}) {
+		t.Errorf("got session %+v, want committed 1 and out of order [3 4]", got)
+	}
+	if sessions.isCommitted(1, 2) {
+		t.Error("command 2 is not committed")
+	}
+	if sessions.inWindow(1, 6) {
+		t.Error("command 6 is outside the window")
+	}
+
+	sessions.commit(1, 2)
+	if got := sessions.get(1); got.Committed != 4 || len(got.OutOfOrder) != 0 {
+		t.Errorf("got session %+v, want committed 4", got)
+	}
+	if !sessions.inWindow(1, 8) {
+		t.Error("the window did not move")
+	}
+}
+
+// TestCmdCacheRequeueForked checks that forked commands are proposed again,
+// and that commands are only executed once, even if they are committed in multiple blocks.
+func TestCmdCacheRequeueForked(t *testing.T) {
+	cache := newTestCmdCache(Config{BatchSize: 2})
+	cmds := []*clientpb.Command{{ClientID: 1, SequenceNumber: 1}, {ClientID: 1, SequenceNumber: 2}}
+	for _, cmd := range cmds {
+		if err := cache.addCommand(cmd); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	proposed, ok := cache.Get(ctx)
+	if !ok {
+		t.Fatal("no batch was proposed")
+	}
+
+	// the proposal is forked, so the commands should be proposed again.
+	cache.requeue(&clientpb.Batch{Commands: cmds})
+	reproposed, ok := cache.Get(ctx)
+	if !ok {
+		t.Fatal("forked commands were not proposed again")
+	}
+	if proposed != reproposed {
+		t.Error("forked commands were not proposed again in the same order")
+	}
+
+	if n := len(cache.commit(&clientpb.Batch{Commands: cmds})); n != 2 {
+		t.Errorf("committed %d new commands, want 2", n)
+	}
+	if n := len(cache.commit(&clientpb.Batch{Commands: cmds})); n != 0 {
+		t.Errorf("committed %d commands twice", n)
+	}
+	if cache.Accept(marshalBatch(t, cmds)) {
+		t.Error("accepted batch with committed commands")
+	}
+}
+
+// TestClientSessionsRestart checks that committed commands are not executed again after a restart.
+func TestClientSessionsRestart(t *testing.T) {
+	dir := t.TempDir()
+	newServer := func() *clientSrv {
+		store, err := blockchain.NewStateStore(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv := newClientServer(Config{BatchSize: 1, StateStore: store}, nil)
+		builder := modules.NewBuilder(1, nil)
+		builder.Add(eventloop.New(100), logging.New("test"), srv)
+		builder.Build()
+		return srv
+	}
+
+	genesis := hotstuff.GetGenesis()
+	block := hotstuff.NewBlock(genesis.Hash(), hotstuff.NewQuorumCert(nil, 0, genesis.Hash()), marshalBatch(t, testCommands()), 1, 1)
+	srv := newServer()
+	srv.Exec(block)
+	if srv.cmdCount != uint32(len(testCommands())) {
+		t.Fatalf("executed %d commands, want %d", srv.cmdCount, len(testCommands()))
+	}
+	if err := srv.stateStore.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	srv = newServer()
+	defer srv.stateStore.Close()
+	if hash, err := srv.stateStore.GetCommittedBlockHash(); err != nil || hash != block.Hash() {
+		t.Errorf("got executed block %.8s (err: %v), want %.8s", hash, err, block.Hash())
+	}
+	srv.Exec(block)
+	if srv.cmdCount != 0 {
+		t.Errorf("executed %d commands again after restart", srv.cmdCount)
+	}
+	if err := srv.cmdCache.addCommand(testCommands()[0]); err != errCommitted {
+		t.Errorf("got %v, want errCommitted", err)
+	}
+}
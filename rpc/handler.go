@@ -8,12 +8,20 @@ import (
 	"strings"
 
 	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/blocksync"
 	"github.com/relab/hotstuff/logging"
 )
 
+// SyncProgress reports the progress of block synchronization.
+type SyncProgress interface {
+	// Progress returns the progress of the current sync, and false if the node is not syncing.
+	Progress() (blocksync.Progress, bool)
+}
+
 // Handler implements the Ethereum JSON-RPC API
 type Handler struct {
 	service Service
+	sync    SyncProgress
 	logger  logging.Logger
 }
 
@@ -25,6 +33,12 @@ func NewHandler(service Service) *Handler {
 	}
 }
 
+// SetSyncProgress sets the source of the progress reported by eth_syncing.
+// If it is not set, eth_syncing always reports that the node is synced.
+func (h *Handler) SetSyncProgress(sync SyncProgress) {
+	h.sync = sync
+}
+
 // ServeHTTP implements the http.Handler interface
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -126,7 +140,17 @@ func (h *Handler) handleRequest(req *JSONRPCRequest) (interface{}, *RPCError) {
 	case "eth_mining":
 		return false, nil // Not a PoW chain
 	case "eth_syncing":
-		return false, nil // Always synced for now
+		return h.syncing(req.Params)
+
+	// Transaction pool methods
+	case "txpool_status":
+		return h.txPoolStatus(req.Params)
+	case "txpool_content":
+		return h.txPoolContent(req.Params)
+	case "txpool_contentFrom":
+		return h.txPoolContentFrom(req.Params)
+	case "txpool_inspect":
+		return h.txPoolInspect(req.Params)
 
 	default:
 		return nil, NewRPCError(MethodNotFound, fmt.Sprintf("method %s not found", req.Method), nil)
@@ -590,6 +614,67 @@ func (h *Handler) getLogs(params json.RawMessage) (interface{}, *RPCError) {
 	return rpcLogs, nil
 }
 
+// Sync methods
+
+func (h *Handler) syncing(params json.RawMessage) (interface{}, *RPCError) {
+	if h.sync == nil {
+		return false, nil
+	}
+	progress, syncing := h.sync.Progress()
+	if !syncing {
+		return false, nil
+	}
+	return NewSyncStatus(progress), nil
+}
+
+// Transaction pool methods
+
+func (h *Handler) txPoolStatus(params json.RawMessage) (interface{}, *RPCError) {
+	pending, queued := h.service.TxPoolContent()
+
+	var numPending, numQueued uint64
+	for _, txs := range pending {
+		numPending += uint64(len(txs))
+	}
+	for _, txs := range queued {
+		numQueued += uint64(len(txs))
+	}
+
+	return TxPoolStatus{
+		Pending: NewHexNumber(numPending),
+		Queued:  NewHexNumber(numQueued),
+	}, nil
+}
+
+func (h *Handler) txPoolContent(params json.RawMessage) (interface{}, *RPCError) {
+	pending, queued := h.service.TxPoolContent()
+	return NewTxPoolContent(pending, queued), nil
+}
+
+func (h *Handler) txPoolContentFrom(params json.RawMessage) (interface{}, *RPCError) {
+	var args []string
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, NewRPCError(InvalidParams, "invalid parameters", err.Error())
+	}
+
+	if len(args) < 1 {
+		return nil, NewRPCError(InvalidParams, "missing address parameter", nil)
+	}
+
+	address, err := Address(args[0]).ToTxpoolAddress()
+	if err != nil {
+		return nil, NewRPCError(InvalidParams, "invalid address format", err.Error())
+	}
+
+	pending, queued := h.service.TxPoolContent()
+	return NewTxPoolContentFrom(address, pending[address], queued[address]), nil
+}
+
+func (h *Handler) txPoolInspect(params json.RawMessage) (interface{}, *RPCError) {
+	pending, queued := h.service.TxPoolContent()
+	return NewTxPoolInspect(pending, queued), nil
+}
+
 // Helper methods
 
 func (h *Handler) parseBlockNumber(param interface{}) (*big.Int, error) {
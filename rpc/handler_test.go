@@ -0,0 +1,183 @@
+package rpc
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/relab/hotstuff/blocksync"
+	"github.com/relab/hotstuff/txpool"
+)
+
+// testService is a Service with a fixed transaction pool.
+type testService struct {
+	Service
+	pending, queued map[txpool.Address][]*txpool.Transaction
+}
+
+func (s testService) TxPoolContent() (pending, queued map[txpool.Address][]*txpool.Transaction) {
+	return s.pending, s.queued
+}
+
+// testResponse is a JSON-RPC response whose result is decoded by the test.
+type testResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// call sends a JSON-RPC request to the handler and returns the response.
+func call(t *testing.T, h *Handler, method, params string) testResponse {
+	t.Helper()
+	body := `{"jsonrpc":"2.0","id":1,"method":"` + method + `","params":` + params + `}`
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	var resp testResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response to %s: %v", method, err)
+	}
+	return resp
+}
+
+// decode decodes the result of a successful response.
+func decode(t *testing.T, resp testResponse, v any) {
+	t.Helper()
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %+v", resp.Error)
+	}
+	if err := json.Unmarshal(resp.Result, v); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+}
+
+func newTestTxPoolHandler() (h *Handler, alice, bob, to txpool.Address) {
+	alice, bob, to = txpool.Address{1}, txpool.Address{2}, txpool.Address{3}
+	newTx := func(nonce uint64, to *txpool.Address) *txpool.Transaction {
+		return &txpool.Transaction{Nonce: nonce, GasPrice: big.NewInt(2), GasLimit: 21000, To: to, Value: big.NewInt(5)}
+	}
+	service := testService{
+		pending: map[txpool.Address][]*txpool.Transaction{
+			alice: {newTx(9, &to), newTx(10, nil)},
+			bob:   {newTx(0, &to)},
+		},
+		queued: map[txpool.Address][]*txpool.Transaction{
+			alice: {newTx(12, &to)},
+		},
+	}
+	return NewHandler(service), alice, bob, to
+}
+
+func TestTxPoolStatus(t *testing.T) {
+	h, _, _, _ := newTestTxPoolHandler()
+	var status TxPoolStatus
+	decode(t, call(t, h, "txpool_status", "[]"), &status)
+	if status.Pending != "0x3" || status.Queued != "0x1" {
+		t.Errorf("got %+v, want 3 pending and 1 queued", status)
+	}
+}
+
+func TestTxPoolContent(t *testing.T) {
+	h, alice, bob, to := newTestTxPoolHandler()
+	var content TxPoolContent
+	decode(t, call(t, h, "txpool_content", "[]"), &content)
+
+	pending := content.Pending[NewAddress(alice)]
+	if len(pending) != 2 || pending["9"] == nil || pending["10"] == nil {
+		t.Fatalf("got pending transactions %v of alice, want nonces 9 and 10", pending)
+	}
+	if tx := pending["9"]; tx.From != NewAddress(alice) || tx.To == nil || *tx.To != NewAddress(to) || tx.Nonce != "0x9" {
+		t.Errorf("got transaction %+v, want nonce 9 from alice to %s", tx, NewAddress(to))
+	}
+	if tx := pending["10"]; tx.To != nil {
+		t.Errorf("got recipient %s of contract creation", *tx.To)
+	}
+	if len(content.Pending[NewAddress(bob)]) != 1 {
+		t.Errorf("got %d pending transactions of bob, want 1", len(content.Pending[NewAddress(bob)]))
+	}
+	if queued := content.Queued[NewAddress(alice)]; len(queued) != 1 || queued["12"] == nil {
+		t.Errorf("got queued transactions %v of alice, want nonce 12", queued)
+	}
+	if _, ok := content.Queued[NewAddress(bob)]; ok {
+		t.Error("bob has no queued transactions")
+	}
+}
+
+func TestTxPoolContentFrom(t *testing.T) {
+	h, alice, _, _ := newTestTxPoolHandler()
+	var content TxPoolContentFrom
+	decode(t, call(t, h, "txpool_contentFrom", `["`+string(NewAddress(alice))+`"]`), &content)
+	if len(content.Pending) != 2 || content.Pending["9"] == nil || content.Pending["10"] == nil {
+		t.Errorf("got pending transactions %v, want nonces 9 and 10", content.Pending)
+	}
+	if len(content.Queued) != 1 || content.Queued["12"] == nil {
+		t.Errorf("got queued transactions %v, want nonce 12", content.Queued)
+	}
+
+	var unknown TxPoolContentFrom
+	decode(t, call(t, h, "txpool_contentFrom", `["`+string(NewAddress(txpool.Address{4}))+`"]`), &unknown)
+	if len(unknown.Pending) != 0 || len(unknown.Queued) != 0 {
+		t.Errorf("got transactions %+v of unknown sender", unknown)
+	}
+
+	for _, params := range []string{`{}`, `[]`, `["0x01"]`, `["0x`+strings.Repeat("z", 40)+`"]`} {
+		resp := call(t, h, "txpool_contentFrom", params)
+		if resp.Error == nil || resp.Error.Code != InvalidParams {
+			t.Errorf("got error %+v for parameters %s, want invalid params", resp.Error, params)
+		}
+	}
+}
+
+func TestTxPoolInspect(t *testing.T) {
+	h, alice, bob, to := newTestTxPoolHandler()
+	var inspect TxPoolInspect
+	decode(t, call(t, h, "txpool_inspect", "[]"), &inspect)
+
+	want := map[string]string{
+		"9":  string(NewAddress(to)) + ": 5 wei + 21000 gas × 2 wei",
+		"10": "contract creation: 5 wei + 21000 gas × 2 wei",
+	}
+	pending := inspect.Pending[NewAddress(alice)]
+	for nonce, summary := range want {
+		if pending[nonce] != summary {
+			t.Errorf("got summary %q of nonce %s, want %q", pending[nonce], nonce, summary)
+		}
+	}
+	if len(inspect.Pending[NewAddress(bob)]) != 1 {
+		t.Errorf("got %d pending transactions of bob, want 1", len(inspect.Pending[NewAddress(bob)]))
+	}
+	if queued := inspect.Queued[NewAddress(alice)]; len(queued) != 1 || queued["12"] == "" {
+		t.Errorf("got queued transactions %v of alice, want nonce 12", queued)
+	}
+}
+
+// testSyncProgress reports a fixed sync progress.
+type testSyncProgress struct {
+	progress blocksync.Progress
+	syncing  bool
+}
+
+func (s *testSyncProgress) Progress() (blocksync.Progress, bool) { return s.progress, s.syncing }
+
+func TestSyncing(t *testing.T) {
+	h := NewHandler(testService{})
+	var synced bool
+	decode(t, call(t, h, "eth_syncing", "[]"), &synced)
+	if synced {
+		t.Error("got syncing without a sync progress")
+	}
+
+	progress := &testSyncProgress{progress: blocksync.Progress{StartingView: 10, CurrentView: 20, HighestView: 300}, syncing: true}
+	h.SetSyncProgress(progress)
+	var status SyncStatus
+	decode(t, call(t, h, "eth_syncing", "[]"), &status)
+	if want := (SyncStatus{StartingBlock: "0xa", CurrentBlock: "0x14", HighestBlock: "0x12c"}); status != want {
+		t.Errorf("got %+v, want %+v", status, want)
+	}
+
+	progress.syncing = false
+	decode(t, call(t, h, "eth_syncing", "[]"), &synced)
+	if synced {
+		t.Error("got syncing after the sync was done")
+	}
+}
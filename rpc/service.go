@@ -39,6 +39,9 @@ type Service interface {
 
 	// Utility operations
 	GetLogs(filter LogFilter) ([]evm.Log, error)
+
+	// Transaction pool operations
+	TxPoolContent() (pending, queued map[txpool.Address][]*txpool.Transaction)
 }
 
 // LogFilter represents a filter for eth_getLogs
@@ -74,6 +77,7 @@ type TxPoolService interface {
 	AddTransaction(tx *txpool.Transaction) error
 	GetTransaction(hash hotstuff.Hash) (*txpool.Transaction, error)
 	GetPendingTransactions() []*txpool.Transaction
+	Content() (pending, queued map[txpool.Address][]*txpool.Transaction)
 }
 
 // StateService defines state operations
@@ -361,6 +365,12 @@ func (s *ServiceImpl) GetLogs(filter LogFilter) ([]evm.Log, error) {
 	return logs, nil
 }
 
+// Transaction pool operations
+
+func (s *ServiceImpl) TxPoolContent() (pending, queued map[txpool.Address][]*txpool.Transaction) {
+	return s.txpool.Content()
+}
+
 // Helper methods
 
 func (s *ServiceImpl) getStateDB(blockNumber *big.Int) (evm.StateDB, error) {
@@ -3,6 +3,7 @@ package rpc
 import (
 	"fmt"
 	"math/big"
+	"sort"
 	"sync"
 	"time"
 
@@ -439,6 +440,12 @@ func (s *SimpleRPCService) GetLogs(filter LogFilter) ([]evm.Log, error) {
 	return logs, nil
 }
 
+// Transaction pool operations
+
+func (s *SimpleRPCService) TxPoolContent() (pending, queued map[txpool.Address][]*txpool.Transaction) {
+	return s.pool.Content()
+}
+
 // Block production methods
 
 // ProcessPendingTransactions processes transactions from the txpool and creates a new block
@@ -650,3 +657,21 @@ func (p *SimpleTxPool) GetPendingTransactions() []*txpool.Transaction {
 	}
 	return txs
 }
+
+// Content returns all transactions grouped by sender. The simple pool has no queue,
+// so every transaction is reported as pending.
+func (p *SimpleTxPool) Content() (pending, queued map[txpool.Address][]*txpool.Transaction) {
+	pending = make(map[txpool.Address][]*txpool.Transaction)
+	queued = make(map[txpool.Address][]*txpool.Transaction)
+	for _, tx := range p.pool {
+		from, err := tx.From()
+		if err != nil {
+			continue
+		}
+		pending[*from] = append(pending[*from], tx)
+	}
+	for _, txs := range pending {
+		sort.Slice(txs, func(i, j int) bool { return txs[i].Nonce < txs[j].Nonce })
+	}
+	return pending, queued
+}
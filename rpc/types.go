@@ -8,6 +8,7 @@ import (
 	"strconv"
 
 	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/blocksync"
 	"github.com/relab/hotstuff/evm"
 	"github.com/relab/hotstuff/txpool"
 )
@@ -86,7 +87,7 @@ type Address string
 
 // NewAddress creates an address from txpool.Address
 func NewAddress(addr txpool.Address) Address {
-	return Address("0x" + fmt.Sprintf("%040x", addr))
+	return Address(fmt.Sprintf("0x%040x", addr[:]))
 }
 
 // ToTxpoolAddress converts to txpool.Address
@@ -437,6 +438,114 @@ func (args *CallArgs) ToTxpoolTransaction() (*txpool.Transaction, error) {
 	return tx, nil
 }
 
+// SyncStatus represents the result of eth_syncing while the node is syncing.
+// Block numbers are the views of the blocks.
+type SyncStatus struct {
+	StartingBlock HexNumber `json:"startingBlock"`
+	CurrentBlock  HexNumber `json:"currentBlock"`
+	HighestBlock  HexNumber `json:"highestBlock"`
+}
+
+// NewSyncStatus creates a SyncStatus from the progress of a block sync.
+func NewSyncStatus(p blocksync.Progress) SyncStatus {
+	return SyncStatus{
+		StartingBlock: NewHexNumber(uint64(p.StartingView)),
+		CurrentBlock:  NewHexNumber(uint64(p.CurrentView)),
+		HighestBlock:  NewHexNumber(uint64(p.HighestView)),
+	}
+}
+
+// TxPoolStatus represents the result of txpool_status
+type TxPoolStatus struct {
+	Pending HexNumber `json:"pending"`
+	Queued  HexNumber `json:"queued"`
+}
+
+// TxPoolContent represents the result of txpool_content.
+// Transactions are grouped by sender and keyed by their decimal nonce.
+type TxPoolContent struct {
+	Pending map[Address]map[string]*Transaction `json:"pending"`
+	Queued  map[Address]map[string]*Transaction `json:"queued"`
+}
+
+// TxPoolContentFrom represents the result of txpool_contentFrom.
+// Transactions are keyed by their decimal nonce.
+type TxPoolContentFrom struct {
+	Pending map[string]*Transaction `json:"pending"`
+	Queued  map[string]*Transaction `json:"queued"`
+}
+
+// TxPoolInspect represents the result of txpool_inspect.
+// Transactions are grouped by sender, keyed by their decimal nonce and summarized as text.
+type TxPoolInspect struct {
+	Pending map[Address]map[string]string `json:"pending"`
+	Queued  map[Address]map[string]string `json:"queued"`
+}
+
+// NewTxPoolContent creates a TxPoolContent from transactions grouped by sender
+func NewTxPoolContent(pending, queued map[txpool.Address][]*txpool.Transaction) *TxPoolContent {
+	content := &TxPoolContent{
+		Pending: make(map[Address]map[string]*Transaction, len(pending)),
+		Queued:  make(map[Address]map[string]*Transaction, len(queued)),
+	}
+	for from, txs := range pending {
+		content.Pending[NewAddress(from)] = newTxPoolNonceMap(from, txs)
+	}
+	for from, txs := range queued {
+		content.Queued[NewAddress(from)] = newTxPoolNonceMap(from, txs)
+	}
+	return content
+}
+
+// NewTxPoolContentFrom creates a TxPoolContentFrom from the transactions of a single sender
+func NewTxPoolContentFrom(from txpool.Address, pending, queued []*txpool.Transaction) *TxPoolContentFrom {
+	return &TxPoolContentFrom{
+		Pending: newTxPoolNonceMap(from, pending),
+		Queued:  newTxPoolNonceMap(from, queued),
+	}
+}
+
+// NewTxPoolInspect creates a TxPoolInspect from transactions grouped by sender
+func NewTxPoolInspect(pending, queued map[txpool.Address][]*txpool.Transaction) *TxPoolInspect {
+	inspect := &TxPoolInspect{
+		Pending: make(map[Address]map[string]string, len(pending)),
+		Queued:  make(map[Address]map[string]string, len(queued)),
+	}
+	for from, txs := range pending {
+		inspect.Pending[NewAddress(from)] = newTxPoolSummaryMap(txs)
+	}
+	for from, txs := range queued {
+		inspect.Queued[NewAddress(from)] = newTxPoolSummaryMap(txs)
+	}
+	return inspect
+}
+
+func newTxPoolNonceMap(from txpool.Address, txs []*txpool.Transaction) map[string]*Transaction {
+	nonces := make(map[string]*Transaction, len(txs))
+	for _, tx := range txs {
+		rpcTx := NewTransactionFromTxpool(tx, nil, nil, nil)
+		rpcTx.From = NewAddress(from)
+		nonces[strconv.FormatUint(tx.Nonce, 10)] = rpcTx
+	}
+	return nonces
+}
+
+func newTxPoolSummaryMap(txs []*txpool.Transaction) map[string]string {
+	summaries := make(map[string]string, len(txs))
+	for _, tx := range txs {
+		summaries[strconv.FormatUint(tx.Nonce, 10)] = txPoolSummary(tx)
+	}
+	return summaries
+}
+
+// txPoolSummary formats a transaction the same way as geth's txpool_inspect
+func txPoolSummary(tx *txpool.Transaction) string {
+	if tx.To == nil {
+		return fmt.Sprintf("contract creation: %v wei + %d gas × %v wei", tx.Value, tx.GasLimit, tx.GasPrice)
+	}
+	return fmt.Sprintf("%s: %v wei + %d gas × %v wei", NewAddress(*tx.To), tx.Value, tx.GasLimit, tx.GasPrice)
+}
+
 // JSONRPCRequest represents a JSON-RPC 2.0 request
 type JSONRPCRequest struct {
 	JSONRPC string          `json:"jsonrpc"`
@@ -0,0 +1,295 @@
+// Package snapshot implements state snapshot sync (fast sync) for replicas that run a trie-backed state machine.
+//
+// Replicas record a checkpoint, consisting of the certificate of a committed block and the state root
+// after executing the block, every N committed views, and serve the trie nodes of the state to other replicas.
+// The checkpoints can be persisted in a blockchain.StateStore, and the block bodies and receipts
+// below the latest checkpoint can be archived or deleted, depending on the retention mode.
+// A new replica downloads the latest checkpoint that at least f+1 replicas agree on,
+// downloads and verifies the state trie against the state root, and installs it.
+// Afterwards, the replica continues with block sync from the view of the checkpoint.
+package snapshot
+
+import (
+	"sync"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/blockchain"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+	"github.com/relab/hotstuff/trie"
+)
+
+// maxCheckpoints is the number of recent checkpoints that are served to other replicas.
+const maxCheckpoints = 100
+
+// StateMachine is an application whose state is stored in a Merkle Patricia trie.
+type StateMachine interface {
+	modules.ExecutorExt
+	// StateRoot returns the root of the state trie after executing the blocks so far.
+	StateRoot() hotstuff.Hash
+	// Restore replaces the state with the state that has the given root.
+	// The nodes of the state trie are already stored in the trie database when Restore is called.
+	Restore(stateRoot hotstuff.Hash) error
+}
+
+// ExecutorOption configures an Executor.
+type ExecutorOption func(*Executor)
+
+// WithCheckpointInterval records a checkpoint for the first certified block in every interval of n views.
+// By default, a checkpoint is recorded for every certified block.
+func WithCheckpointInterval(n hotstuff.View) ExecutorOption {
+	return func(e *Executor) {
+		e.interval = max(n, 1)
+	}
+}
+
+// WithStateStore persists the checkpoints in the state store,
+// such that they are served to other replicas after a restart.
+func WithStateStore(store *blockchain.StateStore) ExecutorOption {
+	return func(e *Executor) {
+		e.store = store
+	}
+}
+
+// WithRetention sets what happens to the blocks and receipts below the latest checkpoint.
+// The archive is only used with blockchain.RetainArchive.
+// The blocks are only pruned if a StateSync module is available, since a replica that lags behind
+// the pruned blocks cannot catch up by block sync, and must download the state of a checkpoint instead.
+func WithRetention(mode blockchain.RetentionMode, archive *blockchain.Archive) ExecutorOption {
+	return func(e *Executor) {
+		e.retention = mode
+		e.archive = archive
+	}
+}
+
+// Executor executes committed blocks on a state machine and periodically records checkpoints.
+// The blocks are also passed on to another executor, such as the one that replies to the clients.
+type Executor struct {
+	eventLoop *eventloop.EventLoop
+	logger    logging.Logger
+
+	executor modules.ExecutorExt
+	state    StateMachine
+	db       *trie.BadgerTrieDB
+
+	interval  hotstuff.View
+	store     *blockchain.StateStore
+	retention blockchain.RetentionMode
+	archive   *blockchain.Archive
+	pruners   []modules.Pruner
+
+	mut         sync.Mutex
+	checkpoints map[hotstuff.Hash]hotstuff.Checkpoint
+	order       []hotstuff.Hash // the order in which checkpoints were recorded.
+	last        hotstuff.View   // the view of the latest checkpoint.
+}
+
+// NewExecutor returns a new Executor that executes the blocks on the state machine whose trie is stored in db.
+// The executor argument is optional. If the state machine is nil, the checkpoints have a zero state root.
+func NewExecutor(executor modules.ExecutorExt, state StateMachine, db *trie.BadgerTrieDB, opts ...ExecutorOption) *Executor {
+	e := &Executor{
+		executor:    executor,
+		state:       state,
+		db:          db,
+		interval:    1,
+		retention:   blockchain.RetainAll,
+		checkpoints: make(map[hotstuff.Hash]hotstuff.Checkpoint),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// InitModule gives the module access to the other modules.
+func (e *Executor) InitModule(mods *modules.Core) {
+	mods.Get(
+		&e.eventLoop,
+		&e.logger,
+	)
+	for _, m := range []any{e.executor, e.state} {
+		if m, ok := m.(modules.Module); ok {
+			m.InitModule(mods)
+		}
+	}
+
+	candidates := []any{e.state, e.executor}
+	var blockChain modules.BlockChain
+	if mods.TryGet(&blockChain) {
+		candidates = append(candidates, blockChain)
+	}
+	for _, m := range candidates {
+		if pruner, ok := m.(modules.Pruner); ok {
+			e.pruners = append(e.pruners, pruner)
+		}
+	}
+	var stateSync modules.StateSync
+	if e.retention != blockchain.RetainAll && !mods.TryGet(&stateSync) {
+		e.logger.Warnf("Keeping all blocks: retention mode %s requires state sync, such that lagging replicas can catch up", e.retention)
+		e.pruners = nil
+	}
+
+	if e.store != nil {
+		checkpoints, err := e.store.GetCheckpoints()
+		if err != nil {
+			e.logger.Errorf("Failed to load checkpoints: %v", err)
+		}
+		for _, checkpoint := range checkpoints {
+			e.record(checkpoint)
+		}
+	}
+
+	e.eventLoop.RegisterHandler(stateDownloaded{}, func(event any) {
+		e.onStateDownloaded(event.(stateDownloaded))
+	})
+}
+
+// Exec executes the block without recording a checkpoint, since the certificate of the block is unknown.
+// The state machine executes the block before the wrapped executor, such that the wrapped executor
+// can persist the executed block together with the resulting state.
+func (e *Executor) Exec(block *hotstuff.Block) {
+	if e.state != nil {
+		e.state.Exec(block)
+	}
+	if e.executor != nil {
+		e.executor.Exec(block)
+	}
+}
+
+// ExecCertified executes the block and records a checkpoint of the resulting state
+// if the block is the first certified block in a new checkpoint interval.
+func (e *Executor) ExecCertified(block *hotstuff.Block, cert hotstuff.QuorumCert) {
+	var stateRoot hotstuff.Hash
+	if e.state != nil {
+		e.state.Exec(block)
+		stateRoot = e.state.StateRoot()
+	}
+	if ce, ok := e.executor.(modules.CertifiedExecutor); ok {
+		ce.ExecCertified(block, cert)
+	} else if e.executor != nil {
+		e.executor.Exec(block)
+	}
+
+	e.mut.Lock()
+	due := len(e.order) == 0 || block.View()/e.interval > e.last/e.interval
+	e.mut.Unlock()
+	if !due {
+		return
+	}
+	checkpoint := hotstuff.Checkpoint{QC: cert, StateRoot: stateRoot}
+	e.record(checkpoint)
+	e.persist(checkpoint)
+	e.prune(checkpoint.View())
+}
+
+// record adds the checkpoint to the recent checkpoints.
+func (e *Executor) record(checkpoint hotstuff.Checkpoint) {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	if _, ok := e.checkpoints[checkpoint.BlockHash()]; ok {
+		return
+	}
+	if len(e.order) >= maxCheckpoints {
+		delete(e.checkpoints, e.order[0])
+		e.order = e.order[1:]
+	}
+	e.checkpoints[checkpoint.BlockHash()] = checkpoint
+	e.order = append(e.order, checkpoint.BlockHash())
+	e.last = max(e.last, checkpoint.View())
+}
+
+// persist saves the checkpoint in the state store, and removes the checkpoints that are no longer served.
+func (e *Executor) persist(checkpoint hotstuff.Checkpoint) {
+	if e.store == nil {
+		return
+	}
+	if err := e.store.SetCheckpoint(checkpoint); err != nil {
+		e.logger.Errorf("Failed to save checkpoint at view %d: %v", checkpoint.View(), err)
+		return
+	}
+	e.mut.Lock()
+	oldest := e.checkpoints[e.order[0]].View()
+	e.mut.Unlock()
+	if err := e.store.DeleteCheckpointsBelow(oldest); err != nil {
+		e.logger.Errorf("Failed to delete old checkpoints: %v", err)
+	}
+}
+
+// prune archives or deletes the blocks and receipts below the checkpoint, according to the retention mode.
+// The block of the checkpoint itself is kept, such that it can be served to replicas that sync from the checkpoint.
+func (e *Executor) prune(view hotstuff.View) {
+	var archive func(key, value []byte) error
+	switch e.retention {
+	case blockchain.RetainArchive:
+		if e.archive == nil {
+			e.logger.Error("Retention mode is archive, but no archive was configured")
+			return
+		}
+		archive = e.archive.Put
+	case blockchain.RetainDelete:
+	default:
+		return
+	}
+	for _, pruner := range e.pruners {
+		if err := pruner.PruneBelow(view, archive); err != nil {
+			e.logger.Errorf("Failed to prune below view %d: %v", view, err)
+		}
+	}
+}
+
+// Checkpoint returns the checkpoint of the block with the given hash,
+// or the latest checkpoint if the hash is the zero hash.
+func (e *Executor) Checkpoint(hash hotstuff.Hash) (hotstuff.Checkpoint, bool) {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	if hash == (hotstuff.Hash{}) {
+		if len(e.order) == 0 {
+			return hotstuff.Checkpoint{}, false
+		}
+		hash = e.order[len(e.order)-1]
+	}
+	checkpoint, ok := e.checkpoints[hash]
+	return checkpoint, ok
+}
+
+// TrieNodes returns the encoded trie nodes with the given hashes. Nodes that are not found are left out.
+func (e *Executor) TrieNodes(hashes []hotstuff.Hash) [][]byte {
+	nodes := make([][]byte, 0, len(hashes))
+	if e.db == nil {
+		return nil
+	}
+	for _, hash := range hashes {
+		data, err := e.db.NodeData(hash)
+		if err != nil {
+			continue
+		}
+		nodes = append(nodes, data)
+	}
+	return nodes
+}
+
+// onStateDownloaded installs the state that was downloaded by the state sync.
+// The StateSyncedEvent is only raised if the state was installed, such that the consensus module
+// does not continue from a checkpoint whose state the replica does not have.
+// Otherwise, the sync is aborted, and the replica continues with block sync.
+func (e *Executor) onStateDownloaded(event stateDownloaded) {
+	checkpoint := event.synced.Checkpoint
+	if e.state != nil {
+		if err := e.state.Restore(checkpoint.StateRoot); err != nil {
+			e.logger.Errorf("Failed to restore state %.8s: %v", checkpoint.StateRoot, err)
+			e.eventLoop.AddEvent(stateSyncFailed{checkpoint: checkpoint})
+			return
+		}
+	}
+	e.record(checkpoint)
+	e.persist(checkpoint)
+	e.eventLoop.AddEvent(event.synced)
+}
+
+var (
+	_ modules.CertifiedExecutor = (*Executor)(nil)
+	_ modules.SnapshotProvider  = (*Executor)(nil)
+)
@@ -0,0 +1,128 @@
+package snapshot
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/blockchain"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+)
+
+// testPruner is a block chain that records the views it was pruned below.
+type testPruner struct {
+	modules.BlockChain
+	pruned   []hotstuff.View
+	archived bool
+}
+
+func (p *testPruner) PruneBelow(view hotstuff.View, archive func(key, value []byte) error) error {
+	p.pruned = append(p.pruned, view)
+	p.archived = archive != nil
+	return nil
+}
+
+// testStateSync is a state sync module that never syncs.
+type testStateSync struct{}
+
+func (testStateSync) Sync()         {}
+func (testStateSync) Syncing() bool { return false }
+
+// newTestExecutor builds an executor with the given pruner. The state sync module is optional.
+func newTestExecutor(t *testing.T, state StateMachine, pruner *testPruner, stateSync modules.StateSync, opts ...ExecutorOption) *Executor {
+	t.Helper()
+	executor := NewExecutor(nil, state, nil, opts...)
+	builder := modules.NewBuilder(1, nil)
+	builder.Add(eventloop.New(10), logging.New("test"), pruner, executor)
+	if stateSync != nil {
+		builder.Add(stateSync)
+	}
+	builder.Build()
+	return executor
+}
+
+func execChain(executor *Executor, from, to hotstuff.View) {
+	parent := hotstuff.GetGenesis()
+	for view := from; view <= to; view++ {
+		block := hotstuff.NewBlock(parent.Hash(), hotstuff.NewQuorumCert(testSignature{}, parent.View(), parent.Hash()), hotstuff.Command(fmt.Sprintf("cmd%d", view)), view, 1)
+		executor.ExecCertified(block, hotstuff.NewQuorumCert(testSignature{}, view, block.Hash()))
+		parent = block
+	}
+}
+
+func TestCheckpointInterval(t *testing.T) {
+	store, err := blockchain.NewStateStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	pruner := &testPruner{BlockChain: blockchain.New()}
+	state := newTestState(t)
+	executor := newTestExecutor(t, state, pruner, testStateSync{},
+		WithCheckpointInterval(10),
+		WithStateStore(store),
+		WithRetention(blockchain.RetainDelete, nil),
+	)
+	execChain(executor, 1, 35)
+
+	checkpoints, err := store.GetCheckpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var views []hotstuff.View
+	for _, checkpoint := range checkpoints {
+		views = append(views, checkpoint.View())
+	}
+	if fmt.Sprint(views) != "[1 10 20 30]" {
+		t.Errorf("checkpoints at views %v, want [1 10 20 30]", views)
+	}
+	latest, ok := executor.Checkpoint(hotstuff.Hash{})
+	if !ok || latest.View() != 30 {
+		t.Fatalf("latest checkpoint %v, want view 30", latest)
+	}
+	if fmt.Sprint(pruner.pruned) != fmt.Sprint(views) {
+		t.Errorf("pruned below views %v, want %v", pruner.pruned, views)
+	}
+	if pruner.archived {
+		t.Error("blocks were archived with retention mode delete")
+	}
+
+	// a restarted executor serves the persisted checkpoints.
+	// the test signature is not persisted, so only the block and the state root are compared.
+	restarted := newTestExecutor(t, newTestState(t), &testPruner{BlockChain: blockchain.New()}, nil, WithStateStore(store))
+	checkpoint, ok := restarted.Checkpoint(hotstuff.Hash{})
+	if !ok || checkpoint.BlockHash() != latest.BlockHash() || checkpoint.View() != latest.View() || checkpoint.StateRoot != latest.StateRoot {
+		t.Errorf("restarted executor has latest checkpoint %v, want %v", checkpoint, latest)
+	}
+}
+
+func TestCheckpointRetainAll(t *testing.T) {
+	pruner := &testPruner{BlockChain: blockchain.New()}
+	executor := newTestExecutor(t, nil, pruner, nil, WithCheckpointInterval(5))
+	execChain(executor, 1, 20)
+
+	if len(pruner.pruned) != 0 {
+		t.Errorf("blocks were pruned below views %v with the default retention mode", pruner.pruned)
+	}
+	checkpoint, ok := executor.Checkpoint(hotstuff.Hash{})
+	if !ok || checkpoint.View() != 20 || checkpoint.StateRoot != (hotstuff.Hash{}) {
+		t.Errorf("latest checkpoint %v, want view 20 without state root", checkpoint)
+	}
+}
+
+func TestCheckpointRetainWithoutStateSync(t *testing.T) {
+	pruner := &testPruner{BlockChain: blockchain.New()}
+	executor := newTestExecutor(t, nil, pruner, nil,
+		WithCheckpointInterval(5),
+		WithRetention(blockchain.RetainDelete, nil),
+	)
+	execChain(executor, 1, 20)
+
+	// a lagging replica could neither sync the pruned blocks nor the state of a checkpoint.
+	if len(pruner.pruned) != 0 {
+		t.Errorf("blocks were pruned below views %v without state sync", pruner.pruned)
+	}
+}
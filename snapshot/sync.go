@@ -0,0 +1,301 @@
+package snapshot
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+	"github.com/relab/hotstuff/trie"
+)
+
+const (
+	// nodesPerRequest is the number of trie nodes requested from a replica at a time.
+	nodesPerRequest = 256
+	// maxFailures is the number of failed requests after which a replica is no longer used.
+	maxFailures = 3
+
+	requestTimeout = 10 * time.Second
+	// retryInterval is how long a download worker waits when all missing nodes have been requested by other workers.
+	retryInterval = 10 * time.Millisecond
+)
+
+// stateDownloaded is raised when the state trie of a checkpoint has been downloaded,
+// but not yet installed in the state machine.
+type stateDownloaded struct {
+	synced hotstuff.StateSyncedEvent
+}
+
+// stateSyncFailed is raised when the downloaded state could not be installed.
+type stateSyncFailed struct {
+	checkpoint hotstuff.Checkpoint
+}
+
+// Sync downloads the state of a certified checkpoint from the other replicas.
+// The downloaded state is installed by the Executor, which must be used together with Sync.
+type Sync struct {
+	blockChain    modules.BlockChain
+	configuration modules.Configuration
+	crypto        modules.Crypto
+	eventLoop     *eventloop.EventLoop
+	logger        logging.Logger
+	opts          *modules.Options
+
+	db   *trie.BadgerTrieDB
+	leaf trie.LeafCallback
+
+	mut     sync.Mutex
+	syncing bool
+}
+
+// NewSync returns a new Sync module that stores the downloaded trie in db.
+// The leaf callback returns the roots of the sub-tries referenced by the leaves of the state trie, and may be nil.
+func NewSync(db *trie.BadgerTrieDB, leaf trie.LeafCallback) *Sync {
+	return &Sync{
+		db:   db,
+		leaf: leaf,
+	}
+}
+
+// InitModule initializes the Sync module.
+func (s *Sync) InitModule(mods *modules.Core) {
+	mods.Get(
+		&s.blockChain,
+		&s.configuration,
+		&s.crypto,
+		&s.eventLoop,
+		&s.logger,
+		&s.opts,
+	)
+
+	s.eventLoop.RegisterHandler(hotstuff.StateSyncedEvent{}, func(_ any) {
+		s.mut.Lock()
+		s.syncing = false
+		s.mut.Unlock()
+	})
+	s.eventLoop.RegisterHandler(stateSyncFailed{}, func(event any) {
+		s.logger.Warnf("State sync aborted: the state of the checkpoint at view %d could not be installed",
+			event.(stateSyncFailed).checkpoint.View())
+		s.mut.Lock()
+		s.syncing = false
+		s.mut.Unlock()
+	})
+}
+
+// Syncing returns true while the replica is downloading the state.
+func (s *Sync) Syncing() bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.syncing
+}
+
+// Sync starts downloading the state of the latest checkpoint.
+func (s *Sync) Sync() {
+	fetcher, ok := s.configuration.(modules.SnapshotFetcher)
+	if !ok {
+		return
+	}
+	s.mut.Lock()
+	if s.syncing {
+		s.mut.Unlock()
+		return
+	}
+	s.syncing = true
+	s.mut.Unlock()
+
+	ctx := s.eventLoop.Context()
+	go func() {
+		event, err := s.sync(ctx, fetcher)
+		if err != nil {
+			s.logger.Warnf("State sync failed: %v", err)
+			s.mut.Lock()
+			s.syncing = false
+			s.mut.Unlock()
+			return
+		}
+		s.eventLoop.AddEvent(stateDownloaded{synced: event})
+	}()
+}
+
+// peers returns the IDs of the other replicas in ascending order.
+func (s *Sync) peers() []hotstuff.ID {
+	var peers []hotstuff.ID
+	for id := range s.configuration.Replicas() {
+		if id != s.opts.ID() {
+			peers = append(peers, id)
+		}
+	}
+	slices.Sort(peers)
+	return peers
+}
+
+func (s *Sync) sync(ctx context.Context, fetcher modules.SnapshotFetcher) (hotstuff.StateSyncedEvent, error) {
+	peers := s.peers()
+
+	// the latest checkpoints of the other replicas, with the highest view first.
+	var candidates []hotstuff.Checkpoint
+	for _, peer := range peers {
+		checkpoint, err := s.fetchCheckpoint(ctx, fetcher, peer, hotstuff.Hash{})
+		if err != nil {
+			s.logger.Infof("Failed to fetch the latest checkpoint from replica %d: %v", peer, err)
+			continue
+		}
+		candidates = append(candidates, checkpoint)
+	}
+	slices.SortFunc(candidates, func(a, b hotstuff.Checkpoint) int {
+		return cmp.Compare(b.View(), a.View())
+	})
+
+	tried := make(map[hotstuff.Hash]bool)
+	for _, candidate := range candidates {
+		if tried[candidate.BlockHash()] {
+			continue
+		}
+		tried[candidate.BlockHash()] = true
+
+		checkpoint, sources, err := s.confirm(ctx, fetcher, peers, candidate)
+		if err != nil {
+			s.logger.Infof("Skipping checkpoint at view %d: %v", candidate.View(), err)
+			continue
+		}
+		event, err := s.install(ctx, fetcher, sources, checkpoint)
+		if err != nil {
+			if ctx.Err() != nil {
+				return hotstuff.StateSyncedEvent{}, ctx.Err()
+			}
+			s.logger.Infof("Failed to download checkpoint at view %d: %v", candidate.View(), err)
+			continue
+		}
+		return event, nil
+	}
+	return hotstuff.StateSyncedEvent{}, errors.New("no checkpoint could be downloaded")
+}
+
+// fetchCheckpoint requests a checkpoint from the peer and verifies its certificate.
+func (s *Sync) fetchCheckpoint(ctx context.Context, fetcher modules.SnapshotFetcher, peer hotstuff.ID, hash hotstuff.Hash) (hotstuff.Checkpoint, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	checkpoint, err := fetcher.FetchCheckpoint(ctx, peer, hash)
+	if err != nil {
+		return hotstuff.Checkpoint{}, err
+	}
+	if hash != (hotstuff.Hash{}) && checkpoint.BlockHash() != hash {
+		return hotstuff.Checkpoint{}, fmt.Errorf("got checkpoint of block %.8s, want %.8s", checkpoint.BlockHash(), hash)
+	}
+	if !s.crypto.VerifyQuorumCert(checkpoint.QC) {
+		return hotstuff.Checkpoint{}, fmt.Errorf("invalid certificate for block %.8s", checkpoint.BlockHash())
+	}
+	return checkpoint, nil
+}
+
+// confirm asks the peers for their checkpoint of the same block as the candidate.
+// The quorum certificate only certifies the block, so at least f+1 replicas must agree on the state root,
+// such that at least one correct replica vouches for it.
+// It returns the confirmed checkpoint and the peers that agree on it.
+func (s *Sync) confirm(ctx context.Context, fetcher modules.SnapshotFetcher, peers []hotstuff.ID, candidate hotstuff.Checkpoint) (hotstuff.Checkpoint, []hotstuff.ID, error) {
+	roots := make(map[hotstuff.Hash][]hotstuff.ID)
+	for _, peer := range peers {
+		checkpoint, err := s.fetchCheckpoint(ctx, fetcher, peer, candidate.BlockHash())
+		if err != nil {
+			continue
+		}
+		roots[checkpoint.StateRoot] = append(roots[checkpoint.StateRoot], peer)
+	}
+	needed := hotstuff.NumFaulty(s.configuration.Len()) + 1
+	for root, sources := range roots {
+		if len(sources) >= needed {
+			return hotstuff.Checkpoint{QC: candidate.QC, StateRoot: root}, sources, nil
+		}
+	}
+	return hotstuff.Checkpoint{}, nil, fmt.Errorf("fewer than %d replicas agree on the state root", needed)
+}
+
+// install downloads the block and the state trie of the checkpoint from the sources.
+func (s *Sync) install(ctx context.Context, fetcher modules.SnapshotFetcher, sources []hotstuff.ID, checkpoint hotstuff.Checkpoint) (hotstuff.StateSyncedEvent, error) {
+	block, ok := s.blockChain.Get(checkpoint.BlockHash())
+	if !ok {
+		return hotstuff.StateSyncedEvent{}, fmt.Errorf("failed to fetch block %.8s", checkpoint.BlockHash())
+	}
+	if block.View() != checkpoint.View() {
+		return hotstuff.StateSyncedEvent{}, fmt.Errorf("block %.8s has view %d, but was certified in view %d", block.Hash(), block.View(), checkpoint.View())
+	}
+
+	s.logger.Infof("Downloading state %.8s of the checkpoint at view %d", checkpoint.StateRoot, checkpoint.View())
+
+	ts, err := trie.NewSync(checkpoint.StateRoot, s.db, s.leaf)
+	if err != nil {
+		return hotstuff.StateSyncedEvent{}, err
+	}
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(sources))
+	)
+	for i, peer := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = s.download(ctx, fetcher, peer, ts)
+		}()
+	}
+	wg.Wait()
+
+	if !ts.Done() {
+		return hotstuff.StateSyncedEvent{}, fmt.Errorf("state is incomplete: %w", errors.Join(errs...))
+	}
+
+	s.logger.Infof("Downloaded %d trie nodes", ts.Nodes())
+
+	return hotstuff.StateSyncedEvent{Checkpoint: checkpoint, Block: block}, nil
+}
+
+// download requests missing trie nodes from the peer until the trie is complete,
+// or the peer fails to deliver the nodes too many times.
+func (s *Sync) download(ctx context.Context, fetcher modules.SnapshotFetcher, peer hotstuff.ID, ts *trie.Sync) error {
+	failures := 0
+	for !ts.Done() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		hashes := ts.Missing(nodesPerRequest)
+		if len(hashes) == 0 {
+			// the remaining nodes have been requested by the other workers,
+			// but they may be scheduled again if the requests fail.
+			select {
+			case <-ctx.Done():
+			case <-time.After(retryInterval):
+			}
+			continue
+		}
+
+		reqCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+		nodes, err := fetcher.FetchTrieNodes(reqCtx, peer, hashes)
+		cancel()
+		if err == nil && len(nodes) == 0 {
+			err = errors.New("no nodes were returned")
+		}
+		if err == nil {
+			err = ts.Process(hashes, nodes)
+		} else {
+			_ = ts.Process(hashes, nil)
+		}
+		if err != nil {
+			failures++
+			s.logger.Infof("Failed to fetch trie nodes from replica %d: %v", peer, err)
+			if failures >= maxFailures {
+				return fmt.Errorf("replica %d: %w", peer, err)
+			}
+		}
+	}
+	return nil
+}
+
+var _ modules.StateSync = (*Sync)(nil)
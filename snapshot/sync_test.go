@@ -0,0 +1,259 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/blockchain"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+	"github.com/relab/hotstuff/trie"
+)
+
+// testSignature is a quorum signature that is accepted by testCrypto.
+type testSignature struct{}
+
+func (testSignature) ToBytes() []byte              { return []byte("valid") }
+func (testSignature) Participants() hotstuff.IDSet { return hotstuff.NewIDSet() }
+
+type testCrypto struct {
+	modules.Crypto
+}
+
+func (testCrypto) VerifyQuorumCert(qc hotstuff.QuorumCert) bool {
+	_, ok := qc.Signature().(testSignature)
+	return ok
+}
+
+type testConsensus struct {
+	modules.Consensus
+}
+
+func (testConsensus) CommittedBlock() *hotstuff.Block {
+	return hotstuff.GetGenesis()
+}
+
+// testState stores the commands of the executed blocks in a trie.
+type testState struct {
+	db   *trie.BadgerTrieDB
+	trie *trie.MerklePatriciaTrie
+}
+
+func newTestState(t *testing.T) *testState {
+	t.Helper()
+	db, err := trie.NewBadgerTrieDB(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return &testState{db: db, trie: trie.NewMerklePatriciaTrie()}
+}
+
+func (s *testState) Exec(block *hotstuff.Block) {
+	s.trie.Put([]byte(block.Command()), block.View().ToBytes())
+	s.trie.Commit(s.db)
+}
+
+func (s *testState) StateRoot() hotstuff.Hash {
+	return s.trie.Root()
+}
+
+func (s *testState) Restore(stateRoot hotstuff.Hash) error {
+	root, err := s.db.Get(stateRoot)
+	if err != nil {
+		return err
+	}
+	s.trie = trie.NewMerklePatriciaTrieWithRoot(root)
+	return nil
+}
+
+// testConfig serves the checkpoints and trie nodes of the source executor.
+type testConfig struct {
+	modules.Configuration
+	replicas map[hotstuff.ID]modules.Replica
+	blocks   map[hotstuff.Hash]*hotstuff.Block
+	source   *Executor
+	// replicas that respond with a forged state root.
+	forged hotstuff.IDSet
+}
+
+func (cfg *testConfig) Replicas() map[hotstuff.ID]modules.Replica {
+	return cfg.replicas
+}
+
+func (cfg *testConfig) Len() int {
+	return len(cfg.replicas)
+}
+
+func (cfg *testConfig) Fetch(_ context.Context, hash hotstuff.Hash) (*hotstuff.Block, bool) {
+	block, ok := cfg.blocks[hash]
+	return block, ok
+}
+
+func (cfg *testConfig) FetchCheckpoint(_ context.Context, id hotstuff.ID, hash hotstuff.Hash) (hotstuff.Checkpoint, error) {
+	checkpoint, ok := cfg.source.Checkpoint(hash)
+	if !ok {
+		return hotstuff.Checkpoint{}, errors.New("checkpoint not found")
+	}
+	if cfg.forged.Contains(id) {
+		checkpoint.StateRoot = hotstuff.Hash{1, 2, 3}
+	}
+	return checkpoint, nil
+}
+
+func (cfg *testConfig) FetchTrieNodes(_ context.Context, id hotstuff.ID, hashes []hotstuff.Hash) ([][]byte, error) {
+	if cfg.forged.Contains(id) {
+		return nil, errors.New("forged replica")
+	}
+	return cfg.source.TrieNodes(hashes), nil
+}
+
+// newSource returns a configuration that serves the checkpoints of a source replica that has executed 100 blocks,
+// and the latest checkpoint of the source.
+func newSource(t *testing.T) (*testConfig, hotstuff.Checkpoint) {
+	t.Helper()
+	source := newTestState(t)
+	sourceExecutor := NewExecutor(nil, source, source.db)
+
+	blocks := make(map[hotstuff.Hash]*hotstuff.Block)
+	parent := hotstuff.GetGenesis()
+	for view := hotstuff.View(1); view <= 100; view++ {
+		block := hotstuff.NewBlock(parent.Hash(), hotstuff.NewQuorumCert(testSignature{}, parent.View(), parent.Hash()), hotstuff.Command(fmt.Sprintf("cmd%d", view)), view, 1)
+		blocks[block.Hash()] = block
+		sourceExecutor.ExecCertified(block, hotstuff.NewQuorumCert(testSignature{}, view, block.Hash()))
+		parent = block
+	}
+	latest, _ := sourceExecutor.Checkpoint(hotstuff.Hash{})
+
+	cfg := &testConfig{
+		replicas: map[hotstuff.ID]modules.Replica{1: nil, 2: nil, 3: nil, 4: nil},
+		blocks:   blocks,
+		source:   sourceExecutor,
+		forged:   hotstuff.NewIDSet(),
+	}
+	cfg.forged.Add(4)
+	return cfg, latest
+}
+
+// newTarget builds the modules of the target replica and returns the event loop.
+func newTarget(cfg *testConfig, targetExecutor *Executor, stateSync *Sync) *eventloop.EventLoop {
+	eventLoop := eventloop.New(100)
+	builder := modules.NewBuilder(1, nil)
+	builder.Add(
+		eventLoop,
+		logging.New("test"),
+		blockchain.New(),
+		testConsensus{},
+		testCrypto{},
+		cfg,
+		targetExecutor,
+		stateSync,
+	)
+	builder.Build()
+	return eventLoop
+}
+
+type startSyncEvent struct{}
+
+func TestStateSync(t *testing.T) {
+	cfg, latest := newSource(t)
+
+	target := newTestState(t)
+	targetExecutor := NewExecutor(nil, target, target.db)
+	stateSync := NewSync(target.db, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	eventLoop := newTarget(cfg, targetExecutor, stateSync)
+
+	done := make(chan hotstuff.StateSyncedEvent, 1)
+	eventLoop.RegisterHandler(hotstuff.StateSyncedEvent{}, func(event any) {
+		done <- event.(hotstuff.StateSyncedEvent)
+	})
+	go eventLoop.Run(ctx)
+	// start the sync from the event loop, like the block sync does.
+	eventLoop.RegisterHandler(startSyncEvent{}, func(_ any) { stateSync.Sync() })
+	eventLoop.AddEvent(startSyncEvent{})
+
+	var synced hotstuff.StateSyncedEvent
+	select {
+	case synced = <-done:
+	case <-ctx.Done():
+		t.Fatal("state sync did not complete")
+	}
+
+	if synced.Checkpoint != latest {
+		t.Errorf("synced checkpoint %v, want %v", synced.Checkpoint, latest)
+	}
+	if synced.Block.Hash() != latest.BlockHash() {
+		t.Errorf("synced block %.8s, want %.8s", synced.Block.Hash(), latest.BlockHash())
+	}
+	if target.StateRoot() != latest.StateRoot {
+		t.Errorf("restored state root %.8s, want %.8s", target.StateRoot(), latest.StateRoot)
+	}
+	for view := hotstuff.View(1); view <= 100; view++ {
+		if _, ok := target.trie.Get([]byte(fmt.Sprintf("cmd%d", view))); !ok {
+			t.Errorf("command from view %d is missing from the restored state", view)
+		}
+	}
+	if stateSync.Syncing() {
+		t.Error("still syncing after the state was installed")
+	}
+	if checkpoint, ok := targetExecutor.Checkpoint(hotstuff.Hash{}); !ok || checkpoint != latest {
+		t.Error("the synced checkpoint is not served to other replicas")
+	}
+}
+
+// failingState is a state machine that cannot restore the downloaded state.
+type failingState struct {
+	*testState
+}
+
+func (failingState) Restore(hotstuff.Hash) error {
+	return errors.New("restore failed")
+}
+
+func TestStateSyncRestoreFailure(t *testing.T) {
+	cfg, _ := newSource(t)
+
+	target := newTestState(t)
+	targetExecutor := NewExecutor(nil, failingState{target}, target.db)
+	stateSync := NewSync(target.db, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	eventLoop := newTarget(cfg, targetExecutor, stateSync)
+
+	synced := make(chan struct{}, 1)
+	eventLoop.RegisterHandler(hotstuff.StateSyncedEvent{}, func(_ any) {
+		synced <- struct{}{}
+	})
+	failed := make(chan struct{}, 1)
+	eventLoop.RegisterHandler(stateSyncFailed{}, func(_ any) {
+		failed <- struct{}{}
+	})
+	go eventLoop.Run(ctx)
+	// start the sync from the event loop, like the block sync does.
+	eventLoop.RegisterHandler(startSyncEvent{}, func(_ any) { stateSync.Sync() })
+	eventLoop.AddEvent(startSyncEvent{})
+
+	select {
+	case <-synced:
+		t.Fatal("the consensus module was told to continue from a state that was not installed")
+	case <-failed:
+	case <-ctx.Done():
+		t.Fatal("state sync was not aborted")
+	}
+	// the failure is handled by the sync module before the handler registered by the test.
+	if stateSync.Syncing() {
+		t.Error("still syncing after the sync was aborted")
+	}
+	if _, ok := targetExecutor.Checkpoint(hotstuff.Hash{}); ok {
+		t.Error("the checkpoint of a state that was not installed is served to other replicas")
+	}
+}
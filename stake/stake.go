@@ -0,0 +1,53 @@
+// Package stake implements a static table of voting power for stake-weighted quorums.
+package stake
+
+import (
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/modules"
+)
+
+// Table assigns a fixed voting power to the replicas in the configuration.
+// Replicas that are not in the table, or whose voting power is zero, have a voting power of one,
+// which is the same rule as for hotstuff.Validator.
+type Table struct {
+	configuration modules.Configuration
+
+	power map[hotstuff.ID]uint64
+}
+
+// New returns a new Table with the given voting power for each replica.
+func New(power map[hotstuff.ID]uint64) *Table {
+	t := &Table{power: make(map[hotstuff.ID]uint64, len(power))}
+	for id, p := range power {
+		t.power[id] = p
+	}
+	return t
+}
+
+// InitModule gives the module access to the other modules.
+func (t *Table) InitModule(mods *modules.Core) {
+	mods.Get(&t.configuration)
+}
+
+// Power returns the voting power of the replica.
+// Replicas that are not in the configuration have no voting power.
+func (t *Table) Power(_ hotstuff.View, id hotstuff.ID) uint64 {
+	if _, ok := t.configuration.Replica(id); !ok {
+		return 0
+	}
+	return t.of(id)
+}
+
+// TotalPower returns the total voting power of the replicas in the configuration.
+func (t *Table) TotalPower(_ hotstuff.View) (total uint64) {
+	for id := range t.configuration.Replicas() {
+		total = hotstuff.AddPower(total, t.of(id))
+	}
+	return total
+}
+
+func (t *Table) of(id hotstuff.ID) uint64 {
+	return hotstuff.Validator{ID: id, Power: t.power[id]}.VotingPower()
+}
+
+var _ modules.VotingPower = (*Table)(nil)
@@ -0,0 +1,115 @@
+package stake
+
+import (
+	"math"
+	"testing"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/modules"
+)
+
+type testConfig struct {
+	modules.Configuration
+	replicas map[hotstuff.ID]modules.Replica
+}
+
+func (cfg testConfig) Replicas() map[hotstuff.ID]modules.Replica { return cfg.replicas }
+
+func (cfg testConfig) Replica(id hotstuff.ID) (modules.Replica, bool) {
+	r, ok := cfg.replicas[id]
+	return r, ok
+}
+
+func (cfg testConfig) QuorumSize() int { return hotstuff.QuorumSize(len(cfg.replicas)) }
+
+func newTable(t *testing.T, n int, power map[hotstuff.ID]uint64) (*Table, testConfig) {
+	t.Helper()
+	cfg := testConfig{replicas: make(map[hotstuff.ID]modules.Replica)}
+	for id := hotstuff.ID(1); id <= hotstuff.ID(n); id++ {
+		cfg.replicas[id] = nil
+	}
+	table := New(power)
+	builder := modules.NewBuilder(1, nil)
+	builder.Add(cfg, table)
+	builder.Build()
+	return table, cfg
+}
+
+func idSet(ids ...hotstuff.ID) hotstuff.IDSet {
+	set := hotstuff.NewIDSet()
+	for _, id := range ids {
+		set.Add(id)
+	}
+	return set
+}
+
+func TestTable(t *testing.T) {
+	table, _ := newTable(t, 4, map[hotstuff.ID]uint64{1: 5, 2: 0, 7: 100})
+	for _, test := range []struct {
+		id   hotstuff.ID
+		want uint64
+	}{{1, 5}, {2, 1}, {3, 1}, {4, 1}, {7, 0}} {
+		if got := table.Power(1, test.id); got != test.want {
+			t.Errorf("Power(%d) = %d, want %d", test.id, got, test.want)
+		}
+	}
+	if got := table.TotalPower(1); got != 8 {
+		t.Errorf("TotalPower() = %d, want 8", got)
+	}
+}
+
+func TestHasQuorum(t *testing.T) {
+	// total power 10: a quorum needs more than 20/3, i.e. at least 7.
+	table, cfg := newTable(t, 4, map[hotstuff.ID]uint64{1: 6, 2: 2, 3: 1, 4: 1})
+	for _, test := range []struct {
+		set  hotstuff.IDSet
+		want bool
+	}{
+		{idSet(1), false},
+		{idSet(1, 3), true},
+		{idSet(2, 3, 4), false},
+		{idSet(1, 2, 3, 4), true},
+	} {
+		if got := modules.HasQuorum(cfg, table, 1, test.set); got != test.want {
+			t.Errorf("HasQuorum(%v) = %v, want %v", test.set, got, test.want)
+		}
+	}
+}
+
+func TestHasQuorumEqualPower(t *testing.T) {
+	// with equal voting power, the stake-weighted quorum is the same as QuorumSize.
+	for n := 1; n <= 10; n++ {
+		table, cfg := newTable(t, n, nil)
+		for size := 0; size <= n; size++ {
+			set := hotstuff.NewIDSet()
+			for id := 1; id <= size; id++ {
+				set.Add(hotstuff.ID(id))
+			}
+			want := modules.HasQuorum(cfg, nil, 1, set)
+			if got := modules.HasQuorum(cfg, table, 1, set); got != want {
+				t.Errorf("n=%d, size=%d: HasQuorum() = %v, want %v", n, size, got, want)
+			}
+		}
+	}
+}
+
+func TestHasQuorumLargePower(t *testing.T) {
+	// the voting power is large enough that the quorum condition would overflow with 64 bits.
+	half := uint64(math.MaxUint64 / 2)
+	table, cfg := newTable(t, 4, map[hotstuff.ID]uint64{1: half, 2: half, 3: 1, 4: 1})
+	if got := table.TotalPower(1); got != math.MaxUint64 {
+		t.Errorf("TotalPower() = %d, want %d", got, uint64(math.MaxUint64))
+	}
+	for _, test := range []struct {
+		set  hotstuff.IDSet
+		want bool
+	}{
+		{idSet(1), false},
+		{idSet(1, 3, 4), false},
+		{idSet(1, 2), true},
+	} {
+		if got := modules.HasQuorum(cfg, table, 1, test.set); got != test.want {
+			t.Errorf("HasQuorum(%v) = %v, want %v", test.set, got, test.want)
+		}
+	}
+}
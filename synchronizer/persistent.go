@@ -23,6 +23,7 @@ type PersistentSynchronizer struct {
 	leaderRotation modules.LeaderRotation
 	logger         logging.Logger
 	opts           *modules.Options
+	power          modules.VotingPower
 
 	// Persistent state store
 	stateStore *blockchain.StateStore
@@ -40,6 +41,9 @@ type PersistentSynchronizer struct {
 	duration ViewDuration
 	timer    oneShotTimer
 
+	// the proposal that the leader delays until it knows the highQCs of a quorum (HotStuff-2)
+	wait *leaderWait
+
 	// map of collected timeout messages per view (not persisted - runtime only)
 	timeouts map[hotstuff.View]map[hotstuff.ID]hotstuff.TimeoutMsg
 }
@@ -79,6 +83,7 @@ func (s *PersistentSynchronizer) InitModule(mods *modules.Core) {
 		&s.logger,
 		&s.opts,
 	)
+	mods.TryGet(&s.power)
 
 	s.eventLoop.RegisterHandler(TimeoutEvent{}, func(event any) {
 		timeoutView := event.(TimeoutEvent).View
@@ -92,6 +97,12 @@ func (s *PersistentSynchronizer) InitModule(mods *modules.Core) {
 		s.OnNewView(newViewMsg)
 	})
 
+	s.eventLoop.RegisterHandler(WaitTimeoutEvent{}, func(event any) {
+		if s.wait != nil && s.wait.view == event.(WaitTimeoutEvent).View {
+			s.proposeIfReady(true)
+		}
+	})
+
 	s.eventLoop.RegisterHandler(hotstuff.TimeoutMsg{}, func(event any) {
 		timeoutMsg := event.(hotstuff.TimeoutMsg)
 		s.OnRemoteTimeout(timeoutMsg)
@@ -122,6 +133,13 @@ func (s *PersistentSynchronizer) loadState() error {
 	if err != nil {
 		return fmt.Errorf("failed to load high QC: %w", err)
 	}
+	// A new state store has no high QC, so start from the genesis block
+	if highQC.BlockHash() == (hotstuff.Hash{}) {
+		highQC, err = s.crypto.CreateQuorumCert(hotstuff.GetGenesis(), []hotstuff.PartialCert{})
+		if err != nil {
+			return fmt.Errorf("failed to create quorum cert for genesis block: %w", err)
+		}
+	}
 	s.highQC = highQC
 
 	// Load high TC
@@ -129,6 +147,12 @@ func (s *PersistentSynchronizer) loadState() error {
 	if err != nil {
 		return fmt.Errorf("failed to load high TC: %w", err)
 	}
+	if highTC.View() == 0 {
+		highTC, err = s.crypto.CreateTimeoutCert(hotstuff.View(0), []hotstuff.TimeoutMsg{})
+		if err != nil {
+			return fmt.Errorf("failed to create timeout cert for view 0: %w", err)
+		}
+	}
 	s.highTC = highTC
 
 	s.logger.Infof("Loaded persistent synchronizer state: view=%d, highQC.view=%d, highTC.view=%d",
@@ -229,7 +253,8 @@ func (s *PersistentSynchronizer) OnLocalTimeout() {
 	s.duration.ViewTimeout() // increase the duration of the next view
 	s.logger.Debugf("OnLocalTimeout: %v", view)
 
-	sig, err := s.crypto.Sign(view.ToBytes())
+	signingContext := modules.SigningContext{Kind: modules.SignTimeout, View: view}
+	sig, err := modules.SignWithContext(s.crypto, signingContext, view.ToBytes())
 	if err != nil {
 		s.logger.Warnf("Failed to sign view: %v", err)
 		return
@@ -243,7 +268,15 @@ func (s *PersistentSynchronizer) OnLocalTimeout() {
 
 	if s.opts.ShouldUseAggQC() {
 		// generate a second signature that will become part of the aggregateQC
-		sig, err := s.crypto.Sign(timeoutMsg.ToBytes())
+		sig, err := modules.SignWithContext(s.crypto, signingContext, timeoutMsg.ToBytes())
+		if err != nil {
+			s.logger.Warnf("Failed to sign timeout message: %v", err)
+			return
+		}
+		timeoutMsg.MsgSignature = sig
+	} else if s.opts.ShouldUseHighQCViews() {
+		// sign the view of the highQC, which becomes part of the timeout certificate
+		sig, err := modules.SignWithContext(s.crypto, signingContext, highQCTimeoutBytes(timeoutMsg))
 		if err != nil {
 			s.logger.Warnf("Failed to sign timeout message: %v", err)
 			return
@@ -271,13 +304,24 @@ func (s *PersistentSynchronizer) OnRemoteTimeout(timeout hotstuff.TimeoutMsg) {
 		}
 	}()
 
-	verifier := s.crypto
-	if !verifier.Verify(timeout.ViewSignature, timeout.View.ToBytes()) {
-		return
+	if !timeout.Verified {
+		verifier := s.crypto
+		if !verifier.Verify(timeout.ViewSignature, timeout.View.ToBytes()) {
+			return
+		}
+		if s.opts.ShouldUseHighQCViews() && !verifyHighQCTimeout(verifier, timeout) {
+			return
+		}
 	}
 	s.logger.Debug("OnRemoteTimeout: ", timeout)
 
+	if !verifyTimeoutQC(s.opts, s.crypto, timeout) {
+		s.logger.Infof("OnRemoteTimeout: dropping timeout from %d with an invalid QC", timeout.ID)
+		return
+	}
+
 	s.AdvanceView(timeout.SyncInfo)
+	s.onHighQC(timeout.ID, timeout.View)
 
 	timeouts, ok := s.timeouts[timeout.View]
 	if !ok {
@@ -289,7 +333,7 @@ func (s *PersistentSynchronizer) OnRemoteTimeout(timeout hotstuff.TimeoutMsg) {
 		timeouts[timeout.ID] = timeout
 	}
 
-	if len(timeouts) < s.configuration.QuorumSize() {
+	if !modules.HasQuorum(s.configuration, s.power, timeout.View, timeoutSenders(timeouts)) {
 		return
 	}
 
@@ -325,6 +369,7 @@ func (s *PersistentSynchronizer) OnRemoteTimeout(timeout hotstuff.TimeoutMsg) {
 // OnNewView handles an incoming consensus.NewViewMsg
 func (s *PersistentSynchronizer) OnNewView(newView hotstuff.NewViewMsg) {
 	s.AdvanceView(newView.SyncInfo)
+	s.onHighQC(newView.ID, syncInfoView(newView.SyncInfo))
 }
 
 // AdvanceView attempts to advance to the next view using the given QC with persistence
@@ -411,9 +456,14 @@ func (s *PersistentSynchronizer) AdvanceView(syncInfo hotstuff.SyncInfo) {
 	s.logger.Debugf("advanced to view %d", newView)
 	s.eventLoop.AddEvent(ViewChangeEvent{View: newView, Timeout: timeout})
 
+	s.stopWaiting()
 	leader := s.leaderRotation.GetLeader(newView)
 	if leader == s.opts.ID() {
-		s.consensus.Propose(syncInfo)
+		if timeout && s.opts.ShouldWaitForHighQC() {
+			s.waitForHighQCs(newView, syncInfo)
+		} else {
+			s.consensus.Propose(syncInfo)
+		}
 	} else if replica, ok := s.configuration.Replica(leader); ok {
 		replica.NewView(syncInfo)
 	}
@@ -463,6 +513,56 @@ func (s *PersistentSynchronizer) updateHighTC(tc hotstuff.TimeoutCert) {
 	}
 }
 
+// waitForHighQCs delays the proposal for the view until the leader has learned the highQCs of a quorum of replicas,
+// or until the wait duration has passed. Since the view was entered after a timeout, the highQC of the leader
+// may be lower than the block that some replicas are locked on.
+func (s *PersistentSynchronizer) waitForHighQCs(view hotstuff.View, syncInfo hotstuff.SyncInfo) {
+	// only the highQCs that are received after the view change are counted,
+	// since the leader may have formed the timeout certificate without learning the highQCs of the others.
+	senders := hotstuff.NewIDSet()
+	senders.Add(s.opts.ID())
+	s.wait = &leaderWait{
+		view:     view,
+		syncInfo: syncInfo,
+		senders:  senders,
+		timer: oneShotTimer{time.AfterFunc(waitDuration(s.duration), func() {
+			s.eventLoop.AddEvent(WaitTimeoutEvent{view})
+		})},
+	}
+	s.logger.Debugf("waiting for highQCs in view %d", view)
+	s.proposeIfReady(false)
+}
+
+// onHighQC records that the leader has learned the highQC that the replica had after the view.
+func (s *PersistentSynchronizer) onHighQC(id hotstuff.ID, view hotstuff.View) {
+	if s.wait == nil || view+1 < s.wait.view {
+		return
+	}
+	s.wait.senders.Add(id)
+	s.proposeIfReady(false)
+}
+
+// proposeIfReady proposes in the view that the leader is waiting for,
+// if the leader has learned the highQCs of a quorum of replicas, or if force is true.
+func (s *PersistentSynchronizer) proposeIfReady(force bool) {
+	wait := s.wait
+	if wait == nil || (!force && !modules.HasQuorum(s.configuration, s.power, wait.view, wait.senders)) {
+		return
+	}
+	s.stopWaiting()
+	if wait.view == s.View() {
+		s.consensus.Propose(wait.syncInfo.WithQC(s.HighQC()))
+	}
+}
+
+// stopWaiting cancels the delayed proposal, if any.
+func (s *PersistentSynchronizer) stopWaiting() {
+	if s.wait != nil {
+		s.wait.timer.Stop()
+		s.wait = nil
+	}
+}
+
 // Timer management (same as original implementation)
 
 func (s *PersistentSynchronizer) startTimeoutTimer() {
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/relab/hotstuff/crypto"
 	"github.com/relab/hotstuff/eventloop"
 	"github.com/relab/hotstuff/logging"
 	"github.com/relab/hotstuff/modules"
@@ -24,6 +25,8 @@ type Synchronizer struct {
 	leaderRotation modules.LeaderRotation
 	logger         logging.Logger
 	opts           *modules.Options
+	power          modules.VotingPower
+	pool           modules.VerificationPool // nil if timeouts are verified on the event loop.
 
 	mut         sync.RWMutex // to protect the following
 	currentView hotstuff.View
@@ -38,6 +41,9 @@ type Synchronizer struct {
 	duration ViewDuration
 	timer    oneShotTimer
 
+	// the proposal that the leader delays until it knows the highQCs of a quorum (HotStuff-2)
+	wait *leaderWait
+
 	// map of collected timeout messages per view
 	timeouts map[hotstuff.View]map[hotstuff.ID]hotstuff.TimeoutMsg
 }
@@ -54,6 +60,8 @@ func (s *Synchronizer) InitModule(mods *modules.Core) {
 		&s.logger,
 		&s.opts,
 	)
+	mods.TryGet(&s.power)
+	mods.TryGet(&s.pool)
 
 	s.eventLoop.RegisterHandler(TimeoutEvent{}, func(event any) {
 		timeoutView := event.(TimeoutEvent).View
@@ -67,6 +75,12 @@ func (s *Synchronizer) InitModule(mods *modules.Core) {
 		s.OnNewView(newViewMsg)
 	})
 
+	s.eventLoop.RegisterHandler(WaitTimeoutEvent{}, func(event any) {
+		if s.wait != nil && s.wait.view == event.(WaitTimeoutEvent).View {
+			s.proposeIfReady(true)
+		}
+	})
+
 	s.eventLoop.RegisterHandler(hotstuff.TimeoutMsg{}, func(event any) {
 		timeoutMsg := event.(hotstuff.TimeoutMsg)
 		s.OnRemoteTimeout(timeoutMsg)
@@ -167,7 +181,8 @@ func (s *Synchronizer) OnLocalTimeout() {
 	s.duration.ViewTimeout() // increase the duration of the next view
 	s.logger.Debugf("OnLocalTimeout: %v", view)
 
-	sig, err := s.crypto.Sign(view.ToBytes())
+	signingContext := modules.SigningContext{Kind: modules.SignTimeout, View: view}
+	sig, err := modules.SignWithContext(s.crypto, signingContext, view.ToBytes())
 	if err != nil {
 		s.logger.Warnf("Failed to sign view: %v", err)
 		return
@@ -181,7 +196,15 @@ func (s *Synchronizer) OnLocalTimeout() {
 
 	if s.opts.ShouldUseAggQC() {
 		// generate a second signature that will become part of the aggregateQC
-		sig, err := s.crypto.Sign(timeoutMsg.ToBytes())
+		sig, err := modules.SignWithContext(s.crypto, signingContext, timeoutMsg.ToBytes())
+		if err != nil {
+			s.logger.Warnf("Failed to sign timeout message: %v", err)
+			return
+		}
+		timeoutMsg.MsgSignature = sig
+	} else if s.opts.ShouldUseHighQCViews() {
+		// sign the view of the highQC, which becomes part of the timeout certificate
+		sig, err := modules.SignWithContext(s.crypto, signingContext, highQCTimeoutBytes(timeoutMsg))
 		if err != nil {
 			s.logger.Warnf("Failed to sign timeout message: %v", err)
 			return
@@ -198,6 +221,42 @@ func (s *Synchronizer) OnLocalTimeout() {
 
 // OnRemoteTimeout handles an incoming timeout from a remote replica.
 func (s *Synchronizer) OnRemoteTimeout(timeout hotstuff.TimeoutMsg) {
+	if timeout.Verified {
+		s.onVerifiedTimeout(timeout)
+		return
+	}
+	if s.pool != nil {
+		s.submitTimeout(timeout)
+		return
+	}
+	verifier := s.crypto
+	if !verifier.Verify(timeout.ViewSignature, timeout.View.ToBytes()) {
+		return
+	}
+	if s.opts.ShouldUseHighQCViews() && !verifyHighQCTimeout(verifier, timeout) {
+		return
+	}
+	s.onVerifiedTimeout(timeout)
+}
+
+// submitTimeout verifies the signatures of the timeout on the verification pool,
+// together with the other votes and timeouts that are pending, and adds the timeout
+// back to the event loop if the signatures are valid.
+func (s *Synchronizer) submitTimeout(timeout hotstuff.TimeoutMsg) {
+	signatures, messages, ok := TimeoutSignatures(s.opts, timeout)
+	if !ok {
+		return
+	}
+	crypto.VerifyAll(s.pool, s.crypto, signatures, messages, func(valid bool) {
+		if valid {
+			timeout.Verified = true
+			s.eventLoop.AddEvent(timeout)
+		}
+	})
+}
+
+// onVerifiedTimeout handles a timeout whose signatures have been verified.
+func (s *Synchronizer) onVerifiedTimeout(timeout hotstuff.TimeoutMsg) {
 	currView := s.View()
 
 	defer func() {
@@ -209,13 +268,15 @@ func (s *Synchronizer) OnRemoteTimeout(timeout hotstuff.TimeoutMsg) {
 		}
 	}()
 
-	verifier := s.crypto
-	if !verifier.Verify(timeout.ViewSignature, timeout.View.ToBytes()) {
+	s.logger.Debug("OnRemoteTimeout: ", timeout)
+
+	if !verifyTimeoutQC(s.opts, s.crypto, timeout) {
+		s.logger.Infof("OnRemoteTimeout: dropping timeout from %d with an invalid QC", timeout.ID)
 		return
 	}
-	s.logger.Debug("OnRemoteTimeout: ", timeout)
 
 	s.AdvanceView(timeout.SyncInfo)
+	s.onHighQC(timeout.ID, timeout.View)
 
 	timeouts, ok := s.timeouts[timeout.View]
 	if !ok {
@@ -227,7 +288,7 @@ func (s *Synchronizer) OnRemoteTimeout(timeout hotstuff.TimeoutMsg) {
 		timeouts[timeout.ID] = timeout
 	}
 
-	if len(timeouts) < s.configuration.QuorumSize() {
+	if !modules.HasQuorum(s.configuration, s.power, timeout.View, timeoutSenders(timeouts)) {
 		return
 	}
 
@@ -263,6 +324,7 @@ func (s *Synchronizer) OnRemoteTimeout(timeout hotstuff.TimeoutMsg) {
 // OnNewView handles an incoming consensus.NewViewMsg
 func (s *Synchronizer) OnNewView(newView hotstuff.NewViewMsg) {
 	s.AdvanceView(newView.SyncInfo)
+	s.onHighQC(newView.ID, syncInfoView(newView.SyncInfo))
 }
 
 // AdvanceView attempts to advance to the next view using the given QC.
@@ -340,9 +402,14 @@ func (s *Synchronizer) AdvanceView(syncInfo hotstuff.SyncInfo) {
 	s.logger.Debugf("advanced to view %d", newView)
 	s.eventLoop.AddEvent(ViewChangeEvent{View: newView, Timeout: timeout})
 
+	s.stopWaiting()
 	leader := s.leaderRotation.GetLeader(newView)
 	if leader == s.opts.ID() {
-		s.consensus.Propose(syncInfo)
+		if timeout && s.opts.ShouldWaitForHighQC() {
+			s.waitForHighQCs(newView, syncInfo)
+		} else {
+			s.consensus.Propose(syncInfo)
+		}
 	} else if replica, ok := s.configuration.Replica(leader); ok {
 		replica.NewView(syncInfo)
 	}
@@ -372,6 +439,114 @@ func (s *Synchronizer) updateHighTC(tc hotstuff.TimeoutCert) {
 	}
 }
 
+// waitForHighQCs delays the proposal for the view until the leader has learned the highQCs of a quorum of replicas,
+// or until the wait duration has passed. Since the view was entered after a timeout, the highQC of the leader
+// may be lower than the block that some replicas are locked on.
+func (s *Synchronizer) waitForHighQCs(view hotstuff.View, syncInfo hotstuff.SyncInfo) {
+	// only the highQCs that are received after the view change are counted,
+	// since the leader may have formed the timeout certificate without learning the highQCs of the others.
+	senders := hotstuff.NewIDSet()
+	senders.Add(s.opts.ID())
+	s.wait = &leaderWait{
+		view:     view,
+		syncInfo: syncInfo,
+		senders:  senders,
+		timer: oneShotTimer{time.AfterFunc(waitDuration(s.duration), func() {
+			s.eventLoop.AddEvent(WaitTimeoutEvent{view})
+		})},
+	}
+	s.logger.Debugf("waiting for highQCs in view %d", view)
+	s.proposeIfReady(false)
+}
+
+// onHighQC records that the leader has learned the highQC that the replica had after the view.
+func (s *Synchronizer) onHighQC(id hotstuff.ID, view hotstuff.View) {
+	if s.wait == nil || view+1 < s.wait.view {
+		return
+	}
+	s.wait.senders.Add(id)
+	s.proposeIfReady(false)
+}
+
+// proposeIfReady proposes in the view that the leader is waiting for,
+// if the leader has learned the highQCs of a quorum of replicas, or if force is true.
+func (s *Synchronizer) proposeIfReady(force bool) {
+	wait := s.wait
+	if wait == nil || (!force && !modules.HasQuorum(s.configuration, s.power, wait.view, wait.senders)) {
+		return
+	}
+	s.stopWaiting()
+	if wait.view == s.View() {
+		s.consensus.Propose(wait.syncInfo.WithQC(s.HighQC()))
+	}
+}
+
+// stopWaiting cancels the delayed proposal, if any.
+func (s *Synchronizer) stopWaiting() {
+	if s.wait != nil {
+		s.wait.timer.Stop()
+		s.wait = nil
+	}
+}
+
+// highQCTimeoutBytes returns the message that is signed by the sender of the timeout
+// to include the view of its highQC in the timeout certificate.
+func highQCTimeoutBytes(timeout hotstuff.TimeoutMsg) []byte {
+	qc, _ := timeout.SyncInfo.QC()
+	return hotstuff.TimeoutBytes(timeout.ID, timeout.View, qc.View())
+}
+
+// verifyHighQCTimeout verifies that the sender of the timeout has signed the view of its highQC.
+func verifyHighQCTimeout(verifier modules.Crypto, timeout hotstuff.TimeoutMsg) bool {
+	return highQCTimeoutSigned(timeout) && verifier.Verify(timeout.MsgSignature, highQCTimeoutBytes(timeout))
+}
+
+// verifyTimeoutQC verifies the QC of the timeout, which is included in the timeout certificates and aggregate QCs
+// that are created from the timeout. If the views of the high QCs are signed, the timeout must carry a QC,
+// since the signed view is the view of that QC; otherwise, a replica could sign an inflated high QC view
+// without a QC to back it, and the proposals that are justified by the resulting timeout certificate would be rejected.
+func verifyTimeoutQC(opts *modules.Options, verifier modules.Crypto, timeout hotstuff.TimeoutMsg) bool {
+	qc, ok := timeout.SyncInfo.QC()
+	if !ok {
+		return !opts.ShouldUseHighQCViews()
+	}
+	return verifier.VerifyQuorumCert(qc)
+}
+
+// TimeoutSignatures returns the signatures that must be valid for the timeout to be accepted, and the messages they sign.
+// It returns false if the timeout lacks a signature that is required by the options.
+func TimeoutSignatures(opts *modules.Options, timeout hotstuff.TimeoutMsg) (signatures []hotstuff.QuorumSignature, messages [][]byte, ok bool) {
+	signatures = []hotstuff.QuorumSignature{timeout.ViewSignature}
+	messages = [][]byte{timeout.View.ToBytes()}
+	if opts.ShouldUseHighQCViews() {
+		if !highQCTimeoutSigned(timeout) {
+			return nil, nil, false
+		}
+		signatures = append(signatures, timeout.MsgSignature)
+		messages = append(messages, highQCTimeoutBytes(timeout))
+	}
+	return signatures, messages, true
+}
+
+// highQCTimeoutSigned returns true if the timeout carries a signature of the view of its highQC by its sender.
+// The signature itself is not verified.
+func highQCTimeoutSigned(timeout hotstuff.TimeoutMsg) bool {
+	if timeout.MsgSignature == nil {
+		return false
+	}
+	signers := timeout.MsgSignature.Participants()
+	return signers.Len() == 1 && signers.Contains(timeout.ID)
+}
+
+// timeoutSenders returns the set of replicas that sent the timeout messages.
+func timeoutSenders(timeouts map[hotstuff.ID]hotstuff.TimeoutMsg) hotstuff.IDSet {
+	senders := hotstuff.NewIDSet()
+	for id := range timeouts {
+		senders.Add(id)
+	}
+	return senders
+}
+
 var _ modules.Synchronizer = (*Synchronizer)(nil)
 
 // ViewChangeEvent is sent on the eventloop whenever a view change occurs.
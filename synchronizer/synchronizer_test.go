@@ -68,3 +68,56 @@ func TestAdvanceViewTC(t *testing.T) {
 		t.Errorf("wrong view: expected: %v, got: %v", 2, s.View())
 	}
 }
+
+func TestRemoteTimeoutHighQCViews(t *testing.T) {
+	run := func(t *testing.T, forge bool) {
+		const n = 4
+		ctrl := gomock.NewController(t)
+		builders := testutil.CreateBuilders(t, ctrl, n)
+		s := synchronizer.New(testutil.FixedTimeout(1000))
+		hs := mocks.NewMockConsensus(ctrl)
+		builders[0].Add(s, hs)
+		builders[0].Options().SetShouldUseHighQCViews()
+
+		hl := builders.Build()
+		signers := hl.Signers()
+
+		// the leader of the next view may be any replica.
+		hs.EXPECT().Propose(gomock.Any()).AnyTimes()
+
+		const view = 5
+		qc := hotstuff.NewQuorumCert(nil, 0, hotstuff.GetGenesis().Hash())
+		if forge {
+			// a QC for a later view that is signed by fewer replicas than a quorum.
+			block := hotstuff.NewBlock(hotstuff.GetGenesis().Hash(), qc, "foo", view-1, 2)
+			var blockChain modules.BlockChain
+			hl[0].Get(&blockChain)
+			blockChain.Store(block)
+			qc = testutil.CreateQC(t, block, signers[1:3])
+		}
+
+		for i, signer := range signers[1:] {
+			id := hotstuff.ID(i + 2)
+			timeout := hotstuff.TimeoutMsg{ID: id, View: view, SyncInfo: hotstuff.NewSyncInfo().WithQC(qc)}
+			var err error
+			if timeout.ViewSignature, err = signer.Sign(timeout.View.ToBytes()); err != nil {
+				t.Fatal(err)
+			}
+			if timeout.MsgSignature, err = signer.Sign(hotstuff.TimeoutBytes(id, timeout.View, qc.View())); err != nil {
+				t.Fatal(err)
+			}
+			s.(*synchronizer.Synchronizer).OnRemoteTimeout(timeout)
+		}
+
+		want := hotstuff.View(view + 1)
+		if forge {
+			// the timeouts are dropped, so no timeout certificate is created.
+			want = 1
+		}
+		if s.View() != want {
+			t.Errorf("wrong view: expected: %v, got: %v", want, s.View())
+		}
+	}
+	t.Run("ValidQC", func(t *testing.T) { run(t, false) })
+	t.Run("ForgedQC", func(t *testing.T) { run(t, true) })
+}
@@ -0,0 +1,39 @@
+package synchronizer
+
+import (
+	"time"
+
+	"github.com/relab/hotstuff"
+)
+
+// leaderWait is the state of a leader that waits for the highQCs of a quorum of replicas
+// before proposing in a view that was entered after a timeout.
+type leaderWait struct {
+	view     hotstuff.View
+	syncInfo hotstuff.SyncInfo
+	senders  hotstuff.IDSet
+	timer    oneShotTimer
+}
+
+// waitDuration returns how long a leader waits for the highQCs of the other replicas.
+// Half of the view duration leaves the rest of the view for the proposal to be certified.
+func waitDuration(duration ViewDuration) time.Duration {
+	return duration.Duration() / 2
+}
+
+// syncInfoView returns the highest view certified by the QC or TC in the sync info.
+func syncInfoView(syncInfo hotstuff.SyncInfo) hotstuff.View {
+	var view hotstuff.View
+	if qc, ok := syncInfo.QC(); ok {
+		view = qc.View()
+	}
+	if tc, ok := syncInfo.TC(); ok && tc.View() > view {
+		view = tc.View()
+	}
+	return view
+}
+
+// WaitTimeoutEvent is sent on the eventloop when a leader stops waiting for the highQCs of the other replicas.
+type WaitTimeoutEvent struct {
+	View hotstuff.View
+}
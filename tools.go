@@ -6,5 +6,6 @@ import (
 	_ "cuelang.org/go/cmd/cue"
 	_ "github.com/relab/gorums/cmd/protoc-gen-gorums"
 	_ "go.uber.org/mock/mockgen"
+	_ "google.golang.org/grpc/cmd/protoc-gen-go-grpc"
 	_ "google.golang.org/protobuf/cmd/protoc-gen-go"
 )
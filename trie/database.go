@@ -2,6 +2,7 @@ package trie
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"sync"
 
@@ -241,6 +242,68 @@ func (db *BadgerTrieDB) Delete(hash hotstuff.Hash) error {
 	return nil
 }
 
+// ErrNodeNotFound is returned when a node is not in the database
+var ErrNodeNotFound = errors.New("trie node not found")
+
+// NodeData returns the encoding of the node with the given hash.
+// The hash of the node is the Keccak-256 hash of its encoding.
+func (db *BadgerTrieDB) NodeData(hash hotstuff.Hash) ([]byte, error) {
+	var nodeData []byte
+	err := db.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(hash[:])
+		if err != nil {
+			return err
+		}
+		nodeData, err = item.ValueCopy(nil)
+		return err
+	})
+	if err == badger.ErrKeyNotFound {
+		return nil, ErrNodeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read node: %w", err)
+	}
+	if len(nodeData) < 2 || nodeData[0] != 1 {
+		return nil, fmt.Errorf("invalid node data")
+	}
+	return nodeData[1:], nil
+}
+
+// PutNodeData stores the encoding of a node after checking that it matches the hash.
+func (db *BadgerTrieDB) PutNodeData(hash hotstuff.Hash, data []byte) error {
+	if hashData(data) != hash {
+		return fmt.Errorf("node data does not match hash %s", hash)
+	}
+
+	// Prepend version byte
+	nodeData := make([]byte, 1+len(data))
+	nodeData[0] = 1
+	copy(nodeData[1:], data)
+
+	err := db.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(hash[:], nodeData)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write node: %w", err)
+	}
+
+	db.mu.Lock()
+	db.stats.NodeCount++
+	db.stats.TotalSize += int64(len(nodeData))
+	db.mu.Unlock()
+
+	return nil
+}
+
+// HasNode returns true if the node with the given hash is in the database
+func (db *BadgerTrieDB) HasNode(hash hotstuff.Hash) bool {
+	err := db.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(hash[:])
+		return err
+	})
+	return err == nil
+}
+
 // Close closes the database
 func (db *BadgerTrieDB) Close() error {
 	db.cache.Clear()
@@ -445,6 +508,21 @@ func (n *HashNode) String() string {
 	return fmt.Sprintf("HashNode(%s)", n.hash.String()[:8]+"...")
 }
 
+// resolveNode loads the node from the database if it is a HashNode.
+func resolveNode(node Node) Node {
+	n, ok := node.(*HashNode)
+	if !ok {
+		return node
+	}
+	if n.cached == nil {
+		n.resolve()
+	}
+	if n.cached == nil {
+		return EmptyNodeInstance
+	}
+	return n.cached
+}
+
 func (n *HashNode) resolve() {
 	if n.db != nil {
 		node, err := n.db.Get(n.hash)
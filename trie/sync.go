@@ -0,0 +1,250 @@
+package trie
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/relab/hotstuff"
+	"golang.org/x/crypto/sha3"
+)
+
+// LeafCallback returns the roots of the sub-tries referenced by the value of a leaf,
+// such as the storage trie of an account.
+type LeafCallback func(value []byte) []hotstuff.Hash
+
+// Sync downloads a trie from its root hash.
+// The nodes are requested by hash, and each delivered node is verified against its hash
+// before it is stored, so the nodes can be downloaded from untrusted peers.
+// The children of a node are scheduled once the node has been stored.
+type Sync struct {
+	db   *BadgerTrieDB
+	leaf LeafCallback
+
+	mut       sync.Mutex
+	pending   []hotstuff.Hash
+	requested map[hotstuff.Hash]struct{}
+	seen      map[hotstuff.Hash]struct{}
+	nodes     int
+}
+
+// NewSync returns a Sync that downloads the trie with the given root into the database.
+// Nodes that are already in the database are not downloaded again.
+func NewSync(root hotstuff.Hash, db *BadgerTrieDB, leaf LeafCallback) (*Sync, error) {
+	s := &Sync{
+		db:        db,
+		leaf:      leaf,
+		requested: make(map[hotstuff.Hash]struct{}),
+		seen:      make(map[hotstuff.Hash]struct{}),
+	}
+	if err := s.schedule(root); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Missing returns up to max hashes of nodes that have not been requested yet,
+// and marks them as requested.
+func (s *Sync) Missing(max int) []hotstuff.Hash {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	n := min(max, len(s.pending))
+	hashes := make([]hotstuff.Hash, n)
+	copy(hashes, s.pending[len(s.pending)-n:])
+	s.pending = s.pending[:len(s.pending)-n]
+	for _, hash := range hashes {
+		s.requested[hash] = struct{}{}
+	}
+	return hashes
+}
+
+// Process stores the nodes that were delivered for the requested hashes.
+// Requested nodes that were not delivered are scheduled again.
+// An error is returned if a delivered node does not match any of the requested hashes.
+func (s *Sync) Process(requested []hotstuff.Hash, data [][]byte) error {
+	want := make(map[hotstuff.Hash]struct{}, len(requested))
+	for _, hash := range requested {
+		want[hash] = struct{}{}
+	}
+
+	var err error
+	for _, node := range data {
+		hash := hashData(node)
+		if _, ok := want[hash]; !ok {
+			err = fmt.Errorf("unexpected node %.8s", hash)
+			break
+		}
+		if err = s.store(hash, node); err != nil {
+			break
+		}
+		delete(want, hash)
+	}
+
+	s.mut.Lock()
+	for hash := range want {
+		delete(s.requested, hash)
+		s.pending = append(s.pending, hash)
+	}
+	s.mut.Unlock()
+	return err
+}
+
+// Done returns true when all nodes of the trie are in the database.
+func (s *Sync) Done() bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return len(s.pending) == 0 && len(s.requested) == 0
+}
+
+// Nodes returns the number of nodes that have been downloaded.
+func (s *Sync) Nodes() int {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.nodes
+}
+
+// store verifies and stores a node, and schedules its children.
+func (s *Sync) store(hash hotstuff.Hash, data []byte) error {
+	children, value, err := decodeRefs(data)
+	if err != nil {
+		return fmt.Errorf("node %.8s: %w", hash, err)
+	}
+	if err := s.db.PutNodeData(hash, data); err != nil {
+		return err
+	}
+
+	if value != nil && s.leaf != nil {
+		children = append(children, s.leaf(value)...)
+	}
+	for _, child := range children {
+		if err := s.schedule(child); err != nil {
+			return err
+		}
+	}
+
+	// the node is only marked as delivered once its children have been scheduled,
+	// such that Done does not return true while the children are being scheduled.
+	s.mut.Lock()
+	delete(s.requested, hash)
+	s.nodes++
+	s.mut.Unlock()
+	return nil
+}
+
+// schedule adds the hash to the pending nodes if the node is not in the database.
+// If the node is already in the database, its children are scheduled instead.
+func (s *Sync) schedule(hash hotstuff.Hash) error {
+	if hash == (hotstuff.Hash{}) {
+		return nil
+	}
+	s.mut.Lock()
+	if _, ok := s.seen[hash]; ok {
+		s.mut.Unlock()
+		return nil
+	}
+	s.seen[hash] = struct{}{}
+	s.mut.Unlock()
+
+	data, err := s.db.NodeData(hash)
+	if err == ErrNodeNotFound {
+		s.mut.Lock()
+		s.pending = append(s.pending, hash)
+		s.mut.Unlock()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// the node is stored locally, but some of its descendants may be missing.
+	children, value, err := decodeRefs(data)
+	if err != nil {
+		return fmt.Errorf("node %.8s: %w", hash, err)
+	}
+	if value != nil && s.leaf != nil {
+		children = append(children, s.leaf(value)...)
+	}
+	for _, child := range children {
+		if err := s.schedule(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeRefs returns the hashes of the children of an encoded node and the value stored in it.
+// Unlike decodeNode, it does not resolve the children.
+func decodeRefs(data []byte) (children []hotstuff.Hash, value []byte, err error) {
+	if len(data) < 1 {
+		return nil, nil, fmt.Errorf("invalid node data: too short")
+	}
+	body := data[1:]
+
+	switch NodeType(data[0]) {
+	case EmptyNode:
+		return nil, nil, nil
+
+	case LeafNode:
+		if len(body) < 1 || len(body) < 1+int(body[0])+1 {
+			return nil, nil, fmt.Errorf("invalid leaf node data")
+		}
+		offset := 1 + int(body[0])
+		valueLen := int(body[offset])
+		if valueLen == 255 {
+			if len(body) < offset+3 {
+				return nil, nil, fmt.Errorf("invalid leaf node large value length")
+			}
+			valueLen = int(binary.BigEndian.Uint16(body[offset+1 : offset+3]))
+			offset += 3
+		} else {
+			offset++
+		}
+		if len(body) < offset+valueLen {
+			return nil, nil, fmt.Errorf("invalid leaf node value")
+		}
+		return nil, body[offset : offset+valueLen], nil
+
+	case ExtensionNode:
+		if len(body) < 1 || len(body) < 1+int(body[0])+32 {
+			return nil, nil, fmt.Errorf("invalid extension node data")
+		}
+		offset := 1 + int(body[0])
+		var child hotstuff.Hash
+		copy(child[:], body[offset:offset+32])
+		return []hotstuff.Hash{child}, nil, nil
+
+	case BranchNode:
+		if len(body) < 16*32+1 {
+			return nil, nil, fmt.Errorf("invalid branch node data")
+		}
+		for i := 0; i < 16; i++ {
+			var child hotstuff.Hash
+			copy(child[:], body[i*32:(i+1)*32])
+			if child != (hotstuff.Hash{}) {
+				children = append(children, child)
+			}
+		}
+		valueLen := int(body[16*32])
+		if valueLen == 0 {
+			return children, nil, nil
+		}
+		if len(body) < 16*32+1+valueLen {
+			return nil, nil, fmt.Errorf("invalid branch node value")
+		}
+		return children, body[16*32+1 : 16*32+1+valueLen], nil
+
+	default:
+		return nil, nil, fmt.Errorf("unknown node type: %d", data[0])
+	}
+}
+
+// hashData returns the Keccak-256 hash of an encoded node.
+func hashData(data []byte) hotstuff.Hash {
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(data)
+
+	var hash hotstuff.Hash
+	copy(hash[:], hasher.Sum(nil))
+	return hash
+}
@@ -0,0 +1,141 @@
+package trie
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/relab/hotstuff"
+)
+
+func newTestDB(t *testing.T) *BadgerTrieDB {
+	t.Helper()
+	db, err := NewBadgerTrieDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestTrieSync(t *testing.T) {
+	source := newTestDB(t)
+	trie := NewMerklePatriciaTrie()
+	for i := 0; i < 500; i++ {
+		if err := trie.Put([]byte(fmt.Sprintf("key%d", i)), []byte(fmt.Sprintf("value%d", i))); err != nil {
+			t.Fatalf("Failed to put key: %v", err)
+		}
+	}
+	if err := trie.Commit(source); err != nil {
+		t.Fatalf("Failed to commit trie: %v", err)
+	}
+	root := trie.Root()
+
+	target := newTestDB(t)
+	sync, err := NewSync(root, target, nil)
+	if err != nil {
+		t.Fatalf("Failed to create sync: %v", err)
+	}
+	for !sync.Done() {
+		hashes := sync.Missing(64)
+		if len(hashes) == 0 {
+			t.Fatal("no missing nodes, but the sync is not done")
+		}
+		var nodes [][]byte
+		for _, hash := range hashes {
+			data, err := source.NodeData(hash)
+			if err != nil {
+				t.Fatalf("Node %.8s not found in source: %v", hash, err)
+			}
+			nodes = append(nodes, data)
+		}
+		if err := sync.Process(hashes, nodes); err != nil {
+			t.Fatalf("Failed to process nodes: %v", err)
+		}
+	}
+
+	rootNode, err := target.Get(root)
+	if err != nil {
+		t.Fatalf("Failed to load root: %v", err)
+	}
+	synced := NewMerklePatriciaTrieWithRoot(rootNode)
+	if synced.Root() != root {
+		t.Errorf("Synced trie has root %s, want %s", synced.Root(), root)
+	}
+	for i := 0; i < 500; i++ {
+		value, found := synced.Get([]byte(fmt.Sprintf("key%d", i)))
+		if !found || string(value) != fmt.Sprintf("value%d", i) {
+			t.Errorf("key%d: got %q, want %q", i, value, fmt.Sprintf("value%d", i))
+		}
+	}
+	if sync.Nodes() != int(source.Stats().NodeCount) {
+		t.Errorf("Downloaded %d nodes, want %d", sync.Nodes(), source.Stats().NodeCount)
+	}
+}
+
+func TestTrieSyncRejectsForgedNodes(t *testing.T) {
+	source := newTestDB(t)
+	trie := NewMerklePatriciaTrie()
+	for i := 0; i < 10; i++ {
+		trie.Put([]byte(fmt.Sprintf("key%d", i)), []byte("value"))
+	}
+	trie.Commit(source)
+
+	sync, err := NewSync(trie.Root(), newTestDB(t), nil)
+	if err != nil {
+		t.Fatalf("Failed to create sync: %v", err)
+	}
+	hashes := sync.Missing(1)
+	forged := NewLeafNode([]byte{1, 2, 3}, []byte("forged")).Encode()
+	if err := sync.Process(hashes, [][]byte{forged}); err == nil {
+		t.Error("Expected error for forged node")
+	}
+
+	// the forged node was not accepted, so the root must be requested again.
+	if again := sync.Missing(1); len(again) != 1 || again[0] != hashes[0] {
+		t.Errorf("Root was not scheduled again: %v", again)
+	}
+	if sync.Done() {
+		t.Error("Sync should not be done")
+	}
+}
+
+func TestTrieSyncSubTries(t *testing.T) {
+	source := newTestDB(t)
+	storage := NewMerklePatriciaTrie()
+	storage.Put([]byte("slot"), []byte("data"))
+	storage.Commit(source)
+	storageRoot := storage.Root()
+
+	state := NewMerklePatriciaTrie()
+	state.Put([]byte("account"), storageRoot[:])
+	state.Commit(source)
+
+	target := newTestDB(t)
+	// only the values of the state trie are roots of sub-tries.
+	leaf := func(value []byte) []hotstuff.Hash {
+		if len(value) != len(hotstuff.Hash{}) {
+			return nil
+		}
+		var root hotstuff.Hash
+		copy(root[:], value)
+		return []hotstuff.Hash{root}
+	}
+	sync, err := NewSync(state.Root(), target, leaf)
+	if err != nil {
+		t.Fatalf("Failed to create sync: %v", err)
+	}
+	for !sync.Done() {
+		hashes := sync.Missing(16)
+		var nodes [][]byte
+		for _, hash := range hashes {
+			data, _ := source.NodeData(hash)
+			nodes = append(nodes, data)
+		}
+		if err := sync.Process(hashes, nodes); err != nil {
+			t.Fatalf("Failed to process nodes: %v", err)
+		}
+	}
+	if !target.HasNode(storageRoot) {
+		t.Error("Storage trie was not synced")
+	}
+}
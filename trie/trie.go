@@ -57,6 +57,7 @@ func (t *MerklePatriciaTrie) get(node Node, key []byte) ([]byte, bool) {
 		return nil, false
 	}
 	
+	node = resolveNode(node)
 	switch n := node.(type) {
 	case *LeafNodeStruct:
 		if nibblesEqual(n.Key, key) {
@@ -129,6 +130,7 @@ func (t *MerklePatriciaTrie) put(node Node, key []byte, value []byte) (Node, err
 		return NewLeafNode(key, value), nil
 	}
 	
+	node = resolveNode(node)
 	switch n := node.(type) {
 	case *LeafNodeStruct:
 		return t.putIntoLeaf(n, key, value)
@@ -345,6 +347,7 @@ func (t *MerklePatriciaTrie) delete(node Node, key []byte) (Node, error) {
 		return EmptyNodeInstance, nil
 	}
 	
+	node = resolveNode(node)
 	switch n := node.(type) {
 	case *LeafNodeStruct:
 		if nibblesEqual(n.Key, key) {
@@ -427,6 +430,7 @@ func (t *MerklePatriciaTrie) prove(node Node, key []byte, proof *[][]byte) error
 	// Add current node to proof
 	*proof = append(*proof, node.Encode())
 	
+	node = resolveNode(node)
 	switch n := node.(type) {
 	case *LeafNodeStruct:
 		// Proof ends at leaf
@@ -469,6 +473,34 @@ func VerifyProof(rootHash hotstuff.Hash, key []byte, value []byte, proof [][]byt
 	return true // Simplified for now
 }
 
+// Commit stores the nodes of the trie in the database.
+// Nodes that were loaded from the database are not stored again.
+func (t *MerklePatriciaTrie) Commit(db Database) error {
+	return t.commitNode(db, t.root)
+}
+
+// commitNode recursively stores a node and its children
+func (t *MerklePatriciaTrie) commitNode(db Database, node Node) error {
+	if node == nil {
+		return nil
+	}
+	switch n := node.(type) {
+	case *HashNode, *EmptyNodeStruct:
+		return nil
+	case *ExtensionNodeStruct:
+		if err := t.commitNode(db, n.Child); err != nil {
+			return err
+		}
+	case *BranchNodeStruct:
+		for _, child := range n.Children {
+			if err := t.commitNode(db, child); err != nil {
+				return err
+			}
+		}
+	}
+	return db.Put(node.Hash(), node)
+}
+
 // Copy creates a deep copy of the trie
 func (t *MerklePatriciaTrie) Copy() *MerklePatriciaTrie {
 	return &MerklePatriciaTrie{
@@ -484,6 +516,10 @@ func (t *MerklePatriciaTrie) copyNode(node Node) Node {
 	}
 	
 	switch n := node.(type) {
+	case *HashNode:
+		// stored nodes are never modified, so they can be shared
+		return n
+
 	case *LeafNodeStruct:
 		key := make([]byte, len(n.Key))
 		copy(key, n.Key)
@@ -551,6 +587,7 @@ func (t *MerklePatriciaTrie) collectStats(node Node, stats *TrieStats, depth int
 		stats.MaxDepth = depth
 	}
 	
+	node = resolveNode(node)
 	switch n := node.(type) {
 	case *LeafNodeStruct:
 		stats.LeafCount++
@@ -0,0 +1,75 @@
+package twins_test
+
+import (
+	"strings"
+	"testing"
+
+	_ "github.com/relab/hotstuff/consensus/chainedhotstuff"
+	_ "github.com/relab/hotstuff/consensus/fasthotstuff"
+	_ "github.com/relab/hotstuff/consensus/hotstuff2"
+	_ "github.com/relab/hotstuff/consensus/jolteon"
+	_ "github.com/relab/hotstuff/consensus/simplehotstuff"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/twins"
+)
+
+// TestHotStuff2Timeout checks that the leader of the view after the isolated leader,
+// which must wait for the highQCs of the other replicas before proposing, keeps the replicas safe.
+func TestHotStuff2Timeout(t *testing.T) {
+	src, err := twins.FromJSON(strings.NewReader(jolteonTimeoutScenario))
+	if err != nil {
+		t.Fatalf("failed to read JSON: %v", err)
+	}
+	scenario, err := src.NextScenario()
+	if err != nil {
+		t.Fatalf("failed to get scenario: %v", err)
+	}
+	settings := src.Settings()
+
+	res, err := twins.ExecuteScenario(scenario, settings.NumNodes, settings.NumTwins, 100, "hotstuff2")
+	if err != nil {
+		t.Fatalf("failed to execute scenario: %v", err)
+	}
+	if !res.Safe {
+		t.Errorf("expected scenario to be safe (%d commits)", res.Commits)
+	}
+}
+
+// TestHotStuff2Twins executes the same generated scenarios with HotStuff-2 and the other implementations,
+// and checks that all of them are safe.
+func TestHotStuff2Twins(t *testing.T) {
+	const (
+		numNodes = 4
+		numTwins = 1
+	)
+	implementations := []string{"hotstuff2", "chainedhotstuff", "fasthotstuff", "jolteon", "simplehotstuff"}
+
+	g := twins.NewGenerator(logging.New(""), twins.Settings{
+		NumNodes:   numNodes,
+		NumTwins:   numTwins,
+		Partitions: 2,
+		Views:      8,
+	})
+	g.Shuffle(2)
+
+	commits := make(map[string]int)
+	for i := 0; i < 20; i++ {
+		s, err := g.NextScenario()
+		if err != nil {
+			break
+		}
+		for _, impl := range implementations {
+			result, err := twins.ExecuteScenario(s, numNodes, numTwins, 100, impl)
+			if err != nil {
+				t.Fatalf("%s: %v", impl, err)
+			}
+			if !result.Safe {
+				t.Errorf("%s: scenario not safe: %v", impl, s)
+			}
+			commits[impl] += result.Commits
+		}
+	}
+	for _, impl := range implementations {
+		t.Logf("%s: %d commits", impl, commits[impl])
+	}
+}
@@ -0,0 +1,122 @@
+package twins_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/relab/hotstuff"
+	_ "github.com/relab/hotstuff/consensus/jolteon"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/twins"
+)
+
+// jolteonTimeoutScenario isolates the leader of view 3, such that the other replicas time out.
+// The leader of view 4 must then justify its proposal with the timeout certificate of view 3,
+// since its QC is not from the previous view.
+const jolteonTimeoutScenario = `
+{
+	"num_nodes": 4,
+	"num_twins": 0,
+	"partitions": 2,
+	"views": 20,
+	"scenarios": [
+		[
+			{ "leader": 1, "partitions": [ [1, 2, 3, 4], [] ] },
+			{ "leader": 1, "partitions": [ [1, 2, 3, 4], [] ] },
+			{ "leader": 2, "partitions": [ [1, 3, 4], [2] ] },
+			{ "leader": 3, "partitions": [ [1, 2, 3, 4], [] ] },
+			{ "leader": 4, "partitions": [ [1, 2, 3, 4], [] ] },
+			{ "leader": 1, "partitions": [ [1, 2, 3, 4], [] ] },
+			{ "leader": 2, "partitions": [ [1, 2, 3, 4], [] ] },
+			{ "leader": 3, "partitions": [ [1, 2, 3, 4], [] ] },
+			{ "leader": 4, "partitions": [ [1, 2, 3, 4], [] ] },
+			{ "leader": 1, "partitions": [ [1, 2, 3, 4], [] ] },
+			{ "leader": 3, "partitions": [ [1, 2, 3, 4], [] ] },
+			{ "leader": 4, "partitions": [ [1, 2, 3, 4], [] ] },
+			{ "leader": 1, "partitions": [ [1, 2, 3, 4], [] ] },
+			{ "leader": 2, "partitions": [ [1, 2, 3, 4], [] ] },
+			{ "leader": 3, "partitions": [ [1, 2, 3, 4], [] ] },
+			{ "leader": 4, "partitions": [ [1, 2, 3, 4], [] ] },
+			{ "leader": 1, "partitions": [ [1, 2, 3, 4], [] ] },
+			{ "leader": 2, "partitions": [ [1, 2, 3, 4], [] ] },
+			{ "leader": 3, "partitions": [ [1, 2, 3, 4], [] ] },
+			{ "leader": 4, "partitions": [ [1, 2, 3, 4], [] ] }
+		]
+	]
+}
+`
+
+func TestJolteonTimeout(t *testing.T) {
+	src, err := twins.FromJSON(strings.NewReader(jolteonTimeoutScenario))
+	if err != nil {
+		t.Fatalf("failed to read JSON: %v", err)
+	}
+	scenario, err := src.NextScenario()
+	if err != nil {
+		t.Fatalf("failed to get scenario: %v", err)
+	}
+	settings := src.Settings()
+
+	res, err := twins.ExecuteScenario(scenario, settings.NumNodes, settings.NumTwins, 100, "jolteon")
+	if err != nil {
+		t.Fatalf("failed to execute scenario: %v", err)
+	}
+	if !res.Safe {
+		t.Errorf("expected scenario to be safe (%d commits)", res.Commits)
+	}
+
+	// a block whose QC is not from the previous view must be committed, since it is justified by a TC,
+	// and the replicas must keep committing blocks after it. Depending on the timing, the first proposal
+	// after view 3 may also time out, so the block may be from view 4 or a later view.
+	var tcView hotstuff.View
+	later := false
+	for _, blocks := range res.NodeCommits {
+		for _, block := range blocks {
+			if block.View() > 3 && block.QuorumCert().View() < block.View()-1 && (tcView == 0 || block.View() < tcView) {
+				tcView = block.View()
+			}
+		}
+	}
+	for _, blocks := range res.NodeCommits {
+		for _, block := range blocks {
+			if tcView > 0 && block.View() > tcView {
+				later = true
+			}
+		}
+	}
+	if tcView == 0 {
+		t.Error("expected a block that is justified by a timeout certificate to be committed")
+	}
+	if !later {
+		t.Error("expected blocks after the timeout certificate to be committed")
+	}
+}
+
+func TestJolteonTwins(t *testing.T) {
+	const (
+		numNodes = 4
+		numTwins = 1
+	)
+
+	g := twins.NewGenerator(logging.New(""), twins.Settings{
+		NumNodes:   numNodes,
+		NumTwins:   numTwins,
+		Partitions: 2,
+		Views:      8,
+	})
+	g.Shuffle(1)
+
+	for i := 0; i < 50; i++ {
+		s, err := g.NextScenario()
+		if err != nil {
+			break
+		}
+		result, err := twins.ExecuteScenario(s, numNodes, numTwins, 100, "jolteon")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Safe {
+			t.Errorf("Scenario not safe: %v", s)
+		}
+	}
+}
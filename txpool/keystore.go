@@ -0,0 +1,99 @@
+package txpool
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/relab/hotstuff/crypto/keystore"
+)
+
+// keyStoreVersion is the version of the Web3 Secret Storage Definition that key files are encoded with.
+const keyStoreVersion = 3
+
+// encryptedKeyJSON is an account key in the format of a Web3 Secret Storage file.
+type encryptedKeyJSON struct {
+	Address string              `json:"address,omitempty"`
+	Crypto  keystore.CryptoJSON `json:"crypto"`
+	ID      string              `json:"id"`
+	Version int                 `json:"version"`
+}
+
+// EncryptKey encrypts an account key with the passphrase, as a Web3 Secret Storage (version 3) JSON file
+// that can also be imported by Ethereum clients.
+// The scrypt parameters determine the cost of decrypting the key, see keystore.StandardScryptN.
+func EncryptKey(key *ecdsa.PrivateKey, passphrase []byte, scryptN, scryptP int) ([]byte, error) {
+	secret := make([]byte, 32)
+	key.D.FillBytes(secret)
+	c, err := keystore.Encrypt(secret, passphrase, scryptN, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	address := AddressFromPublicKey(&key.PublicKey)
+	return json.MarshalIndent(encryptedKeyJSON{
+		Address: hex.EncodeToString(address[:]),
+		Crypto:  *c,
+		ID:      uuid.NewString(),
+		Version: keyStoreVersion,
+	}, "", "  ")
+}
+
+// DecryptKey decrypts an account key from a Web3 Secret Storage (version 3) JSON file.
+// It returns keystore.ErrDecrypt if the passphrase is wrong.
+func DecryptKey(keyJSON, passphrase []byte) (*ecdsa.PrivateKey, error) {
+	var k encryptedKeyJSON
+	if err := json.Unmarshal(keyJSON, &k); err != nil {
+		return nil, fmt.Errorf("invalid key file: %w", err)
+	}
+	if k.Version != keyStoreVersion {
+		return nil, fmt.Errorf("unsupported key file version %d", k.Version)
+	}
+	secret, err := keystore.Decrypt(&k.Crypto, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	key := PrivateKeyFromBytes(secret)
+	if k.Address != "" {
+		address := AddressFromPublicKey(&key.PublicKey)
+		if !strings.EqualFold(strings.TrimPrefix(k.Address, "0x"), hex.EncodeToString(address[:])) {
+			return nil, fmt.Errorf("key file address %s does not match the decrypted key", k.Address)
+		}
+	}
+	return key, nil
+}
+
+// PrivateKeyFromBytes returns the account key with the given 32-byte secret.
+func PrivateKeyFromBytes(secret []byte) *ecdsa.PrivateKey {
+	key := new(ecdsa.PrivateKey)
+	key.D = new(big.Int).SetBytes(secret)
+	key.PublicKey.Curve = GetCurve()
+	key.PublicKey.X, key.PublicKey.Y = key.PublicKey.Curve.ScalarBaseMult(secret)
+	return key
+}
+
+// WriteKeyFile writes an account key that is encrypted with the passphrase to the specified file.
+func WriteKeyFile(key *ecdsa.PrivateKey, filePath string, passphrase []byte, scryptN, scryptP int) error {
+	b, err := EncryptKey(key, passphrase, scryptN, scryptP)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, b, 0o600)
+}
+
+// ReadKeyFile reads an encrypted account key from the specified file.
+func ReadKeyFile(filePath string, passphrase keystore.Passphrase) (*ecdsa.PrivateKey, error) {
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	pass, err := passphrase()
+	if err != nil {
+		return nil, err
+	}
+	return DecryptKey(b, pass)
+}
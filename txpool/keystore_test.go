@@ -0,0 +1,65 @@
+package txpool
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/relab/hotstuff/crypto/keystore"
+)
+
+// testVector is the scrypt test vector of the Web3 Secret Storage Definition.
+const testVector = `{
+	"crypto": {
+		"cipher": "aes-128-ctr",
+		"cipherparams": {"iv": "83dbcc02d8ccb40e466191a123791e0e"},
+		"ciphertext": "d172bf743a674da9cdad04534d56926ef8358534d458fffccd4e6ad2fbde479c",
+		"kdf": "scrypt",
+		"kdfparams": {"dklen": 32, "n": 262144, "r": 1, "p": 8, "salt": "ab0c7876052600dd703518d6fc3fe8984592145b591fc8fb5c6d43190334ba19"},
+		"mac": "2103ac29920d71da29f15d75b4a16dbe95cfd7ff8faea1056c33131d846e3097"
+	},
+	"id": "3198bc9c-6672-5ab3-d995-4942343ae5b6",
+	"version": 3
+}`
+
+func TestDecryptKeyTestVector(t *testing.T) {
+	key, err := DecryptKey([]byte(testVector), []byte("testpassword"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d"
+	if got := hex.EncodeToString(key.D.Bytes()); got != want {
+		t.Errorf("got key %s, want %s", got, want)
+	}
+}
+
+func TestEncryptKey(t *testing.T) {
+	key, _, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyJSON, err := EncryptKey(key, []byte("secret"), keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecryptKey(keyJSON, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.D.Cmp(key.D) != 0 || AddressFromPublicKey(&got.PublicKey) != AddressFromPublicKey(&key.PublicKey) {
+		t.Error("decrypted key does not match the encrypted key")
+	}
+	if _, err := DecryptKey(keyJSON, []byte("wrong")); !errors.Is(err, keystore.ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt with the wrong passphrase, got %v", err)
+	}
+}
+
+func TestDecryptKeyScryptLimits(t *testing.T) {
+	for _, params := range []string{`"n": 1073741824, "r": 8, "p": 1`, `"n": 262144, "r": 8, "p": 64`, `"n": 3, "r": 8, "p": 1`} {
+		keyJSON := strings.Replace(testVector, `"n": 262144, "r": 1, "p": 8`, params, 1)
+		if _, err := DecryptKey([]byte(keyJSON), []byte("testpassword")); err == nil || errors.Is(err, keystore.ErrDecrypt) {
+			t.Errorf("expected an error for the scrypt parameters %s, got %v", params, err)
+		}
+	}
+}
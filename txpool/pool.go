@@ -264,6 +264,23 @@ func (pool *TxPool) Pending() map[Address][]*Transaction {
 	return pending
 }
 
+// Content returns the pending and queued transactions of the pool, grouped by sender
+// and sorted by nonce
+func (pool *TxPool) Content() (pending, queued map[Address][]*Transaction) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	pending = make(map[Address][]*Transaction)
+	for addr, list := range pool.pending {
+		pending[addr] = list.Flatten()
+	}
+	queued = make(map[Address][]*Transaction)
+	for addr, list := range pool.queue {
+		queued[addr] = list.Flatten()
+	}
+	return pending, queued
+}
+
 // NextNonce returns the next nonce for the given address
 func (pool *TxPool) NextNonce(addr Address) uint64 {
 	pool.mu.RLock()
@@ -157,6 +157,34 @@ func TestTxPool_PendingTransactions(t *testing.T) {
 	}
 }
 
+func TestTxPool_Content(t *testing.T) {
+	config := DefaultConfig()
+	signer := NewEIP155Signer(big.NewInt(1))
+	pool := NewTxPool(config, signer)
+	defer pool.Close()
+
+	tx1 := createSignedTestTx(t, 0, 1000000000, 21000)
+	tx2 := createSignedTestTx(t, 1, 2000000000, 21000)
+
+	pool.AddLocal(tx1)
+	pool.AddLocal(tx2)
+
+	pending, queued := pool.Content()
+
+	if len(queued) != 0 {
+		t.Errorf("Expected no queued transactions, got %d senders", len(queued))
+	}
+
+	totalTxs := 0
+	for _, txs := range pending {
+		totalTxs += len(txs)
+	}
+
+	if totalTxs != 2 {
+		t.Errorf("Expected 2 pending transactions, got %d", totalTxs)
+	}
+}
+
 func TestTxPool_GetTransactionsForBlock(t *testing.T) {
 	config := DefaultConfig()
 	signer := NewEIP155Signer(big.NewInt(1))
@@ -2,9 +2,11 @@ package txpool
 
 import (
 	"crypto/ecdsa"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"strings"
 
 	"golang.org/x/crypto/sha3"
 )
@@ -44,6 +46,19 @@ func (addr Address) String() string {
 	return fmt.Sprintf("0x%x", addr[:])
 }
 
+// ParseAddress parses the hex representation of an address, with or without the 0x prefix
+func ParseAddress(s string) (addr Address, err error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return addr, fmt.Errorf("invalid address %q: %w", s, err)
+	}
+	if len(b) != len(addr) {
+		return addr, fmt.Errorf("invalid address %q: expected %d bytes, got %d", s, len(addr), len(b))
+	}
+	copy(addr[:], b)
+	return addr, nil
+}
+
 // String returns the hex representation of the hash
 func (h Hash) String() string {
 	return fmt.Sprintf("0x%x", h[:])
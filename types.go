@@ -3,6 +3,7 @@ package hotstuff
 import (
 	"bytes"
 	"crypto"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/binary"
 	"fmt"
@@ -92,6 +93,25 @@ func (v View) ToBytes() []byte {
 	return viewBytes[:]
 }
 
+// Epoch is a number that identifies a range of consecutive views with the same set of validators.
+type Epoch uint64
+
+// Validator describes a replica that participates in the consensus protocol during an epoch.
+type Validator struct {
+	ID        ID
+	Address   string // The address of the replica. May be empty if the replica is already connected.
+	PublicKey PublicKey
+	Power     uint64 // The voting power of the replica. Zero means that the replica has one vote.
+}
+
+// VotingPower returns the voting power of the validator.
+func (v Validator) VotingPower() uint64 {
+	if v.Power == 0 {
+		return 1
+	}
+	return v.Power
+}
+
 // Hash is a SHA256 hash
 type Hash [32]byte
 
@@ -126,10 +146,14 @@ type QuorumSignature interface {
 	Participants() IDSet
 }
 
-// ThresholdSignature is a signature that is only valid when it contains the signatures of a quorum of replicas.
-//
-// Deprecated: renamed to QuorumSignature
-type ThresholdSignature = QuorumSignature
+// ThresholdSignature is a quorum signature that is combined from the signature shares of a threshold number of replicas.
+// It is verified with the public key of the group rather than the keys of the signers,
+// and it has a constant size because it does not reveal which replicas signed it. Hence, it has no participants.
+type ThresholdSignature interface {
+	QuorumSignature
+	// Threshold returns the number of signature shares that are needed to create the signature.
+	Threshold() int
+}
 
 // PartialCert is a signed block hash.
 type PartialCert struct {
@@ -304,15 +328,153 @@ func (qc QuorumCert) String() string {
 	return fmt.Sprintf("QC{ hash: %.6s, IDs: [ %s] }", qc.hash, &sb)
 }
 
+// Checkpoint is the root of the application state after executing a committed block.
+// The block is identified by the quorum certificate that certified it.
+type Checkpoint struct {
+	QC        QuorumCert
+	StateRoot Hash
+}
+
+// BlockHash returns the hash of the block that the checkpoint was taken after.
+func (c Checkpoint) BlockHash() Hash {
+	return c.QC.BlockHash()
+}
+
+// View returns the view of the block that the checkpoint was taken after.
+func (c Checkpoint) View() View {
+	return c.QC.View()
+}
+
+func (c Checkpoint) String() string {
+	return fmt.Sprintf("Checkpoint{ block: %.6s, view: %d, root: %.6s }", c.BlockHash(), c.View(), c.StateRoot)
+}
+
+// Evidence proves that a replica signed two different blocks in the same view,
+// either by proposing both blocks or by voting for both of them.
+type Evidence struct {
+	Offender        ID
+	First           *Block
+	FirstSignature  QuorumSignature
+	Second          *Block
+	SecondSignature QuorumSignature
+}
+
+// View returns the view in which the offender signed the conflicting blocks.
+func (e Evidence) View() View {
+	return e.First.View()
+}
+
+func (e Evidence) String() string {
+	return fmt.Sprintf("Evidence{ offender: %d, view: %d, blocks: %.6s, %.6s }", e.Offender, e.View(), e.First.Hash(), e.Second.Hash())
+}
+
+// MempoolBatch is a batch of client commands that is disseminated by the mempool, separately from the proposals.
+// The batches form a DAG: a batch in a round references the certified batches of the previous round by their digests.
+type MempoolBatch struct {
+	Author  ID     // The replica that created the batch.
+	Round   uint64 // The round of the batch in the DAG.
+	Parents []Hash // The digests of the certified batches of the previous round.
+	Data    []byte // The encoded batch of commands.
+}
+
+// Digest returns the hash of the batch.
+func (b *MempoolBatch) Digest() Hash {
+	h := sha256.New()
+	var buf [12]byte
+	binary.LittleEndian.PutUint32(buf[:4], uint32(b.Author))
+	binary.LittleEndian.PutUint64(buf[4:], b.Round)
+	_, _ = h.Write(buf[:])
+	for _, parent := range b.Parents {
+		_, _ = h.Write(parent[:])
+	}
+	_, _ = h.Write(b.Data)
+	var digest Hash
+	h.Sum(digest[:0])
+	return digest
+}
+
+func (b *MempoolBatch) String() string {
+	return fmt.Sprintf("MempoolBatch{ author: %d, round: %d, parents: %d, size: %d }", b.Author, b.Round, len(b.Parents), len(b.Data))
+}
+
+// AvailabilityCert proves that a quorum of replicas have stored the mempool batch with the digest,
+// such that the batch can be retrieved by any replica once the certificate is ordered.
+type AvailabilityCert struct {
+	signature QuorumSignature
+	digest    Hash
+	author    ID
+	round     uint64
+}
+
+// NewAvailabilityCert returns a new availability certificate for the batch.
+func NewAvailabilityCert(signature QuorumSignature, digest Hash, author ID, round uint64) AvailabilityCert {
+	return AvailabilityCert{signature: signature, digest: digest, author: author, round: round}
+}
+
+// AvailabilityBytes returns the message that is signed by a replica that has stored the batch with the digest.
+func AvailabilityBytes(digest Hash, author ID, round uint64) []byte {
+	b := make([]byte, 0, len(digest)+12)
+	b = append(b, digest[:]...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(author))
+	return binary.LittleEndian.AppendUint64(b, round)
+}
+
+// ToBytes returns the message that is signed by the replicas in the certificate.
+func (ac AvailabilityCert) ToBytes() []byte {
+	return AvailabilityBytes(ac.digest, ac.author, ac.round)
+}
+
+// Signature returns the quorum signature of the certificate.
+func (ac AvailabilityCert) Signature() QuorumSignature {
+	return ac.signature
+}
+
+// Digest returns the digest of the certified batch.
+func (ac AvailabilityCert) Digest() Hash {
+	return ac.digest
+}
+
+// Author returns the replica that created the certified batch.
+func (ac AvailabilityCert) Author() ID {
+	return ac.author
+}
+
+// Round returns the round of the certified batch.
+func (ac AvailabilityCert) Round() uint64 {
+	return ac.round
+}
+
+func (ac AvailabilityCert) String() string {
+	return fmt.Sprintf("AvailabilityCert{ batch: %.6s, author: %d, round: %d }", ac.digest, ac.author, ac.round)
+}
+
 // TimeoutCert (TC) is a certificate created by a quorum of timeout messages.
 type TimeoutCert struct {
-	signature QuorumSignature
-	view      View
+	signature   QuorumSignature
+	view        View
+	highQCViews map[ID]View
 }
 
 // NewTimeoutCert returns a new timeout certificate.
 func NewTimeoutCert(signature QuorumSignature, view View) TimeoutCert {
-	return TimeoutCert{signature, view}
+	return TimeoutCert{signature: signature, view: view}
+}
+
+// NewTimeoutCertWithHighQCs returns a new timeout certificate that includes the view of the high QC of each signer.
+// The signature is an aggregate of the signatures of TimeoutBytes(id, view, highQCViews[id]) by each signer.
+// This is used by Jolteon: https://arxiv.org/abs/2106.10362
+func NewTimeoutCertWithHighQCs(signature QuorumSignature, view View, highQCViews map[ID]View) TimeoutCert {
+	return TimeoutCert{signature: signature, view: view, highQCViews: highQCViews}
+}
+
+// TimeoutBytes returns the message that is signed by the replica with the given ID when it times out in the view
+// and the view of its high QC is highQCView.
+func TimeoutBytes(id ID, view, highQCView View) []byte {
+	var b [20]byte
+	binary.LittleEndian.PutUint32(b[:4], uint32(id))
+	binary.LittleEndian.PutUint64(b[4:12], uint64(view))
+	binary.LittleEndian.PutUint64(b[12:], uint64(highQCView))
+	return b[:]
 }
 
 // ToBytes returns a byte representation of the timeout certificate.
@@ -332,6 +494,19 @@ func (tc TimeoutCert) View() View {
 	return tc.view
 }
 
+// HighQCViews returns the view of the high QC of each signer, or nil if the certificate does not include them.
+func (tc TimeoutCert) HighQCViews() map[ID]View {
+	return tc.highQCViews
+}
+
+// HighQCView returns the highest view of the high QCs of the signers.
+func (tc TimeoutCert) HighQCView() (view View) {
+	for _, v := range tc.highQCViews {
+		view = max(view, v)
+	}
+	return view
+}
+
 func (tc TimeoutCert) String() string {
 	var sb strings.Builder
 	if tc.signature != nil {
@@ -385,6 +560,11 @@ func writeParticipants(wr io.Writer, participants IDSet) (err error) {
 	return err
 }
 
+// ConsensusLatencyEvent is raised when a block is committed.
 type ConsensusLatencyEvent struct {
+	// The time since the block was created, according to the timestamp set by the proposer.
 	Latency time.Duration
+	// The time since the replica received the proposal of the block, measured with the local clock.
+	// It is zero if the replica did not receive the proposal, for example because the block was synced.
+	LocalLatency time.Duration
 }